@@ -2,17 +2,32 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/wailsapp/wails/v3/pkg/application"
+	"github.com/wailsapp/wails/v3/pkg/services/notifications"
+	"github.com/zoeyai/zoeyworker/internal/logger"
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
 	"github.com/zoeyai/zoeyworker/pkg/auto/text"
+	"github.com/zoeyai/zoeyworker/pkg/autostart"
 	"github.com/zoeyai/zoeyworker/pkg/config"
 	"github.com/zoeyai/zoeyworker/pkg/executor"
 	"github.com/zoeyai/zoeyworker/pkg/grpc"
+	"github.com/zoeyai/zoeyworker/pkg/history"
+	"github.com/zoeyai/zoeyworker/pkg/metrics"
 	"github.com/zoeyai/zoeyworker/pkg/permissions"
 	"github.com/zoeyai/zoeyworker/pkg/plugin"
+	"github.com/zoeyai/zoeyworker/pkg/vision/ocr"
 )
 
 // App 应用结构体（作为 Wails v3 Service）
@@ -22,20 +37,93 @@ type App struct {
 	configMgr                *config.Manager
 	executor                 *executor.Executor
 	hasShownTrayNotification bool // 是否已显示过托盘通知
+
+	// notifier 系统通知（wait_for_hotkey 提示操作员），作为 Wails 服务注册
+	notifier *notifications.NotificationService
+
+	// minimizeToTray 关闭窗口时隐藏到托盘（否则退出），保存配置、切换配置和热加载时更新
+	minimizeToTray atomic.Bool
+
+	// trayStateChanged 连接状态变化时通知托盘立即更新（容量 1，合并连续的变化）
+	trayStateChanged chan struct{}
+
+	// metricsServer 指标服务（配置了 metrics_addr 时启动）
+	metricsServer *metrics.Server
+
+	// history 本地执行历史（打开失败时为 nil，不记录）
+	history *history.SQLiteStore
+
+	// pluginCancels 进行中的插件安装（插件名 -> 取消函数），由 pluginMu 保护
+	pluginMu      sync.Mutex
+	pluginCancels map[string]context.CancelFunc
 }
 
 // NewApp 创建应用实例
 func NewApp() *App {
 	return &App{
-		configMgr: config.GetDefaultManager(),
+		configMgr:        config.GetDefaultManager(),
+		trayStateChanged: make(chan struct{}, 1),
+		notifier:         notifications.New(),
+	}
+}
+
+// notifyOperator 显示系统通知提示操作员（如 wait_for_hotkey 等待插入 U 盾），失败时只输出日志
+func (a *App) notifyOperator(title, message string) {
+	err := a.notifier.SendNotification(notifications.NotificationOptions{
+		ID:    fmt.Sprintf("operator-%d", time.Now().UnixNano()),
+		Title: "Zoey Worker - " + title,
+		Body:  message,
+	})
+	if err != nil {
+		fmt.Printf("[WARN] 显示通知失败: %v\n", err)
 	}
 }
 
 // ServiceStartup Wails v3 服务启动时调用
 func (a *App) ServiceStartup(ctx context.Context, options application.ServiceOptions) error {
 	a.ctx = ctx
-	a.grpcClient = grpc.NewClient(nil)
+	clientConfig := grpc.DefaultConfig()
+	clientConfig.DataDir = a.configMgr.GetConfigDir()
+	clientConfig.OutboxDir = filepath.Join(clientConfig.DataDir, "outbox")
+	a.grpcClient = grpc.NewClient(clientConfig)
 	a.executor = executor.NewExecutor(a.grpcClient)
+	a.grpcClient.SetScreenLockProbe(a.executor.ScreenLocked)
+	a.executor.SetOperatorNotifier(a.notifyOperator)
+	a.executor.SetCheckpointDir(filepath.Join(clientConfig.DataDir, "checkpoints"))
+	a.grpcClient.SetDisplayProbe(func() (int, int, float64) {
+		d := screen.CurrentDisplay()
+		return d.Width, d.Height, d.ScaleFactor
+	})
+	if store, err := history.Open(filepath.Join(clientConfig.DataDir, "history.db"), history.DefaultRetention); err != nil {
+		fmt.Printf("[WARN] 打开执行历史失败，不记录历史: %v\n", err)
+	} else {
+		a.history = store
+		a.executor.SetHistory(store)
+	}
+	if cfg, err := a.configMgr.Load(); err == nil {
+		logger.SetLevel(logger.ParseLevel(cfg.LogLevel))
+		a.grpcClient.SetHeartbeatInterval(cfg.HeartbeatInterval)
+		a.grpcClient.SetRemoteControl(cfg.RemoteControl)
+		a.grpcClient.SetClockSkewCorrection(cfg.CorrectClockSkew)
+		a.grpcClient.UpdateTLS(cfg.CACertFile, cfg.ClientCertFile, cfg.ClientKeyFile, cfg.InsecureSkipVerify)
+		a.executor.SetScreenshotMaxWidth(cfg.ScreenshotMaxWidth)
+		a.executor.SetScreenshotQuality(cfg.ScreenshotQuality)
+		a.executor.SetKeepAwake(cfg.KeepAwake)
+		if err := ocr.SetDefaultExecutionProvider(cfg.OCRProvider); err != nil {
+			fmt.Printf("[WARN] %v，使用 CPU\n", err)
+		}
+		if cfg.MetricsAddr != "" {
+			if server, err := metrics.Serve(cfg.MetricsAddr); err != nil {
+				fmt.Printf("[WARN] 启动指标服务失败: %v\n", err)
+			} else {
+				a.metricsServer = server
+			}
+		}
+	}
+
+	// 配置文件被外部修改（如运维轮换密钥）时热加载
+	a.configMgr.OnChange(a.applyConfigChange)
+	a.configMgr.Watch(ctx, 2*time.Second)
 
 	// 预热系统信息（异步检测 Python 环境等耗时操作）
 	grpc.WarmupSystemInfo()
@@ -55,6 +143,18 @@ func (a *App) ServiceStartup(ctx context.Context, options application.ServiceOpt
 		}
 	})
 
+	// 连接状态变化时更新托盘
+	a.grpcClient.SetStatusCallback(func(status grpc.ClientStatus) {
+		// 上次运行崩溃时正在执行的批量任务，首次连接后上报失败
+		if status == grpc.StatusConnected {
+			a.executor.ReportInterruptedTasks()
+		}
+		select {
+		case a.trayStateChanged <- struct{}{}:
+		default:
+		}
+	})
+
 	// 设置任务回调
 	a.grpcClient.SetTaskCallback(func(taskID, taskType, payloadJSON string) {
 		go a.executor.Execute(taskID, taskType, payloadJSON)
@@ -66,18 +166,76 @@ func (a *App) ServiceStartup(ctx context.Context, options application.ServiceOpt
 	})
 
 	// 设置执行器状态回调（用于心跳上报）
-	a.grpcClient.SetExecutorStatusCallback(func() (string, string, string, int64, int) {
+	a.grpcClient.SetExecutorStatusCallback(func() (string, string, string, int64, int, []string) {
 		return a.executor.GetStatus()
 	})
 
+	// 服务端下发设置（心跳间隔由客户端应用）
+	a.grpcClient.SetSettingsCallback(a.applyServerSettings)
+
 	return nil
 }
 
+// applyServerSettings 应用服务端下发的日志级别和截图质量，Persist 时写入当前配置（保存后由热加载再次应用）
+func (a *App) applyServerSettings(settings *grpc.WsUpdateSettings) error {
+	if settings.LogLevel != "" {
+		logger.SetLevel(logger.ParseLevel(settings.LogLevel))
+	}
+	if settings.ScreenshotQuality > 0 {
+		a.executor.SetScreenshotQuality(settings.ScreenshotQuality)
+	}
+	if !settings.Persist {
+		return nil
+	}
+
+	cfg, err := a.configMgr.Load()
+	if err != nil {
+		return fmt.Errorf("读取配置失败: %w", err)
+	}
+	if settings.HeartbeatIntervalSeconds > 0 {
+		cfg.HeartbeatInterval = settings.HeartbeatIntervalSeconds
+	}
+	if settings.LogLevel != "" {
+		cfg.LogLevel = settings.LogLevel
+	}
+	if settings.ScreenshotQuality > 0 {
+		cfg.ScreenshotQuality = settings.ScreenshotQuality
+	}
+	if err := a.configMgr.Save(cfg); err != nil {
+		return fmt.Errorf("保存配置失败: %w", err)
+	}
+	return nil
+}
+
+// applyConfigChange 应用热加载的配置：日志级别、截图宽度、心跳间隔和关闭窗口行为立即生效，
+// 已连接且服务端地址、密钥或 TLS 选项变化时重新连接
+func (a *App) applyConfigChange(cfg *config.ConnectionConfig) {
+	logger.SetLevel(logger.ParseLevel(cfg.LogLevel))
+	a.grpcClient.SetHeartbeatInterval(cfg.HeartbeatInterval)
+	a.grpcClient.SetRemoteControl(cfg.RemoteControl)
+	a.grpcClient.SetClockSkewCorrection(cfg.CorrectClockSkew)
+	a.executor.SetScreenshotMaxWidth(cfg.ScreenshotMaxWidth)
+	a.executor.SetScreenshotQuality(cfg.ScreenshotQuality)
+	a.executor.SetKeepAwake(cfg.KeepAwake)
+	a.minimizeToTray.Store(cfg.MinimizeToTray)
+
+	a.grpcClient.UpdateTLS(cfg.CACertFile, cfg.ClientCertFile, cfg.ClientKeyFile, cfg.InsecureSkipVerify)
+	if changed, err := a.grpcClient.UpdateCredentials(cfg.ServerURL, cfg.AccessKey, cfg.SecretKey); changed && err != nil {
+		a.grpcClient.Log("ERROR", fmt.Sprintf("使用新配置重新连接失败: %v", err))
+	}
+}
+
 // ServiceShutdown Wails v3 服务关闭时调用
 func (a *App) ServiceShutdown() error {
 	if a.grpcClient != nil && a.grpcClient.IsConnected() {
 		a.grpcClient.Disconnect()
 	}
+	if a.metricsServer != nil {
+		a.metricsServer.Close()
+	}
+	if a.history != nil {
+		a.history.Close()
+	}
 	return nil
 }
 
@@ -90,6 +248,11 @@ type ConfigData struct {
 	AccessKey   string `json:"access_key"`
 	SecretKey   string `json:"secret_key"`
 	AutoConnect bool   `json:"auto_connect"`
+	// TLS 设置
+	CACertFile         string `json:"ca_cert_file"`
+	ClientCertFile     string `json:"client_cert_file"`
+	ClientKeyFile      string `json:"client_key_file"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
 	// 重连设置
 	AutoReconnect     bool `json:"auto_reconnect"`
 	ReconnectInterval int  `json:"reconnect_interval"` // 秒
@@ -98,6 +261,7 @@ type ConfigData struct {
 	// 界面设置
 	MinimizeToTray bool `json:"minimize_to_tray"`
 	StartMinimized bool `json:"start_minimized"`
+	AutoStart      bool `json:"auto_start"` // 登录后自动启动（读取时为系统中的实际状态）
 }
 
 // LoadConfig 加载配置
@@ -106,17 +270,11 @@ func (a *App) LoadConfig() ConfigData {
 	if err != nil {
 		cfg = config.DefaultConnectionConfig()
 	}
-	return ConfigData{
-		ServerURL:         cfg.ServerURL,
-		AccessKey:         cfg.AccessKey,
-		SecretKey:         cfg.SecretKey,
-		AutoConnect:       cfg.AutoConnect,
-		AutoReconnect:     cfg.AutoReconnect,
-		ReconnectInterval: cfg.ReconnectInterval,
-		LogLevel:          cfg.LogLevel,
-		MinimizeToTray:    cfg.MinimizeToTray,
-		StartMinimized:    cfg.StartMinimized,
+	data := newConfigData(cfg)
+	if enabled, err := a.GetAutoStart(); err == nil {
+		data.AutoStart = enabled
 	}
+	return data
 }
 
 // SaveConfig 保存配置
@@ -125,18 +283,172 @@ func (a *App) SaveConfig(data ConfigData) error {
 	if err != nil {
 		cfg = config.DefaultConnectionConfig()
 	}
+	if data.AutoStart != cfg.AutoStart {
+		if err := setAutoStart(data.AutoStart); err != nil {
+			return err
+		}
+	}
+	data.applyTo(cfg)
+	if err := a.configMgr.Save(cfg); err != nil {
+		return err
+	}
+	logger.SetLevel(logger.ParseLevel(cfg.LogLevel))
+	a.minimizeToTray.Store(cfg.MinimizeToTray)
+	return nil
+}
+
+// SetLogLevel 运行时调整日志级别（DEBUG / INFO / WARN / ERROR，不区分大小写），不写入配置
+func (a *App) SetLogLevel(level string) error {
+	switch strings.ToUpper(strings.TrimSpace(level)) {
+	case "DEBUG", "INFO", "WARN", "ERROR":
+	default:
+		return fmt.Errorf("无效的日志级别 %q，应为 DEBUG、INFO、WARN 或 ERROR", level)
+	}
+	logger.SetLevel(logger.ParseLevel(level))
+	return nil
+}
+
+// GetLogLevel 获取当前日志级别
+func (a *App) GetLogLevel() string {
+	return logger.GetLevel().String()
+}
+
+// newConfigData 从连接配置生成前端配置数据
+func newConfigData(cfg *config.ConnectionConfig) ConfigData {
+	return ConfigData{
+		ServerURL:          cfg.ServerURL,
+		AccessKey:          cfg.AccessKey,
+		SecretKey:          cfg.SecretKey,
+		AutoConnect:        cfg.AutoConnect,
+		CACertFile:         cfg.CACertFile,
+		ClientCertFile:     cfg.ClientCertFile,
+		ClientKeyFile:      cfg.ClientKeyFile,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		AutoReconnect:      cfg.AutoReconnect,
+		ReconnectInterval:  cfg.ReconnectInterval,
+		LogLevel:           cfg.LogLevel,
+		MinimizeToTray:     cfg.MinimizeToTray,
+		StartMinimized:     cfg.StartMinimized,
+		AutoStart:          cfg.AutoStart,
+	}
+}
+
+// applyTo 将前端配置数据写入连接配置（前端未展示的字段保持不变）
+func (data ConfigData) applyTo(cfg *config.ConnectionConfig) {
 	cfg.ServerURL = data.ServerURL
 	cfg.AccessKey = data.AccessKey
 	cfg.SecretKey = data.SecretKey
 	cfg.AutoConnect = data.AutoConnect
+	cfg.CACertFile = data.CACertFile
+	cfg.ClientCertFile = data.ClientCertFile
+	cfg.ClientKeyFile = data.ClientKeyFile
+	cfg.InsecureSkipVerify = data.InsecureSkipVerify
 	cfg.AutoReconnect = data.AutoReconnect
 	cfg.ReconnectInterval = data.ReconnectInterval
 	cfg.LogLevel = data.LogLevel
 	cfg.MinimizeToTray = data.MinimizeToTray
 	cfg.StartMinimized = data.StartMinimized
+	cfg.AutoStart = data.AutoStart
+}
+
+// ==================== 登录后自动启动 ====================
+
+// SetAutoStart 启用或关闭登录后自动启动（写入系统的自启动项），成功后写入配置
+func (a *App) SetAutoStart(enabled bool) error {
+	if err := setAutoStart(enabled); err != nil {
+		return err
+	}
+	return a.saveAutoStart(enabled)
+}
+
+// GetAutoStart 获取系统中的自启动状态；与配置不一致（如在系统设置中被关闭）时更新配置
+func (a *App) GetAutoStart() (bool, error) {
+	entry, err := autostart.NewEntry()
+	if err != nil {
+		return false, err
+	}
+	enabled, err := autostart.IsEnabled(entry)
+	if err != nil {
+		return false, err
+	}
+	if err := a.saveAutoStart(enabled); err != nil {
+		logger.Warn("同步自启动配置失败: %v", err)
+	}
+	return enabled, nil
+}
+
+// setAutoStart 写入或删除当前程序的自启动项
+func setAutoStart(enabled bool) error {
+	entry, err := autostart.NewEntry()
+	if err != nil {
+		return err
+	}
+	if enabled {
+		err = autostart.Enable(entry)
+	} else {
+		err = autostart.Disable(entry)
+	}
+	if err != nil {
+		return fmt.Errorf("设置登录后自动启动失败: %w", err)
+	}
+	logger.Info("登录后自动启动: %v", enabled)
+	return nil
+}
+
+// saveAutoStart 配置中的自启动状态与 enabled 不同时写入配置
+func (a *App) saveAutoStart(enabled bool) error {
+	cfg, err := a.configMgr.Load()
+	if err != nil {
+		cfg = config.DefaultConnectionConfig()
+	}
+	if cfg.AutoStart == enabled {
+		return nil
+	}
+	cfg.AutoStart = enabled
 	return a.configMgr.Save(cfg)
 }
 
+// ProfileList 连接配置列表（供配置下拉框使用）
+type ProfileList struct {
+	Current  string   `json:"current"`
+	Profiles []string `json:"profiles"`
+}
+
+// ListProfiles 获取所有连接配置及当前配置
+func (a *App) ListProfiles() (ProfileList, error) {
+	names, current, err := a.configMgr.ListProfiles()
+	if err != nil {
+		return ProfileList{}, err
+	}
+	return ProfileList{Current: current, Profiles: names}, nil
+}
+
+// SaveProfile 保存命名配置（不存在时创建），不切换当前配置
+func (a *App) SaveProfile(name string, data ConfigData) error {
+	cfg, err := a.configMgr.LoadProfile(name)
+	if err != nil {
+		cfg = config.DefaultConnectionConfig()
+	}
+	data.applyTo(cfg)
+	return a.configMgr.SaveProfile(name, cfg)
+}
+
+// DeleteProfile 删除命名配置（不能删除当前配置）
+func (a *App) DeleteProfile(name string) error {
+	return a.configMgr.DeleteProfile(name)
+}
+
+// UseProfile 切换当前配置并返回该配置，已连接时需重新连接才会使用新的服务端地址
+func (a *App) UseProfile(name string) (ConfigData, error) {
+	if err := a.configMgr.UseProfile(name); err != nil {
+		return ConfigData{}, err
+	}
+	data := a.LoadConfig()
+	logger.SetLevel(logger.ParseLevel(data.LogLevel))
+	a.minimizeToTray.Store(data.MinimizeToTray)
+	return data, nil
+}
+
 // ==================== gRPC 连接管理 ====================
 
 // ConnectResult 连接结果
@@ -160,14 +472,24 @@ func (a *App) Connect(serverURL, accessKey, secretKey string) ConnectResult {
 	_ = a.configMgr.Save(cfg)
 
 	// 连接（Connect 方法会自动启动 TaskStream）
+	a.grpcClient.UpdateTLS(cfg.CACertFile, cfg.ClientCertFile, cfg.ClientKeyFile, cfg.InsecureSkipVerify)
 	err := a.grpcClient.Connect(serverURL, accessKey, secretKey)
 	if err != nil {
+		message := fmt.Sprintf("连接失败: %v", err)
+		if errors.Is(err, grpc.ErrTLS) {
+			message += "（请在设置中检查 CA 证书或客户端证书）"
+		}
 		return ConnectResult{
 			Success: false,
-			Message: fmt.Sprintf("连接失败: %v", err),
+			Message: message,
 		}
 	}
 
+	// 后台预热 OCR，不阻塞连接
+	if cfg.OCRWarmup {
+		a.executor.WarmupOCR()
+	}
+
 	// 获取状态
 	_, agentID, agentName := a.grpcClient.GetStatus()
 
@@ -192,25 +514,360 @@ type StatusResult struct {
 	Connected bool   `json:"connected"`
 	AgentID   string `json:"agent_id"`
 	AgentName string `json:"agent_name"`
+	// Status 连接状态机的状态（reconnecting 时由客户端自动重连，前端无需重连）
+	Status string `json:"status"`
 }
 
 // GetStatus 获取连接状态
 func (a *App) GetStatus() StatusResult {
 	if a.grpcClient == nil {
-		return StatusResult{Connected: false}
+		return StatusResult{Connected: false, Status: string(grpc.StatusDisconnected)}
 	}
-	_, agentID, agentName := a.grpcClient.GetStatus()
+	status, agentID, agentName := a.grpcClient.GetStatus()
 	return StatusResult{
-		Connected: a.grpcClient.IsConnected(),
+		Connected: status == grpc.StatusConnected,
 		AgentID:   agentID,
 		AgentName: agentName,
+		Status:    string(status),
+	}
+}
+
+// WorkerStats 运行统计
+type WorkerStats struct {
+	// 资源使用率与心跳上报的相同（连接后按心跳间隔采样），尚未采样时 HasResources 为 false
+	HasResources  bool    `json:"has_resources"`
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemoryPercent float64 `json:"memory_percent"`
+	DiskPercent   float64 `json:"disk_percent"`
+	UptimeSeconds int64   `json:"uptime_seconds"`
+	TasksExecuted int64   `json:"tasks_executed"` // 启动以来执行完成的任务数
+	TasksFailed   int64   `json:"tasks_failed"`
+	Reconnects    int64   `json:"reconnects"`     // 连接异常断开后重连成功的次数
+	LastHeartbeat int64   `json:"last_heartbeat"` // 最近一次心跳时间（毫秒时间戳），尚未发送时为 0
+}
+
+// GetWorkerStats 获取运行统计（前端定时轮询）
+func (a *App) GetWorkerStats() WorkerStats {
+	if a.grpcClient == nil || a.executor == nil {
+		return WorkerStats{}
+	}
+	stats := a.grpcClient.Stats()
+	result := WorkerStats{
+		UptimeSeconds: int64(time.Since(stats.StartedAt).Seconds()),
+		Reconnects:    stats.Reconnects,
+	}
+	if r := stats.Resources; r != nil {
+		result.HasResources = true
+		result.CPUPercent = float64(r.CpuUsage)
+		result.MemoryPercent = float64(r.MemoryUsage)
+		result.DiskPercent = float64(r.DiskUsage)
+	}
+	if !stats.LastHeartbeat.IsZero() {
+		result.LastHeartbeat = stats.LastHeartbeat.UnixMilli()
+	}
+	result.TasksExecuted, result.TasksFailed = a.executor.Stats()
+	return result
+}
+
+// ==================== 运行中任务 ====================
+
+// RunningTaskInfo 运行中的任务
+type RunningTaskInfo struct {
+	TaskID         string `json:"task_id"`
+	TaskType       string `json:"task_type"`
+	StartedAt      int64  `json:"started_at"`   // 开始时间（毫秒时间戳）
+	CurrentStep    string `json:"current_step"` // 批量任务的当前步骤
+	TotalSteps     int32  `json:"total_steps"`
+	CompletedSteps int32  `json:"completed_steps"`
+	Cancelled      bool   `json:"cancelled"` // 已请求取消，等待当前步骤结束
+}
+
+// GetRunningTasks 获取运行中的任务（按开始时间排序）
+func (a *App) GetRunningTasks() []RunningTaskInfo {
+	if a.executor == nil {
+		return []RunningTaskInfo{}
+	}
+	tasks := a.executor.RunningTasks()
+	result := make([]RunningTaskInfo, len(tasks))
+	for i, t := range tasks {
+		result[i] = RunningTaskInfo{
+			TaskID:         t.TaskID,
+			TaskType:       t.TaskType,
+			StartedAt:      t.StartedAt,
+			CurrentStep:    t.CurrentStep,
+			TotalSteps:     t.TotalSteps,
+			CompletedSteps: t.CompletedSteps,
+			Cancelled:      t.Cancelled,
+		}
+	}
+	return result
+}
+
+// CancelRunningTask 取消运行中的任务，服务端收到 CANCELLED 结果；任务不存在或已取消时返回 false
+func (a *App) CancelRunningTask(taskID string) bool {
+	if a.executor == nil {
+		return false
+	}
+	if !a.executor.CancelTask(taskID) {
+		return false
+	}
+	logger.Info("用户取消任务 %s", taskID)
+	return true
+}
+
+// cancelCurrentTask 取消当前任务（托盘菜单使用，当前任务的选择规则同心跳上报）
+func (a *App) cancelCurrentTask() bool {
+	if a.executor == nil {
+		return false
+	}
+	_, taskID, _, _, _, _ := a.executor.GetStatus()
+	return taskID != "" && a.CancelRunningTask(taskID)
+}
+
+// ==================== 执行历史 ====================
+
+// HistoryFilter 执行历史查询条件，空字段不过滤
+type HistoryFilter struct {
+	Status   string `json:"status"`    // SUCCESS、FAILED、CANCELLED ...
+	TaskType string `json:"task_type"` // 任务类型
+	Query    string `json:"query"`     // 任务 ID 或错误信息包含的文字
+}
+
+// HistoryStep 执行历史中的步骤
+type HistoryStep struct {
+	StepExecutionID string `json:"step_execution_id"` // GetStepDetails 的参数
+	StepID          string `json:"step_id"`
+	ActionType      string `json:"action_type"`
+	Status          string `json:"status"`
+	FailureReason   string `json:"failure_reason"`
+	Error           string `json:"error"`
+	DurationMs      int64  `json:"duration_ms"`
+	HasScreenshots  bool   `json:"has_screenshots"`
+}
+
+// HistoryRun 一次任务执行
+type HistoryRun struct {
+	TaskID        string        `json:"task_id"`
+	TaskType      string        `json:"task_type"`
+	Status        string        `json:"status"`
+	Success       bool          `json:"success"`
+	FailureReason string        `json:"failure_reason"`
+	Error         string        `json:"error"`
+	StartedAt     int64         `json:"started_at"` // 开始时间（毫秒时间戳）
+	DurationMs    int64         `json:"duration_ms"`
+	Steps         []HistoryStep `json:"steps"`
+}
+
+// HistoryPage 一页执行历史（从新到旧）
+type HistoryPage struct {
+	Runs     []HistoryRun `json:"runs"`
+	Total    int          `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+}
+
+// StepDetails 步骤详情，截图从磁盘读取为 data URL
+type StepDetails struct {
+	HistoryStep
+	TaskID           string `json:"task_id"`
+	ScreenshotBefore string `json:"screenshot_before"`
+	ScreenshotAfter  string `json:"screenshot_after"`
+}
+
+// GetTaskHistory 分页查询本地执行历史（page 从 1 开始，每页 history.DefaultPageSize 条）
+func (a *App) GetTaskHistory(page int, filter HistoryFilter) (HistoryPage, error) {
+	if a.history == nil {
+		return HistoryPage{Runs: []HistoryRun{}, Page: max(page, 1), PageSize: history.DefaultPageSize}, nil
+	}
+	result, err := a.history.List(page, 0, history.Filter{Status: filter.Status, TaskType: filter.TaskType, Query: filter.Query})
+	if err != nil {
+		return HistoryPage{}, err
+	}
+	runs := make([]HistoryRun, len(result.Runs))
+	for i, run := range result.Runs {
+		steps := make([]HistoryStep, len(run.Steps))
+		for j, step := range run.Steps {
+			steps[j] = toHistoryStep(step)
+		}
+		runs[i] = HistoryRun{
+			TaskID:        run.ID,
+			TaskType:      run.TaskType,
+			Status:        run.Status,
+			Success:       run.Success,
+			FailureReason: run.FailureReason,
+			Error:         run.Error,
+			StartedAt:     run.StartedAt,
+			DurationMs:    run.DurationMs,
+			Steps:         steps,
+		}
+	}
+	return HistoryPage{Runs: runs, Total: result.Total, Page: result.Page, PageSize: result.PageSize}, nil
+}
+
+// GetStepDetails 获取步骤详情（含截图），截图文件已被清理时为空
+func (a *App) GetStepDetails(stepExecutionID string) (StepDetails, error) {
+	if a.history == nil {
+		return StepDetails{}, errors.New("执行历史不可用")
+	}
+	run, step, err := a.history.Step(stepExecutionID)
+	if errors.Is(err, history.ErrNotFound) {
+		return StepDetails{}, fmt.Errorf("步骤 %s 不存在", stepExecutionID)
+	}
+	if err != nil {
+		return StepDetails{}, err
+	}
+	return StepDetails{
+		HistoryStep:      toHistoryStep(step),
+		TaskID:           run.ID,
+		ScreenshotBefore: readScreenshot(step.ScreenshotBefore),
+		ScreenshotAfter:  readScreenshot(step.ScreenshotAfter),
+	}, nil
+}
+
+func toHistoryStep(step *history.Step) HistoryStep {
+	return HistoryStep{
+		StepExecutionID: step.StepExecutionID,
+		StepID:          step.StepID,
+		ActionType:      step.ActionType,
+		Status:          step.Status,
+		FailureReason:   step.FailureReason,
+		Error:           step.Error,
+		DurationMs:      step.DurationMs,
+		HasScreenshots:  step.ScreenshotBefore != "" || step.ScreenshotAfter != "",
+	}
+}
+
+// readScreenshot 读取截图文件为 data URL（前端无法直接访问本地文件），不存在时返回空字符串
+func readScreenshot(path string) string {
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// ==================== 定位测试 ====================
+
+// LocatorRegion 文字查找的屏幕区域
+type LocatorRegion struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// LocatorResult 定位测试结果
+type LocatorResult struct {
+	Found       bool           `json:"found"`
+	X           int            `json:"x"` // 目标中心（屏幕坐标）
+	Y           int            `json:"y"`
+	Bounds      *LocatorRegion `json:"bounds"`
+	Confidence  float64        `json:"confidence"`
+	MatchedText string         `json:"matched_text"`
+	DurationMs  int64          `json:"duration_ms"`
+	Message     string         `json:"message"`
+	Screenshot  string         `json:"screenshot"` // 标注了查找结果的截图（data URL）
+}
+
+// TestFindImage 在当前屏幕查找图像（文件路径或 base64），threshold 为 0 时使用默认阈值
+// 只截图不点击，未连接服务端时也可使用
+func (a *App) TestFindImage(source string, threshold float64) (LocatorResult, error) {
+	result, err := executor.LocateImage(source, threshold)
+	if err != nil {
+		return LocatorResult{}, err
+	}
+	return toLocatorResult(result), nil
+}
+
+// TestFindText 在当前屏幕（region 为 null 时全屏）OCR 查找文字，只截图不点击，未连接服务端时也可使用
+func (a *App) TestFindText(target string, region *LocatorRegion) (LocatorResult, error) {
+	var r *auto.Region
+	if region != nil {
+		r = &auto.Region{X: region.X, Y: region.Y, Width: region.Width, Height: region.Height}
 	}
+	result, err := executor.LocateText(target, r)
+	if err != nil {
+		return LocatorResult{}, err
+	}
+	return toLocatorResult(result), nil
+}
+
+func toLocatorResult(r *executor.LocateResult) LocatorResult {
+	result := LocatorResult{
+		Found:       r.Found,
+		X:           r.X,
+		Y:           r.Y,
+		Confidence:  r.Confidence,
+		MatchedText: r.MatchedText,
+		DurationMs:  r.DurationMs,
+		Message:     r.Message,
+		Screenshot:  r.Screenshot,
+	}
+	if r.Bounds != nil {
+		result.Bounds = &LocatorRegion{X: r.Bounds.X, Y: r.Bounds.Y, Width: r.Bounds.Width, Height: r.Bounds.Height}
+	}
+	return result
+}
+
+// ==================== 临时步骤 ====================
+
+// AdhocStepResult 临时步骤的执行结果（同服务端收到的步骤结果）
+type AdhocStepResult struct {
+	Status        string `json:"status"` // SUCCESS, FAILED, CANCELLED
+	ActionType    string `json:"action_type"`
+	DurationMs    int64  `json:"duration_ms"`
+	ErrorMessage  string `json:"error_message"`
+	FailureReason string `json:"failure_reason"`
+
+	ScreenshotBefore string `json:"screenshot_before"` // data URL
+	ScreenshotAfter  string `json:"screenshot_after"`  // 画面未变化时为空，此时 ScreenshotAfterSameAsBefore 为 true
+	// 执行后画面与执行前相同
+	ScreenshotAfterSameAsBefore bool `json:"screenshot_after_same_as_before"`
+
+	TargetBounds *LocatorRegion `json:"target_bounds"` // 截图像素坐标
+	Confidence   float64        `json:"confidence"`
+	MatchedText  string         `json:"matched_text"`
+	Stdout       string         `json:"stdout"`
+	Stderr       string         `json:"stderr"`
+	ExitCode     int            `json:"exit_code"`
+}
+
+// RunAdhocStep 在本地执行单个步骤（payloadJSON 同服务端下发的任务参数），不发送任何消息到服务端，
+// 记录到本地执行历史；有任务正在执行时返回错误，未连接服务端时也可使用
+func (a *App) RunAdhocStep(taskType string, payloadJSON string) (AdhocStepResult, error) {
+	r, err := a.executor.RunStep(taskType, payloadJSON)
+	if err != nil {
+		return AdhocStepResult{}, err
+	}
+	result := AdhocStepResult{
+		Status:                      r.Status,
+		ActionType:                  r.ActionType,
+		DurationMs:                  r.DurationMs,
+		ErrorMessage:                r.ErrorMessage,
+		FailureReason:               r.FailureReason,
+		ScreenshotBefore:            r.ScreenshotBefore,
+		ScreenshotAfter:             r.ScreenshotAfter,
+		ScreenshotAfterSameAsBefore: r.ScreenshotAfterSameAsBefore,
+		Confidence:                  r.Confidence,
+		MatchedText:                 r.MatchedText,
+		Stdout:                      r.Stdout,
+		Stderr:                      r.Stderr,
+		ExitCode:                    r.ExitCode,
+	}
+	if b := r.TargetBounds; b != nil {
+		result.TargetBounds = &LocatorRegion{X: b.X, Y: b.Y, Width: b.Width, Height: b.Height}
+	}
+	return result, nil
 }
 
 // ==================== 日志 ====================
 
 // LogEntry 日志条目
 type LogEntry struct {
+	Seq     int64  `json:"seq"`
 	Time    string `json:"time"`
 	Level   string `json:"level"`
 	Message string `json:"message"`
@@ -222,10 +879,22 @@ func (a *App) GetLogs(count int) []LogEntry {
 		return []LogEntry{}
 	}
 
-	logs := a.grpcClient.GetLogs(count)
+	return toLogEntries(a.grpcClient.GetLogs(count))
+}
+
+// GetLogsSince 获取序号大于 seq 的新日志，前端记录最大的 seq 增量拉取（seq 为 0 时返回全部缓冲的日志）
+func (a *App) GetLogsSince(seq int64) []LogEntry {
+	if a.grpcClient == nil {
+		return []LogEntry{}
+	}
+	return toLogEntries(a.grpcClient.GetLogsSince(seq))
+}
+
+func toLogEntries(logs []grpc.LogEntry) []LogEntry {
 	result := make([]LogEntry, len(logs))
 	for i, log := range logs {
 		result[i] = LogEntry{
+			Seq:     log.Seq,
 			Time:    log.Timestamp,
 			Level:   log.Level,
 			Message: log.Message,
@@ -241,6 +910,7 @@ type SystemInfo struct {
 	Platform string `json:"platform"`
 	Hostname string `json:"hostname"`
 	Arch     string `json:"arch"`
+	ClientID string `json:"client_id"` // 客户端标识（重启后不变）
 }
 
 // GetSystemInfo 获取系统信息
@@ -259,6 +929,7 @@ func (a *App) GetSystemInfo() SystemInfo {
 		Platform: platform,
 		Hostname: hostname,
 		Arch:     runtime.GOARCH,
+		ClientID: a.grpcClient.ClientID(),
 	}
 }
 
@@ -349,33 +1020,145 @@ func (a *App) RefreshPythonInfo() PythonInfo {
 // OCRPluginStatusResult OCR 插件状态
 type OCRPluginStatusResult struct {
 	Installed bool `json:"installed"`
+	// Provider 配置的执行提供者，ActiveProvider 为实际使用的（OCR 尚未加载时为空）
+	Provider       string `json:"provider"`
+	ActiveProvider string `json:"active_provider"`
 }
 
 // GetOCRPluginStatus 获取 OCR 插件状态
 func (a *App) GetOCRPluginStatus() OCRPluginStatusResult {
 	p := plugin.GetOCRPlugin()
+	status := ocr.GetProviderStatus()
 	return OCRPluginStatusResult{
-		Installed: p.IsInstalled(),
+		Installed:      p.IsInstalled(),
+		Provider:       string(status.Requested),
+		ActiveProvider: string(status.Active),
 	}
 }
 
 // InstallOCRPlugin 安装 OCR 插件
 func (a *App) InstallOCRPlugin() error {
-	p := plugin.GetOCRPlugin()
+	return a.InstallPlugin(plugin.OCRPluginName)
+}
 
-	// 设置进度回调
+// InstallOCRPluginFromArchive 选择离线安装包（zip / tgz）安装 OCR 插件，返回选择的文件路径（用户取消选择时为空）
+func (a *App) InstallOCRPluginFromArchive() (string, error) {
+	path, err := mainApp.Dialog.OpenFile().
+		SetTitle("选择 OCR 离线安装包").
+		AddFilter("OCR 离线安装包", "*.zip;*.tgz;*.tar.gz").
+		PromptForSingleSelection()
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	p := plugin.GetOCRPlugin()
 	p.SetProgressCallback(func(progress float64) {
-		// Wails v3 暂时不使用事件系统，简化处理
-		fmt.Printf("OCR Install progress: %.0f%%\n", progress*100)
+		fmt.Printf("OCR Install progress: %.0f%%\n", progress)
 	})
+	return path, pluginInstallError(p.InstallFromArchive(path))
+}
+
+// pluginInstallError 安装前检查（磁盘空间、目录权限）失败时注明不是网络问题，其他错误原样返回
+func pluginInstallError(err error) error {
+	var precheckErr *plugin.PrecheckError
+	if errors.As(err, &precheckErr) {
+		return fmt.Errorf("安装前检查未通过（不是网络问题）: %w", err)
+	}
+	return err
+}
 
-	// 开始安装
-	return p.Install()
+// OCRInstallProgress OCR 插件安装进度
+type OCRInstallProgress struct {
+	Downloading bool    `json:"downloading"`
+	Phase       string  `json:"phase"`   // 如 "downloading det.onnx"、"extracting onnxruntime.dll"、"done"、"canceled"、"failed"、"precheck_failed"（磁盘空间或权限问题）
+	Percent     float64 `json:"percent"` // 0-100
+}
+
+// GetOCRInstallProgress 获取 OCR 插件安装进度（供前端轮询）
+func (a *App) GetOCRInstallProgress() OCRInstallProgress {
+	status := plugin.GetOCRPlugin().GetStatus()
+	return OCRInstallProgress{
+		Downloading: status.Downloading,
+		Phase:       status.Phase,
+		Percent:     status.Progress,
+	}
+}
+
+// CancelOCRPluginInstall 取消进行中的 OCR 插件安装，没有进行中的安装时返回 false
+func (a *App) CancelOCRPluginInstall() bool {
+	return plugin.GetOCRPlugin().Cancel()
 }
 
 // UninstallOCRPlugin 卸载 OCR 插件
 func (a *App) UninstallOCRPlugin() error {
-	p := plugin.GetOCRPlugin()
+	return a.UninstallPlugin(plugin.OCRPluginName)
+}
+
+// ==================== 插件管理 ====================
+
+// ListPlugins 获取所有可选插件的状态及文件路径
+func (a *App) ListPlugins() []plugin.Info {
+	return plugin.ListInfo()
+}
+
+// InstallPlugin 安装指定插件，前端通过轮询 ListPlugins 获取进度，可通过 CancelPluginInstall 取消
+func (a *App) InstallPlugin(name string) error {
+	p, ok := plugin.Get(name)
+	if !ok {
+		return fmt.Errorf("未知的插件: %s", name)
+	}
+
+	// 镜像和代理按当前配置设置（修改配置后无需重启）
+	if m, ok := p.(interface {
+		SetMirrors([]string)
+		SetProxy(string) error
+	}); ok {
+		if cfg, err := a.configMgr.Load(); err == nil {
+			m.SetMirrors(cfg.PluginMirrors)
+			if err := m.SetProxy(cfg.PluginProxy); err != nil {
+				return err
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.pluginMu.Lock()
+	if a.pluginCancels == nil {
+		a.pluginCancels = make(map[string]context.CancelFunc)
+	}
+	a.pluginCancels[name] = cancel
+	a.pluginMu.Unlock()
+	defer func() {
+		a.pluginMu.Lock()
+		delete(a.pluginCancels, name)
+		a.pluginMu.Unlock()
+		cancel()
+	}()
+
+	// 进度只输出到控制台（progress 已是 0-100）
+	err := p.Install(ctx, func(progress float64) {
+		fmt.Printf("Plugin %s install progress: %.0f%%\n", name, progress)
+	})
+	return pluginInstallError(err)
+}
+
+// CancelPluginInstall 取消通过 InstallPlugin 发起的安装，没有进行中的安装时返回 false
+func (a *App) CancelPluginInstall(name string) bool {
+	a.pluginMu.Lock()
+	defer a.pluginMu.Unlock()
+	cancel, ok := a.pluginCancels[name]
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// UninstallPlugin 卸载指定插件
+func (a *App) UninstallPlugin(name string) error {
+	p, ok := plugin.Get(name)
+	if !ok {
+		return fmt.Errorf("未知的插件: %s", name)
+	}
 	return p.Uninstall()
 }
 
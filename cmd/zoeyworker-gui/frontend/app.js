@@ -46,11 +46,21 @@ const SERVICE = 'main.App'
 const App = {
   LoadConfig: () => callBackend(`${SERVICE}.LoadConfig`),
   SaveConfig: (config) => callBackend(`${SERVICE}.SaveConfig`, config),
+  SetAutoStart: (enabled) => callBackend(`${SERVICE}.SetAutoStart`, enabled),
   Connect: (url, accessKey, secretKey) => callBackend(`${SERVICE}.Connect`, url, accessKey, secretKey),
   Disconnect: () => callBackend(`${SERVICE}.Disconnect`),
   GetStatus: () => callBackend(`${SERVICE}.GetStatus`),
   GetLogs: (count) => callBackend(`${SERVICE}.GetLogs`, count),
+  GetLogsSince: (seq) => callBackend(`${SERVICE}.GetLogsSince`, seq),
   GetSystemInfo: () => callBackend(`${SERVICE}.GetSystemInfo`),
+  GetRunningTasks: () => callBackend(`${SERVICE}.GetRunningTasks`),
+  GetWorkerStats: () => callBackend(`${SERVICE}.GetWorkerStats`),
+  GetTaskHistory: (page, filter) => callBackend(`${SERVICE}.GetTaskHistory`, page, filter),
+  GetStepDetails: (stepExecutionId) => callBackend(`${SERVICE}.GetStepDetails`, stepExecutionId),
+  TestFindImage: (source, threshold) => callBackend(`${SERVICE}.TestFindImage`, source, threshold),
+  TestFindText: (target, region) => callBackend(`${SERVICE}.TestFindText`, target, region),
+  RunAdhocStep: (taskType, payloadJSON) => callBackend(`${SERVICE}.RunAdhocStep`, taskType, payloadJSON),
+  CancelRunningTask: (taskId) => callBackend(`${SERVICE}.CancelRunningTask`, taskId),
   CheckPermissions: () => callBackend(`${SERVICE}.CheckPermissions`),
   RequestPermissions: () => callBackend(`${SERVICE}.RequestPermissions`),
   OpenAccessibilitySettings: () => callBackend(`${SERVICE}.OpenAccessibilitySettings`),
@@ -75,9 +85,48 @@ const els = {
   connectBtn: $('connectBtn'),
   disconnectBtn: $('disconnectBtn'),
   errorMessage: $('errorMessage'),
+  runningTask: $('runningTask'),
+  runningTaskInfo: $('runningTaskInfo'),
+  statCpu: $('statCpu'),
+  statMemory: $('statMemory'),
+  statDisk: $('statDisk'),
+  statUptime: $('statUptime'),
+  statTasks: $('statTasks'),
+  statReconnects: $('statReconnects'),
+  statHeartbeat: $('statHeartbeat'),
+  cancelTaskBtn: $('cancelTaskBtn'),
   refreshLogsBtn: $('refreshLogsBtn'),
   emptyLogs: $('emptyLogs'),
   logList: $('logList'),
+  // 执行历史
+  historyStatus: $('historyStatus'),
+  historyQuery: $('historyQuery'),
+  refreshHistoryBtn: $('refreshHistoryBtn'),
+  emptyHistory: $('emptyHistory'),
+  historyList: $('historyList'),
+  historyTotal: $('historyTotal'),
+  historyPageInfo: $('historyPageInfo'),
+  historyPrevBtn: $('historyPrevBtn'),
+  historyNextBtn: $('historyNextBtn'),
+  locatorMode: $('locatorMode'),
+  locatorTarget: $('locatorTarget'),
+  locatorFindBtn: $('locatorFindBtn'),
+  locatorImageOptions: $('locatorImageOptions'),
+  locatorThreshold: $('locatorThreshold'),
+  locatorTextOptions: $('locatorTextOptions'),
+  locatorRegionX: $('locatorRegionX'),
+  locatorRegionY: $('locatorRegionY'),
+  locatorRegionW: $('locatorRegionW'),
+  locatorRegionH: $('locatorRegionH'),
+  locatorResult: $('locatorResult'),
+  locatorScreenshot: $('locatorScreenshot'),
+  adhocTaskType: $('adhocTaskType'),
+  adhocPayload: $('adhocPayload'),
+  adhocRunBtn: $('adhocRunBtn'),
+  adhocResult: $('adhocResult'),
+  adhocScreenshots: $('adhocScreenshots'),
+  adhocScreenshotBefore: $('adhocScreenshotBefore'),
+  adhocScreenshotAfter: $('adhocScreenshotAfter'),
   systemInfo: $('systemInfo'),
   currentTime: $('currentTime'),
   // Header 连接信息
@@ -89,15 +138,21 @@ const els = {
   settingAutoConnect: $('settingAutoConnect'),
   settingAutoReconnect: $('settingAutoReconnect'),
   settingReconnectInterval: $('settingReconnectInterval'),
+  settingCACertFile: $('settingCACertFile'),
+  settingClientCertFile: $('settingClientCertFile'),
+  settingClientKeyFile: $('settingClientKeyFile'),
+  settingInsecureSkipVerify: $('settingInsecureSkipVerify'),
   settingLogLevel: $('settingLogLevel'),
   settingMinimizeToTray: $('settingMinimizeToTray'),
   settingStartMinimized: $('settingStartMinimized'),
+  settingAutoStart: $('settingAutoStart'),
   settingsSaved: $('settingsSaved')
 }
 
 // ========== 状态 ==========
 let state = {
   connected: false,
+  status: 'disconnected',
   agentId: '',
   agentName: '',
   config: null,
@@ -143,15 +198,23 @@ async function init() {
   try {
     const info = await App.GetSystemInfo()
     els.systemInfo.textContent = `${info.platform} | ${info.hostname}`
+    els.systemInfo.title = info.client_id ? `客户端标识: ${info.client_id}` : ''
   } catch (e) {
     console.error('获取系统信息失败:', e)
   }
 
-  // 定时刷新日志
-  setInterval(refreshLogs, 3000)
+  // 定时拉取新日志（只返回上次之后的日志，间隔短也不会重复渲染）
+  setInterval(refreshLogs, 1000)
 
   // 定时检查连接状态
   setInterval(checkConnectionStatus, 2000)
+
+  // 定时刷新运行中的任务
+  setInterval(refreshRunningTask, 1000)
+
+  // 定时刷新运行统计（资源使用率按心跳间隔采样，无需更频繁）
+  refreshWorkerStats()
+  setInterval(refreshWorkerStats, 5000)
 }
 
 // ========== 事件绑定 ==========
@@ -171,13 +234,39 @@ function bindEvents() {
   els.disconnectBtn.addEventListener('click', disconnect)
 
   // 刷新日志
-  els.refreshLogsBtn.addEventListener('click', refreshLogs)
+  els.refreshLogsBtn.addEventListener('click', reloadLogs)
+
+  // 取消运行中的任务
+  els.cancelTaskBtn.addEventListener('click', cancelRunningTask)
+
+  // 执行历史
+  els.refreshHistoryBtn.addEventListener('click', () => loadHistory(historyPage))
+  els.historyStatus.addEventListener('change', () => loadHistory(1))
+  els.historyQuery.addEventListener('keydown', e => {
+    if (e.key === 'Enter') loadHistory(1)
+  })
+  els.historyPrevBtn.addEventListener('click', () => loadHistory(historyPage - 1))
+  els.historyNextBtn.addEventListener('click', () => loadHistory(historyPage + 1))
+
+  // 定位测试
+  els.locatorMode.addEventListener('change', switchLocatorMode)
+  els.locatorFindBtn.addEventListener('click', runLocator)
+  els.locatorTarget.addEventListener('keydown', e => {
+    if (e.key === 'Enter') runLocator()
+  })
+
+  // 执行单步
+  els.adhocRunBtn.addEventListener('click', runAdhocStep)
   
   // 设置变更 - 自动保存
   const settingInputs = [
     els.settingAutoConnect,
     els.settingAutoReconnect,
     els.settingReconnectInterval,
+    els.settingCACertFile,
+    els.settingClientCertFile,
+    els.settingClientKeyFile,
+    els.settingInsecureSkipVerify,
     els.settingLogLevel,
     els.settingMinimizeToTray,
     els.settingStartMinimized
@@ -188,6 +277,9 @@ function bindEvents() {
     }
   })
   
+  // 登录后自动启动（写入系统的自启动项，失败时恢复勾选状态）
+  els.settingAutoStart.addEventListener('change', setAutoStart)
+
   // 复制 Agent ID
   if (els.copyAgentIdBtn) {
     els.copyAgentIdBtn.addEventListener('click', copyAgentId)
@@ -234,6 +326,9 @@ function switchTab(tabName) {
   if (tabName === 'logs') {
     refreshLogs()
   }
+  if (tabName === 'history') {
+    loadHistory(historyPage)
+  }
 }
 
 // ========== 连接管理 ==========
@@ -278,6 +373,7 @@ async function disconnect() {
   }
 
   state.connected = false
+  state.status = 'disconnected'
   state.agentId = ''
   state.agentName = ''
   setConnecting(false)
@@ -288,24 +384,95 @@ async function checkConnectionStatus() {
   try {
     const status = await App.GetStatus()
     const wasConnected = state.connected
+    const wasStatus = state.status
     
     state.connected = status.connected
+    state.status = status.status
     state.agentId = status.agent_id || ''
     state.agentName = status.agent_name || ''
     
     if (wasConnected !== state.connected) {
       setConnecting(false)
       updateUI()
-      
-      if (wasConnected && !state.connected && state.config?.auto_reconnect && !state.reconnecting) {
-        scheduleReconnect()
-      }
+    }
+    
+    // 连接断开后先由客户端自动重连（reconnecting），客户端放弃后才由前端按重连间隔继续尝试
+    const lost = wasConnected || wasStatus === 'reconnecting'
+    if (lost && state.status === 'disconnected' && state.config?.auto_reconnect && !state.reconnecting) {
+      scheduleReconnect()
     }
   } catch (e) {
     console.error('检查连接状态失败:', e)
   }
 }
 
+// ========== 运行中的任务 ==========
+let runningTaskId = ''
+
+async function refreshRunningTask() {
+  try {
+    const tasks = await App.GetRunningTasks() || []
+    // 多个任务同时运行时显示最早开始的任务
+    const task = tasks[0]
+    runningTaskId = task ? task.task_id : ''
+    els.runningTask.classList.toggle('hidden', !task)
+    if (!task) return
+
+    const elapsed = Math.max(0, Math.floor((Date.now() - task.started_at) / 1000))
+    let info = `${task.task_type} · ${task.task_id} · ${elapsed}s`
+    if (task.current_step) {
+      info += ` · 步骤 ${task.completed_steps + 1}/${task.total_steps} ${task.current_step}`
+    }
+    if (tasks.length > 1) info += ` (共 ${tasks.length} 个)`
+    els.runningTaskInfo.textContent = info
+    els.runningTaskInfo.title = info
+    els.cancelTaskBtn.disabled = task.cancelled
+    els.cancelTaskBtn.textContent = task.cancelled ? '正在取消...' : '取消任务'
+  } catch (e) {
+    console.error('获取运行中任务失败:', e)
+  }
+}
+
+async function cancelRunningTask() {
+  if (!runningTaskId) return
+  els.cancelTaskBtn.disabled = true
+  try {
+    await App.CancelRunningTask(runningTaskId)
+  } catch (e) {
+    console.error('取消任务失败:', e)
+  }
+  refreshRunningTask()
+}
+
+// ========== 运行统计 ==========
+function formatDuration(seconds) {
+  const h = Math.floor(seconds / 3600)
+  const m = Math.floor(seconds % 3600 / 60)
+  if (h >= 24) return `${Math.floor(h / 24)}天${h % 24}小时`
+  if (h > 0) return `${h}小时${m}分`
+  return `${m}分${seconds % 60}秒`
+}
+
+async function refreshWorkerStats() {
+  try {
+    const stats = await App.GetWorkerStats()
+    const percent = v => stats.has_resources ? `${v.toFixed(1)}%` : '-'
+    els.statCpu.textContent = percent(stats.cpu_percent)
+    els.statMemory.textContent = percent(stats.memory_percent)
+    els.statDisk.textContent = percent(stats.disk_percent)
+    els.statUptime.textContent = formatDuration(stats.uptime_seconds)
+    els.statTasks.textContent = stats.tasks_failed > 0
+      ? `${stats.tasks_executed}（失败 ${stats.tasks_failed}）`
+      : `${stats.tasks_executed}`
+    els.statReconnects.textContent = `${stats.reconnects}`
+    els.statHeartbeat.textContent = stats.last_heartbeat
+      ? new Date(stats.last_heartbeat).toLocaleTimeString('zh-CN')
+      : '-'
+  } catch (e) {
+    console.error('获取运行统计失败:', e)
+  }
+}
+
 function scheduleReconnect() {
   if (state.reconnectTimer) {
     clearTimeout(state.reconnectTimer)
@@ -392,33 +559,229 @@ function hideError() {
 }
 
 // ========== 日志 ==========
+// 按序号增量拉取，只追加新日志；最多显示 MAX_LOG_ROWS 条
+const MAX_LOG_ROWS = 500
+let lastLogSeq = 0
+
 async function refreshLogs() {
   try {
-    const logs = await App.GetLogs(100)
+    const logs = await App.GetLogsSince(lastLogSeq)
 
     if (!logs || logs.length === 0) {
-      els.emptyLogs.classList.remove('hidden')
-      els.logList.innerHTML = ''
+      els.emptyLogs.classList.toggle('hidden', lastLogSeq > 0)
       return
     }
 
+    lastLogSeq = logs[logs.length - 1].seq
     els.emptyLogs.classList.add('hidden')
-    els.logList.innerHTML = logs
-      .map(
-        log => `
+    els.logList.insertAdjacentHTML(
+      'beforeend',
+      logs
+        .map(
+          log => `
       <div class="px-2 py-1 hover:bg-muted/50 rounded log-${log.level.toLowerCase()}">
-        <span class="text-muted-foreground">${log.timestamp}</span>
+        <span class="text-muted-foreground">${log.time}</span>
         <span class="mx-2 font-medium">[${log.level}]</span>
         <span>${log.message}</span>
       </div>
     `
-      )
-      .join('')
+        )
+        .join('')
+    )
+    while (els.logList.childElementCount > MAX_LOG_ROWS) {
+      els.logList.firstElementChild.remove()
+    }
   } catch (e) {
     console.error('获取日志失败:', e)
   }
 }
 
+// 清空后重新拉取缓冲区中的全部日志
+function reloadLogs() {
+  lastLogSeq = 0
+  els.logList.innerHTML = ''
+  return refreshLogs()
+}
+
+// ========== 执行历史 ==========
+let historyPage = 1
+
+const HISTORY_STATUS_CLASS = {
+  SUCCESS: 'text-emerald-600',
+  FAILED: 'text-destructive',
+  TIMEOUT: 'text-destructive',
+  CANCELLED: 'text-muted-foreground'
+}
+
+function escapeHtml(text) {
+  const div = document.createElement('div')
+  div.textContent = text ?? ''
+  return div.innerHTML
+}
+
+async function loadHistory(page) {
+  try {
+    const filter = { status: els.historyStatus.value, task_type: '', query: els.historyQuery.value.trim() }
+    const result = await App.GetTaskHistory(Math.max(page, 1), filter)
+    const pages = Math.max(1, Math.ceil(result.total / result.page_size))
+    historyPage = result.page
+    if (historyPage > pages && result.total > 0) return loadHistory(pages)
+
+    els.emptyHistory.classList.toggle('hidden', result.total > 0)
+    els.historyTotal.textContent = `共 ${result.total} 条`
+    els.historyPageInfo.textContent = `${historyPage} / ${pages}`
+    els.historyPrevBtn.disabled = historyPage <= 1
+    els.historyNextBtn.disabled = historyPage >= pages
+    els.historyList.innerHTML = (result.runs || []).map(renderHistoryRun).join('')
+    els.historyList.querySelectorAll('[data-step]').forEach(el => {
+      el.addEventListener('click', () => showStepDetails(el.dataset.step))
+    })
+  } catch (e) {
+    console.error('获取执行历史失败:', e)
+  }
+}
+
+function renderHistoryRun(run) {
+  const time = new Date(run.started_at).toLocaleString()
+  const steps = (run.steps || []).map(step => `
+      <div data-step="${escapeHtml(step.step_execution_id)}" class="flex items-center gap-2 pl-4 py-0.5 rounded cursor-pointer hover:bg-muted/50">
+        <span class="${HISTORY_STATUS_CLASS[step.status] || ''}">${escapeHtml(step.status)}</span>
+        <span>${escapeHtml(step.step_id || step.step_execution_id)}</span>
+        <span class="text-muted-foreground">${escapeHtml(step.action_type)} · ${step.duration_ms}ms</span>
+        ${step.has_screenshots ? '<i data-lucide="image" class="w-3 h-3 text-muted-foreground"></i>' : ''}
+        <span class="text-destructive truncate">${escapeHtml(step.error)}</span>
+      </div>`).join('')
+  return `
+    <details class="border rounded-md px-2 py-1.5">
+      <summary class="flex items-center gap-2 cursor-pointer">
+        <span class="font-medium ${HISTORY_STATUS_CLASS[run.status] || ''}">${escapeHtml(run.status)}</span>
+        <span class="truncate">${escapeHtml(run.task_type)} · ${escapeHtml(run.task_id)}</span>
+        <span class="ml-auto shrink-0 text-muted-foreground">${time} · ${(run.duration_ms / 1000).toFixed(1)}s</span>
+      </summary>
+      ${run.error ? `<div class="pl-4 py-0.5 text-destructive">${escapeHtml(run.error)}</div>` : ''}
+      ${steps}
+    </details>`
+}
+
+async function showStepDetails(stepExecutionId) {
+  let step
+  try {
+    step = await App.GetStepDetails(stepExecutionId)
+  } catch (e) {
+    console.error('获取步骤详情失败:', e)
+    return
+  }
+  const image = (title, src) => src ? `
+        <div class="flex-1 min-w-0">
+          <div class="text-xs text-muted-foreground mb-1">${title}</div>
+          <img src="${src}" class="w-full rounded border">
+        </div>` : ''
+  const modal = document.createElement('div')
+  modal.className = 'fixed inset-0 z-50 flex items-center justify-center bg-black/50'
+  modal.innerHTML = `
+    <div class="bg-card rounded-xl shadow-2xl max-w-3xl w-full mx-4 overflow-hidden animate-slide-up">
+      <div class="px-6 py-4 border-b flex items-center justify-between">
+        <h2 class="text-sm font-semibold">${escapeHtml(step.step_id || step.step_execution_id)}
+          <span class="ml-2 ${HISTORY_STATUS_CLASS[step.status] || ''}">${escapeHtml(step.status)}</span>
+        </h2>
+        <button onclick="this.closest('.fixed').remove()" class="text-muted-foreground hover:text-foreground">
+          <i data-lucide="x" class="w-4 h-4"></i>
+        </button>
+      </div>
+      <div class="px-6 py-4 space-y-3 text-xs">
+        <div class="text-muted-foreground">任务 ${escapeHtml(step.task_id)} · ${escapeHtml(step.action_type)} · ${step.duration_ms}ms</div>
+        ${step.error ? `<div class="text-destructive">${escapeHtml(step.failure_reason)} ${escapeHtml(step.error)}</div>` : ''}
+        <div class="flex gap-3">
+          ${image('执行前', step.screenshot_before)}
+          ${image('执行后', step.screenshot_after)}
+        </div>
+        ${step.has_screenshots && !step.screenshot_before && !step.screenshot_after ? '<div class="text-muted-foreground">截图已被清理</div>' : ''}
+      </div>
+    </div>
+  `
+  modal.addEventListener('click', e => {
+    if (e.target === modal) modal.remove()
+  })
+  document.body.appendChild(modal)
+  lucide.createIcons()
+}
+
+// ========== 定位测试 ==========
+function switchLocatorMode() {
+  const isText = els.locatorMode.value === 'text'
+  els.locatorTarget.placeholder = isText ? '要查找的文字' : '图像文件路径或 base64'
+  els.locatorImageOptions.classList.toggle('hidden', isText)
+  els.locatorTextOptions.classList.toggle('hidden', !isText)
+}
+
+// locatorRegion 读取文字查找区域，四项都未填写时为 null（全屏）
+function locatorRegion() {
+  const inputs = [els.locatorRegionX, els.locatorRegionY, els.locatorRegionW, els.locatorRegionH]
+  if (inputs.every(input => input.value === '')) return null
+  const [x, y, width, height] = inputs.map(input => parseInt(input.value) || 0)
+  return { x, y, width, height }
+}
+
+async function runLocator() {
+  const target = els.locatorTarget.value.trim()
+  if (!target) return
+  els.locatorFindBtn.disabled = true
+  try {
+    const result = els.locatorMode.value === 'text'
+      ? await App.TestFindText(target, locatorRegion())
+      : await App.TestFindImage(target, parseFloat(els.locatorThreshold.value) || 0)
+    const detail = result.found
+      ? `找到 (${result.x}, ${result.y}) · 置信度 ${result.confidence.toFixed(3)}${result.matched_text ? ` · ${escapeHtml(result.matched_text)}` : ''}`
+      : escapeHtml(result.message)
+    els.locatorResult.innerHTML = `
+      <span class="font-medium ${result.found ? 'text-emerald-600' : 'text-destructive'}">${result.found ? '已找到' : '未找到'}</span>
+      <span class="ml-2">${detail}</span>
+      <span class="ml-2 text-muted-foreground">${result.duration_ms}ms</span>`
+    els.locatorScreenshot.src = result.screenshot || ''
+    els.locatorScreenshot.classList.toggle('hidden', !result.screenshot)
+  } catch (e) {
+    els.locatorResult.innerHTML = `<span class="text-destructive">${escapeHtml(String(e?.message || e))}</span>`
+    els.locatorScreenshot.classList.add('hidden')
+  } finally {
+    els.locatorResult.classList.remove('hidden')
+    els.locatorFindBtn.disabled = false
+  }
+}
+
+// ========== 执行单步 ==========
+async function runAdhocStep() {
+  const taskType = els.adhocTaskType.value.trim()
+  if (!taskType) return
+  els.adhocRunBtn.disabled = true
+  els.adhocResult.innerHTML = '<span class="text-muted-foreground">执行中...</span>'
+  els.adhocResult.classList.remove('hidden')
+  els.adhocScreenshots.classList.add('hidden')
+  try {
+    const result = await App.RunAdhocStep(taskType, els.adhocPayload.value.trim() || '{}')
+    const success = result.status === 'SUCCESS'
+    const details = [
+      result.failure_reason,
+      result.error_message,
+      result.matched_text && `命中: ${result.matched_text}`,
+      result.confidence && `置信度 ${result.confidence.toFixed(3)}`
+    ].filter(Boolean).map(escapeHtml).join(' · ')
+    const output = [result.stdout, result.stderr].filter(Boolean).join('\n')
+    els.adhocResult.innerHTML = `
+      <span class="font-medium ${success ? 'text-emerald-600' : 'text-destructive'}">${escapeHtml(result.status)}</span>
+      <span class="ml-2">${details}</span>
+      <span class="ml-2 text-muted-foreground">${result.duration_ms}ms</span>
+      ${output ? `<pre class="mt-2 p-2 bg-muted rounded-md whitespace-pre-wrap font-mono">${escapeHtml(output)}</pre>` : ''}`
+    const after = result.screenshot_after || (result.screenshot_after_same_as_before ? result.screenshot_before : '')
+    els.adhocScreenshotBefore.src = result.screenshot_before || ''
+    els.adhocScreenshotAfter.src = after
+    els.adhocScreenshots.classList.toggle('hidden', !result.screenshot_before && !after)
+  } catch (e) {
+    els.adhocResult.innerHTML = `<span class="text-destructive">${escapeHtml(String(e?.message || e))}</span>`
+  } finally {
+    els.adhocRunBtn.disabled = false
+  }
+}
+
 // ========== 设置管理 ==========
 function loadSettingsToUI(config) {
   if (!config) return
@@ -426,9 +789,14 @@ function loadSettingsToUI(config) {
   els.settingAutoConnect.checked = config.auto_connect || false
   els.settingAutoReconnect.checked = config.auto_reconnect !== false
   els.settingReconnectInterval.value = config.reconnect_interval || 5
+  els.settingCACertFile.value = config.ca_cert_file || ''
+  els.settingClientCertFile.value = config.client_cert_file || ''
+  els.settingClientKeyFile.value = config.client_key_file || ''
+  els.settingInsecureSkipVerify.checked = config.insecure_skip_verify || false
   els.settingLogLevel.value = config.log_level || 'INFO'
   els.settingMinimizeToTray.checked = config.minimize_to_tray !== false
   els.settingStartMinimized.checked = config.start_minimized || false
+  els.settingAutoStart.checked = config.auto_start || false
 }
 
 async function saveSettings() {
@@ -440,9 +808,14 @@ async function saveSettings() {
       auto_connect: els.settingAutoConnect.checked,
       auto_reconnect: els.settingAutoReconnect.checked,
       reconnect_interval: parseInt(els.settingReconnectInterval.value) || 5,
+      ca_cert_file: els.settingCACertFile.value.trim(),
+      client_cert_file: els.settingClientCertFile.value.trim(),
+      client_key_file: els.settingClientKeyFile.value.trim(),
+      insecure_skip_verify: els.settingInsecureSkipVerify.checked,
       log_level: els.settingLogLevel.value,
       minimize_to_tray: els.settingMinimizeToTray.checked,
-      start_minimized: els.settingStartMinimized.checked
+      start_minimized: els.settingStartMinimized.checked,
+      auto_start: els.settingAutoStart.checked
     }
     
     await App.SaveConfig(config)
@@ -454,6 +827,18 @@ async function saveSettings() {
   }
 }
 
+async function setAutoStart() {
+  const enabled = els.settingAutoStart.checked
+  try {
+    await App.SetAutoStart(enabled)
+    if (state.config) state.config.auto_start = enabled
+    showSettingsSaved()
+  } catch (e) {
+    console.error('设置登录后自动启动失败:', e)
+    els.settingAutoStart.checked = !enabled
+  }
+}
+
 function showSettingsSaved() {
   els.settingsSaved.classList.remove('hidden')
   lucide.createIcons()
@@ -15,6 +15,15 @@ import { Call as $Call, CancellablePromise as $CancellablePromise, Create as $Cr
 // @ts-ignore: Unused imports
 import * as $models from "./models.js";
 
+/**
+ * CancelRunningTask 取消运行中的任务，服务端收到 CANCELLED 结果；任务不存在或已取消时返回 false
+ * @param {string} taskID
+ * @returns {$CancellablePromise<boolean>}
+ */
+export function CancelRunningTask(taskID) {
+    return $Call.ByName("main.App.CancelRunningTask", taskID);
+}
+
 /**
  * CheckPermissions 检查系统权限
  * @returns {$CancellablePromise<$models.PermissionsInfo>}
@@ -46,6 +55,14 @@ export function Disconnect() {
     return $Call.ByName("main.App.Disconnect");
 }
 
+/**
+ * GetAutoStart 获取系统中的自启动状态；与配置不一致（如在系统设置中被关闭）时更新配置
+ * @returns {$CancellablePromise<boolean>}
+ */
+export function GetAutoStart() {
+    return $Call.ByName("main.App.GetAutoStart");
+}
+
 /**
  * GetLogs 获取日志
  * @param {number} count
@@ -57,6 +74,17 @@ export function GetLogs(count) {
     }));
 }
 
+/**
+ * GetLogsSince 获取序号大于 seq 的新日志，前端记录最大的 seq 增量拉取（seq 为 0 时返回全部缓冲的日志）
+ * @param {number} seq
+ * @returns {$CancellablePromise<$models.LogEntry[]>}
+ */
+export function GetLogsSince(seq) {
+    return $Call.ByName("main.App.GetLogsSince", seq).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType3($result);
+    }));
+}
+
 /**
  * GetOCRPluginStatus 获取 OCR 插件状态
  * @returns {$CancellablePromise<$models.OCRPluginStatusResult>}
@@ -67,6 +95,27 @@ export function GetOCRPluginStatus() {
     }));
 }
 
+/**
+ * GetRunningTasks 获取运行中的任务（按开始时间排序）
+ * @returns {$CancellablePromise<$models.RunningTaskInfo[]>}
+ */
+export function GetRunningTasks() {
+    return $Call.ByName("main.App.GetRunningTasks").then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType9($result);
+    }));
+}
+
+/**
+ * GetStepDetails 获取步骤详情（含截图），截图文件已被清理时为空
+ * @param {string} stepExecutionID
+ * @returns {$CancellablePromise<$models.StepDetails>}
+ */
+export function GetStepDetails(stepExecutionID) {
+    return $Call.ByName("main.App.GetStepDetails", stepExecutionID).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType10($result);
+    }));
+}
+
 /**
  * GetStatus 获取连接状态
  * @returns {$CancellablePromise<$models.StatusResult>}
@@ -87,6 +136,28 @@ export function GetSystemInfo() {
     }));
 }
 
+/**
+ * GetTaskHistory 分页查询本地执行历史（page 从 1 开始，每页 history.DefaultPageSize 条）
+ * @param {number} page
+ * @param {$models.HistoryFilter} filter
+ * @returns {$CancellablePromise<$models.HistoryPage>}
+ */
+export function GetTaskHistory(page, filter) {
+    return $Call.ByName("main.App.GetTaskHistory", page, filter).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType11($result);
+    }));
+}
+
+/**
+ * GetWorkerStats 获取运行统计（前端定时轮询）
+ * @returns {$CancellablePromise<$models.WorkerStats>}
+ */
+export function GetWorkerStats() {
+    return $Call.ByName("main.App.GetWorkerStats").then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType13($result);
+    }));
+}
+
 /**
  * HideWindow 隐藏窗口
  * @returns {$CancellablePromise<void>}
@@ -137,6 +208,19 @@ export function QuitApp() {
     return $Call.ByName("main.App.QuitApp");
 }
 
+/**
+ * RunAdhocStep 在本地执行单个步骤（payloadJSON 同服务端下发的任务参数），不发送任何消息到服务端，
+ * 记录到本地执行历史；有任务正在执行时返回错误，未连接服务端时也可使用
+ * @param {string} taskType
+ * @param {string} payloadJSON
+ * @returns {$CancellablePromise<$models.AdhocStepResult>}
+ */
+export function RunAdhocStep(taskType, payloadJSON) {
+    return $Call.ByName("main.App.RunAdhocStep", taskType, payloadJSON).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType14($result);
+    }));
+}
+
 /**
  * SaveConfig 保存配置
  * @param {$models.ConfigData} data
@@ -146,6 +230,15 @@ export function SaveConfig(data) {
     return $Call.ByName("main.App.SaveConfig", data);
 }
 
+/**
+ * SetAutoStart 启用或关闭登录后自动启动（写入系统的自启动项），成功后写入配置
+ * @param {boolean} enabled
+ * @returns {$CancellablePromise<void>}
+ */
+export function SetAutoStart(enabled) {
+    return $Call.ByName("main.App.SetAutoStart", enabled);
+}
+
 /**
  * ShowWindow 显示窗口
  * @returns {$CancellablePromise<void>}
@@ -154,6 +247,31 @@ export function ShowWindow() {
     return $Call.ByName("main.App.ShowWindow");
 }
 
+/**
+ * TestFindImage 在当前屏幕查找图像（文件路径或 base64），threshold 为 0 时使用默认阈值
+ * 只截图不点击，未连接服务端时也可使用
+ * @param {string} source
+ * @param {number} threshold
+ * @returns {$CancellablePromise<$models.LocatorResult>}
+ */
+export function TestFindImage(source, threshold) {
+    return $Call.ByName("main.App.TestFindImage", source, threshold).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType12($result);
+    }));
+}
+
+/**
+ * TestFindText 在当前屏幕（region 为 null 时全屏）OCR 查找文字，只截图不点击，未连接服务端时也可使用
+ * @param {string} target
+ * @param {$models.LocatorRegion | null} region
+ * @returns {$CancellablePromise<$models.LocatorResult>}
+ */
+export function TestFindText(target, region) {
+    return $Call.ByName("main.App.TestFindText", target, region).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType12($result);
+    }));
+}
+
 /**
  * UninstallOCRPlugin 卸载 OCR 插件
  * @returns {$CancellablePromise<void>}
@@ -171,3 +289,10 @@ const $$createType4 = $models.OCRPluginStatusResult.createFrom;
 const $$createType5 = $models.StatusResult.createFrom;
 const $$createType6 = $models.SystemInfo.createFrom;
 const $$createType7 = $models.ConfigData.createFrom;
+const $$createType8 = $models.RunningTaskInfo.createFrom;
+const $$createType9 = $Create.Array($$createType8);
+const $$createType10 = $models.StepDetails.createFrom;
+const $$createType11 = $models.HistoryPage.createFrom;
+const $$createType12 = $models.LocatorResult.createFrom;
+const $$createType13 = $models.WorkerStats.createFrom;
+const $$createType14 = $models.AdhocStepResult.createFrom;
@@ -8,11 +8,21 @@ export {
 };
 
 export {
+    AdhocStepResult,
     ConfigData,
     ConnectResult,
+    HistoryFilter,
+    HistoryPage,
+    HistoryRun,
+    HistoryStep,
+    LocatorRegion,
+    LocatorResult,
     LogEntry,
     OCRPluginStatusResult,
     PermissionsInfo,
+    RunningTaskInfo,
     StatusResult,
-    SystemInfo
+    StepDetails,
+    SystemInfo,
+    WorkerStats
 } from "./models.js";
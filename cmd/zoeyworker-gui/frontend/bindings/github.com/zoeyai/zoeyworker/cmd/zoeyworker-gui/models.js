@@ -6,6 +6,137 @@
 // @ts-ignore: Unused imports
 import { Create as $Create } from "@wailsio/runtime";
 
+/**
+ * AdhocStepResult 临时步骤的执行结果（同服务端收到的步骤结果）
+ */
+export class AdhocStepResult {
+    /**
+     * Creates a new AdhocStepResult instance.
+     * @param {Partial<AdhocStepResult>} [$$source = {}] - The source object to create the AdhocStepResult.
+     */
+    constructor($$source = {}) {
+        if (!("status" in $$source)) {
+            /**
+             * SUCCESS, FAILED, CANCELLED
+             * @member
+             * @type {string}
+             */
+            this["status"] = "";
+        }
+        if (!("action_type" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["action_type"] = "";
+        }
+        if (!("duration_ms" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["duration_ms"] = 0;
+        }
+        if (!("error_message" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["error_message"] = "";
+        }
+        if (!("failure_reason" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["failure_reason"] = "";
+        }
+        if (!("screenshot_before" in $$source)) {
+            /**
+             * data URL
+             * @member
+             * @type {string}
+             */
+            this["screenshot_before"] = "";
+        }
+        if (!("screenshot_after" in $$source)) {
+            /**
+             * 画面未变化时为空，此时 ScreenshotAfterSameAsBefore 为 true
+             * @member
+             * @type {string}
+             */
+            this["screenshot_after"] = "";
+        }
+        if (!("screenshot_after_same_as_before" in $$source)) {
+            /**
+             * 执行后画面与执行前相同
+             * @member
+             * @type {boolean}
+             */
+            this["screenshot_after_same_as_before"] = false;
+        }
+        if (!("target_bounds" in $$source)) {
+            /**
+             * 截图像素坐标
+             * @member
+             * @type {LocatorRegion | null}
+             */
+            this["target_bounds"] = null;
+        }
+        if (!("confidence" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["confidence"] = 0;
+        }
+        if (!("matched_text" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["matched_text"] = "";
+        }
+        if (!("stdout" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["stdout"] = "";
+        }
+        if (!("stderr" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["stderr"] = "";
+        }
+        if (!("exit_code" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["exit_code"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new AdhocStepResult instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {AdhocStepResult}
+     */
+    static createFrom($$source = {}) {
+        const $$createField8_0 = $$createType5;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("target_bounds" in $$parsedSource) {
+            $$parsedSource["target_bounds"] = $$createField8_0($$parsedSource["target_bounds"]);
+        }
+        return new AdhocStepResult(/** @type {Partial<AdhocStepResult>} */($$parsedSource));
+    }
+}
+
 /**
  * ConfigData 配置数据
  */
@@ -44,6 +175,35 @@ export class ConfigData {
              */
             this["auto_connect"] = false;
         }
+        if (!("ca_cert_file" in $$source)) {
+            /**
+             * TLS 设置
+             * @member
+             * @type {string}
+             */
+            this["ca_cert_file"] = "";
+        }
+        if (!("client_cert_file" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["client_cert_file"] = "";
+        }
+        if (!("client_key_file" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["client_key_file"] = "";
+        }
+        if (!("insecure_skip_verify" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["insecure_skip_verify"] = false;
+        }
         if (!("auto_reconnect" in $$source)) {
             /**
              * 重连设置
@@ -83,6 +243,14 @@ export class ConfigData {
              */
             this["start_minimized"] = false;
         }
+        if (!("auto_start" in $$source)) {
+            /**
+             * 登录后自动启动（读取时为系统中的实际状态）
+             * @member
+             * @type {boolean}
+             */
+            this["auto_start"] = false;
+        }
 
         Object.assign(this, $$source);
     }
@@ -151,194 +319,817 @@ export class ConnectResult {
 }
 
 /**
- * LogEntry 日志条目
+ * HistoryFilter 执行历史查询条件，空字段不过滤
  */
-export class LogEntry {
+export class HistoryFilter {
     /**
-     * Creates a new LogEntry instance.
-     * @param {Partial<LogEntry>} [$$source = {}] - The source object to create the LogEntry.
+     * Creates a new HistoryFilter instance.
+     * @param {Partial<HistoryFilter>} [$$source = {}] - The source object to create the HistoryFilter.
      */
     constructor($$source = {}) {
-        if (!("time" in $$source)) {
+        if (!("status" in $$source)) {
             /**
+             * SUCCESS、FAILED、CANCELLED ...
              * @member
              * @type {string}
              */
-            this["time"] = "";
+            this["status"] = "";
         }
-        if (!("level" in $$source)) {
+        if (!("task_type" in $$source)) {
             /**
+             * 任务类型
              * @member
              * @type {string}
              */
-            this["level"] = "";
+            this["task_type"] = "";
         }
-        if (!("message" in $$source)) {
+        if (!("query" in $$source)) {
             /**
+             * 任务 ID 或错误信息包含的文字
              * @member
              * @type {string}
              */
-            this["message"] = "";
+            this["query"] = "";
         }
 
         Object.assign(this, $$source);
     }
 
     /**
-     * Creates a new LogEntry instance from a string or object.
+     * Creates a new HistoryFilter instance from a string or object.
      * @param {any} [$$source = {}]
-     * @returns {LogEntry}
+     * @returns {HistoryFilter}
      */
     static createFrom($$source = {}) {
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        return new LogEntry(/** @type {Partial<LogEntry>} */($$parsedSource));
+        return new HistoryFilter(/** @type {Partial<HistoryFilter>} */($$parsedSource));
     }
 }
 
 /**
- * OCRPluginStatusResult OCR 插件状态
+ * HistoryPage 一页执行历史（从新到旧）
  */
-export class OCRPluginStatusResult {
+export class HistoryPage {
     /**
-     * Creates a new OCRPluginStatusResult instance.
-     * @param {Partial<OCRPluginStatusResult>} [$$source = {}] - The source object to create the OCRPluginStatusResult.
+     * Creates a new HistoryPage instance.
+     * @param {Partial<HistoryPage>} [$$source = {}] - The source object to create the HistoryPage.
      */
     constructor($$source = {}) {
-        if (!("installed" in $$source)) {
+        if (!("runs" in $$source)) {
             /**
              * @member
-             * @type {boolean}
+             * @type {HistoryRun[]}
              */
-            this["installed"] = false;
+            this["runs"] = [];
+        }
+        if (!("total" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["total"] = 0;
+        }
+        if (!("page" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["page"] = 0;
+        }
+        if (!("page_size" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["page_size"] = 0;
         }
 
         Object.assign(this, $$source);
     }
 
     /**
-     * Creates a new OCRPluginStatusResult instance from a string or object.
+     * Creates a new HistoryPage instance from a string or object.
      * @param {any} [$$source = {}]
-     * @returns {OCRPluginStatusResult}
+     * @returns {HistoryPage}
      */
     static createFrom($$source = {}) {
+        const $$createField0_0 = $$createType1;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        return new OCRPluginStatusResult(/** @type {Partial<OCRPluginStatusResult>} */($$parsedSource));
+        if ("runs" in $$parsedSource) {
+            $$parsedSource["runs"] = $$createField0_0($$parsedSource["runs"]);
+        }
+        return new HistoryPage(/** @type {Partial<HistoryPage>} */($$parsedSource));
     }
 }
 
 /**
- * PermissionsInfo 权限信息
+ * HistoryRun 一次任务执行
  */
-export class PermissionsInfo {
+export class HistoryRun {
     /**
-     * Creates a new PermissionsInfo instance.
-     * @param {Partial<PermissionsInfo>} [$$source = {}] - The source object to create the PermissionsInfo.
+     * Creates a new HistoryRun instance.
+     * @param {Partial<HistoryRun>} [$$source = {}] - The source object to create the HistoryRun.
      */
     constructor($$source = {}) {
-        if (!("accessibility" in $$source)) {
+        if (!("task_id" in $$source)) {
             /**
              * @member
-             * @type {boolean}
+             * @type {string}
              */
-            this["accessibility"] = false;
+            this["task_id"] = "";
         }
-        if (!("screen_recording" in $$source)) {
+        if (!("task_type" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["task_type"] = "";
+        }
+        if (!("status" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["status"] = "";
+        }
+        if (!("success" in $$source)) {
             /**
              * @member
              * @type {boolean}
              */
-            this["screen_recording"] = false;
+            this["success"] = false;
+        }
+        if (!("failure_reason" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["failure_reason"] = "";
+        }
+        if (!("error" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["error"] = "";
+        }
+        if (!("started_at" in $$source)) {
+            /**
+             * 开始时间（毫秒时间戳）
+             * @member
+             * @type {number}
+             */
+            this["started_at"] = 0;
+        }
+        if (!("duration_ms" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["duration_ms"] = 0;
+        }
+        if (!("steps" in $$source)) {
+            /**
+             * @member
+             * @type {HistoryStep[]}
+             */
+            this["steps"] = [];
         }
 
         Object.assign(this, $$source);
     }
 
     /**
-     * Creates a new PermissionsInfo instance from a string or object.
+     * Creates a new HistoryRun instance from a string or object.
      * @param {any} [$$source = {}]
-     * @returns {PermissionsInfo}
+     * @returns {HistoryRun}
      */
     static createFrom($$source = {}) {
+        const $$createField0_0 = $$createType3;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        return new PermissionsInfo(/** @type {Partial<PermissionsInfo>} */($$parsedSource));
+        if ("steps" in $$parsedSource) {
+            $$parsedSource["steps"] = $$createField0_0($$parsedSource["steps"]);
+        }
+        return new HistoryRun(/** @type {Partial<HistoryRun>} */($$parsedSource));
     }
 }
 
 /**
- * StatusResult 状态结果
+ * HistoryStep 执行历史中的步骤
  */
-export class StatusResult {
+export class HistoryStep {
     /**
-     * Creates a new StatusResult instance.
-     * @param {Partial<StatusResult>} [$$source = {}] - The source object to create the StatusResult.
+     * Creates a new HistoryStep instance.
+     * @param {Partial<HistoryStep>} [$$source = {}] - The source object to create the HistoryStep.
      */
     constructor($$source = {}) {
-        if (!("connected" in $$source)) {
+        if (!("step_execution_id" in $$source)) {
             /**
+             * GetStepDetails 的参数
              * @member
-             * @type {boolean}
+             * @type {string}
              */
-            this["connected"] = false;
+            this["step_execution_id"] = "";
         }
-        if (!("agent_id" in $$source)) {
+        if (!("step_id" in $$source)) {
             /**
              * @member
              * @type {string}
              */
-            this["agent_id"] = "";
+            this["step_id"] = "";
         }
-        if (!("agent_name" in $$source)) {
+        if (!("action_type" in $$source)) {
             /**
              * @member
              * @type {string}
              */
-            this["agent_name"] = "";
+            this["action_type"] = "";
+        }
+        if (!("status" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["status"] = "";
+        }
+        if (!("failure_reason" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["failure_reason"] = "";
+        }
+        if (!("error" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["error"] = "";
+        }
+        if (!("duration_ms" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["duration_ms"] = 0;
+        }
+        if (!("has_screenshots" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["has_screenshots"] = false;
         }
 
         Object.assign(this, $$source);
     }
 
     /**
-     * Creates a new StatusResult instance from a string or object.
+     * Creates a new HistoryStep instance from a string or object.
      * @param {any} [$$source = {}]
-     * @returns {StatusResult}
+     * @returns {HistoryStep}
      */
     static createFrom($$source = {}) {
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        return new StatusResult(/** @type {Partial<StatusResult>} */($$parsedSource));
+        return new HistoryStep(/** @type {Partial<HistoryStep>} */($$parsedSource));
     }
 }
 
 /**
- * SystemInfo 系统信息
+ * LocatorRegion 文字查找的屏幕区域
  */
-export class SystemInfo {
+export class LocatorRegion {
     /**
-     * Creates a new SystemInfo instance.
-     * @param {Partial<SystemInfo>} [$$source = {}] - The source object to create the SystemInfo.
+     * Creates a new LocatorRegion instance.
+     * @param {Partial<LocatorRegion>} [$$source = {}] - The source object to create the LocatorRegion.
      */
     constructor($$source = {}) {
-        if (!("platform" in $$source)) {
+        if (!("x" in $$source)) {
             /**
              * @member
-             * @type {string}
+             * @type {number}
              */
-            this["platform"] = "";
+            this["x"] = 0;
         }
-        if (!("hostname" in $$source)) {
+        if (!("y" in $$source)) {
             /**
              * @member
-             * @type {string}
+             * @type {number}
              */
-            this["hostname"] = "";
+            this["y"] = 0;
         }
-        if (!("arch" in $$source)) {
+        if (!("width" in $$source)) {
             /**
              * @member
-             * @type {string}
+             * @type {number}
+             */
+            this["width"] = 0;
+        }
+        if (!("height" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["height"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new LocatorRegion instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {LocatorRegion}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new LocatorRegion(/** @type {Partial<LocatorRegion>} */($$parsedSource));
+    }
+}
+
+/**
+ * LocatorResult 定位测试结果
+ */
+export class LocatorResult {
+    /**
+     * Creates a new LocatorResult instance.
+     * @param {Partial<LocatorResult>} [$$source = {}] - The source object to create the LocatorResult.
+     */
+    constructor($$source = {}) {
+        if (!("found" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["found"] = false;
+        }
+        if (!("x" in $$source)) {
+            /**
+             * 目标中心（屏幕坐标）
+             * @member
+             * @type {number}
+             */
+            this["x"] = 0;
+        }
+        if (!("y" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["y"] = 0;
+        }
+        if (!("bounds" in $$source)) {
+            /**
+             * @member
+             * @type {LocatorRegion | null}
+             */
+            this["bounds"] = null;
+        }
+        if (!("confidence" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["confidence"] = 0;
+        }
+        if (!("matched_text" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["matched_text"] = "";
+        }
+        if (!("duration_ms" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["duration_ms"] = 0;
+        }
+        if (!("message" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["message"] = "";
+        }
+        if (!("screenshot" in $$source)) {
+            /**
+             * 标注了查找结果的截图（data URL）
+             * @member
+             * @type {string}
+             */
+            this["screenshot"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new LocatorResult instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {LocatorResult}
+     */
+    static createFrom($$source = {}) {
+        const $$createField3_0 = $$createType5;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("bounds" in $$parsedSource) {
+            $$parsedSource["bounds"] = $$createField3_0($$parsedSource["bounds"]);
+        }
+        return new LocatorResult(/** @type {Partial<LocatorResult>} */($$parsedSource));
+    }
+}
+
+/**
+ * LogEntry 日志条目
+ */
+export class LogEntry {
+    /**
+     * Creates a new LogEntry instance.
+     * @param {Partial<LogEntry>} [$$source = {}] - The source object to create the LogEntry.
+     */
+    constructor($$source = {}) {
+        if (!("seq" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["seq"] = 0;
+        }
+        if (!("time" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["time"] = "";
+        }
+        if (!("level" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["level"] = "";
+        }
+        if (!("message" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["message"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new LogEntry instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {LogEntry}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new LogEntry(/** @type {Partial<LogEntry>} */($$parsedSource));
+    }
+}
+
+/**
+ * OCRPluginStatusResult OCR 插件状态
+ */
+export class OCRPluginStatusResult {
+    /**
+     * Creates a new OCRPluginStatusResult instance.
+     * @param {Partial<OCRPluginStatusResult>} [$$source = {}] - The source object to create the OCRPluginStatusResult.
+     */
+    constructor($$source = {}) {
+        if (!("installed" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["installed"] = false;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new OCRPluginStatusResult instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {OCRPluginStatusResult}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new OCRPluginStatusResult(/** @type {Partial<OCRPluginStatusResult>} */($$parsedSource));
+    }
+}
+
+/**
+ * PermissionsInfo 权限信息
+ */
+export class PermissionsInfo {
+    /**
+     * Creates a new PermissionsInfo instance.
+     * @param {Partial<PermissionsInfo>} [$$source = {}] - The source object to create the PermissionsInfo.
+     */
+    constructor($$source = {}) {
+        if (!("accessibility" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["accessibility"] = false;
+        }
+        if (!("screen_recording" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["screen_recording"] = false;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new PermissionsInfo instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {PermissionsInfo}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new PermissionsInfo(/** @type {Partial<PermissionsInfo>} */($$parsedSource));
+    }
+}
+
+/**
+ * RunningTaskInfo 运行中的任务
+ */
+export class RunningTaskInfo {
+    /**
+     * Creates a new RunningTaskInfo instance.
+     * @param {Partial<RunningTaskInfo>} [$$source = {}] - The source object to create the RunningTaskInfo.
+     */
+    constructor($$source = {}) {
+        if (!("task_id" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["task_id"] = "";
+        }
+        if (!("task_type" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["task_type"] = "";
+        }
+        if (!("started_at" in $$source)) {
+            /**
+             * 开始时间（毫秒时间戳）
+             * @member
+             * @type {number}
+             */
+            this["started_at"] = 0;
+        }
+        if (!("current_step" in $$source)) {
+            /**
+             * 批量任务的当前步骤
+             * @member
+             * @type {string}
+             */
+            this["current_step"] = "";
+        }
+        if (!("total_steps" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["total_steps"] = 0;
+        }
+        if (!("completed_steps" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["completed_steps"] = 0;
+        }
+        if (!("cancelled" in $$source)) {
+            /**
+             * 已请求取消，等待当前步骤结束
+             * @member
+             * @type {boolean}
+             */
+            this["cancelled"] = false;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new RunningTaskInfo instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {RunningTaskInfo}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new RunningTaskInfo(/** @type {Partial<RunningTaskInfo>} */($$parsedSource));
+    }
+}
+
+/**
+ * StatusResult 状态结果
+ */
+export class StatusResult {
+    /**
+     * Creates a new StatusResult instance.
+     * @param {Partial<StatusResult>} [$$source = {}] - The source object to create the StatusResult.
+     */
+    constructor($$source = {}) {
+        if (!("connected" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["connected"] = false;
+        }
+        if (!("agent_id" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["agent_id"] = "";
+        }
+        if (!("agent_name" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["agent_name"] = "";
+        }
+        if (!("status" in $$source)) {
+            /**
+             * Status 连接状态机的状态（reconnecting 时由客户端自动重连，前端无需重连）
+             * @member
+             * @type {string}
+             */
+            this["status"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new StatusResult instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {StatusResult}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new StatusResult(/** @type {Partial<StatusResult>} */($$parsedSource));
+    }
+}
+
+/**
+ * StepDetails 步骤详情，截图从磁盘读取为 data URL
+ */
+export class StepDetails {
+    /**
+     * Creates a new StepDetails instance.
+     * @param {Partial<StepDetails>} [$$source = {}] - The source object to create the StepDetails.
+     */
+    constructor($$source = {}) {
+        if (!("step_execution_id" in $$source)) {
+            /**
+             * GetStepDetails 的参数
+             * @member
+             * @type {string}
+             */
+            this["step_execution_id"] = "";
+        }
+        if (!("step_id" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["step_id"] = "";
+        }
+        if (!("action_type" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["action_type"] = "";
+        }
+        if (!("status" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["status"] = "";
+        }
+        if (!("failure_reason" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["failure_reason"] = "";
+        }
+        if (!("error" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["error"] = "";
+        }
+        if (!("duration_ms" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["duration_ms"] = 0;
+        }
+        if (!("has_screenshots" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["has_screenshots"] = false;
+        }
+        if (!("task_id" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["task_id"] = "";
+        }
+        if (!("screenshot_before" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["screenshot_before"] = "";
+        }
+        if (!("screenshot_after" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["screenshot_after"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new StepDetails instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {StepDetails}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new StepDetails(/** @type {Partial<StepDetails>} */($$parsedSource));
+    }
+}
+
+/**
+ * SystemInfo 系统信息
+ */
+export class SystemInfo {
+    /**
+     * Creates a new SystemInfo instance.
+     * @param {Partial<SystemInfo>} [$$source = {}] - The source object to create the SystemInfo.
+     */
+    constructor($$source = {}) {
+        if (!("platform" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["platform"] = "";
+        }
+        if (!("hostname" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["hostname"] = "";
+        }
+        if (!("arch" in $$source)) {
+            /**
+             * @member
+             * @type {string}
              */
             this["arch"] = "";
         }
+        if (!("client_id" in $$source)) {
+            /**
+             * 客户端标识（重启后不变）
+             * @member
+             * @type {string}
+             */
+            this["client_id"] = "";
+        }
 
         Object.assign(this, $$source);
     }
@@ -353,3 +1144,102 @@ export class SystemInfo {
         return new SystemInfo(/** @type {Partial<SystemInfo>} */($$parsedSource));
     }
 }
+
+/**
+ * WorkerStats 运行统计
+ */
+export class WorkerStats {
+    /**
+     * Creates a new WorkerStats instance.
+     * @param {Partial<WorkerStats>} [$$source = {}] - The source object to create the WorkerStats.
+     */
+    constructor($$source = {}) {
+        if (!("has_resources" in $$source)) {
+            /**
+             * 资源使用率与心跳上报的相同（连接后按心跳间隔采样），尚未采样时 HasResources 为 false
+             * @member
+             * @type {boolean}
+             */
+            this["has_resources"] = false;
+        }
+        if (!("cpu_percent" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["cpu_percent"] = 0;
+        }
+        if (!("memory_percent" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["memory_percent"] = 0;
+        }
+        if (!("disk_percent" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["disk_percent"] = 0;
+        }
+        if (!("uptime_seconds" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["uptime_seconds"] = 0;
+        }
+        if (!("tasks_executed" in $$source)) {
+            /**
+             * 启动以来执行完成的任务数
+             * @member
+             * @type {number}
+             */
+            this["tasks_executed"] = 0;
+        }
+        if (!("tasks_failed" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["tasks_failed"] = 0;
+        }
+        if (!("reconnects" in $$source)) {
+            /**
+             * 连接异常断开后重连成功的次数
+             * @member
+             * @type {number}
+             */
+            this["reconnects"] = 0;
+        }
+        if (!("last_heartbeat" in $$source)) {
+            /**
+             * 最近一次心跳时间（毫秒时间戳），尚未发送时为 0
+             * @member
+             * @type {number}
+             */
+            this["last_heartbeat"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new WorkerStats instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {WorkerStats}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new WorkerStats(/** @type {Partial<WorkerStats>} */($$parsedSource));
+    }
+}
+
+// Private type creation functions
+const $$createType0 = HistoryRun.createFrom;
+const $$createType1 = $Create.Array($$createType0);
+const $$createType2 = HistoryStep.createFrom;
+const $$createType3 = $Create.Array($$createType2);
+const $$createType4 = LocatorRegion.createFrom;
+const $$createType5 = $Create.Nullable($$createType4);
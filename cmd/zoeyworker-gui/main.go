@@ -2,13 +2,16 @@ package main
 
 import (
 	"embed"
+	"fmt"
 	"io/fs"
 	"log"
 	"net/http"
 	"runtime"
+	"time"
 
 	"github.com/wailsapp/wails/v3/pkg/application"
 	"github.com/wailsapp/wails/v3/pkg/events"
+	"github.com/zoeyai/zoeyworker/pkg/grpc"
 )
 
 //go:embed all:frontend/dist
@@ -26,6 +29,12 @@ var appIcon []byte
 //go:embed build/trayicon.png
 var trayIcon []byte
 
+//go:embed build/trayicon_disconnected.png
+var trayIconDisconnected []byte
+
+//go:embed build/trayicon_busy.png
+var trayIconBusy []byte
+
 var (
 	mainApp    *application.App
 	mainWindow *application.WebviewWindow
@@ -42,6 +51,7 @@ func main() {
 		Description: "UI 自动化执行客户端",
 		Icon:        appIcon, // 应用图标（用于任务栏、关于窗口等）
 		Services: []application.Service{
+			application.NewService(appService.notifier),
 			application.NewService(appService),
 		},
 		Assets: application.AssetOptions{
@@ -52,6 +62,10 @@ func main() {
 		},
 	})
 
+	// 启动时读取界面设置：开启"启动时最小化"时窗口保持隐藏，只显示托盘图标
+	settings := appService.LoadConfig()
+	appService.minimizeToTray.Store(settings.MinimizeToTray)
+
 	// 创建主窗口
 	mainWindow = mainApp.Window.NewWithOptions(application.WebviewWindowOptions{
 		Title:            "Zoey Worker",
@@ -61,17 +75,22 @@ func main() {
 		MinHeight:        500,
 		BackgroundColour: application.NewRGB(255, 255, 255),
 		URL:              "/",
-		Hidden:           false,
+		Hidden:           settings.StartMinimized,
 		Windows: application.WindowsWindow{
 			HiddenOnTaskbar: false,
 		},
 	})
 
 	// 使用 Hook 拦截窗口关闭事件（Hook 比 OnWindowEvent 更早执行）
-	// 点击关闭按钮时隐藏到托盘而不是退出
+	// 开启"最小化到托盘"时点击关闭按钮隐藏到托盘，否则与托盘菜单"退出"相同：断开连接后退出
 	mainWindow.RegisterHook(events.Common.WindowClosing, func(e *application.WindowEvent) {
-		e.Cancel()        // 阻止关闭
-		mainWindow.Hide() // 隐藏到托盘
+		e.Cancel() // 阻止关闭
+		if appService.minimizeToTray.Load() {
+			mainWindow.Hide() // 隐藏到托盘
+			return
+		}
+		appService.Disconnect()
+		mainApp.Quit()
 	})
 
 	// 设置系统托盘
@@ -89,12 +108,8 @@ func setupSystemTray(app *application.App, window *application.WebviewWindow, sv
 	// 创建系统托盘
 	tray := app.SystemTray.New()
 
-	// 设置图标（macOS 使用 22x22 模板图标，Windows 使用完整图标）
-	if runtime.GOOS == "darwin" {
-		tray.SetTemplateIcon(trayIcon)
-	} else {
-		tray.SetIcon(appIcon)
-	}
+	// 设置图标（状态变化时由 watchTrayState 更新）
+	setTrayIcon(tray, trayIconDisconnected)
 	tray.SetTooltip("Zoey Worker - UI 自动化执行客户端")
 
 	// 点击托盘图标显示/隐藏窗口
@@ -134,6 +149,15 @@ func setupSystemTray(app *application.App, window *application.WebviewWindow, sv
 
 	trayMenu.AddSeparator()
 
+	// 取消当前任务（仅有任务运行时可用）
+	cancelItem := trayMenu.Add("取消当前任务")
+	cancelItem.SetEnabled(false)
+	cancelItem.OnClick(func(ctx *application.Context) {
+		svc.cancelCurrentTask()
+	})
+
+	trayMenu.AddSeparator()
+
 	// 退出
 	trayMenu.Add("退出").OnClick(func(ctx *application.Context) {
 		// 断开连接后退出
@@ -142,4 +166,106 @@ func setupSystemTray(app *application.App, window *application.WebviewWindow, sv
 	})
 
 	tray.SetMenu(trayMenu)
+
+	go watchTrayState(tray, trayMenu, trayItems{connect: connectItem, disconnect: disconnectItem, cancel: cancelItem}, svc)
+}
+
+// setTrayIcon 设置托盘图标（macOS 使用 22x22 模板图标，随系统明暗切换）
+func setTrayIcon(tray *application.SystemTray, icon []byte) {
+	if runtime.GOOS == "darwin" {
+		tray.SetTemplateIcon(icon)
+	} else {
+		tray.SetIcon(icon)
+	}
+}
+
+// trayItems 随状态启用 / 禁用的托盘菜单项
+type trayItems struct {
+	connect    *application.MenuItem
+	disconnect *application.MenuItem
+	cancel     *application.MenuItem
+}
+
+// trayState 托盘显示的状态（可比较，变化时才更新托盘）
+type trayState struct {
+	status    string // 连接状态机的状态
+	agentName string
+	task      string // 当前任务的描述，空表示空闲
+	canCancel bool
+}
+
+// currentTrayState 读取当前连接状态和运行中的任务（有多个任务时显示最早开始的）
+func currentTrayState(svc *App) trayState {
+	status := svc.GetStatus()
+	state := trayState{status: status.Status, agentName: status.AgentName}
+	if tasks := svc.GetRunningTasks(); len(tasks) > 0 {
+		t := tasks[0]
+		state.task = t.TaskType
+		if t.TotalSteps > 0 {
+			state.task += fmt.Sprintf(" (%d/%d)", t.CompletedSteps, t.TotalSteps)
+		}
+		if t.Cancelled {
+			state.task += " 取消中"
+		}
+		state.canCancel = !t.Cancelled
+	}
+	return state
+}
+
+// trayStatusText 连接状态的显示文字
+var trayStatusText = map[string]string{
+	string(grpc.StatusDisconnected): "未连接",
+	string(grpc.StatusConnecting):   "连接中",
+	string(grpc.StatusConnected):    "已连接",
+	string(grpc.StatusReconnecting): "重连中",
+	string(grpc.StatusStopping):     "断开中",
+}
+
+// applyTrayState 按状态更新托盘图标、提示文字和菜单项：执行任务时为忙碌图标，未连接（含重连中）时为灰色图标
+func applyTrayState(tray *application.SystemTray, menu *application.Menu, items trayItems, state trayState) {
+	connected := state.status == string(grpc.StatusConnected)
+	switch {
+	case state.task != "":
+		setTrayIcon(tray, trayIconBusy)
+	case connected && runtime.GOOS == "darwin":
+		setTrayIcon(tray, trayIcon)
+	case connected:
+		setTrayIcon(tray, appIcon) // Windows / Linux 连接且空闲时使用完整图标
+	default:
+		setTrayIcon(tray, trayIconDisconnected)
+	}
+
+	tooltip := "Zoey Worker"
+	if state.agentName != "" {
+		tooltip += " - " + state.agentName
+	}
+	tooltip += "\n" + trayStatusText[state.status]
+	if state.task != "" {
+		tooltip += "\n执行中: " + state.task
+	}
+	tray.SetTooltip(tooltip)
+
+	items.connect.SetEnabled(state.status == string(grpc.StatusDisconnected))
+	items.disconnect.SetEnabled(state.status != string(grpc.StatusDisconnected) && state.status != string(grpc.StatusStopping))
+	items.cancel.SetEnabled(state.canCancel)
+	menu.Update()
+}
+
+// watchTrayState 状态变化时更新托盘：连接状态由客户端状态回调立即通知，运行中的任务每秒检查一次
+func watchTrayState(tray *application.SystemTray, menu *application.Menu, items trayItems, svc *App) {
+	var last trayState
+	first := true
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		if state := currentTrayState(svc); first || state != last {
+			first = false
+			last = state
+			applyTrayState(tray, menu, items, state)
+		}
+		select {
+		case <-svc.trayStateChanged:
+		case <-ticker.C:
+		}
+	}
 }
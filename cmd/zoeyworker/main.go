@@ -1,17 +1,36 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	stdimage "image"
+	"image/png"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/zoeyai/zoeyworker/internal/logger"
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	autoimage "github.com/zoeyai/zoeyworker/pkg/auto/image"
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+	"github.com/zoeyai/zoeyworker/pkg/auto/text"
 	"github.com/zoeyai/zoeyworker/pkg/config"
+	"github.com/zoeyai/zoeyworker/pkg/doctor"
 	"github.com/zoeyai/zoeyworker/pkg/executor"
 	"github.com/zoeyai/zoeyworker/pkg/grpc"
+	"github.com/zoeyai/zoeyworker/pkg/metrics"
 	"github.com/zoeyai/zoeyworker/pkg/permissions"
+	"github.com/zoeyai/zoeyworker/pkg/plugin"
+	"github.com/zoeyai/zoeyworker/pkg/service"
+	"github.com/zoeyai/zoeyworker/pkg/vision/ocr"
 )
 
 // 版本信息 (可通过 ldflags 注入)
@@ -22,13 +41,43 @@ var (
 )
 
 func main() {
+	// 子命令
+	if len(os.Args) > 1 && os.Args[1] == "install-ocr" {
+		os.Exit(installOCR(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "run" {
+		os.Exit(runTask(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		os.Exit(runDoctor(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "find-image" {
+		os.Exit(findImage(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "find-text" {
+		os.Exit(findText(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "service" {
+		os.Exit(serviceCommand(os.Args[2:]))
+	}
+
 	// 命令行参数
 	var (
 		serverURL   = flag.String("server", "", "服务端地址 (例: localhost:50051)")
 		accessKey   = flag.String("access-key", "", "访问密钥")
 		secretKey   = flag.String("secret-key", "", "秘密密钥")
 		saveConfig  = flag.Bool("save", false, "保存配置到本地")
+		profile     = flag.String("profile", "", "使用指定的连接配置（默认为当前配置）")
+		logFile     = flag.String("log-file", "", "日志文件路径（默认 ~/.zoey-worker/logs/worker.log，off 关闭）")
+		caCert      = flag.String("ca-cert", "", "额外信任的 CA 证书（PEM），用于内部 CA 签发的服务端证书")
+		clientCert  = flag.String("client-cert", "", "客户端证书（PEM），服务端要求双向 TLS 时使用")
+		clientKey   = flag.String("client-key", "", "客户端私钥（PEM）")
+		insecure    = flag.Bool("insecure-skip-verify", false, "跳过服务端证书校验（仅用于测试环境）")
+		retry       = flag.Bool("retry", false, "首次连接失败时持续重试（配置 auto_connect 为 true 时默认开启）")
+		once        = flag.Bool("once", false, "首次连接失败时立即退出（用于 CI，优先于 -retry）")
+		serviceMode = flag.Bool("service", false, "以系统服务方式运行（由 service install 注册，连接失败时持续重试）")
 		showVersion = flag.Bool("version", false, "显示版本信息")
+		showConfig  = flag.Bool("show-config", false, "显示已保存的配置（隐藏密钥）")
 		showHelp    = flag.Bool("help", false, "显示帮助信息")
 	)
 
@@ -46,22 +95,49 @@ func main() {
 		return
 	}
 
-	// 加载配置
-	cfg, err := config.Load()
-	if err != nil {
+	// 加载配置（-profile 指定的配置不存在且带 -save 时创建新配置）
+	cfg, err := config.LoadProfile(*profile)
+	if err != nil && !(errors.Is(err, config.ErrProfileNotFound) && *saveConfig) {
 		fmt.Printf("[WARN] 加载配置失败: %v\n", err)
 	}
 
-	// 命令行参数优先级高于配置文件
-	if *serverURL != "" {
-		cfg.ServerURL = *serverURL
-	}
-	if *accessKey != "" {
-		cfg.AccessKey = *accessKey
+	// 日志级别（低于该级别的客户端和执行器日志不输出）
+	logger.SetLevel(logger.ParseLevel(cfg.LogLevel))
+
+	// 显示配置
+	if *showConfig {
+		printConfig(*profile, cfg)
+		return
 	}
-	if *secretKey != "" {
-		cfg.SecretKey = *secretKey
+
+	// 命令行参数优先级高于配置文件（热加载配置时同样适用）
+	applyFlags := func(cfg *config.ConnectionConfig) {
+		if *serverURL != "" {
+			cfg.ServerURL = *serverURL
+		}
+		if *accessKey != "" {
+			cfg.AccessKey = *accessKey
+		}
+		if *secretKey != "" {
+			cfg.SecretKey = *secretKey
+		}
+		if *logFile != "" {
+			cfg.LogFile = *logFile
+		}
+		if *caCert != "" {
+			cfg.CACertFile = *caCert
+		}
+		if *clientCert != "" {
+			cfg.ClientCertFile = *clientCert
+		}
+		if *clientKey != "" {
+			cfg.ClientKeyFile = *clientKey
+		}
+		if *insecure {
+			cfg.InsecureSkipVerify = true
+		}
 	}
+	applyFlags(cfg)
 
 	// 验证必要参数
 	if cfg.ServerURL == "" {
@@ -77,7 +153,7 @@ func main() {
 
 	// 保存配置
 	if *saveConfig {
-		if err := config.Save(cfg); err != nil {
+		if err := config.SaveProfile(*profile, cfg); err != nil {
 			fmt.Printf("[WARN] 保存配置失败: %v\n", err)
 		} else {
 			fmt.Printf("[INFO] 配置已保存到 %s\n", config.GetDefaultManager().GetConfigFile())
@@ -89,6 +165,7 @@ func main() {
 	fmt.Printf("  Zoey Worker v%s\n", Version)
 	fmt.Println("========================================")
 	fmt.Printf("服务端: %s\n", cfg.ServerURL)
+	setupLogFile(cfg.LogFile)
 	fmt.Println()
 
 	// macOS 权限检查
@@ -96,16 +173,47 @@ func main() {
 		checkMacOSPermissions()
 	}
 
+	// OCR 执行提供者（加速提供者不可用时自动回退 CPU）
+	if err := ocr.SetDefaultExecutionProvider(cfg.OCRProvider); err != nil {
+		fmt.Printf("[WARN] %v，使用 CPU\n", err)
+	}
+
 	// 创建 gRPC 客户端
-	client := grpc.NewClient(nil)
+	client := grpc.NewClient(newClientConfig(cfg))
+	fmt.Printf("[INFO] 客户端标识: %s\n", client.ClientID())
 
 	// 设置状态回调
 	client.SetStatusCallback(func(status grpc.ClientStatus) {
 		fmt.Printf("[STATUS] %s\n", status)
 	})
 
+	// 心跳上报能否截图（Windows 服务在用户登录前没有桌面会话）
+	client.SetScreenProbe(screen.Probe)
+	// 心跳上报当前分辨率（运行中接入或断开扩展坞时分辨率会变化）
+	client.SetDisplayProbe(func() (int, int, float64) {
+		d := screen.CurrentDisplay()
+		return d.Width, d.Height, d.ScaleFactor
+	})
+
+	// Prometheus 指标（metrics_addr 为空时不启动）
+	if cfg.MetricsAddr != "" {
+		if server, err := metrics.Serve(cfg.MetricsAddr); err != nil {
+			fmt.Printf("[WARN] 启动指标服务失败: %v\n", err)
+		} else {
+			defer server.Close()
+			fmt.Printf("[INFO] 指标服务: http://%s/metrics\n", server.Addr())
+		}
+	}
+
 	// 创建任务执行器
 	exec := executor.NewExecutor(client)
+	exec.SetScreenshotMaxWidth(cfg.ScreenshotMaxWidth)
+	exec.SetScreenshotQuality(cfg.ScreenshotQuality)
+	exec.SetKeepAwake(cfg.KeepAwake)
+	// 上次运行崩溃时正在执行的批量任务，连接后上报失败
+	exec.SetCheckpointDir(filepath.Join(config.GetDefaultManager().GetConfigDir(), "checkpoints"))
+	// 心跳上报锁屏状态，便于服务端告警
+	client.SetScreenLockProbe(exec.ScreenLocked)
 
 	// 设置 executor 日志函数
 	executor.SetLogFunc(func(level, message string) {
@@ -118,31 +226,668 @@ func main() {
 	})
 
 	// 设置执行器状态回调（用于心跳上报）
-	client.SetExecutorStatusCallback(func() (string, string, string, int64, int) {
+	client.SetExecutorStatusCallback(func() (string, string, string, int64, int, []string) {
 		return exec.GetStatus()
 	})
 
+	// 服务端下发设置（心跳间隔由客户端应用）
+	client.SetSettingsCallback(func(settings *grpc.WsUpdateSettings) error {
+		return applyServerSettings(*profile, exec, settings)
+	})
+
+	// Ctrl+C、SIGTERM 或服务停止请求时退出
+	ctx, done := service.Notify(service.DefaultName)
+	defer done()
+
 	// 连接服务端
 	fmt.Println("[INFO] 正在连接服务端...")
-	if err := client.Connect(cfg.ServerURL, cfg.AccessKey, cfg.SecretKey); err != nil {
+	if (cfg.AutoConnect || *retry || *serviceMode) && !*once {
+		// 开机早于网络（VPN）就绪时持续重试
+		err := client.ConnectWithRetry(ctx, cfg.ServerURL, cfg.AccessKey, cfg.SecretKey)
+		if errors.Is(err, context.Canceled) {
+			fmt.Println("[INFO] 已取消连接")
+			return
+		}
+		if err != nil {
+			fmt.Printf("[ERROR] 连接失败: %v\n", err)
+			printConnectHint(err)
+			os.Exit(1)
+		}
+	} else if err := client.Connect(cfg.ServerURL, cfg.AccessKey, cfg.SecretKey); err != nil {
 		fmt.Printf("[ERROR] 连接失败: %v\n", err)
+		if printConnectHint(err) {
+			os.Exit(1)
+		}
+		if !*once {
+			fmt.Println("[INFO] 使用 -retry 或在配置中开启 auto_connect 可在连接失败时持续重试")
+		}
 		os.Exit(1)
 	}
 
 	fmt.Println("[INFO] 连接成功，等待任务...")
+	if n := exec.ReportInterruptedTasks(); n > 0 {
+		fmt.Printf("[WARN] 已上报上次运行中断的 %d 个批量任务\n", n)
+	}
+	if cfg.OCRWarmup {
+		exec.WarmupOCR()
+	}
+
+	// 配置文件热加载（运维修改配置文件轮换密钥时无需重启）
+	config.GetDefaultManager().OnChange(func(changed *config.ConnectionConfig) {
+		if *profile != "" {
+			// -profile 指定的配置不一定是当前配置
+			loaded, err := config.LoadProfile(*profile)
+			if err != nil {
+				logger.Warn("重新加载配置 %s 失败: %v", *profile, err)
+				return
+			}
+			changed = loaded
+		}
+		applyFlags(changed)
+		applyConfigChange(client, exec, changed)
+	})
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	config.GetDefaultManager().Watch(watchCtx, 2*time.Second)
+
 	fmt.Println("[INFO] 按 Ctrl+C 退出")
 
-	// 等待中断信号
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	<-sigCh
+	// 等待退出
+	<-ctx.Done()
 
 	fmt.Println()
 	fmt.Println("[INFO] 正在断开连接...")
 	client.Disconnect()
+	logger.Default().Close()
 	fmt.Println("[INFO] 已退出")
 }
 
+// newClientConfig 由连接配置生成客户端配置（重连间隔、是否自动重连和 TLS 选项）
+func newClientConfig(cfg *config.ConnectionConfig) *grpc.ClientConfig {
+	clientConfig := grpc.DefaultConfig()
+	clientConfig.ServerURL = cfg.ServerURL
+	clientConfig.ReconnectDelays = reconnectDelays(cfg.ReconnectInterval)
+	clientConfig.DisableReconnect = !cfg.AutoReconnect
+	if cfg.HeartbeatInterval > 0 {
+		clientConfig.HeartbeatInterval = cfg.HeartbeatInterval
+	}
+	clientConfig.CACertFile = cfg.CACertFile
+	clientConfig.ClientCertFile = cfg.ClientCertFile
+	clientConfig.ClientKeyFile = cfg.ClientKeyFile
+	clientConfig.InsecureSkipVerify = cfg.InsecureSkipVerify
+	clientConfig.RemoteControl = cfg.RemoteControl
+	clientConfig.CorrectClockSkew = cfg.CorrectClockSkew
+	clientConfig.DataDir = config.GetDefaultManager().GetConfigDir()
+	clientConfig.OutboxDir = filepath.Join(clientConfig.DataDir, "outbox")
+	return clientConfig
+}
+
+// printConnectHint 根据连接错误类型输出排查提示，返回是否为重试无法解决的错误（TLS 或认证失败）
+func printConnectHint(err error) bool {
+	switch {
+	case errors.Is(err, grpc.ErrTLS):
+		fmt.Println("[INFO] TLS 握手失败：内部 CA 签发的证书请使用 -ca-cert 指定 CA，双向 TLS 请检查 -client-cert 和 -client-key")
+		return true
+	case errors.Is(err, grpc.ErrAuthRejected):
+		fmt.Println("[INFO] 服务端拒绝认证：请检查 -access-key 和 -secret-key")
+		return true
+	}
+	return false
+}
+
+// applyServerSettings 应用服务端下发的日志级别和截图质量，Persist 时写入 profile 配置（保存后由热加载再次应用）
+func applyServerSettings(profile string, exec *executor.Executor, settings *grpc.WsUpdateSettings) error {
+	if settings.LogLevel != "" {
+		logger.SetLevel(logger.ParseLevel(settings.LogLevel))
+	}
+	if settings.ScreenshotQuality > 0 {
+		exec.SetScreenshotQuality(settings.ScreenshotQuality)
+	}
+	if !settings.Persist {
+		return nil
+	}
+
+	cfg, err := config.LoadProfile(profile)
+	if err != nil {
+		return fmt.Errorf("读取配置失败: %w", err)
+	}
+	if settings.HeartbeatIntervalSeconds > 0 {
+		cfg.HeartbeatInterval = settings.HeartbeatIntervalSeconds
+	}
+	if settings.LogLevel != "" {
+		cfg.LogLevel = settings.LogLevel
+	}
+	if settings.ScreenshotQuality > 0 {
+		cfg.ScreenshotQuality = settings.ScreenshotQuality
+	}
+	if err := config.SaveProfile(profile, cfg); err != nil {
+		return fmt.Errorf("保存配置失败: %w", err)
+	}
+	return nil
+}
+
+// applyConfigChange 应用热加载的配置：日志级别、截图宽度和心跳间隔立即生效，
+// 服务端地址、密钥或 TLS 选项变化时重新连接（失败且开启自动重连时在后台持续重试）
+func applyConfigChange(client *grpc.Client, exec *executor.Executor, cfg *config.ConnectionConfig) {
+	logger.SetLevel(logger.ParseLevel(cfg.LogLevel))
+	client.SetHeartbeatInterval(cfg.HeartbeatInterval)
+	client.SetRemoteControl(cfg.RemoteControl)
+	client.SetClockSkewCorrection(cfg.CorrectClockSkew)
+	exec.SetScreenshotMaxWidth(cfg.ScreenshotMaxWidth)
+	exec.SetScreenshotQuality(cfg.ScreenshotQuality)
+	exec.SetKeepAwake(cfg.KeepAwake)
+
+	client.UpdateTLS(cfg.CACertFile, cfg.ClientCertFile, cfg.ClientKeyFile, cfg.InsecureSkipVerify)
+	changed, err := client.UpdateCredentials(cfg.ServerURL, cfg.AccessKey, cfg.SecretKey)
+	if !changed {
+		return
+	}
+	if err == nil {
+		logger.Info("已使用新配置重新连接 %s", cfg.ServerURL)
+		return
+	}
+	logger.Error("使用新配置重新连接失败: %v", err)
+	if cfg.AutoReconnect && !errors.Is(err, grpc.ErrAuthRejected) {
+		go client.ConnectWithRetry(context.Background(), cfg.ServerURL, cfg.AccessKey, cfg.SecretKey)
+	}
+}
+
+// reconnectDelays 由配置的重连间隔生成退避序列（每次翻倍，最长 60 秒或重连间隔本身），间隔无效时使用默认序列
+func reconnectDelays(interval int) []int {
+	if interval <= 0 {
+		return grpc.DefaultConfig().ReconnectDelays
+	}
+	limit := max(60, interval)
+	delays := make([]int, 0, 5)
+	for d := interval; len(delays) < 5; d *= 2 {
+		delays = append(delays, min(d, limit))
+	}
+	return delays
+}
+
+// setupLogFile 启用日志文件输出（按大小轮转，10MB × 5 个历史文件）
+// path 为空时使用配置目录下的 logs/worker.log，为 "off" 时不写文件
+func setupLogFile(path string) {
+	if path == "off" {
+		return
+	}
+	if path == "" {
+		path = filepath.Join(config.GetDefaultManager().GetConfigDir(), "logs", "worker.log")
+	}
+	if err := logger.Default().SetFile(true, path); err != nil {
+		fmt.Printf("[WARN] 启用日志文件失败: %v\n", err)
+		return
+	}
+	fmt.Printf("日志文件: %s\n", path)
+}
+
+
+// installOCR 安装 OCR 插件：指定 --from 时从离线安装包安装，否则在线下载（使用配置的镜像和代理）
+func installOCR(args []string) int {
+	fs := flag.NewFlagSet("install-ocr", flag.ExitOnError)
+	from := fs.String("from", "", "离线安装包路径 (.zip / .tgz)")
+	fs.Parse(args)
+
+	p := plugin.GetOCRPlugin()
+	p.SetProgressCallback(func(progress float64) {
+		fmt.Printf("\r[INFO] 安装进度: %3.0f%%", progress)
+	})
+
+	var err error
+	if *from != "" {
+		fmt.Printf("[INFO] 正在从 %s 安装 OCR 插件...\n", *from)
+		err = p.InstallFromArchive(*from)
+	} else {
+		if cfg, loadErr := config.Load(); loadErr == nil {
+			p.SetMirrors(cfg.PluginMirrors)
+			if err := p.SetProxy(cfg.PluginProxy); err != nil {
+				fmt.Printf("[ERROR] %v\n", err)
+				return 1
+			}
+		}
+		fmt.Println("[INFO] 正在下载 OCR 插件...")
+		err = p.Install(context.Background(), nil)
+	}
+	fmt.Println()
+	if err != nil {
+		fmt.Printf("[ERROR] 安装 OCR 插件失败: %v\n", err)
+		return 1
+	}
+	fmt.Printf("[INFO] OCR 插件已安装到 %s\n", filepath.Dir(p.GetStatus().DetModelPath))
+	return 0
+}
+
+// prepareLocal 本地执行（run / find-image / find-text）前的准备：日志输出到标准错误，
+// 按配置设置日志级别和 OCR 执行提供者，并启用 OCR 插件
+func prepareLocal() {
+	logger.Default().SetOutput(os.Stderr)
+	if cfg, err := config.Load(); err == nil {
+		logger.SetLevel(logger.ParseLevel(cfg.LogLevel))
+		if err := ocr.SetDefaultExecutionProvider(cfg.OCRProvider); err != nil {
+			logger.Warn("%v，使用 CPU", err)
+		}
+	}
+	text.SetOCRPlugin(plugin.GetOCRPlugin())
+}
+
+// taskFile 本地任务文件：{"type": "click_image", "payload": {...}}
+// 没有 type 字段时整个文件作为 debug_case 的 payload（包含 steps 的用例）
+type taskFile struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// runTask 不连接服务端，在本地执行任务文件或单个步骤，结果以 JSON 输出到标准输出（日志输出到标准错误）
+// 退出码：0 成功，1 失败，2 参数错误
+func runTask(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	file := fs.String("file", "", "任务文件路径（JSON）")
+	taskType := fs.String("type", "", "单步任务类型（如 click_image）")
+	payload := fs.String("payload", "{}", "单步任务参数（JSON）")
+	screenshots := fs.String("screenshots", "", "步骤截图保存目录（默认不保存）")
+	fs.Parse(args)
+
+	prepareLocal()
+
+	var typ, payloadJSON string
+	switch {
+	case *file != "":
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR] 读取任务文件失败: %v\n", err)
+			return 2
+		}
+		var task taskFile
+		if err := json.Unmarshal(data, &task); err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR] 解析任务文件失败: %v\n", err)
+			return 2
+		}
+		if task.Type == "" {
+			typ, payloadJSON = executor.TaskTypeDebugCase, string(data)
+		} else {
+			typ, payloadJSON = task.Type, string(task.Payload)
+		}
+	case *taskType != "":
+		typ, payloadJSON = *taskType, *payload
+	default:
+		fmt.Fprintln(os.Stderr, "[ERROR] 请使用 --file 指定任务文件，或使用 --type / --payload 指定单个步骤")
+		return 2
+	}
+	if payloadJSON == "" {
+		payloadJSON = "{}"
+	}
+
+	result := executor.RunLocal(typ, payloadJSON, *screenshots)
+	data, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(data))
+	if !result.Success {
+		return 1
+	}
+	return 0
+}
+
+// parseTarget 解析子命令的位置参数（查找目标），允许放在选项之前或之后
+func parseTarget(fs *flag.FlagSet, args []string) string {
+	var target string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		target, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if target == "" {
+		target = fs.Arg(0)
+	}
+	return target
+}
+
+// parseRegion 解析 x,y,w,h 格式的搜索区域
+func parseRegion(s string) (auto.Option, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("搜索区域格式应为 x,y,w,h: %s", s)
+	}
+	var v [4]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("搜索区域格式应为 x,y,w,h: %s", s)
+		}
+		v[i] = n
+	}
+	if v[2] <= 0 || v[3] <= 0 {
+		return nil, fmt.Errorf("搜索区域的宽高必须大于 0: %s", s)
+	}
+	return auto.WithRegion(v[0], v[1], v[2], v[3]), nil
+}
+
+// locatorOptions 构造查找选项（与执行器相同的默认值，超时默认为 0 即只查找一次）
+func locatorOptions(timeout float64, region string) ([]auto.Option, error) {
+	opts := []auto.Option{auto.WithTimeout(time.Duration(timeout * float64(time.Second)))}
+	if region != "" {
+		opt, err := parseRegion(region)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, opt)
+	}
+	return opts, nil
+}
+
+// saveAnnotated 将标注截图保存为 PNG
+func saveAnnotated(path string, img stdimage.Image) {
+	file, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] 保存标注截图失败: %v\n", err)
+		return
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] 保存标注截图失败: %v\n", err)
+		return
+	}
+	fmt.Printf("标注截图: %s\n", path)
+}
+
+// findImage 截图并查找模板图像（与执行器相同的匹配流程），输出位置、置信度和耗时
+// 退出码：0 找到，1 未找到，2 参数错误或查找失败
+func findImage(args []string) int {
+	fs := flag.NewFlagSet("find-image", flag.ExitOnError)
+	threshold := fs.Float64("threshold", 0.8, "匹配阈值 (0, 1]")
+	region := fs.String("region", "", "搜索区域 x,y,w,h（默认全屏）")
+	timeout := fs.Float64("timeout", 0, "等待图像出现的超时（秒，默认只查找一次）")
+	annotate := fs.String("annotate", "", "标注截图保存路径（PNG）")
+	path := parseTarget(fs, args)
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "[ERROR] 用法: zoeyworker find-image <模板图片> [--threshold 0.8] [--region x,y,w,h] [--annotate out.png]")
+		return 2
+	}
+	if *threshold <= 0 || *threshold > 1 {
+		fmt.Fprintf(os.Stderr, "[ERROR] threshold 必须在 (0, 1] 之间: %v\n", *threshold)
+		return 2
+	}
+	opts, err := locatorOptions(*timeout, *region)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		return 2
+	}
+	opts = append(opts, auto.WithThreshold(*threshold))
+	prepareLocal()
+
+	start := time.Now()
+	result, err := autoimage.FindImage(path, opts...)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil && !errors.Is(err, auto.ErrTimeout) {
+		fmt.Fprintf(os.Stderr, "[ERROR] 查找图像失败: %v\n", err)
+		return 2
+	}
+
+	if result == nil {
+		fmt.Printf("未找到图像 %s（阈值 %.2f，耗时 %v）\n", path, *threshold, elapsed)
+		if *annotate != "" {
+			img, candidate, err := autoimage.AnnotateSearch(path, opts...)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[WARN] 生成标注截图失败: %v\n", err)
+			} else {
+				if candidate != nil {
+					fmt.Printf("最佳候选: (%d, %d)，置信度 %.3f\n", candidate.Result.X, candidate.Result.Y, candidate.Confidence)
+				}
+				saveAnnotated(*annotate, img)
+			}
+		}
+		return 1
+	}
+
+	bounds := autoimage.MatchRegion(result)
+	fmt.Printf("找到图像 %s\n", path)
+	fmt.Printf("  位置: (%d, %d)\n", result.Result.X, result.Result.Y)
+	fmt.Printf("  区域: x=%d y=%d w=%d h=%d\n", bounds.X, bounds.Y, bounds.Width, bounds.Height)
+	fmt.Printf("  置信度: %.3f\n", result.Confidence)
+	fmt.Printf("  耗时: %v\n", elapsed)
+	if *annotate != "" {
+		img, err := autoimage.AnnotateMatch(result, opts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[WARN] 生成标注截图失败: %v\n", err)
+		} else {
+			saveAnnotated(*annotate, img)
+		}
+	}
+	return 0
+}
+
+// findText 截图并通过 OCR 查找文字（与执行器相同的匹配流程），输出位置、置信度和耗时
+// 退出码：0 找到，1 未找到，2 参数错误或识别失败
+func findText(args []string) int {
+	fs := flag.NewFlagSet("find-text", flag.ExitOnError)
+	region := fs.String("region", "", "搜索区域 x,y,w,h（默认全屏）")
+	timeout := fs.Float64("timeout", 0, "等待文字出现的超时（秒，默认只查找一次）")
+	match := fs.String("match", "", "匹配方式: contains（默认）、exact、regex、fuzzy")
+	language := fs.String("language", "", "OCR 语言模型（如 ch、ja，默认 ch）")
+	annotate := fs.String("annotate", "", "标注截图保存路径（PNG）")
+	target := parseTarget(fs, args)
+	if target == "" {
+		fmt.Fprintln(os.Stderr, "[ERROR] 用法: zoeyworker find-text \"文字\" [--region x,y,w,h] [--annotate out.png]")
+		return 2
+	}
+	opts, err := locatorOptions(*timeout, *region)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		return 2
+	}
+	if *match != "" {
+		opts = append(opts, auto.WithTextMatch(*match, 0))
+	}
+	if *language != "" {
+		opts = append(opts, auto.WithLanguage(*language))
+	}
+	prepareLocal()
+
+	start := time.Now()
+	result, err := text.WaitForTextMatch(target, opts...)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	switch {
+	case errors.Is(err, auto.ErrTimeout), errors.Is(err, auto.ErrNotFound):
+		fmt.Printf("未找到文字 %q（耗时 %v）\n", target, elapsed)
+		if errors.Is(err, auto.ErrNotFound) {
+			fmt.Printf("  %v\n", err)
+		}
+		return 1
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "[ERROR] 查找文字失败: %v\n", err)
+		return 2
+	}
+
+	fmt.Printf("找到文字 %q\n", target)
+	fmt.Printf("  识别文字: %s\n", result.Text)
+	fmt.Printf("  位置: (%d, %d)\n", result.Position.X, result.Position.Y)
+	if b := result.Bounds; b.Width > 0 && b.Height > 0 {
+		fmt.Printf("  区域: x=%d y=%d w=%d h=%d\n", b.X, b.Y, b.Width, b.Height)
+	}
+	fmt.Printf("  置信度: %.3f\n", result.Confidence)
+	fmt.Printf("  耗时: %v\n", elapsed)
+	if *annotate != "" {
+		img, err := text.AnnotateText(result, opts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[WARN] 生成标注截图失败: %v\n", err)
+		} else {
+			saveAnnotated(*annotate, img)
+		}
+	}
+	return 0
+}
+
+// serviceCommand 管理系统服务：install / uninstall / start / stop
+// 服务以 -service 参数启动 Worker，使用已保存的配置，连接失败时持续重试；Linux 输出 systemd 用户单元
+func serviceCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "[ERROR] 用法: zoeyworker service install|uninstall|start|stop [选项]")
+		return 2
+	}
+	action := args[0]
+	fs := flag.NewFlagSet("service "+action, flag.ExitOnError)
+	name := fs.String("name", service.DefaultName, "服务名")
+	profile := fs.String("profile", "", "服务使用的连接配置（默认为当前配置）")
+	system := fs.Bool("system", false, "macOS：安装为 LaunchDaemon（开机即启动，但没有图形会话，无法截图）")
+	user := fs.String("user", "", "Windows：运行服务的账户（如 .\\tester，默认 LocalSystem）")
+	password := fs.String("password", "", "Windows：运行服务的账户密码")
+	output := fs.String("output", "", "Linux：systemd 单元的保存路径（默认输出到标准输出）")
+	fs.Parse(args[1:])
+
+	var extra []string
+	if *profile != "" {
+		extra = append(extra, "-profile", *profile)
+	}
+	svcCfg, err := service.NewConfig(*name, extra...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		return 1
+	}
+	svcCfg.System = *system
+	svcCfg.User = *user
+	svcCfg.Password = *password
+	svcCfg.LogFile = filepath.Join(config.GetDefaultManager().GetConfigDir(), "logs", "service.log")
+
+	switch action {
+	case "install":
+		// 服务没有交互界面，必须先保存连接配置
+		cfg, err := config.LoadProfile(*profile)
+		if err != nil || cfg.ServerURL == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+			fmt.Fprintln(os.Stderr, "[ERROR] 服务使用已保存的配置，请先使用 -server / -access-key / -secret-key -save 保存配置")
+			return 1
+		}
+		if runtime.GOOS == "linux" {
+			unit := service.SystemdUnit(svcCfg)
+			if *output == "" {
+				fmt.Print(unit)
+				return 0
+			}
+			if err := os.WriteFile(*output, []byte(unit), 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "[ERROR] 写入 systemd 单元失败: %v\n", err)
+				return 1
+			}
+			fmt.Printf("[INFO] systemd 单元已保存到 %s，执行 systemctl --user daemon-reload && systemctl --user enable --now %s 启动\n", *output, *name)
+			return 0
+		}
+		if runtime.GOOS == "windows" && *user == "" {
+			fmt.Println("[WARN] 服务将以 LocalSystem 运行，只能读取 LocalSystem 的配置目录，且无法解密当前用户保存的密钥；")
+			fmt.Println("[WARN] 请使用 --user / --password 指定保存配置的账户")
+		}
+		err = service.Install(svcCfg)
+		if err == nil {
+			fmt.Printf("[INFO] 服务 %s 已安装，开机（macOS 为登录）后自动启动；执行 zoeyworker service start 立即启动\n", *name)
+		}
+	case "uninstall":
+		err = service.Uninstall(svcCfg)
+		if err == nil {
+			fmt.Printf("[INFO] 服务 %s 已卸载\n", *name)
+		}
+	case "start":
+		err = service.Start(svcCfg)
+		if err == nil {
+			fmt.Printf("[INFO] 服务 %s 已启动，日志见 %s\n", *name, filepath.Dir(svcCfg.LogFile))
+		}
+	case "stop":
+		err = service.Stop(svcCfg)
+		if err == nil {
+			fmt.Printf("[INFO] 服务 %s 已停止\n", *name)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "[ERROR] 未知的操作 %s，应为 install、uninstall、start 或 stop\n", action)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// doctorMarks 诊断结果状态对应的标记
+var doctorMarks = map[doctor.Status]string{
+	doctor.StatusOK:   "✓",
+	doctor.StatusWarn: "!",
+	doctor.StatusFail: "✗",
+	doctor.StatusSkip: "-",
+}
+
+// runDoctor 检查运行环境并输出检查清单（--json 输出 JSON 便于自动化），存在失败项时退出码为 1
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	serverURL := fs.String("server", "", "测试连通性的服务端地址（默认使用配置）")
+	profile := fs.String("profile", "", "使用指定的连接配置（默认为当前配置）")
+	jsonOutput := fs.Bool("json", false, "以 JSON 输出诊断结果")
+	fs.Parse(args)
+
+	// 诊断过程中的日志不混入检查清单
+	logger.Default().SetOutput(os.Stderr)
+	logger.SetLevel(logger.WARN)
+
+	var server *grpc.ClientConfig
+	var cfg *config.ConnectionConfig
+	var err error
+	if *profile != "" {
+		cfg, err = config.GetDefaultManager().LoadProfile(*profile)
+	} else {
+		cfg, err = config.Load()
+	}
+	if err == nil {
+		server = newClientConfig(cfg)
+		if err := ocr.SetDefaultExecutionProvider(cfg.OCRProvider); err != nil {
+			logger.Warn("%v，使用 CPU", err)
+		}
+	}
+	if *serverURL != "" {
+		if server == nil {
+			server = grpc.DefaultConfig()
+		}
+		server.ServerURL = *serverURL
+	}
+	text.SetOCRPlugin(plugin.GetOCRPlugin())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	report := doctor.Run(ctx, server)
+
+	if *jsonOutput {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Zoey Worker v%s (%s)\n", Version, report.Platform)
+		for _, c := range report.Checks {
+			fmt.Printf("[%s] %s: %s\n", doctorMarks[c.Status], c.Title, c.Message)
+			if issues, ok := c.Details["issues"].([]string); ok {
+				for _, issue := range issues {
+					fmt.Printf("      - %s\n", issue)
+				}
+			}
+		}
+	}
+	if !report.OK {
+		return 1
+	}
+	return 0
+}
+
+// printConfig 打印配置（SecretKey 已隐藏），profile 为空时为当前配置
+func printConfig(profile string, cfg *config.ConnectionConfig) {
+	data, err := json.MarshalIndent(cfg.Redacted(), "", "  ")
+	if err != nil {
+		fmt.Printf("[ERROR] 序列化配置失败: %v\n", err)
+		return
+	}
+	names, current, _ := config.GetDefaultManager().ListProfiles()
+	if profile == "" {
+		profile = current
+	}
+	fmt.Printf("配置文件位置: %s\n", config.GetDefaultManager().GetConfigFile())
+	fmt.Printf("所有配置: %s（当前: %s）\n", strings.Join(names, ", "), current)
+	fmt.Printf("配置 %s:\n", profile)
+	fmt.Println(string(data))
+	if issues, err := config.GetDefaultManager().Validate(); err == nil && len(issues) > 0 {
+		fmt.Println("配置文件问题:")
+		for _, issue := range issues {
+			fmt.Printf("  - %s\n", issue)
+		}
+	}
+}
 
 // printVersion 打印版本信息
 func printVersion() {
@@ -157,12 +902,29 @@ func printHelp() {
 	fmt.Println()
 	fmt.Println("用法:")
 	fmt.Println("  zoeyworker [选项]")
+	fmt.Println("  zoeyworker install-ocr [--from 安装包路径]")
+	fmt.Println("  zoeyworker run --file 任务文件.json [--screenshots 目录]")
+	fmt.Println("  zoeyworker run --type click_image --payload '{\"image\":\"btn.png\"}'")
+	fmt.Println("  zoeyworker doctor [--json] [--server 地址] [--profile 配置名]")
+	fmt.Println("  zoeyworker find-image 模板.png [--threshold 0.8] [--region x,y,w,h] [--annotate out.png]")
+	fmt.Println("  zoeyworker find-text \"文字\" [--region x,y,w,h] [--match exact] [--annotate out.png]")
+	fmt.Println("  zoeyworker service install|uninstall|start|stop [--profile 配置名]")
 	fmt.Println()
 	fmt.Println("选项:")
 	fmt.Println("  -server string      服务端地址 (例: localhost:50051)")
 	fmt.Println("  -access-key string  访问密钥")
 	fmt.Println("  -secret-key string  秘密密钥")
 	fmt.Println("  -save               保存配置到本地")
+	fmt.Println("  -profile string     使用指定的连接配置（如 staging、prod，配合 -save 创建或更新）")
+	fmt.Println("  -log-file string    日志文件路径（默认 ~/.zoey-worker/logs/worker.log，off 关闭，配合 -save 保存）")
+	fmt.Println("  -ca-cert string     额外信任的 CA 证书（PEM），用于内部 CA 签发的 wss:// 服务端证书")
+	fmt.Println("  -client-cert string 客户端证书（PEM），服务端要求双向 TLS 时与 -client-key 一起使用")
+	fmt.Println("  -client-key string  客户端私钥（PEM）")
+	fmt.Println("  -insecure-skip-verify 跳过服务端证书校验（仅用于测试环境）")
+	fmt.Println("  -retry              首次连接失败时按重连间隔持续重试（配置 auto_connect 为 true 时默认开启）")
+	fmt.Println("  -once               首次连接失败时立即退出（用于 CI）")
+	fmt.Println("  -service            以系统服务方式运行（由 service install 注册）")
+	fmt.Println("  -show-config        显示已保存的配置（隐藏密钥）")
 	fmt.Println("  -version            显示版本信息")
 	fmt.Println("  -help               显示帮助信息")
 	fmt.Println()
@@ -176,6 +938,17 @@ func printHelp() {
 	fmt.Println("  # 使用已保存的配置连接")
 	fmt.Println("  zoeyworker")
 	fmt.Println()
+	fmt.Println("  # 保存并使用命名配置")
+	fmt.Println("  zoeyworker -profile staging -server staging:50051 -access-key KEY -secret-key SECRET -save")
+	fmt.Println("  zoeyworker -profile staging")
+	fmt.Println()
+	fmt.Println("  # 在线安装 OCR 插件 / 从离线安装包安装")
+	fmt.Println("  zoeyworker install-ocr")
+	fmt.Println("  zoeyworker install-ocr --from /path/bundle.zip")
+	fmt.Println()
+	fmt.Println("  # 诊断运行环境（权限、截图、OCR、Python、配置文件、服务端连通性）")
+	fmt.Println("  zoeyworker doctor")
+	fmt.Println()
 	fmt.Printf("配置文件位置: %s\n", config.GetDefaultManager().GetConfigFile())
 }
 
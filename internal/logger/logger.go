@@ -2,10 +2,11 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"os"
+	"strings"
 	"sync"
 	"time"
 )
@@ -35,16 +36,16 @@ func (l Level) String() string {
 	}
 }
 
-// ParseLevel 解析日志级别字符串
+// ParseLevel 解析日志级别字符串（不区分大小写），无法识别时返回 INFO
 func ParseLevel(s string) Level {
-	switch s {
-	case "DEBUG", "debug":
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "DEBUG":
 		return DEBUG
-	case "INFO", "info":
+	case "INFO":
 		return INFO
-	case "WARN", "warn", "WARNING", "warning":
+	case "WARN", "WARNING":
 		return WARN
-	case "ERROR", "error":
+	case "ERROR":
 		return ERROR
 	default:
 		return INFO
@@ -52,6 +53,7 @@ func ParseLevel(s string) Level {
 }
 
 // Logger 日志记录器
+// 每条日志同时输出到控制台（文本格式）和日志文件（JSON Lines，按大小轮转）
 type Logger struct {
 	mu       sync.Mutex
 	level    Level
@@ -59,8 +61,8 @@ type Logger struct {
 	console  bool
 	file     bool
 	filePath string
-	logger   *log.Logger
-	fileOut  *os.File
+	stdout   io.Writer
+	fileOut  io.WriteCloser
 }
 
 // 全局默认 logger
@@ -73,7 +75,7 @@ func New() *Logger {
 		enabled: true,
 		console: true,
 		file:    false,
-		logger:  log.New(os.Stdout, "", 0),
+		stdout:  os.Stdout,
 	}
 }
 
@@ -89,6 +91,20 @@ func (l *Logger) SetLevel(level Level) {
 	l.level = level
 }
 
+// GetLevel 获取日志级别
+func (l *Logger) GetLevel() Level {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.level
+}
+
+// Enabled 指定级别的日志是否会输出
+func (l *Logger) Enabled(level Level) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.enabled && level >= l.level
+}
+
 // SetEnabled 设置是否启用日志
 func (l *Logger) SetEnabled(enabled bool) {
 	l.mu.Lock()
@@ -101,11 +117,22 @@ func (l *Logger) SetConsole(enabled bool) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	l.console = enabled
-	l.updateOutput()
 }
 
-// SetFile 设置是否输出到文件
+// SetOutput 设置控制台输出目标（默认 os.Stdout，标准输出用于结果时可改为 os.Stderr）
+func (l *Logger) SetOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stdout = w
+}
+
+// SetFile 设置是否输出到文件，文件超过 DefaultMaxFileSize 时轮转，保留 DefaultMaxBackups 个历史文件
 func (l *Logger) SetFile(enabled bool, path string) error {
+	return l.SetRotatingFile(enabled, path, DefaultMaxFileSize, DefaultMaxBackups)
+}
+
+// SetRotatingFile 设置是否输出到文件及轮转参数（maxSize 字节、保留 maxBackups 个历史文件）
+func (l *Logger) SetRotatingFile(enabled bool, path string, maxSize int64, maxBackups int) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -119,48 +146,59 @@ func (l *Logger) SetFile(enabled bool, path string) error {
 	l.filePath = path
 
 	if enabled && path != "" {
-		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		f, err := NewRotatingFile(path, maxSize, maxBackups)
 		if err != nil {
-			return fmt.Errorf("无法打开日志文件: %w", err)
+			return err
 		}
 		l.fileOut = f
 	}
-
-	l.updateOutput()
 	return nil
 }
 
-func (l *Logger) updateOutput() {
-	var writers []io.Writer
+// FilePath 日志文件路径（未启用文件输出时为空）
+func (l *Logger) FilePath() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.file {
+		return ""
+	}
+	return l.filePath
+}
 
+// fileEntry 日志文件中的一行（JSON Lines）
+type fileEntry struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Message string `json:"msg"`
+}
+
+// Log 输出一条已格式化的日志（message 不作为格式串解析）
+func (l *Logger) Log(level Level, message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.enabled || level < l.level {
+		return
+	}
+
+	now := time.Now()
 	if l.console {
-		writers = append(writers, os.Stdout)
+		fmt.Fprintf(l.stdout, "%s | %-5s | %s\n", now.Format("15:04:05"), level.String(), message)
 	}
 	if l.file && l.fileOut != nil {
-		writers = append(writers, l.fileOut)
-	}
-
-	if len(writers) == 0 {
-		l.logger.SetOutput(io.Discard)
-	} else if len(writers) == 1 {
-		l.logger.SetOutput(writers[0])
-	} else {
-		l.logger.SetOutput(io.MultiWriter(writers...))
+		line, err := json.Marshal(fileEntry{Time: now.Format("2006-01-02T15:04:05.000Z07:00"), Level: level.String(), Message: message})
+		if err == nil {
+			l.fileOut.Write(append(line, '\n'))
+		}
 	}
 }
 
 // log 内部日志方法
 func (l *Logger) log(level Level, format string, args ...interface{}) {
-	if !l.enabled || level < l.level {
+	if !l.Enabled(level) {
 		return
 	}
-
-	l.mu.Lock()
-	defer l.mu.Unlock()
-
-	timestamp := time.Now().Format("15:04:05")
-	msg := fmt.Sprintf(format, args...)
-	l.logger.Printf("%s | %-5s | %s", timestamp, level.String(), msg)
+	l.Log(level, fmt.Sprintf(format, args...))
 }
 
 // Debug 输出 DEBUG 级别日志
@@ -211,6 +249,10 @@ func (l *Logger) Close() error {
 }
 
 // 包级别便捷函数
+func Log(level Level, message string)          { defaultLogger.Log(level, message) }
+func SetLevel(level Level)                     { defaultLogger.SetLevel(level) }
+func GetLevel() Level                          { return defaultLogger.GetLevel() }
+func Enabled(level Level) bool                 { return defaultLogger.Enabled(level) }
 func Debug(format string, args ...interface{}) { defaultLogger.Debug(format, args...) }
 func Info(format string, args ...interface{})  { defaultLogger.Info(format, args...) }
 func Warn(format string, args ...interface{})  { defaultLogger.Warn(format, args...) }
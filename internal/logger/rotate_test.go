@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "worker.log")
+	f, err := NewRotatingFile(path, 100, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	line := strings.Repeat("x", 59) + "\n" // 60 字节，每个文件只能写一行
+	for i := 0; i < 5; i++ {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("应存在 %s: %v", filepath.Base(name), err)
+		}
+		if string(data) != line {
+			t.Errorf("%s 应只包含一行, 实际 %d 字节", filepath.Base(name), len(data))
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("超出 maxBackups 的历史文件应被删除")
+	}
+}
+
+func TestLoggerWritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "worker.log")
+	l := New()
+	l.SetConsole(false)
+	l.SetLevel(INFO)
+	if err := l.SetFile(true, path); err != nil {
+		t.Fatal(err)
+	}
+	l.Log(DEBUG, "hidden")
+	l.Log(WARN, "100% done")
+	l.Error("task %s failed", "t1")
+	l.Close()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var entries []fileEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry fileEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("每行应为 JSON: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("应写入 2 条日志（DEBUG 被过滤）, 实际 %+v", entries)
+	}
+	if entries[0].Level != "WARN" || entries[0].Message != "100% done" || entries[0].Time == "" {
+		t.Errorf("第一条日志不正确: %+v", entries[0])
+	}
+	if entries[1].Level != "ERROR" || entries[1].Message != "task t1 failed" {
+		t.Errorf("第二条日志不正确: %+v", entries[1])
+	}
+}
+
+func TestParseLevelCaseInsensitive(t *testing.T) {
+	for input, want := range map[string]Level{"debug": DEBUG, "Info": INFO, " WARNING ": WARN, "error": ERROR, "bogus": INFO} {
+		if got := ParseLevel(input); got != want {
+			t.Errorf("ParseLevel(%q) = %v, 应为 %v", input, got, want)
+		}
+	}
+}
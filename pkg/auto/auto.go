@@ -27,6 +27,30 @@ func ScaleCoord(value int, scale float64) int {
 	return int(math.Round(float64(value) / scale))
 }
 
+// GetScreenScaleFactor 返回截图像素与鼠标输入坐标的比例（macOS Retina 通常为 2，Windows 高 DPI 下为 DPI 缩放）
+// 匹配结果等截图坐标除以该比例即为 robotgo 输入坐标，input 包在移动鼠标时自动换算
+func GetScreenScaleFactor() float64 {
+	scaleX, _ := getCoordinateScale()
+	if scaleX <= 0 {
+		return 1.0
+	}
+	return scaleX
+}
+
+// normalizeScale 规整探测到的缩放比例，异常值视为 1.0
+func normalizeScale(v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 1.0
+	}
+	if v < 0.5 || v > 4.0 {
+		return 1.0
+	}
+	if math.Abs(v-1.0) < 0.05 {
+		return 1.0
+	}
+	return v
+}
+
 // MinInt 返回最小值
 func MinInt(values ...int) int {
 	min := values[0]
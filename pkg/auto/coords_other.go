@@ -3,47 +3,140 @@
 package auto
 
 import (
+	"fmt"
 	"math"
+	"sync"
 
 	"github.com/go-vgo/robotgo"
 )
 
-// NormalizePointForInput 非 Windows 平台无需缩放
+// =====================================================================
+// 非 Windows 坐标说明
+// =====================================================================
+//
+// macOS Retina 屏幕上 robotgo.CaptureImg() 返回 2x 像素，而 robotgo.Move()、
+// GetScreenSize()、GetBounds() 使用点（point）坐标。
+// 与 Windows 相同，统一以截图像素作为坐标空间：
+//
+// coordScale = 截图像素尺寸 / robotgo.GetScreenSize()
+//
+// NormalizePointForInput:  截图坐标 → robotgo 坐标 = x / coordScale
+// NormalizePointForScreen: robotgo 坐标 → 截图坐标 = x * coordScale
+// Linux 等平台 coordScale 通常为 1.0。
+// =====================================================================
+
+var (
+	coordinateScaleMu sync.Mutex
+	cachedScaleX      float64
+	cachedScaleY      float64
+	coordsDetected    bool
+)
+
+// 可替换的屏幕尺寸查询（便于测试）
+var (
+	reportedScreenSize = robotgo.GetScreenSize
+	capturedScreenSize = func(width, height int) (int, int, error) {
+		img, err := robotgo.CaptureImg(0, 0, width, height)
+		if err != nil {
+			return 0, 0, err
+		}
+		if img == nil {
+			return 0, 0, fmt.Errorf("截图为空")
+		}
+		return img.Bounds().Dx(), img.Bounds().Dy(), nil
+	}
+)
+
+// getCoordinateScale 获取 截图像素 → robotgo 输入坐标 之间的缩放比
+func getCoordinateScale() (float64, float64) {
+	coordinateScaleMu.Lock()
+	defer coordinateScaleMu.Unlock()
+
+	if coordsDetected {
+		return cachedScaleX, cachedScaleY
+	}
+
+	cachedScaleX, cachedScaleY = detectCoordinateScale()
+	coordsDetected = true
+	return cachedScaleX, cachedScaleY
+}
+
+func detectCoordinateScale() (float64, float64) {
+	reportedW, reportedH := reportedScreenSize()
+	if reportedW <= 0 || reportedH <= 0 {
+		return 1.0, 1.0
+	}
+
+	captureW, captureH, err := capturedScreenSize(reportedW, reportedH)
+	if err != nil || captureW <= 0 || captureH <= 0 {
+		return 1.0, 1.0
+	}
+
+	return normalizeScale(float64(captureW) / float64(reportedW)),
+		normalizeScale(float64(captureH) / float64(reportedH))
+}
+
+// ResetCoordinateScaleCache 重置坐标缩放缓存（显示器配置变化后调用）
+func ResetCoordinateScaleCache() {
+	coordinateScaleMu.Lock()
+	defer coordinateScaleMu.Unlock()
+	cachedScaleX = 0
+	cachedScaleY = 0
+	coordsDetected = false
+}
+
+// NormalizePointForInput 将截图坐标转换为 robotgo 输入坐标
 func NormalizePointForInput(x, y int) (int, int) {
-	return x, y
+	scaleX, scaleY := getCoordinateScale()
+	return ScaleCoord(x, scaleX), ScaleCoord(y, scaleY)
 }
 
-// NormalizePointForScreen 非 Windows 平台无需缩放
+// NormalizePointForScreen 将 robotgo 坐标转换为截图坐标
 func NormalizePointForScreen(x, y int) (int, int) {
-	return x, y
+	scaleX, scaleY := getCoordinateScale()
+	return ScaleInt(x, scaleX), ScaleInt(y, scaleY)
 }
 
-// NormalizeRegionForInput 非 Windows 平台无需缩放
+// NormalizeRegionForInput 将截图区域转换为 robotgo 输入区域
 func NormalizeRegionForInput(x, y, width, height int) (int, int, int, int) {
-	return x, y, width, height
+	scaleX, scaleY := getCoordinateScale()
+	nw, nh := ScaleCoord(width, scaleX), ScaleCoord(height, scaleY)
+	if width > 0 && nw < 1 {
+		nw = 1
+	}
+	if height > 0 && nh < 1 {
+		nh = 1
+	}
+	return ScaleCoord(x, scaleX), ScaleCoord(y, scaleY), nw, nh
 }
 
-// NormalizeRegionForScreen 非 Windows 平台无需缩放
-func NormalizeRegionForScreen(x, y, width, height int) (int, int, int, int) {
-	return x, y, width, height
+// NormalizeRegionForCapture 将截图区域转换为 robotgo.CaptureImg 的参数（点坐标）
+func NormalizeRegionForCapture(x, y, width, height int) (int, int, int, int) {
+	return NormalizeRegionForInput(x, y, width, height)
 }
 
-// ResetCoordinateScaleCache 非 Windows 平台无操作
-func ResetCoordinateScaleCache() {}
+// NormalizeRegionForScreen 将 robotgo 区域转换为截图区域
+func NormalizeRegionForScreen(x, y, width, height int) (int, int, int, int) {
+	scaleX, scaleY := getCoordinateScale()
+	return ScaleInt(x, scaleX), ScaleInt(y, scaleY), ScaleInt(width, scaleX), ScaleInt(height, scaleY)
+}
 
-// GetDPIScale 非 Windows 平台返回 1.0
+// GetDPIScale 非 Windows 平台返回 1.0（Retina 缩放见 GetScreenScaleFactor）
 func GetDPIScale() float64 {
 	return 1.0
 }
 
-// GetPhysicalScreenSize 获取物理屏幕尺寸
-// 非 Windows 平台等同于 robotgo.GetScreenSize()（macOS Retina 由 robotgo 自行处理）
+// GetPhysicalScreenSize 获取物理屏幕尺寸（截图像素）
 func GetPhysicalScreenSize() (width, height int) {
-	return robotgo.GetScreenSize()
+	w, h := robotgo.GetScreenSize()
+	scaleX, scaleY := getCoordinateScale()
+	return ScaleInt(w, scaleX), ScaleInt(h, scaleY)
 }
 
-// ResetDPIScaleCache 非 Windows 平台无操作
-func ResetDPIScaleCache() {}
+// ResetDPIScaleCache 非 Windows 平台等同于重置坐标缩放缓存
+func ResetDPIScaleCache() {
+	ResetCoordinateScaleCache()
+}
 
 // ScaleInt 缩放整数值
 func ScaleInt(value int, factor float64) int {
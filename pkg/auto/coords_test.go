@@ -0,0 +1,58 @@
+//go:build !windows
+
+package auto
+
+import (
+	"testing"
+)
+
+// mockScreen 模拟 robotgo 报告的屏幕尺寸与实际截图尺寸
+func mockScreen(t *testing.T, reportedW, reportedH, captureW, captureH int) {
+	t.Helper()
+	origReported, origCaptured := reportedScreenSize, capturedScreenSize
+	reportedScreenSize = func() (int, int) { return reportedW, reportedH }
+	capturedScreenSize = func(int, int) (int, int, error) { return captureW, captureH, nil }
+	ResetCoordinateScaleCache()
+	t.Cleanup(func() {
+		reportedScreenSize, capturedScreenSize = origReported, origCaptured
+		ResetCoordinateScaleCache()
+	})
+}
+
+func TestCoordinateScale_Retina(t *testing.T) {
+	mockScreen(t, 1440, 900, 2880, 1800)
+
+	if got := GetScreenScaleFactor(); got != 2.0 {
+		t.Fatalf("GetScreenScaleFactor = %v, 期望 2", got)
+	}
+
+	// 截图像素 (2000, 800) 应点击在点坐标 (1000, 400)
+	if x, y := NormalizePointForInput(2000, 800); x != 1000 || y != 400 {
+		t.Errorf("NormalizePointForInput = (%d, %d), 期望 (1000, 400)", x, y)
+	}
+	if x, y := NormalizePointForScreen(1000, 400); x != 2000 || y != 800 {
+		t.Errorf("NormalizePointForScreen = (%d, %d), 期望 (2000, 800)", x, y)
+	}
+	if x, y, w, h := NormalizeRegionForCapture(200, 100, 1, 600); x != 100 || y != 50 || w != 1 || h != 300 {
+		t.Errorf("NormalizeRegionForCapture = (%d, %d, %d, %d), 期望 (100, 50, 1, 300)", x, y, w, h)
+	}
+}
+
+func TestCoordinateScale_Standard(t *testing.T) {
+	mockScreen(t, 1920, 1080, 1920, 1080)
+
+	if got := GetScreenScaleFactor(); got != 1.0 {
+		t.Errorf("GetScreenScaleFactor = %v, 期望 1", got)
+	}
+	if x, y := NormalizePointForInput(1234, 567); x != 1234 || y != 567 {
+		t.Errorf("NormalizePointForInput = (%d, %d), 期望不变", x, y)
+	}
+}
+
+func TestCoordinateScale_Invalid(t *testing.T) {
+	// 截图失败或比例异常时退回 1.0
+	mockScreen(t, 1440, 900, 100000, 90000)
+	if got := GetScreenScaleFactor(); got != 1.0 {
+		t.Errorf("异常比例应视为 1.0, 实际为 %v", got)
+	}
+}
@@ -188,19 +188,6 @@ func detectCoordinateScale() (float64, float64) {
 	return scaleX, scaleY
 }
 
-func normalizeScale(v float64) float64 {
-	if math.IsNaN(v) || math.IsInf(v, 0) {
-		return 1.0
-	}
-	if v < 0.5 || v > 4.0 {
-		return 1.0
-	}
-	if math.Abs(v-1.0) < 0.05 {
-		return 1.0
-	}
-	return v
-}
-
 // ResetCoordinateScaleCache 重置坐标缩放缓存
 func ResetCoordinateScaleCache() {
 	coordinateScaleMu.Lock()
@@ -255,6 +242,11 @@ func NormalizeRegionForInput(x, y, width, height int) (int, int, int, int) {
 	return nx, ny, nw, nh
 }
 
+// NormalizeRegionForCapture Windows 上 robotgo.CaptureImg 直接使用截图坐标，无需转换
+func NormalizeRegionForCapture(x, y, width, height int) (int, int, int, int) {
+	return x, y, width, height
+}
+
 // NormalizeRegionForScreen 将 robotgo 区域转换为截图物理区域
 func NormalizeRegionForScreen(x, y, width, height int) (int, int, int, int) {
 	scaleX, scaleY := getCoordinateScale()
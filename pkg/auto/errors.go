@@ -0,0 +1,39 @@
+package auto
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 自动化操作的错误分类，可通过 errors.Is 判断
+var (
+	// ErrNotFound 目标（图像、文字、窗口、进程等）未找到
+	ErrNotFound = errors.New("not found")
+	// ErrTimeout 等待目标超时
+	ErrTimeout = errors.New("timeout")
+	// ErrParam 参数错误
+	ErrParam = errors.New("invalid parameter")
+	// ErrSystem 运行环境错误（如缺少依赖的系统工具），不是目标未找到
+	ErrSystem = errors.New("system error")
+)
+
+// Errorf 创建带分类的错误
+// 错误消息与 fmt.Errorf(format, args...) 相同，且 errors.Is(err, kind) 为 true；
+// format 中的 %w 仍可用于包装底层错误
+func Errorf(kind error, format string, args ...interface{}) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+// kindError 带分类的错误
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
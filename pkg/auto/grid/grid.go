@@ -126,11 +126,21 @@ func GetGridCellRect(rect auto.Region, rows, cols, row, col int) auto.Region {
 
 // ClickGrid 点击网格位置
 func ClickGrid(rect auto.Region, gridStr string, opts ...auto.Option) error {
+	_, err := ClickGridResult(rect, gridStr, opts...)
+	return err
+}
+
+// ClickGridResult 点击网格位置，并返回实际点击的坐标（含点击偏移）
+func ClickGridResult(rect auto.Region, gridStr string, opts ...auto.Option) (*auto.Point, error) {
 	pos, err := CalculateGridCenterFromString(rect, gridStr)
 	if err != nil {
-		return fmt.Errorf("计算网格位置失败: %w", err)
+		return nil, fmt.Errorf("计算网格位置失败: %w", err)
 	}
 
 	o := auto.ApplyOptions(opts...)
-	return input.ClickAt(pos.X+o.ClickOffset.X, pos.Y+o.ClickOffset.Y, o)
+	clickPos := &auto.Point{X: pos.X + o.ClickOffset.X, Y: pos.Y + o.ClickOffset.Y}
+	if err := input.ClickAt(clickPos.X, clickPos.Y, o); err != nil {
+		return nil, err
+	}
+	return clickPos, nil
 }
@@ -0,0 +1,70 @@
+package image
+
+import (
+	"fmt"
+	stdimage "image"
+
+	"gocv.io/x/gocv"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+	"github.com/zoeyai/zoeyworker/pkg/vision/cv"
+)
+
+// AnnotateSearch 截取全屏并标注图像查找的诊断信息（用于失败截图）
+// 标注内容：搜索区域（如有）、忽略阈值后的最佳候选及其置信度。
+// 匹配和标注使用同一帧截图，标出的候选与实际搜索的画面一致。
+// 返回标注后的图像和最佳候选（可能为 nil）
+func AnnotateSearch(templatePath string, opts ...auto.Option) (stdimage.Image, *cv.MatchResult, error) {
+	o := auto.ApplyOptions(opts...)
+
+	// 截取一帧全屏，从中裁剪搜索区域匹配（处理区域偏移和缩放）
+	frame, err := screen.CaptureScreen()
+	if err != nil {
+		return nil, nil, err
+	}
+	searchMat, meta, err := screen.CropForMatch(frame, o)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl := cv.NewTemplate(templatePath, templateOptions(o)...)
+	candidate, err := tmpl.BestCandidateIn(searchMat)
+	tmpl.Close()
+	searchMat.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("匹配失败: %w", err)
+	}
+	candidate = screen.AdjustMatchResult(candidate, meta)
+
+	// 在同一帧截图上绘制标注
+	annotated, err := screen.AnnotateFrame(frame, o, func(mat *gocv.Mat) {
+		if candidate != nil {
+			region := MatchRegion(candidate)
+			cv.DrawRectangle(mat, candidate.Rectangle, cv.ColorCandidate, 3)
+			label := fmt.Sprintf("best %.2f < %.2f", candidate.Confidence, o.Threshold)
+			cv.DrawLabel(mat, label, stdimage.Pt(region.X, region.Y-4), cv.ColorCandidate)
+			return
+		}
+		label := fmt.Sprintf("no candidate (threshold %.2f)", o.Threshold)
+		pt := stdimage.Pt(10, 30)
+		if searchRegion, _ := screen.SearchRegion(o); searchRegion != nil {
+			pt = stdimage.Pt(searchRegion.X+4, searchRegion.Y+24)
+		}
+		cv.DrawLabel(mat, label, pt, cv.ColorCandidate)
+	})
+	if err != nil {
+		return nil, candidate, err
+	}
+	return annotated, candidate, nil
+}
+
+// AnnotateMatch 截取全屏并标注查找成功的匹配区域及其置信度（用于调试定位）
+func AnnotateMatch(result *cv.MatchResult, opts ...auto.Option) (stdimage.Image, error) {
+	o := auto.ApplyOptions(opts...)
+	return screen.Annotate(o, func(mat *gocv.Mat) {
+		region := MatchRegion(result)
+		cv.DrawRectangle(mat, result.Rectangle, cv.ColorMatched, 3)
+		label := fmt.Sprintf("match %.2f", result.Confidence)
+		cv.DrawLabel(mat, label, stdimage.Pt(region.X, region.Y-4), cv.ColorMatched)
+	})
+}
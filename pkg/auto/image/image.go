@@ -17,44 +17,66 @@ import (
 
 // ClickImage 点击图像位置
 func ClickImage(templatePath string, opts ...auto.Option) error {
+	_, err := ClickImageResult(templatePath, opts...)
+	return err
+}
+
+// ClickImageResult 点击图像位置，并返回完整的匹配结果（匹配区域、置信度）
+func ClickImageResult(templatePath string, opts ...auto.Option) (*cv.MatchResult, error) {
 	o := auto.ApplyOptions(opts...)
 
-	pos, err := waitForImageInternal(templatePath, o)
+	result, err := waitForImageResultInternal(templatePath, o)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return input.ClickAt(pos.X+o.ClickOffset.X, pos.Y+o.ClickOffset.Y, o)
+	pos := result.Result
+	if err := input.ClickAt(pos.X+o.ClickOffset.X, pos.Y+o.ClickOffset.Y, o); err != nil {
+		return result, err
+	}
+	return result, nil
 }
 
 // ClickImageWithGrid 点击图像匹配区域内的网格位置
 // gridStr: 网格位置字符串 (如 "2.2.1.1" 表示 2x2 网格的第1行第1列)
 func ClickImageWithGrid(templatePath string, gridStr string, opts ...auto.Option) error {
+	_, err := ClickImageWithGridResult(templatePath, gridStr, opts...)
+	return err
+}
+
+// ClickImageWithGridResult 点击图像匹配区域内的网格位置，并返回完整的匹配结果
+func ClickImageWithGridResult(templatePath string, gridStr string, opts ...auto.Option) (*cv.MatchResult, error) {
 	o := auto.ApplyOptions(opts...)
 
 	result, err := waitForImageResultInternal(templatePath, o)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	clickPos, err := grid.CalculateGridCenterFromString(MatchRegion(result), gridStr)
+	if err != nil {
+		return result, fmt.Errorf("计算网格位置失败: %w", err)
+	}
+
+	if err := input.ClickAt(clickPos.X+o.ClickOffset.X, clickPos.Y+o.ClickOffset.Y, o); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// MatchRegion 计算匹配结果四个角点的外接矩形
+func MatchRegion(result *cv.MatchResult) auto.Region {
 	rect := result.Rectangle
 	minX := auto.MinInt(rect.TopLeft.X, rect.TopRight.X, rect.BottomLeft.X, rect.BottomRight.X)
 	maxX := auto.MaxInt(rect.TopLeft.X, rect.TopRight.X, rect.BottomLeft.X, rect.BottomRight.X)
 	minY := auto.MinInt(rect.TopLeft.Y, rect.TopRight.Y, rect.BottomLeft.Y, rect.BottomRight.Y)
 	maxY := auto.MaxInt(rect.TopLeft.Y, rect.TopRight.Y, rect.BottomLeft.Y, rect.BottomRight.Y)
-	matchRegion := auto.Region{
+	return auto.Region{
 		X:      minX,
 		Y:      minY,
 		Width:  auto.MaxInt(1, maxX-minX),
 		Height: auto.MaxInt(1, maxY-minY),
 	}
-
-	clickPos, err := grid.CalculateGridCenterFromString(matchRegion, gridStr)
-	if err != nil {
-		return fmt.Errorf("计算网格位置失败: %w", err)
-	}
-
-	return input.ClickAt(clickPos.X+o.ClickOffset.X, clickPos.Y+o.ClickOffset.Y, o)
 }
 
 // ClickImageGrid 点击图像匹配结果的网格位置（ClickImageWithGrid 的别名）
@@ -74,6 +96,38 @@ func ClickImageData(template stdimage.Image, opts ...auto.Option) error {
 	return input.ClickAt(pos.X+o.ClickOffset.X, pos.Y+o.ClickOffset.Y, o)
 }
 
+// FindImage 等待图像出现，返回完整的匹配结果（中心点、四个角点、置信度）
+func FindImage(templatePath string, opts ...auto.Option) (*cv.MatchResult, error) {
+	o := auto.ApplyOptions(opts...)
+	return waitForImageResultInternal(templatePath, o)
+}
+
+// FindAllImages 截图一次，返回搜索区域内模板的所有匹配（按置信度降序，坐标已换算为屏幕坐标）
+// 只使用模板匹配（特征点匹配只能返回单个结果，不适合计数），重叠的候选经非极大值抑制去重；
+// maxResults <= 0 表示不限制数量，没有匹配时返回空切片
+func FindAllImages(templatePath string, maxResults int, opts ...auto.Option) ([]*cv.MatchResult, error) {
+	o := auto.ApplyOptions(opts...)
+	tmplOpts := append(templateOptions(o), cv.WithTemplateMethods(cv.MatchMethodTemplate), cv.WithTemplateMaxResults(maxResults))
+	tmpl := cv.NewTemplate(templatePath, tmplOpts...)
+	defer tmpl.Close()
+
+	screenMat, meta, err := screen.CaptureForMatch(o)
+	if err != nil {
+		return nil, err
+	}
+	defer screenMat.Close()
+
+	results, err := tmpl.MatchAllInCtx(o.Context(), screenMat)
+	if err != nil {
+		return nil, fmt.Errorf("匹配失败: %w", err)
+	}
+	adjusted := make([]*cv.MatchResult, len(results))
+	for i, r := range results {
+		adjusted[i] = screen.AdjustMatchResult(r, meta)
+	}
+	return adjusted, nil
+}
+
 // WaitForImage 等待图像出现
 func WaitForImage(templatePath string, opts ...auto.Option) (*auto.Point, error) {
 	o := auto.ApplyOptions(opts...)
@@ -104,6 +158,21 @@ func ImageExistsData(template stdimage.Image, opts ...auto.Option) bool {
 
 // ==================== 内部函数 ====================
 
+// templateOptions 将自动化选项转换为模板匹配选项
+func templateOptions(o *auto.Options) []cv.TemplateOption {
+	opts := []cv.TemplateOption{cv.WithTemplateThreshold(o.Threshold)}
+	if len(o.Scales) > 0 {
+		opts = append(opts, cv.WithTemplateScales(o.Scales...))
+	}
+	if o.Grayscale {
+		opts = append(opts, cv.WithTemplateGrayscale())
+	}
+	if o.NoMask {
+		opts = append(opts, cv.WithTemplateMask(false))
+	}
+	return opts
+}
+
 func waitForImageInternal(templatePath string, o *auto.Options) (*auto.Point, error) {
 	result, err := waitForImageResultInternal(templatePath, o)
 	if err != nil {
@@ -115,21 +184,28 @@ func waitForImageInternal(templatePath string, o *auto.Options) (*auto.Point, er
 }
 
 func waitForImageResultInternal(templatePath string, o *auto.Options) (*cv.MatchResult, error) {
-	tmpl := cv.NewTemplate(templatePath,
-		cv.WithTemplateThreshold(o.Threshold),
-	)
+	// 模板在循环外解码一次，每轮只截图匹配
+	tmpl := cv.NewTemplate(templatePath, templateOptions(o)...)
+	defer tmpl.Close()
 
+	ctx := o.Context()
 	startTime := time.Now()
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		screenMat, meta, err := screen.CaptureForMatch(o)
 		if err != nil {
 			return nil, err
 		}
 
-		result, err := tmpl.MatchResultIn(screenMat)
+		result, err := tmpl.MatchResultInCtx(ctx, screenMat)
 		screenMat.Close()
 
 		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
 			return nil, fmt.Errorf("匹配失败: %w", err)
 		}
 		if result != nil {
@@ -137,10 +213,12 @@ func waitForImageResultInternal(templatePath string, o *auto.Options) (*cv.Match
 		}
 
 		if o.Timeout == 0 || time.Since(startTime) > o.Timeout {
-			return nil, fmt.Errorf("等待图像超时: %s", templatePath)
+			return nil, auto.Errorf(auto.ErrTimeout, "等待图像超时: %s", templatePath)
 		}
 
-		time.Sleep(auto.DefaultPollInterval)
+		if err := o.WaitPoll(); err != nil {
+			return nil, err
+		}
 	}
 }
 
@@ -151,19 +229,26 @@ func waitForImageDataInternal(template stdimage.Image, o *auto.Options) (*auto.P
 	}
 	defer templateMat.Close()
 
+	ctx := o.Context()
 	startTime := time.Now()
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		screenMat, meta, err := screen.CaptureForMatch(o)
 		if err != nil {
 			return nil, err
 		}
 
 		matcher := cv.NewSIFTMatching(templateMat, screenMat, o.Threshold)
-		result, err := matcher.FindBestResult()
+		result, err := matcher.FindBestResultCtx(ctx)
 		matcher.Close()
 		screenMat.Close()
 
 		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
 			return nil, fmt.Errorf("匹配失败: %w", err)
 		}
 		if result != nil {
@@ -172,9 +257,11 @@ func waitForImageDataInternal(template stdimage.Image, o *auto.Options) (*auto.P
 		}
 
 		if o.Timeout == 0 || time.Since(startTime) > o.Timeout {
-			return nil, fmt.Errorf("等待图像超时")
+			return nil, auto.Errorf(auto.ErrTimeout, "等待图像超时")
 		}
 
-		time.Sleep(auto.DefaultPollInterval)
+		if err := o.WaitPoll(); err != nil {
+			return nil, err
+		}
 	}
 }
@@ -1,6 +1,18 @@
 package input
 
-import "github.com/go-vgo/robotgo"
+import (
+	"image"
+
+	"github.com/go-vgo/robotgo"
+)
+
+// 剪贴板内容类型
+const (
+	ClipboardEmpty = "empty" // 剪贴板为空
+	ClipboardText  = "text"  // 文字
+	ClipboardImage = "image" // 图像（同时有文字和图像时视为图像）
+	ClipboardOther = "other" // 其他（如文件列表）
+)
 
 // CopyToClipboard 复制到剪贴板
 func CopyToClipboard(text string) error {
@@ -11,3 +23,19 @@ func CopyToClipboard(text string) error {
 func ReadClipboard() (string, error) {
 	return robotgo.ReadAll()
 }
+
+// CopyImageToClipboard 将图像复制到剪贴板（替换原有内容），用于粘贴上传等场景
+// macOS 写入 PNG 和 TIFF，Windows 写入 CF_DIB 和 PNG，Linux 通过 wl-copy / xclip 写入 image/png
+func CopyImageToClipboard(img image.Image) error {
+	return copyImagePlatform(img)
+}
+
+// ReadClipboardImage 读取剪贴板中的图像，剪贴板中没有图像时返回 auto.ErrNotFound
+func ReadClipboardImage() (image.Image, error) {
+	return readImagePlatform()
+}
+
+// ClipboardContentType 返回剪贴板内容类型（ClipboardEmpty、ClipboardText、ClipboardImage、ClipboardOther）
+func ClipboardContentType() (string, error) {
+	return contentTypePlatform()
+}
@@ -0,0 +1,126 @@
+//go:build darwin
+
+package input
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework AppKit
+#import <AppKit/AppKit.h>
+#include <stdlib.h>
+#include <string.h>
+
+// 写入 PNG 数据，同时提供 TIFF 以兼容只读取 TIFF 的应用
+int clipboardSetImage(const void* data, int length) {
+    @autoreleasepool {
+        NSData* png = [NSData dataWithBytes:data length:length];
+        NSImage* image = [[NSImage alloc] initWithData:png];
+        if (image == nil) {
+            return 0;
+        }
+        NSPasteboard* pb = [NSPasteboard generalPasteboard];
+        [pb clearContents];
+        BOOL ok = [pb setData:png forType:NSPasteboardTypePNG];
+        NSData* tiff = [image TIFFRepresentation];
+        if (tiff != nil) {
+            [pb setData:tiff forType:NSPasteboardTypeTIFF];
+        }
+        return ok ? 1 : 0;
+    }
+}
+
+// 读取图像并转为 PNG，没有图像时返回 NULL；返回的内存由调用方 free
+void* clipboardGetImage(int* length) {
+    @autoreleasepool {
+        *length = 0;
+        NSPasteboard* pb = [NSPasteboard generalPasteboard];
+        NSData* data = [pb dataForType:NSPasteboardTypePNG];
+        if (data == nil) {
+            NSData* tiff = [pb dataForType:NSPasteboardTypeTIFF];
+            if (tiff == nil) {
+                return NULL;
+            }
+            NSBitmapImageRep* rep = [NSBitmapImageRep imageRepWithData:tiff];
+            data = [rep representationUsingType:NSBitmapImageFileTypePNG properties:@{}];
+            if (data == nil) {
+                return NULL;
+            }
+        }
+        *length = (int)[data length];
+        void* buf = malloc(*length);
+        memcpy(buf, [data bytes], *length);
+        return buf;
+    }
+}
+
+// 剪贴板内容类型：0 空，1 文字，2 图像，3 其他
+int clipboardContentType() {
+    @autoreleasepool {
+        NSPasteboard* pb = [NSPasteboard generalPasteboard];
+        NSArray* types = [pb types];
+        if (types == nil || [types count] == 0) {
+            return 0;
+        }
+        if ([pb availableTypeFromArray:@[NSPasteboardTypePNG, NSPasteboardTypeTIFF]] != nil) {
+            return 2;
+        }
+        if ([pb availableTypeFromArray:@[NSPasteboardTypeString]] != nil) {
+            return 1;
+        }
+        return 3;
+    }
+}
+*/
+import "C"
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"unsafe"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+// copyImagePlatform macOS：通过 NSPasteboard 写入 PNG 和 TIFF
+func copyImagePlatform(img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return fmt.Errorf("PNG 编码失败: %w", err)
+	}
+	data := buf.Bytes()
+	if C.clipboardSetImage(unsafe.Pointer(&data[0]), C.int(len(data))) == 0 {
+		return errors.New("写入剪贴板失败")
+	}
+	return nil
+}
+
+// readImagePlatform macOS：读取 PNG，没有 PNG 时从 TIFF 转换
+func readImagePlatform() (image.Image, error) {
+	var length C.int
+	ptr := C.clipboardGetImage(&length)
+	if ptr == nil {
+		return nil, auto.Errorf(auto.ErrNotFound, "剪贴板中没有图像")
+	}
+	defer C.free(ptr)
+	img, err := png.Decode(bytes.NewReader(C.GoBytes(ptr, length)))
+	if err != nil {
+		return nil, fmt.Errorf("解析剪贴板图像失败: %w", err)
+	}
+	return img, nil
+}
+
+// contentTypePlatform macOS：按 NSPasteboard 的可用类型判断
+func contentTypePlatform() (string, error) {
+	switch C.clipboardContentType() {
+	case 0:
+		return ClipboardEmpty, nil
+	case 1:
+		return ClipboardText, nil
+	case 2:
+		return ClipboardImage, nil
+	default:
+		return ClipboardOther, nil
+	}
+}
@@ -0,0 +1,142 @@
+//go:build !darwin && !windows
+
+package input
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // 剪贴板中的 JPEG 图像
+	"image/png"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+// clipboardTimeout 读取剪贴板命令的超时
+const clipboardTimeout = 5 * time.Second
+
+// wayland 当前是否为 Wayland 会话且安装了 wl-clipboard（否则使用 xclip）
+func wayland() bool {
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		return false
+	}
+	_, err := exec.LookPath("wl-paste")
+	return err == nil
+}
+
+// clipboardTypes 列出剪贴板中可用的 MIME 类型，剪贴板为空时返回空列表
+func clipboardTypes() ([]string, error) {
+	name, args := "xclip", []string{"-selection", "clipboard", "-t", "TARGETS", "-o"}
+	if wayland() {
+		name, args = "wl-paste", []string{"--list-types"}
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, auto.Errorf(auto.ErrSystem, "未找到剪贴板工具 %s，请安装 xclip 或 wl-clipboard: %w", name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clipboardTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		// 剪贴板为空时两者都以非 0 退出
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取剪贴板类型失败: %w", err)
+	}
+	var types []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			types = append(types, line)
+		}
+	}
+	return types, nil
+}
+
+// imageType 返回第一个图像 MIME 类型（优先 image/png）
+func imageType(types []string) string {
+	var first string
+	for _, t := range types {
+		if t == "image/png" {
+			return t
+		}
+		if first == "" && strings.HasPrefix(t, "image/") {
+			first = t
+		}
+	}
+	return first
+}
+
+// copyImagePlatform Linux：通过 wl-copy 或 xclip 写入 image/png
+// 两者都会在后台保持剪贴板内容，因此不接管其输出（否则会等待后台进程退出）
+func copyImagePlatform(img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return fmt.Errorf("PNG 编码失败: %w", err)
+	}
+	name, args := "xclip", []string{"-selection", "clipboard", "-t", "image/png", "-i"}
+	if wayland() {
+		name, args = "wl-copy", []string{"--type", "image/png"}
+	}
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = &buf
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("写入剪贴板失败（%s）: %w", name, err)
+	}
+	return nil
+}
+
+// readImagePlatform Linux：读取剪贴板中的第一个图像类型
+func readImagePlatform() (image.Image, error) {
+	types, err := clipboardTypes()
+	if err != nil {
+		return nil, err
+	}
+	mime := imageType(types)
+	if mime == "" {
+		return nil, auto.Errorf(auto.ErrNotFound, "剪贴板中没有图像")
+	}
+	name, args := "xclip", []string{"-selection", "clipboard", "-t", mime, "-o"}
+	if wayland() {
+		name, args = "wl-paste", []string{"--no-newline", "--type", mime}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clipboardTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("读取剪贴板图像失败: %w", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(out))
+	if err != nil {
+		return nil, fmt.Errorf("解析剪贴板图像（%s）失败: %w", mime, err)
+	}
+	return img, nil
+}
+
+// contentTypePlatform Linux：按可用 MIME 类型判断
+func contentTypePlatform() (string, error) {
+	types, err := clipboardTypes()
+	if err != nil {
+		return "", err
+	}
+	if len(types) == 0 {
+		return ClipboardEmpty, nil
+	}
+	if imageType(types) != "" {
+		return ClipboardImage, nil
+	}
+	for _, t := range types {
+		if strings.HasPrefix(t, "text/plain") || t == "UTF8_STRING" || t == "STRING" || t == "TEXT" {
+			return ClipboardText, nil
+		}
+	}
+	return ClipboardOther, nil
+}
@@ -0,0 +1,184 @@
+//go:build windows
+
+package input
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"runtime"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+var (
+	clipUser32                     = syscall.NewLazyDLL("user32.dll")
+	clipKernel32                   = syscall.NewLazyDLL("kernel32.dll")
+	procOpenClipboard              = clipUser32.NewProc("OpenClipboard")
+	procCloseClipboard             = clipUser32.NewProc("CloseClipboard")
+	procEmptyClipboard             = clipUser32.NewProc("EmptyClipboard")
+	procSetClipboardData           = clipUser32.NewProc("SetClipboardData")
+	procGetClipboardData           = clipUser32.NewProc("GetClipboardData")
+	procIsClipboardFormatAvailable = clipUser32.NewProc("IsClipboardFormatAvailable")
+	procCountClipboardFormats      = clipUser32.NewProc("CountClipboardFormats")
+	procRegisterClipboardFormatW   = clipUser32.NewProc("RegisterClipboardFormatW")
+	procGlobalAlloc                = clipKernel32.NewProc("GlobalAlloc")
+	procGlobalFree                 = clipKernel32.NewProc("GlobalFree")
+	procGlobalLock                 = clipKernel32.NewProc("GlobalLock")
+	procGlobalUnlock               = clipKernel32.NewProc("GlobalUnlock")
+	procGlobalSize                 = clipKernel32.NewProc("GlobalSize")
+	procRtlMoveMemory              = clipKernel32.NewProc("RtlMoveMemory")
+)
+
+// 剪贴板格式
+const (
+	cfText        = 1
+	cfBitmap      = 2
+	cfDIB         = 8
+	cfUnicodeText = 13
+	cfDIBV5       = 17
+	gmemMoveable  = 0x0002
+)
+
+// pngFormat 浏览器、Office 等使用的 "PNG" 剪贴板格式（保留透明度），注册失败时为 0
+func pngFormat() uintptr {
+	name, _ := syscall.UTF16PtrFromString("PNG")
+	format, _, _ := procRegisterClipboardFormatW.Call(uintptr(unsafe.Pointer(name)))
+	return format
+}
+
+// openClipboard 打开剪贴板；其他程序占用时重试（最多约 500ms）
+// 剪贴板由打开它的线程持有，调用方须先 runtime.LockOSThread，直到 CloseClipboard 之后再解锁
+func openClipboard() error {
+	var err error
+	for i := 0; i < 50; i++ {
+		var r uintptr
+		if r, _, err = procOpenClipboard.Call(0); r != 0 {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return fmt.Errorf("打开剪贴板失败: %w", err)
+}
+
+func formatAvailable(format uintptr) bool {
+	if format == 0 {
+		return false
+	}
+	r, _, _ := procIsClipboardFormatAvailable.Call(format)
+	return r != 0
+}
+
+// setClipboardBytes 以全局内存写入一种格式的数据（剪贴板须已打开）
+func setClipboardBytes(format uintptr, data []byte) error {
+	h, _, err := procGlobalAlloc.Call(gmemMoveable, uintptr(len(data)))
+	if h == 0 {
+		return fmt.Errorf("分配剪贴板内存失败: %w", err)
+	}
+	ptr, _, err := procGlobalLock.Call(h)
+	if ptr == 0 {
+		procGlobalFree.Call(h)
+		return fmt.Errorf("锁定剪贴板内存失败: %w", err)
+	}
+	procRtlMoveMemory.Call(ptr, uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)))
+	procGlobalUnlock.Call(h)
+	// 成功后内存归系统所有，失败时需自行释放
+	if r, _, err := procSetClipboardData.Call(format, h); r == 0 {
+		procGlobalFree.Call(h)
+		return fmt.Errorf("写入剪贴板失败: %w", err)
+	}
+	return nil
+}
+
+// getClipboardBytes 读取一种格式的数据（剪贴板须已打开）
+func getClipboardBytes(format uintptr) ([]byte, error) {
+	h, _, err := procGetClipboardData.Call(format)
+	if h == 0 {
+		return nil, fmt.Errorf("读取剪贴板失败: %w", err)
+	}
+	size, _, _ := procGlobalSize.Call(h)
+	ptr, _, err := procGlobalLock.Call(h)
+	if ptr == 0 || size == 0 {
+		return nil, fmt.Errorf("锁定剪贴板内存失败: %w", err)
+	}
+	defer procGlobalUnlock.Call(h)
+	data := make([]byte, size)
+	procRtlMoveMemory.Call(uintptr(unsafe.Pointer(&data[0])), ptr, size)
+	return data, nil
+}
+
+// copyImagePlatform Windows：写入 CF_DIB（通用）和 PNG（保留透明度）
+func copyImagePlatform(img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return fmt.Errorf("PNG 编码失败: %w", err)
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if err := openClipboard(); err != nil {
+		return err
+	}
+	defer procCloseClipboard.Call()
+
+	if r, _, err := procEmptyClipboard.Call(); r == 0 {
+		return fmt.Errorf("清空剪贴板失败: %w", err)
+	}
+	if err := setClipboardBytes(cfDIB, encodeDIB(img)); err != nil {
+		return err
+	}
+	if format := pngFormat(); format != 0 {
+		setClipboardBytes(format, buf.Bytes()) // 仅为补充格式，失败时保留 CF_DIB
+	}
+	return nil
+}
+
+// readImagePlatform Windows：优先读取 PNG 格式，否则解析 CF_DIB（系统会从 CF_BITMAP / CF_DIBV5 自动转换）
+func readImagePlatform() (image.Image, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if err := openClipboard(); err != nil {
+		return nil, err
+	}
+	defer procCloseClipboard.Call()
+
+	if format := pngFormat(); formatAvailable(format) {
+		if data, err := getClipboardBytes(format); err == nil {
+			if img, err := png.Decode(bytes.NewReader(data)); err == nil {
+				return img, nil
+			}
+		}
+	}
+	if !formatAvailable(cfDIB) {
+		return nil, auto.Errorf(auto.ErrNotFound, "剪贴板中没有图像")
+	}
+	data, err := getClipboardBytes(cfDIB)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDIB(data)
+}
+
+// contentTypePlatform Windows：按可用格式判断
+func contentTypePlatform() (string, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if err := openClipboard(); err != nil {
+		return "", err
+	}
+	defer procCloseClipboard.Call()
+
+	switch {
+	case formatAvailable(pngFormat()) || formatAvailable(cfDIB) || formatAvailable(cfBitmap) || formatAvailable(cfDIBV5):
+		return ClipboardImage, nil
+	case formatAvailable(cfUnicodeText) || formatAvailable(cfText):
+		return ClipboardText, nil
+	}
+	if n, _, _ := procCountClipboardFormats.Call(); n == 0 {
+		return ClipboardEmpty, nil
+	}
+	return ClipboardOther, nil
+}
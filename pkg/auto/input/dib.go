@@ -0,0 +1,104 @@
+package input
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// Windows 位图格式常量
+const (
+	dibHeaderSize = 40 // BITMAPINFOHEADER
+	biRGB         = 0
+	biBitfields   = 3
+)
+
+// encodeDIB 将图像编码为 CF_DIB 格式：BITMAPINFOHEADER + 32 位 BGRA 像素（自下而上，BI_RGB）
+func encodeDIB(img image.Image) []byte {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	buf := make([]byte, dibHeaderSize+w*h*4)
+
+	le := binary.LittleEndian
+	le.PutUint32(buf[0:], dibHeaderSize)
+	le.PutUint32(buf[4:], uint32(int32(w)))
+	le.PutUint32(buf[8:], uint32(int32(h)))
+	le.PutUint16(buf[12:], 1)  // biPlanes
+	le.PutUint16(buf[14:], 32) // biBitCount
+	le.PutUint32(buf[16:], biRGB)
+	le.PutUint32(buf[20:], uint32(w*h*4))
+
+	pixels := buf[dibHeaderSize:]
+	for y := 0; y < h; y++ {
+		row := pixels[(h-1-y)*w*4:]
+		for x := 0; x < w; x++ {
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			row[x*4], row[x*4+1], row[x*4+2], row[x*4+3] = c.B, c.G, c.R, c.A
+		}
+	}
+	return buf
+}
+
+// decodeDIB 解析 CF_DIB 数据，支持 24 / 32 位的 BI_RGB 和 BI_BITFIELDS（BGRA 顺序）
+// 32 位图像的 alpha 全为 0 时（多数应用不写 alpha）视为不透明
+func decodeDIB(data []byte) (image.Image, error) {
+	if len(data) < dibHeaderSize {
+		return nil, errors.New("DIB 数据过短")
+	}
+	le := binary.LittleEndian
+	headerSize := int(le.Uint32(data[0:]))
+	w := int(int32(le.Uint32(data[4:])))
+	h := int(int32(le.Uint32(data[8:])))
+	bitCount := int(le.Uint16(data[14:]))
+	compression := le.Uint32(data[16:])
+	if headerSize < dibHeaderSize || headerSize > len(data) {
+		return nil, fmt.Errorf("无效的 DIB 头大小: %d", headerSize)
+	}
+	if compression != biRGB && compression != biBitfields || bitCount != 24 && bitCount != 32 {
+		return nil, fmt.Errorf("不支持的 DIB 格式: %d 位, 压缩方式 %d", bitCount, compression)
+	}
+
+	topDown := h < 0
+	if topDown {
+		h = -h
+	}
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("无效的 DIB 尺寸: %dx%d", w, h)
+	}
+	offset := headerSize
+	if compression == biBitfields && headerSize == dibHeaderSize {
+		offset += 12 // BITMAPINFOHEADER 之后的三个颜色掩码
+	}
+	bytesPerPixel := bitCount / 8
+	stride := (w*bytesPerPixel + 3) &^ 3
+	if len(data) < offset+stride*h {
+		return nil, fmt.Errorf("DIB 像素数据不完整: %d 字节, 需要 %d", len(data), offset+stride*h)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	hasAlpha := false
+	for y := 0; y < h; y++ {
+		srcY := h - 1 - y
+		if topDown {
+			srcY = y
+		}
+		row := data[offset+srcY*stride:]
+		for x := 0; x < w; x++ {
+			p := row[x*bytesPerPixel:]
+			a := uint8(255)
+			if bytesPerPixel == 4 {
+				a = p[3]
+				hasAlpha = hasAlpha || a != 0
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: p[2], G: p[1], B: p[0], A: a})
+		}
+	}
+	if bytesPerPixel == 4 && !hasAlpha {
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 255
+		}
+	}
+	return img, nil
+}
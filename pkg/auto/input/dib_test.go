@@ -0,0 +1,63 @@
+package input
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDIBRoundTrip(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	src.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	src.SetNRGBA(2, 1, color.NRGBA{B: 200, G: 100, A: 128})
+
+	data := encodeDIB(src)
+	if len(data) != dibHeaderSize+3*2*4 {
+		t.Fatalf("DIB 长度 = %d", len(data))
+	}
+	// 自下而上：第一行像素是图像的最后一行
+	if got := data[dibHeaderSize+2*4 : dibHeaderSize+3*4]; got[0] != 200 || got[1] != 100 || got[3] != 128 {
+		t.Errorf("像素应为 BGRA 且自下而上: %v", got)
+	}
+
+	img, err := decodeDIB(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []image.Point{{0, 0}, {2, 1}, {1, 1}} {
+		if got, want := color.NRGBAModel.Convert(img.At(p.X, p.Y)), src.NRGBAAt(p.X, p.Y); got != want {
+			t.Errorf("%v: %v, 期望 %v", p, got, want)
+		}
+	}
+}
+
+func TestDecodeDIB24TopDown(t *testing.T) {
+	// 2x2、24 位、自上而下（高度为负），每行补齐到 4 字节
+	data := make([]byte, dibHeaderSize+8*2)
+	le := binary.LittleEndian
+	le.PutUint32(data[0:], dibHeaderSize)
+	le.PutUint32(data[4:], 2)
+	le.PutUint32(data[8:], uint32(0xFFFFFFFE)) // -2
+	le.PutUint16(data[14:], 24)
+	copy(data[dibHeaderSize:], []byte{0, 0, 255, 0, 255, 0, 0, 0})
+
+	img, err := decodeDIB(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.At(0, 0).(color.NRGBA); got != (color.NRGBA{R: 255, A: 255}) {
+		t.Errorf("(0,0) = %v", got)
+	}
+	if got := img.At(1, 0).(color.NRGBA); got != (color.NRGBA{G: 255, A: 255}) {
+		t.Errorf("(1,0) = %v", got)
+	}
+
+	if _, err := decodeDIB(data[:dibHeaderSize+4]); err == nil {
+		t.Error("像素数据不完整时应报错")
+	}
+	le.PutUint16(data[14:], 8)
+	if _, err := decodeDIB(data); err == nil {
+		t.Error("不支持的位深度应报错")
+	}
+}
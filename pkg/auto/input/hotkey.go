@@ -0,0 +1,81 @@
+package input
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+// DefaultHotkey wait_for_hotkey 未指定热键时使用的全局热键
+const DefaultHotkey = "ctrl+shift+f9"
+
+// hotkeyModifiers 全局热键可用的修饰键（规范化后的键名），按显示顺序排列
+var hotkeyModifiers = []string{"ctrl", "alt", "shift", "command"}
+
+// hotkeyNamedKeys 可作为全局热键主键的多字符键名（各平台都有对应的键码），此外还支持单个字母和数字
+var hotkeyNamedKeys = map[string]bool{
+	"f1": true, "f2": true, "f3": true, "f4": true, "f5": true, "f6": true, "f7": true,
+	"f8": true, "f9": true, "f10": true, "f11": true, "f12": true, "f13": true, "f14": true,
+	"f15": true, "f16": true, "f17": true, "f18": true, "f19": true, "f20": true,
+	"space": true, "enter": true, "tab": true, "escape": true, "backspace": true, "delete": true,
+	"home": true, "end": true, "pageup": true, "pagedown": true,
+	"up": true, "down": true, "left": true, "right": true,
+}
+
+// Hotkey 全局热键：零个或多个修饰键加一个主键（均为规范化后的键名）
+type Hotkey struct {
+	Modifiers []string // ctrl、alt、shift、command 的子集，按 hotkeyModifiers 的顺序
+	Key       string
+}
+
+// String 返回 "ctrl+shift+f9" 形式的热键
+func (h Hotkey) String() string {
+	return strings.Join(append(append([]string{}, h.Modifiers...), h.Key), "+")
+}
+
+// has 是否包含修饰键
+func (h Hotkey) has(modifier string) bool {
+	for _, m := range h.Modifiers {
+		if m == modifier {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseHotkey 解析 "Ctrl+Shift+F9" 形式的热键（忽略大小写，键名别名同 key_press，如 primary、cmd、option）
+// 最后一项为主键：单个字母、数字，或 f1-f20、space、enter、tab、escape、方向键等；前面的项必须是修饰键
+func ParseHotkey(s string) (Hotkey, error) {
+	parts := strings.Split(s, "+")
+	key := normalizeKeyName(parts[len(parts)-1])
+	if !(len(key) == 1 && (key[0] >= 'a' && key[0] <= 'z' || key[0] >= '0' && key[0] <= '9') || hotkeyNamedKeys[key]) {
+		return Hotkey{}, auto.Errorf(auto.ErrParam, "无效的热键 %q: 主键必须是字母、数字或 f1-f20、space、enter、tab、escape、方向键等", s)
+	}
+
+	pressed := map[string]bool{}
+	for _, part := range parts[:len(parts)-1] {
+		name := normalizeKeyName(part)
+		valid := false
+		for _, m := range hotkeyModifiers {
+			valid = valid || m == name
+		}
+		if !valid {
+			return Hotkey{}, auto.Errorf(auto.ErrParam, "无效的热键 %q: %q 不是修饰键（支持 ctrl、alt、shift、command、primary）", s, strings.TrimSpace(part))
+		}
+		pressed[name] = true
+	}
+	h := Hotkey{Key: key}
+	for _, m := range hotkeyModifiers {
+		if pressed[m] {
+			h.Modifiers = append(h.Modifiers, m)
+		}
+	}
+	return h, nil
+}
+
+// WaitHotkey 注册全局热键并阻塞直到操作员按下，ctx 取消时返回 ctx.Err()
+// 返回前总是注销热键（包括 ctx 取消和出错时）；热键已被其他程序占用时返回错误
+func WaitHotkey(ctx context.Context, h Hotkey) error {
+	return waitHotkeyPlatform(ctx, h)
+}
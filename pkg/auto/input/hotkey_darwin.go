@@ -0,0 +1,83 @@
+//go:build darwin
+
+package input
+
+/*
+#cgo LDFLAGS: -framework ApplicationServices
+#include <ApplicationServices/ApplicationServices.h>
+
+// 主键是否按下且所需的修饰键都按下
+static int hotkeyPressed(int keycode, unsigned long long flags) {
+    CGEventFlags current = CGEventSourceFlagsState(kCGEventSourceStateCombinedSessionState);
+    if ((current & flags) != flags) {
+        return 0;
+    }
+    return CGEventSourceKeyState(kCGEventSourceStateCombinedSessionState, (CGKeyCode)keycode) ? 1 : 0;
+}
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// hotkeyPollInterval macOS 查询按键状态的间隔
+const hotkeyPollInterval = 30 * time.Millisecond
+
+// hotkeyKeycodes 主键的 macOS 虚拟键码（kVK_*，与键盘布局的物理位置对应）
+var hotkeyKeycodes = map[string]int{
+	"a": 0x00, "s": 0x01, "d": 0x02, "f": 0x03, "h": 0x04, "g": 0x05, "z": 0x06, "x": 0x07,
+	"c": 0x08, "v": 0x09, "b": 0x0B, "q": 0x0C, "w": 0x0D, "e": 0x0E, "r": 0x0F, "y": 0x10,
+	"t": 0x11, "1": 0x12, "2": 0x13, "3": 0x14, "4": 0x15, "6": 0x16, "5": 0x17, "9": 0x19,
+	"7": 0x1A, "8": 0x1C, "0": 0x1D, "o": 0x1F, "u": 0x20, "i": 0x22, "p": 0x23, "l": 0x25,
+	"j": 0x26, "k": 0x28, "n": 0x2D, "m": 0x2E,
+
+	"enter": 0x24, "tab": 0x30, "space": 0x31, "backspace": 0x33, "escape": 0x35, "delete": 0x75,
+	"home": 0x73, "end": 0x77, "pageup": 0x74, "pagedown": 0x79,
+	"left": 0x7B, "right": 0x7C, "down": 0x7D, "up": 0x7E,
+
+	"f1": 0x7A, "f2": 0x78, "f3": 0x63, "f4": 0x76, "f5": 0x60, "f6": 0x61, "f7": 0x62,
+	"f8": 0x64, "f9": 0x65, "f10": 0x6D, "f11": 0x67, "f12": 0x6F, "f13": 0x69, "f14": 0x6B,
+	"f15": 0x71, "f16": 0x6A, "f17": 0x40, "f18": 0x4F, "f19": 0x50, "f20": 0x5A,
+}
+
+// hotkeyFlags 修饰键对应的 CGEventFlags
+var hotkeyFlags = map[string]C.ulonglong{
+	"shift":   C.kCGEventFlagMaskShift,
+	"ctrl":    C.kCGEventFlagMaskControl,
+	"alt":     C.kCGEventFlagMaskAlternate,
+	"command": C.kCGEventFlagMaskCommand,
+}
+
+// waitHotkeyPlatform macOS：轮询按键状态（CGEventSourceKeyState），等待主键从松开变为按下时修饰键都已按下
+// Carbon 的 RegisterEventHotKey 需要应用的事件循环，命令行 Worker 没有，因此不注册系统热键，也无需注销；
+// 按键同时会传给前台应用。需要在"隐私与安全性 - 输入监控"中授权
+func waitHotkeyPlatform(ctx context.Context, h Hotkey) error {
+	keycode, ok := hotkeyKeycodes[h.Key]
+	if !ok {
+		return fmt.Errorf("热键 %s 的主键在 macOS 上不受支持", h)
+	}
+	var flags C.ulonglong
+	for _, m := range h.Modifiers {
+		flags |= hotkeyFlags[m]
+	}
+
+	ticker := time.NewTicker(hotkeyPollInterval)
+	defer ticker.Stop()
+	// 开始等待时已经按住的热键不算，必须先松开
+	released := false
+	for {
+		if C.hotkeyPressed(C.int(keycode), flags) == 0 {
+			released = true
+		} else if released {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
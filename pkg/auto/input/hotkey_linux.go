@@ -0,0 +1,108 @@
+//go:build linux
+
+package input
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// hotkeyKeysyms 主键的 X11 keysym（字母和数字即其小写 ASCII 码）
+var hotkeyKeysyms = map[string]xproto.Keysym{
+	"space": 0x0020, "enter": 0xff0d, "tab": 0xff09, "escape": 0xff1b, "backspace": 0xff08, "delete": 0xffff,
+	"home": 0xff50, "end": 0xff57, "pageup": 0xff55, "pagedown": 0xff56,
+	"left": 0xff51, "up": 0xff52, "right": 0xff53, "down": 0xff54,
+}
+
+func init() {
+	for i := 1; i <= 20; i++ {
+		hotkeyKeysyms[fmt.Sprintf("f%d", i)] = xproto.Keysym(0xffbe + i - 1) // XK_F1 起连续
+	}
+}
+
+// hotkeyLockMasks 同时抓取 CapsLock / NumLock 打开时的组合，否则锁定键打开时按下热键不会被识别
+var hotkeyLockMasks = []uint16{0, xproto.ModMaskLock, xproto.ModMask2, xproto.ModMaskLock | xproto.ModMask2}
+
+// waitHotkeyPlatform Linux：在根窗口上 XGrabKey，等待 KeyPress 事件
+// 需要 X11（Wayland 会话中只有 XWayland 窗口获得焦点时才能收到）；返回前 UngrabKey 并关闭连接
+func waitHotkeyPlatform(ctx context.Context, h Hotkey) error {
+	if os.Getenv("DISPLAY") == "" {
+		return fmt.Errorf("全局热键需要 X11（未设置 DISPLAY）")
+	}
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return fmt.Errorf("连接 X11 失败: %w", err)
+	}
+	defer conn.Close()
+
+	setup := xproto.Setup(conn)
+	root := setup.DefaultScreen(conn).Root
+	keycode, err := hotkeyKeycode(conn, setup, h)
+	if err != nil {
+		return err
+	}
+	var mods uint16
+	for m, mask := range map[string]uint16{"ctrl": xproto.ModMaskControl, "alt": xproto.ModMask1, "shift": xproto.ModMaskShift, "command": xproto.ModMask4} {
+		if h.has(m) {
+			mods |= mask
+		}
+	}
+
+	// 先注册注销，部分组合抓取失败时也会撤销已抓取的组合
+	defer xproto.UngrabKeyChecked(conn, keycode, root, xproto.ModMaskAny).Check()
+	for _, lock := range hotkeyLockMasks {
+		err := xproto.GrabKeyChecked(conn, true, root, mods|lock, keycode, xproto.GrabModeAsync, xproto.GrabModeAsync).Check()
+		if err != nil {
+			return fmt.Errorf("注册热键 %s 失败（可能已被其他程序占用）: %v", h, err)
+		}
+	}
+
+	// 连接关闭后 WaitForEvent 返回 (nil, nil)，读取协程随之退出
+	pressed := make(chan struct{}, 1)
+	go func() {
+		for {
+			ev, xerr := conn.WaitForEvent()
+			if ev == nil && xerr == nil {
+				return
+			}
+			if press, ok := ev.(xproto.KeyPressEvent); ok && press.Detail == keycode {
+				pressed <- struct{}{}
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-pressed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// hotkeyKeycode 在当前键盘映射中查找主键的键码
+func hotkeyKeycode(conn *xgb.Conn, setup *xproto.SetupInfo, h Hotkey) (xproto.Keycode, error) {
+	sym, ok := hotkeyKeysyms[h.Key]
+	if !ok && len(h.Key) == 1 {
+		sym, ok = xproto.Keysym(h.Key[0]), true
+	}
+	if !ok {
+		return 0, fmt.Errorf("热键 %s 的主键在 X11 上不受支持", h)
+	}
+	count := byte(setup.MaxKeycode - setup.MinKeycode + 1)
+	mapping, err := xproto.GetKeyboardMapping(conn, setup.MinKeycode, count).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("读取键盘映射失败: %w", err)
+	}
+	per := int(mapping.KeysymsPerKeycode)
+	for i, s := range mapping.Keysyms {
+		if s == sym && per > 0 {
+			return setup.MinKeycode + xproto.Keycode(i/per), nil
+		}
+	}
+	return 0, fmt.Errorf("当前键盘布局中没有热键 %s 的主键", h)
+}
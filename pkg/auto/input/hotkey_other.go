@@ -0,0 +1,14 @@
+//go:build !darwin && !windows && !linux
+
+package input
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+// waitHotkeyPlatform 其他平台不支持全局热键
+func waitHotkeyPlatform(ctx context.Context, h Hotkey) error {
+	return fmt.Errorf("当前平台（%s）不支持全局热键", runtime.GOOS)
+}
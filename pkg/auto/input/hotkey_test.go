@@ -0,0 +1,29 @@
+package input
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+func TestParseHotkey(t *testing.T) {
+	for input, want := range map[string]string{
+		"Ctrl+Shift+F9":      "ctrl+shift+f9",
+		" shift + control+k": "ctrl+shift+k",
+		"Alt+Cmd+Return":     "alt+command+enter",
+		"option+1":           "alt+1",
+		"F12":                "f12",
+		"ctrl+ctrl+PgDn":     "ctrl+pagedown",
+	} {
+		h, err := ParseHotkey(input)
+		if err != nil || h.String() != want {
+			t.Errorf("ParseHotkey(%q) = %q, %v; want %q", input, h, err, want)
+		}
+	}
+	for _, input := range []string{"", "ctrl+", "ctrl+shift", "ctrl+f24", "a+b", "ctrl+num1", "ctrl+;", "hyper+a"} {
+		if _, err := ParseHotkey(input); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("ParseHotkey(%q) 应返回 ErrParam: %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+//go:build windows
+
+package input
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	user32                 = syscall.NewLazyDLL("user32.dll")
+	procRegisterHotKey     = user32.NewProc("RegisterHotKey")
+	procUnregisterHotKey   = user32.NewProc("UnregisterHotKey")
+	procGetMessageW        = user32.NewProc("GetMessageW")
+	procPostThreadMessageW = user32.NewProc("PostThreadMessageW")
+
+	procGetCurrentThreadID = syscall.NewLazyDLL("kernel32.dll").NewProc("GetCurrentThreadId")
+)
+
+const (
+	wmQuit   = 0x0012
+	wmHotkey = 0x0312
+
+	modAlt      = 0x0001
+	modControl  = 0x0002
+	modShift    = 0x0004
+	modWin      = 0x0008
+	modNoRepeat = 0x4000
+)
+
+// hotkeyVirtualKeys 主键的虚拟键码（字母和数字即其大写 ASCII 码）
+var hotkeyVirtualKeys = map[string]uintptr{
+	"space": 0x20, "enter": 0x0D, "tab": 0x09, "escape": 0x1B, "backspace": 0x08, "delete": 0x2E,
+	"home": 0x24, "end": 0x23, "pageup": 0x21, "pagedown": 0x22,
+	"left": 0x25, "up": 0x26, "right": 0x27, "down": 0x28,
+}
+
+func init() {
+	for i := 1; i <= 20; i++ {
+		hotkeyVirtualKeys[fmt.Sprintf("f%d", i)] = uintptr(0x70 + i - 1) // VK_F1 起连续
+	}
+}
+
+// hotkeyIDs 每次注册使用不同的热键 ID（RegisterHotKey 要求 0x0000-0xBFFF）
+var hotkeyIDs atomic.Uint32
+
+// msg Win32 MSG 结构
+type msg struct {
+	hwnd    uintptr
+	message uint32
+	wParam  uintptr
+	lParam  uintptr
+	time    uint32
+	ptX     int32
+	ptY     int32
+	private uint32
+}
+
+// errHotkeyQuit 消息循环因 ctx 取消（WM_QUIT）退出
+var errHotkeyQuit = errors.New("hotkey wait stopped")
+
+// waitHotkeyPlatform Windows：RegisterHotKey 注册到专用线程的消息队列，GetMessage 等待 WM_HOTKEY
+// ctx 取消时向该线程投递 WM_QUIT，并等待线程注销热键后再返回
+func waitHotkeyPlatform(ctx context.Context, h Hotkey) error {
+	vk, ok := hotkeyVirtualKeys[h.Key]
+	if !ok && len(h.Key) == 1 {
+		vk, ok = uintptr(h.Key[0]), true
+		if h.Key[0] >= 'a' && h.Key[0] <= 'z' {
+			vk -= 'a' - 'A'
+		}
+	}
+	if !ok {
+		return fmt.Errorf("热键 %s 的主键在 Windows 上不受支持", h)
+	}
+	mods := uintptr(modNoRepeat)
+	for m, flag := range map[string]uintptr{"ctrl": modControl, "alt": modAlt, "shift": modShift, "command": modWin} {
+		if h.has(m) {
+			mods |= flag
+		}
+	}
+	id := uintptr(hotkeyIDs.Add(1) % 0xC000)
+
+	threadID := make(chan uintptr, 1)
+	done := make(chan error, 1)
+	go func() {
+		// 热键消息投递到注册线程的消息队列，注册、等待和注销必须在同一个系统线程上
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		tid, _, _ := procGetCurrentThreadID.Call()
+		if ret, _, err := procRegisterHotKey.Call(0, id, mods, vk); ret == 0 {
+			threadID <- 0
+			done <- fmt.Errorf("注册热键 %s 失败（可能已被其他程序占用）: %w", h, err)
+			return
+		}
+		defer procUnregisterHotKey.Call(0, id)
+		threadID <- tid
+
+		var m msg
+		for {
+			ret, _, err := procGetMessageW.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+			switch int32(ret) {
+			case -1:
+				done <- fmt.Errorf("等待热键消息失败: %w", err)
+				return
+			case 0:
+				done <- errHotkeyQuit
+				return
+			}
+			if m.message == wmHotkey && m.wParam == id {
+				done <- nil
+				return
+			}
+		}
+	}()
+
+	tid := <-threadID
+	if tid == 0 {
+		return <-done
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		procPostThreadMessageW.Call(tid, wmQuit, 0, 0)
+		<-done
+		return ctx.Err()
+	}
+}
@@ -1,24 +1,56 @@
 package input
 
 import (
+	"runtime"
 	"strings"
 
 	"github.com/go-vgo/robotgo"
 )
 
-// normalizeKeyName 规范化键名为 robotgo 期望的格式（robotgo 对大小写敏感）
+// normalizeKeyName 规范化键名为当前平台 robotgo 期望的格式，见 normalizeKey
 func normalizeKeyName(key string) string {
-	// 统一转换为小写
-	key = strings.ToLower(key)
+	return normalizeKey(key, runtime.GOOS)
+}
+
+// normalizeKey 规范化键名为 robotgo 期望的格式（robotgo 对大小写敏感且不认识的键名不报错）
+// 统一转换为小写并映射常见别名；primary 为平台主修饰键：macOS 上是 Command，其他平台是 Ctrl
+func normalizeKey(key, goos string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
 
-	// 常见别名映射
 	switch key {
+	case "primary":
+		if goos == "darwin" {
+			return "command"
+		}
+		return "ctrl"
 	case "control":
 		return "ctrl"
-	case "cmd", "command", "win", "meta":
+	case "cmd", "command", "win", "windows", "meta", "super":
 		return "command"
+	case "option", "opt":
+		return "alt"
+	case "return":
+		return "enter"
 	case "esc":
 		return "escape"
+	case "del":
+		return "delete"
+	case "ins":
+		return "insert"
+	case "pgup", "page_up":
+		return "pageup"
+	case "pgdn", "page_down":
+		return "pagedown"
+	case "arrowup", "arrow_up":
+		return "up"
+	case "arrowdown", "arrow_down":
+		return "down"
+	case "arrowleft", "arrow_left":
+		return "left"
+	case "arrowright", "arrow_right":
+		return "right"
+	case "spacebar":
+		return "space"
 	}
 
 	return key
@@ -0,0 +1,46 @@
+package input
+
+import (
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+// keyNames robotgo 接受的多字符键名（与 robotgo 的 keyNames 一致，规范化后的小写形式）
+// robotgo 遇到未知键名时不报错也不按键，因此执行前用它校验
+var keyNames = map[string]bool{
+	"backspace": true, "delete": true, "enter": true, "tab": true, "esc": true, "escape": true,
+	"up": true, "down": true, "right": true, "left": true,
+	"home": true, "end": true, "pageup": true, "pagedown": true,
+
+	"f1": true, "f2": true, "f3": true, "f4": true, "f5": true, "f6": true,
+	"f7": true, "f8": true, "f9": true, "f10": true, "f11": true, "f12": true,
+	"f13": true, "f14": true, "f15": true, "f16": true, "f17": true, "f18": true,
+	"f19": true, "f20": true, "f21": true, "f22": true, "f23": true, "f24": true,
+
+	"cmd": true, "lcmd": true, "rcmd": true, "command": true,
+	"alt": true, "lalt": true, "ralt": true,
+	"ctrl": true, "lctrl": true, "rctrl": true, "control": true,
+	"shift": true, "lshift": true, "rshift": true, "right_shift": true,
+	"capslock": true, "space": true, "print": true, "printscreen": true, "insert": true, "menu": true,
+
+	"audio_mute": true, "audio_vol_down": true, "audio_vol_up": true, "audio_play": true,
+	"audio_stop": true, "audio_pause": true, "audio_prev": true, "audio_next": true,
+	"audio_rewind": true, "audio_forward": true, "audio_repeat": true, "audio_random": true,
+
+	"num0": true, "num1": true, "num2": true, "num3": true, "num4": true,
+	"num5": true, "num6": true, "num7": true, "num8": true, "num9": true, "num_lock": true,
+	"num.": true, "num+": true, "num-": true, "num*": true, "num/": true,
+	"num_clear": true, "num_enter": true, "num_equal": true,
+
+	"lights_mon_up": true, "lights_mon_down": true,
+	"lights_kbd_toggle": true, "lights_kbd_up": true, "lights_kbd_down": true,
+}
+
+// ValidateKey 检查键名是否有效：单个 ASCII 可打印字符，或 robotgo 支持的键名（忽略大小写，
+// 支持 primary、control、cmd、win、meta、option、return、esc 等别名，见 normalizeKey）；无效时返回 auto.ErrParam，错误信息包含该键名
+func ValidateKey(key string) error {
+	name := normalizeKeyName(key)
+	if len(name) == 1 && name[0] > ' ' && name[0] < 0x7f || keyNames[name] {
+		return nil
+	}
+	return auto.Errorf(auto.ErrParam, "无效的键名: %q（支持单个字符或 enter、tab、escape、ctrl、shift、alt、command、f1-f24、num0-num9 等键名）", key)
+}
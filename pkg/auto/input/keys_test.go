@@ -0,0 +1,63 @@
+package input
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+func TestValidateKey(t *testing.T) {
+	for _, key := range []string{"a", "A", "7", "/", "Enter", "TAB", "Ctrl", "control", "cmd", "Win", "meta", "esc", "F12", "num_enter", "pagedown", "Return", "pgdn", "Primary", "Option"} {
+		if err := ValidateKey(key); err != nil {
+			t.Errorf("ValidateKey(%q) = %v, 应有效", key, err)
+		}
+	}
+	for _, key := range []string{"", " ", "F25", "中", "ctrl+c", "pgdown"} {
+		err := ValidateKey(key)
+		if !errors.Is(err, auto.ErrParam) {
+			t.Errorf("ValidateKey(%q) 应返回 ErrParam: %v", key, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), "\""+key+"\"") {
+			t.Errorf("错误信息应包含无效的键名 %q: %v", key, err)
+		}
+	}
+}
+
+func TestNormalizeKey(t *testing.T) {
+	tests := []struct {
+		key                    string
+		darwin, windows, linux string
+	}{
+		{"primary", "command", "ctrl", "ctrl"},
+		{"Primary", "command", "ctrl", "ctrl"},
+		{"Ctrl", "ctrl", "ctrl", "ctrl"},
+		{"Control", "ctrl", "ctrl", "ctrl"},
+		{"Cmd", "command", "command", "command"},
+		{"Meta", "command", "command", "command"},
+		{"Win", "command", "command", "command"},
+		{"Super", "command", "command", "command"},
+		{"Alt", "alt", "alt", "alt"},
+		{"Option", "alt", "alt", "alt"},
+		{"Enter", "enter", "enter", "enter"},
+		{"Return", "enter", "enter", "enter"},
+		{"Esc", "escape", "escape", "escape"},
+		{"Del", "delete", "delete", "delete"},
+		{"PgDn", "pagedown", "pagedown", "pagedown"},
+		{"ArrowUp", "up", "up", "up"},
+		{" Shift ", "shift", "shift", "shift"},
+		{"C", "c", "c", "c"},
+	}
+	for _, tt := range tests {
+		for goos, want := range map[string]string{"darwin": tt.darwin, "windows": tt.windows, "linux": tt.linux} {
+			if got := normalizeKey(tt.key, goos); got != want {
+				t.Errorf("normalizeKey(%q, %s) = %q, 期望 %q", tt.key, goos, got, want)
+			}
+			if err := ValidateKey(tt.key); err != nil {
+				t.Errorf("ValidateKey(%q) = %v", tt.key, err)
+			}
+		}
+	}
+}
@@ -2,7 +2,10 @@
 // 组合 vision 模块和 robotgo 实现高级自动化操作
 package auto
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Option 配置选项函数类型
 type Option func(*Options)
@@ -21,6 +24,26 @@ type Options struct {
 	RightClick bool
 	// Region 搜索区域 (nil 表示全屏)
 	Region *Region
+	// Interval 轮询间隔 (0 表示 DefaultPollInterval)
+	Interval time.Duration
+	// DisplayID 搜索的显示器序号 (-1 表示主显示器全屏；设置 Region 时忽略)
+	DisplayID int
+	// Grayscale 是否以灰度图匹配
+	Grayscale bool
+	// NoMask 忽略模板的 alpha 通道（默认模板含透明像素时按掩码匹配）
+	NoMask bool
+	// Scales 图像匹配的模板缩放候选 (nil 表示使用默认多尺度候选)
+	Scales []float64
+	// Ctx 取消等待和匹配的上下文 (nil 表示不可取消)
+	Ctx context.Context
+	// TextMatchMode 文字匹配模式 contains/exact/regex/fuzzy (空表示 contains)
+	TextMatchMode string
+	// TextMaxDistance fuzzy 模式允许的最大编辑距离 (0 表示默认值)
+	TextMaxDistance int
+	// MinOCRConfidence 参与文字匹配的 OCR 行最低识别置信度 (0 表示默认值，负数表示不过滤)
+	MinOCRConfidence float64
+	// Language OCR 语言模型（空字符串表示默认语言）
+	Language string
 }
 
 // Point 表示二维坐标点
@@ -46,6 +69,7 @@ func DefaultOptions() *Options {
 		DoubleClick: false,
 		RightClick:  false,
 		Region:      nil,
+		DisplayID:   -1,
 	}
 }
 
@@ -100,5 +124,109 @@ func WithRegion(x, y, width, height int) Option {
 	}
 }
 
+// WithInterval 设置轮询间隔
+func WithInterval(d time.Duration) Option {
+	return func(o *Options) {
+		o.Interval = d
+	}
+}
+
+// WithDisplay 设置搜索的显示器序号
+func WithDisplay(id int) Option {
+	return func(o *Options) {
+		o.DisplayID = id
+	}
+}
+
+// WithGrayscale 设置以灰度图匹配
+func WithGrayscale() Option {
+	return func(o *Options) {
+		o.Grayscale = true
+	}
+}
+
+// WithMask 设置是否使用模板 alpha 通道作为匹配掩码（默认启用）
+func WithMask(enabled bool) Option {
+	return func(o *Options) {
+		o.NoMask = !enabled
+	}
+}
+
+// WithScales 设置图像匹配的模板缩放候选
+func WithScales(scales ...float64) Option {
+	return func(o *Options) {
+		o.Scales = scales
+	}
+}
+
+// WithMultiScale 设置是否多尺度匹配（false 时仅匹配原始尺寸）
+func WithMultiScale(enabled bool) Option {
+	return func(o *Options) {
+		if enabled {
+			o.Scales = nil
+		} else {
+			o.Scales = []float64{1.0}
+		}
+	}
+}
+
+// WithTextMatch 设置文字匹配模式和 fuzzy 模式的最大编辑距离
+func WithTextMatch(mode string, maxDistance int) Option {
+	return func(o *Options) {
+		o.TextMatchMode = mode
+		o.TextMaxDistance = maxDistance
+	}
+}
+
+// WithMinOCRConfidence 设置参与文字匹配的 OCR 行最低识别置信度（负数表示不过滤）
+func WithMinOCRConfidence(c float64) Option {
+	return func(o *Options) {
+		o.MinOCRConfidence = c
+	}
+}
+
+// WithLanguage 设置文字识别使用的 OCR 语言模型（如 "ja"，需已安装对应语言的模型）
+func WithLanguage(language string) Option {
+	return func(o *Options) {
+		o.Language = language
+	}
+}
+
+// WithContext 设置上下文，取消时等待和匹配提前返回 ctx.Err()
+func WithContext(ctx context.Context) Option {
+	return func(o *Options) {
+		o.Ctx = ctx
+	}
+}
+
+// Context 返回上下文（未设置时为 context.Background()）
+func (o *Options) Context() context.Context {
+	if o.Ctx != nil {
+		return o.Ctx
+	}
+	return context.Background()
+}
+
+// WaitPoll 等待一个轮询间隔，上下文取消时提前返回 ctx.Err()
+func (o *Options) WaitPoll() error {
+	ctx := o.Context()
+	timer := time.NewTimer(o.PollInterval())
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// PollInterval 返回轮询间隔（未设置时为 DefaultPollInterval）
+func (o *Options) PollInterval() time.Duration {
+	if o.Interval > 0 {
+		return o.Interval
+	}
+	return DefaultPollInterval
+}
+
 // DefaultPollInterval 默认轮询间隔
 const DefaultPollInterval = 200 * time.Millisecond
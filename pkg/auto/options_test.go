@@ -0,0 +1,36 @@
+package auto
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitPoll_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	o := ApplyOptions(WithInterval(time.Hour), WithContext(ctx))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	if err := o.WaitPoll(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("WaitPoll 应返回 context.Canceled, 实际 %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("取消后应立即返回, 耗时 %v", elapsed)
+	}
+}
+
+func TestWaitPoll_Default(t *testing.T) {
+	o := ApplyOptions(WithInterval(time.Millisecond))
+	if o.Context() == nil {
+		t.Fatal("未设置时 Context() 应返回 context.Background()")
+	}
+	if err := o.WaitPoll(); err != nil {
+		t.Errorf("WaitPoll 失败: %v", err)
+	}
+}
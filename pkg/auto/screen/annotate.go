@@ -0,0 +1,47 @@
+package screen
+
+import (
+	"fmt"
+	"image"
+
+	"gocv.io/x/gocv"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/vision/cv"
+)
+
+// Annotate 截取全屏，标出搜索区域（如有）后调用 draw 绘制其他标注，返回标注后的图像
+// draw 中使用屏幕坐标（与匹配结果经 AdjustMatchResult / AdjustPoint 换算后的坐标一致）
+func Annotate(o *auto.Options, draw func(mat *gocv.Mat)) (image.Image, error) {
+	fullImg, err := CaptureScreen()
+	if err != nil {
+		return nil, err
+	}
+	return AnnotateFrame(fullImg, o, draw)
+}
+
+// AnnotateFrame 同 Annotate，但在已截取的全屏图像 frame 上标注（不修改 frame）
+// 与 CropForMatch 配合，使标注的截图与实际匹配的截图为同一帧
+func AnnotateFrame(frame image.Image, o *auto.Options, draw func(mat *gocv.Mat)) (image.Image, error) {
+	mat, err := gocv.ImageToMatRGB(frame)
+	if err != nil {
+		return nil, fmt.Errorf("转换图像失败: %w", err)
+	}
+	defer mat.Close()
+
+	searchRegion, _ := SearchRegion(o)
+	if searchRegion != nil {
+		r := image.Rect(searchRegion.X, searchRegion.Y, searchRegion.X+searchRegion.Width, searchRegion.Y+searchRegion.Height)
+		gocv.Rectangle(&mat, r, cv.ColorRegion, 2)
+		cv.DrawLabel(&mat, "search region", image.Pt(r.Min.X, r.Min.Y-4), cv.ColorRegion)
+	}
+	if draw != nil {
+		draw(&mat)
+	}
+
+	annotated, err := mat.ToImage()
+	if err != nil {
+		return nil, fmt.Errorf("Mat 转换失败: %w", err)
+	}
+	return annotated, nil
+}
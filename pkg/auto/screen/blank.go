@@ -0,0 +1,33 @@
+package screen
+
+import "image"
+
+// 黑屏判断参数：按网格采样，亮度（0-255）全部不超过 blankMaxLuma 且极差不超过 blankMaxSpread 时视为黑屏
+const (
+	blankSampleGrid = 64
+	blankMaxLuma    = 48
+	blankMaxSpread  = 16
+)
+
+// IsBlankFrame 判断截图是否为近乎纯黑的画面（锁屏、安全桌面或显示器关闭时截图通常是全黑的）
+// 只认为"暗且均匀"的画面是黑屏，纯白或浅色的空白页面不算
+func IsBlankFrame(img image.Image) bool {
+	b := img.Bounds()
+	if b.Empty() {
+		return true
+	}
+	lo, hi := uint32(255), uint32(0)
+	for gy := 0; gy < blankSampleGrid; gy++ {
+		y := b.Min.Y + (2*gy+1)*b.Dy()/(2*blankSampleGrid)
+		for gx := 0; gx < blankSampleGrid; gx++ {
+			x := b.Min.X + (2*gx+1)*b.Dx()/(2*blankSampleGrid)
+			r, g, bl, _ := img.At(x, y).RGBA()
+			luma := (299*r + 587*g + 114*bl) / 1000 >> 8
+			lo, hi = min(lo, luma), max(hi, luma)
+			if hi > blankMaxLuma || hi-lo > blankMaxSpread {
+				return false
+			}
+		}
+	}
+	return true
+}
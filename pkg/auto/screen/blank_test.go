@@ -0,0 +1,51 @@
+package screen
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func filled(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestIsBlankFrame(t *testing.T) {
+	if !IsBlankFrame(filled(320, 200, color.Black)) {
+		t.Error("纯黑画面应判定为黑屏")
+	}
+	if !IsBlankFrame(filled(320, 200, color.RGBA{R: 12, G: 14, B: 20, A: 255})) {
+		t.Error("接近纯黑的暗色画面应判定为黑屏")
+	}
+	if IsBlankFrame(filled(320, 200, color.White)) {
+		t.Error("纯白画面（空白页面）不应判定为黑屏")
+	}
+	if IsBlankFrame(filled(320, 200, color.RGBA{R: 40, G: 80, B: 160, A: 255})) {
+		t.Error("纯色桌面背景不应判定为黑屏")
+	}
+
+	// 黑色背景上有少量文字（深色终端）
+	img := filled(320, 200, color.Black)
+	for y := 90; y < 110; y++ {
+		for x := 0; x < 320; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 200, B: 200, A: 255})
+		}
+	}
+	if IsBlankFrame(img) {
+		t.Error("含有明亮内容的暗色画面不应判定为黑屏")
+	}
+
+	if !IsBlankFrame(image.NewRGBA(image.Rect(0, 0, 0, 0))) {
+		t.Error("空图像应判定为黑屏")
+	}
+	// 小于采样网格的图像
+	if !IsBlankFrame(filled(3, 2, color.Black)) {
+		t.Error("小图像也应能判定")
+	}
+}
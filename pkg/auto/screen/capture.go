@@ -1,7 +1,8 @@
 // Package screen 提供屏幕截图和编码功能
 //
 // 坐标原则：截图多大，屏幕就是多大。
-// CaptureImg 返回的 Bounds 就是 robotgo.Move 的坐标范围，不需要额外缩放。
+// 对外的坐标统一为截图像素坐标；与 robotgo 输入坐标（如 macOS Retina 的点坐标）之间的换算
+// 由 auto.NormalizePointForInput 等函数完成，见 auto.GetScreenScaleFactor。
 package screen
 
 import (
@@ -10,6 +11,8 @@ import (
 	"sync"
 
 	"github.com/go-vgo/robotgo"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
 )
 
 var (
@@ -34,8 +37,9 @@ func CaptureScreen() (image.Image, error) {
 	return img, nil
 }
 
-// CaptureRegion 截取屏幕指定区域
+// CaptureRegion 截取屏幕指定区域（截图坐标）
 func CaptureRegion(x, y, width, height int) (image.Image, error) {
+	x, y, width, height = auto.NormalizeRegionForCapture(x, y, width, height)
 	img, err := robotgo.CaptureImg(x, y, width, height)
 	if err != nil {
 		return nil, fmt.Errorf("截取区域失败: %w", err)
@@ -43,6 +47,22 @@ func CaptureRegion(x, y, width, height int) (image.Image, error) {
 	return img, nil
 }
 
+// Probe 检查当前会话能否截图（截取 1x1 区域）
+// Windows 服务在用户登录前运行于没有桌面的会话 0，此时截图不可用；截图库的 panic 也作为错误返回
+func Probe() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("截屏失败: %v", r)
+		}
+	}()
+	w, h := robotgo.GetScreenSize()
+	if w <= 0 || h <= 0 {
+		return fmt.Errorf("截屏失败: 没有可用的显示器")
+	}
+	_, err = CaptureRegion(0, 0, 1, 1)
+	return err
+}
+
 // GetScreenSize 返回截图的实际像素尺寸（= robotgo.Move 的坐标范围）
 func GetScreenSize() (width, height int) {
 	captureSizeMu.RLock()
@@ -58,10 +78,57 @@ func GetScreenSize() (width, height int) {
 		captureSizeMu.RUnlock()
 		return w, h
 	}
-	return robotgo.GetScreenSize()
+	return auto.GetPhysicalScreenSize()
 }
 
 // GetDisplayCount 获取显示器数量
 func GetDisplayCount() int {
 	return robotgo.DisplaysNum()
 }
+
+// DisplayInfo 显示器信息
+type DisplayInfo struct {
+	Index       int         `json:"index"`
+	Bounds      auto.Region `json:"bounds"`
+	ScaleFactor float64     `json:"scale_factor"`
+	Primary     bool        `json:"primary"`
+}
+
+// GetDisplays 获取所有显示器的序号、区域（全局截图坐标）和缩放比例
+func GetDisplays() []DisplayInfo {
+	count := GetDisplayCount()
+	displays := make([]DisplayInfo, 0, count)
+	for i := 0; i < count; i++ {
+		x, y, w, h := robotgo.GetDisplayBounds(i)
+		x, y, w, h = auto.NormalizeRegionForScreen(x, y, w, h)
+		displays = append(displays, DisplayInfo{
+			Index:       i,
+			Bounds:      auto.Region{X: x, Y: y, Width: w, Height: h},
+			ScaleFactor: robotgo.ScaleF(i),
+			Primary:     x == 0 && y == 0,
+		})
+	}
+	return displays
+}
+
+// CaptureDisplay 截取指定显示器
+func CaptureDisplay(id int) (image.Image, error) {
+	region, err := DisplayBounds(id)
+	if err != nil {
+		return nil, err
+	}
+	return CaptureRegion(region.X, region.Y, region.Width, region.Height)
+}
+
+// DisplayBounds 获取指定显示器的区域（全局坐标）
+func DisplayBounds(id int) (auto.Region, error) {
+	if count := GetDisplayCount(); id < 0 || id >= count {
+		return auto.Region{}, auto.Errorf(auto.ErrParam, "显示器序号超出范围: %d (共 %d 个)", id, count)
+	}
+	x, y, w, h := robotgo.GetDisplayBounds(id)
+	x, y, w, h = auto.NormalizeRegionForScreen(x, y, w, h)
+	if w <= 0 || h <= 0 {
+		return auto.Region{}, fmt.Errorf("获取显示器 %d 区域失败", id)
+	}
+	return auto.Region{X: x, Y: y, Width: w, Height: h}, nil
+}
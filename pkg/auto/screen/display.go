@@ -0,0 +1,41 @@
+package screen
+
+import (
+	"sync"
+
+	"github.com/go-vgo/robotgo"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+// Display 主显示器的分辨率（截图像素）和缩放比例（截图像素 / 鼠标输入坐标）
+type Display struct {
+	Width       int     `json:"width"`
+	Height      int     `json:"height"`
+	ScaleFactor float64 `json:"scale_factor"`
+}
+
+var (
+	reportedW, reportedH int
+	reportedMu           sync.Mutex
+)
+
+// CurrentDisplay 返回主显示器当前的分辨率和缩放比例
+// 每次调用只查询 robotgo 报告的屏幕尺寸（开销很小，不截图）；尺寸与上次不同时（如笔记本接入或断开扩展坞）
+// 重置截图尺寸和坐标缩放缓存，再重新检测
+func CurrentDisplay() Display {
+	w, h := robotgo.GetScreenSize()
+	reportedMu.Lock()
+	changed := reportedW != 0 && (w != reportedW || h != reportedH)
+	reportedW, reportedH = w, h
+	reportedMu.Unlock()
+	if changed {
+		captureSizeMu.Lock()
+		lastCaptureW, lastCaptureH = 0, 0
+		captureSizeMu.Unlock()
+		auto.ResetDPIScaleCache()
+	}
+
+	width, height := GetScreenSize()
+	return Display{Width: width, Height: height, ScaleFactor: auto.GetScreenScaleFactor()}
+}
@@ -7,8 +7,13 @@ import (
 	"image"
 	"image/jpeg"
 	"image/png"
+
+	"gocv.io/x/gocv"
 )
 
+// DefaultScreenshotMaxWidth 步骤截图默认最大宽度（4K/Retina 屏截图缩小后再传输）
+const DefaultScreenshotMaxWidth = 1280
+
 // ImageToBase64 将图像转换为 Base64 字符串
 // format: "png" 或 "jpeg"，默认 "jpeg"（更小的体积）
 // quality: JPEG 质量 1-100，默认 80
@@ -48,11 +53,56 @@ func ImageToBase64(img image.Image, format string, quality int) (string, error)
 	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64Str), nil
 }
 
-// CaptureScreenToBase64 截取屏幕并转换为 Base64
-func CaptureScreenToBase64(quality int) (string, error) {
+// DownscaleImage 将图像等比缩小到宽度不超过 maxWidth
+// 返回缩放后的图像和缩放比例（截图像素 / 屏幕像素，<= 1）
+// maxWidth <= 0 或图像宽度未超出时原样返回，比例为 1
+func DownscaleImage(img image.Image, maxWidth int) (image.Image, float64, error) {
+	if img == nil {
+		return nil, 0, fmt.Errorf("图像为空")
+	}
+	width := img.Bounds().Dx()
+	if maxWidth <= 0 || width <= maxWidth {
+		return img, 1, nil
+	}
+
+	scale := float64(maxWidth) / float64(width)
+	height := int(float64(img.Bounds().Dy())*scale + 0.5)
+	if height < 1 {
+		height = 1
+	}
+
+	src, err := gocv.ImageToMatRGB(img)
+	if err != nil {
+		return nil, 0, fmt.Errorf("转换图像失败: %w", err)
+	}
+	defer src.Close()
+
+	dst := gocv.NewMat()
+	defer dst.Close()
+	gocv.Resize(src, &dst, image.Point{X: maxWidth, Y: height}, 0, 0, gocv.InterpolationArea)
+
+	resized, err := dst.ToImage()
+	if err != nil {
+		return nil, 0, fmt.Errorf("Mat 转换失败: %w", err)
+	}
+	return resized, scale, nil
+}
+
+// CaptureScreenToBase64 截取屏幕并转换为 Base64（JPEG）
+// maxWidth > 0 时先等比缩小到该宽度再编码，返回截图相对屏幕坐标的缩放比例，
+// 屏幕坐标乘以该比例即为截图上的位置
+func CaptureScreenToBase64(quality, maxWidth int) (string, float64, error) {
 	img, err := CaptureScreen()
 	if err != nil {
-		return "", err
+		return "", 0, err
+	}
+	img, scale, err := DownscaleImage(img, maxWidth)
+	if err != nil {
+		return "", 0, err
+	}
+	encoded, err := ImageToBase64(img, "jpeg", quality)
+	if err != nil {
+		return "", 0, err
 	}
-	return ImageToBase64(img, "jpeg", quality)
+	return encoded, scale, nil
 }
@@ -0,0 +1,7 @@
+package screen
+
+// SessionLocked 通过平台接口查询当前会话是否已锁屏
+// known 为 false 表示当前平台或环境无法判断（此时 locked 无意义），调用方可改用 IsBlankFrame 检测黑屏
+func SessionLocked() (locked, known bool) {
+	return sessionLockedPlatform()
+}
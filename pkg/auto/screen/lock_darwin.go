@@ -0,0 +1,37 @@
+//go:build darwin
+
+package screen
+
+/*
+#cgo LDFLAGS: -framework CoreGraphics -framework CoreFoundation
+#include <CoreFoundation/CoreFoundation.h>
+#include <CoreGraphics/CoreGraphics.h>
+
+// 读取当前会话的锁屏状态：1 锁定，0 未锁定，-1 无法判断（不在图形会话中）
+static int sessionScreenLocked() {
+    CFDictionaryRef dict = CGSessionCopyCurrentDictionary();
+    if (dict == NULL) {
+        return -1;
+    }
+    int locked = 0;
+    CFBooleanRef value = (CFBooleanRef)CFDictionaryGetValue(dict, CFSTR("CGSSessionScreenIsLocked"));
+    if (value != NULL && CFGetTypeID(value) == CFBooleanGetTypeID() && CFBooleanGetValue(value)) {
+        locked = 1;
+    }
+    CFRelease(dict);
+    return locked;
+}
+*/
+import "C"
+
+// sessionLockedPlatform macOS：读取 CGSessionCopyCurrentDictionary 中的 CGSSessionScreenIsLocked
+func sessionLockedPlatform() (locked, known bool) {
+	switch C.sessionScreenLocked() {
+	case 1:
+		return true, true
+	case 0:
+		return false, true
+	default:
+		return false, false
+	}
+}
@@ -0,0 +1,22 @@
+//go:build linux
+
+package screen
+
+import "github.com/godbus/dbus/v5"
+
+// sessionLockedPlatform Linux：读取 systemd-logind 当前会话的 LockedHint（由 GNOME、KDE 等桌面在锁屏时设置）
+// 没有 logind 或 Worker 不在图形会话中时视为无法判断
+func sessionLockedPlatform() (locked, known bool) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return false, false
+	}
+	defer conn.Close()
+	obj := conn.Object("org.freedesktop.login1", "/org/freedesktop/login1/session/auto")
+	v, err := obj.GetProperty("org.freedesktop.login1.Session.LockedHint")
+	if err != nil {
+		return false, false
+	}
+	hint, ok := v.Value().(bool)
+	return hint, ok
+}
@@ -0,0 +1,8 @@
+//go:build !darwin && !windows && !linux
+
+package screen
+
+// sessionLockedPlatform 其他平台无法判断锁屏状态
+func sessionLockedPlatform() (locked, known bool) {
+	return false, false
+}
@@ -0,0 +1,65 @@
+//go:build windows
+
+package screen
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	wtsapi32                        = syscall.NewLazyDLL("wtsapi32.dll")
+	procWTSQuerySessionInformationW = wtsapi32.NewProc("WTSQuerySessionInformationW")
+	procWTSFreeMemory               = wtsapi32.NewProc("WTSFreeMemory")
+)
+
+const (
+	wtsCurrentServerHandle = 0
+	wtsCurrentSession      = 0xFFFFFFFF
+	wtsSessionInfoEx       = 25
+
+	// WTSINFOEX_LEVEL1_W.SessionFlags 的取值
+	wtsSessionStateLock   = 0
+	wtsSessionStateUnlock = 1
+)
+
+// wtsInfoEx WTSINFOEXW 的前部：Level 与 WTSINFOEX_LEVEL1_W 的前三个字段
+type wtsInfoEx struct {
+	Level        uint32
+	SessionID    uint32
+	SessionState int32
+	SessionFlags int32
+}
+
+// sessionLockedPlatform Windows：WTSQuerySessionInformation(WTSSessionInfoEx) 读取当前会话的锁定状态
+// Windows 8 及以上可靠（Windows 7 上 SessionFlags 含义相反）；其他取值（WTS_SESSIONSTATE_UNKNOWN）视为无法判断
+func sessionLockedPlatform() (locked, known bool) {
+	if procWTSQuerySessionInformationW.Find() != nil {
+		return false, false
+	}
+	var info *wtsInfoEx
+	var size uint32
+	ret, _, _ := procWTSQuerySessionInformationW.Call(
+		wtsCurrentServerHandle,
+		wtsCurrentSession,
+		wtsSessionInfoEx,
+		uintptr(unsafe.Pointer(&info)),
+		uintptr(unsafe.Pointer(&size)),
+	)
+	if ret == 0 || info == nil {
+		return false, false
+	}
+	defer procWTSFreeMemory.Call(uintptr(unsafe.Pointer(info)))
+	if size < uint32(unsafe.Sizeof(*info)) {
+		return false, false
+	}
+	flags := info.SessionFlags
+	switch flags {
+	case wtsSessionStateLock:
+		return true, true
+	case wtsSessionStateUnlock:
+		return false, true
+	default:
+		return false, false
+	}
+}
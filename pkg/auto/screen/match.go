@@ -3,6 +3,7 @@ package screen
 import (
 	"fmt"
 	"image"
+	"image/draw"
 
 	"gocv.io/x/gocv"
 
@@ -18,17 +19,43 @@ type CaptureMeta struct {
 	OffsetY int
 }
 
-// CaptureForMatch 截图用于匹配，返回 gocv.Mat 和元信息
-func CaptureForMatch(o *auto.Options) (gocv.Mat, CaptureMeta, error) {
-	var img image.Image
-	var err error
-
+// SearchRegion 返回实际搜索区域：优先 Region，其次 DisplayID 对应的显示器，nil 表示主显示器全屏
+func SearchRegion(o *auto.Options) (*auto.Region, error) {
 	if o.Region != nil {
-		img, err = CaptureRegion(o.Region.X, o.Region.Y, o.Region.Width, o.Region.Height)
+		return o.Region, nil
+	}
+	if o.DisplayID >= 0 {
+		region, err := DisplayBounds(o.DisplayID)
+		if err != nil {
+			return nil, err
+		}
+		return &region, nil
+	}
+	return nil, nil
+}
+
+// CaptureSearchArea 按搜索区域截图（Region / DisplayID / 全屏）
+func CaptureSearchArea(o *auto.Options) (image.Image, *auto.Region, error) {
+	region, err := SearchRegion(o)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var img image.Image
+	if region != nil {
+		img, err = CaptureRegion(region.X, region.Y, region.Width, region.Height)
 	} else {
 		img, err = CaptureScreen()
 	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return img, region, nil
+}
 
+// CaptureForMatch 截图用于匹配，返回 gocv.Mat 和元信息
+func CaptureForMatch(o *auto.Options) (gocv.Mat, CaptureMeta, error) {
+	img, region, err := CaptureSearchArea(o)
 	if err != nil {
 		return gocv.Mat{}, CaptureMeta{}, fmt.Errorf("截屏失败: %w", err)
 	}
@@ -38,23 +65,50 @@ func CaptureForMatch(o *auto.Options) (gocv.Mat, CaptureMeta, error) {
 		return gocv.Mat{}, CaptureMeta{}, fmt.Errorf("转换图像失败: %w", err)
 	}
 
-	meta := BuildCaptureMeta(o, img)
+	meta := BuildCaptureMeta(region, img)
 	return mat, meta, nil
 }
 
-// BuildCaptureMeta 构建截图元信息
-func BuildCaptureMeta(o *auto.Options, img image.Image) CaptureMeta {
+// CropForMatch 从全屏截图 frame 中裁剪出搜索区域用于匹配，返回值同 CaptureForMatch
+// 用于在同一帧上匹配和标注（见 AnnotateFrame）；搜索区域超出 frame 的部分被裁掉
+func CropForMatch(frame image.Image, o *auto.Options) (gocv.Mat, CaptureMeta, error) {
+	region, err := SearchRegion(o)
+	if err != nil {
+		return gocv.Mat{}, CaptureMeta{}, err
+	}
+
+	img := frame
+	if region != nil {
+		r := image.Rect(region.X, region.Y, region.X+region.Width, region.Y+region.Height).Intersect(frame.Bounds())
+		if r.Empty() {
+			return gocv.Mat{}, CaptureMeta{}, fmt.Errorf("搜索区域 (%d,%d %dx%d) 不在屏幕截图范围内", region.X, region.Y, region.Width, region.Height)
+		}
+		cropped := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+		draw.Draw(cropped, cropped.Bounds(), frame, r.Min, draw.Src)
+		img = cropped
+		region = &auto.Region{X: r.Min.X, Y: r.Min.Y, Width: r.Dx(), Height: r.Dy()}
+	}
+
+	mat, err := gocv.ImageToMatRGB(img)
+	if err != nil {
+		return gocv.Mat{}, CaptureMeta{}, fmt.Errorf("转换图像失败: %w", err)
+	}
+	return mat, BuildCaptureMeta(region, img), nil
+}
+
+// BuildCaptureMeta 按实际截图区域构建元信息（region 为 nil 表示全屏）
+func BuildCaptureMeta(region *auto.Region, img image.Image) CaptureMeta {
 	bounds := img.Bounds()
 	imgW := bounds.Dx()
 	imgH := bounds.Dy()
 
 	expectedW, expectedH := GetScreenSize()
 	offsetX, offsetY := 0, 0
-	if o.Region != nil {
-		expectedW = o.Region.Width
-		expectedH = o.Region.Height
-		offsetX = o.Region.X
-		offsetY = o.Region.Y
+	if region != nil {
+		expectedW = region.Width
+		expectedH = region.Height
+		offsetX = region.X
+		offsetY = region.Y
 	}
 
 	scaleX := 1.0
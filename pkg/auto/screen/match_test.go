@@ -0,0 +1,30 @@
+package screen
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+func TestCropForMatch(t *testing.T) {
+	frame := filled(200, 100, color.White)
+	// frame 视为最近一次全屏截图
+	captureSizeMu.Lock()
+	lastCaptureW, lastCaptureH = 200, 100
+	captureSizeMu.Unlock()
+
+	mat, meta, err := CropForMatch(frame, &auto.Options{DisplayID: -1, Region: &auto.Region{X: 150, Y: 20, Width: 100, Height: 40}})
+	if err != nil {
+		t.Fatalf("裁剪失败: %v", err)
+	}
+	mat.Close()
+	// 超出截图的部分被裁掉，偏移为区域左上角，缩放为 1
+	if meta != (CaptureMeta{ScaleX: 1, ScaleY: 1, OffsetX: 150, OffsetY: 20}) {
+		t.Errorf("元信息错误: %+v", meta)
+	}
+
+	if _, _, err := CropForMatch(frame, &auto.Options{DisplayID: -1, Region: &auto.Region{X: 300, Y: 0, Width: 50, Height: 50}}); err == nil {
+		t.Error("搜索区域不在截图范围内时应返回错误")
+	}
+}
@@ -0,0 +1,30 @@
+package text
+
+import (
+	"fmt"
+	"image"
+
+	"gocv.io/x/gocv"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+	"github.com/zoeyai/zoeyworker/pkg/vision/cv"
+)
+
+// AnnotateText 截取全屏并标注文字查找结果（用于调试定位）：搜索区域（如有）、命中行的文字框和置信度
+// 识别结果没有文字框时以中心点标注
+func AnnotateText(match *TextMatch, opts ...auto.Option) (image.Image, error) {
+	o := auto.ApplyOptions(opts...)
+	return screen.Annotate(o, func(mat *gocv.Mat) {
+		label := fmt.Sprintf("text %.2f", match.Confidence)
+		b := match.Bounds
+		if b.Width > 0 && b.Height > 0 {
+			gocv.Rectangle(mat, image.Rect(b.X, b.Y, b.X+b.Width, b.Y+b.Height), cv.ColorMatched, 3)
+			cv.DrawLabel(mat, label, image.Pt(b.X, b.Y-4), cv.ColorMatched)
+			return
+		}
+		center := image.Pt(match.Position.X, match.Position.Y)
+		gocv.Circle(mat, center, 12, cv.ColorMatched, 3)
+		cv.DrawLabel(mat, label, image.Pt(center.X-12, center.Y-16), cv.ColorMatched)
+	})
+}
@@ -2,6 +2,10 @@ package text
 
 import (
 	"fmt"
+	"image"
+	"image/draw"
+	"sync"
+	"time"
 
 	"github.com/zoeyai/zoeyworker/pkg/vision/ocr"
 )
@@ -10,36 +14,65 @@ import (
 type OCRPluginInterface interface {
 	IsInstalled() bool
 	GetConfig() (onnxPath, detPath, recPath, dictPath string, err error)
+	// GetClsModelPath 方向分类模型路径（可选，未下载时为空）
+	GetClsModelPath() string
 }
 
-// 全局 OCR 识别器和插件
+// 全局 OCR 识别器和插件，均由 textRecognizersMu 保护
 var (
-	globalTextRecognizer *ocr.TextRecognizer
-	ocrPluginInstance    OCRPluginInterface
+	// textRecognizers 按语言缓存的识别器（InitOCR 或插件配置创建，为 nil 时使用 ocr 包的全局识别器）
+	textRecognizers   *ocr.ModelRegistry
+	textRecognizersMu sync.Mutex
+	ocrPluginInstance OCRPluginInterface
 )
 
-// InitOCR 初始化 OCR（可选，不调用会自动初始化）
+// textFinder 识别和查找文字（*ocr.TextRecognizer 实现）
+type textFinder interface {
+	FindTextMatch(img image.Image, m ocr.TextMatch) (*ocr.OcrResult, error)
+	Recognize(img image.Image) ([]ocr.OcrResult, error)
+}
+
+// acquireFinder 借出指定语言的识别器，用完后调用 release（测试时替换）
+var acquireFinder = func(language string) (textFinder, func(), error) {
+	recognizer, release, err := acquireTextRecognizer(language)
+	if err != nil {
+		return nil, nil, err
+	}
+	return recognizer, release, nil
+}
+
+// InitOCR 初始化 OCR（可选，不调用会自动初始化），config 为默认语言的配置
 func InitOCR(config ocr.Config) error {
-	var err error
-	globalTextRecognizer, err = ocr.NewTextRecognizer(config)
-	return err
+	registry := ocr.NewModelRegistry(config, ocr.DefaultMaxResidentModels)
+	if _, err := registry.Get(""); err != nil {
+		return err
+	}
+
+	textRecognizersMu.Lock()
+	defer textRecognizersMu.Unlock()
+	if textRecognizers != nil {
+		textRecognizers.Close()
+	}
+	textRecognizers = registry
+	return nil
 }
 
 // SetOCRPlugin 设置 OCR 插件实例（避免循环导入）
 func SetOCRPlugin(p OCRPluginInterface) {
+	textRecognizersMu.Lock()
+	defer textRecognizersMu.Unlock()
 	ocrPluginInstance = p
 }
 
-// getOCRPlugin 获取 OCR 插件
-func getOCRPlugin() OCRPluginInterface {
-	return ocrPluginInstance
-}
+// acquireTextRecognizer 借出指定语言的 OCR 识别器（空字符串为默认语言），release 前不会因模型淘汰被关闭
+// 其他语言的模型从插件目录的 <lang>/ 子目录加载，最多同时驻留 ocr.DefaultMaxResidentModels 个
+func acquireTextRecognizer(language string) (*ocr.TextRecognizer, func(), error) {
+	textRecognizersMu.Lock()
+	defer textRecognizersMu.Unlock()
 
-// getTextRecognizer 获取或创建 OCR 识别器
-func getTextRecognizer() (*ocr.TextRecognizer, error) {
-	if globalTextRecognizer == nil {
+	if textRecognizers == nil {
 		// 尝试使用插件提供的配置
-		ocrPlugin := getOCRPlugin()
+		ocrPlugin := ocrPluginInstance
 		if ocrPlugin != nil && ocrPlugin.IsInstalled() {
 			onnxPath, detPath, recPath, dictPath, err := ocrPlugin.GetConfig()
 			if err == nil {
@@ -48,21 +81,45 @@ func getTextRecognizer() (*ocr.TextRecognizer, error) {
 					DetModelPath:       detPath,
 					RecModelPath:       recPath,
 					DictPath:           dictPath,
+					ClsModelPath:       ocrPlugin.GetClsModelPath(),
+					Language:           "ch",
 				}
-				recognizer, err := ocr.NewTextRecognizer(config)
-				if err == nil {
-					globalTextRecognizer = recognizer
-					return globalTextRecognizer, nil
+				registry := ocr.NewModelRegistry(config, ocr.DefaultMaxResidentModels)
+				if _, err := registry.Get(""); err == nil {
+					textRecognizers = registry
 				}
 			}
 		}
+	}
+	if textRecognizers != nil {
+		return textRecognizers.Acquire(language)
+	}
 
-		// 回退到默认配置
-		recognizer, err := ocr.GetGlobalRecognizer()
-		if err != nil {
-			return nil, fmt.Errorf("初始化 OCR 失败: %w", err)
-		}
-		globalTextRecognizer = recognizer
+	// 回退到默认配置
+	recognizer, release, err := ocr.AcquireRecognizer(language)
+	if err != nil {
+		return nil, nil, fmt.Errorf("初始化 OCR 失败: %w", err)
+	}
+	return recognizer, release, nil
+}
+
+// warmupImageSize 预热推理使用的空白图像尺寸（足够小，推理本身耗时可忽略）
+var warmupImageSize = image.Rect(0, 0, 64, 32)
+
+// Warmup 加载默认语言的 OCR 模型并对一张空白小图执行一次推理，返回总耗时
+// 用于启动后提前完成 ONNX Runtime 和模型的加载，避免首个文字类步骤超时
+func Warmup() (time.Duration, error) {
+	start := time.Now()
+	recognizer, release, err := acquireTextRecognizer("")
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	img := image.NewRGBA(warmupImageSize)
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	if _, err := recognizer.Recognize(img); err != nil {
+		return 0, err
 	}
-	return globalTextRecognizer, nil
+	return time.Since(start), nil
 }
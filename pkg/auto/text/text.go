@@ -2,78 +2,228 @@
 package text
 
 import (
+	"errors"
 	"fmt"
 	"image"
+	"regexp"
 	"time"
 
 	"github.com/zoeyai/zoeyworker/pkg/auto"
 	"github.com/zoeyai/zoeyworker/pkg/auto/input"
 	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+	"github.com/zoeyai/zoeyworker/pkg/vision/ocr"
 )
 
-// ClickText 点击文字位置
+// captureSearchArea 截取搜索区域，返回截图及换算回屏幕坐标所需的信息（测试时替换）
+var captureSearchArea = func(o *auto.Options) (image.Image, screen.CaptureMeta, error) {
+	img, region, err := screen.CaptureSearchArea(o)
+	if err != nil {
+		return nil, screen.CaptureMeta{}, err
+	}
+	return img, screen.BuildCaptureMeta(region, img), nil
+}
+
+// TextMatch 文字查找结果
+type TextMatch struct {
+	// Position 文字中心的屏幕坐标
+	Position auto.Point
+	// Text 实际命中的识别文字
+	Text string
+	// Confidence 命中行的 OCR 识别置信度 (0-1)
+	Confidence float64
+	// Bounds 命中行文字框的外接矩形（识别结果没有文字框时为零值）
+	Bounds auto.Region
+}
+
+// TextLine 识别出的一行文字（屏幕坐标）
+type TextLine struct {
+	// Text 识别文字
+	Text string
+	// Confidence OCR 识别置信度 (0-1)
+	Confidence float64
+	// Position 文字中心的屏幕坐标
+	Position auto.Point
+	// Bounds 文字框的外接矩形
+	Bounds auto.Region
+}
+
 func ClickText(text string, opts ...auto.Option) error {
+	_, err := ClickTextResult(text, opts...)
+	return err
+}
+
+// ClickTextResult 点击文字位置，并返回实际点击的坐标（含点击偏移）
+func ClickTextResult(text string, opts ...auto.Option) (*auto.Point, error) {
+	clickPos, _, err := ClickTextMatch(text, opts...)
+	return clickPos, err
+}
+
+// ClickTextMatch 点击文字位置，返回实际点击的坐标（含点击偏移）和命中的文字
+func ClickTextMatch(text string, opts ...auto.Option) (*auto.Point, *TextMatch, error) {
 	o := auto.ApplyOptions(opts...)
 
-	pos, err := waitForTextInternal(text, o)
+	match, err := waitForTextInternal(text, o)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	return input.ClickAt(pos.X+o.ClickOffset.X, pos.Y+o.ClickOffset.Y, o)
+	pos := match.Position
+	clickPos := &auto.Point{X: pos.X + o.ClickOffset.X, Y: pos.Y + o.ClickOffset.Y}
+	if err := input.ClickAt(clickPos.X, clickPos.Y, o); err != nil {
+		return nil, nil, err
+	}
+	return clickPos, match, nil
 }
 
 // WaitForText 等待文字出现
 func WaitForText(text string, opts ...auto.Option) (*auto.Point, error) {
+	match, err := WaitForTextMatch(text, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &match.Position, nil
+}
+
+// WaitForTextMatch 等待文字出现，返回位置和命中的文字
+func WaitForTextMatch(text string, opts ...auto.Option) (*TextMatch, error) {
 	o := auto.ApplyOptions(opts...)
 	return waitForTextInternal(text, o)
 }
 
 // TextExists 检查文字是否存在
 func TextExists(text string, opts ...auto.Option) bool {
+	match, _ := FindText(text, opts...)
+	return match != nil
+}
+
+// FindText 立即查找一次文字（忽略超时），未找到时返回 nil, nil
+// 与 TextExists 不同，匹配条件非法（如无效正则）等错误会返回；
+// 目标只出现在低置信度识别结果中时返回 auto.ErrNotFound，错误信息包含最佳候选及其置信度
+func FindText(text string, opts ...auto.Option) (*TextMatch, error) {
 	o := auto.ApplyOptions(opts...)
 	o.Timeout = 0
-	pos, _ := waitForTextInternal(text, o)
-	return pos != nil
+	match, err := waitForTextInternal(text, o)
+	if errors.Is(err, auto.ErrTimeout) {
+		return nil, nil
+	}
+	return match, err
+}
+
+// textMatch 由选项构造 OCR 匹配条件
+func textMatch(text string, o *auto.Options) (ocr.TextMatch, error) {
+	mode, err := ocr.ParseMatchMode(o.TextMatchMode)
+	if err != nil {
+		return ocr.TextMatch{}, auto.Errorf(auto.ErrParam, "%v", err)
+	}
+	if mode == ocr.MatchModeRegex {
+		if _, err := regexp.Compile(text); err != nil {
+			return ocr.TextMatch{}, auto.Errorf(auto.ErrParam, "无效的正则表达式 %q: %v", text, err)
+		}
+	}
+	return ocr.TextMatch{Text: text, Mode: mode, MaxDistance: o.TextMaxDistance, MinConfidence: o.MinOCRConfidence}, nil
 }
 
 // waitForTextInternal 内部等待文字函数
-func waitForTextInternal(text string, o *auto.Options) (*auto.Point, error) {
-	recognizer, err := getTextRecognizer()
+func waitForTextInternal(text string, o *auto.Options) (*TextMatch, error) {
+	m, err := textMatch(text, o)
 	if err != nil {
 		return nil, err
 	}
+	language, err := ocr.NormalizeLanguage(o.Language)
+	if err != nil {
+		return nil, auto.Errorf(auto.ErrParam, "%v", err)
+	}
+	recognizer, release, err := acquireFinder(language)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
+	// lowConfidence 最近一次只在低置信度结果中找到目标的原因，超时时用于提示调整阈值
+	var lowConfidence *ocr.LowConfidenceError
 	startTime := time.Now()
 	for {
 		// 截图
-		var img image.Image
-		var captureErr error
-		if o.Region != nil {
-			img, captureErr = screen.CaptureRegion(o.Region.X, o.Region.Y, o.Region.Width, o.Region.Height)
-		} else {
-			img, captureErr = screen.CaptureScreen()
-		}
+		img, meta, captureErr := captureSearchArea(o)
 		if captureErr != nil {
 			return nil, captureErr
 		}
 
 		// OCR 查找文字
-		result, err := recognizer.FindText(img, text)
-		if err != nil {
+		result, err := recognizer.FindTextMatch(img, m)
+		var rejected *ocr.LowConfidenceError
+		if err != nil && !errors.As(err, &rejected) {
 			return nil, fmt.Errorf("OCR 识别失败: %w", err)
 		}
+		if rejected != nil {
+			lowConfidence = rejected
+		}
 
 		if result != nil {
-			meta := screen.BuildCaptureMeta(o, img)
-			adjusted := screen.AdjustPoint(auto.Point{X: result.X, Y: result.Y}, meta)
-			return &adjusted, nil
+			adjusted := screen.AdjustPoint(auto.Point{X: result.Position.X, Y: result.Position.Y}, meta)
+			return &TextMatch{Position: adjusted, Text: result.Text, Confidence: result.Confidence, Bounds: boxBounds(result.Box, meta)}, nil
 		}
 
 		if o.Timeout == 0 || time.Since(startTime) > o.Timeout {
-			return nil, fmt.Errorf("等待文字超时: %s", text)
+			if lowConfidence != nil {
+				return nil, auto.Errorf(auto.ErrNotFound, "未找到文字: %s（%w）", text, lowConfidence)
+			}
+			return nil, auto.Errorf(auto.ErrTimeout, "等待文字超时: %s", text)
+		}
+
+		if err := o.WaitPoll(); err != nil {
+			return nil, err
 		}
+	}
+}
 
-		time.Sleep(auto.DefaultPollInterval)
+// FindAllText 立即识别一次搜索区域内的全部文字行（不点击、不等待），
+// 按 MinOCRConfidence 过滤低置信度结果，按识别顺序返回
+func FindAllText(opts ...auto.Option) ([]TextLine, error) {
+	o := auto.ApplyOptions(opts...)
+	language, err := ocr.NormalizeLanguage(o.Language)
+	if err != nil {
+		return nil, auto.Errorf(auto.ErrParam, "%v", err)
+	}
+	recognizer, release, err := acquireFinder(language)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	img, meta, err := captureSearchArea(o)
+	if err != nil {
+		return nil, err
+	}
+	results, err := recognizer.Recognize(img)
+	if err != nil {
+		return nil, fmt.Errorf("OCR 识别失败: %w", err)
+	}
+
+	accepted, _ := ocr.FilterByConfidence(results, ocr.TextMatch{MinConfidence: o.MinOCRConfidence}.EffectiveMinConfidence())
+	lines := make([]TextLine, 0, len(accepted))
+	for _, r := range accepted {
+		lines = append(lines, TextLine{
+			Text:       r.Text,
+			Confidence: r.Confidence,
+			Position:   screen.AdjustPoint(auto.Point{X: r.Position.X, Y: r.Position.Y}, meta),
+			Bounds:     boxBounds(r.Box, meta),
+		})
+	}
+	return lines, nil
+}
+
+// boxBounds 将识别框换算为屏幕坐标下的外接矩形（无框时为零值）
+func boxBounds(box []ocr.Point, meta screen.CaptureMeta) auto.Region {
+	if len(box) == 0 {
+		return auto.Region{}
+	}
+	minX, minY, maxX, maxY := box[0].X, box[0].Y, box[0].X, box[0].Y
+	for _, p := range box[1:] {
+		minX, minY = min(minX, p.X), min(minY, p.Y)
+		maxX, maxY = max(maxX, p.X), max(maxY, p.Y)
 	}
+	topLeft := screen.AdjustPoint(auto.Point{X: minX, Y: minY}, meta)
+	bottomRight := screen.AdjustPoint(auto.Point{X: maxX, Y: maxY}, meta)
+	return auto.Region{X: topLeft.X, Y: topLeft.Y, Width: bottomRight.X - topLeft.X, Height: bottomRight.Y - topLeft.Y}
 }
@@ -0,0 +1,118 @@
+package text
+
+import (
+	"image"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+	"github.com/zoeyai/zoeyworker/pkg/vision/ocr"
+)
+
+// fakeFinder 在固定位置“识别”出目标文字
+type fakeFinder struct {
+	calls atomic.Int64
+}
+
+func (f *fakeFinder) FindTextMatch(img image.Image, m ocr.TextMatch) (*ocr.OcrResult, error) {
+	f.calls.Add(1)
+	return &ocr.OcrResult{Text: m.Text, Confidence: 0.9, Position: ocr.Point{X: 10, Y: 20}, Box: []ocr.Point{{X: 0, Y: 10}, {X: 20, Y: 10}, {X: 20, Y: 30}, {X: 0, Y: 30}}}, nil
+}
+
+func (f *fakeFinder) Recognize(image.Image) ([]ocr.OcrResult, error) {
+	f.calls.Add(1)
+	return []ocr.OcrResult{
+		{Text: "确定", Confidence: 0.9, Position: ocr.Point{X: 10, Y: 20}, Box: []ocr.Point{{X: 0, Y: 10}, {X: 20, Y: 10}, {X: 20, Y: 30}, {X: 0, Y: 30}}},
+		{Text: "取消", Confidence: 0.4, Position: ocr.Point{X: 40, Y: 20}},
+	}, nil
+}
+
+// stubOCR 替换截图和识别器，返回借出计数（借出 +1，归还 -1）
+func stubOCR(t *testing.T, finder textFinder) *atomic.Int64 {
+	t.Helper()
+	origCapture, origAcquire := captureSearchArea, acquireFinder
+	t.Cleanup(func() { captureSearchArea, acquireFinder = origCapture, origAcquire })
+
+	captureSearchArea = func(*auto.Options) (image.Image, screen.CaptureMeta, error) {
+		meta := screen.CaptureMeta{ScaleX: 1, ScaleY: 1, OffsetX: 100, OffsetY: 200}
+		return image.NewRGBA(image.Rect(0, 0, 50, 40)), meta, nil
+	}
+	var outstanding atomic.Int64
+	acquireFinder = func(string) (textFinder, func(), error) {
+		outstanding.Add(1)
+		return finder, func() { outstanding.Add(-1) }, nil
+	}
+	return &outstanding
+}
+
+func TestTextExists_Concurrent(t *testing.T) {
+	finder := &fakeFinder{}
+	outstanding := stubOCR(t, finder)
+
+	const workers, rounds = 8, 25
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < rounds; j++ {
+				if !TextExists("确定") {
+					t.Error("TextExists 应返回 true")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := finder.calls.Load(); got != workers*rounds {
+		t.Errorf("识别次数 = %d, 期望 %d", got, workers*rounds)
+	}
+	if got := outstanding.Load(); got != 0 {
+		t.Errorf("未归还的识别器 = %d, 每次查找后都应归还", got)
+	}
+}
+
+func TestFindText_AdjustsToScreen(t *testing.T) {
+	stubOCR(t, &fakeFinder{})
+
+	match, err := FindText("确定")
+	if err != nil || match == nil {
+		t.Fatalf("FindText 失败: match=%v err=%v", match, err)
+	}
+	// 区域截图内 (10,20) 换算回屏幕坐标
+	if match.Position != (auto.Point{X: 110, Y: 220}) {
+		t.Errorf("Position = %+v, 期望 (110,220)", match.Position)
+	}
+	if want := (auto.Region{X: 100, Y: 210, Width: 20, Height: 20}); match.Bounds != want {
+		t.Errorf("Bounds = %+v, 期望 %+v", match.Bounds, want)
+	}
+}
+
+func TestFindAllText(t *testing.T) {
+	outstanding := stubOCR(t, &fakeFinder{})
+
+	lines, err := FindAllText()
+	if err != nil {
+		t.Fatalf("FindAllText 失败: %v", err)
+	}
+	// 默认过滤置信度低于 0.6 的结果
+	if len(lines) != 1 || lines[0].Text != "确定" {
+		t.Fatalf("结果 = %+v, 期望只有 '确定'", lines)
+	}
+	if lines[0].Position != (auto.Point{X: 110, Y: 220}) {
+		t.Errorf("Position = %+v, 期望 (110,220)", lines[0].Position)
+	}
+	if want := (auto.Region{X: 100, Y: 210, Width: 20, Height: 20}); lines[0].Bounds != want {
+		t.Errorf("Bounds = %+v, 期望 %+v", lines[0].Bounds, want)
+	}
+
+	if lines, _ := FindAllText(auto.WithMinOCRConfidence(-1)); len(lines) != 2 {
+		t.Errorf("不过滤时结果数 = %d, 期望 2", len(lines))
+	}
+	if got := outstanding.Load(); got != 0 {
+		t.Errorf("未归还的识别器 = %d", got)
+	}
+}
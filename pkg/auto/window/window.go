@@ -18,6 +18,7 @@ import (
 // WindowInfo 窗口信息
 type WindowInfo struct {
 	PID       int         `json:"pid"`
+	Handle    int64       `json:"handle,omitempty"` // 原生窗口句柄（Windows 为 HWND，macOS 为 CGWindowID，其他平台为 0）
 	Title     string      `json:"title"`
 	OwnerName string      `json:"owner_name"`
 	Bounds    auto.Region `json:"bounds"`
@@ -86,7 +87,7 @@ func GetWindowByTitle(title string) (*WindowInfo, error) {
 	}
 
 	if len(windows) == 0 {
-		return nil, fmt.Errorf("未找到标题包含 %q 的窗口", title)
+		return nil, auto.Errorf(auto.ErrNotFound, "未找到标题包含 %q 的窗口", title)
 	}
 
 	return &windows[0], nil
@@ -96,7 +97,7 @@ func GetWindowByTitle(title string) (*WindowInfo, error) {
 func GetWindowByPID(pid int) (*WindowInfo, error) {
 	title := robotgo.GetTitle(pid)
 	if title == "" {
-		return nil, fmt.Errorf("未找到 PID=%d 的窗口", pid)
+		return nil, auto.Errorf(auto.ErrNotFound, "未找到 PID=%d 的窗口", pid)
 	}
 
 	x, y, w, h := robotgo.GetBounds(pid)
@@ -140,6 +141,15 @@ func MaximizeWindow(pid int) {
 	robotgo.MaxWindow(pid)
 }
 
+// SetWindowBounds 移动窗口并调整大小：w 为 GetWindows 返回的窗口，bounds 为目标位置和尺寸（与 WindowInfo.Bounds 相同的坐标系）
+// 窗口管理器可能限制最终的位置和尺寸，调用方应重新获取窗口边界
+func SetWindowBounds(w WindowInfo, bounds auto.Region) error {
+	if bounds.Width <= 0 || bounds.Height <= 0 {
+		return auto.Errorf(auto.ErrParam, "窗口尺寸必须是正数: %dx%d", bounds.Width, bounds.Height)
+	}
+	return setWindowBoundsPlatform(w, bounds)
+}
+
 // CloseWindowByPID 关闭窗口
 func CloseWindowByPID(pid int) {
 	robotgo.CloseWindow(pid)
@@ -205,10 +215,12 @@ func WaitForWindow(title string, opts ...auto.Option) (*WindowInfo, error) {
 		}
 
 		if o.Timeout == 0 || time.Since(startTime) > o.Timeout {
-			return nil, fmt.Errorf("等待窗口超时: %s", title)
+			return nil, auto.Errorf(auto.ErrTimeout, "等待窗口超时: %s", title)
 		}
 
-		auto.Sleep(auto.DefaultPollInterval)
+		if err := o.WaitPoll(); err != nil {
+			return nil, err
+		}
 	}
 }
 
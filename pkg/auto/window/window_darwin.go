@@ -200,6 +200,7 @@ func getWindowsDarwin(filter ...string) ([]WindowInfo, error) {
 
 		result = append(result, WindowInfo{
 			PID:       int(w.pid),
+			Handle:    int64(w.windowId),
 			Title:     title,
 			OwnerName: ownerName,
 			Bounds: auto.Region{
@@ -241,7 +242,7 @@ func activateWindowPlatform(name string) error {
 		return nil
 	}
 
-	return fmt.Errorf("无法激活窗口 %s: 未找到匹配的应用或窗口", name)
+	return auto.Errorf(auto.ErrNotFound, "无法激活窗口 %s: 未找到匹配的应用或窗口", name)
 }
 
 func activateWindowByPIDPlatform(pid int) error {
@@ -293,7 +294,7 @@ func activateWindowByTitlePlatform(appName, windowTitle string) error {
 	}
 
 	if targetWindow == nil {
-		return fmt.Errorf("未找到匹配的窗口: appName=%s, windowTitle=%s", appName, windowTitle)
+		return auto.Errorf(auto.ErrNotFound, "未找到匹配的窗口: appName=%s, windowTitle=%s", appName, windowTitle)
 	}
 
 	bundleID := C.GoString(C.getBundleIDByPID(C.int(targetWindow.PID)))
@@ -328,3 +329,32 @@ func activateWindowByTitlePlatform(appName, windowTitle string) error {
 
 	return nil
 }
+
+// setWindowBoundsPlatform macOS：通过 System Events 设置窗口的位置和尺寸（需要辅助功能权限）
+// 优先选择标题相同的窗口，找不到时使用应用的第一个窗口
+func setWindowBoundsPlatform(w WindowInfo, bounds auto.Region) error {
+	script := fmt.Sprintf(`
+		tell application "System Events"
+			set targetProcess to first process whose unix id is %d
+			set targetWindow to first window of targetProcess
+			repeat with candidate in windows of targetProcess
+				if name of candidate is "%s" then
+					set targetWindow to candidate
+					exit repeat
+				end if
+			end repeat
+			set position of targetWindow to {%d, %d}
+			set size of targetWindow to {%d, %d}
+		end tell
+	`, w.PID, escapeAppleScript(w.Title), bounds.X, bounds.Y, bounds.Width, bounds.Height)
+
+	if out, err := exec.Command("osascript", "-e", script).CombinedOutput(); err != nil {
+		return fmt.Errorf("设置窗口位置失败: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// escapeAppleScript 转义 AppleScript 字符串字面量中的反斜杠和引号
+func escapeAppleScript(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
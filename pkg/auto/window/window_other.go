@@ -4,10 +4,14 @@ package window
 
 import (
 	"fmt"
+	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-vgo/robotgo"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
 )
 
 // getWindowsPlatform Linux 等其他平台实现
@@ -69,7 +73,7 @@ func activateWindowByTitlePlatform(appName, windowTitle string) error {
 	}
 
 	if targetWindow == nil {
-		return fmt.Errorf("未找到匹配的窗口: appName=%s, windowTitle=%s", appName, windowTitle)
+		return auto.Errorf(auto.ErrNotFound, "未找到匹配的窗口: appName=%s, windowTitle=%s", appName, windowTitle)
 	}
 
 	robotgo.MaxWindow(targetWindow.PID)
@@ -88,3 +92,33 @@ func activateWindowByTitlePlatform(appName, windowTitle string) error {
 
 	return nil
 }
+
+// setWindowBoundsPlatform Linux：通过 xdotool 移动窗口并调整大小（仅支持 X11）
+// 优先选择标题相同的窗口，找不到时使用该进程的第一个可见窗口
+func setWindowBoundsPlatform(w WindowInfo, bounds auto.Region) error {
+	if _, err := exec.LookPath("xdotool"); err != nil {
+		return auto.Errorf(auto.ErrSystem, "未找到 xdotool，请先安装: %w", err)
+	}
+	out, err := exec.Command("xdotool", "search", "--onlyvisible", "--pid", strconv.Itoa(w.PID)).Output()
+	ids := strings.Fields(string(out))
+	if err != nil || len(ids) == 0 {
+		return auto.Errorf(auto.ErrNotFound, "未找到 PID=%d 的窗口", w.PID)
+	}
+	id := ids[0]
+	for _, candidate := range ids {
+		name, _ := exec.Command("xdotool", "getwindowname", candidate).Output()
+		if strings.TrimSpace(string(name)) == w.Title {
+			id = candidate
+			break
+		}
+	}
+
+	x, y, width, height := auto.NormalizeRegionForInput(bounds.X, bounds.Y, bounds.Width, bounds.Height)
+	if out, err := exec.Command("xdotool",
+		"windowsize", id, strconv.Itoa(width), strconv.Itoa(height),
+		"windowmove", id, strconv.Itoa(x), strconv.Itoa(y),
+	).CombinedOutput(); err != nil {
+		return fmt.Errorf("设置窗口位置失败: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
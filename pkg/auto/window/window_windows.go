@@ -31,6 +31,9 @@ var (
 	procGetForegroundWindow      = user32.NewProc("GetForegroundWindow")
 	procAttachThreadInput        = user32.NewProc("AttachThreadInput")
 	procGetCurrentThreadId       = kernel32.NewProc("GetCurrentThreadId")
+	procSetWindowPos             = user32.NewProc("SetWindowPos")
+	procIsZoomed                 = user32.NewProc("IsZoomed")
+	procIsIconic                 = user32.NewProc("IsIconic")
 )
 
 const (
@@ -45,6 +48,9 @@ const (
 	processVMRead           = 0x0010
 	swRestore               = 9
 	swShow                  = 5
+
+	swpNoZOrder   = 0x0004
+	swpNoActivate = 0x0010
 )
 
 // RECT Windows 矩形结构
@@ -130,6 +136,7 @@ func getWindowsWindows(filter ...string) ([]WindowInfo, error) {
 
 		data.windows = append(data.windows, WindowInfo{
 			PID:       int(pid),
+			Handle:    int64(hwnd),
 			Title:     title,
 			OwnerName: ownerName,
 			Bounds: auto.Region{
@@ -185,7 +192,7 @@ func activateWindowPlatform(name string) error {
 		return err
 	}
 	if len(windows) == 0 {
-		return fmt.Errorf("未找到窗口: %s", name)
+		return auto.Errorf(auto.ErrNotFound, "未找到窗口: %s", name)
 	}
 
 	return activateWindowByTitleInternal(windows[0].Title)
@@ -211,7 +218,7 @@ func activateWindowByPIDPlatform(pid int) error {
 	procEnumWindows.Call(callback, 0)
 
 	if targetHwnd == 0 {
-		return fmt.Errorf("未找到 PID %d 的窗口", pid)
+		return auto.Errorf(auto.ErrNotFound, "未找到 PID %d 的窗口", pid)
 	}
 
 	return activateWindowByHandle(targetHwnd)
@@ -247,7 +254,7 @@ func activateWindowByTitlePlatform(appName, windowTitle string) error {
 		}
 	}
 
-	return fmt.Errorf("未找到匹配的窗口: appName=%s, windowTitle=%s", appName, windowTitle)
+	return auto.Errorf(auto.ErrNotFound, "未找到匹配的窗口: appName=%s, windowTitle=%s", appName, windowTitle)
 }
 
 // activateWindowByTitleInternal 通过窗口标题激活窗口
@@ -275,7 +282,7 @@ func activateWindowByTitleInternal(title string) error {
 	procEnumWindows.Call(callback, 0)
 
 	if targetHwnd == 0 {
-		return fmt.Errorf("未找到窗口: %s", title)
+		return auto.Errorf(auto.ErrNotFound, "未找到窗口: %s", title)
 	}
 
 	return activateWindowByHandle(targetHwnd)
@@ -312,3 +319,31 @@ func activateWindowByHandle(hwnd syscall.Handle) error {
 
 	return nil
 }
+
+// setWindowBoundsPlatform 通过 SetWindowPos 移动窗口并调整大小，最大化或最小化的窗口先还原
+func setWindowBoundsPlatform(w WindowInfo, bounds auto.Region) error {
+	hwnd := uintptr(w.Handle)
+	if hwnd == 0 {
+		return auto.Errorf(auto.ErrNotFound, "窗口句柄无效: %s", w.Title)
+	}
+
+	zoomed, _, _ := procIsZoomed.Call(hwnd)
+	iconic, _, _ := procIsIconic.Call(hwnd)
+	if zoomed != 0 || iconic != 0 {
+		procShowWindow.Call(hwnd, swRestore)
+	}
+
+	ret, _, err := procSetWindowPos.Call(
+		hwnd,
+		0,
+		uintptr(int32(bounds.X)),
+		uintptr(int32(bounds.Y)),
+		uintptr(int32(bounds.Width)),
+		uintptr(int32(bounds.Height)),
+		swpNoZOrder|swpNoActivate,
+	)
+	if ret == 0 {
+		return fmt.Errorf("SetWindowPos 失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Package autostart 用户登录后自动启动 GUI：macOS LaunchAgent（~/Library/LaunchAgents）、
+// Windows 当前用户的 Run 注册表项、Linux XDG autostart（~/.config/autostart）
+//
+// 与 service 包不同，自启动项随用户登录在图形会话中启动一次，不会在退出后重启
+package autostart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultName 默认自启动项名称
+const DefaultName = "zoeyworker-gui"
+
+// Entry 自启动项
+type Entry struct {
+	Name        string   // 标识（launchd Label 后缀、注册表值名、.desktop 文件名）
+	DisplayName string   // 显示名称
+	Executable  string   // 可执行文件的绝对路径
+	Args        []string // 启动参数
+}
+
+// NewEntry 以当前可执行文件创建自启动项
+func NewEntry(args ...string) (*Entry, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("获取可执行文件路径失败: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return &Entry{
+		Name:        DefaultName,
+		DisplayName: "Zoey Worker",
+		Executable:  exe,
+		Args:        args,
+	}, nil
+}
+
+// Label launchd 标识
+func (e *Entry) Label() string {
+	return "com.zoeyai." + e.Name
+}
+
+// LaunchAgentPlist 生成 LaunchAgent 配置：登录时在图形会话（Aqua）中启动一次
+func LaunchAgentPlist(e *Entry) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	fmt.Fprintf(&b, "\t<key>Label</key>\n\t<string>%s</string>\n", xmlEscape(e.Label()))
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range append([]string{e.Executable}, e.Args...) {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", xmlEscape(arg))
+	}
+	b.WriteString("\t</array>\n")
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>LimitLoadToSessionType</key>\n\t<string>Aqua</string>\n")
+	b.WriteString("\t<key>ProcessType</key>\n\t<string>Interactive</string>\n")
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+// xmlEscape 转义 XML 特殊字符
+func xmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;").Replace(s)
+}
+
+// DesktopEntry 生成 XDG autostart 的 .desktop 文件
+func DesktopEntry(e *Entry) string {
+	args := make([]string, 0, len(e.Args)+1)
+	for _, arg := range append([]string{e.Executable}, e.Args...) {
+		args = append(args, desktopQuote(arg))
+	}
+
+	var b strings.Builder
+	b.WriteString("[Desktop Entry]\n")
+	b.WriteString("Type=Application\n")
+	fmt.Fprintf(&b, "Name=%s\n", e.DisplayName)
+	fmt.Fprintf(&b, "Exec=%s\n", strings.Join(args, " "))
+	b.WriteString("Terminal=false\n")
+	b.WriteString("X-GNOME-Autostart-enabled=true\n")
+	return b.String()
+}
+
+// desktopHidden .desktop 文件是否被桌面环境关闭（Hidden=true 或 X-GNOME-Autostart-enabled=false）
+func desktopHidden(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if key == "Hidden" && value == "true" || key == "X-GNOME-Autostart-enabled" && value == "false" {
+			return true
+		}
+	}
+	return false
+}
+
+// desktopQuote 按 Desktop Entry 规范为 Exec 参数加引号（包含空白或保留字符时），% 转义为 %%
+func desktopQuote(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	if s != "" && !strings.ContainsAny(s, " \t\n\"'\\><~|&;$*?#()`") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", "$", `\$`).Replace(s) + `"`
+}
+
+// RunCommand 生成 Windows Run 注册表项的命令行（可执行文件路径始终加引号）
+func RunCommand(e *Entry) string {
+	args := []string{`"` + e.Executable + `"`}
+	for _, arg := range e.Args {
+		args = append(args, windowsQuote(arg))
+	}
+	return strings.Join(args, " ")
+}
+
+// windowsQuote 按 CommandLineToArgvW 规则为参数加引号（包含空白或引号时）
+func windowsQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"") {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	slashes := 0
+	for _, r := range s {
+		switch r {
+		case '\\':
+			slashes++
+			continue
+		case '"':
+			b.WriteString(strings.Repeat(`\`, 2*slashes+1))
+		default:
+			b.WriteString(strings.Repeat(`\`, slashes))
+		}
+		slashes = 0
+		b.WriteRune(r)
+	}
+	b.WriteString(strings.Repeat(`\`, 2*slashes))
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,61 @@
+//go:build darwin
+
+package autostart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// plistPath LaunchAgent 配置文件路径
+func plistPath(e *Entry) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户目录失败: %w", err)
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", e.Label()+".plist"), nil
+}
+
+// Enable 写入 LaunchAgent 配置（下次登录时启动，不立即启动）
+func Enable(e *Entry) error {
+	path, err := plistPath(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(LaunchAgentPlist(e)), 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+// Disable 删除 LaunchAgent 配置（不影响正在运行的进程），未启用时不报错
+func Disable(e *Entry) error {
+	path, err := plistPath(e)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+// IsEnabled LaunchAgent 配置是否存在
+func IsEnabled(e *Entry) (bool, error) {
+	path, err := plistPath(e)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("检查 %s 失败: %w", path, err)
+	}
+	return true, nil
+}
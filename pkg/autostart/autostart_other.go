@@ -0,0 +1,61 @@
+//go:build !windows && !darwin
+
+package autostart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// desktopPath XDG autostart 文件路径（$XDG_CONFIG_HOME/autostart，默认 ~/.config/autostart）
+func desktopPath(e *Entry) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("获取配置目录失败: %w", err)
+	}
+	return filepath.Join(dir, "autostart", e.Name+".desktop"), nil
+}
+
+// Enable 写入 XDG autostart 文件（下次登录桌面时启动，不立即启动）
+func Enable(e *Entry) error {
+	path, err := desktopPath(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(DesktopEntry(e)), 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+// Disable 删除 XDG autostart 文件，未启用时不报错
+func Disable(e *Entry) error {
+	path, err := desktopPath(e)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+// IsEnabled XDG autostart 文件是否存在（桌面环境的启动项设置中关闭时会写入 Hidden=true，视为未启用）
+func IsEnabled(e *Entry) (bool, error) {
+	path, err := desktopPath(e)
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("读取 %s 失败: %w", path, err)
+	}
+	return !desktopHidden(string(data)), nil
+}
@@ -0,0 +1,101 @@
+package autostart
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func testEntry() *Entry {
+	return &Entry{
+		Name:        DefaultName,
+		DisplayName: "Zoey Worker",
+		Executable:  "/opt/Zoey Worker/zoeyworker-gui",
+		Args:        []string{"-profile", "R&D 100%"},
+	}
+}
+
+func TestLaunchAgentPlist(t *testing.T) {
+	plist := LaunchAgentPlist(testEntry())
+	for _, want := range []string{
+		"<string>com.zoeyai.zoeyworker-gui</string>",
+		"<string>/opt/Zoey Worker/zoeyworker-gui</string>",
+		"<string>R&amp;D 100%</string>",
+		"<key>RunAtLoad</key>\n\t<true/>",
+		"<string>Aqua</string>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist 应包含 %q:\n%s", want, plist)
+		}
+	}
+	if strings.Contains(plist, "KeepAlive") {
+		t.Error("自启动项不应在退出后重启")
+	}
+}
+
+func TestDesktopEntry(t *testing.T) {
+	entry := DesktopEntry(testEntry())
+	if want := `Exec="/opt/Zoey Worker/zoeyworker-gui" -profile "R&D 100%%"`; !strings.Contains(entry, want) {
+		t.Errorf("应包含 %q:\n%s", want, entry)
+	}
+	if desktopHidden(entry) {
+		t.Error("生成的文件不应被视为已关闭")
+	}
+	for _, content := range []string{entry + "Hidden=true\n", strings.Replace(entry, "enabled=true", "enabled=false", 1)} {
+		if !desktopHidden(content) {
+			t.Errorf("应视为已关闭:\n%s", content)
+		}
+	}
+	if got := desktopQuote(`a"$b`); got != `"a\"\$b"` {
+		t.Errorf("desktopQuote = %s", got)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	e := &Entry{Executable: `C:\Program Files\Zoey\zoeyworker-gui.exe`, Args: []string{"-profile", "a b", `x"y`, `c:\dir\`}}
+	want := `"C:\Program Files\Zoey\zoeyworker-gui.exe" -profile "a b" "x\"y" c:\dir\`
+	if got := RunCommand(e); got != want {
+		t.Errorf("RunCommand = %s, 期望 %s", got, want)
+	}
+	if got := windowsQuote(`c:\my dir\`); got != `"c:\my dir\\"` {
+		t.Errorf("结尾的反斜杠应加倍: %s", got)
+	}
+}
+
+func TestEnableDisable_XDG(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("仅 XDG autostart")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	e := testEntry()
+
+	if enabled, err := IsEnabled(e); err != nil || enabled {
+		t.Fatalf("初始应未启用: %v %v", enabled, err)
+	}
+	if err := Enable(e); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "autostart", DefaultName+".desktop")
+	if enabled, err := IsEnabled(e); err != nil || !enabled {
+		t.Fatalf("启用后应为已启用: %v %v", enabled, err)
+	}
+
+	// 桌面环境的启动项设置中关闭
+	os.WriteFile(path, []byte(DesktopEntry(e)+"Hidden=true\n"), 0644)
+	if enabled, _ := IsEnabled(e); enabled {
+		t.Error("Hidden=true 时应为未启用")
+	}
+
+	if err := Disable(e); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("禁用后应删除 .desktop 文件")
+	}
+	if err := Disable(e); err != nil {
+		t.Errorf("重复禁用不应报错: %v", err)
+	}
+}
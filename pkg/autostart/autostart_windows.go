@@ -0,0 +1,78 @@
+//go:build windows
+
+package autostart
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	// runKey 当前用户登录时启动的程序
+	runKey = `Software\Microsoft\Windows\CurrentVersion\Run`
+	// approvedKey 任务管理器"启动"页的启用状态（首字节为奇数表示已禁用）
+	approvedKey = `Software\Microsoft\Windows\CurrentVersion\Explorer\StartupApproved\Run`
+)
+
+// Enable 写入当前用户的 Run 注册表项（下次登录时启动，不立即启动），并清除任务管理器中的禁用状态
+func Enable(e *Entry) error {
+	k, _, err := registry.CreateKey(registry.CURRENT_USER, runKey, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("打开注册表项失败: %w", err)
+	}
+	defer k.Close()
+	if err := k.SetStringValue(e.Name, RunCommand(e)); err != nil {
+		return fmt.Errorf("写入注册表失败: %w", err)
+	}
+
+	if k, err := registry.OpenKey(registry.CURRENT_USER, approvedKey, registry.SET_VALUE); err == nil {
+		k.DeleteValue(e.Name) // 不存在时失败，忽略
+		k.Close()
+	}
+	return nil
+}
+
+// Disable 删除 Run 注册表项，未启用时不报错
+func Disable(e *Entry) error {
+	k, err := registry.OpenKey(registry.CURRENT_USER, runKey, registry.SET_VALUE)
+	if errors.Is(err, registry.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("打开注册表项失败: %w", err)
+	}
+	defer k.Close()
+	if err := k.DeleteValue(e.Name); err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return fmt.Errorf("删除注册表值失败: %w", err)
+	}
+	return nil
+}
+
+// IsEnabled Run 注册表项是否存在且未在任务管理器中禁用
+func IsEnabled(e *Entry) (bool, error) {
+	k, err := registry.OpenKey(registry.CURRENT_USER, runKey, registry.QUERY_VALUE)
+	if errors.Is(err, registry.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("打开注册表项失败: %w", err)
+	}
+	defer k.Close()
+	if _, _, err := k.GetStringValue(e.Name); errors.Is(err, registry.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("读取注册表失败: %w", err)
+	}
+
+	approved, err := registry.OpenKey(registry.CURRENT_USER, approvedKey, registry.QUERY_VALUE)
+	if err != nil {
+		return true, nil
+	}
+	defer approved.Close()
+	if data, _, err := approved.GetBinaryValue(e.Name); err == nil && len(data) > 0 && data[0]&1 == 1 {
+		return false, nil
+	}
+	return true, nil
+}
@@ -1,8 +1,6 @@
 package config
 
 import (
-	"encoding/json"
-	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
@@ -16,30 +14,67 @@ type ConnectionConfig struct {
 	SecretKey   string `json:"secret_key"`
 	AutoConnect bool   `json:"auto_connect"` // 启动时自动连接
 
+	// TLS 设置（wss:// 地址）
+	CACertFile         string `json:"ca_cert_file"`         // 额外信任的 CA 证书（PEM），用于内部 CA 签发的服务端证书
+	ClientCertFile     string `json:"client_cert_file"`     // 客户端证书（PEM），服务端要求双向 TLS 时与 client_key_file 一起设置
+	ClientKeyFile      string `json:"client_key_file"`      // 客户端私钥（PEM）
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"` // 跳过服务端证书校验（仅用于测试环境）
+
 	// 重连设置
 	AutoReconnect     bool `json:"auto_reconnect"`     // 断开后自动重连
 	ReconnectInterval int  `json:"reconnect_interval"` // 重连间隔(秒)
+	HeartbeatInterval int  `json:"heartbeat_interval"` // 心跳间隔(秒)
 
 	// 日志设置
 	LogLevel string `json:"log_level"` // 日志级别: DEBUG, INFO, WARN, ERROR
+	LogFile  string `json:"log_file"`  // 命令行模式的日志文件路径，为空时使用 ~/.zoey-worker/logs/worker.log，"off" 关闭
 
 	// GUI 设置
 	MinimizeToTray bool `json:"minimize_to_tray"` // 关闭时最小化到托盘
 	StartMinimized bool `json:"start_minimized"`  // 启动时最小化
+	AutoStart      bool `json:"auto_start"`       // 登录后自动启动（GUI 读取时与系统中的自启动项同步）
+
+	// 截图设置
+	ScreenshotMaxWidth int `json:"screenshot_max_width"` // 步骤截图最大宽度，超出时等比缩小（0 不缩放）
+	ScreenshotQuality  int `json:"screenshot_quality"`   // 步骤截图 JPEG 质量（1-100）
+
+	// OCR 设置
+	OCRWarmup   bool   `json:"ocr_warmup"`   // 连接后在后台预加载 OCR 模型，避免首个文字步骤超时
+	OCRProvider string `json:"ocr_provider"` // OCR 执行提供者: cpu（默认）, auto, cuda, coreml, directml
+
+	// 插件下载设置
+	PluginMirrors []string `json:"plugin_mirrors"` // 插件下载镜像地址（按顺序尝试，均失败后使用官方地址）
+	PluginProxy   string   `json:"plugin_proxy"`   // 插件下载代理，为空时使用 HTTP_PROXY / HTTPS_PROXY 环境变量
+
+	// 远程控制
+	RemoteControl bool `json:"remote_control"` // 允许服务端在屏幕流期间发送鼠标键盘事件
+
+	// 时钟偏差
+	CorrectClockSkew bool `json:"correct_clock_skew"` // 按服务端 Ping 估计的时钟偏差校正发送消息的时间戳
+
+	// 运行指标
+	MetricsAddr string `json:"metrics_addr"` // Prometheus 指标监听地址（如 :9100，只有端口时仅监听 127.0.0.1），为空时不启动
+
+	// 电源
+	KeepAwake bool `json:"keep_awake"` // 有任务运行期间阻止系统休眠和锁屏
 }
 
 // DefaultConnectionConfig 默认连接配置
 func DefaultConnectionConfig() *ConnectionConfig {
 	return &ConnectionConfig{
-		ServerURL:         "localhost:3001",
-		AccessKey:         "",
-		SecretKey:         "",
-		AutoConnect:       false,
-		AutoReconnect:     true,
-		ReconnectInterval: 5,
-		LogLevel:          "INFO",
-		MinimizeToTray:    true,
-		StartMinimized:    false,
+		ServerURL:          "localhost:3001",
+		AccessKey:          "",
+		SecretKey:          "",
+		AutoConnect:        false,
+		AutoReconnect:      true,
+		ReconnectInterval:  5,
+		HeartbeatInterval:  5,
+		LogLevel:           "INFO",
+		MinimizeToTray:     true,
+		StartMinimized:     false,
+		ScreenshotMaxWidth: 1280,
+		ScreenshotQuality:  60,
+		KeepAwake:          true,
 	}
 }
 
@@ -48,6 +83,13 @@ type Manager struct {
 	configDir  string
 	configFile string
 	mu         sync.RWMutex
+	// reported 已输出到日志的配置问题，避免每次加载重复输出
+	reported map[string]bool
+	// written 本进程最后一次写入的文件内容哈希，Watch 据此忽略自己的保存
+	written [32]byte
+
+	watchMu  sync.Mutex
+	onChange []func(*ConnectionConfig)
 }
 
 // NewManager 创建配置管理器
@@ -77,50 +119,18 @@ func (m *Manager) ensureDir() error {
 	return os.MkdirAll(m.configDir, 0755)
 }
 
-// Load 加载配置
+// Load 加载当前配置，返回解密后的 AccessKey / SecretKey
+// 旧版本的单一配置文件和明文密钥在首次加载时自动迁移
 func (m *Manager) Load() (*ConnectionConfig, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	if _, err := os.Stat(m.configFile); os.IsNotExist(err) {
-		return DefaultConnectionConfig(), nil
-	}
-
-	data, err := os.ReadFile(m.configFile)
-	if err != nil {
-		return DefaultConnectionConfig(), fmt.Errorf("读取配置文件失败: %w", err)
-	}
-
-	var config ConnectionConfig
-	if err := json.Unmarshal(data, &config); err != nil {
-		return DefaultConnectionConfig(), fmt.Errorf("解析配置文件失败: %w", err)
-	}
-
-	return &config, nil
+	return m.LoadProfile("")
 }
 
-// Save 保存配置
+// Save 保存到当前配置，AccessKey / SecretKey 加密存储（系统钥匙串或加密后写入配置文件）
 func (m *Manager) Save(config *ConnectionConfig) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if err := m.ensureDir(); err != nil {
-		return fmt.Errorf("创建配置目录失败: %w", err)
-	}
-
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return fmt.Errorf("序列化配置失败: %w", err)
-	}
-
-	if err := os.WriteFile(m.configFile, data, 0600); err != nil {
-		return fmt.Errorf("写入配置文件失败: %w", err)
-	}
-
-	return nil
+	return m.SaveProfile("", config)
 }
 
-// Clear 清除配置
+// Clear 清除所有配置（包括系统钥匙串中的密钥）
 func (m *Manager) Clear() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -129,6 +139,12 @@ func (m *Manager) Clear() error {
 		return nil
 	}
 
+	if set, err := m.read(); err == nil {
+		for name := range set.profiles {
+			m.removeSecrets(name)
+		}
+	}
+	m.removeSecrets("")
 	return os.Remove(m.configFile)
 }
 
@@ -169,6 +185,16 @@ func Save(config *ConnectionConfig) error {
 	return defaultManager.Save(config)
 }
 
+// LoadProfile 使用默认管理器加载指定配置（name 为空时为当前配置）
+func LoadProfile(name string) (*ConnectionConfig, error) {
+	return defaultManager.LoadProfile(name)
+}
+
+// SaveProfile 使用默认管理器保存指定配置（name 为空时为当前配置）
+func SaveProfile(name string, config *ConnectionConfig) error {
+	return defaultManager.SaveProfile(name, config)
+}
+
 // Clear 使用默认管理器清除配置
 func Clear() error {
 	return defaultManager.Clear()
@@ -228,6 +228,28 @@ func TestConfigFilePermissions(t *testing.T) {
 	t.Logf("配置文件权限: %o", perm)
 }
 
+func TestManagerLoadKeepsDefaultsForMissingFields(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := NewManagerWithDir(tempDir)
+
+	// 旧版本配置文件不含 screenshot_max_width
+	old := []byte(`{"server_url":"old.server:8080","access_key":"ak","secret_key":"sk"}`)
+	if err := os.WriteFile(filepath.Join(tempDir, "config.json"), old, 0600); err != nil {
+		t.Fatalf("写入配置失败: %v", err)
+	}
+
+	loaded, err := manager.Load()
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if loaded.ServerURL != "old.server:8080" {
+		t.Errorf("ServerURL 应为 old.server:8080, 实际为 %s", loaded.ServerURL)
+	}
+	if loaded.ScreenshotMaxWidth != DefaultConnectionConfig().ScreenshotMaxWidth {
+		t.Errorf("缺失的 ScreenshotMaxWidth 应使用默认值, 实际为 %d", loaded.ScreenshotMaxWidth)
+	}
+}
+
 // BenchmarkSaveLoad 基准测试
 func BenchmarkSaveLoad(b *testing.B) {
 	tempDir := b.TempDir()
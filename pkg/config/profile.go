@@ -0,0 +1,280 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+)
+
+// DefaultProfile 默认配置名，旧版本的单一配置迁移到该配置
+const DefaultProfile = "default"
+
+// ErrProfileNotFound 指定的配置不存在
+var ErrProfileNotFound = errors.New("配置不存在")
+
+// profileNamePattern 配置名：字母、数字（含中文）、下划线、点和短横线，最长 64 个字符
+var profileNamePattern = regexp.MustCompile(`^[\p{L}\p{N}_.-]{1,64}$`)
+
+// profilesFile 配置文件格式：多个命名配置及当前使用的配置
+type profilesFile struct {
+	Version  int                        `json:"version"`
+	Current  string                     `json:"current"`
+	Profiles map[string]json.RawMessage `json:"profiles"`
+}
+
+// profileSet 已读取的配置集合，每个配置的密钥仍为加密状态
+type profileSet struct {
+	current  string
+	profiles map[string]*fileConfig
+	// issues 校验发现的问题（未知字段、非法取值），非法取值已替换为默认值
+	issues []Issue
+	// dirty 读取时做了迁移（旧版本格式、明文密钥），需要写回文件
+	dirty bool
+	// legacy 旧版单一配置的密钥保存在系统钥匙串中，写回新格式后删除
+	legacy bool
+}
+
+// validateProfileName 检查配置名是否合法
+func validateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("无效的配置名 %q（只能包含字母、数字、下划线、点和短横线，最长 64 个字符）", name)
+	}
+	return nil
+}
+
+// decodeFileConfig 以默认配置为基础解析单个配置，旧配置文件中缺失的字段保留默认值
+func decodeFileConfig(data []byte) (*fileConfig, error) {
+	file := &fileConfig{ConnectionConfig: DefaultConnectionConfig()}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// read 读取配置文件（调用方持有写锁）
+// 旧版本格式按升级链迁移到当前版本（迁移前备份原文件），明文密钥迁移为加密存储，迁移后 dirty 为 true
+func (m *Manager) read() (*profileSet, error) {
+	set := &profileSet{current: DefaultProfile, profiles: make(map[string]*fileConfig)}
+	data, err := os.ReadFile(m.configFile)
+	if os.IsNotExist(err) {
+		return set, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	mg := &migrator{m: m}
+	if err := json.Unmarshal(data, &mg.doc); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	version, err := mg.migrate(data)
+	if err != nil {
+		return nil, err
+	}
+	set.legacy = mg.legacyKeyring
+	set.issues = unknownFields(mg.doc, topLevelFields, "")
+
+	var file profilesFile
+	if err := json.Unmarshal(mustMarshal(mg.doc), &file); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	if file.Current != "" {
+		set.current = file.Current
+	}
+	for name, raw := range file.Profiles {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, fmt.Errorf("解析配置 %s 失败: %w", name, err)
+		}
+		profile, err := decodeFileConfig(raw)
+		if err != nil {
+			return nil, fmt.Errorf("解析配置 %s 失败: %w", name, err)
+		}
+		set.issues = append(set.issues, unknownFields(fields, profileFields(), name)...)
+		set.issues = append(set.issues, validateProfile(name, profile.ConnectionConfig)...)
+		set.profiles[name] = profile
+	}
+	if _, ok := set.profiles[set.current]; !ok && len(set.profiles) > 0 {
+		set.issues = append(set.issues, Issue{Field: "current", Message: fmt.Sprintf("当前配置 %s 不存在，将使用默认配置", set.current)})
+	}
+	sortIssues(set.issues)
+
+	// 明文密钥（旧版本或手工编辑）：加密后写回
+	for name, profile := range set.profiles {
+		if profile.SecretStore == "" && (profile.AccessKey != "" || profile.SecretKey != "") {
+			sealed, err := m.sealSecrets(name, profile.ConnectionConfig)
+			if err != nil {
+				return nil, err
+			}
+			set.profiles[name] = sealed
+			set.dirty = true
+		}
+	}
+	if version < CurrentVersion {
+		set.dirty = true
+	}
+	return set, nil
+}
+
+// write 写入配置文件（调用方持有写锁）
+func (m *Manager) write(set *profileSet) error {
+	if err := m.ensureDir(); err != nil {
+		return fmt.Errorf("创建配置目录失败: %w", err)
+	}
+
+	file := profilesFile{Version: CurrentVersion, Current: set.current, Profiles: make(map[string]json.RawMessage, len(set.profiles))}
+	for name, profile := range set.profiles {
+		data, err := json.Marshal(profile)
+		if err != nil {
+			return fmt.Errorf("序列化配置失败: %w", err)
+		}
+		file.Profiles[name] = data
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化配置失败: %w", err)
+	}
+
+	if err := os.WriteFile(m.configFile, data, 0600); err != nil {
+		return fmt.Errorf("写入配置文件失败: %w", err)
+	}
+	m.written = sha256.Sum256(data)
+	if set.legacy {
+		m.removeSecrets("")
+		set.legacy = false
+	}
+	return nil
+}
+
+// readForUpdate 读取配置并写回读取时的迁移结果（调用方持有写锁），迁移写回失败时下次读取重试
+func (m *Manager) readForUpdate() (*profileSet, error) {
+	set, err := m.read()
+	if err != nil {
+		return nil, err
+	}
+	if set.dirty {
+		m.write(set)
+		set.dirty = false
+	}
+	return set, nil
+}
+
+// LoadProfile 加载指定配置（name 为空时加载当前配置），返回解密后的 AccessKey / SecretKey
+// 当前配置尚未保存时返回默认配置；指定的配置不存在时返回默认配置和 ErrProfileNotFound
+func (m *Manager) LoadProfile(name string) (*ConnectionConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set, err := m.readForUpdate()
+	if err != nil {
+		return DefaultConnectionConfig(), err
+	}
+	current := name == "" || name == set.current
+	if name == "" {
+		name = set.current
+	}
+	var issues []Issue
+	for _, issue := range set.issues {
+		if issue.Profile == "" || issue.Profile == name {
+			issues = append(issues, issue)
+		}
+	}
+	m.reportIssues(issues)
+	profile, ok := set.profiles[name]
+	if !ok {
+		if current {
+			return DefaultConnectionConfig(), nil
+		}
+		return DefaultConnectionConfig(), fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	if err := m.openSecrets(name, profile); err != nil {
+		return profile.ConnectionConfig, err
+	}
+	return profile.ConnectionConfig, nil
+}
+
+// SaveProfile 保存指定配置（name 为空时保存到当前配置），不存在时创建，不改变当前配置
+func (m *Manager) SaveProfile(name string, config *ConnectionConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set, err := m.read()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = set.current
+	}
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+	// 非法取值（未填写的字段等）以默认值保存，避免每次加载时报告
+	normalized := *config
+	validateProfile(name, &normalized)
+	sealed, err := m.sealSecrets(name, &normalized)
+	if err != nil {
+		return err
+	}
+	set.profiles[name] = sealed
+	return m.write(set)
+}
+
+// UseProfile 切换当前配置，之后 Load / Save 使用该配置
+func (m *Manager) UseProfile(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set, err := m.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := set.profiles[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	set.current = name
+	return m.write(set)
+}
+
+// DeleteProfile 删除配置及其保存在系统钥匙串中的密钥，不能删除当前配置
+func (m *Manager) DeleteProfile(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set, err := m.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := set.profiles[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	if name == set.current {
+		return fmt.Errorf("不能删除当前使用的配置 %s，请先切换到其他配置", name)
+	}
+	delete(set.profiles, name)
+	if err := m.write(set); err != nil {
+		return err
+	}
+	m.removeSecrets(name)
+	return nil
+}
+
+// ListProfiles 返回所有配置名（已排序）和当前配置名
+func (m *Manager) ListProfiles() ([]string, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set, err := m.readForUpdate()
+	if err != nil {
+		return nil, "", err
+	}
+	names := make([]string, 0, len(set.profiles))
+	for name := range set.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, set.current, nil
+}
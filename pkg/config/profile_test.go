@@ -0,0 +1,120 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	manager := NewManagerWithDir(t.TempDir())
+	if err := manager.SaveProfile("staging", &ConnectionConfig{ServerURL: "staging:3001", AccessKey: "ak-s", SecretKey: "sk-s"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.SaveProfile("prod", &ConnectionConfig{ServerURL: "prod:3001", AccessKey: "ak-p", SecretKey: "sk-p"}); err != nil {
+		t.Fatal(err)
+	}
+
+	names, current, err := manager.ListProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "prod,staging" || current != DefaultProfile {
+		t.Errorf("ListProfiles = %v, 当前 %q", names, current)
+	}
+
+	if err := manager.UseProfile("prod"); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := manager.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.ServerURL != "prod:3001" || loaded.SecretKey != "sk-p" {
+		t.Errorf("切换后 Load 应返回 prod 配置, 实际 %+v", loaded)
+	}
+
+	// Save 写入当前配置，不影响其他配置
+	loaded.ServerURL = "prod2:3001"
+	if err := manager.Save(loaded); err != nil {
+		t.Fatal(err)
+	}
+	staging, err := manager.LoadProfile("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if staging.ServerURL != "staging:3001" || staging.SecretKey != "sk-s" {
+		t.Errorf("staging 配置不应被修改, 实际 %+v", staging)
+	}
+
+	if err := manager.DeleteProfile("prod"); err == nil {
+		t.Error("不能删除当前配置")
+	}
+	if err := manager.DeleteProfile("staging"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.LoadProfile("staging"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("删除后加载应返回 ErrProfileNotFound, 实际 %v", err)
+	}
+	if err := manager.UseProfile("missing"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("切换到不存在的配置应返回 ErrProfileNotFound, 实际 %v", err)
+	}
+	if err := manager.SaveProfile("../evil", &ConnectionConfig{}); err == nil {
+		t.Error("非法配置名应返回错误")
+	}
+}
+
+func TestLoadMigratesLegacyConfigToDefaultProfile(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewManagerWithDir(dir)
+	legacy := []byte(`{"server_url":"old:1","access_key":"ak","secret_key":"sk","auto_connect":true}`)
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), legacy, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := manager.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.ServerURL != "old:1" || !loaded.AutoConnect || loaded.SecretKey != "sk" {
+		t.Errorf("迁移后的配置 = %+v", loaded)
+	}
+	names, current, _ := manager.ListProfiles()
+	if len(names) != 1 || names[0] != DefaultProfile || current != DefaultProfile {
+		t.Errorf("旧配置应迁移为 default, 实际 %v（当前 %q）", names, current)
+	}
+
+	var file profilesFile
+	raw, _ := os.ReadFile(manager.GetConfigFile())
+	if err := json.Unmarshal(raw, &file); err != nil || file.Profiles[DefaultProfile] == nil {
+		t.Errorf("配置文件应改为多配置格式:\n%s", raw)
+	}
+}
+
+func TestLoadMigratesLegacyEncryptedConfig(t *testing.T) {
+	// 单一配置格式、密钥已加密的配置文件
+	manager := NewManagerWithDir(t.TempDir())
+	sealed, err := manager.sealSecrets("", &ConnectionConfig{ServerURL: "old:1", AccessKey: "ak", SecretKey: "sk"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(sealed)
+	if err := os.WriteFile(manager.GetConfigFile(), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := manager.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.AccessKey != "ak" || loaded.SecretKey != "sk" {
+		t.Errorf("迁移后应能解密原有密钥, 实际 %q / %q", loaded.AccessKey, loaded.SecretKey)
+	}
+	// 迁移后按 default 配置重新加密，再次加载仍可解密
+	if again, err := manager.Load(); err != nil || again.SecretKey != "sk" {
+		t.Errorf("再次加载 = %+v, %v", again, err)
+	}
+}
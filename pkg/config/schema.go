@@ -0,0 +1,278 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/zoeyai/zoeyworker/internal/logger"
+	"github.com/zoeyai/zoeyworker/pkg/metrics"
+)
+
+// CurrentVersion 配置文件格式的当前版本
+//
+//	1: 单一配置（没有 version 字段）
+//	2: 多个命名配置 profiles（没有 version 字段）
+//	3: 增加 version 字段；非正数的 reconnect_interval、空 log_level 改用默认值
+const CurrentVersion = 3
+
+// migrator 配置文件升级过程的状态，doc 为配置文件的顶层 JSON 对象
+type migrator struct {
+	m   *Manager
+	doc map[string]json.RawMessage
+	// legacyKeyring 旧版单一配置的密钥在系统钥匙串中，写回新格式后删除
+	legacyKeyring bool
+}
+
+// migrations 升级链：migrations[v] 将版本 v 升级到 v+1
+var migrations = map[int]func(*migrator) error{
+	1: (*migrator).singleToProfiles,
+	2: (*migrator).addVersion,
+}
+
+// detectVersion 配置文件版本：没有 version 字段时按结构判断
+func detectVersion(doc map[string]json.RawMessage) (int, error) {
+	if raw, ok := doc["version"]; ok {
+		var version int
+		if err := json.Unmarshal(raw, &version); err != nil || version < 1 {
+			return 0, fmt.Errorf("配置文件的 version 字段无效: %s", raw)
+		}
+		return version, nil
+	}
+	if _, ok := doc["profiles"]; ok {
+		return 2, nil
+	}
+	return 1, nil
+}
+
+// migrate 将配置文件升级到当前版本，升级前将原文件备份为 config.json.v<版本>.bak
+func (mg *migrator) migrate(data []byte) (int, error) {
+	version, err := detectVersion(mg.doc)
+	if err != nil {
+		return 0, err
+	}
+	if version > CurrentVersion {
+		return 0, fmt.Errorf("配置文件版本 %d 高于当前程序支持的版本 %d，请升级 Zoey Worker 或删除 %s", version, CurrentVersion, mg.m.configFile)
+	}
+	if version == CurrentVersion {
+		return version, nil
+	}
+
+	backup := fmt.Sprintf("%s.v%d.bak", mg.m.configFile, version)
+	if _, err := os.Stat(backup); os.IsNotExist(err) {
+		if err := os.WriteFile(backup, data, 0600); err != nil {
+			return 0, fmt.Errorf("备份配置文件失败，未执行升级: %w", err)
+		}
+	}
+	for v := version; v < CurrentVersion; v++ {
+		if err := migrations[v](mg); err != nil {
+			return 0, fmt.Errorf("配置文件从版本 %d 升级失败: %w", v, err)
+		}
+	}
+	return version, nil
+}
+
+// singleToProfiles 版本 1 → 2：单一配置移入 default 配置，已加密的密钥解密后由 read 按新账户名重新加密
+func (mg *migrator) singleToProfiles() error {
+	profile := mg.doc
+	if raw, ok := profile["secret_store"]; ok && string(raw) != `""` {
+		legacy, err := decodeFileConfig(mustMarshal(profile))
+		if err != nil {
+			return err
+		}
+		if err := mg.m.openSecrets("", legacy); err != nil {
+			return err
+		}
+		profile["access_key"] = mustMarshal(legacy.AccessKey)
+		profile["secret_key"] = mustMarshal(legacy.SecretKey)
+		mg.legacyKeyring = true
+	}
+	delete(profile, "secret_store")
+	delete(profile, "access_key_enc")
+	delete(profile, "secret_key_enc")
+
+	mg.doc = map[string]json.RawMessage{
+		"current":  mustMarshal(DefaultProfile),
+		"profiles": mustMarshal(map[string]json.RawMessage{DefaultProfile: mustMarshal(profile)}),
+	}
+	return nil
+}
+
+// addVersion 版本 2 → 3：旧版本写入的非正数重连间隔和空日志级别改用默认值
+func (mg *migrator) addVersion() error {
+	var profiles map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(mg.doc["profiles"], &profiles); err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		var interval int
+		if raw, ok := profile["reconnect_interval"]; ok && json.Unmarshal(raw, &interval) == nil && interval <= 0 {
+			delete(profile, "reconnect_interval")
+		}
+		if raw, ok := profile["log_level"]; ok && string(raw) == `""` {
+			delete(profile, "log_level")
+		}
+	}
+	mg.doc["profiles"] = mustMarshal(profiles)
+	mg.doc["version"] = mustMarshal(3)
+	return nil
+}
+
+// mustMarshal 序列化已知可序列化的值
+func mustMarshal(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// Issue 配置校验发现的问题
+type Issue struct {
+	Profile string `json:"profile,omitempty"` // 所属配置，为空时是文件级问题
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Profile == "" {
+		return fmt.Sprintf("%s: %s", i.Field, i.Message)
+	}
+	return fmt.Sprintf("配置 %s 的 %s: %s", i.Profile, i.Field, i.Message)
+}
+
+// 配置文件中允许的字段
+var (
+	topLevelFields = map[string]bool{"version": true, "current": true, "profiles": true}
+	profileFields  = sync.OnceValue(func() map[string]bool {
+		fields := jsonFieldNames(reflect.TypeOf(ConnectionConfig{}))
+		for name := range jsonFieldNames(reflect.TypeOf(fileConfig{})) {
+			fields[name] = true
+		}
+		return fields
+	})
+)
+
+// jsonFieldNames 结构体（含嵌入字段）的 JSON 字段名
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			continue
+		}
+		if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// unknownFields 报告不认识的字段（拼写错误或来自更新版本的字段），这些字段会被忽略
+func unknownFields(fields map[string]json.RawMessage, known map[string]bool, profile string) []Issue {
+	var issues []Issue
+	for name := range fields {
+		if !known[name] {
+			issues = append(issues, Issue{Profile: profile, Field: name, Message: "未知字段，已忽略（检查是否拼写错误）"})
+		}
+	}
+	return issues
+}
+
+// validLogLevels 支持的日志级别
+var validLogLevels = map[string]bool{"DEBUG": true, "INFO": true, "WARN": true, "ERROR": true}
+
+// validOCRProviders 支持的 OCR 执行提供者（与 ocr.ParseExecutionProvider 一致）
+var validOCRProviders = map[string]bool{"": true, "auto": true, "cpu": true, "cuda": true, "coreml": true, "directml": true}
+
+// validateProfile 校验配置的取值，非法值替换为默认值并返回问题列表
+func validateProfile(profile string, c *ConnectionConfig) []Issue {
+	defaults := DefaultConnectionConfig()
+	var issues []Issue
+	report := func(field, format string, args ...any) {
+		issues = append(issues, Issue{Profile: profile, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if level := strings.ToUpper(c.LogLevel); !validLogLevels[level] {
+		report("log_level", "无效的日志级别 %q，应为 DEBUG、INFO、WARN 或 ERROR，已使用 %s", c.LogLevel, defaults.LogLevel)
+		c.LogLevel = defaults.LogLevel
+	} else {
+		c.LogLevel = level
+	}
+	if c.ReconnectInterval <= 0 {
+		report("reconnect_interval", "重连间隔必须大于 0 秒（当前 %d），已使用 %d", c.ReconnectInterval, defaults.ReconnectInterval)
+		c.ReconnectInterval = defaults.ReconnectInterval
+	}
+	if c.HeartbeatInterval <= 0 {
+		report("heartbeat_interval", "心跳间隔必须大于 0 秒（当前 %d），已使用 %d", c.HeartbeatInterval, defaults.HeartbeatInterval)
+		c.HeartbeatInterval = defaults.HeartbeatInterval
+	}
+	if c.ScreenshotMaxWidth < 0 {
+		report("screenshot_max_width", "截图最大宽度不能为负数（0 表示不缩放），已使用 %d", defaults.ScreenshotMaxWidth)
+		c.ScreenshotMaxWidth = defaults.ScreenshotMaxWidth
+	}
+	if c.ScreenshotQuality < 1 || c.ScreenshotQuality > 100 {
+		report("screenshot_quality", "截图质量应在 1-100 之间（当前 %d），已使用 %d", c.ScreenshotQuality, defaults.ScreenshotQuality)
+		c.ScreenshotQuality = defaults.ScreenshotQuality
+	}
+	if provider := strings.ToLower(strings.TrimSpace(c.OCRProvider)); !validOCRProviders[provider] {
+		report("ocr_provider", "不支持的 OCR 执行提供者 %q（可用: auto, cpu, cuda, coreml, directml），已使用 cpu", c.OCRProvider)
+		c.OCRProvider = ""
+	}
+	if c.MetricsAddr != "" {
+		if _, err := metrics.ListenAddr(c.MetricsAddr); err != nil {
+			report("metrics_addr", "%v，已关闭指标服务", err)
+			c.MetricsAddr = ""
+		}
+	}
+	if (c.ClientCertFile == "") != (c.ClientKeyFile == "") {
+		field := "client_key_file"
+		if c.ClientCertFile == "" {
+			field = "client_cert_file"
+		}
+		report(field, "client_cert_file 和 client_key_file 必须同时设置，已忽略客户端证书")
+		c.ClientCertFile, c.ClientKeyFile = "", ""
+	}
+	return issues
+}
+
+// sortIssues 按配置名和字段名排序，输出稳定
+func sortIssues(issues []Issue) {
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Profile != issues[j].Profile {
+			return issues[i].Profile < issues[j].Profile
+		}
+		return issues[i].Field < issues[j].Field
+	})
+}
+
+// Validate 校验配置文件，返回所有问题（不修改文件）；文件无法解析时返回错误
+func (m *Manager) Validate() ([]Issue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set, err := m.read()
+	if err != nil {
+		return nil, err
+	}
+	return set.issues, nil
+}
+
+// reportIssues 将配置问题输出到日志，同一问题只输出一次（调用方持有写锁）
+func (m *Manager) reportIssues(issues []Issue) {
+	for _, issue := range issues {
+		msg := issue.String()
+		if m.reported[msg] {
+			continue
+		}
+		if m.reported == nil {
+			m.reported = make(map[string]bool)
+		}
+		m.reported[msg] = true
+		logger.Warn("配置文件 %s: %s", m.configFile, msg)
+	}
+}
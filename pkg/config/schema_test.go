@@ -0,0 +1,164 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfigFile 写入原始配置文件内容
+func writeConfigFile(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readVersion 读取配置文件中的 version 字段
+func readVersion(t *testing.T, path string) int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file profilesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	return file.Version
+}
+
+// hasIssue 问题列表中是否包含指定配置和字段的问题
+func hasIssue(issues []Issue, profile, field string) bool {
+	for _, issue := range issues {
+		if issue.Profile == profile && issue.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMigrateV1SingleConfig(t *testing.T) {
+	dir := t.TempDir()
+	original := `{"server_url":"old:1","access_key":"ak","secret_key":"sk","reconnect_interval":0,"log_level":"","screenshot_quality":80}`
+	path := writeConfigFile(t, dir, original)
+	manager := NewManagerWithDir(dir)
+
+	loaded, err := manager.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.ServerURL != "old:1" || loaded.SecretKey != "sk" {
+		t.Errorf("迁移后应保留原配置, 实际 %+v", loaded)
+	}
+	if loaded.ReconnectInterval != 5 || loaded.LogLevel != "INFO" || loaded.ScreenshotMaxWidth != 1280 {
+		t.Errorf("零值和缺失字段应使用默认值, 实际 %+v", loaded)
+	}
+
+	if v := readVersion(t, path); v != CurrentVersion {
+		t.Errorf("迁移后版本应为 %d, 实际 %d", CurrentVersion, v)
+	}
+	backup, err := os.ReadFile(path + ".v1.bak")
+	if err != nil {
+		t.Fatalf("迁移前应备份原文件: %v", err)
+	}
+	if string(backup) != original {
+		t.Errorf("备份内容应与原文件一致, 实际 %s", backup)
+	}
+}
+
+func TestMigrateV2ProfilesWithoutVersion(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfigFile(t, dir, `{
+  "current": "prod",
+  "profiles": {
+    "default": {"server_url": "a:1", "reconnect_interval": -3},
+    "prod": {"server_url": "b:1", "log_level": "", "reconnect_interval": 10}
+  }
+}`)
+	manager := NewManagerWithDir(dir)
+
+	loaded, err := manager.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.ServerURL != "b:1" || loaded.LogLevel != "INFO" || loaded.ReconnectInterval != 10 {
+		t.Errorf("当前配置应为 prod 且空日志级别使用默认值, 实际 %+v", loaded)
+	}
+	other, err := manager.LoadProfile(DefaultProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.ReconnectInterval != 5 {
+		t.Errorf("负数重连间隔应迁移为默认值, 实际 %d", other.ReconnectInterval)
+	}
+
+	if v := readVersion(t, path); v != CurrentVersion {
+		t.Errorf("迁移后版本应为 %d, 实际 %d", CurrentVersion, v)
+	}
+	if _, err := os.Stat(path + ".v2.bak"); err != nil {
+		t.Errorf("迁移前应备份原文件: %v", err)
+	}
+	issues, err := manager.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("迁移后的配置不应有问题, 实际 %v", issues)
+	}
+}
+
+func TestValidateReportsUnknownAndInvalidFields(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, `{
+  "version": 3,
+  "current": "missing",
+  "colour": "blue",
+  "profiles": {
+    "default": {"server_url": "a:1", "log_level": "VERBOSE", "screenshot_max_width": -1, "screenshot_quality": 101, "ocr_provider": "tpu", "servr_url": "typo", "client_cert_file": "agent.pem", "metrics_addr": "localhost"}
+  }
+}`)
+	manager := NewManagerWithDir(dir)
+
+	issues, err := manager.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []struct{ profile, field string }{
+		{"", "colour"},
+		{"", "current"},
+		{DefaultProfile, "servr_url"},
+		{DefaultProfile, "log_level"},
+		{DefaultProfile, "screenshot_max_width"},
+		{DefaultProfile, "screenshot_quality"},
+		{DefaultProfile, "ocr_provider"},
+		{DefaultProfile, "client_key_file"},
+		{DefaultProfile, "metrics_addr"},
+	} {
+		if !hasIssue(issues, want.profile, want.field) {
+			t.Errorf("应报告 %s/%s 的问题, 实际 %v", want.profile, want.field, issues)
+		}
+	}
+
+	loaded, err := manager.LoadProfile(DefaultProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.LogLevel != "INFO" || loaded.ScreenshotMaxWidth != 1280 || loaded.ScreenshotQuality != 60 || loaded.OCRProvider != "" || loaded.ClientCertFile != "" || loaded.MetricsAddr != "" {
+		t.Errorf("非法取值应替换为默认值, 实际 %+v", loaded)
+	}
+}
+
+func TestLoadRejectsNewerVersion(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, `{"version": 99, "current": "default", "profiles": {}}`)
+
+	_, err := NewManagerWithDir(dir).Load()
+	if err == nil || !strings.Contains(err.Error(), "99") {
+		t.Errorf("更高版本的配置文件应返回错误, 实际 %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// secretService 系统钥匙串中的服务名
+const secretService = "zoey-worker"
+
+// secretStore 密钥的静态加密存储（系统钥匙串、DPAPI 或本机密钥 AES-GCM）
+type secretStore interface {
+	// name 存储名，写入配置文件的 secret_store 字段
+	name() string
+	// seal 保存密钥，返回写入配置文件的密文（保存在系统钥匙串中时返回空字符串）
+	seal(account, secret string) (string, error)
+	// open 读取 seal 保存的密钥，sealed 为配置文件中的密文
+	open(account, sealed string) (string, error)
+	// remove 删除系统钥匙串中的密钥（不使用钥匙串的存储为空操作）
+	remove(account string) error
+}
+
+// secretStores 当前平台可用的存储，按优先级排列，前面的不可用时依次回退（测试时替换）
+var secretStores = platformSecretStores
+
+// fileConfig 配置文件格式：AccessKey / SecretKey 加密后写入 *_enc 字段，明文字段留空
+type fileConfig struct {
+	*ConnectionConfig
+	SecretStore  string `json:"secret_store,omitempty"`
+	AccessKeyEnc string `json:"access_key_enc,omitempty"`
+	SecretKeyEnc string `json:"secret_key_enc,omitempty"`
+}
+
+// account 钥匙串账户名：配置文件路径的哈希、配置名加字段名，不同配置目录和配置互不影响
+// profile 为空时为旧版单一配置的账户名
+func (m *Manager) account(profile, field string) string {
+	sum := sha256.Sum256([]byte(m.configFile))
+	if profile == "" {
+		return hex.EncodeToString(sum[:8]) + "/" + field
+	}
+	return hex.EncodeToString(sum[:8]) + "/" + profile + "/" + field
+}
+
+// sealSecrets 返回待写入文件的配置：密钥按优先级尝试各存储加密，全部失败时返回错误（不会写入明文）
+func (m *Manager) sealSecrets(profile string, config *ConnectionConfig) (*fileConfig, error) {
+	plain := *config
+	plain.AccessKey, plain.SecretKey = "", ""
+	file := &fileConfig{ConnectionConfig: &plain}
+	if config.AccessKey == "" && config.SecretKey == "" {
+		return file, nil
+	}
+
+	var errs []string
+	for _, store := range secretStores() {
+		accessEnc, err := store.seal(m.account(profile, "access_key"), config.AccessKey)
+		if err == nil {
+			var secretEnc string
+			if secretEnc, err = store.seal(m.account(profile, "secret_key"), config.SecretKey); err == nil {
+				file.SecretStore, file.AccessKeyEnc, file.SecretKeyEnc = store.name(), accessEnc, secretEnc
+				return file, nil
+			}
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", store.name(), err))
+	}
+	return nil, fmt.Errorf("加密密钥失败: %s", strings.Join(errs, "; "))
+}
+
+// openSecrets 解密配置文件中的密钥并写回 file.ConnectionConfig
+func (m *Manager) openSecrets(profile string, file *fileConfig) error {
+	if file.SecretStore == "" {
+		return nil // 未保存密钥
+	}
+	var store secretStore
+	for _, s := range secretStores() {
+		if s.name() == file.SecretStore {
+			store = s
+		}
+	}
+	if store == nil {
+		return fmt.Errorf("当前平台不支持密钥存储 %s，请重新填写访问密钥", file.SecretStore)
+	}
+
+	accessKey, err := store.open(m.account(profile, "access_key"), file.AccessKeyEnc)
+	if err != nil {
+		return fmt.Errorf("解密 AccessKey 失败（%s）: %w", store.name(), err)
+	}
+	secretKey, err := store.open(m.account(profile, "secret_key"), file.SecretKeyEnc)
+	if err != nil {
+		return fmt.Errorf("解密 SecretKey 失败（%s）: %w", store.name(), err)
+	}
+	file.AccessKey, file.SecretKey = accessKey, secretKey
+	return nil
+}
+
+// removeSecrets 删除配置保存在系统钥匙串中的密钥
+func (m *Manager) removeSecrets(profile string) {
+	for _, store := range secretStores() {
+		store.remove(m.account(profile, "access_key"))
+		store.remove(m.account(profile, "secret_key"))
+	}
+}
+
+// aesStore 使用本机派生密钥的 AES-GCM 加密，系统钥匙串不可用时的回退
+// 只能防止配置文件被复制到其他机器后泄露，不能防御本机上的其他进程
+type aesStore struct{}
+
+func (aesStore) name() string { return "aes-gcm" }
+
+func (aesStore) seal(account, secret string) (string, error) {
+	if secret == "" {
+		return "", nil
+	}
+	gcm, err := machineCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	// 以账户名作为附加数据，密文不能挪用到其他字段
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), []byte(account))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (aesStore) open(account, sealed string) (string, error) {
+	if sealed == "" {
+		return "", nil
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := machineCipher()
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("密文长度无效")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(account))
+	if err != nil {
+		return "", errors.New("密文无法解密（配置文件可能来自其他机器）")
+	}
+	return string(plain), nil
+}
+
+func (aesStore) remove(string) error { return nil }
+
+// machineCipher 由本机标识派生 AES-256-GCM 密钥
+func machineCipher() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(secretService + ":" + machineID()))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// machineID 本机标识：优先使用 systemd / dbus 的 machine-id，没有时使用主机名和用户目录
+func machineID() string {
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if data, err := os.ReadFile(path); err == nil {
+			if id := strings.TrimSpace(string(data)); id != "" {
+				return id
+			}
+		}
+	}
+	hostname, _ := os.Hostname()
+	homeDir, _ := os.UserHomeDir()
+	return hostname + ":" + homeDir
+}
+
+// Redacted 返回隐藏 SecretKey 的副本（用于打印配置）
+func (c *ConnectionConfig) Redacted() *ConnectionConfig {
+	redacted := *c
+	if redacted.SecretKey != "" {
+		redacted.SecretKey = "******"
+	}
+	return &redacted
+}
@@ -0,0 +1,38 @@
+//go:build darwin
+
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// platformSecretStores macOS：钥匙串，失败时回退 AES-GCM
+func platformSecretStores() []secretStore {
+	return []secretStore{keychainStore, aesStore{}}
+}
+
+// keychainStore macOS 钥匙串（security 命令），密钥以十六进制保存，避免交互模式的引号转义问题
+var keychainStore = keyringStore{
+	storeName: "keychain",
+	set: func(account, secret string) error {
+		command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", secretService, account, hex.EncodeToString([]byte(secret)))
+		_, err := runKeyringTool(command, "security", "-i")
+		return err
+	},
+	get: func(account string) (string, error) {
+		out, err := runKeyringTool("", "security", "find-generic-password", "-s", secretService, "-a", account, "-w")
+		if err != nil {
+			return "", err
+		}
+		secret, err := hex.DecodeString(out)
+		if err != nil {
+			return "", fmt.Errorf("钥匙串中的密钥格式错误: %w", err)
+		}
+		return string(secret), nil
+	},
+	del: func(account string) error {
+		_, err := runKeyringTool("", "security", "delete-generic-password", "-s", secretService, "-a", account)
+		return err
+	},
+}
@@ -0,0 +1,62 @@
+//go:build darwin || linux
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keyringStored 配置文件中的占位值，表示密钥保存在系统钥匙串中
+const keyringStored = "keyring"
+
+// keyringStore 通过命令行工具访问系统钥匙串（macOS security、Linux secret-tool）
+type keyringStore struct {
+	storeName string
+	set       func(account, secret string) error
+	get       func(account string) (string, error)
+	del       func(account string) error
+}
+
+func (s keyringStore) name() string { return s.storeName }
+
+func (s keyringStore) seal(account, secret string) (string, error) {
+	if secret == "" {
+		s.del(account)
+		return "", nil
+	}
+	if err := s.set(account, secret); err != nil {
+		return "", err
+	}
+	return keyringStored, nil
+}
+
+func (s keyringStore) open(account, sealed string) (string, error) {
+	if sealed == "" {
+		return "", nil
+	}
+	return s.get(account)
+}
+
+func (s keyringStore) remove(account string) error {
+	return s.del(account)
+}
+
+// runKeyringTool 执行钥匙串命令行工具，stdin 用于传递密钥（避免出现在进程参数中），返回去掉末尾换行的输出
+func runKeyringTool(stdin string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", name, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
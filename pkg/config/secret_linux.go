@@ -0,0 +1,30 @@
+//go:build linux
+
+package config
+
+import "os/exec"
+
+// platformSecretStores Linux：安装了 secret-tool 时使用 libsecret（GNOME Keyring / KWallet），否则 AES-GCM
+// 无桌面会话时 secret-tool 通常无法解锁密钥环，保存失败后自动回退 AES-GCM
+func platformSecretStores() []secretStore {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return []secretStore{aesStore{}}
+	}
+	return []secretStore{libsecretStore, aesStore{}}
+}
+
+// libsecretStore 通过 secret-tool 访问 libsecret
+var libsecretStore = keyringStore{
+	storeName: "libsecret",
+	set: func(account, secret string) error {
+		_, err := runKeyringTool(secret, "secret-tool", "store", "--label=Zoey Worker", "service", secretService, "account", account)
+		return err
+	},
+	get: func(account string) (string, error) {
+		return runKeyringTool("", "secret-tool", "lookup", "service", secretService, "account", account)
+	},
+	del: func(account string) error {
+		_, err := runKeyringTool("", "secret-tool", "clear", "service", secretService, "account", account)
+		return err
+	},
+}
@@ -0,0 +1,8 @@
+//go:build !darwin && !linux && !windows
+
+package config
+
+// platformSecretStores 其他平台只支持 AES-GCM
+func platformSecretStores() []secretStore {
+	return []secretStore{aesStore{}}
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// 测试不访问系统钥匙串
+	secretStores = func() []secretStore { return []secretStore{aesStore{}} }
+	os.Exit(m.Run())
+}
+
+// failingStore 始终保存失败的存储（模拟钥匙串不可用）
+type failingStore struct{}
+
+func (failingStore) name() string                        { return "failing" }
+func (failingStore) seal(string, string) (string, error) { return "", errors.New("keyring locked") }
+func (failingStore) open(string, string) (string, error) { return "", errors.New("keyring locked") }
+func (failingStore) remove(string) error                 { return nil }
+
+func TestSaveEncryptsSecrets(t *testing.T) {
+	manager := NewManagerWithDir(t.TempDir())
+	if err := manager.Save(&ConnectionConfig{ServerURL: "s:1", AccessKey: "plain-access", SecretKey: "plain-secret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := os.ReadFile(manager.GetConfigFile())
+	if strings.Contains(string(raw), "plain-access") || strings.Contains(string(raw), "plain-secret") {
+		t.Fatalf("配置文件不应包含明文密钥:\n%s", raw)
+	}
+	if !strings.Contains(string(raw), `"secret_store": "aes-gcm"`) {
+		t.Errorf("配置文件应记录密钥存储方式:\n%s", raw)
+	}
+
+	loaded, err := manager.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.AccessKey != "plain-access" || loaded.SecretKey != "plain-secret" {
+		t.Errorf("Load 应返回解密后的密钥, 实际 %q / %q", loaded.AccessKey, loaded.SecretKey)
+	}
+}
+
+func TestLoadMigratesPlaintextSecrets(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewManagerWithDir(dir)
+	old := []byte(`{"server_url":"old:1","access_key":"ak","secret_key":"sk"}`)
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), old, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := manager.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.AccessKey != "ak" || loaded.SecretKey != "sk" {
+		t.Errorf("迁移时应返回原有密钥, 实际 %q / %q", loaded.AccessKey, loaded.SecretKey)
+	}
+
+	raw, _ := os.ReadFile(manager.GetConfigFile())
+	if strings.Contains(string(raw), `"secret_key": "sk"`) || !strings.Contains(string(raw), "secret_key_enc") {
+		t.Errorf("首次加载后应改为加密存储:\n%s", raw)
+	}
+}
+
+func TestSecretStoreFallback(t *testing.T) {
+	saved := secretStores
+	secretStores = func() []secretStore { return []secretStore{failingStore{}, aesStore{}} }
+	t.Cleanup(func() { secretStores = saved })
+
+	manager := NewManagerWithDir(t.TempDir())
+	if err := manager.Save(&ConnectionConfig{AccessKey: "ak", SecretKey: "sk"}); err != nil {
+		t.Fatalf("钥匙串不可用时应回退 AES-GCM: %v", err)
+	}
+	raw, _ := os.ReadFile(manager.GetConfigFile())
+	if !strings.Contains(string(raw), `"secret_store": "aes-gcm"`) {
+		t.Errorf("应使用回退存储:\n%s", raw)
+	}
+
+	// 所有存储都失败时不写入明文
+	secretStores = func() []secretStore { return []secretStore{failingStore{}} }
+	if err := manager.Save(&ConnectionConfig{AccessKey: "ak2", SecretKey: "sk2"}); err == nil {
+		t.Error("所有存储都不可用时应返回错误")
+	}
+	if raw, _ := os.ReadFile(manager.GetConfigFile()); strings.Contains(string(raw), "sk2") {
+		t.Error("加密失败时不应写入明文密钥")
+	}
+}
+
+func TestLoadRejectsSwappedCiphertext(t *testing.T) {
+	manager := NewManagerWithDir(t.TempDir())
+	if err := manager.Save(&ConnectionConfig{AccessKey: "ak", SecretKey: "sk"}); err != nil {
+		t.Fatal(err)
+	}
+	loaded, _ := manager.Load()
+	file, err := manager.sealSecrets(DefaultProfile, loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 密文以账户名作为附加数据，交换字段后无法解密
+	file.AccessKeyEnc, file.SecretKeyEnc = file.SecretKeyEnc, file.AccessKeyEnc
+	if err := manager.openSecrets(DefaultProfile, file); err == nil {
+		t.Error("交换密文字段后应解密失败")
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	config := &ConnectionConfig{AccessKey: "ak", SecretKey: "sk"}
+	redacted := config.Redacted()
+	if redacted.SecretKey == "sk" || redacted.AccessKey != "ak" {
+		t.Errorf("Redacted = %+v", redacted)
+	}
+	if config.SecretKey != "sk" {
+		t.Error("Redacted 不应修改原配置")
+	}
+	if (&ConnectionConfig{}).Redacted().SecretKey != "" {
+		t.Error("未设置的 SecretKey 应保持为空")
+	}
+}
@@ -0,0 +1,91 @@
+//go:build windows
+
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	crypt32                = syscall.NewLazyDLL("crypt32.dll")
+	kernel32               = syscall.NewLazyDLL("kernel32.dll")
+	procCryptProtectData   = crypt32.NewProc("CryptProtectData")
+	procCryptUnprotectData = crypt32.NewProc("CryptUnprotectData")
+	procLocalFree          = kernel32.NewProc("LocalFree")
+)
+
+// cryptProtectUIForbidden 禁止 DPAPI 弹出界面
+const cryptProtectUIForbidden = 0x1
+
+// platformSecretStores Windows：DPAPI（绑定当前用户），失败时回退 AES-GCM
+func platformSecretStores() []secretStore {
+	return []secretStore{dpapiStore{}, aesStore{}}
+}
+
+// dataBlob DPAPI 的 DATA_BLOB
+type dataBlob struct {
+	cbData uint32
+	pbData *byte
+}
+
+func newBlob(data []byte) *dataBlob {
+	if len(data) == 0 {
+		return &dataBlob{}
+	}
+	return &dataBlob{cbData: uint32(len(data)), pbData: &data[0]}
+}
+
+// dpapiStore Windows DPAPI，密文写入配置文件，只有同一 Windows 用户能解密
+type dpapiStore struct{}
+
+func (dpapiStore) name() string { return "dpapi" }
+
+func (dpapiStore) seal(account, secret string) (string, error) {
+	if secret == "" {
+		return "", nil
+	}
+	out, err := dpapiCall(procCryptProtectData, []byte(secret), account)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+func (dpapiStore) open(account, sealed string) (string, error) {
+	if sealed == "" {
+		return "", nil
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	out, err := dpapiCall(procCryptUnprotectData, data, account)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func (dpapiStore) remove(string) error { return nil }
+
+// dpapiCall 调用 CryptProtectData / CryptUnprotectData，以账户名作为附加熵
+func dpapiCall(proc *syscall.LazyProc, data []byte, account string) ([]byte, error) {
+	in := newBlob(data)
+	entropy := newBlob([]byte(account))
+	var out dataBlob
+	r, _, err := proc.Call(
+		uintptr(unsafe.Pointer(in)), 0, uintptr(unsafe.Pointer(entropy)),
+		0, 0, cryptProtectUIForbidden, uintptr(unsafe.Pointer(&out)),
+	)
+	if r == 0 {
+		if err == nil || err == syscall.Errno(0) {
+			err = errors.New("DPAPI 调用失败")
+		}
+		return nil, err
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(out.pbData)))
+	return append([]byte(nil), unsafe.Slice(out.pbData, out.cbData)...), nil
+}
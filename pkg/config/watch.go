@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/internal/logger"
+)
+
+// fileStamp 配置文件的修改时间、大小和内容哈希
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+	sum     [32]byte
+}
+
+// OnChange 注册配置变更回调：Watch 检测到配置文件被其他进程修改（如运维下发新密钥）时，
+// 以重新加载后的当前配置调用；本进程的 Save / SaveProfile 等写入不会触发
+func (m *Manager) OnChange(fn func(*ConnectionConfig)) {
+	m.watchMu.Lock()
+	defer m.watchMu.Unlock()
+	m.onChange = append(m.onChange, fn)
+}
+
+// Watch 在后台每隔 interval 检查配置文件的修改时间和大小，内容变化时重新加载并调用 OnChange 回调，ctx 取消时停止
+func (m *Manager) Watch(ctx context.Context, interval time.Duration) {
+	last := m.stamp()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.checkChanged(&last)
+			}
+		}
+	}()
+}
+
+// stamp 读取配置文件当前状态（文件不存在时为零值）
+func (m *Manager) stamp() fileStamp {
+	info, err := os.Stat(m.configFile)
+	if err != nil {
+		return fileStamp{}
+	}
+	data, err := os.ReadFile(m.configFile)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size(), sum: sha256.Sum256(data)}
+}
+
+// checkChanged 检查配置文件是否被其他进程修改，修改时调用回调并返回 true
+// 修改时间或大小变化后才比较内容，内容与上次或本进程最后一次写入相同时忽略（避免保存后重复加载）
+func (m *Manager) checkChanged(last *fileStamp) bool {
+	info, err := os.Stat(m.configFile)
+	if err != nil {
+		return false // 文件被删除时保留当前配置
+	}
+	if info.ModTime().Equal(last.modTime) && info.Size() == last.size {
+		return false
+	}
+	current := m.stamp()
+	if current.sum == last.sum {
+		*last = current
+		return false
+	}
+	*last = current
+
+	m.mu.Lock()
+	self := m.written == current.sum
+	m.mu.Unlock()
+	if self {
+		return false
+	}
+
+	cfg, err := m.Load()
+	if err != nil {
+		// 编辑器可能分多次写入，下次修改时重试
+		logger.Warn("配置文件 %s 已修改，但重新加载失败: %v", m.configFile, err)
+		return false
+	}
+	logger.Info("配置文件 %s 已修改，重新加载", m.configFile)
+
+	m.watchMu.Lock()
+	callbacks := append([]func(*ConnectionConfig){}, m.onChange...)
+	m.watchMu.Unlock()
+	for _, fn := range callbacks {
+		fn(cfg)
+	}
+	return true
+}
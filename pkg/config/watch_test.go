@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchReloadsExternalChanges(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewManagerWithDir(dir)
+	if err := manager.Save(&ConnectionConfig{ServerURL: "old:1", AccessKey: "ak1", SecretKey: "sk1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var reloaded []*ConnectionConfig
+	manager.OnChange(func(cfg *ConnectionConfig) { reloaded = append(reloaded, cfg) })
+	last := manager.stamp()
+
+	// 本进程保存不触发回调
+	if err := manager.Save(&ConnectionConfig{ServerURL: "self:1", AccessKey: "ak1", SecretKey: "sk1"}); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, manager.GetConfigFile(), time.Now().Add(time.Second))
+	if manager.checkChanged(&last) || len(reloaded) != 0 {
+		t.Fatalf("本进程保存不应触发热加载, 实际 %d 次", len(reloaded))
+	}
+
+	// 其他进程修改（明文密钥，模拟运维下发）
+	path := filepath.Join(dir, "config.json")
+	external := `{"version": 3, "current": "default", "profiles": {"default": {"server_url": "new:1", "access_key": "ak2", "secret_key": "sk2", "log_level": "debug"}}}`
+	if err := os.WriteFile(path, []byte(external), 0600); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, path, time.Now().Add(2*time.Second))
+	if !manager.checkChanged(&last) {
+		t.Fatal("外部修改应触发热加载")
+	}
+	if len(reloaded) != 1 || reloaded[0].ServerURL != "new:1" || reloaded[0].SecretKey != "sk2" || reloaded[0].LogLevel != "DEBUG" {
+		t.Fatalf("回调应收到新配置, 实际 %+v", reloaded)
+	}
+
+	// 重新加载时明文密钥被加密写回，该写入不应再次触发
+	touch(t, path, time.Now().Add(3*time.Second))
+	if manager.checkChanged(&last) || len(reloaded) != 1 {
+		t.Errorf("重新加载后的迁移写入不应再次触发, 实际 %d 次", len(reloaded))
+	}
+}
+
+// touch 修改文件的修改时间（避免文件系统时间精度导致检测不到变化）
+func touch(t *testing.T, path string, mtime time.Time) {
+	t.Helper()
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,229 @@
+// Package doctor 提供运行环境诊断（zoeyworker doctor）：权限、截图、OCR、Python、UIA、配置文件和服务端连通性
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+	"github.com/zoeyai/zoeyworker/pkg/auto/text"
+	"github.com/zoeyai/zoeyworker/pkg/config"
+	"github.com/zoeyai/zoeyworker/pkg/grpc"
+	"github.com/zoeyai/zoeyworker/pkg/permissions"
+	"github.com/zoeyai/zoeyworker/pkg/plugin"
+	"github.com/zoeyai/zoeyworker/pkg/python"
+	"github.com/zoeyai/zoeyworker/pkg/uia"
+	"github.com/zoeyai/zoeyworker/pkg/vision/ocr"
+)
+
+// Status 检查结果状态
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn" // 不影响核心功能（如未安装 Python）
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip" // 当前平台不适用
+)
+
+// Check 单项检查结果
+type Check struct {
+	Name    string         `json:"name"`  // 检查项标识，如 screen、ocr
+	Title   string         `json:"title"` // 检查项名称
+	Status  Status         `json:"status"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// Report 诊断报告
+type Report struct {
+	Platform string  `json:"platform"`
+	OK       bool    `json:"ok"` // 没有失败的检查项
+	Checks   []Check `json:"checks"`
+}
+
+// 外部依赖（测试时替换）
+var (
+	goos                = runtime.GOOS
+	checkPermissions    = permissions.CheckPermissions
+	captureScreen       = screen.CaptureScreen
+	displayCount        = screen.GetDisplayCount
+	screenScale         = auto.GetScreenScaleFactor
+	ocrPluginStatus     = func() plugin.OCRPluginStatus { return plugin.GetOCRPlugin().GetStatus() }
+	bundledOCRAvailable = ocr.IsAvailable
+	warmupOCR           = text.Warmup
+	detectPython        = python.DetectPython
+	uiaSupported        = uia.IsSupported
+	configManager       = config.GetDefaultManager
+	probeServer         = grpc.ProbeServer
+)
+
+// Run 依次执行所有检查，server 为 nil 或未设置服务端地址时跳过服务端连通性检查
+// OCR 推理测试使用插件模型，调用前需先通过 text.SetOCRPlugin 设置插件
+func Run(ctx context.Context, server *grpc.ClientConfig) *Report {
+	report := &Report{Platform: goos + "/" + runtime.GOARCH, OK: true}
+	checks := []Check{
+		checkPermissionStatus(),
+		checkScreen(),
+		checkOCR(),
+		checkPython(),
+		checkUIA(),
+		checkConfig(),
+		checkServer(ctx, server),
+	}
+	for _, c := range checks {
+		if c.Status == StatusFail {
+			report.OK = false
+		}
+	}
+	report.Checks = checks
+	return report
+}
+
+// checkPermissionStatus 检查 macOS 辅助功能和屏幕录制权限
+func checkPermissionStatus() Check {
+	c := Check{Name: "permissions", Title: "系统权限"}
+	if goos != "darwin" {
+		c.Status, c.Message = StatusSkip, "仅 macOS 需要授权"
+		return c
+	}
+	status := checkPermissions()
+	c.Details = map[string]any{"accessibility": status.Accessibility, "screen_recording": status.ScreenRecording}
+	var missing []string
+	if !status.Accessibility {
+		missing = append(missing, "辅助功能")
+	}
+	if !status.ScreenRecording {
+		missing = append(missing, "屏幕录制")
+	}
+	if len(missing) > 0 {
+		c.Status = StatusFail
+		c.Message = fmt.Sprintf("未授予%s权限，请在 系统设置 > 隐私与安全性 中授权后重启程序", strings.Join(missing, "、"))
+		return c
+	}
+	c.Status, c.Message = StatusOK, "辅助功能和屏幕录制权限已授予"
+	return c
+}
+
+// checkScreen 实际截取一次屏幕，输出分辨率和缩放比例
+func checkScreen() Check {
+	c := Check{Name: "screen", Title: "屏幕截图"}
+	img, err := captureScreen()
+	if err != nil {
+		c.Status, c.Message = StatusFail, fmt.Sprintf("截图失败: %v", err)
+		return c
+	}
+	bounds := img.Bounds()
+	scale := screenScale()
+	c.Details = map[string]any{"width": bounds.Dx(), "height": bounds.Dy(), "scale": scale, "displays": displayCount()}
+	c.Status = StatusOK
+	c.Message = fmt.Sprintf("%dx%d，缩放 %.2fx", bounds.Dx(), bounds.Dy(), scale)
+	return c
+}
+
+// checkOCR 检查 OCR 模型来源（插件优先，其次内置模型），并执行一次空白图推理
+func checkOCR() Check {
+	c := Check{Name: "ocr", Title: "OCR"}
+	var source string
+	switch {
+	case ocrPluginStatus().Installed:
+		source = "plugin"
+	case bundledOCRAvailable():
+		source = "bundled"
+	default:
+		c.Status, c.Message = StatusFail, "未安装 OCR 插件，请运行 zoeyworker install-ocr"
+		return c
+	}
+	c.Details = map[string]any{"source": source}
+
+	elapsed, err := warmupOCR()
+	if err != nil {
+		c.Status, c.Message = StatusFail, fmt.Sprintf("OCR 推理失败（%s）: %v", source, err)
+		return c
+	}
+	c.Details["warmup_ms"] = elapsed.Milliseconds()
+	c.Status = StatusOK
+	c.Message = fmt.Sprintf("使用%s模型，推理测试耗时 %v", map[string]string{"plugin": "插件", "bundled": "内置"}[source], elapsed.Round(time.Millisecond))
+	return c
+}
+
+// checkPython 检测 Python 环境（仅 Python 脚本步骤需要）
+func checkPython() Check {
+	c := Check{Name: "python", Title: "Python"}
+	info := detectPython()
+	if !info.Available {
+		c.Status, c.Message = StatusWarn, "未检测到 Python 3，Python 脚本步骤不可用"
+		return c
+	}
+	c.Details = map[string]any{"version": info.Version, "path": info.Path}
+	c.Status, c.Message = StatusOK, fmt.Sprintf("Python %s (%s)", info.Version, info.Path)
+	return c
+}
+
+// checkUIA 检查 Windows UI Automation 支持
+func checkUIA() Check {
+	c := Check{Name: "uia", Title: "UI Automation"}
+	if goos != "windows" {
+		c.Status, c.Message = StatusSkip, "仅 Windows 支持"
+		return c
+	}
+	if !uiaSupported() {
+		c.Status, c.Message = StatusWarn, "UI Automation 不可用，元素类步骤将无法执行"
+		return c
+	}
+	c.Status, c.Message = StatusOK, "UI Automation 可用"
+	return c
+}
+
+// checkConfig 检查配置文件位置和内容
+func checkConfig() Check {
+	c := Check{Name: "config", Title: "配置文件"}
+	manager := configManager()
+	path := manager.GetConfigFile()
+	c.Details = map[string]any{"path": path}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		c.Status, c.Message = StatusWarn, fmt.Sprintf("%s 不存在（尚未保存配置）", path)
+		return c
+	}
+	issues, err := manager.Validate()
+	if err != nil {
+		c.Status, c.Message = StatusFail, fmt.Sprintf("%s 无法读取: %v", path, err)
+		return c
+	}
+	if len(issues) > 0 {
+		messages := make([]string, len(issues))
+		for i, issue := range issues {
+			messages[i] = issue.String()
+		}
+		c.Details["issues"] = messages
+		c.Status, c.Message = StatusWarn, fmt.Sprintf("%s 存在 %d 个问题", path, len(issues))
+		return c
+	}
+	c.Status, c.Message = StatusOK, path
+	return c
+}
+
+// checkServer 测试与服务端的 WebSocket 握手（不认证，使用配置的 TLS 选项）
+func checkServer(ctx context.Context, server *grpc.ClientConfig) Check {
+	c := Check{Name: "server", Title: "服务端连接"}
+	if server == nil || server.ServerURL == "" {
+		c.Status, c.Message = StatusSkip, "未配置服务端地址"
+		return c
+	}
+	start := time.Now()
+	wsURL, err := probeServer(ctx, server)
+	c.Details = map[string]any{"url": wsURL}
+	if err != nil {
+		c.Status, c.Message = StatusFail, err.Error()
+		return c
+	}
+	elapsed := time.Since(start)
+	c.Details["latency_ms"] = elapsed.Milliseconds()
+	c.Status, c.Message = StatusOK, fmt.Sprintf("%s 握手成功，耗时 %v", wsURL, elapsed.Round(time.Millisecond))
+	return c
+}
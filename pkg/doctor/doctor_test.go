@@ -0,0 +1,139 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/config"
+	"github.com/zoeyai/zoeyworker/pkg/grpc"
+	"github.com/zoeyai/zoeyworker/pkg/permissions"
+	"github.com/zoeyai/zoeyworker/pkg/plugin"
+	"github.com/zoeyai/zoeyworker/pkg/python"
+)
+
+// stubEnvironment 替换所有外部依赖为正常环境
+func stubEnvironment(t *testing.T, system string) {
+	t.Helper()
+	origGOOS, origPermissions, origCapture, origDisplays, origScale, origPlugin := goos, checkPermissions, captureScreen, displayCount, screenScale, ocrPluginStatus
+	origBundled, origWarmup, origPython, origUIA, origManager, origProbe := bundledOCRAvailable, warmupOCR, detectPython, uiaSupported, configManager, probeServer
+	t.Cleanup(func() {
+		goos, checkPermissions, captureScreen, displayCount, screenScale, ocrPluginStatus = origGOOS, origPermissions, origCapture, origDisplays, origScale, origPlugin
+		bundledOCRAvailable, warmupOCR, detectPython, uiaSupported, configManager, probeServer = origBundled, origWarmup, origPython, origUIA, origManager, origProbe
+	})
+
+	dir := t.TempDir()
+	manager := config.NewManagerWithDir(dir)
+	if err := manager.Save(&config.ConnectionConfig{ServerURL: "localhost:3001"}); err != nil {
+		t.Fatal(err)
+	}
+
+	goos = system
+	checkPermissions = func() *permissions.PermissionStatus {
+		return &permissions.PermissionStatus{Accessibility: true, ScreenRecording: true, AllGranted: true}
+	}
+	captureScreen = func() (image.Image, error) { return image.NewRGBA(image.Rect(0, 0, 2880, 1800)), nil }
+	displayCount = func() int { return 1 }
+	screenScale = func() float64 { return 2 }
+	ocrPluginStatus = func() plugin.OCRPluginStatus { return plugin.OCRPluginStatus{Installed: true} }
+	bundledOCRAvailable = func() bool { return false }
+	warmupOCR = func() (time.Duration, error) { return 300 * time.Millisecond, nil }
+	detectPython = func() *python.PythonInfo {
+		return &python.PythonInfo{Available: true, Version: "3.11.5", Path: "/usr/bin/python3"}
+	}
+	uiaSupported = func() bool { return true }
+	configManager = func() *config.Manager { return manager }
+	probeServer = func(ctx context.Context, server *grpc.ClientConfig) (string, error) {
+		return "ws://" + server.ServerURL + "/ws/agent", nil
+	}
+}
+
+// statuses 按检查项标识汇总状态
+func statuses(report *Report) map[string]Status {
+	out := make(map[string]Status)
+	for _, c := range report.Checks {
+		out[c.Name] = c.Status
+	}
+	return out
+}
+
+func TestRunAllPassing(t *testing.T) {
+	stubEnvironment(t, "darwin")
+	report := Run(context.Background(), &grpc.ClientConfig{ServerURL: "localhost:3001"})
+	if !report.OK {
+		t.Fatalf("所有检查通过时报告应为 OK: %+v", report.Checks)
+	}
+	want := map[string]Status{"permissions": StatusOK, "screen": StatusOK, "ocr": StatusOK, "python": StatusOK,
+		"uia": StatusSkip, "config": StatusOK, "server": StatusOK}
+	got := statuses(report)
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("%s 应为 %s, 实际 %s", name, status, got[name])
+		}
+	}
+
+	// JSON 输出包含截图分辨率等细节
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Checks []struct {
+			Name    string         `json:"name"`
+			Details map[string]any `json:"details"`
+		} `json:"checks"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range decoded.Checks {
+		if c.Name == "screen" && (c.Details["width"] != float64(2880) || c.Details["scale"] != float64(2)) {
+			t.Errorf("截图检查应包含分辨率和缩放比例: %+v", c.Details)
+		}
+	}
+}
+
+func TestRunReportsFailures(t *testing.T) {
+	stubEnvironment(t, "windows")
+	captureScreen = func() (image.Image, error) { return nil, errors.New("no display") }
+	ocrPluginStatus = func() plugin.OCRPluginStatus { return plugin.OCRPluginStatus{} }
+	detectPython = func() *python.PythonInfo { return &python.PythonInfo{} }
+	uiaSupported = func() bool { return false }
+	probeServer = func(ctx context.Context, server *grpc.ClientConfig) (string, error) {
+		return "ws://" + server.ServerURL + "/ws/agent", errors.New("connection refused")
+	}
+
+	report := Run(context.Background(), &grpc.ClientConfig{ServerURL: "localhost:3001"})
+	if report.OK {
+		t.Fatal("存在失败项时报告不应为 OK")
+	}
+	want := map[string]Status{"permissions": StatusSkip, "screen": StatusFail, "ocr": StatusFail, "python": StatusWarn,
+		"uia": StatusWarn, "config": StatusOK, "server": StatusFail}
+	got := statuses(report)
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("%s 应为 %s, 实际 %s", name, status, got[name])
+		}
+	}
+}
+
+func TestCheckConfigReportsIssues(t *testing.T) {
+	stubEnvironment(t, "linux")
+	path := configManager().GetConfigFile()
+	data := `{"version": 3, "current": "default", "profiles": {"default": {"server_url": "localhost:3001", "log_level": "verbose"}}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := checkConfig()
+	if c.Status != StatusWarn || len(c.Details["issues"].([]string)) != 1 {
+		t.Errorf("配置问题应报告为警告: %+v", c)
+	}
+	if c := checkServer(context.Background(), nil); c.Status != StatusSkip {
+		t.Errorf("未配置服务端地址时应跳过连通性检查, 实际 %s", c.Status)
+	}
+}
@@ -0,0 +1,78 @@
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+)
+
+// ErrTaskRunning 有任务正在执行，不能运行临时步骤
+var ErrTaskRunning = errors.New("有任务正在执行，请等待任务结束后再试")
+
+// adhocSeq 临时步骤序号（保证同一毫秒内的任务 ID 不重复）
+var adhocSeq atomic.Int64
+
+// RunStep 在本地执行单个临时步骤（GUI 演示、快速验证）：不向服务端发送任何消息，
+// 返回带执行前后截图的步骤结果；与其他任务一样记录日志、统计和本地执行历史（任务 ID 为 adhoc_<毫秒时间戳>_<序号>）
+// 有任务正在执行时不排队，直接返回 ErrTaskRunning；payload 不是合法 JSON 或任务类型是批量任务时返回错误
+func (e *Executor) RunStep(taskType, payloadJSON string) (*StepExecutionResult, error) {
+	if isBatchTaskType(taskType) || taskType == TaskTypeAIAction {
+		return nil, auto.Errorf(auto.ErrParam, "临时步骤不支持任务类型: %s", taskType)
+	}
+	payload := map[string]interface{}{}
+	if payloadJSON != "" {
+		if err := json.Unmarshal([]byte(payloadJSON), &payload); err != nil {
+			return nil, auto.Errorf(auto.ErrParam, "解析 payload 失败: %v", err)
+		}
+	}
+
+	taskID := fmt.Sprintf("adhoc_%d_%d", time.Now().UnixMilli(), adhocSeq.Add(1))
+	if !e.registerIdleTask(taskID, taskType) {
+		return nil, ErrTaskRunning
+	}
+	startTime := time.Now()
+	log("INFO", fmt.Sprintf("[Task:%s] 开始执行临时步骤 type=%s", taskID, taskType))
+	log("DEBUG", fmt.Sprintf("[Task:%s] payload=%s", taskID, truncateString(redactPayload(payloadJSON), 500)))
+	defer func() {
+		e.unregisterTask(taskID)
+		log("INFO", fmt.Sprintf("[Task:%s] 执行完成 duration=%v", taskID, time.Since(startTime)))
+	}()
+
+	// 可通过 CancelTask 取消（GUI 运行中任务的取消按钮）
+	ctx, cancel := e.taskContext(taskID)
+	defer cancel()
+	result := e.executeStepWithScreenshots(ctx, "", taskType, taskType, payload, e.parseScreenshotOptions(payload, true), nil)
+	e.recordHistoryStep(taskID, result)
+
+	success := result.Status == "SUCCESS"
+	status := pb.TaskStatus(pb.TaskStatus_value["TASK_STATUS_"+result.Status])
+	reason := pb.FailureReason(pb.FailureReason_value["FAILURE_REASON_"+result.FailureReason])
+	if success {
+		log("INFO", fmt.Sprintf("[Task:%s] 执行成功", taskID))
+	} else {
+		log("ERROR", fmt.Sprintf("[Task:%s] 执行失败 status=%s reason=%s", taskID, result.Status, result.FailureReason))
+		log("DEBUG", fmt.Sprintf("[Task:%s] 详细错误: %s", taskID, result.ErrorMessage))
+	}
+	e.recordTaskResult(success)
+	e.finishHistory(taskID, success, status, reason, result.ErrorMessage, startTime)
+	return result, nil
+}
+
+// registerIdleTask 没有其他任务运行时注册任务，否则返回 false
+func (e *Executor) registerIdleTask(taskID, taskType string) bool {
+	e.tasksMutex.Lock()
+	ok := len(e.runningTasks) == 0
+	if ok {
+		_, ok = e.registerTaskLocked(taskID, taskType)
+	}
+	e.tasksMutex.Unlock()
+	if ok {
+		e.awake.taskStarted()
+	}
+	return ok
+}
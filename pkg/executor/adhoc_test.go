@@ -0,0 +1,59 @@
+package executor
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/history"
+)
+
+func TestRunStep(t *testing.T) {
+	store, err := history.Open(filepath.Join(t.TempDir(), "history.db"), history.DefaultRetention)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	e, recorder := newTestExecutor()
+	e.SetHistory(store)
+
+	result, err := e.RunStep(TaskTypeWaitTime, `{"duration": 1, "screenshot_mode": "never"}`)
+	if err != nil || result.Status != "SUCCESS" || result.ActionType != "wait" {
+		t.Fatalf("RunStep = %+v, %v", result, err)
+	}
+	result, err = e.RunStep("no_such_action", `{"screenshot_mode": "never"}`)
+	if err != nil || result.Status != "FAILED" || result.FailureReason != "PARAM_ERROR" {
+		t.Fatalf("未知动作应返回失败的步骤结果: %+v, %v", result, err)
+	}
+	if len(recorder.messages) != 0 {
+		t.Errorf("临时步骤不应发送消息到服务端, 实际 %d 条", len(recorder.messages))
+	}
+
+	page, _ := store.List(1, 10, history.Filter{})
+	if page.Total != 2 || !strings.HasPrefix(page.Runs[1].ID, "adhoc_") || !page.Runs[1].Success || len(page.Runs[1].Steps) != 1 {
+		t.Fatalf("临时步骤应记录到执行历史: %+v", page.Runs)
+	}
+	if failed := page.Runs[0]; failed.Success || failed.FailureReason != "PARAM_ERROR" {
+		t.Errorf("失败记录错误: %+v", failed)
+	}
+	if executed, failed := e.Stats(); executed != 2 || failed != 1 {
+		t.Errorf("Stats = %d, %d", executed, failed)
+	}
+
+	// 参数错误、批量任务类型不执行
+	if _, err := e.RunStep(TaskTypeWaitTime, `{`); !errors.Is(err, auto.ErrParam) {
+		t.Errorf("非法 payload 应返回 ErrParam: %v", err)
+	}
+	if _, err := e.RunStep(TaskTypeDebugCase, `{}`); !errors.Is(err, auto.ErrParam) {
+		t.Errorf("批量任务类型应返回 ErrParam: %v", err)
+	}
+
+	// 有任务运行时拒绝
+	e.registerTask("server-task", TaskTypeExecutePlan)
+	if _, err := e.RunStep(TaskTypeWaitTime, `{"duration": 1}`); !errors.Is(err, ErrTaskRunning) {
+		t.Errorf("有任务运行时应返回 ErrTaskRunning: %v", err)
+	}
+	e.unregisterTask("server-task")
+}
@@ -0,0 +1,211 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	autoimage "github.com/zoeyai/zoeyworker/pkg/auto/image"
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+)
+
+// ErrAnchorNotFound relative_to 的锚点未找到（同时属于 auto.ErrNotFound，失败原因为 NOT_FOUND），
+// 与目标未找到通过 errors.Is 和步骤结果的 anchorNotFound 区分
+var ErrAnchorNotFound = fmt.Errorf("anchor %w", auto.ErrNotFound)
+
+// 锚点方向
+const (
+	DirectionLeft  = "left"
+	DirectionRight = "right"
+	DirectionAbove = "above"
+	DirectionBelow = "below"
+)
+
+// fullScreenArea 未限定搜索区域时锚点搜索区域的边界（可替换，便于测试）
+var fullScreenArea = func() auto.Region {
+	w, h := screen.GetScreenSize()
+	return auto.Region{Width: w, Height: h}
+}
+
+// relativeTo 解析后的 relative_to 参数
+type relativeTo struct {
+	image       string // 锚点图像（与 text 二选一）
+	text        string // 锚点文字
+	direction   string
+	maxDistance int  // 沿方向的最大距离（像素），0 表示到搜索区域边缘
+	padding     int  // 垂直于方向的两侧各扩展的像素
+	hasPadding  bool // 未指定 padding 时取锚点垂直方向尺寸的一半
+	params      map[string]interface{}
+}
+
+// parseRelativeTo 解析 relative_to：{image 或 text, direction, max_distance, padding, 以及锚点的查找选项}
+func parseRelativeTo(raw interface{}) (*relativeTo, error) {
+	params, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, auto.Errorf(auto.ErrParam, "relative_to 必须是对象")
+	}
+	r := &relativeTo{params: params}
+	r.image, _ = params["image"].(string)
+	r.text, _ = params["text"].(string)
+	if (r.image == "") == (r.text == "") {
+		return nil, auto.Errorf(auto.ErrParam, "relative_to 必须指定 image 或 text 之一作为锚点")
+	}
+
+	r.direction, _ = params["direction"].(string)
+	switch r.direction {
+	case DirectionLeft, DirectionRight, DirectionAbove, DirectionBelow:
+	default:
+		return nil, auto.Errorf(auto.ErrParam, "relative_to.direction 必须是 left、right、above、below 之一: %v", params["direction"])
+	}
+
+	if raw, ok := params["max_distance"]; ok {
+		v, ok := raw.(float64)
+		if !ok || v < 0 {
+			return nil, auto.Errorf(auto.ErrParam, "relative_to.max_distance 必须是非负数（像素）: %v", raw)
+		}
+		r.maxDistance = int(v)
+	}
+	if raw, ok := params["padding"]; ok {
+		v, ok := raw.(float64)
+		if !ok || v < 0 {
+			return nil, auto.Errorf(auto.ErrParam, "relative_to.padding 必须是非负数（像素）: %v", raw)
+		}
+		r.padding, r.hasPadding = int(v), true
+	}
+	return r, nil
+}
+
+// parseLocatorOptions 解析查找类任务的选项（同 parseAutoOptions）
+// 指定 relative_to 时先查找锚点，再将搜索区域限定为锚点指定方向上的区域，锚点边框和实际搜索区域写入 result
+func (e *Executor) parseLocatorOptions(ctx context.Context, payload map[string]interface{}, result *ActionResult) ([]auto.Option, error) {
+	opts, err := e.parseAutoOptions(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := payload["relative_to"]
+	if !ok {
+		return opts, nil
+	}
+	rel, err := parseRelativeTo(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	// 锚点和目标都在任务指定的搜索区域（region / window / display_id）内查找
+	area, err := screen.SearchRegion(auto.ApplyOptions(opts...))
+	if err != nil {
+		return nil, err
+	}
+	if area == nil {
+		full := fullScreenArea()
+		area = &full
+	}
+
+	anchor, err := e.findAnchor(ctx, rel, *area)
+	if err != nil {
+		return nil, err
+	}
+	result.AnchorBounds = &BoundsInfo{X: anchor.X, Y: anchor.Y, Width: anchor.Width, Height: anchor.Height}
+
+	region, ok := relativeRegion(anchor, rel, *area)
+	if !ok {
+		return nil, auto.Errorf(auto.ErrNotFound, "锚点 %s 方向上没有可搜索的区域", rel.direction)
+	}
+	result.SearchRegion = &BoundsInfo{X: region.X, Y: region.Y, Width: region.Width, Height: region.Height}
+	log("DEBUG", fmt.Sprintf("锚点 (%d,%d %dx%d)，%s 方向搜索区域 (%d,%d %dx%d)",
+		anchor.X, anchor.Y, anchor.Width, anchor.Height, rel.direction, region.X, region.Y, region.Width, region.Height))
+	return append(opts, auto.WithRegion(region.X, region.Y, region.Width, region.Height)), nil
+}
+
+// findAnchor 在 area 内查找一次锚点（不等待），返回锚点边框；未找到时返回 ErrAnchorNotFound
+// 锚点自身指定了 region / window / display_id 时使用锚点的搜索区域
+func (e *Executor) findAnchor(ctx context.Context, rel *relativeTo, area auto.Region) (auto.Region, error) {
+	opts, err := e.parseAutoOptions(ctx, rel.params)
+	if err != nil {
+		return auto.Region{}, fmt.Errorf("relative_to: %w", err)
+	}
+	if o := auto.ApplyOptions(opts...); o.Region == nil && o.DisplayID < 0 {
+		opts = append(opts, auto.WithRegion(area.X, area.Y, area.Width, area.Height))
+	}
+
+	if rel.image != "" {
+		match, err := locateImage(rel.image, append(opts, auto.WithTimeout(0))...)
+		if errors.Is(err, auto.ErrTimeout) || err == nil && match == nil {
+			return auto.Region{}, auto.Errorf(ErrAnchorNotFound, "未找到锚点图像: %s", truncateString(rel.image, 100))
+		}
+		if err != nil {
+			return auto.Region{}, fmt.Errorf("查找锚点图像失败: %w", err)
+		}
+		return autoimage.MatchRegion(match), nil
+	}
+
+	match, reason, err := findText(rel.text, opts)
+	if err != nil {
+		return auto.Region{}, fmt.Errorf("查找锚点文字失败: %w", err)
+	}
+	if match == nil {
+		if reason != nil {
+			return auto.Region{}, auto.Errorf(ErrAnchorNotFound, "未找到锚点文字 '%s': %v", rel.text, reason)
+		}
+		return auto.Region{}, auto.Errorf(ErrAnchorNotFound, "未找到锚点文字 '%s'", rel.text)
+	}
+	if match.Bounds.Width <= 0 || match.Bounds.Height <= 0 {
+		// 识别结果没有文字框时以中心点作为锚点
+		return auto.Region{X: match.Position.X, Y: match.Position.Y, Width: 1, Height: 1}, nil
+	}
+	return match.Bounds, nil
+}
+
+// relativeRegion 计算锚点指定方向上的搜索区域（不含锚点本身），裁剪到 area 内；区域为空时返回 false
+// 沿方向延伸 maxDistance（0 表示到 area 边缘），垂直方向为锚点的范围两侧各扩展 padding
+func relativeRegion(anchor auto.Region, rel *relativeTo, area auto.Region) (auto.Region, bool) {
+	x0, y0 := anchor.X, anchor.Y
+	x1, y1 := anchor.X+anchor.Width, anchor.Y+anchor.Height
+	areaX1, areaY1 := area.X+area.Width, area.Y+area.Height
+
+	horizontal := rel.direction == DirectionLeft || rel.direction == DirectionRight
+	padding := rel.padding
+	if !rel.hasPadding {
+		if horizontal {
+			padding = anchor.Height / 2
+		} else {
+			padding = anchor.Width / 2
+		}
+	}
+	if horizontal {
+		y0, y1 = y0-padding, y1+padding
+	} else {
+		x0, x1 = x0-padding, x1+padding
+	}
+
+	switch rel.direction {
+	case DirectionRight:
+		x0, x1 = x1, areaX1
+		if rel.maxDistance > 0 {
+			x1 = min(x1, x0+rel.maxDistance)
+		}
+	case DirectionLeft:
+		x0, x1 = area.X, x0
+		if rel.maxDistance > 0 {
+			x0 = max(x0, x1-rel.maxDistance)
+		}
+	case DirectionBelow:
+		y0, y1 = y1, areaY1
+		if rel.maxDistance > 0 {
+			y1 = min(y1, y0+rel.maxDistance)
+		}
+	case DirectionAbove:
+		y0, y1 = area.Y, y0
+		if rel.maxDistance > 0 {
+			y0 = max(y0, y1-rel.maxDistance)
+		}
+	}
+
+	x0, y0 = max(x0, area.X), max(y0, area.Y)
+	x1, y1 = min(x1, areaX1), min(y1, areaY1)
+	if x1 <= x0 || y1 <= y0 {
+		return auto.Region{}, false
+	}
+	return auto.Region{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}, true
+}
@@ -0,0 +1,136 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/text"
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+	"github.com/zoeyai/zoeyworker/pkg/vision/cv"
+)
+
+func TestRelativeRegion(t *testing.T) {
+	area := auto.Region{Width: 1000, Height: 800}
+	anchor := auto.Region{X: 100, Y: 200, Width: 50, Height: 20}
+	tests := []struct {
+		name string
+		rel  relativeTo
+		want auto.Region
+		ok   bool
+	}{
+		{"right to edge", relativeTo{direction: DirectionRight}, auto.Region{X: 150, Y: 190, Width: 850, Height: 40}, true},
+		{"right max distance", relativeTo{direction: DirectionRight, maxDistance: 300}, auto.Region{X: 150, Y: 190, Width: 300, Height: 40}, true},
+		{"left clipped", relativeTo{direction: DirectionLeft, maxDistance: 500}, auto.Region{X: 0, Y: 190, Width: 100, Height: 40}, true},
+		{"below no padding", relativeTo{direction: DirectionBelow, maxDistance: 100, hasPadding: true}, auto.Region{X: 100, Y: 220, Width: 50, Height: 100}, true},
+		{"above padding", relativeTo{direction: DirectionAbove, padding: 10, hasPadding: true}, auto.Region{X: 90, Y: 0, Width: 70, Height: 200}, true},
+		{"nothing left of edge", relativeTo{direction: DirectionLeft}, auto.Region{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := anchor
+			if !tt.ok {
+				a.X = 0
+			}
+			got, ok := relativeRegion(a, &tt.rel, area)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("relativeRegion = %+v, %v, 期望 %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRelativeTo(t *testing.T) {
+	mockLocate(t)
+	origArea := fullScreenArea
+	fullScreenArea = func() auto.Region { return auto.Region{Width: 1000, Height: 800} }
+	t.Cleanup(func() { fullScreenArea = origArea })
+
+	regions := map[string]*auto.Region{}
+	findTextOnce = func(target string, opts ...auto.Option) (*text.TextMatch, error) {
+		regions[target] = auto.ApplyOptions(opts...).Region
+		switch target {
+		case "Order 123":
+			return &text.TextMatch{Text: target, Position: auto.Point{X: 140, Y: 210}, Bounds: auto.Region{X: 100, Y: 200, Width: 80, Height: 20}, Confidence: 0.9}, nil
+		case "删除":
+			return &text.TextMatch{Text: target, Position: auto.Point{X: 400, Y: 210}, Bounds: auto.Region{X: 380, Y: 200, Width: 40, Height: 20}, Confidence: 0.95}, nil
+		}
+		return nil, nil
+	}
+	locateImage = func(source string, opts ...auto.Option) (*cv.MatchResult, error) {
+		regions[source] = auto.ApplyOptions(opts...).Region
+		if source != "anchor.png" {
+			return nil, auto.Errorf(auto.ErrTimeout, "等待图像超时")
+		}
+		return &cv.MatchResult{
+			Result:     cv.Point{X: 510, Y: 520},
+			Rectangle:  cv.Rectangle{TopLeft: cv.Point{X: 500, Y: 500}, TopRight: cv.Point{X: 520, Y: 500}, BottomLeft: cv.Point{X: 500, Y: 540}, BottomRight: cv.Point{X: 520, Y: 540}},
+			Confidence: 0.9,
+		}, nil
+	}
+	e, _ := newTestExecutor()
+	run := func(taskType, payloadJSON string) (*ActionResult, error) {
+		return e.runAction(context.Background(), taskType, decodePayload(t, payloadJSON))
+	}
+
+	result, err := run(TaskTypeAssertText, `{"text": "删除", "relative_to": {"text": "Order 123", "direction": "right", "max_distance": 400}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *regions["Order 123"] != (auto.Region{Width: 1000, Height: 800}) {
+		t.Errorf("锚点应在全屏查找: %+v", regions["Order 123"])
+	}
+	wantRegion := auto.Region{X: 180, Y: 190, Width: 400, Height: 40}
+	if *regions["删除"] != wantRegion {
+		t.Errorf("目标搜索区域 = %+v, 期望 %+v", regions["删除"], wantRegion)
+	}
+	if *result.AnchorBounds != (BoundsInfo{X: 100, Y: 200, Width: 80, Height: 20}) || *result.SearchRegion != (BoundsInfo{X: 180, Y: 190, Width: 400, Height: 40}) {
+		t.Errorf("AnchorBounds = %+v, SearchRegion = %+v", result.AnchorBounds, result.SearchRegion)
+	}
+	if result.TargetBounds == nil || result.TargetBounds.X != 380 {
+		t.Errorf("TargetBounds = %+v", result.TargetBounds)
+	}
+
+	// 图像锚点，限定的 region 内查找锚点
+	result, err = run(TaskTypeImageExists, `{"image": "target.png", "region": {"x": 400, "y": 400, "width": 400, "height": 300}, "relative_to": {"image": "anchor.png", "direction": "below", "padding": 0}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *regions["anchor.png"] != (auto.Region{X: 400, Y: 400, Width: 400, Height: 300}) {
+		t.Errorf("锚点应在 region 内查找: %+v", regions["anchor.png"])
+	}
+	if *regions["target.png"] != (auto.Region{X: 500, Y: 540, Width: 20, Height: 160}) {
+		t.Errorf("目标搜索区域 = %+v", regions["target.png"])
+	}
+	if result.Data.(map[string]bool)["exists"] {
+		t.Error("目标不存在时 exists 应为 false")
+	}
+
+	// 锚点未找到与目标未找到可区分
+	_, err = run(TaskTypeAssertText, `{"text": "删除", "relative_to": {"text": "Order 999", "direction": "right"}}`)
+	if !errors.Is(err, ErrAnchorNotFound) || classifyError(err).Reason != pb.FailureReason_FAILURE_REASON_NOT_FOUND {
+		t.Errorf("锚点未找到应返回 ErrAnchorNotFound（NOT_FOUND）: %v", err)
+	}
+	_, err = run(TaskTypeAssertText, `{"text": "编辑", "relative_to": {"text": "Order 123", "direction": "right"}}`)
+	if errors.Is(err, ErrAnchorNotFound) || !errors.Is(err, ErrAssertionFailed) {
+		t.Errorf("目标未找到不应是 ErrAnchorNotFound: %v", err)
+	}
+	step := e.executeStepWithScreenshots(context.Background(), "", "s1", TaskTypeAssertText,
+		map[string]interface{}{"text": "删除", "relative_to": map[string]interface{}{"text": "Order 999", "direction": "left"}},
+		screenshotOptions{Mode: ScreenshotModeNever}, nil)
+	if !step.AnchorNotFound || step.FailureReason != "NOT_FOUND" {
+		t.Errorf("步骤结果应标记锚点未找到: %+v", step)
+	}
+
+	for _, payload := range []string{
+		`{"text": "删除", "relative_to": {"text": "Order 123", "direction": "up"}}`,
+		`{"text": "删除", "relative_to": {"direction": "right"}}`,
+		`{"text": "删除", "relative_to": {"text": "a", "image": "b.png", "direction": "right"}}`,
+		`{"text": "删除", "relative_to": {"text": "Order 123", "direction": "right", "max_distance": -1}}`,
+	} {
+		if _, err := run(TaskTypeTextExists, payload); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("%s 应返回 ErrParam: %v", payload, err)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+)
+
+// checkpointExt 断点文件扩展名；写入时先写同名 .tmp 文件再重命名，进程在写入中被杀死时不会留下半个 JSON
+const checkpointExt = ".json"
+
+// taskCheckpoint 批量任务断点：在步骤（和用例）边界写入磁盘，任务结束时删除；
+// 进程崩溃后残留的断点在重启并连接后上报为失败，服务端不会一直等待结果
+type taskCheckpoint struct {
+	TaskID    string `json:"task_id"`
+	TaskType  string `json:"task_type"`
+	StartedAt int64  `json:"started_at"` // 任务开始时间（毫秒时间戳）
+	UpdatedAt int64  `json:"updated_at"` // 最近一次写入时间
+
+	// execute_plan 的用例进度：正在执行第 CaseIndex 个用例（从 0 开始）
+	CaseIndex      int    `json:"case_index,omitempty"`
+	CaseID         string `json:"case_id,omitempty"`
+	TotalCases     int    `json:"total_cases,omitempty"`
+	CompletedCases int32  `json:"completed_cases,omitempty"`
+	PassedCases    int32  `json:"passed_cases,omitempty"`
+	FailedCases    int32  `json:"failed_cases,omitempty"`
+
+	// 步骤进度（execute_plan 中为当前用例的步骤）：正在执行第 StepIndex 个步骤（从 0 开始）
+	StepIndex   int32  `json:"step_index"`
+	CurrentStep string `json:"current_step,omitempty"`
+	TotalSteps  int32  `json:"total_steps"`
+	PassedSteps int32  `json:"passed_steps"`
+	FailedSteps int32  `json:"failed_steps"`
+
+	Interrupted bool `json:"interrupted,omitempty"` // 上报时为 true
+
+	path string
+}
+
+// SetCheckpointDir 设置批量任务断点目录（为空时不记录），应在接收任务前调用
+// 目录中上次运行残留的断点（进程崩溃或被杀死时正在执行的任务）在 ReportInterruptedTasks 时上报
+func (e *Executor) SetCheckpointDir(dir string) {
+	var leftovers []*taskCheckpoint
+	if dir != "" {
+		var err error
+		if leftovers, err = loadCheckpoints(dir); err != nil {
+			log("WARN", fmt.Sprintf("读取任务断点失败: %v", err))
+		}
+		if len(leftovers) > 0 {
+			log("WARN", fmt.Sprintf("上次运行中断的批量任务 %d 个，连接后上报失败", len(leftovers)))
+		}
+	}
+
+	e.tasksMutex.Lock()
+	defer e.tasksMutex.Unlock()
+	e.checkpointDir = dir
+	e.interrupted = leftovers
+}
+
+// ReportInterruptedTasks 将上次运行中断的批量任务上报为失败（SYSTEM_ERROR，附带中断时的进度）并删除断点，
+// 连接服务端后调用；只上报 SetCheckpointDir 时读取到的断点，重复调用不会重复上报。返回上报的任务数
+func (e *Executor) ReportInterruptedTasks() int {
+	e.tasksMutex.Lock()
+	leftovers := e.interrupted
+	e.interrupted = nil
+	e.tasksMutex.Unlock()
+
+	reported := 0
+	for _, cp := range leftovers {
+		e.tasksMutex.Lock()
+		_, running := e.runningTasks[cp.TaskID]
+		e.tasksMutex.Unlock()
+		if running {
+			// 服务端已重新下发该任务，断点由新的执行覆盖
+			continue
+		}
+		message := cp.interruptedMessage()
+		log("WARN", fmt.Sprintf("[Task:%s] %s", cp.TaskID, message))
+		cp.Interrupted = true
+		resultJSON, _ := json.Marshal(cp)
+		// 耗时按任务开始到最后一次断点计算
+		startTime := time.Now().Add(-time.Duration(cp.UpdatedAt-cp.StartedAt) * time.Millisecond)
+		e.sendTaskResultWithError(cp.TaskID, newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR, message), nil, startTime, string(resultJSON))
+		if err := os.Remove(cp.path); err != nil && !os.IsNotExist(err) {
+			log("WARN", fmt.Sprintf("[Task:%s] 删除任务断点失败: %v", cp.TaskID, err))
+		}
+		reported++
+	}
+	return reported
+}
+
+// interruptedMessage 中断任务的失败信息
+func (cp *taskCheckpoint) interruptedMessage() string {
+	where := fmt.Sprintf("第 %d/%d 个步骤", cp.StepIndex+1, cp.TotalSteps)
+	if cp.TotalSteps == 0 {
+		where = "第一个步骤之前"
+	}
+	if cp.TaskType == TaskTypeExecutePlan && cp.TotalCases > 0 {
+		where = fmt.Sprintf("第 %d/%d 个用例（%s）的", cp.CaseIndex+1, cp.TotalCases, cp.CaseID) + where
+	}
+	return fmt.Sprintf("worker restarted during execution: Worker 在任务执行中重启，中断于%s（通过 %d，失败 %d）", where, cp.PassedSteps, cp.FailedSteps)
+}
+
+// newCheckpoint 为批量任务创建断点，未设置断点目录或不是批量任务时返回 nil；调用方持有 tasksMutex
+func (e *Executor) newCheckpoint(info *TaskInfo) *taskCheckpoint {
+	if e.checkpointDir == "" || !isBatchTaskType(info.TaskType) {
+		return nil
+	}
+	return &taskCheckpoint{
+		TaskID:    info.TaskID,
+		TaskType:  info.TaskType,
+		StartedAt: info.StartedAt,
+		path:      filepath.Join(e.checkpointDir, checkpointFileName(info.TaskID)),
+	}
+}
+
+// saveCheckpoint 写入任务的当前断点（任务没有断点时不做任何事），写入失败只输出日志
+func (e *Executor) saveCheckpoint(taskID string) {
+	e.tasksMutex.Lock()
+	info := e.runningTasks[taskID]
+	if info == nil || info.checkpoint == nil {
+		e.tasksMutex.Unlock()
+		return
+	}
+	cp := *info.checkpoint
+	e.tasksMutex.Unlock()
+
+	cp.UpdatedAt = time.Now().UnixMilli()
+	if err := writeCheckpoint(&cp); err != nil {
+		log("WARN", fmt.Sprintf("[Task:%s] 写入任务断点失败: %v", taskID, err))
+	}
+}
+
+// recordCaseProgress 记录 execute_plan 开始执行第 caseIndex 个用例，并写入断点
+func (e *Executor) recordCaseProgress(taskID string, caseIndex, totalCases int, caseID string, completedCases, passedCases, failedCases int32) {
+	e.tasksMutex.Lock()
+	if info := e.runningTasks[taskID]; info != nil && info.checkpoint != nil {
+		cp := info.checkpoint
+		cp.CaseIndex, cp.TotalCases, cp.CaseID = caseIndex, totalCases, caseID
+		cp.CompletedCases, cp.PassedCases, cp.FailedCases = completedCases, passedCases, failedCases
+		cp.StepIndex, cp.CurrentStep, cp.TotalSteps, cp.PassedSteps, cp.FailedSteps = 0, "", 0, 0, 0
+	}
+	e.tasksMutex.Unlock()
+	e.saveCheckpoint(taskID)
+}
+
+// removeCheckpoint 任务结束时删除断点文件
+func removeCheckpoint(cp *taskCheckpoint) {
+	if cp == nil {
+		return
+	}
+	if err := os.Remove(cp.path); err != nil && !os.IsNotExist(err) {
+		log("WARN", fmt.Sprintf("[Task:%s] 删除任务断点失败: %v", cp.TaskID, err))
+	}
+}
+
+// writeCheckpoint 原子写入断点：先写临时文件再重命名覆盖
+func writeCheckpoint(cp *taskCheckpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cp.path), 0755); err != nil {
+		return fmt.Errorf("创建断点目录失败: %w", err)
+	}
+	tmp := cp.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, cp.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// loadCheckpoints 读取目录中残留的断点；写入中断残留的临时文件和无法解析的断点直接删除
+func loadCheckpoints(dir string) ([]*taskCheckpoint, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var checkpoints []*taskCheckpoint
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			os.Remove(path)
+			continue
+		}
+		if !strings.HasSuffix(entry.Name(), checkpointExt) {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return checkpoints, err
+		}
+		cp := &taskCheckpoint{}
+		if err := json.Unmarshal(data, cp); err != nil || cp.TaskID == "" {
+			log("WARN", fmt.Sprintf("任务断点 %s 无法解析，已删除", entry.Name()))
+			os.Remove(path)
+			continue
+		}
+		cp.path = path
+		checkpoints = append(checkpoints, cp)
+	}
+	return checkpoints, nil
+}
+
+// checkpointFileName 断点文件名（任务 ID 中不能用作文件名的字符替换为 _）
+func checkpointFileName(taskID string) string {
+	return safeFileName(taskID) + checkpointExt
+}
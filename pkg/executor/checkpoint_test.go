@@ -0,0 +1,123 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+)
+
+func TestCheckpointRecovery(t *testing.T) {
+	reached, release := make(chan struct{}), make(chan struct{})
+	RegisterAction("test_pass", func(ctx context.Context, payload map[string]interface{}) (*ActionResult, error) {
+		return &ActionResult{}, nil
+	})
+	RegisterAction("test_fail", func(ctx context.Context, payload map[string]interface{}) (*ActionResult, error) {
+		return nil, auto.Errorf(ErrAssertionFailed, "断言失败")
+	})
+	RegisterAction("test_block", func(ctx context.Context, payload map[string]interface{}) (*ActionResult, error) {
+		reached <- struct{}{}
+		<-release
+		return &ActionResult{}, nil
+	})
+	t.Cleanup(func() {
+		actionsMu.Lock()
+		delete(actions, "test_pass")
+		delete(actions, "test_fail")
+		delete(actions, "test_block")
+		actionsMu.Unlock()
+	})
+
+	dir := t.TempDir()
+	e, _ := newTestExecutor()
+	e.SetCheckpointDir(dir)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.Execute("plan/1", TaskTypeExecutePlan, `{"screenshot_mode": "never", "cases": [
+			{"case_id": "c1", "steps": [{"step_id": "s1", "task_type": "test_pass", "params": {}}]},
+			{"case_id": "c2", "steps": [
+				{"step_id": "s2", "task_type": "test_pass", "params": {}},
+				{"step_id": "s3", "task_type": "test_fail", "params": {}},
+				{"step_id": "s4", "task_type": "test_block", "params": {}},
+				{"step_id": "s5", "task_type": "test_pass", "params": {}}]}]}`)
+	}()
+
+	// 第 4 个步骤执行中进程被杀死：此时磁盘上的断点就是重启后看到的状态
+	select {
+	case <-reached:
+	case <-time.After(5 * time.Second):
+		t.Fatal("步骤未开始执行")
+	}
+	crashDir := t.TempDir()
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != "plan_1.json" {
+		t.Fatalf("执行中应只有一个断点文件（没有残留的临时文件）: %v", entries)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cp taskCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		t.Fatalf("断点不是合法 JSON: %v %s", err, data)
+	}
+	if cp.TaskID != "plan/1" || cp.TaskType != TaskTypeExecutePlan || cp.CaseIndex != 1 || cp.CaseID != "c2" || cp.TotalCases != 2 ||
+		cp.CompletedCases != 1 || cp.PassedCases != 1 || cp.StepIndex != 2 || cp.TotalSteps != 4 || cp.PassedSteps != 1 || cp.FailedSteps != 1 ||
+		cp.StartedAt == 0 || cp.UpdatedAt < cp.StartedAt {
+		t.Errorf("断点内容错误: %s", data)
+	}
+	os.WriteFile(filepath.Join(crashDir, entries[0].Name()), data, 0644)
+
+	close(release)
+	<-done
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("任务结束后应删除断点: %v", entries)
+	}
+
+	// 重启：写入中断残留的临时文件和损坏的断点被清理，不会上报
+	os.WriteFile(filepath.Join(crashDir, "other.json.tmp"), []byte(`{"task_id": "ot`), 0644)
+	os.WriteFile(filepath.Join(crashDir, "broken.json"), []byte(`{"task_id": `), 0644)
+	restarted, recorder := newTestExecutor()
+	restarted.SetCheckpointDir(crashDir)
+	if res := recorder.results("plan/1"); len(res) != 0 {
+		t.Fatalf("连接前不应上报: %+v", res)
+	}
+	if n := restarted.ReportInterruptedTasks(); n != 1 {
+		t.Fatalf("应上报 1 个中断的任务，实际 %d", n)
+	}
+	res := recorder.results("plan/1")
+	if len(res) != 1 || res[0].Success || res[0].Status != pb.TaskStatus_TASK_STATUS_FAILED ||
+		res[0].FailureReason != pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR || !strings.Contains(res[0].Message, "worker restarted during execution") {
+		t.Fatalf("应上报 SYSTEM_ERROR 失败: %+v", res)
+	}
+	var partial map[string]interface{}
+	if err := json.Unmarshal([]byte(res[0].ResultJson), &partial); err != nil || partial["interrupted"] != true ||
+		partial["passed_cases"] != 1.0 || partial["passed_steps"] != 1.0 || partial["failed_steps"] != 1.0 || partial["case_id"] != "c2" {
+		t.Errorf("结果应附带中断时的进度: %s %v", res[0].ResultJson, err)
+	}
+	if entries, _ := os.ReadDir(crashDir); len(entries) != 0 {
+		t.Errorf("上报后应清理断点目录: %v", entries)
+	}
+	if n := restarted.ReportInterruptedTasks(); n != 0 || len(recorder.results("plan/1")) != 1 {
+		t.Errorf("不应重复上报: %d", n)
+	}
+
+	// 服务端重复下发时返回上报过的失败结果，不重新执行
+	restarted.Execute("plan/1", TaskTypeExecutePlan, `{"cases": []}`)
+	if res := recorder.results("plan/1"); len(res) != 2 || res[1].FailureReason != pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR {
+		t.Errorf("重复下发应重发中断结果: %+v", res)
+	}
+
+	// 单步任务不写断点
+	e.Execute("single", "test_pass", `{}`)
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("单步任务不应写断点: %v", entries)
+	}
+}
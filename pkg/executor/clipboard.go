@@ -0,0 +1,226 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/jpeg" // image_base64 支持 JPEG
+	_ "image/png"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/input"
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+)
+
+// 可替换的剪贴板实现（便于测试）
+var (
+	readClipboard        = input.ReadClipboard
+	writeClipboard       = input.CopyToClipboard
+	readClipboardImage   = input.ReadClipboardImage
+	writeClipboardImage  = input.CopyImageToClipboard
+	clipboardContentType = input.ClipboardContentType
+)
+
+// 剪贴板断言的匹配模式
+const (
+	ClipboardMatchExact    = "exact"    // 去除首尾空白后完全相等（默认）
+	ClipboardMatchContains = "contains" // 包含
+	ClipboardMatchRegex    = "regex"    // 满足正则表达式
+)
+
+// 剪贴板断言结果中实际文字的最大长度（字节），以及差异摘录在差异位置前后保留的字符数
+const (
+	clipboardActualMaxBytes = 4096
+	clipboardExcerptRunes   = 40
+)
+
+// currentClipboardType 返回剪贴板内容类型；无法判断时（如缺少剪贴板工具）按文字处理
+func currentClipboardType() string {
+	contentType, err := clipboardContentType()
+	if err != nil {
+		log("WARN", fmt.Sprintf("获取剪贴板内容类型失败，按文字读取: %v", err))
+		return input.ClipboardText
+	}
+	return contentType
+}
+
+// executeGetClipboard 执行获取剪贴板：文字返回 text，图像返回 image_base64（PNG data URL）及宽高
+func (e *Executor) executeGetClipboard(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
+	contentType := currentClipboardType()
+	data := map[string]interface{}{"content_type": contentType, "text": ""}
+	if contentType == input.ClipboardImage {
+		img, err := readClipboardImage()
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := screen.ImageToBase64(img, "png", 0)
+		if err != nil {
+			return nil, err
+		}
+		data["image_base64"] = encoded
+		data["width"] = img.Bounds().Dx()
+		data["height"] = img.Bounds().Dy()
+		return data, nil
+	}
+
+	textStr, err := readClipboard()
+	if err != nil {
+		return nil, err
+	}
+	data["text"] = textStr
+	return data, nil
+}
+
+// executeSetClipboard 执行设置剪贴板：text 或 image_base64（PNG / JPEG，可为 data URL）二选一
+func (e *Executor) executeSetClipboard(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
+	textStr, hasText := payload["text"].(string)
+	imageStr, _ := payload["image_base64"].(string)
+	if hasText && imageStr != "" {
+		return nil, auto.Errorf(auto.ErrParam, "text 和 image_base64 只能指定一个")
+	}
+
+	if imageStr != "" {
+		img, err := decodeBase64Image(imageStr)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeClipboardImage(img); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"copied":       true,
+			"content_type": input.ClipboardImage,
+			"width":        img.Bounds().Dx(),
+			"height":       img.Bounds().Dy(),
+		}, nil
+	}
+
+	if !hasText {
+		return nil, auto.Errorf(auto.ErrParam, "缺少 text 或 image_base64 参数")
+	}
+	if err := writeClipboard(textStr); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"copied": true, "content_type": input.ClipboardText}, nil
+}
+
+// decodeBase64Image 解码 base64 图像（纯 base64 或 data URL）
+func decodeBase64Image(s string) (image.Image, error) {
+	if strings.HasPrefix(s, "data:") {
+		if i := strings.Index(s, ","); i >= 0 {
+			s = s[i+1:]
+		}
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, auto.Errorf(auto.ErrParam, "image_base64 不是合法的 base64: %v", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, auto.Errorf(auto.ErrParam, "无法解析 image_base64 图像（支持 PNG、JPEG）: %v", err)
+	}
+	return img, nil
+}
+
+// executeAssertClipboard 断言剪贴板文字：match_mode 为 exact（默认）、contains 或 regex，ignore_case 忽略大小写（regex 可用 (?i)）
+// 不一致时返回 ASSERTION_FAILED，错误信息包含期望和实际文字在首个差异处的摘录
+func (e *Executor) executeAssertClipboard(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
+	expected, ok := payload["expected"].(string)
+	if !ok {
+		return nil, auto.Errorf(auto.ErrParam, "缺少 expected 参数")
+	}
+	mode := ClipboardMatchExact
+	if raw, ok := payload["match_mode"]; ok {
+		name, _ := raw.(string)
+		switch name = strings.ToLower(strings.TrimSpace(name)); name {
+		case ClipboardMatchExact, ClipboardMatchContains, ClipboardMatchRegex:
+			mode = name
+		default:
+			return nil, auto.Errorf(auto.ErrParam, "match_mode 必须是 exact、contains、regex 之一: %v", raw)
+		}
+	}
+	ignoreCase, _ := payload["ignore_case"].(bool)
+	var re *regexp.Regexp
+	if mode == ClipboardMatchRegex {
+		var err error
+		if re, err = regexp.Compile(expected); err != nil {
+			return nil, auto.Errorf(auto.ErrParam, "无效的正则表达式 %q: %v", expected, err)
+		}
+	}
+
+	contentType := currentClipboardType()
+	data := map[string]interface{}{
+		"content_type": contentType,
+		"expected":     expected,
+		"match_mode":   mode,
+	}
+	if contentType == input.ClipboardImage {
+		return data, auto.Errorf(ErrAssertionFailed, "断言失败: 剪贴板内容是图像，不是文字")
+	}
+	actual, err := readClipboard()
+	if err != nil {
+		return nil, err
+	}
+	data["actual"] = truncateString(actual, clipboardActualMaxBytes)
+	data["length"] = utf8.RuneCountInString(actual)
+
+	switch mode {
+	case ClipboardMatchExact:
+		want, got := strings.TrimSpace(expected), strings.TrimSpace(actual)
+		if ignoreCase && strings.EqualFold(want, got) || want == got {
+			break
+		}
+		offset := firstDiff(want, got, ignoreCase)
+		data["diff_offset"] = offset
+		return data, auto.Errorf(ErrAssertionFailed, "断言失败: 剪贴板内容与期望不一致（第 %d 个字符起不同）\n期望: %s\n实际: %s",
+			offset+1, excerpt(want, offset), excerpt(got, offset))
+	case ClipboardMatchContains:
+		haystack, needle := actual, expected
+		if ignoreCase {
+			haystack, needle = strings.ToLower(haystack), strings.ToLower(needle)
+		}
+		if !strings.Contains(haystack, needle) {
+			return data, auto.Errorf(ErrAssertionFailed, "断言失败: 剪贴板内容不包含 %q\n实际: %s", expected, excerpt(actual, 0))
+		}
+	case ClipboardMatchRegex:
+		if !re.MatchString(actual) {
+			return data, auto.Errorf(ErrAssertionFailed, "断言失败: 剪贴板内容不满足正则表达式 %q\n实际: %s", expected, excerpt(actual, 0))
+		}
+	}
+	data["asserted"] = true
+	return data, nil
+}
+
+// firstDiff 返回两个字符串第一个不同字符的位置（字符下标）
+func firstDiff(a, b string, ignoreCase bool) int {
+	ra, rb := []rune(a), []rune(b)
+	for i := 0; i < min(len(ra), len(rb)); i++ {
+		if ra[i] != rb[i] && !(ignoreCase && strings.EqualFold(string(ra[i]), string(rb[i]))) {
+			return i
+		}
+	}
+	return min(len(ra), len(rb))
+}
+
+// excerpt 截取 offset 前后各 clipboardExcerptRunes 个字符，以 Go 字符串字面量形式返回（换行等不可见字符转义），截断处以 … 表示
+func excerpt(s string, offset int) string {
+	r := []rune(s)
+	start := max(0, offset-clipboardExcerptRunes)
+	end := min(len(r), offset+clipboardExcerptRunes)
+	if start > end {
+		start = end
+	}
+	out := fmt.Sprintf("%q", string(r[start:end]))
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(r) {
+		out += "…"
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+)
+
+func TestClipboard(t *testing.T) {
+	origRead, origWrite, origReadImage, origWriteImage, origType := readClipboard, writeClipboard, readClipboardImage, writeClipboardImage, clipboardContentType
+	t.Cleanup(func() {
+		readClipboard, writeClipboard, readClipboardImage, writeClipboardImage, clipboardContentType = origRead, origWrite, origReadImage, origWriteImage, origType
+	})
+	// 模拟剪贴板：文字或图像
+	var clipText string
+	var clipImage image.Image
+	readClipboard = func() (string, error) { return clipText, nil }
+	writeClipboard = func(s string) error { clipText, clipImage = s, nil; return nil }
+	readClipboardImage = func() (image.Image, error) { return clipImage, nil }
+	writeClipboardImage = func(img image.Image) error { clipText, clipImage = "", img; return nil }
+	clipboardContentType = func() (string, error) {
+		if clipImage != nil {
+			return "image", nil
+		}
+		return "text", nil
+	}
+	e, recorder := newTestExecutor()
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 3)))
+	dataURL := "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+	res, err := e.runAction(ctx, TaskTypeSetClipboard, map[string]interface{}{"image_base64": dataURL})
+	if err != nil || clipImage == nil || clipImage.Bounds().Dx() != 4 || res.Data.(map[string]interface{})["content_type"] != "image" {
+		t.Fatalf("设置图像失败: %+v %v", res, err)
+	}
+	res, err = e.runAction(ctx, TaskTypeGetClipboard, nil)
+	if data, _ := res.Data.(map[string]interface{}); err != nil || data["content_type"] != "image" || data["height"] != 3 ||
+		!strings.HasPrefix(data["image_base64"].(string), "data:image/png;base64,") {
+		t.Fatalf("读取图像失败: %+v %v", res, err)
+	}
+	e.Execute("assert-image", TaskTypeAssertClipboard, `{"expected": "hello"}`)
+	if res := recorder.results("assert-image"); len(res) != 1 || res[0].FailureReason != pb.FailureReason_FAILURE_REASON_ASSERTION_FAILED {
+		t.Errorf("剪贴板为图像时文字断言应失败: %+v", res)
+	}
+
+	if _, err := e.runAction(ctx, TaskTypeSetClipboard, decodePayload(t, `{"text": "Order #123 已创建\n"}`)); err != nil || clipImage != nil {
+		t.Fatalf("设置文字失败: %v", err)
+	}
+	res, err = e.runAction(ctx, TaskTypeGetClipboard, nil)
+	if data, _ := res.Data.(map[string]interface{}); err != nil || data["content_type"] != "text" || data["text"] != "Order #123 已创建\n" {
+		t.Fatalf("读取文字失败: %+v %v", res, err)
+	}
+
+	for _, payload := range []string{
+		`{"expected": "Order #123 已创建"}`,
+		`{"expected": "order #123 已创建", "ignore_case": true}`,
+		`{"expected": "#123", "match_mode": "contains"}`,
+		`{"expected": "^Order #\\d+ ", "match_mode": "regex"}`,
+	} {
+		if _, err := e.runAction(ctx, TaskTypeAssertClipboard, decodePayload(t, payload)); err != nil {
+			t.Errorf("%s 应断言成功: %v", payload, err)
+		}
+	}
+
+	e.Execute("assert-diff", TaskTypeAssertClipboard, `{"expected": "Order #124 已创建"}`)
+	results := recorder.results("assert-diff")
+	if len(results) != 1 || results[0].FailureReason != pb.FailureReason_FAILURE_REASON_ASSERTION_FAILED {
+		t.Fatalf("内容不一致应为 ASSERTION_FAILED: %+v", results)
+	}
+	if msg := results[0].Message; !strings.Contains(msg, "第 10 个字符") || !strings.Contains(msg, `"Order #124 已创建"`) || !strings.Contains(msg, `"Order #123 已创建"`) {
+		t.Errorf("错误信息应包含差异位置和两侧摘录: %s", msg)
+	}
+	if !strings.Contains(results[0].ResultJson, `"diff_offset":9`) {
+		t.Errorf("结果应包含实际文字和差异位置: %s", results[0].ResultJson)
+	}
+	for _, payload := range []string{
+		`{"expected": "#999", "match_mode": "contains"}`,
+		`{"expected": "^\\d+$", "match_mode": "regex"}`,
+	} {
+		if _, err := e.runAction(ctx, TaskTypeAssertClipboard, decodePayload(t, payload)); !errors.Is(err, ErrAssertionFailed) {
+			t.Errorf("%s 应断言失败: %v", payload, err)
+		}
+	}
+
+	for _, tt := range []struct{ taskType, payload string }{
+		{TaskTypeAssertClipboard, `{}`},
+		{TaskTypeAssertClipboard, `{"expected": "a", "match_mode": "fuzzy"}`},
+		{TaskTypeAssertClipboard, `{"expected": "(", "match_mode": "regex"}`},
+		{TaskTypeSetClipboard, `{}`},
+		{TaskTypeSetClipboard, `{"text": "a", "image_base64": "AAAA"}`},
+		{TaskTypeSetClipboard, `{"image_base64": "not base64!"}`},
+		{TaskTypeSetClipboard, `{"image_base64": "AAAA"}`},
+	} {
+		if _, err := e.runAction(ctx, tt.taskType, decodePayload(t, tt.payload)); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("%s %s 应返回 ErrParam: %v", tt.taskType, tt.payload, err)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	long := strings.Repeat("a", 100) + "X" + strings.Repeat("b", 100)
+	got := excerpt(long, 100)
+	if !strings.HasPrefix(got, "…\"") || !strings.HasSuffix(got, "\"…") || !strings.Contains(got, "aX") {
+		t.Errorf("excerpt = %s", got)
+	}
+	if got := excerpt("a\nb", 0); got != `"a\nb"` {
+		t.Errorf("换行应转义: %s", got)
+	}
+	if got := firstDiff("abc", "abd", false); got != 2 {
+		t.Errorf("firstDiff = %d", got)
+	}
+	if got := firstDiff("ABC", "abc", true); got != 3 {
+		t.Errorf("忽略大小写时 firstDiff = %d", got)
+	}
+}
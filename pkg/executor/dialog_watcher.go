@@ -0,0 +1,337 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	autoimage "github.com/zoeyai/zoeyworker/pkg/auto/image"
+	"github.com/zoeyai/zoeyworker/pkg/auto/input"
+	"github.com/zoeyai/zoeyworker/pkg/auto/window"
+	"github.com/zoeyai/zoeyworker/pkg/grpc"
+)
+
+// 可替换的弹窗处理实现（便于测试）
+var (
+	dialogClick = func(x, y int) {
+		input.MoveTo(x, y)
+		input.Click("left")
+	}
+	closeWindow = window.CloseWindowByPID
+)
+
+// 弹窗的处理方式
+const (
+	DialogActionClick       = "click"        // 点击 button 定位到的按钮
+	DialogActionEscape      = "escape"       // 按 Esc
+	DialogActionCloseWindow = "close_window" // 关闭 app_name / window_title 匹配的窗口
+)
+
+// 弹窗巡检间隔：默认值及下限（每轮对每条规则截图查找一次，间隔过短会拖慢步骤）
+const (
+	defaultDialogInterval = 3 * time.Second
+	minDialogInterval     = 500 * time.Millisecond
+)
+
+// dialogLocator 弹窗或按钮的定位方式：image 或 text 二选一
+type dialogLocator struct {
+	image string
+	text  string
+}
+
+func parseDialogLocator(raw map[string]interface{}, name string) (dialogLocator, error) {
+	var l dialogLocator
+	l.image, _ = raw["image"].(string)
+	l.text, _ = raw["text"].(string)
+	if (l.image == "") == (l.text == "") {
+		return l, auto.Errorf(auto.ErrParam, "%s 必须指定 image 或 text 之一", name)
+	}
+	return l, nil
+}
+
+// find 在屏幕上查找一次（不等待），未找到时返回 nil
+func (l dialogLocator) find() (*BoundsInfo, string) {
+	if l.image != "" {
+		match := findImageOnce(l.image, nil)
+		if match == nil {
+			return nil, ""
+		}
+		region := autoimage.MatchRegion(match)
+		return &BoundsInfo{X: region.X, Y: region.Y, Width: region.Width, Height: region.Height}, ""
+	}
+	match, _, err := findText(l.text, []auto.Option{auto.WithTimeout(0)})
+	if err != nil || match == nil {
+		return nil, ""
+	}
+	if bounds := textBounds(match); bounds != nil {
+		return bounds, match.Text
+	}
+	return &BoundsInfo{X: match.Position.X, Y: match.Position.Y}, match.Text
+}
+
+func (l dialogLocator) String() string {
+	if l.image != "" {
+		return "图像"
+	}
+	return fmt.Sprintf("文字 %q", l.text)
+}
+
+// dialogRule 一条弹窗规则：定位到弹窗后按 action 处理
+type dialogRule struct {
+	name    string
+	locator dialogLocator
+	action  string
+	button  dialogLocator // action 为 click 时的按钮
+
+	appName, title, mode string // action 为 close_window 时的窗口条件
+}
+
+func parseDialogRule(raw interface{}, index int) (*dialogRule, error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, auto.Errorf(auto.ErrParam, "dialog_watcher.rules[%d] 必须是对象", index)
+	}
+	field := fmt.Sprintf("dialog_watcher.rules[%d]", index)
+	rule := &dialogRule{}
+	rule.name, _ = m["name"].(string)
+	if rule.name == "" {
+		rule.name = fmt.Sprintf("rule_%d", index+1)
+	}
+	var err error
+	if rule.locator, err = parseDialogLocator(m, field); err != nil {
+		return nil, err
+	}
+
+	rule.action, _ = m["action"].(string)
+	switch rule.action = strings.ToLower(strings.TrimSpace(rule.action)); rule.action {
+	case DialogActionClick:
+		button, ok := m["button"].(map[string]interface{})
+		if !ok {
+			return nil, auto.Errorf(auto.ErrParam, "%s 的 action 为 click 时必须指定 button", field)
+		}
+		if rule.button, err = parseDialogLocator(button, field+".button"); err != nil {
+			return nil, err
+		}
+	case DialogActionEscape:
+	case DialogActionCloseWindow:
+		if rule.appName, rule.title, rule.mode, err = parseWindowTarget(m); err != nil {
+			return nil, fmt.Errorf("%s: %w", field, err)
+		}
+	default:
+		return nil, auto.Errorf(auto.ErrParam, "%s 的 action 必须是 click、escape、close_window 之一: %v", field, m["action"])
+	}
+	return rule, nil
+}
+
+// dismiss 处理弹窗
+func (r *dialogRule) dismiss() error {
+	switch r.action {
+	case DialogActionClick:
+		bounds, _ := r.button.find()
+		if bounds == nil {
+			return fmt.Errorf("未找到按钮（%s）", r.button)
+		}
+		dialogClick(bounds.X+bounds.Width/2, bounds.Y+bounds.Height/2)
+	case DialogActionEscape:
+		keyTap("escape")
+	case DialogActionCloseWindow:
+		w, err := findMatchingWindow(r.appName, r.title, r.mode)
+		if err != nil {
+			return err
+		}
+		if w == nil {
+			return fmt.Errorf("未找到窗口 %s", describeWindow(r.appName, r.title))
+		}
+		closeWindow(w.PID)
+	}
+	return nil
+}
+
+// dialogWatcher 弹窗监视：批量任务执行期间在后台按间隔查找规则中的弹窗并处理，默认关闭
+// 步骤操作鼠标键盘期间持有 inputMu，巡检在该期间跳过，避免与步骤的点击、输入交错
+type dialogWatcher struct {
+	rules    []*dialogRule
+	interval time.Duration
+
+	inputMu sync.Mutex
+
+	mu        sync.Mutex
+	dismissed []grpc.WsDismissedDialog
+}
+
+// newDialogWatcher 解析 dialog_watcher 配置；未配置、enabled 为 false 或没有规则时返回 nil（不监视）
+//
+//	"dialog_watcher": {
+//	  "interval_ms": 3000,
+//	  "rules": [
+//	    {"name": "更新提示", "text": "发现新版本", "action": "click", "button": {"text": "以后再说"}},
+//	    {"name": "崩溃报告", "image": "crash.png", "action": "close_window", "window_title": "Problem Report"}
+//	  ]
+//	}
+func newDialogWatcher(payload map[string]interface{}) (*dialogWatcher, error) {
+	raw, ok := payload["dialog_watcher"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	cfg, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, auto.Errorf(auto.ErrParam, "dialog_watcher 必须是对象")
+	}
+	if enabled, ok := cfg["enabled"].(bool); ok && !enabled {
+		return nil, nil
+	}
+	w := &dialogWatcher{interval: defaultDialogInterval}
+	if raw, ok := cfg["interval_ms"]; ok {
+		v, ok := raw.(float64)
+		if !ok || time.Duration(v)*time.Millisecond < minDialogInterval {
+			return nil, auto.Errorf(auto.ErrParam, "dialog_watcher.interval_ms 必须是不小于 %d 的数: %v", minDialogInterval.Milliseconds(), raw)
+		}
+		w.interval = time.Duration(v) * time.Millisecond
+	}
+	rules, _ := cfg["rules"].([]interface{})
+	for i, raw := range rules {
+		rule, err := parseDialogRule(raw, i)
+		if err != nil {
+			return nil, err
+		}
+		w.rules = append(w.rules, rule)
+	}
+	if len(w.rules) == 0 {
+		return nil, nil
+	}
+	return w, nil
+}
+
+// start 在后台巡检直到 ctx 取消，返回的函数停止巡检并等待当前一轮结束；w 为 nil 时不做任何事
+func (w *dialogWatcher) start(ctx context.Context, taskID string) (stop func()) {
+	if w == nil {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	log("INFO", fmt.Sprintf("[Task:%s] 弹窗监视已启用，共 %d 条规则，间隔 %v", taskID, len(w.rules), w.interval))
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			// 步骤正在操作鼠标键盘时跳过本轮
+			if !w.inputMu.TryLock() {
+				continue
+			}
+			w.scan(ctx, taskID)
+			w.inputMu.Unlock()
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// scan 对每条规则查找一次弹窗，找到时处理并记录
+func (w *dialogWatcher) scan(ctx context.Context, taskID string) {
+	for _, rule := range w.rules {
+		if ctx.Err() != nil {
+			return
+		}
+		bounds, matched := rule.locator.find()
+		if bounds == nil {
+			continue
+		}
+		entry := grpc.WsDismissedDialog{
+			Rule:        rule.name,
+			Action:      rule.action,
+			X:           bounds.X,
+			Y:           bounds.Y,
+			Width:       bounds.Width,
+			Height:      bounds.Height,
+			MatchedText: matched,
+			Timestamp:   time.Now().UnixMilli(),
+		}
+		if err := rule.dismiss(); err != nil {
+			entry.Error = err.Error()
+			log("WARN", fmt.Sprintf("[Task:%s] 弹窗监视: 规则 %s 命中，处理失败（%s）: %v", taskID, rule.name, rule.action, err))
+		} else {
+			log("WARN", fmt.Sprintf("[Task:%s] 弹窗监视: 规则 %s 命中，已处理（%s）", taskID, rule.name, rule.action))
+		}
+		w.mu.Lock()
+		w.dismissed = append(w.dismissed, entry)
+		w.mu.Unlock()
+	}
+}
+
+// passiveTaskTypes 不操作鼠标键盘的任务类型，执行期间弹窗监视照常巡检（等待中的弹窗往往正是步骤超时的原因）
+// 其余任务类型（包括自定义动作和脚本）执行期间暂停巡检
+var passiveTaskTypes = map[string]bool{
+	TaskTypeScreenshot:           true,
+	TaskTypeWaitImage:            true,
+	TaskTypeWaitText:             true,
+	TaskTypeWaitTime:             true,
+	TaskTypeWaitForWindow:        true,
+	TaskTypeImageExists:          true,
+	TaskTypeTextExists:           true,
+	TaskTypeTextFindAll:          true,
+	TaskTypeAssertImage:          true,
+	TaskTypeAssertImageCount:     true,
+	TaskTypeAssertText:           true,
+	TaskTypeGetPixelColor:        true,
+	TaskTypeAssertPixelColor:     true,
+	TaskTypeGetClipboard:         true,
+	TaskTypeAssertClipboard:      true,
+	TaskTypeAssertWindowTitle:    true,
+	TaskTypeAssertProcessRunning: true,
+}
+
+// pause 执行操作鼠标键盘的步骤前暂停巡检（等待进行中的一轮结束），返回的函数恢复巡检；w 为 nil 时不做任何事
+func (w *dialogWatcher) pause(taskType string) (resume func()) {
+	if w == nil || passiveTaskTypes[taskType] {
+		return func() {}
+	}
+	w.inputMu.Lock()
+	return w.inputMu.Unlock
+}
+
+// drain 取出自上次调用以来处理的弹窗，caseID 非空时记录在每一项中
+func (w *dialogWatcher) drain(caseID string) []grpc.WsDismissedDialog {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	dismissed := w.dismissed
+	w.dismissed = nil
+	for i := range dismissed {
+		dismissed[i].CaseID = caseID
+	}
+	return dismissed
+}
+
+// withDismissedDialogs 在结果数据中附带处理的弹窗（没有时不附带）
+func withDismissedDialogs(data map[string]interface{}, dismissed []grpc.WsDismissedDialog) map[string]interface{} {
+	if len(dismissed) > 0 {
+		data["dismissed_dialogs"] = dismissed
+	}
+	return data
+}
+
+type dialogWatcherKey struct{}
+
+// withDialogWatcher 将弹窗监视放入 context，供步骤执行时暂停巡检
+func withDialogWatcher(ctx context.Context, w *dialogWatcher) context.Context {
+	return context.WithValue(ctx, dialogWatcherKey{}, w)
+}
+
+// dialogWatcherFromContext 从 context 中取出弹窗监视，未启用时返回 nil
+func dialogWatcherFromContext(ctx context.Context) *dialogWatcher {
+	w, _ := ctx.Value(dialogWatcherKey{}).(*dialogWatcher)
+	return w
+}
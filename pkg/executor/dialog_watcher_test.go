@@ -0,0 +1,91 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/grpc"
+	"github.com/zoeyai/zoeyworker/pkg/vision/cv"
+)
+
+func TestDialogWatcher(t *testing.T) {
+	for _, payload := range []string{`{}`, `{"dialog_watcher": {"enabled": false, "rules": [{"image": "a.png", "action": "escape"}]}}`, `{"dialog_watcher": {"rules": []}}`} {
+		if w, err := newDialogWatcher(decodePayload(t, payload)); w != nil || err != nil {
+			t.Errorf("%s 不应启用弹窗监视: %v %v", payload, w, err)
+		}
+	}
+	for _, payload := range []string{
+		`{"dialog_watcher": "on"}`,
+		`{"dialog_watcher": {"interval_ms": 100, "rules": [{"image": "a.png", "action": "escape"}]}}`,
+		`{"dialog_watcher": {"rules": [{"action": "escape"}]}}`,
+		`{"dialog_watcher": {"rules": [{"image": "a.png", "text": "更新", "action": "escape"}]}}`,
+		`{"dialog_watcher": {"rules": [{"image": "a.png", "action": "click"}]}}`,
+		`{"dialog_watcher": {"rules": [{"image": "a.png", "action": "close_window"}]}}`,
+		`{"dialog_watcher": {"rules": [{"image": "a.png", "action": "ignore"}]}}`,
+	} {
+		if _, err := newDialogWatcher(decodePayload(t, payload)); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("%s 应返回 ErrParam: %v", payload, err)
+		}
+	}
+
+	origLocate, origClick := locateImage, dialogClick
+	t.Cleanup(func() { locateImage, dialogClick = origLocate, origClick })
+	var popup atomic.Bool
+	var clicks atomic.Int32
+	locateImage = func(source string, opts ...auto.Option) (*cv.MatchResult, error) {
+		if !popup.Load() {
+			return nil, auto.Errorf(auto.ErrTimeout, "未找到")
+		}
+		return &cv.MatchResult{
+			Result:     cv.Point{X: 110, Y: 220},
+			Rectangle:  cv.Rectangle{TopLeft: cv.Point{X: 100, Y: 210}, TopRight: cv.Point{X: 120, Y: 210}, BottomLeft: cv.Point{X: 100, Y: 230}, BottomRight: cv.Point{X: 120, Y: 230}},
+			Confidence: 0.93,
+		}, nil
+	}
+	dialogClick = func(x, y int) {
+		if x == 110 && y == 220 {
+			clicks.Add(1)
+			popup.Store(false)
+		}
+	}
+	RegisterAction("test_busy", func(ctx context.Context, payload map[string]interface{}) (*ActionResult, error) {
+		time.Sleep(800 * time.Millisecond)
+		return &ActionResult{}, nil
+	})
+	t.Cleanup(func() {
+		actionsMu.Lock()
+		delete(actions, "test_busy")
+		actionsMu.Unlock()
+	})
+
+	// 操作鼠标键盘的步骤执行期间不巡检
+	e, recorder := newTestExecutor()
+	const watcher = `"dialog_watcher": {"interval_ms": 500, "rules": [{"name": "更新提示", "image": "update.png", "action": "click", "button": {"image": "later.png"}}]}`
+	popup.Store(true)
+	e.Execute("busy", TaskTypeExecuteCase, `{"screenshot_mode": "never", `+watcher+`, "steps": [{"step_id": "s1", "task_type": "test_busy", "params": {}}]}`)
+	if res := recorder.results("busy"); len(res) != 1 || !res[0].Success || clicks.Load() != 0 || strings.Contains(res[0].ResultJson, "dismissed_dialogs") {
+		t.Fatalf("步骤操作期间不应处理弹窗: clicks=%d %+v", clicks.Load(), res)
+	}
+
+	// 等待类步骤期间处理弹窗，并记录在用例结果中
+	e.Execute("wait", TaskTypeExecuteCase, `{"screenshot_mode": "never", `+watcher+`, "steps": [{"step_id": "s1", "task_type": "wait_time", "params": {"duration": 800}}]}`)
+	res := recorder.results("wait")
+	if len(res) != 1 || !res[0].Success || clicks.Load() != 1 {
+		t.Fatalf("应处理一次弹窗: clicks=%d %+v", clicks.Load(), res)
+	}
+	var data struct {
+		DismissedDialogs []grpc.WsDismissedDialog `json:"dismissed_dialogs"`
+	}
+	if err := json.Unmarshal([]byte(res[0].ResultJson), &data); err != nil || len(data.DismissedDialogs) != 1 {
+		t.Fatalf("结果应附带处理的弹窗: %s %v", res[0].ResultJson, err)
+	}
+	if d := data.DismissedDialogs[0]; d.Rule != "更新提示" || d.Action != DialogActionClick || d.X != 100 || d.Width != 20 || d.Error != "" {
+		t.Errorf("弹窗记录: %+v", d)
+	}
+}
@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+)
+
+// currentDisplay 查询主显示器分辨率和缩放比例（可替换，便于测试）
+var currentDisplay = screen.CurrentDisplay
+
+// ErrResolutionChanged 执行期间屏幕分辨率或缩放比例发生变化（fail_on_resolution_change 为 true 时步骤失败）
+var ErrResolutionChanged = errors.New("屏幕分辨率已变化")
+
+// displayWatch 用例执行期间监视分辨率和缩放比例：任务开始时记录，每个步骤执行前重新检查
+type displayWatch struct {
+	current      screen.Display
+	failOnChange bool // fail_on_resolution_change：变化时当前步骤失败并结束用例
+	tripped      bool // 最近一次检查发现变化且 failOnChange 为 true
+}
+
+// newDisplayWatch 记录任务开始时的分辨率
+func newDisplayWatch(payload map[string]interface{}) *displayWatch {
+	failOnChange, _ := payload["fail_on_resolution_change"].(bool)
+	return &displayWatch{current: currentDisplay(), failOnChange: failOnChange}
+}
+
+// displayChange 两次检查之间的分辨率变化
+type displayChange struct {
+	previous, current screen.Display
+}
+
+func (c *displayChange) err() error {
+	return fmt.Errorf("%w（%s → %s），绝对坐标和缓存的区域可能已失效", ErrResolutionChanged, formatDisplay(c.previous), formatDisplay(c.current))
+}
+
+// apply 在步骤结果中记录变化前后的分辨率
+func (c *displayChange) apply(result *StepExecutionResult) {
+	result.ResolutionChanged = true
+	result.PreviousResolution = newResolutionInfo(c.previous)
+	result.Resolution = newResolutionInfo(c.current)
+}
+
+// ResolutionInfo 分辨率信息（截图像素）及缩放比例
+type ResolutionInfo struct {
+	Width       int     `json:"width"`
+	Height      int     `json:"height"`
+	ScaleFactor float64 `json:"scaleFactor"`
+}
+
+func newResolutionInfo(d screen.Display) *ResolutionInfo {
+	return &ResolutionInfo{Width: d.Width, Height: d.Height, ScaleFactor: d.ScaleFactor}
+}
+
+func formatDisplay(d screen.Display) string {
+	return fmt.Sprintf("%dx%d@%.2fx", d.Width, d.Height, d.ScaleFactor)
+}
+
+// check 步骤执行前检查分辨率，变化时输出 WARN 日志并以新的分辨率为准；w 为 nil 时不检查
+func (w *displayWatch) check() *displayChange {
+	if w == nil {
+		return nil
+	}
+	d := currentDisplay()
+	if d == w.current {
+		w.tripped = false
+		return nil
+	}
+	change := &displayChange{previous: w.current, current: d}
+	w.current = d
+	w.tripped = w.failOnChange
+	log("WARN", fmt.Sprintf("屏幕分辨率已变化: %s → %s", formatDisplay(change.previous), formatDisplay(change.current)))
+	return change
+}
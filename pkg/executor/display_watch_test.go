@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+)
+
+func TestResolutionChange(t *testing.T) {
+	origDisplay := currentDisplay
+	t.Cleanup(func() { currentDisplay = origDisplay })
+	display := screen.Display{Width: 2560, Height: 1600, ScaleFactor: 2}
+	currentDisplay = func() screen.Display { return display }
+
+	e, recorder := newTestExecutor()
+	wait := decodePayload(t, `{"duration": 1}`)
+	never := screenshotOptions{Mode: ScreenshotModeNever}
+	watch := newDisplayWatch(map[string]interface{}{})
+	if step := e.executeStepWithScreenshots(context.Background(), "", "s1", TaskTypeWaitTime, wait, never, watch); step.ResolutionChanged || step.Resolution != nil {
+		t.Errorf("分辨率未变化时不应标记: %+v", step)
+	}
+
+	display = screen.Display{Width: 1920, Height: 1080, ScaleFactor: 1}
+	step := e.executeStepWithScreenshots(context.Background(), "", "s2", TaskTypeWaitTime, wait, never, watch)
+	if step.Status != "SUCCESS" || !step.ResolutionChanged {
+		t.Fatalf("分辨率变化后步骤应照常执行并标记: %+v", step)
+	}
+	if *step.PreviousResolution != (ResolutionInfo{Width: 2560, Height: 1600, ScaleFactor: 2}) || *step.Resolution != (ResolutionInfo{Width: 1920, Height: 1080, ScaleFactor: 1}) {
+		t.Errorf("变化前后的分辨率错误: %+v → %+v", step.PreviousResolution, step.Resolution)
+	}
+	if step := e.executeStepWithScreenshots(context.Background(), "", "s3", TaskTypeWaitTime, wait, never, watch); step.ResolutionChanged {
+		t.Error("只有变化后的第一个步骤应标记")
+	}
+
+	// fail_on_resolution_change：步骤不执行并结束用例（即使 stop_on_fail 为 false）
+	var ran atomic.Int32
+	RegisterAction("test_undock", func(ctx context.Context, payload map[string]interface{}) (*ActionResult, error) {
+		ran.Add(1)
+		display = screen.Display{Width: 1366, Height: 768, ScaleFactor: 1}
+		return &ActionResult{}, nil
+	})
+	t.Cleanup(func() {
+		actionsMu.Lock()
+		delete(actions, "test_undock")
+		actionsMu.Unlock()
+	})
+	e.Execute("case-1", TaskTypeExecuteCase, `{"screenshot_mode": "never", "stop_on_fail": false, "fail_on_resolution_change": true, "steps": [
+		{"step_id": "s1", "task_type": "test_undock", "params": {}},
+		{"step_id": "s2", "task_type": "test_undock", "params": {}},
+		{"step_id": "s3", "task_type": "test_undock", "params": {}}
+	]}`)
+	if ran.Load() != 1 {
+		t.Errorf("分辨率变化后不应继续执行步骤, 实际执行 %d 次", ran.Load())
+	}
+	results := recorder.results("case-1")
+	if len(results) != 1 || results[0].Success || !strings.Contains(results[0].Message, "屏幕分辨率已变化") || !strings.Contains(results[0].Message, "1920x1080@1.00x → 1366x768@1.00x") {
+		t.Fatalf("用例应因分辨率变化失败: %+v", results)
+	}
+}
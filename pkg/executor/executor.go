@@ -1,41 +1,68 @@
 package executor
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"runtime/debug"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
+	"github.com/zoeyai/zoeyworker/internal/logger"
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
 	"github.com/zoeyai/zoeyworker/pkg/grpc"
 	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+	"github.com/zoeyai/zoeyworker/pkg/history"
+	"github.com/zoeyai/zoeyworker/pkg/vision/cv"
 )
 
 // ==================== 任务类型常量 ====================
 
 // TaskType 任务类型
 const (
-	TaskTypeClickImage   = "click_image"
-	TaskTypeClickText    = "click_text"
-	TaskTypeClickNative  = "click_native"
-	TaskTypeTypeText     = "type_text"
-	TaskTypeKeyPress     = "key_press"
-	TaskTypeScreenshot   = "screenshot"
-	TaskTypeWaitImage    = "wait_image"
-	TaskTypeWaitText     = "wait_text"
-	TaskTypeWaitTime     = "wait_time"
-	TaskTypeMouseMove    = "mouse_move"
-	TaskTypeMouseClick   = "mouse_click"
-	TaskTypeActivateApp  = "activate_app"
-	TaskTypeCloseApp     = "close_app"
-	TaskTypeGridClick    = "grid_click"
-	TaskTypeImageExists  = "image_exists"
-	TaskTypeTextExists   = "text_exists"
-	TaskTypeAssertImage  = "assert_image"
-	TaskTypeAssertText   = "assert_text"
-	TaskTypeGetClipboard = "get_clipboard"
-	TaskTypeSetClipboard = "set_clipboard"
-	TaskTypeRunPython    = "run_python"
+	TaskTypeClickImage           = "click_image"
+	TaskTypeClickText            = "click_text"
+	TaskTypeClickNative          = "click_native"
+	TaskTypeTypeText             = "type_text"
+	TaskTypeKeyPress             = "key_press"
+	TaskTypeKeyDown              = "key_down"
+	TaskTypeKeyUp                = "key_up"
+	TaskTypeScreenshot           = "screenshot"
+	TaskTypeWaitImage            = "wait_image"
+	TaskTypeWaitText             = "wait_text"
+	TaskTypeWaitTime             = "wait_time"
+	TaskTypeMouseMove            = "mouse_move"
+	TaskTypeMouseClick           = "mouse_click"
+	TaskTypeActivateApp          = "activate_app"
+	TaskTypeCloseApp             = "close_app"
+	TaskTypeOpenURL              = "open_url"
+	TaskTypeGridClick            = "grid_click"
+	TaskTypeImageExists          = "image_exists"
+	TaskTypeTextExists           = "text_exists"
+	TaskTypeTextFindAll          = "text_find_all"
+	TaskTypeAssertImage          = "assert_image"
+	TaskTypeAssertImageCount     = "assert_image_count"
+	TaskTypeAssertText           = "assert_text"
+	TaskTypeGetPixelColor        = "get_pixel_color"
+	TaskTypeAssertPixelColor     = "assert_pixel_color"
+	TaskTypeGetClipboard         = "get_clipboard"
+	TaskTypeSetClipboard         = "set_clipboard"
+	TaskTypeAssertClipboard      = "assert_clipboard"
+	TaskTypeWaitForWindow        = "wait_for_window"
+	TaskTypeSetWindowBounds      = "set_window_bounds"
+	TaskTypeMinimizeWindow       = "minimize_window"
+	TaskTypeMaximizeWindow       = "maximize_window"
+	TaskTypeAssertWindowTitle    = "assert_window_title"
+	TaskTypeAssertProcessRunning = "assert_process_running"
+	TaskTypeKillProcessByPID     = "kill_process_by_pid"
+	TaskTypeWaitForHotkey        = "wait_for_hotkey"
+	TaskTypeRunPython            = "run_python"
 	// AI 动作类型（归一化坐标 + 自动截屏返回）
 	// TaskTypeAIAction 定义在 executor_ai.go 中
 	// 批量执行类型
@@ -115,13 +142,49 @@ func newTaskError(status pb.TaskStatus, reason pb.FailureReason, message string)
 	return &TaskError{Status: status, Reason: reason, Message: message}
 }
 
+// ErrAssertionFailed 断言失败
+var ErrAssertionFailed = errors.New("assertion failed")
+
 // classifyError 对错误进行分类
+// 优先通过 errors.Is/As 识别类型化错误，未知错误再回退到消息关键字匹配
 func classifyError(err error) *TaskError {
 	if err == nil {
 		return nil
 	}
 
+	var taskErr *TaskError
+	if errors.As(err, &taskErr) {
+		return taskErr
+	}
+
 	errStr := err.Error()
+	switch {
+	// 模板下载错误优先判断：404 不是匹配失败，网络超时也不是等待超时
+	case errors.Is(err, cv.ErrTemplateNotFound):
+		return newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_PARAM_ERROR, errStr)
+	case errors.Is(err, cv.ErrTemplateDownload):
+		return newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR, errStr)
+	case errors.Is(err, context.Canceled):
+		return newTaskError(pb.TaskStatus_TASK_STATUS_CANCELLED, pb.FailureReason_FAILURE_REASON_UNSPECIFIED, errStr)
+	case errors.Is(err, auto.ErrTimeout), errors.Is(err, cv.ErrMatchTimeout), errors.Is(err, context.DeadlineExceeded):
+		return newTaskError(pb.TaskStatus_TASK_STATUS_TIMEOUT, pb.FailureReason_FAILURE_REASON_UNSPECIFIED, errStr)
+	case errors.Is(err, ErrScreenLocked), errors.Is(err, ErrResolutionChanged), errors.Is(err, auto.ErrSystem):
+		return newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR, errStr)
+	case errors.Is(err, ErrAssertionFailed):
+		return newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_ASSERTION_FAILED, errStr)
+	case errors.Is(err, auto.ErrNotFound):
+		return newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_NOT_FOUND, errStr)
+	case errors.Is(err, cv.ErrMultipleMatches):
+		return newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_MULTIPLE_MATCHES, errStr)
+	case errors.Is(err, auto.ErrParam):
+		return newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_PARAM_ERROR, errStr)
+	}
+
+	return classifyErrorMessage(errStr)
+}
+
+// classifyErrorMessage 根据错误消息关键字分类（兼容未类型化的错误）
+func classifyErrorMessage(errStr string) *TaskError {
 	errLower := strings.ToLower(errStr)
 
 	// 超时单独作为状态
@@ -149,44 +212,6 @@ func classifyError(err error) *TaskError {
 	return newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, reason, errStr)
 }
 
-// StepExecutionResult 步骤执行结果（用于前端回放）
-type StepExecutionResult struct {
-	StepExecutionID string `json:"stepExecutionId,omitempty"` // 步骤执行记录 ID
-	StepID          string `json:"stepId"`                    // 步骤 ID
-	Status          string `json:"status"`                    // SUCCESS, FAILED, SKIPPED
-
-	// 截图（Base64 格式）
-	ScreenshotBefore string `json:"screenshotBefore,omitempty"` // 执行前截图
-	ScreenshotAfter  string `json:"screenshotAfter,omitempty"`  // 执行后截图
-
-	// 操作信息
-	ActionType string `json:"actionType"` // click, long_press, double_click, input, swipe, assert, wait
-
-	// 目标元素边框（用于回放时高亮显示）
-	TargetBounds *BoundsInfo `json:"targetBounds,omitempty"`
-
-	// 实际点击位置（用于回放时显示点击动画）
-	ClickPosition *PositionInfo `json:"clickPosition,omitempty"`
-
-	// 滑动轨迹（仅 swipe 操作）
-	SwipePath *SwipePathInfo `json:"swipePath,omitempty"`
-
-	// 输入内容（仅 input 操作）
-	InputText string `json:"inputText,omitempty"`
-
-	// 脚本执行输出（仅 script/run_python 操作）
-	Stdout   string `json:"stdout,omitempty"`   // 标准输出
-	Stderr   string `json:"stderr,omitempty"`   // 标准错误
-	ExitCode int    `json:"exitCode,omitempty"` // 退出码
-
-	// 执行耗时（毫秒）
-	DurationMs int64 `json:"durationMs"`
-
-	// 错误信息（仅失败时）
-	ErrorMessage  string `json:"errorMessage,omitempty"`
-	FailureReason string `json:"failureReason,omitempty"` // NOT_FOUND, MULTIPLE_MATCHES, ASSERTION_FAILED, PARAM_ERROR, SYSTEM_ERROR
-}
-
 // BoundsInfo 边界信息
 type BoundsInfo struct {
 	X      int `json:"x"`
@@ -201,14 +226,6 @@ type PositionInfo struct {
 	Y int `json:"y"`
 }
 
-// SwipePathInfo 滑动轨迹信息
-type SwipePathInfo struct {
-	StartX int `json:"startX"`
-	StartY int `json:"startY"`
-	EndX   int `json:"endX"`
-	EndY   int `json:"endY"`
-}
-
 // ActionResult 操作执行结果（各执行函数返回）
 type ActionResult struct {
 	Success       bool          // 是否成功
@@ -216,73 +233,26 @@ type ActionResult struct {
 	Data          interface{}   // 原始返回数据
 	ClickPosition *PositionInfo // 点击位置
 	TargetBounds  *BoundsInfo   // 目标边界
+	Confidence    float64       // 匹配置信度
+	Scale         float64       // 命中时的模板缩放比例（仅图像匹配）
+	SearchRegion  *BoundsInfo   // 限定的搜索区域（文字查找，或指定了 relative_to 的查找；全屏时为 nil）
+	AnchorBounds  *BoundsInfo   // relative_to 锚点的边界
+	MatchedText   string        // 实际命中的识别文字（仅文字查找）
 	InputText     string        // 输入的文本
 }
 
-// CaseExecutionResult 用例执行结果
-type CaseExecutionResult struct {
-	Success      bool
-	ErrorMessage string
-	TotalSteps   int
-	PassedSteps  int
-	FailedSteps  int
-}
-
-// ==================== 映射函数 ====================
-
-// mapTaskTypeToActionType 将任务类型映射为操作类型
-func mapTaskTypeToActionType(taskType string) string {
-	switch taskType {
-	case TaskTypeClickImage, TaskTypeClickText, TaskTypeClickNative, TaskTypeMouseClick, TaskTypeGridClick:
-		return "click"
-	case TaskTypeTypeText:
-		return "input"
-	case TaskTypeKeyPress:
-		return "input"
-	case TaskTypeWaitImage, TaskTypeWaitText, TaskTypeWaitTime:
-		return "wait"
-	case TaskTypeAssertImage, TaskTypeAssertText, TaskTypeImageExists, TaskTypeTextExists:
-		return "assert"
-	case TaskTypeRunPython:
-		return "script"
-	default:
-		return "other"
-	}
-}
-
-// mapFailureReasonToString 将失败原因枚举映射为字符串
-func mapFailureReasonToString(reason pb.FailureReason) string {
-	switch reason {
-	case pb.FailureReason_FAILURE_REASON_NOT_FOUND:
-		return "NOT_FOUND"
-	case pb.FailureReason_FAILURE_REASON_MULTIPLE_MATCHES:
-		return "MULTIPLE_MATCHES"
-	case pb.FailureReason_FAILURE_REASON_ASSERTION_FAILED:
-		return "ASSERTION_FAILED"
-	case pb.FailureReason_FAILURE_REASON_PARAM_ERROR:
-		return "PARAM_ERROR"
-	case pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR:
-		return "SYSTEM_ERROR"
-	default:
-		return ""
-	}
-}
-
-// mapTaskStatusToString 将任务状态枚举映射为字符串
-func mapTaskStatusToString(status pb.TaskStatus) string {
-	switch status {
-	case pb.TaskStatus_TASK_STATUS_SUCCESS:
-		return "SUCCESS"
-	case pb.TaskStatus_TASK_STATUS_FAILED:
-		return "FAILED"
-	case pb.TaskStatus_TASK_STATUS_SKIPPED:
-		return "SKIPPED"
-	case pb.TaskStatus_TASK_STATUS_CANCELLED:
-		return "CANCELLED"
-	case pb.TaskStatus_TASK_STATUS_TIMEOUT:
-		return "FAILED" // 超时也算失败
-	default:
-		return "UNKNOWN"
+// matchLocation 动作结果的 MatchLocation：目标边界的左上角（X、Y）和宽高，单步任务和批量步骤含义相同；
+// 没有目标边界（如 mouse_click）时返回 nil
+func matchLocation(result *ActionResult) *pb.MatchLocation {
+	if result == nil || result.TargetBounds == nil {
+		return nil
+	}
+	return &pb.MatchLocation{
+		X:          int32(result.TargetBounds.X),
+		Y:          int32(result.TargetBounds.Y),
+		Width:      int32(result.TargetBounds.Width),
+		Height:     int32(result.TargetBounds.Height),
+		Confidence: float32(result.Confidence),
 	}
 }
 
@@ -299,12 +269,15 @@ func SetLogFunc(fn LogFunc) {
 	globalLogFunc = fn
 }
 
-// log 输出日志
+// log 输出日志，低于全局日志级别（logger.SetLevel）的日志直接丢弃
 func log(level, message string) {
+	if !logger.Enabled(logger.ParseLevel(level)) {
+		return
+	}
 	if globalLogFunc != nil {
 		globalLogFunc(level, message)
 	} else {
-		fmt.Printf("[%s] %s\n", level, message)
+		logger.Log(logger.ParseLevel(level), message)
 	}
 }
 
@@ -316,61 +289,248 @@ type TaskInfo struct {
 	TaskType  string
 	StartedAt int64
 	CancelCh  chan struct{}
+	Cancelled bool // 已调用 CancelTask（CancelCh 已关闭），任务结束前仍在运行列表中
+
+	// 最近一次进度上报（批量任务）
+	CurrentStep    string
+	TotalSteps     int32
+	CompletedSteps int32
+
+	run        *history.Run    // 执行历史（设置了 SetHistory 时），结束时保存
+	checkpoint *taskCheckpoint // 批量任务断点（设置了 SetCheckpointDir 时），结束时删除
 }
 
 // Executor 任务执行器
 type Executor struct {
-	client       *grpc.Client
-	runningTasks map[string]*TaskInfo // 运行中的任务信息
-	tasksMutex   sync.Mutex
+	client         *grpc.Client
+	send           func(msg *pb.WorkerMessage) // 发送消息到服务端（client 为空时为 nil）
+	notifyOperator func(title, message string) // 提示操作员（GUI 系统通知），见 SetOperatorNotifier
+	runningTasks   map[string]*TaskInfo        // 运行中的任务信息
+	completedTasks *completedTaskCache         // 最近完成的任务及其最终结果
+	tasksMutex     sync.Mutex
+
+	screenshotMaxWidth int // 步骤截图默认最大宽度（<= 0 不缩放）
+	screenshotQuality  int // 步骤截图默认 JPEG 质量（1-100）
+
+	history   history.Store // 本地执行历史（为 nil 时不记录）
+	reportDir string        // execute_plan 测试报告根目录（为空时使用默认目录），见 SetReportDir
+
+	checkpointDir string            // 批量任务断点目录（为空时不记录），见 SetCheckpointDir
+	interrupted   []*taskCheckpoint // 上次运行残留的断点，见 ReportInterruptedTasks
+
+	tasksExecuted atomic.Int64 // 执行完成（发送最终结果）的任务数，见 Stats
+	tasksFailed   atomic.Int64 // 其中结果不是成功的任务数
+
+	warmupOnce sync.Once     // OCR 预热只执行一次
+	warmupDone chan struct{} // OCR 预热结束时关闭
+
+	awake       awakeGuard  // 有任务运行期间阻止系统休眠和锁屏
+	blankScreen atomic.Bool // 最近一次步骤前检查到截图全黑，见 ScreenLocked
+	heldKeys    heldKeys    // key_down 按住、尚未 key_up 的键
 }
 
 // NewExecutor 创建任务执行器
 func NewExecutor(client *grpc.Client) *Executor {
-	return &Executor{
-		client:       client,
-		runningTasks: make(map[string]*TaskInfo),
+	e := &Executor{
+		client:         client,
+		runningTasks:   make(map[string]*TaskInfo),
+		completedTasks: newCompletedTaskCache(DefaultCompletedTaskCacheSize),
+
+		screenshotMaxWidth: screen.DefaultScreenshotMaxWidth,
+		screenshotQuality:  DefaultScreenshotQuality,
+	}
+	if client != nil {
+		e.send = client.SendTaskMessage
+	}
+	return e
+}
+
+// SetMessageSink 替换发往服务端的消息出口（本地运行、测试时收集结果），fn 为 nil 时丢弃所有消息
+func (e *Executor) SetMessageSink(fn func(msg *pb.WorkerMessage)) {
+	e.send = fn
+}
+
+// SetScreenshotMaxWidth 设置步骤截图默认最大宽度（<= 0 表示不缩放）
+// 任务 payload 中的 screenshot_max_width 优先
+func (e *Executor) SetScreenshotMaxWidth(width int) {
+	e.screenshotMaxWidth = width
+}
+
+// SetKeepAwake 开启或关闭任务执行期间阻止系统休眠和锁屏（默认开启）
+func (e *Executor) SetKeepAwake(enabled bool) {
+	e.awake.setEnabled(enabled)
+}
+
+// DefaultScreenshotQuality 步骤截图默认 JPEG 质量（较低的质量以减小传输量）
+const DefaultScreenshotQuality = 60
+
+// SetScreenshotQuality 设置步骤截图默认 JPEG 质量（1-100，超出范围时使用 DefaultScreenshotQuality）
+// 任务 payload 中的 screenshot_quality 优先
+func (e *Executor) SetScreenshotQuality(quality int) {
+	if quality <= 0 || quality > 100 {
+		quality = DefaultScreenshotQuality
 	}
+	e.screenshotQuality = quality
 }
 
-// CancelTask 取消任务
+// CancelTask 取消任务：单步任务和批量任务当前步骤的 ctx 随之取消，批量任务不再执行后续步骤并上报 CANCELLED；
+// 任务真正结束前仍在运行列表中。任务不存在或已取消时返回 false
 func (e *Executor) CancelTask(taskID string) bool {
 	e.tasksMutex.Lock()
 	defer e.tasksMutex.Unlock()
 
-	if taskInfo, exists := e.runningTasks[taskID]; exists {
-		close(taskInfo.CancelCh)
-		delete(e.runningTasks, taskID)
-		return true
+	taskInfo, exists := e.runningTasks[taskID]
+	if !exists || taskInfo.Cancelled {
+		return false
 	}
-	return false
+	taskInfo.Cancelled = true
+	close(taskInfo.CancelCh)
+	return true
 }
 
-// registerTask 注册运行中的任务
-func (e *Executor) registerTask(taskID, taskType string) chan struct{} {
+// taskContext 返回任务被取消（CancelTask）时随之取消的 ctx，任务未注册时只在调用 cancel 后取消
+func (e *Executor) taskContext(taskID string) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.tasksMutex.Lock()
+	info := e.runningTasks[taskID]
+	e.tasksMutex.Unlock()
+	if info != nil {
+		go func() {
+			select {
+			case <-info.CancelCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+	return ctx, cancel
+}
+
+// RunningTask 运行中任务的快照
+type RunningTask struct {
+	TaskID         string
+	TaskType       string
+	StartedAt      int64  // 开始时间（毫秒时间戳）
+	CurrentStep    string // 最近一次进度上报的当前步骤（批量任务），单步任务为空
+	TotalSteps     int32
+	CompletedSteps int32
+	Cancelled      bool // 已请求取消，等待当前步骤结束
+}
+
+// RunningTasks 返回运行中的任务（按开始时间排序）
+func (e *Executor) RunningTasks() []RunningTask {
 	e.tasksMutex.Lock()
 	defer e.tasksMutex.Unlock()
 
+	tasks := make([]RunningTask, 0, len(e.runningTasks))
+	for _, info := range e.runningTasks {
+		tasks = append(tasks, RunningTask{
+			TaskID:         info.TaskID,
+			TaskType:       info.TaskType,
+			StartedAt:      info.StartedAt,
+			CurrentStep:    info.CurrentStep,
+			TotalSteps:     info.TotalSteps,
+			CompletedSteps: info.CompletedSteps,
+			Cancelled:      info.Cancelled,
+		})
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].StartedAt != tasks[j].StartedAt {
+			return tasks[i].StartedAt < tasks[j].StartedAt
+		}
+		return tasks[i].TaskID < tasks[j].TaskID
+	})
+	return tasks
+}
+
+// recordProgress 记录任务最近一次进度（RunningTasks 返回），批量任务同时写入断点
+func (e *Executor) recordProgress(taskID string, totalSteps, completedSteps, passedSteps, failedSteps int32, currentStepName string) {
+	e.tasksMutex.Lock()
+	info, ok := e.runningTasks[taskID]
+	if ok {
+		info.CurrentStep = currentStepName
+		info.TotalSteps = totalSteps
+		info.CompletedSteps = completedSteps
+		if cp := info.checkpoint; cp != nil {
+			cp.StepIndex, cp.CurrentStep, cp.TotalSteps = completedSteps, currentStepName, totalSteps
+			cp.PassedSteps, cp.FailedSteps = passedSteps, failedSteps
+		}
+	}
+	e.tasksMutex.Unlock()
+
+	if ok {
+		e.saveCheckpoint(taskID)
+	}
+}
+
+// registerTask 注册运行中的任务
+// 如果同一 taskID 正在运行或最近已完成，则不重复注册并返回 false
+func (e *Executor) registerTask(taskID, taskType string) (chan struct{}, bool) {
+	e.tasksMutex.Lock()
+	cancelCh, ok := e.registerTaskLocked(taskID, taskType)
+	e.tasksMutex.Unlock()
+	if ok {
+		e.awake.taskStarted()
+		e.saveCheckpoint(taskID)
+	}
+	return cancelCh, ok
+}
+
+// registerTaskLocked 同 registerTask，调用方持有 tasksMutex
+func (e *Executor) registerTaskLocked(taskID, taskType string) (chan struct{}, bool) {
+	if _, running := e.runningTasks[taskID]; running {
+		return nil, false
+	}
+	if _, completed := e.completedTasks.get(taskID); completed {
+		return nil, false
+	}
+
 	cancelCh := make(chan struct{})
-	e.runningTasks[taskID] = &TaskInfo{
+	info := &TaskInfo{
 		TaskID:    taskID,
 		TaskType:  taskType,
 		StartedAt: time.Now().UnixMilli(),
 		CancelCh:  cancelCh,
 	}
-	return cancelCh
+	if e.history != nil {
+		info.run = &history.Run{ID: taskID, TaskType: taskType, StartedAt: info.StartedAt}
+	}
+	info.checkpoint = e.newCheckpoint(info)
+	e.runningTasks[taskID] = info
+	return cancelCh, true
+}
+
+// handleDuplicateTask 处理重复下发的任务
+// 正在运行的任务只回复确认；已完成的任务重发缓存的最终结果，不会重新执行
+func (e *Executor) handleDuplicateTask(taskID string) {
+	if result, ok := e.completedTasks.get(taskID); ok {
+		log("WARN", fmt.Sprintf("[Task:%s] 重复下发的任务已完成，重发缓存结果", taskID))
+		e.sendTaskAck(taskID, true, "duplicate, already completed")
+		e.resendTaskResult(result)
+		return
+	}
+
+	log("WARN", fmt.Sprintf("[Task:%s] 重复下发的任务正在运行，忽略", taskID))
+	e.sendTaskAck(taskID, true, "duplicate, already running")
 }
 
 // unregisterTask 注销任务
 func (e *Executor) unregisterTask(taskID string) {
 	e.tasksMutex.Lock()
-	defer e.tasksMutex.Unlock()
-
+	info, running := e.runningTasks[taskID]
 	delete(e.runningTasks, taskID)
+	e.tasksMutex.Unlock()
+
+	if running {
+		removeCheckpoint(info.checkpoint)
+		e.awake.taskFinished()
+	}
 }
 
 // GetStatus 获取执行器状态
-func (e *Executor) GetStatus() (status string, currentTaskID string, currentTaskType string, taskStartedAt int64, runningCount int) {
+// 当前任务按确定性规则选择：存在唯一的批量任务时返回该任务，否则返回最早开始的任务；
+// runningTaskIDs 按开始时间排序，保证心跳上报稳定
+func (e *Executor) GetStatus() (status string, currentTaskID string, currentTaskType string, taskStartedAt int64, runningCount int, runningTaskIDs []string) {
 	e.tasksMutex.Lock()
 	defer e.tasksMutex.Unlock()
 
@@ -381,31 +541,71 @@ func (e *Executor) GetStatus() (status string, currentTaskID string, currentTask
 	}
 
 	status = "BUSY"
-	// 返回第一个任务的信息
+
+	tasks := make([]*TaskInfo, 0, runningCount)
 	for _, info := range e.runningTasks {
-		currentTaskID = info.TaskID
-		currentTaskType = info.TaskType
-		taskStartedAt = info.StartedAt
-		break
+		tasks = append(tasks, info)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].StartedAt != tasks[j].StartedAt {
+			return tasks[i].StartedAt < tasks[j].StartedAt
+		}
+		return tasks[i].TaskID < tasks[j].TaskID
+	})
+
+	current := tasks[0]
+	var batchTasks []*TaskInfo
+	for _, info := range tasks {
+		runningTaskIDs = append(runningTaskIDs, info.TaskID)
+		if isBatchTaskType(info.TaskType) {
+			batchTasks = append(batchTasks, info)
+		}
+	}
+	if len(batchTasks) == 1 {
+		current = batchTasks[0]
 	}
+
+	currentTaskID = current.TaskID
+	currentTaskType = current.TaskType
+	taskStartedAt = current.StartedAt
 	return
 }
 
+// isBatchTaskType 是否为批量执行类型
+func isBatchTaskType(taskType string) bool {
+	switch taskType {
+	case TaskTypeDebugCase, TaskTypeExecutePlan, TaskTypeExecuteCase:
+		return true
+	}
+	return false
+}
+
 // Execute 执行任务
 func (e *Executor) Execute(taskID, taskType, payloadJSON string) {
 	startTime := time.Now()
 
+	// 注册任务，获取取消通道（重复下发的任务不再执行）
+	cancelCh, ok := e.registerTask(taskID, taskType)
+	if !ok {
+		e.handleDuplicateTask(taskID)
+		return
+	}
+
 	// 日志：任务开始
 	log("INFO", fmt.Sprintf("[Task:%s] 开始执行 type=%s", taskID, taskType))
-	log("DEBUG", fmt.Sprintf("[Task:%s] payload=%s", taskID, truncateString(payloadJSON, 500)))
-
-	// 注册任务，获取取消通道
-	cancelCh := e.registerTask(taskID, taskType)
+	log("DEBUG", fmt.Sprintf("[Task:%s] payload=%s", taskID, truncateString(redactPayload(payloadJSON), 500)))
 	defer func() {
 		e.unregisterTask(taskID)
 		duration := time.Since(startTime)
 		log("INFO", fmt.Sprintf("[Task:%s] 执行完成 duration=%v", taskID, duration))
 	}()
+	// 任务异常（panic）时上报系统错误，不让整个 Worker 退出；之后照常注销任务、释放休眠阻止
+	defer func() {
+		if r := recover(); r != nil {
+			log("ERROR", fmt.Sprintf("[Task:%s] 执行异常: %v\n%s", taskID, r, debug.Stack()))
+			e.sendTaskResultWithError(taskID, newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR, fmt.Sprintf("任务执行异常: %v", r)), nil, startTime)
+		}
+	}()
 
 	// 发送任务确认
 	e.sendTaskAck(taskID, true, "任务已接收")
@@ -430,6 +630,7 @@ func (e *Executor) Execute(taskID, taskType, payloadJSON string) {
 
 	// 根据任务类型执行
 	var result interface{}
+	var action *ActionResult
 	var err error
 
 	switch taskType {
@@ -447,8 +648,18 @@ func (e *Executor) Execute(taskID, taskType, payloadJSON string) {
 		e.executeAIAction(taskID, payload, startTime)
 		return
 	default:
-		// 单步任务：复用 executeSingleStep 统一分发
-		result, err = e.executeSingleStep(taskType, payload)
+		// 单步任务：通过动作注册表分发，任务取消时 ctx 随之取消
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-cancelCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		action, err = e.runAction(ctx, taskType, payload)
+		result = action.Data
+		cancel()
 	}
 
 	// 发送结果
@@ -466,34 +677,27 @@ func (e *Executor) Execute(taskID, taskType, payloadJSON string) {
 		}
 		e.sendTaskResultWithError(taskID, taskErr, nil, startTime, resultJSONForError)
 	} else {
-		// 尝试提取匹配位置
-		var matchLoc *pb.MatchLocation
-		if resultMap, ok := result.(map[string]interface{}); ok {
-			if x, xOk := resultMap["x"].(int); xOk {
-				if y, yOk := resultMap["y"].(int); yOk {
-					matchLoc = &pb.MatchLocation{
-						X: int32(x),
-						Y: int32(y),
-					}
-					if conf, ok := resultMap["confidence"].(float64); ok {
-						matchLoc.Confidence = float32(conf)
-					}
-				}
-			}
-		}
-
 		resultJSON, _ := json.Marshal(result)
 		log("INFO", fmt.Sprintf("[Task:%s] 执行成功 result=%s", taskID, truncateString(string(resultJSON), 200)))
-		e.sendTaskResultSuccess(taskID, string(resultJSON), matchLoc, startTime)
+		e.sendTaskResultSuccess(taskID, string(resultJSON), matchLocation(action), startTime)
 	}
 }
 
 // ==================== 工具函数 ====================
 
-// truncateString 截断字符串
+// truncateString 截断字符串（按字节长度，保证在 UTF-8 字符边界截断）
+// 截断后追加被省略的字节数，如 "abc...(+120 bytes)"
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
 	}
-	return s[:maxLen] + "..."
+	if maxLen < 0 {
+		maxLen = 0
+	}
+
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...(+%d bytes)", s[:cut], len(s)-cut)
 }
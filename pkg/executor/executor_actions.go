@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"image"
 	"image/png"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,29 +27,34 @@ import (
 	"github.com/zoeyai/zoeyworker/pkg/plugin"
 	"github.com/zoeyai/zoeyworker/pkg/process"
 	"github.com/zoeyai/zoeyworker/pkg/python"
+	"github.com/zoeyai/zoeyworker/pkg/uia"
+	"github.com/zoeyai/zoeyworker/pkg/vision/cv"
 	"github.com/zoeyai/zoeyworker/pkg/vision/ocr"
 )
 
 // ==================== 单步操作实现 ====================
 
 // executeClickImage 执行点击图像
-func (e *Executor) executeClickImage(payload map[string]interface{}) (interface{}, error) {
+func (e *Executor) executeClickImage(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
 	imagePath, ok := payload["image"].(string)
 	if !ok || imagePath == "" {
-		return nil, fmt.Errorf("缺少 image 参数")
+		return nil, auto.Errorf(auto.ErrParam, "缺少 image 参数")
 	}
 
 	// 检查是否有网格参数
 	gridStr, _ := payload["grid"].(string)
 
-	opts := e.parseAutoOptions(payload)
+	opts, err := e.parseLocatorOptions(ctx, payload, result)
+	if err != nil {
+		return nil, err
+	}
 
 	// 获取任务 ID（用于调试）
 	taskID, _ := payload["task_id"].(string)
 	startTime := time.Now()
 
 	// 发送调试数据的辅助函数
-	sendDebugData := func(status string, matched bool, confidence float64, x, y int, errMsg string) {
+	sendDebugData := func(status string, matched bool, confidence float64, bounds BoundsInfo, errMsg string) {
 		// 截取当前屏幕
 		screenBase64 := ""
 		if screenImg, err := screen.CaptureScreen(); err == nil {
@@ -63,38 +72,54 @@ func (e *Executor) executeClickImage(payload map[string]interface{}) (interface{
 			ScreenBase64:   screenBase64,
 			Matched:        matched,
 			Confidence:     confidence,
-			X:              x,
-			Y:              y,
+			X:              bounds.X,
+			Y:              bounds.Y,
+			Width:          bounds.Width,
+			Height:         bounds.Height,
 			Duration:       time.Since(startTime).Milliseconds(),
 			Error:          errMsg,
 		})
 	}
 
 	// 🔴 立即发送调试数据：开始搜索
-	sendDebugData("searching", false, 0, 0, 0, "")
+	sendDebugData("searching", false, 0, BoundsInfo{}, "")
 
+	var match *cv.MatchResult
 	if gridStr != "" {
 		// 使用网格点击
-		err := autoimage.ClickImageWithGrid(imagePath, gridStr, opts...)
-		if err != nil {
-			sendDebugData("not_found", false, 0, 0, 0, err.Error())
-			return nil, err
-		}
-		x, y := input.GetMousePosition()
-		sendDebugData("found", true, 1.0, x, y, "")
-		return map[string]interface{}{"clicked": true, "grid": gridStr}, nil
+		match, err = autoimage.ClickImageWithGridResult(imagePath, gridStr, opts...)
+	} else {
+		// 普通点击
+		match, err = autoimage.ClickImageResult(imagePath, opts...)
 	}
-
-	// 普通点击
-	err := autoimage.ClickImage(imagePath, opts...)
 	if err != nil {
-		sendDebugData("not_found", false, 0, 0, 0, err.Error())
+		sendDebugData("not_found", false, 0, BoundsInfo{}, err.Error())
 		return nil, err
 	}
 
+	region := autoimage.MatchRegion(match)
+	bounds := &BoundsInfo{X: region.X, Y: region.Y, Width: region.Width, Height: region.Height}
+	sendDebugData("found", true, match.Confidence, *bounds, "")
+
 	x, y := input.GetMousePosition()
-	sendDebugData("found", true, 1.0, x, y, "")
-	return map[string]bool{"clicked": true}, nil
+	result.ClickPosition = &PositionInfo{X: x, Y: y}
+	result.TargetBounds = bounds
+	result.Confidence = match.Confidence
+	result.Scale = match.Scale
+	log("DEBUG", fmt.Sprintf("图像匹配 confidence=%.3f scale=%.2f", match.Confidence, match.Scale))
+
+	data := map[string]interface{}{
+		"clicked":    true,
+		"x":          match.Result.X,
+		"y":          match.Result.Y,
+		"confidence": match.Confidence,
+		"scale":      match.Scale,
+		"bounds":     bounds,
+	}
+	if gridStr != "" {
+		data["grid"] = gridStr
+	}
+	return data, nil
 }
 
 // isOCRAvailable 检查 OCR 功能是否可用（插件安装或默认配置可用）
@@ -106,86 +131,53 @@ func isOCRAvailable() bool {
 }
 
 // executeClickText 执行点击文字
-func (e *Executor) executeClickText(payload map[string]interface{}) (interface{}, error) {
+func (e *Executor) executeClickText(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
 	if !isOCRAvailable() {
 		return nil, fmt.Errorf("OCR 功能未安装，请在客户端设置中下载安装 OCR 支持")
 	}
 
 	textStr, ok := payload["text"].(string)
 	if !ok || textStr == "" {
-		return nil, fmt.Errorf("缺少 text 参数")
+		return nil, auto.Errorf(auto.ErrParam, "缺少 text 参数")
 	}
 
-	opts := e.parseAutoOptions(payload)
-	err := text.ClickText(textStr, opts...)
+	opts, err := e.parseLocatorOptions(ctx, payload, result)
 	if err != nil {
 		return nil, err
 	}
-
-	return map[string]bool{"clicked": true}, nil
-}
-
-// executeTypeText 执行输入文字
-func (e *Executor) executeTypeText(payload map[string]interface{}) (interface{}, error) {
-	textStr, ok := payload["text"].(string)
-	if !ok {
-		return nil, fmt.Errorf("缺少 text 参数")
-	}
-
-	input.TypeText(textStr)
-	return map[string]bool{"typed": true}, nil
-}
-
-// executeKeyPress 执行按键
-func (e *Executor) executeKeyPress(payload map[string]interface{}) (interface{}, error) {
-	// 新格式：keys 数组 (如 ["Ctrl", "C"] 或 ["Enter"])
-	if keysRaw, ok := payload["keys"].([]interface{}); ok && len(keysRaw) > 0 {
-		var keys []string
-		for _, k := range keysRaw {
-			if s, ok := k.(string); ok {
-				keys = append(keys, s)
-			}
-		}
-
-		if len(keys) == 0 {
-			return nil, fmt.Errorf("keys 数组为空")
-		}
-
-		if len(keys) == 1 {
-			input.KeyTap(keys[0])
-		} else {
-			mainKey := keys[len(keys)-1]
-			modifiers := keys[:len(keys)-1]
-			input.KeyTap(mainKey, modifiers...)
-		}
-
-		return map[string]interface{}{"pressed": true, "keys": keys}, nil
-	}
-
-	// 旧格式兼容：key + modifiers
-	key, ok := payload["key"].(string)
-	if !ok || key == "" {
-		return nil, fmt.Errorf("缺少 key 参数")
-	}
-
-	var modifiers []string
-	if mods, ok := payload["modifiers"].([]interface{}); ok {
-		for _, m := range mods {
-			if s, ok := m.(string); ok {
-				modifiers = append(modifiers, s)
-			}
-		}
+	result.SearchRegion = searchRegionInfo(opts)
+	pos, match, err := text.ClickTextMatch(textStr, opts...)
+	if err != nil {
+		return nil, err
 	}
 
-	input.KeyTap(key, modifiers...)
-	return map[string]bool{"pressed": true}, nil
+	result.ClickPosition = &PositionInfo{X: pos.X, Y: pos.Y}
+	result.TargetBounds = textBounds(match)
+	result.MatchedText = match.Text
+	result.Confidence = match.Confidence
+	return withSearchRegion(map[string]interface{}{
+		"clicked":      true,
+		"x":            match.Position.X,
+		"y":            match.Position.Y,
+		"confidence":   match.Confidence,
+		"matched_text": match.Text,
+	}, result.SearchRegion), nil
 }
 
 // executeScreenshot 执行截屏
-func (e *Executor) executeScreenshot(payload map[string]interface{}) (interface{}, error) {
+func (e *Executor) executeScreenshot(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
 	savePath, _ := payload["save_path"].(string)
 
-	img, err := screen.CaptureScreen()
+	id, hasDisplay, err := parseDisplayID(payload)
+	if err != nil {
+		return nil, err
+	}
+	var img image.Image
+	if hasDisplay {
+		img, err = screen.CaptureDisplay(id)
+	} else {
+		img, err = screen.CaptureScreen()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -211,89 +203,106 @@ func (e *Executor) executeScreenshot(payload map[string]interface{}) (interface{
 }
 
 // executeWaitImage 执行等待图像
-func (e *Executor) executeWaitImage(payload map[string]interface{}) (interface{}, error) {
+func (e *Executor) executeWaitImage(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
 	imagePath, ok := payload["image"].(string)
 	if !ok || imagePath == "" {
-		return nil, fmt.Errorf("缺少 image 参数")
+		return nil, auto.Errorf(auto.ErrParam, "缺少 image 参数")
 	}
 
-	opts := e.parseAutoOptions(payload)
-	pos, err := autoimage.WaitForImage(imagePath, opts...)
+	opts, err := e.parseLocatorOptions(ctx, payload, result)
+	if err != nil {
+		return nil, err
+	}
+	match, err := autoimage.FindImage(imagePath, opts...)
 	if err != nil {
 		return nil, err
 	}
+	setImageMatch(result, match)
 
 	return map[string]interface{}{
 		"found": true,
-		"x":     pos.X,
-		"y":     pos.Y,
+		"x":     match.Result.X,
+		"y":     match.Result.Y,
 	}, nil
 }
 
 // executeWaitText 执行等待文字
-func (e *Executor) executeWaitText(payload map[string]interface{}) (interface{}, error) {
+func (e *Executor) executeWaitText(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
 	if !isOCRAvailable() {
 		return nil, fmt.Errorf("OCR 功能未安装，请在客户端设置中下载安装 OCR 支持")
 	}
 
 	textStr, ok := payload["text"].(string)
 	if !ok || textStr == "" {
-		return nil, fmt.Errorf("缺少 text 参数")
+		return nil, auto.Errorf(auto.ErrParam, "缺少 text 参数")
 	}
 
-	opts := e.parseAutoOptions(payload)
-	pos, err := text.WaitForText(textStr, opts...)
+	opts, err := e.parseLocatorOptions(ctx, payload, result)
+	if err != nil {
+		return nil, err
+	}
+	result.SearchRegion = searchRegionInfo(opts)
+	match, err := text.WaitForTextMatch(textStr, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"found": true,
-		"x":     pos.X,
-		"y":     pos.Y,
-	}, nil
+	result.TargetBounds = textBounds(match)
+	result.MatchedText = match.Text
+	result.Confidence = match.Confidence
+	return withSearchRegion(map[string]interface{}{
+		"found":        true,
+		"x":            match.Position.X,
+		"y":            match.Position.Y,
+		"confidence":   match.Confidence,
+		"matched_text": match.Text,
+	}, result.SearchRegion), nil
 }
 
 // executeMouseMove 执行鼠标移动
-func (e *Executor) executeMouseMove(payload map[string]interface{}) (interface{}, error) {
-	x, xOk := payload["x"].(float64)
-	y, yOk := payload["y"].(float64)
-
-	if !xOk || !yOk {
-		return nil, fmt.Errorf("缺少 x 或 y 参数")
+// 坐标可为绝对像素 x/y 或百分比 x_pct/y_pct
+func (e *Executor) executeMouseMove(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
+	x, y, err := resolvePoint(payload)
+	if err != nil {
+		return nil, err
 	}
 
-	input.MoveTo(int(x), int(y))
+	input.MoveTo(x, y)
 	return map[string]bool{"moved": true}, nil
 }
 
 // executeMouseClick 执行鼠标点击
-func (e *Executor) executeMouseClick(payload map[string]interface{}) (interface{}, error) {
-	x, xOk := payload["x"].(float64)
-	y, yOk := payload["y"].(float64)
-
-	if !xOk || !yOk {
-		return nil, fmt.Errorf("缺少 x 或 y 参数")
+// 坐标可为绝对像素 x/y 或百分比 x_pct/y_pct
+// button: left/right/middle（默认 left），right=true 等价于 button=right，double=true 双击
+func (e *Executor) executeMouseClick(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
+	x, y, err := resolvePoint(payload)
+	if err != nil {
+		return nil, err
 	}
 
+	button, _ := payload["button"].(string)
+	if right, _ := payload["right"].(bool); right && button == "" {
+		button = "right"
+	}
+	if button == "" {
+		button = "left"
+	}
 	double, _ := payload["double"].(bool)
-	right, _ := payload["right"].(bool)
 
-	input.MoveTo(int(x), int(y))
+	result.ClickPosition = &PositionInfo{X: x, Y: y}
+	input.MoveTo(x, y)
 
 	if double {
-		input.DoubleClick()
-	} else if right {
-		input.RightClick()
+		input.DoubleClick(button)
 	} else {
-		input.Click()
+		input.Click(button)
 	}
 
 	return map[string]bool{"clicked": true}, nil
 }
 
 // executeActivateApp 执行激活应用
-func (e *Executor) executeActivateApp(payload map[string]interface{}) (interface{}, error) {
+func (e *Executor) executeActivateApp(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
 	appName, _ := payload["app_name"].(string)
 	windowTitle, _ := payload["window_title"].(string)
 
@@ -326,172 +335,541 @@ func (e *Executor) executeActivateApp(payload map[string]interface{}) (interface
 		return map[string]bool{"activated": true}, nil
 	}
 
-	return nil, fmt.Errorf("缺少 app_name 或 window_title 参数")
+	return nil, auto.Errorf(auto.ErrParam, "缺少 app_name 或 window_title 参数")
 }
 
 // executeGridClick 执行网格点击
-func (e *Executor) executeGridClick(payload map[string]interface{}) (interface{}, error) {
+// region / window 可选，默认全屏
+func (e *Executor) executeGridClick(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
 	gridStr, ok := payload["grid"].(string)
 	if !ok || gridStr == "" {
-		return nil, fmt.Errorf("缺少 grid 参数")
+		return nil, auto.Errorf(auto.ErrParam, "缺少 grid 参数")
 	}
 
 	var region auto.Region
-	if r, ok := payload["region"].(map[string]interface{}); ok {
-		region.X = int(r["x"].(float64))
-		region.Y = int(r["y"].(float64))
-		region.Width = int(r["width"].(float64))
-		region.Height = int(r["height"].(float64))
+	area, err := parseSearchArea(payload)
+	if err != nil {
+		return nil, err
+	}
+	if area != nil {
+		region = *area
 	} else {
 		w, h := screen.GetScreenSize()
 		region = auto.Region{X: 0, Y: 0, Width: w, Height: h}
 	}
 
-	opts := e.parseAutoOptions(payload)
-	err := grid.ClickGrid(region, gridStr, opts...)
+	opts, err := e.parseAutoOptions(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := grid.ClickGridResult(region, gridStr, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]bool{"clicked": true}, nil
+	result.ClickPosition = &PositionInfo{X: pos.X, Y: pos.Y}
+	return map[string]interface{}{"clicked": true, "grid": gridStr, "x": pos.X, "y": pos.Y}, nil
 }
 
 // executeImageExists 执行检查图像存在
-func (e *Executor) executeImageExists(payload map[string]interface{}) (interface{}, error) {
+func (e *Executor) executeImageExists(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
 	imagePath, ok := payload["image"].(string)
 	if !ok || imagePath == "" {
-		return nil, fmt.Errorf("缺少 image 参数")
+		return nil, auto.Errorf(auto.ErrParam, "缺少 image 参数")
 	}
 
-	opts := e.parseAutoOptions(payload)
-	exists := autoimage.ImageExists(imagePath, opts...)
+	opts, err := e.parseLocatorOptions(ctx, payload, result)
+	if err != nil {
+		return nil, err
+	}
+	match := findImageOnce(imagePath, opts)
+	setImageMatch(result, match)
 
-	return map[string]bool{"exists": exists}, nil
+	return map[string]bool{"exists": match != nil}, nil
 }
 
 // executeTextExists 执行检查文字存在
-func (e *Executor) executeTextExists(payload map[string]interface{}) (interface{}, error) {
+func (e *Executor) executeTextExists(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
 	textStr, ok := payload["text"].(string)
 	if !ok || textStr == "" {
-		return nil, fmt.Errorf("缺少 text 参数")
+		return nil, auto.Errorf(auto.ErrParam, "缺少 text 参数")
 	}
 
-	opts := e.parseAutoOptions(payload)
-	exists := text.TextExists(textStr, opts...)
+	opts, err := e.parseLocatorOptions(ctx, payload, result)
+	if err != nil {
+		return nil, err
+	}
+	result.SearchRegion = searchRegionInfo(opts)
+	match, reason, err := findText(textStr, opts)
+	if err != nil {
+		return nil, err
+	}
 
-	return map[string]bool{"exists": exists}, nil
+	data := map[string]interface{}{"exists": match != nil}
+	if match != nil {
+		result.TargetBounds = textBounds(match)
+		result.MatchedText = match.Text
+		result.Confidence = match.Confidence
+		data["matched_text"] = match.Text
+		data["confidence"] = match.Confidence
+	} else if reason != nil {
+		data["reason"] = reason.Error()
+	}
+	return withSearchRegion(data, result.SearchRegion), nil
 }
 
-// executeGetClipboard 执行获取剪贴板
-func (e *Executor) executeGetClipboard(payload map[string]interface{}) (interface{}, error) {
-	textStr, err := input.ReadClipboard()
+// DefaultMaxTextItems text_find_all 默认最多返回的文字行数
+const DefaultMaxTextItems = 500
+
+// 可替换的文字识别实现（便于测试）
+var (
+	findAllText  = text.FindAllText
+	findTextOnce = text.FindText
+)
+
+// TextItem text_find_all 返回的一行文字（屏幕坐标）
+type TextItem struct {
+	Text       string     `json:"text"`
+	Confidence float64    `json:"confidence"`
+	X          int        `json:"x"` // 文字中心
+	Y          int        `json:"y"`
+	Bounds     BoundsInfo `json:"bounds"`
+}
+
+// executeTextFindAll 识别全屏（或 region / window）内的全部文字行，可按 contains 过滤（忽略大小写）
+// 只截图和识别，不操作鼠标键盘，缺少辅助功能权限时同样可用；
+// 返回的 items 最多 max_items 条（默认 DefaultMaxTextItems），total 为过滤后的总行数
+func (e *Executor) executeTextFindAll(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
+	if !ocrAvailable() {
+		return nil, fmt.Errorf("OCR 功能未安装，请在客户端设置中下载安装 OCR 支持")
+	}
+
+	contains, _ := payload["contains"].(string)
+	maxItems := DefaultMaxTextItems
+	if raw, ok := payload["max_items"]; ok {
+		v, ok := raw.(float64)
+		if !ok || v < 1 || v != float64(int(v)) {
+			return nil, auto.Errorf(auto.ErrParam, "max_items 必须是正整数: %v", raw)
+		}
+		maxItems = min(int(v), DefaultMaxTextItems)
+	}
+
+	opts, err := e.parseLocatorOptions(ctx, payload, result)
+	if err != nil {
+		return nil, err
+	}
+	result.SearchRegion = searchRegionInfo(opts)
+	lines, err := findAllText(opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]string{"text": textStr}, nil
+	target := strings.ToLower(contains)
+	items := make([]TextItem, 0, min(len(lines), maxItems))
+	total := 0
+	for _, line := range lines {
+		if target != "" && !strings.Contains(strings.ToLower(line.Text), target) {
+			continue
+		}
+		total++
+		if len(items) < maxItems {
+			items = append(items, TextItem{
+				Text:       line.Text,
+				Confidence: line.Confidence,
+				X:          line.Position.X,
+				Y:          line.Position.Y,
+				Bounds:     BoundsInfo{X: line.Bounds.X, Y: line.Bounds.Y, Width: line.Bounds.Width, Height: line.Bounds.Height},
+			})
+		}
+	}
+
+	data := map[string]interface{}{
+		"items":     items,
+		"total":     total,
+		"truncated": total > len(items),
+	}
+	return withSearchRegion(data, result.SearchRegion), nil
 }
 
-// executeSetClipboard 执行设置剪贴板
-func (e *Executor) executeSetClipboard(payload map[string]interface{}) (interface{}, error) {
-	textStr, ok := payload["text"].(string)
-	if !ok {
-		return nil, fmt.Errorf("缺少 text 参数")
+// findText 查找一次文字；只有匹配条件非法（PARAM_ERROR）时返回 err，OCR 不可用等情况视为未找到
+// 目标只出现在低置信度识别结果中时 reason 说明最佳候选及其置信度
+func findText(textStr string, opts []auto.Option) (match *text.TextMatch, reason error, err error) {
+	match, err = findTextOnce(textStr, opts...)
+	switch {
+	case errors.Is(err, auto.ErrParam):
+		return nil, nil, err
+	case errors.Is(err, auto.ErrNotFound):
+		return nil, err, nil
+	}
+	return match, nil, nil
+}
+
+// findImageOnce 查找一次图像（不等待），未找到或出错时返回 nil（同 autoimage.ImageExists）
+func findImageOnce(imagePath string, opts []auto.Option) *cv.MatchResult {
+	match, _ := locateImage(imagePath, append(opts, auto.WithTimeout(0))...)
+	return match
+}
+
+// setImageMatch 记录图像匹配的目标边界和置信度，match 为 nil 时不记录
+func setImageMatch(result *ActionResult, match *cv.MatchResult) {
+	if match == nil {
+		return
+	}
+	region := autoimage.MatchRegion(match)
+	result.TargetBounds = &BoundsInfo{X: region.X, Y: region.Y, Width: region.Width, Height: region.Height}
+	result.Confidence = match.Confidence
+	result.Scale = match.Scale
+}
+
+// textBounds 文字匹配的文字框，识别结果没有文字框时返回 nil
+func textBounds(match *text.TextMatch) *BoundsInfo {
+	if match.Bounds.Width <= 0 || match.Bounds.Height <= 0 {
+		return nil
+	}
+	return &BoundsInfo{X: match.Bounds.X, Y: match.Bounds.Y, Width: match.Bounds.Width, Height: match.Bounds.Height}
+}
+
+// 可替换的 UIA 与窗口查询实现（便于测试）
+var (
+	uiaClickElement = uia.ClickElement
+	getWindows      = window.GetWindows
+)
+
+// executeClickNative 执行原生控件点击（UI Automation）
+// 目标窗口可通过 window_handle、pid 或 app_name/window_title 指定；
+// 优先使用原生窗口句柄，句柄不可用时按进程 ID 连接
+func (e *Executor) executeClickNative(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
+	automationID, _ := payload["automation_id"].(string)
+
+	if automationID == "" {
+		return nil, auto.Errorf(auto.ErrParam, "缺少 automation_id 参数")
 	}
 
-	err := input.CopyToClipboard(textStr)
+	target, err := resolveWindowTarget(payload)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]bool{"copied": true}, nil
+	if err := uiaClickElement(target, automationID); err != nil {
+		return nil, fmt.Errorf("点击原生控件失败 (%s): %w", target, err)
+	}
+
+	return map[string]interface{}{
+		"clicked":       true,
+		"window_handle": target.Handle,
+		"pid":           target.PID,
+	}, nil
 }
 
-// executeClickNative 执行原生控件点击
-func (e *Executor) executeClickNative(payload map[string]interface{}) (interface{}, error) {
-	automationID, _ := payload["automation_id"].(string)
+// resolveWindowTarget 从 payload 解析 UIA 目标窗口
+func resolveWindowTarget(payload map[string]interface{}) (uia.WindowTarget, error) {
+	var target uia.WindowTarget
+	if handle, ok := payload["window_handle"].(float64); ok {
+		target.Handle = int64(handle)
+	}
+	if pid, ok := payload["pid"].(float64); ok {
+		target.PID = int(pid)
+	}
+	if target.IsValid() {
+		return target, nil
+	}
 
-	if automationID == "" {
-		return nil, fmt.Errorf("缺少 automation_id 参数")
+	filter, _ := payload["window_title"].(string)
+	if filter == "" {
+		filter, _ = payload["app_name"].(string)
+	}
+	if filter == "" {
+		return target, auto.Errorf(auto.ErrParam, "缺少 window_handle、pid、app_name 或 window_title 参数")
 	}
 
-	return map[string]bool{"clicked": true}, nil
+	windows, err := getWindows(filter)
+	if err != nil {
+		return target, fmt.Errorf("获取窗口列表失败: %w", err)
+	}
+	if len(windows) == 0 {
+		return target, auto.Errorf(auto.ErrNotFound, "未找到窗口: %s", filter)
+	}
+
+	return uia.WindowTarget{Handle: windows[0].Handle, PID: windows[0].PID}, nil
 }
 
 // executeWaitTime 执行等待时间
-func (e *Executor) executeWaitTime(payload map[string]interface{}) (interface{}, error) {
+func (e *Executor) executeWaitTime(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
 	duration, ok := payload["duration"].(float64)
 	if !ok {
 		duration = 1000
 	}
 
-	time.Sleep(time.Duration(duration) * time.Millisecond)
+	// 任务取消时立即返回
+	select {
+	case <-time.After(time.Duration(duration) * time.Millisecond):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 	return map[string]interface{}{"waited": true, "duration_ms": duration}, nil
 }
 
+// defaultForceAfterMs 关闭应用时等待进程优雅退出的默认时间（毫秒）
+const defaultForceAfterMs = 3000
+
+// defaultCloseAppMaxMatches close_app 默认最多终止的进程数，超过时拒绝执行
+const defaultCloseAppMaxMatches = 10
+
 // executeCloseApp 执行关闭应用
-func (e *Executor) executeCloseApp(payload map[string]interface{}) (interface{}, error) {
+// 默认忽略大小写和 ".exe" 后缀，优先终止名称完全相同的进程，没有时才按部分匹配（见 process.FindByName）；
+// strict=true 时要求名称完全一致。匹配数超过 max_matches 时不终止任何进程
+func (e *Executor) executeCloseApp(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
 	appName, ok := payload["app_name"].(string)
 	if !ok || appName == "" {
-		return nil, fmt.Errorf("缺少 app_name 参数")
+		return nil, auto.Errorf(auto.ErrParam, "缺少 app_name 参数")
+	}
+
+	strict, _ := payload["strict"].(bool)
+	forceAfterMs := float64(defaultForceAfterMs)
+	if v, ok := payload["force_after_ms"].(float64); ok && v >= 0 {
+		forceAfterMs = v
+	}
+	maxMatches := defaultCloseAppMaxMatches
+	if raw, ok := payload["max_matches"]; ok {
+		v, ok := raw.(float64)
+		if !ok || v < 1 || v != float64(int(v)) {
+			return nil, auto.Errorf(auto.ErrParam, "max_matches 必须是正整数: %v", raw)
+		}
+		maxMatches = int(v)
 	}
 
-	processes, err := process.GetProcesses()
+	processes, err := listProcesses()
 	if err != nil {
 		return nil, fmt.Errorf("获取进程列表失败: %w", err)
 	}
 
-	for _, proc := range processes {
-		if proc.Name == appName {
-			if err := process.KillProcess(proc.PID); err != nil {
-				return nil, fmt.Errorf("终止进程失败: %w", err)
-			}
-			return map[string]interface{}{"closed": true, "pid": proc.PID}, nil
+	selfPID := os.Getpid()
+	processes = slices.DeleteFunc(processes, func(proc process.ProcessInfo) bool { return proc.PID == selfPID })
+	matched := process.FindByName(processes, appName, strict)
+	if len(matched) == 0 {
+		return nil, auto.Errorf(auto.ErrNotFound, "未找到进程: %s", appName)
+	}
+	if len(matched) > maxMatches {
+		return nil, auto.Errorf(auto.ErrParam, "app_name=%s 匹配到 %d 个进程，超过 max_matches（%d），未终止任何进程；请使用更精确的名称或 strict",
+			appName, len(matched), maxMatches)
+	}
+	pids := make([]int, len(matched))
+	for i, proc := range matched {
+		pids[i] = proc.PID
+	}
+
+	// 先向所有匹配的进程发送终止信号，再统一等待 force_after_ms，避免逐个等待；任务取消时立即强制结束
+	errs := stopProcesses(ctx, pids, time.Duration(forceAfterMs)*time.Millisecond)
+	killedPIDs := make([]int, 0, len(matched))
+	var lastErr error
+	for _, proc := range matched {
+		if err := errs[proc.PID]; err != nil {
+			log("WARN", fmt.Sprintf("终止进程失败: pid=%d name=%s err=%v", proc.PID, proc.Name, err))
+			lastErr = err
+			continue
 		}
+		killedPIDs = append(killedPIDs, proc.PID)
 	}
 
-	return nil, fmt.Errorf("未找到进程: %s", appName)
+	if len(killedPIDs) == 0 {
+		return nil, fmt.Errorf("终止进程失败: %w", lastErr)
+	}
+
+	return map[string]interface{}{
+		"closed":      true,
+		"pid":         killedPIDs[0],
+		"killed_pids": killedPIDs,
+		"matched":     len(matched),
+	}, nil
 }
 
 // executeAssertImage 执行图像断言
-func (e *Executor) executeAssertImage(payload map[string]interface{}) (interface{}, error) {
+func (e *Executor) executeAssertImage(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
 	imagePath, ok := payload["image"].(string)
 	if !ok || imagePath == "" {
-		return nil, fmt.Errorf("缺少 image 参数")
+		return nil, auto.Errorf(auto.ErrParam, "缺少 image 参数")
 	}
 
-	opts := e.parseAutoOptions(payload)
-	exists := autoimage.ImageExists(imagePath, opts...)
-
-	if !exists {
-		return nil, fmt.Errorf("断言失败: 未找到指定图像")
+	opts, err := e.parseLocatorOptions(ctx, payload, result)
+	if err != nil {
+		return nil, err
 	}
+	match := findImageOnce(imagePath, opts)
+	if match == nil {
+		return nil, auto.Errorf(ErrAssertionFailed, "断言失败: 未找到指定图像")
+	}
+	setImageMatch(result, match)
 
 	return map[string]bool{"asserted": true, "exists": true}, nil
 }
 
+// ImageItem assert_image_count 返回的一个匹配（屏幕坐标）
+type ImageItem struct {
+	X          int        `json:"x"` // 匹配中心
+	Y          int        `json:"y"`
+	Confidence float64    `json:"confidence"`
+	Bounds     BoundsInfo `json:"bounds"`
+}
+
+// executeAssertImageCount 断言图像的匹配数量：expected 为精确数量，或 min / max 指定范围（可只给一端）
+// 截图一次，用模板匹配找出所有达到 threshold 的匹配（重叠的匹配经非极大值抑制去重）后计数；
+// 结果包含实际数量 count 和全部匹配 matches，最佳匹配写入 x / y / confidence / bounds；
+// 数量不在范围内时返回 ASSERTION_FAILED，结果数据同样返回
+func (e *Executor) executeAssertImageCount(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
+	imagePath, ok := payload["image"].(string)
+	if !ok || imagePath == "" {
+		return nil, auto.Errorf(auto.ErrParam, "缺少 image 参数")
+	}
+	minCount, maxCount, err := parseCountRange(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := e.parseLocatorOptions(ctx, payload, result)
+	if err != nil {
+		return nil, err
+	}
+	// 有上限时多找一个即可判断超出
+	limit := 0
+	if maxCount >= 0 {
+		limit = maxCount + 1
+	}
+	matches, err := findAllImages(imagePath, limit, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]ImageItem, len(matches))
+	for i, match := range matches {
+		region := autoimage.MatchRegion(match)
+		items[i] = ImageItem{
+			X:          match.Result.X,
+			Y:          match.Result.Y,
+			Confidence: match.Confidence,
+			Bounds:     BoundsInfo{X: region.X, Y: region.Y, Width: region.Width, Height: region.Height},
+		}
+	}
+	count := len(items)
+	data := map[string]interface{}{
+		"count":   count,
+		"matches": items,
+	}
+	if count > 0 {
+		best := matches[0]
+		setImageMatch(result, best)
+		data["x"] = best.Result.X
+		data["y"] = best.Result.Y
+		data["confidence"] = best.Confidence
+		data["bounds"] = result.TargetBounds
+	}
+
+	expected := countRangeString(minCount, maxCount)
+	data["expected"] = expected
+	if count < minCount || maxCount >= 0 && count > maxCount {
+		countStr := strconv.Itoa(count)
+		if maxCount >= 0 && count > maxCount {
+			countStr = "超过 " + strconv.Itoa(maxCount)
+		}
+		return data, auto.Errorf(ErrAssertionFailed, "断言失败: 图像匹配数量为 %s，期望 %s", countStr, expected)
+	}
+	data["asserted"] = true
+	return data, nil
+}
+
+// parseCountRange 解析 expected 或 min / max，返回数量范围（maxCount 为 -1 表示无上限）
+func parseCountRange(payload map[string]interface{}) (minCount, maxCount int, err error) {
+	parse := func(key string) (int, bool, error) {
+		raw, ok := payload[key]
+		if !ok {
+			return 0, false, nil
+		}
+		v, ok := raw.(float64)
+		if !ok || v < 0 || v != float64(int(v)) {
+			return 0, false, auto.Errorf(auto.ErrParam, "%s 必须是非负整数: %v", key, raw)
+		}
+		return int(v), true, nil
+	}
+
+	expected, hasExpected, err := parse("expected")
+	if err != nil {
+		return 0, 0, err
+	}
+	minCount, hasMin, err := parse("min")
+	if err != nil {
+		return 0, 0, err
+	}
+	maxCount, hasMax, err := parse("max")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	switch {
+	case hasExpected && (hasMin || hasMax):
+		return 0, 0, auto.Errorf(auto.ErrParam, "expected 不能与 min / max 同时指定")
+	case hasExpected:
+		return expected, expected, nil
+	case !hasMin && !hasMax:
+		return 0, 0, auto.Errorf(auto.ErrParam, "缺少 expected 或 min / max 参数")
+	case !hasMax:
+		return minCount, -1, nil
+	case minCount > maxCount:
+		return 0, 0, auto.Errorf(auto.ErrParam, "min (%d) 不能大于 max (%d)", minCount, maxCount)
+	}
+	return minCount, maxCount, nil
+}
+
+// countRangeString 数量范围的说明文字（用于错误信息和结果）
+func countRangeString(minCount, maxCount int) string {
+	switch {
+	case minCount == maxCount:
+		return strconv.Itoa(minCount)
+	case maxCount < 0:
+		return fmt.Sprintf(">= %d", minCount)
+	case minCount == 0:
+		return fmt.Sprintf("<= %d", maxCount)
+	}
+	return fmt.Sprintf("%d-%d", minCount, maxCount)
+}
+
 // executeAssertText 执行文字断言
-func (e *Executor) executeAssertText(payload map[string]interface{}) (interface{}, error) {
+func (e *Executor) executeAssertText(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
 	textStr, ok := payload["text"].(string)
 	if !ok || textStr == "" {
-		return nil, fmt.Errorf("缺少 text 参数")
+		return nil, auto.Errorf(auto.ErrParam, "缺少 text 参数")
 	}
 
-	opts := e.parseAutoOptions(payload)
-	exists := text.TextExists(textStr, opts...)
-
-	if !exists {
-		return nil, fmt.Errorf("断言失败: 未找到指定文字 '%s'", textStr)
+	opts, err := e.parseLocatorOptions(ctx, payload, result)
+	if err != nil {
+		return nil, err
 	}
-
-	return map[string]bool{"asserted": true, "exists": true}, nil
+	result.SearchRegion = searchRegionInfo(opts)
+	match, reason, err := findText(textStr, opts)
+	if err != nil {
+		return nil, err
+	}
+	if match == nil {
+		if reason != nil {
+			return nil, auto.Errorf(ErrAssertionFailed, "断言失败: %v", reason)
+		}
+		return nil, auto.Errorf(ErrAssertionFailed, "断言失败: 未找到指定文字 '%s'", textStr)
+	}
+
+	result.TargetBounds = textBounds(match)
+	result.MatchedText = match.Text
+	result.Confidence = match.Confidence
+	return withSearchRegion(map[string]interface{}{
+		"asserted":     true,
+		"exists":       true,
+		"confidence":   match.Confidence,
+		"matched_text": match.Text,
+	}, result.SearchRegion), nil
 }
 
 // executeRunPython 执行 Python 代码
-func (e *Executor) executeRunPython(payload map[string]interface{}) (interface{}, error) {
+func (e *Executor) executeRunPython(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
 	code, ok := payload["code"].(string)
 	if !ok || code == "" {
-		return nil, fmt.Errorf("缺少 code 参数")
+		return nil, auto.Errorf(auto.ErrParam, "缺少 code 参数")
 	}
 
 	timeoutSec := 30.0
@@ -511,7 +889,7 @@ func (e *Executor) executeRunPython(payload map[string]interface{}) (interface{}
 	}
 	defer os.Remove(tmpFile)
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSec)*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSec)*time.Second)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, pythonInfo.Path, tmpFile)
@@ -528,7 +906,7 @@ func (e *Executor) executeRunPython(payload map[string]interface{}) (interface{}
 	exitCode := 0
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return nil, fmt.Errorf("Python 脚本执行超时（超过 %.0f 秒）", timeoutSec)
+			return nil, auto.Errorf(auto.ErrTimeout, "Python 脚本执行超时（超过 %.0f 秒）", timeoutSec)
 		}
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			exitCode = exitErr.ExitCode()
@@ -555,205 +933,405 @@ func (e *Executor) executeRunPython(payload map[string]interface{}) (interface{}
 	return result, nil
 }
 
-// ==================== 步骤分发 ====================
-
-// executeSingleStep 执行单个步骤
-func (e *Executor) executeSingleStep(taskType string, payload map[string]interface{}) (interface{}, error) {
-	switch taskType {
-	case TaskTypeClickImage:
-		return e.executeClickImage(payload)
-	case TaskTypeClickText:
-		return e.executeClickText(payload)
-	case TaskTypeClickNative:
-		return e.executeClickNative(payload)
-	case TaskTypeTypeText:
-		return e.executeTypeText(payload)
-	case TaskTypeKeyPress:
-		return e.executeKeyPress(payload)
-	case TaskTypeScreenshot:
-		return e.executeScreenshot(payload)
-	case TaskTypeWaitImage:
-		return e.executeWaitImage(payload)
-	case TaskTypeWaitText:
-		return e.executeWaitText(payload)
-	case TaskTypeWaitTime:
-		return e.executeWaitTime(payload)
-	case TaskTypeMouseMove:
-		return e.executeMouseMove(payload)
-	case TaskTypeMouseClick:
-		return e.executeMouseClick(payload)
-	case TaskTypeActivateApp:
-		return e.executeActivateApp(payload)
-	case TaskTypeCloseApp:
-		return e.executeCloseApp(payload)
-	case TaskTypeGridClick:
-		return e.executeGridClick(payload)
-	case TaskTypeImageExists:
-		return e.executeImageExists(payload)
-	case TaskTypeTextExists:
-		return e.executeTextExists(payload)
-	case TaskTypeAssertImage:
-		return e.executeAssertImage(payload)
-	case TaskTypeAssertText:
-		return e.executeAssertText(payload)
-	case TaskTypeGetClipboard:
-		return e.executeGetClipboard(payload)
-	case TaskTypeSetClipboard:
-		return e.executeSetClipboard(payload)
-	case TaskTypeRunPython:
-		return e.executeRunPython(payload)
-	default:
-		return nil, fmt.Errorf("未知的任务类型: %s", taskType)
-	}
-}
-
-// executeSingleStepV2 执行单个步骤（增强版）
-func (e *Executor) executeSingleStepV2(taskType string, payload map[string]interface{}) *ActionResult {
-	result := &ActionResult{Success: true}
-
-	if textStr, ok := payload["text"].(string); ok && taskType == TaskTypeTypeText {
-		result.InputText = textStr
-	}
-
-	mouseX, mouseY := input.GetMousePosition()
-
-	var data interface{}
-	var err error
-
-	switch taskType {
-	case TaskTypeClickImage:
-		data, err = e.executeClickImageV2(payload, result)
-	case TaskTypeClickText:
-		data, err = e.executeClickTextV2(payload, result)
-	case TaskTypeMouseClick:
-		data, err = e.executeMouseClickV2(payload, result)
-	case TaskTypeGridClick:
-		data, err = e.executeGridClickV2(payload, result)
-	default:
-		data, err = e.executeSingleStep(taskType, payload)
-	}
-
-	if err != nil {
-		result.Success = false
-		result.Error = err
-		if result.ClickPosition == nil {
-			result.ClickPosition = &PositionInfo{X: mouseX, Y: mouseY}
-		}
-	}
-
-	result.Data = data
-	return result
-}
-
-// ==================== V2 增强版操作 ====================
-
-func (e *Executor) executeClickImageV2(payload map[string]interface{}, result *ActionResult) (interface{}, error) {
-	data, err := e.executeClickImage(payload)
-
-	if err == nil {
-		x, y := input.GetMousePosition()
-		result.ClickPosition = &PositionInfo{X: x, Y: y}
-	}
-
-	return data, err
-}
-
-func (e *Executor) executeClickTextV2(payload map[string]interface{}, result *ActionResult) (interface{}, error) {
-	if !isOCRAvailable() {
-		return nil, fmt.Errorf("OCR 功能未安装，请在客户端设置中下载安装 OCR 支持")
+// ==================== 选项解析 ====================
+
+// parseAutoOptions 解析自动化选项，ctx 取消时等待和图像匹配提前返回
+// 支持 timeout(秒)、threshold、interval_ms、region、window、display_id、grayscale、multi_scale、
+// offset、offset_pct、double、right，
+// 字段类型或取值非法时返回 PARAM_ERROR，错误信息中包含字段名
+func (e *Executor) parseAutoOptions(ctx context.Context, payload map[string]interface{}) ([]auto.Option, error) {
+	// 任务取消时等待和匹配随之中止
+	opts := []auto.Option{auto.WithContext(ctx)}
+
+	if raw, ok := payload["timeout"]; ok {
+		timeout, ok := raw.(float64)
+		if !ok || timeout < 0 {
+			return nil, auto.Errorf(auto.ErrParam, "timeout 必须是非负数（秒）: %v", raw)
+		}
+		opts = append(opts, auto.WithTimeout(time.Duration(timeout*float64(time.Second))))
 	}
 
-	textStr, ok := payload["text"].(string)
-	if !ok || textStr == "" {
-		return nil, fmt.Errorf("缺少 text 参数")
+	if raw, ok := payload["threshold"]; ok {
+		threshold, ok := raw.(float64)
+		if !ok || threshold <= 0 || threshold > 1 {
+			return nil, auto.Errorf(auto.ErrParam, "threshold 必须在 (0, 1] 之间: %v", raw)
+		}
+		opts = append(opts, auto.WithThreshold(threshold))
 	}
 
-	opts := e.parseAutoOptions(payload)
+	if raw, ok := payload["interval_ms"]; ok {
+		interval, ok := raw.(float64)
+		if !ok || interval <= 0 {
+			return nil, auto.Errorf(auto.ErrParam, "interval_ms 必须是正数: %v", raw)
+		}
+		opts = append(opts, auto.WithInterval(time.Duration(interval*float64(time.Millisecond))))
+	}
 
-	pos, err := text.WaitForText(textStr, opts...)
+	region, err := parseSearchArea(payload)
 	if err != nil {
 		return nil, err
 	}
+	if region != nil {
+		opts = append(opts, auto.WithRegion(region.X, region.Y, region.Width, region.Height))
+	}
 
-	result.ClickPosition = &PositionInfo{X: pos.X, Y: pos.Y}
+	if id, ok, err := parseDisplayID(payload); err != nil {
+		return nil, err
+	} else if ok {
+		opts = append(opts, auto.WithDisplay(id))
+	}
 
-	err = text.ClickText(textStr, opts...)
-	if err != nil {
+	if raw, ok := payload["grayscale"]; ok {
+		grayscale, ok := raw.(bool)
+		if !ok {
+			return nil, auto.Errorf(auto.ErrParam, "grayscale 必须是布尔值: %v", raw)
+		}
+		if grayscale {
+			opts = append(opts, auto.WithGrayscale())
+		}
+	}
+
+	if raw, ok := payload["mask"]; ok {
+		mask, ok := raw.(bool)
+		if !ok {
+			return nil, auto.Errorf(auto.ErrParam, "mask 必须是布尔值: %v", raw)
+		}
+		opts = append(opts, auto.WithMask(mask))
+	}
+
+	if raw, ok := payload["multi_scale"]; ok {
+		switch v := raw.(type) {
+		case bool:
+			opts = append(opts, auto.WithMultiScale(v))
+		case []interface{}:
+			scales := make([]float64, 0, len(v))
+			for _, item := range v {
+				scale, ok := item.(float64)
+				if !ok || scale <= 0 {
+					return nil, auto.Errorf(auto.ErrParam, "multi_scale 缩放比例必须是正数: %v", item)
+				}
+				scales = append(scales, scale)
+			}
+			if len(scales) == 0 {
+				return nil, auto.Errorf(auto.ErrParam, "multi_scale 缩放比例列表不能为空")
+			}
+			opts = append(opts, auto.WithScales(scales...))
+		default:
+			return nil, auto.Errorf(auto.ErrParam, "multi_scale 必须是布尔值或缩放比例数组: %v", raw)
+		}
+	}
+
+	if raw, ok := payload["min_ocr_confidence"]; ok {
+		confidence, ok := raw.(float64)
+		if !ok || confidence < 0 || confidence > 1 {
+			return nil, auto.Errorf(auto.ErrParam, "min_ocr_confidence 必须在 [0, 1] 之间: %v", raw)
+		}
+		if confidence == 0 {
+			// 0 表示不过滤（auto 选项中 0 为默认值）
+			confidence = -1
+		}
+		opts = append(opts, auto.WithMinOCRConfidence(confidence))
+	}
+
+	if raw, ok := payload["language"]; ok {
+		name, isString := raw.(string)
+		language, err := ocr.NormalizeLanguage(name)
+		if !isString || err != nil {
+			return nil, auto.Errorf(auto.ErrParam, "language 必须为语言模型名称（如 ch、ja）: %v", raw)
+		}
+		opts = append(opts, auto.WithLanguage(language))
+	}
+
+	if mode, maxDistance, ok, err := parseTextMatch(payload); err != nil {
 		return nil, err
+	} else if ok {
+		opts = append(opts, auto.WithTextMatch(string(mode), maxDistance))
 	}
 
-	return map[string]bool{"clicked": true}, nil
-}
+	if offset, err := parseClickOffset(payload); err != nil {
+		return nil, err
+	} else if offset != nil {
+		opts = append(opts, auto.WithClickOffset(offset.X, offset.Y))
+	}
 
-func (e *Executor) executeMouseClickV2(payload map[string]interface{}, result *ActionResult) (interface{}, error) {
-	x, xOk := payload["x"].(float64)
-	y, yOk := payload["y"].(float64)
-	if !xOk || !yOk {
-		return nil, fmt.Errorf("缺少 x 或 y 参数")
+	if double, ok := payload["double"].(bool); ok && double {
+		opts = append(opts, auto.WithDoubleClick())
 	}
 
-	result.ClickPosition = &PositionInfo{X: int(x), Y: int(y)}
+	if right, ok := payload["right"].(bool); ok && right {
+		opts = append(opts, auto.WithRightClick())
+	}
 
-	input.MoveTo(int(x), int(y))
+	return opts, nil
+}
 
-	button, _ := payload["button"].(string)
-	if button == "" {
-		button = "left"
+// parseSearchArea 解析搜索区域：region 和/或 window，返回屏幕坐标（nil 表示未限定）
+// 指定 window 时以窗口边界为搜索区域，同时指定 region 时 region 视为窗口内的相对区域
+func parseSearchArea(payload map[string]interface{}) (*auto.Region, error) {
+	var region *auto.Region
+	if _, ok := payload["region"]; ok {
+		r, err := parseRegion(payload, "region")
+		if err != nil {
+			return nil, err
+		}
+		region = &r
 	}
 
-	double, _ := payload["double"].(bool)
-	if double {
-		input.DoubleClick(button)
-	} else {
-		input.Click(button)
+	raw, ok := payload["window"]
+	if !ok {
+		return region, nil
 	}
+	name, ok := raw.(string)
+	if !ok || name == "" {
+		return nil, auto.Errorf(auto.ErrParam, "window 必须是非空字符串（应用名或窗口标题）")
+	}
+	bounds, err := findWindowBounds(name)
+	if err != nil {
+		return nil, err
+	}
+	if region == nil {
+		return &bounds, nil
+	}
+	region.X += bounds.X
+	region.Y += bounds.Y
+	return region, nil
+}
 
-	return map[string]bool{"clicked": true}, nil
+// parseTextMatch 解析文字匹配模式 match_mode 和 fuzzy 模式的 max_distance，返回是否指定
+func parseTextMatch(payload map[string]interface{}) (ocr.MatchMode, int, bool, error) {
+	rawMode, hasMode := payload["match_mode"]
+	rawDistance, hasDistance := payload["max_distance"]
+	if !hasMode && !hasDistance {
+		return "", 0, false, nil
+	}
+
+	var mode ocr.MatchMode
+	if hasMode {
+		name, ok := rawMode.(string)
+		if !ok {
+			return "", 0, false, auto.Errorf(auto.ErrParam, "match_mode 必须是字符串: %v", rawMode)
+		}
+		var err error
+		if mode, err = ocr.ParseMatchMode(name); err != nil {
+			return "", 0, false, auto.Errorf(auto.ErrParam, "match_mode: %v", err)
+		}
+	}
+
+	var maxDistance int
+	if hasDistance {
+		distance, ok := rawDistance.(float64)
+		if !ok || distance < 1 || distance != float64(int(distance)) {
+			return "", 0, false, auto.Errorf(auto.ErrParam, "max_distance 必须是正整数: %v", rawDistance)
+		}
+		maxDistance = int(distance)
+	}
+	return mode, maxDistance, true, nil
 }
 
-func (e *Executor) executeGridClickV2(payload map[string]interface{}, result *ActionResult) (interface{}, error) {
-	gridStr, ok := payload["grid"].(string)
-	if !ok || gridStr == "" {
-		return nil, fmt.Errorf("缺少 grid 参数")
+// searchRegionInfo 返回选项对应的实际搜索区域（全屏搜索时为 nil），写入步骤结果便于调试
+func searchRegionInfo(opts []auto.Option) *BoundsInfo {
+	region, err := screen.SearchRegion(auto.ApplyOptions(opts...))
+	if err != nil || region == nil {
+		return nil
 	}
+	return &BoundsInfo{X: region.X, Y: region.Y, Width: region.Width, Height: region.Height}
+}
 
-	screenWidth, screenHeight := screen.GetScreenSize()
-	region := auto.Region{X: 0, Y: 0, Width: screenWidth, Height: screenHeight}
+// withSearchRegion 限定了搜索区域时在返回数据中附带 region
+func withSearchRegion(data map[string]interface{}, region *BoundsInfo) map[string]interface{} {
+	if region != nil {
+		data["region"] = region
+	}
+	return data
+}
 
-	pos, err := grid.CalculateGridCenterFromString(region, gridStr)
+// findWindowBounds 按应用名或窗口标题查找窗口，返回其屏幕坐标边界
+func findWindowBounds(name string) (auto.Region, error) {
+	windows, err := getWindows(name)
 	if err != nil {
-		return nil, err
+		return auto.Region{}, fmt.Errorf("获取窗口列表失败: %w", err)
+	}
+	for _, w := range windows {
+		if w.Bounds.Width > 0 && w.Bounds.Height > 0 {
+			return w.Bounds, nil
+		}
 	}
+	return auto.Region{}, auto.Errorf(auto.ErrNotFound, "未找到窗口: %s", name)
+}
 
-	result.ClickPosition = &PositionInfo{X: pos.X, Y: pos.Y}
+// parseDisplayID 解析 display_id（兼容 display_index），返回是否指定
+func parseDisplayID(payload map[string]interface{}) (int, bool, error) {
+	key := "display_id"
+	raw, ok := payload[key]
+	if !ok {
+		key = "display_index"
+		if raw, ok = payload[key]; !ok {
+			return 0, false, nil
+		}
+	}
+	id, ok := raw.(float64)
+	if !ok || id < 0 || id != float64(int(id)) {
+		return 0, false, auto.Errorf(auto.ErrParam, "%s 必须是非负整数: %v", key, raw)
+	}
+	return int(id), true, nil
+}
 
-	input.MoveTo(pos.X, pos.Y)
-	input.Click()
+// referenceArea 返回百分比坐标的参照区域：window 指定的窗口 > display_id 指定的显示器 > 主显示器
+func referenceArea(payload map[string]interface{}) (auto.Region, error) {
+	if name, ok := payload["window"].(string); ok && name != "" {
+		return findWindowBounds(name)
+	}
+	if id, ok, err := parseDisplayID(payload); err != nil {
+		return auto.Region{}, err
+	} else if ok {
+		return screen.DisplayBounds(id)
+	}
+	w, h := screen.GetScreenSize()
+	return auto.Region{Width: w, Height: h}, nil
+}
 
-	return map[string]interface{}{"clicked": true, "grid": gridStr, "x": pos.X, "y": pos.Y}, nil
+// parsePct 解析百分比参数，返回值是否存在；超出 [min, max] 时返回 PARAM_ERROR
+func parsePct(params map[string]interface{}, key, name string, min, max float64) (float64, bool, error) {
+	raw, ok := params[key]
+	if !ok {
+		return 0, false, nil
+	}
+	v, ok := raw.(float64)
+	if !ok || v < min || v > max {
+		return 0, false, auto.Errorf(auto.ErrParam, "%s 必须是 [%g, %g] 之间的数字: %v", name, min, max, raw)
+	}
+	return v, true, nil
 }
 
-// ==================== 选项解析 ====================
+// pctToPixel 将百分比转换为参照区域内的像素坐标（限制在区域内）
+func pctToPixel(origin, size int, pct float64) int {
+	offset := int(pct * float64(size))
+	if offset >= size {
+		offset = size - 1
+	}
+	return origin + max(0, offset)
+}
 
-// parseAutoOptions 解析自动化选项
-func (e *Executor) parseAutoOptions(payload map[string]interface{}) []auto.Option {
-	var opts []auto.Option
+// resolvePoint 解析点击/移动坐标：绝对像素 x/y 优先，否则使用百分比 x_pct/y_pct（0-1）
+// 百分比相对于 window 指定的窗口、display_id 指定的显示器或主显示器；
+// 指定 display_id 时绝对坐标相对于该显示器左上角
+func resolvePoint(payload map[string]interface{}) (int, int, error) {
+	x, xOk := payload["x"].(float64)
+	y, yOk := payload["y"].(float64)
+	if xOk && yOk {
+		return absolutePoint(payload, int(x), int(y))
+	}
 
-	if timeout, ok := payload["timeout"].(float64); ok {
-		opts = append(opts, auto.WithTimeout(time.Duration(timeout)*time.Second))
+	xPct, xPctOk, err := parsePct(payload, "x_pct", "x_pct", 0, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	yPct, yPctOk, err := parsePct(payload, "y_pct", "y_pct", 0, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !(xOk || xPctOk) || !(yOk || yPctOk) {
+		return 0, 0, auto.Errorf(auto.ErrParam, "缺少 x 或 y 参数（或 x_pct/y_pct）")
 	}
 
-	if threshold, ok := payload["threshold"].(float64); ok {
-		opts = append(opts, auto.WithThreshold(threshold))
+	area, err := referenceArea(payload)
+	if err != nil {
+		return 0, 0, err
 	}
+	px, py, err := absolutePoint(payload, int(x), int(y))
+	if err != nil {
+		return 0, 0, err
+	}
+	if !xOk {
+		px = pctToPixel(area.X, area.Width, xPct)
+	}
+	if !yOk {
+		py = pctToPixel(area.Y, area.Height, yPct)
+	}
+	return px, py, nil
+}
 
-	if double, ok := payload["double"].(bool); ok && double {
-		opts = append(opts, auto.WithDoubleClick())
+// absolutePoint 转换绝对坐标：指定 display_id 时 x/y 相对于该显示器左上角，否则为全局坐标
+func absolutePoint(payload map[string]interface{}, x, y int) (int, int, error) {
+	id, ok, err := parseDisplayID(payload)
+	if err != nil || !ok {
+		return x, y, err
+	}
+	bounds, err := screen.DisplayBounds(id)
+	if err != nil {
+		return 0, 0, err
 	}
+	return bounds.X + x, bounds.Y + y, nil
+}
 
-	if right, ok := payload["right"].(bool); ok && right {
-		opts = append(opts, auto.WithRightClick())
+// parseClickOffset 解析锚点点击偏移：offset {x, y}（像素）优先，否则 offset_pct {x, y}（参照区域宽高的比例，-1~1）
+func parseClickOffset(payload map[string]interface{}) (*auto.Point, error) {
+	rawOffset, hasOffset := payload["offset"]
+	rawPct, hasPct := payload["offset_pct"]
+	if !hasOffset && !hasPct {
+		return nil, nil
 	}
 
-	return opts
+	var offset auto.Point
+	var hasX, hasY bool
+	if hasOffset {
+		o, ok := rawOffset.(map[string]interface{})
+		if !ok {
+			return nil, auto.Errorf(auto.ErrParam, "offset 必须是包含 x、y 的对象")
+		}
+		if v, ok := o["x"].(float64); ok {
+			offset.X, hasX = int(v), true
+		}
+		if v, ok := o["y"].(float64); ok {
+			offset.Y, hasY = int(v), true
+		}
+	}
+	if !hasPct || (hasX && hasY) {
+		return &offset, nil
+	}
+
+	o, ok := rawPct.(map[string]interface{})
+	if !ok {
+		return nil, auto.Errorf(auto.ErrParam, "offset_pct 必须是包含 x、y 的对象")
+	}
+	xPct, xPctOk, err := parsePct(o, "x", "offset_pct.x", -1, 1)
+	if err != nil {
+		return nil, err
+	}
+	yPct, yPctOk, err := parsePct(o, "y", "offset_pct.y", -1, 1)
+	if err != nil {
+		return nil, err
+	}
+	area, err := referenceArea(payload)
+	if err != nil {
+		return nil, err
+	}
+	if xPctOk && !hasX {
+		offset.X = int(xPct * float64(area.Width))
+	}
+	if yPctOk && !hasY {
+		offset.Y = int(yPct * float64(area.Height))
+	}
+	return &offset, nil
+}
+
+// parseRegion 解析 {x, y, width, height} 区域参数
+func parseRegion(payload map[string]interface{}, key string) (auto.Region, error) {
+	r, ok := payload[key].(map[string]interface{})
+	if !ok {
+		return auto.Region{}, auto.Errorf(auto.ErrParam, "%s 必须是包含 x、y、width、height 的对象", key)
+	}
+	var values [4]float64
+	for i, field := range []string{"x", "y", "width", "height"} {
+		v, ok := r[field].(float64)
+		if !ok {
+			return auto.Region{}, auto.Errorf(auto.ErrParam, "%s.%s 必须是数字", key, field)
+		}
+		values[i] = v
+	}
+	region := auto.Region{X: int(values[0]), Y: int(values[1]), Width: int(values[2]), Height: int(values[3])}
+	if region.Width <= 0 || region.Height <= 0 {
+		return auto.Region{}, auto.Errorf(auto.ErrParam, "%s 宽高必须大于 0", key)
+	}
+	return region, nil
 }
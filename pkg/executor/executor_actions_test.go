@@ -0,0 +1,469 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/text"
+	"github.com/zoeyai/zoeyworker/pkg/auto/window"
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+	"github.com/zoeyai/zoeyworker/pkg/uia"
+	"github.com/zoeyai/zoeyworker/pkg/vision/cv"
+)
+
+// mockClickNative 替换 UIA 与窗口查询实现，记录传入 UIA 的窗口标识
+func mockClickNative(t *testing.T, windows []window.WindowInfo) *[]uia.WindowTarget {
+	t.Helper()
+	var targets []uia.WindowTarget
+
+	origClick, origGetWindows := uiaClickElement, getWindows
+	uiaClickElement = func(target uia.WindowTarget, automationID string) error {
+		targets = append(targets, target)
+		return nil
+	}
+	getWindows = func(filter ...string) ([]window.WindowInfo, error) {
+		return windows, nil
+	}
+	t.Cleanup(func() {
+		uiaClickElement, getWindows = origClick, origGetWindows
+	})
+	return &targets
+}
+
+func TestClickNative_UsesWindowHandle(t *testing.T) {
+	targets := mockClickNative(t, []window.WindowInfo{{PID: 4321, Handle: 0x1a2b, Title: "Notepad"}})
+	e, _ := newTestExecutor()
+
+	if _, err := e.executeClickNative(context.Background(), map[string]interface{}{"automation_id": "btnOK", "app_name": "notepad"}); err != nil {
+		t.Fatalf("executeClickNative 失败: %v", err)
+	}
+
+	if len(*targets) != 1 {
+		t.Fatalf("应调用 UIA 1 次, 实际为 %d", len(*targets))
+	}
+	if got := (*targets)[0]; got.Handle != 0x1a2b || got.PID != 4321 {
+		t.Errorf("应传入窗口句柄而非 PID: %+v", got)
+	}
+}
+
+func TestClickNative_FallsBackToPID(t *testing.T) {
+	targets := mockClickNative(t, []window.WindowInfo{{PID: 4321, Title: "Notepad"}})
+	e, _ := newTestExecutor()
+
+	if _, err := e.executeClickNative(context.Background(), map[string]interface{}{"automation_id": "btnOK", "window_title": "Notepad"}); err != nil {
+		t.Fatalf("executeClickNative 失败: %v", err)
+	}
+
+	if got := (*targets)[0]; got.Handle != 0 || got.PID != 4321 {
+		t.Errorf("句柄不可用时应按 PID 连接: %+v", got)
+	}
+}
+
+func TestClickNative_ExplicitTarget(t *testing.T) {
+	targets := mockClickNative(t, nil)
+	e, _ := newTestExecutor()
+
+	if _, err := e.executeClickNative(context.Background(), map[string]interface{}{"automation_id": "btnOK", "window_handle": float64(777)}); err != nil {
+		t.Fatalf("executeClickNative 失败: %v", err)
+	}
+	if got := (*targets)[0]; got.Handle != 777 {
+		t.Errorf("应直接使用传入的 window_handle: %+v", got)
+	}
+
+	_, err := e.executeClickNative(context.Background(), map[string]interface{}{"automation_id": "btnOK", "app_name": "missing"})
+	if !errors.Is(err, auto.ErrNotFound) {
+		t.Errorf("未找到窗口应返回 ErrNotFound, 实际为 %v", err)
+	}
+}
+
+func TestParseAutoOptions(t *testing.T) {
+	e, _ := newTestExecutor()
+	opts, err := e.parseAutoOptions(context.Background(), map[string]interface{}{
+		"timeout":      1.5,
+		"threshold":    0.9,
+		"interval_ms":  float64(50),
+		"region":       map[string]interface{}{"x": float64(10), "y": float64(20), "width": float64(300), "height": float64(200)},
+		"display_id":   float64(1),
+		"grayscale":    true,
+		"mask":         false,
+		"multi_scale":  []interface{}{0.5, 1.0},
+		"match_mode":   "Fuzzy",
+		"max_distance": float64(2),
+
+		"min_ocr_confidence": 0.8,
+		"language":           "JA",
+	})
+	if err != nil {
+		t.Fatalf("parseAutoOptions 失败: %v", err)
+	}
+
+	o := auto.ApplyOptions(opts...)
+	if o.Timeout != 1500*time.Millisecond {
+		t.Errorf("Timeout = %v, 期望 1.5s", o.Timeout)
+	}
+	if o.Threshold != 0.9 {
+		t.Errorf("Threshold = %v, 期望 0.9", o.Threshold)
+	}
+	if o.PollInterval() != 50*time.Millisecond {
+		t.Errorf("PollInterval = %v, 期望 50ms", o.PollInterval())
+	}
+	if o.Region == nil || *o.Region != (auto.Region{X: 10, Y: 20, Width: 300, Height: 200}) {
+		t.Errorf("Region = %+v", o.Region)
+	}
+	if o.DisplayID != 1 {
+		t.Errorf("DisplayID = %d, 期望 1", o.DisplayID)
+	}
+	if !o.Grayscale {
+		t.Error("Grayscale 应为 true")
+	}
+	if !o.NoMask {
+		t.Error("mask=false 时 NoMask 应为 true")
+	}
+	if len(o.Scales) != 2 || o.Scales[0] != 0.5 || o.Scales[1] != 1.0 {
+		t.Errorf("Scales = %v, 期望 [0.5 1]", o.Scales)
+	}
+	if o.TextMatchMode != "fuzzy" || o.TextMaxDistance != 2 {
+		t.Errorf("文字匹配 = %q/%d, 期望 fuzzy/2", o.TextMatchMode, o.TextMaxDistance)
+	}
+	if o.Language != "ja" {
+		t.Errorf("Language = %q, 期望 ja", o.Language)
+	}
+	if o.MinOCRConfidence != 0.8 {
+		t.Errorf("MinOCRConfidence = %v, 期望 0.8", o.MinOCRConfidence)
+	}
+
+	// min_ocr_confidence=0 表示不过滤
+	opts, _ = e.parseAutoOptions(context.Background(), map[string]interface{}{"min_ocr_confidence": float64(0)})
+	if o := auto.ApplyOptions(opts...); o.MinOCRConfidence >= 0 {
+		t.Errorf("min_ocr_confidence=0: MinOCRConfidence = %v, 期望 < 0", o.MinOCRConfidence)
+	}
+
+	// display_index 为 display_id 的别名
+	opts, _ = e.parseAutoOptions(context.Background(), map[string]interface{}{"display_index": float64(2)})
+	if o := auto.ApplyOptions(opts...); o.DisplayID != 2 {
+		t.Errorf("display_index: DisplayID = %d, 期望 2", o.DisplayID)
+	}
+
+	// 未指定时保持默认值
+	opts, err = e.parseAutoOptions(context.Background(), map[string]interface{}{"multi_scale": false})
+	if err != nil {
+		t.Fatalf("parseAutoOptions 失败: %v", err)
+	}
+	o = auto.ApplyOptions(opts...)
+	if o.PollInterval() != auto.DefaultPollInterval || o.DisplayID != -1 || o.Region != nil || o.NoMask {
+		t.Errorf("默认值异常: interval=%v display=%d region=%v nomask=%v", o.PollInterval(), o.DisplayID, o.Region, o.NoMask)
+	}
+	if len(o.Scales) != 1 || o.Scales[0] != 1.0 {
+		t.Errorf("multi_scale=false 应仅匹配原始尺寸, Scales = %v", o.Scales)
+	}
+}
+
+func TestParseAutoOptions_Invalid(t *testing.T) {
+	tests := []struct {
+		field   string
+		payload map[string]interface{}
+	}{
+		{"timeout", map[string]interface{}{"timeout": float64(-1)}},
+		{"threshold", map[string]interface{}{"threshold": 1.5}},
+		{"interval_ms", map[string]interface{}{"interval_ms": float64(0)}},
+		{"interval_ms", map[string]interface{}{"interval_ms": "fast"}},
+		{"region", map[string]interface{}{"region": "full"}},
+		{"region.width", map[string]interface{}{"region": map[string]interface{}{"x": float64(0), "y": float64(0), "height": float64(10)}}},
+		{"region", map[string]interface{}{"region": map[string]interface{}{"x": float64(0), "y": float64(0), "width": float64(0), "height": float64(10)}}},
+		{"display_id", map[string]interface{}{"display_id": 1.5}},
+		{"display_id", map[string]interface{}{"display_id": float64(-1)}},
+		{"display_index", map[string]interface{}{"display_index": "second"}},
+		{"grayscale", map[string]interface{}{"grayscale": "yes"}},
+		{"mask", map[string]interface{}{"mask": "alpha"}},
+		{"multi_scale", map[string]interface{}{"multi_scale": []interface{}{1.0, float64(0)}}},
+		{"multi_scale", map[string]interface{}{"multi_scale": "auto"}},
+		{"match_mode", map[string]interface{}{"match_mode": "glob"}},
+		{"match_mode", map[string]interface{}{"match_mode": true}},
+		{"max_distance", map[string]interface{}{"match_mode": "fuzzy", "max_distance": float64(0)}},
+		{"max_distance", map[string]interface{}{"max_distance": 1.5}},
+		{"min_ocr_confidence", map[string]interface{}{"min_ocr_confidence": 1.2}},
+		{"min_ocr_confidence", map[string]interface{}{"min_ocr_confidence": "high"}},
+		{"language", map[string]interface{}{"language": "../ja"}},
+		{"language", map[string]interface{}{"language": float64(1)}},
+	}
+
+	e, _ := newTestExecutor()
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			_, err := e.parseAutoOptions(context.Background(), tt.payload)
+			if !errors.Is(err, auto.ErrParam) {
+				t.Fatalf("期望 ErrParam, 实际为 %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.field) {
+				t.Errorf("错误信息应包含字段名 %q: %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestParseAutoOptions_Window(t *testing.T) {
+	mockClickNative(t, []window.WindowInfo{
+		{PID: 1, Title: "Editor - minimized"},
+		{PID: 2, Title: "Editor", Bounds: auto.Region{X: 100, Y: 50, Width: 800, Height: 600}},
+	})
+	e, _ := newTestExecutor()
+
+	opts, err := e.parseAutoOptions(context.Background(), map[string]interface{}{"window": "Editor"})
+	if err != nil {
+		t.Fatalf("parseAutoOptions 失败: %v", err)
+	}
+	if o := auto.ApplyOptions(opts...); o.Region == nil || *o.Region != (auto.Region{X: 100, Y: 50, Width: 800, Height: 600}) {
+		t.Errorf("应以窗口边界为搜索区域, 实际为 %+v", o.Region)
+	}
+
+	// region 与 window 同时指定时为窗口内相对区域
+	opts, _ = e.parseAutoOptions(context.Background(), map[string]interface{}{
+		"window": "Editor",
+		"region": map[string]interface{}{"x": float64(10), "y": float64(20), "width": float64(30), "height": float64(40)},
+	})
+	if o := auto.ApplyOptions(opts...); o.Region == nil || *o.Region != (auto.Region{X: 110, Y: 70, Width: 30, Height: 40}) {
+		t.Errorf("region 应转换为屏幕坐标, 实际为 %+v", o.Region)
+	}
+
+	mockClickNative(t, nil)
+	_, err = e.runAction(context.Background(), TaskTypeClickImage, map[string]interface{}{"image": "a.png", "window": "Browser"})
+	if !errors.Is(err, auto.ErrNotFound) || !strings.Contains(err.Error(), "Browser") {
+		t.Errorf("窗口不存在应返回包含窗口名的 ErrNotFound, 实际为 %v", err)
+	}
+	if taskErr := classifyError(err); taskErr.Reason != pb.FailureReason_FAILURE_REASON_NOT_FOUND {
+		t.Errorf("Reason = %s, 期望 NOT_FOUND", taskErr.Reason)
+	}
+}
+
+func TestSearchRegionInfo(t *testing.T) {
+	want := BoundsInfo{X: 10, Y: 20, Width: 300, Height: 200}
+	region := searchRegionInfo([]auto.Option{auto.WithRegion(10, 20, 300, 200)})
+	if region == nil || *region != want {
+		t.Errorf("searchRegionInfo = %+v, 期望 %+v", region, want)
+	}
+	if data := withSearchRegion(map[string]interface{}{"exists": true}, region); data["region"] != region {
+		t.Errorf("限定区域时返回数据应包含 region, 实际为 %v", data)
+	}
+
+	// 全屏搜索时不附带 region
+	if region := searchRegionInfo(nil); region != nil {
+		t.Errorf("全屏搜索时应为 nil, 实际为 %+v", region)
+	}
+	if data := withSearchRegion(map[string]interface{}{"exists": true}, nil); len(data) != 1 {
+		t.Errorf("全屏搜索时不应返回 region, 实际为 %v", data)
+	}
+}
+
+func TestResolvePoint(t *testing.T) {
+	mockClickNative(t, []window.WindowInfo{{PID: 2, Title: "Editor", Bounds: auto.Region{X: 100, Y: 50, Width: 800, Height: 600}}})
+
+	tests := []struct {
+		name    string
+		payload map[string]interface{}
+		x, y    int
+	}{
+		{"绝对坐标", map[string]interface{}{"x": float64(10), "y": float64(20)}, 10, 20},
+		{"绝对坐标优先", map[string]interface{}{"x": float64(10), "y": float64(20), "x_pct": 0.5, "y_pct": 0.5}, 10, 20},
+		{"百分比相对窗口", map[string]interface{}{"x_pct": 0.5, "y_pct": 0.25, "window": "Editor"}, 500, 200},
+		{"混合", map[string]interface{}{"x": float64(7), "y_pct": 0.5, "window": "Editor"}, 7, 350},
+		{"右下角限制在区域内", map[string]interface{}{"x_pct": 1.0, "y_pct": 1.0, "window": "Editor"}, 899, 649},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y, err := resolvePoint(tt.payload)
+			if err != nil {
+				t.Fatalf("resolvePoint 失败: %v", err)
+			}
+			if x != tt.x || y != tt.y {
+				t.Errorf("resolvePoint = (%d, %d), 期望 (%d, %d)", x, y, tt.x, tt.y)
+			}
+		})
+	}
+
+	for _, payload := range []map[string]interface{}{
+		{"x_pct": 1.5, "y_pct": 0.5},
+		{"x_pct": -0.1, "y_pct": 0.5},
+		{"x_pct": 0.5},
+	} {
+		if _, _, err := resolvePoint(payload); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("resolvePoint(%v) 应返回 ErrParam, 实际为 %v", payload, err)
+		}
+	}
+}
+
+func TestParseClickOffset(t *testing.T) {
+	mockClickNative(t, []window.WindowInfo{{PID: 2, Title: "Editor", Bounds: auto.Region{X: 100, Y: 50, Width: 800, Height: 600}}})
+	e, _ := newTestExecutor()
+
+	opts, err := e.parseAutoOptions(context.Background(), map[string]interface{}{
+		"window":     "Editor",
+		"offset":     map[string]interface{}{"x": float64(5)},
+		"offset_pct": map[string]interface{}{"x": 0.5, "y": -0.1},
+	})
+	if err != nil {
+		t.Fatalf("parseAutoOptions 失败: %v", err)
+	}
+	if got := auto.ApplyOptions(opts...).ClickOffset; got != (auto.Point{X: 5, Y: -60}) {
+		t.Errorf("ClickOffset = %+v, 期望 {5 -60}", got)
+	}
+
+	_, err = e.parseAutoOptions(context.Background(), map[string]interface{}{"offset_pct": map[string]interface{}{"x": 2.0}})
+	if !errors.Is(err, auto.ErrParam) || !strings.Contains(err.Error(), "offset_pct.x") {
+		t.Errorf("越界的 offset_pct 应返回包含字段名的 ErrParam, 实际为 %v", err)
+	}
+}
+
+// mockFindAllText 替换 OCR 可用性检查和全文识别，记录收到的选项
+func mockFindAllText(t *testing.T, lines []text.TextLine) *auto.Options {
+	t.Helper()
+	got := &auto.Options{}
+	origAvailable, origFind := ocrAvailable, findAllText
+	ocrAvailable = func() bool { return true }
+	findAllText = func(opts ...auto.Option) ([]text.TextLine, error) {
+		*got = *auto.ApplyOptions(opts...)
+		return lines, nil
+	}
+	t.Cleanup(func() {
+		ocrAvailable, findAllText = origAvailable, origFind
+	})
+	return got
+}
+
+func TestTextFindAll(t *testing.T) {
+	opts := mockFindAllText(t, []text.TextLine{
+		{Text: "Save", Confidence: 0.95, Position: auto.Point{X: 110, Y: 220}, Bounds: auto.Region{X: 100, Y: 210, Width: 20, Height: 20}},
+		{Text: "Save As", Confidence: 0.9, Position: auto.Point{X: 110, Y: 260}},
+		{Text: "Cancel", Confidence: 0.8, Position: auto.Point{X: 200, Y: 220}},
+	})
+	e, _ := newTestExecutor()
+
+	payload := map[string]interface{}{
+		"contains": "save",
+		"region":   map[string]interface{}{"x": float64(0), "y": float64(0), "width": float64(800), "height": float64(600)},
+	}
+	out, err := e.executeTextFindAll(context.Background(), payload, &ActionResult{})
+	if err != nil {
+		t.Fatalf("text_find_all 失败: %v", err)
+	}
+	data := out.(map[string]interface{})
+	items := data["items"].([]TextItem)
+	if data["total"] != 2 || len(items) != 2 || data["truncated"] != false {
+		t.Fatalf("结果 = %+v, 期望 2 条 Save 且未截断", data)
+	}
+	if items[0].X != 110 || items[0].Bounds != (BoundsInfo{X: 100, Y: 210, Width: 20, Height: 20}) {
+		t.Errorf("第一条 = %+v", items[0])
+	}
+	if opts.Region == nil || opts.Region.Width != 800 {
+		t.Errorf("region 未传给识别: %+v", opts.Region)
+	}
+	if data["region"] == nil {
+		t.Error("限定区域时应返回 region")
+	}
+}
+
+func TestTextFindAll_MaxItems(t *testing.T) {
+	lines := make([]text.TextLine, DefaultMaxTextItems+10)
+	for i := range lines {
+		lines[i] = text.TextLine{Text: strconv.Itoa(i), Confidence: 0.9}
+	}
+	mockFindAllText(t, lines)
+	e, _ := newTestExecutor()
+
+	out, err := e.executeTextFindAll(context.Background(), map[string]interface{}{}, &ActionResult{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := out.(map[string]interface{})
+	if n := len(data["items"].([]TextItem)); n != DefaultMaxTextItems || data["total"] != len(lines) || data["truncated"] != true {
+		t.Errorf("默认上限: items=%d total=%v truncated=%v", n, data["total"], data["truncated"])
+	}
+
+	out, _ = e.executeTextFindAll(context.Background(), map[string]interface{}{"max_items": float64(3)}, &ActionResult{})
+	if n := len(out.(map[string]interface{})["items"].([]TextItem)); n != 3 {
+		t.Errorf("max_items=3 时 items = %d", n)
+	}
+	for _, bad := range []interface{}{float64(0), float64(1.5), "10"} {
+		if _, err := e.executeTextFindAll(context.Background(), map[string]interface{}{"max_items": bad}, &ActionResult{}); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("max_items=%v 应返回参数错误, 实际 %v", bad, err)
+		}
+	}
+}
+
+func TestAssertImageCount(t *testing.T) {
+	orig := findAllImages
+	t.Cleanup(func() { findAllImages = orig })
+	var limits []int
+	findAllImages = func(source string, maxResults int, opts ...auto.Option) ([]*cv.MatchResult, error) {
+		limits = append(limits, maxResults)
+		var matches []*cv.MatchResult
+		for i, conf := range []float64{0.97, 0.91, 0.88} {
+			x := 100 + i*200
+			matches = append(matches, &cv.MatchResult{
+				Result:     cv.Point{X: x + 10, Y: 60},
+				Rectangle:  cv.Rectangle{TopLeft: cv.Point{X: x, Y: 50}, TopRight: cv.Point{X: x + 20, Y: 50}, BottomLeft: cv.Point{X: x, Y: 70}, BottomRight: cv.Point{X: x + 20, Y: 70}},
+				Confidence: conf,
+			})
+		}
+		if maxResults > 0 && len(matches) > maxResults {
+			matches = matches[:maxResults]
+		}
+		return matches, nil
+	}
+	e, recorder := newTestExecutor()
+
+	e.Execute("count-ok", TaskTypeAssertImageCount, `{"image": "error.png", "expected": 3}`)
+	res := recorder.results("count-ok")
+	if len(res) != 1 || !res[0].Success {
+		t.Fatalf("数量相等时应成功: %+v", res)
+	}
+	if loc := res[0].MatchLocation; loc == nil || loc.X != 100 || loc.Y != 50 || loc.Width != 20 || loc.Confidence < 0.96 {
+		t.Errorf("MatchLocation 应为最佳匹配: %+v", loc)
+	}
+	var data struct {
+		Count   int         `json:"count"`
+		Matches []ImageItem `json:"matches"`
+	}
+	if err := json.Unmarshal([]byte(res[0].ResultJson), &data); err != nil || data.Count != 3 || len(data.Matches) != 3 || data.Matches[2].X != 510 {
+		t.Errorf("结果应包含数量和全部匹配: %s", res[0].ResultJson)
+	}
+	if limits[0] != 4 {
+		t.Errorf("有上限时应多查找一个: %d", limits[0])
+	}
+
+	for _, tt := range []struct{ id, payload string }{
+		{"count-over", `{"image": "error.png", "expected": 2}`},
+		{"count-under", `{"image": "error.png", "min": 4}`},
+		{"count-range", `{"image": "error.png", "min": 0, "max": 1}`},
+	} {
+		e.Execute(tt.id, TaskTypeAssertImageCount, tt.payload)
+		res := recorder.results(tt.id)
+		if len(res) != 1 || res[0].Success || res[0].FailureReason != pb.FailureReason_FAILURE_REASON_ASSERTION_FAILED {
+			t.Fatalf("%s: 数量不在范围内应为 ASSERTION_FAILED: %+v", tt.id, res)
+		}
+		if !strings.Contains(res[0].ResultJson, `"count"`) {
+			t.Errorf("%s: 失败时也应返回匹配数据: %s", tt.id, res[0].ResultJson)
+		}
+	}
+	e.Execute("count-min", TaskTypeAssertImageCount, `{"image": "error.png", "min": 2}`)
+	if res := recorder.results("count-min"); len(res) != 1 || !res[0].Success {
+		t.Errorf("满足 min 时应成功: %+v", res)
+	}
+
+	for _, payload := range []string{
+		`{"image": "error.png"}`,
+		`{"image": "error.png", "expected": 1, "min": 1}`,
+		`{"image": "error.png", "min": 3, "max": 2}`,
+		`{"image": "error.png", "expected": 1.5}`,
+		`{"expected": 1}`,
+	} {
+		if _, err := e.runAction(context.Background(), TaskTypeAssertImageCount, decodePayload(t, payload)); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("%s 应返回 ErrParam: %v", payload, err)
+		}
+	}
+}
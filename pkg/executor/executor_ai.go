@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/go-vgo/robotgo"
+	"github.com/zoeyai/zoeyworker/pkg/auto"
 	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
 )
 
@@ -203,7 +204,7 @@ func executeAIOperation(action string, params map[string]interface{}, strategy s
 	case "type":
 		text, _ := params["text"].(string)
 		if text == "" {
-			return "", fmt.Errorf("缺少 text 参数")
+			return "", auto.Errorf(auto.ErrParam, "缺少 text 参数")
 		}
 		robotgo.TypeStr(text)
 		return fmt.Sprintf("Typed: %s", text), nil
@@ -211,7 +212,7 @@ func executeAIOperation(action string, params map[string]interface{}, strategy s
 	case "press":
 		keys := getStringSlice(params, "keys")
 		if len(keys) == 0 {
-			return "", fmt.Errorf("缺少 keys 参数")
+			return "", auto.Errorf(auto.ErrParam, "缺少 keys 参数")
 		}
 		// 规范化所有键名为小写（robotgo 对大小写敏感）
 		for i, k := range keys {
@@ -266,7 +267,7 @@ func executeAIOperation(action string, params map[string]interface{}, strategy s
 		return fmt.Sprintf("Task ended: %s", result), nil
 
 	default:
-		return "", fmt.Errorf("未知动作: %s", action)
+		return "", auto.Errorf(auto.ErrParam, "未知动作: %s", action)
 	}
 }
 
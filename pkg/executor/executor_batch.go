@@ -1,14 +1,180 @@
 package executor
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	autoimage "github.com/zoeyai/zoeyworker/pkg/auto/image"
+	"github.com/zoeyai/zoeyworker/pkg/auto/input"
 	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+	"github.com/zoeyai/zoeyworker/pkg/grpc"
 	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
 )
 
+// ==================== 批量执行类型 ====================
+
+// StepExecutionResult 步骤执行结果（用于前端回放）
+type StepExecutionResult struct {
+	StepExecutionID string `json:"stepExecutionId,omitempty"` // 步骤执行记录 ID
+	StepID          string `json:"stepId"`                    // 步骤 ID
+	Status          string `json:"status"`                    // SUCCESS, FAILED, SKIPPED
+
+	// 截图（Base64 格式）
+	ScreenshotBefore string `json:"screenshotBefore,omitempty"` // 执行前截图
+	ScreenshotAfter  string `json:"screenshotAfter,omitempty"`  // 执行后截图
+
+	// 执行后画面与执行前完全相同，此时不发送 ScreenshotAfter
+	ScreenshotAfterSameAsBefore bool `json:"screenshotAfterSameAsBefore,omitempty"`
+
+	// 截图缩放比例（截图像素 / 屏幕像素），屏幕坐标乘以该比例即为截图上的位置
+	ScreenshotScale float64 `json:"screenshotScale,omitempty"`
+
+	// 屏幕缩放比例（截图像素 / 鼠标输入坐标，macOS Retina 通常为 2），TargetBounds、ClickPosition 均为截图像素坐标
+	ScreenScaleFactor float64 `json:"screenScaleFactor,omitempty"`
+
+	// 操作信息
+	ActionType string `json:"actionType"` // click, long_press, double_click, input, swipe, assert, wait
+
+	// 目标元素边框（用于回放时高亮显示）
+	TargetBounds *BoundsInfo `json:"targetBounds,omitempty"`
+
+	// 限定的搜索区域（文字查找类操作，或指定了 relative_to 的查找类操作；全屏搜索时省略）
+	SearchRegion *BoundsInfo `json:"searchRegion,omitempty"`
+
+	// relative_to 锚点的边框（目标在锚点指定方向上的 SearchRegion 内查找）
+	AnchorBounds *BoundsInfo `json:"anchorBounds,omitempty"`
+
+	// 失败原因是 relative_to 的锚点未找到（而不是目标未找到）
+	AnchorNotFound bool `json:"anchorNotFound,omitempty"`
+
+	// 实际命中的识别文字（仅文字查找类操作，match_mode 非 exact 时可能与目标文字不同）
+	MatchedText string `json:"matchedText,omitempty"`
+
+	// 目标匹配置信度（0-1，仅图像匹配类操作）
+	Confidence float64 `json:"confidence,omitempty"`
+
+	// 命中时的模板缩放比例（仅图像匹配类操作；长期偏离 1.0 说明录制与执行环境分辨率不一致）
+	MatchScale float64 `json:"matchScale,omitempty"`
+
+	// 实际点击位置（用于回放时显示点击动画）
+	ClickPosition *PositionInfo `json:"clickPosition,omitempty"`
+
+	// 滑动轨迹（仅 swipe 操作）
+	SwipePath *SwipePathInfo `json:"swipePath,omitempty"`
+
+	// 输入内容（仅 input 操作）
+	InputText string `json:"inputText,omitempty"`
+
+	// 输入前已全选并删除输入框原有内容（仅 type_text 指定 clear_before 时）
+	Cleared bool `json:"cleared,omitempty"`
+
+	// 脚本执行输出（仅 script/run_python 操作）
+	Stdout   string `json:"stdout,omitempty"`   // 标准输出
+	Stderr   string `json:"stderr,omitempty"`   // 标准错误
+	ExitCode int    `json:"exitCode,omitempty"` // 退出码
+
+	// 步骤执行期间的录像（record_video 时）：JPEG 帧序列及 manifest.json 的 zip，data URL 或上传后的 url/key
+	Video       string `json:"video,omitempty"`
+	VideoFrames int    `json:"videoFrames,omitempty"` // 录像帧数
+
+	// 取样点周围区域的截图（仅 assert_pixel_color 失败时，PNG data URL）
+	SampleScreenshot string `json:"sampleScreenshot,omitempty"`
+
+	// 上一步骤之后屏幕分辨率或缩放比例发生了变化（如接入或断开扩展坞），此后的绝对坐标和缓存区域可能失效
+	ResolutionChanged  bool            `json:"resolutionChanged,omitempty"`
+	PreviousResolution *ResolutionInfo `json:"previousResolution,omitempty"` // 变化前的分辨率
+	Resolution         *ResolutionInfo `json:"resolution,omitempty"`         // 变化后的分辨率
+
+	// 执行耗时（毫秒）
+	DurationMs int64 `json:"durationMs"`
+
+	// 错误信息（仅失败时）
+	ErrorMessage  string `json:"errorMessage,omitempty"`
+	FailureReason string `json:"failureReason,omitempty"` // NOT_FOUND, MULTIPLE_MATCHES, ASSERTION_FAILED, PARAM_ERROR, SYSTEM_ERROR
+}
+
+// SwipePathInfo 滑动轨迹信息
+type SwipePathInfo struct {
+	StartX int `json:"startX"`
+	StartY int `json:"startY"`
+	EndX   int `json:"endX"`
+	EndY   int `json:"endY"`
+}
+
+// CaseExecutionResult 用例执行结果
+type CaseExecutionResult struct {
+	Success      bool
+	ErrorMessage string
+	TotalSteps   int
+	PassedSteps  int
+	FailedSteps  int
+	Cancelled    bool // 任务被取消，剩余步骤未执行
+
+	DismissedDialogs []grpc.WsDismissedDialog // 执行期间弹窗监视处理的弹窗
+}
+
+// ==================== 映射函数 ====================
+
+// mapTaskTypeToActionType 将任务类型映射为操作类型
+func mapTaskTypeToActionType(taskType string) string {
+	switch taskType {
+	case TaskTypeClickImage, TaskTypeClickText, TaskTypeClickNative, TaskTypeMouseClick, TaskTypeGridClick:
+		return "click"
+	case TaskTypeTypeText:
+		return "input"
+	case TaskTypeKeyPress, TaskTypeKeyDown, TaskTypeKeyUp:
+		return "input"
+	case TaskTypeWaitImage, TaskTypeWaitText, TaskTypeWaitTime, TaskTypeWaitForWindow, TaskTypeWaitForHotkey:
+		return "wait"
+	case TaskTypeAssertImage, TaskTypeAssertImageCount, TaskTypeAssertText, TaskTypeAssertPixelColor, TaskTypeAssertClipboard, TaskTypeAssertWindowTitle, TaskTypeAssertProcessRunning, TaskTypeImageExists, TaskTypeTextExists:
+		return "assert"
+	case TaskTypeRunPython:
+		return "script"
+	default:
+		return "other"
+	}
+}
+
+// mapFailureReasonToString 将失败原因枚举映射为字符串
+func mapFailureReasonToString(reason pb.FailureReason) string {
+	switch reason {
+	case pb.FailureReason_FAILURE_REASON_NOT_FOUND:
+		return "NOT_FOUND"
+	case pb.FailureReason_FAILURE_REASON_MULTIPLE_MATCHES:
+		return "MULTIPLE_MATCHES"
+	case pb.FailureReason_FAILURE_REASON_ASSERTION_FAILED:
+		return "ASSERTION_FAILED"
+	case pb.FailureReason_FAILURE_REASON_PARAM_ERROR:
+		return "PARAM_ERROR"
+	case pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR:
+		return "SYSTEM_ERROR"
+	default:
+		return ""
+	}
+}
+
+// mapTaskStatusToString 将任务状态枚举映射为字符串
+func mapTaskStatusToString(status pb.TaskStatus) string {
+	switch status {
+	case pb.TaskStatus_TASK_STATUS_SUCCESS:
+		return "SUCCESS"
+	case pb.TaskStatus_TASK_STATUS_FAILED:
+		return "FAILED"
+	case pb.TaskStatus_TASK_STATUS_SKIPPED:
+		return "SKIPPED"
+	case pb.TaskStatus_TASK_STATUS_CANCELLED:
+		return "CANCELLED"
+	case pb.TaskStatus_TASK_STATUS_TIMEOUT:
+		return "FAILED" // 超时也算失败
+	default:
+		return "UNKNOWN"
+	}
+}
+
 // ==================== 批量执行 ====================
 
 // executeDebugCase 执行调试用例（顺序执行多个步骤）
@@ -22,24 +188,38 @@ func (e *Executor) executeDebugCase(taskID string, payload map[string]interface{
 	}
 
 	stopOnFail, _ := payload["stop_on_fail"].(bool)
-	// 是否启用截图（默认启用，可通过 capture_screenshots: false 禁用）
-	captureScreenshots := true
-	if cs, ok := payload["capture_screenshots"].(bool); ok {
-		captureScreenshots = cs
-	}
-	// 截图质量（JPEG 质量 1-100，默认 60 以减小传输量）
-	screenshotQuality := 60
-	if sq, ok := payload["screenshot_quality"].(float64); ok && sq > 0 && sq <= 100 {
-		screenshotQuality = int(sq)
-	}
+	// 调试用例默认标注失败截图
+	shotOpts := e.parseScreenshotOptions(payload, true)
+	shotOpts.Video = parseVideoOptions(payload, shotOpts.Quality)
 
 	totalSteps := len(stepsRaw)
 
-	log("INFO", fmt.Sprintf("[Task:%s] debug_case 开始，共 %d 个步骤, 截图=%v, 质量=%d", taskID, totalSteps, captureScreenshots, screenshotQuality))
+	dialogs, err := newDialogWatcher(payload)
+	if err != nil {
+		e.sendTaskResultWithError(taskID, classifyError(err), nil, startTime)
+		return
+	}
+
+	log("INFO", fmt.Sprintf("[Task:%s] debug_case 开始，共 %d 个步骤, 截图=%s, 质量=%d", taskID, totalSteps, shotOpts.Mode, shotOpts.Quality))
+	watch := newDisplayWatch(payload)
 
 	var completedSteps, passedSteps, failedSteps int32
 
+	ctx, cancel := e.taskContext(taskID)
+	defer cancel()
+	defer e.releaseHeldKeys(taskID)
+	ctx = withDialogWatcher(ctx, dialogs)
+	stopDialogs := dialogs.start(ctx, taskID)
+	defer stopDialogs()
+
 	for i, stepRaw := range stepsRaw {
+		if ctx.Err() != nil {
+			shotOpts.waitUploads()
+			e.sendTaskProgress(taskID, int32(totalSteps), completedSteps, passedSteps, failedSteps, "", "CANCELLED")
+			e.sendTaskCancelled(taskID, startTime)
+			return
+		}
+
 		stepMap, ok := stepRaw.(map[string]interface{})
 		if !ok {
 			log("WARN", fmt.Sprintf("[Task:%s] 步骤 %d 格式错误", taskID, i+1))
@@ -60,7 +240,8 @@ func (e *Executor) executeDebugCase(taskID string, payload map[string]interface{
 		e.sendTaskProgress(taskID, int32(totalSteps), completedSteps, passedSteps, failedSteps, stepTaskType, "RUNNING")
 
 		// 执行步骤（带前后截图）
-		stepResult := e.executeStepWithScreenshots(stepExecutionID, stepID, stepTaskType, stepParams, captureScreenshots, screenshotQuality)
+		stepResult := e.executeStepWithScreenshots(ctx, stepExecutionID, stepID, stepTaskType, stepParams, shotOpts, watch)
+		e.recordHistoryStep(taskID, stepResult)
 
 		completedSteps++
 
@@ -69,14 +250,17 @@ func (e *Executor) executeDebugCase(taskID string, payload map[string]interface{
 			log("ERROR", fmt.Sprintf("[Task:%s] 步骤 %s 执行失败: %s", taskID, stepID, stepResult.ErrorMessage))
 
 			// 发送步骤失败结果（使用增强版）
-			e.sendStepResultV2(stepTaskID, stepResult)
+			e.sendStepResultWithUpload(stepTaskID, stepResult, shotOpts)
 
-			if stopOnFail {
-				log("INFO", fmt.Sprintf("[Task:%s] stop_on_fail=true，停止执行", taskID))
+			if (stopOnFail || watch.tripped) && ctx.Err() == nil {
+				log("INFO", fmt.Sprintf("[Task:%s] 停止执行（stop_on_fail=%v，分辨率变化=%v）", taskID, stopOnFail, watch.tripped))
+				shotOpts.waitUploads()
+				stopDialogs()
 				// 发送整体任务失败结果
 				e.sendTaskProgress(taskID, int32(totalSteps), completedSteps, passedSteps, failedSteps, stepTaskType, "FAILED")
 				taskErr := newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_UNSPECIFIED, stepResult.ErrorMessage)
-				e.sendTaskResultWithError(taskID, taskErr, nil, startTime)
+				resultJSON, _ := json.Marshal(withDismissedDialogs(map[string]interface{}{}, dialogs.drain("")))
+				e.sendTaskResultWithError(taskID, taskErr, nil, startTime, string(resultJSON))
 				return
 			}
 		} else {
@@ -84,11 +268,18 @@ func (e *Executor) executeDebugCase(taskID string, payload map[string]interface{
 			log("INFO", fmt.Sprintf("[Task:%s] 步骤 %s 执行成功", taskID, stepID))
 
 			// 发送步骤成功结果（使用增强版）
-			e.sendStepResultV2(stepTaskID, stepResult)
+			e.sendStepResultWithUpload(stepTaskID, stepResult, shotOpts)
 		}
 	}
 
 	// 所有步骤执行完成
+	shotOpts.waitUploads()
+	stopDialogs()
+	if ctx.Err() != nil {
+		e.sendTaskProgress(taskID, int32(totalSteps), completedSteps, passedSteps, failedSteps, "", "CANCELLED")
+		e.sendTaskCancelled(taskID, startTime)
+		return
+	}
 	log("INFO", fmt.Sprintf("[Task:%s] debug_case 完成: passed=%d, failed=%d", taskID, passedSteps, failedSteps))
 
 	// 发送最终进度和结果
@@ -99,15 +290,15 @@ func (e *Executor) executeDebugCase(taskID string, payload map[string]interface{
 	e.sendTaskProgress(taskID, int32(totalSteps), completedSteps, passedSteps, failedSteps, "", finalStatus)
 
 	// 发送整体任务结果
-	resultJSON, _ := json.Marshal(map[string]interface{}{
+	resultJSON, _ := json.Marshal(withDismissedDialogs(map[string]interface{}{
 		"total_steps":     totalSteps,
 		"completed_steps": completedSteps,
 		"passed_steps":    passedSteps,
 		"failed_steps":    failedSteps,
-	})
+	}, dialogs.drain("")))
 
 	if failedSteps > 0 {
-		e.sendTaskResultWithError(taskID, newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_UNSPECIFIED, fmt.Sprintf("部分步骤失败: %d/%d", failedSteps, totalSteps)), nil, startTime)
+		e.sendTaskResultWithError(taskID, newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_UNSPECIFIED, fmt.Sprintf("部分步骤失败: %d/%d", failedSteps, totalSteps)), nil, startTime, string(resultJSON))
 	} else {
 		e.sendTaskResultSuccess(taskID, string(resultJSON), nil, startTime)
 	}
@@ -128,8 +319,15 @@ func (e *Executor) executeDebugCase(taskID string, payload map[string]interface{
 //	    }
 //	  ],
 //	  "stop_on_fail": true/false,
-//	  "capture_screenshots": true/false,
-//	  "screenshot_quality": 60
+//	  "stream_case_results": true/false,  // 每个用例完成后发送用例中间结果（见 grpc.WsCaseResult）
+//	  "screenshot_mode": "always" | "on_failure" | "never",
+//	  "screenshot_quality": 60,
+//	  "screenshot_max_width": 1280,
+//	  "annotate_failures": true/false,
+//	  "upload_url": "https://...",  // 可选，截图上传地址（multipart POST，返回 url/key）
+//	  "upload_token": "xxx",        // 可选，上传时的 Bearer token
+//	  "dialog_watcher": {...},      // 可选，后台自动处理意外弹窗（见 newDialogWatcher），默认关闭
+//	  "generate_report": true/false // 可选，完成后在本地生成 JUnit XML 和 HTML 报告（见 SetReportDir），路径见结果的 report
 //	}
 func (e *Executor) executeExecutePlan(taskID string, payload map[string]interface{}, startTime time.Time) {
 	planExecutionID, _ := payload["plan_execution_id"].(string)
@@ -144,21 +342,38 @@ func (e *Executor) executeExecutePlan(taskID string, payload map[string]interfac
 	}
 
 	stopOnFail, _ := payload["stop_on_fail"].(bool)
-	captureScreenshots := true
-	if cs, ok := payload["capture_screenshots"].(bool); ok {
-		captureScreenshots = cs
-	}
-	screenshotQuality := 60
-	if sq, ok := payload["screenshot_quality"].(float64); ok && sq > 0 && sq <= 100 {
-		screenshotQuality = int(sq)
+	streamCaseResults, _ := payload["stream_case_results"].(bool)
+	shotOpts := e.parseScreenshotOptions(payload, false)
+	dialogs, err := newDialogWatcher(payload)
+	if err != nil {
+		e.sendTaskResultWithError(taskID, classifyError(err), nil, startTime)
+		return
 	}
 
 	totalCases := len(casesRaw)
 	log("INFO", fmt.Sprintf("[Task:%s] execute_plan 开始，计划=%s，共 %d 个用例", taskID, planID, totalCases))
+	watch := newDisplayWatch(payload)
 
 	var completedCases, passedCases, failedCases int32
+	var dismissed []grpc.WsDismissedDialog
+
+	ctx, cancel := e.taskContext(taskID)
+	defer cancel()
+	defer e.releaseHeldKeys(taskID)
+	ctx = withDialogWatcher(ctx, dialogs)
+	stopDialogs := dialogs.start(ctx, taskID)
+	defer stopDialogs()
+	var report *planReport
+	if generate, _ := payload["generate_report"].(bool); generate {
+		report = newPlanReport(planID, planExecutionID)
+		ctx = withPlanReport(ctx, report)
+	}
 
 	for caseIdx, caseRaw := range casesRaw {
+		if ctx.Err() != nil {
+			break
+		}
+
 		caseMap, ok := caseRaw.(map[string]interface{})
 		if !ok {
 			log("WARN", fmt.Sprintf("[Task:%s] 用例 %d 格式错误", taskID, caseIdx+1))
@@ -176,9 +391,21 @@ func (e *Executor) executeExecutePlan(taskID string, payload map[string]interfac
 		}
 
 		log("INFO", fmt.Sprintf("[Task:%s] 执行用例 %d/%d: %s (id=%s)", taskID, caseIdx+1, totalCases, caseName, caseID))
+		e.recordCaseProgress(taskID, caseIdx, totalCases, caseID, completedCases, passedCases, failedCases)
 
 		// 执行用例中的所有步骤
-		caseResult := e.executeCaseSteps(taskID, caseExecutionID, caseID, stepsRaw, stopOnFail, captureScreenshots, screenshotQuality)
+		caseStartTime := time.Now()
+		report.beginCase(caseID, caseExecutionID, caseName, stepsRaw)
+		caseResult := e.executeCaseSteps(ctx, taskID, caseExecutionID, caseID, stepsRaw, stopOnFail, shotOpts, watch)
+		report.endCase(caseResult, time.Since(caseStartTime))
+		dismissed = append(dismissed, caseResult.DismissedDialogs...)
+
+		if streamCaseResults {
+			e.sendCaseResult(taskID, planExecutionID, caseExecutionID, caseID, caseResult, time.Since(caseStartTime))
+		}
+		if caseResult.Cancelled {
+			break
+		}
 
 		completedCases++
 		if caseResult.Success {
@@ -196,33 +423,62 @@ func (e *Executor) executeExecutePlan(taskID string, payload map[string]interfac
 	}
 
 	// 所有用例执行完成
+	shotOpts.waitUploads()
+	stopDialogs()
+	if ctx.Err() != nil {
+		e.sendTaskCancelled(taskID, startTime)
+		return
+	}
 	log("INFO", fmt.Sprintf("[Task:%s] execute_plan 完成: passed=%d, failed=%d", taskID, passedCases, failedCases))
 
 	// 发送整体结果
-	resultJSON, _ := json.Marshal(map[string]interface{}{
+	resultData := withDismissedDialogs(map[string]interface{}{
 		"plan_execution_id": planExecutionID,
 		"plan_id":           planID,
 		"total_cases":       totalCases,
 		"completed_cases":   completedCases,
 		"passed_cases":      passedCases,
 		"failed_cases":      failedCases,
-	})
+	}, append(dismissed, dialogs.drain("")...))
+	if report != nil {
+		// 报告写入失败不影响计划结果
+		files, err := e.writePlanReport(report, taskID)
+		if err != nil {
+			log("WARN", fmt.Sprintf("[Task:%s] 生成测试报告失败: %v", taskID, err))
+			resultData["report_error"] = err.Error()
+		} else {
+			log("INFO", fmt.Sprintf("[Task:%s] 测试报告已生成: %s", taskID, files.HTML))
+			resultData["report"] = files
+		}
+	}
+	resultJSON, _ := json.Marshal(resultData)
 
 	if failedCases > 0 {
-		e.sendTaskResultWithError(taskID, newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_UNSPECIFIED, fmt.Sprintf("部分用例失败: %d/%d", failedCases, totalCases)), nil, startTime)
+		e.sendTaskResultWithError(taskID, newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_UNSPECIFIED, fmt.Sprintf("部分用例失败: %d/%d", failedCases, totalCases)), nil, startTime, string(resultJSON))
 	} else {
 		e.sendTaskResultSuccess(taskID, string(resultJSON), nil, startTime)
 	}
 }
 
 // executeCaseSteps 执行用例中的所有步骤（内部方法，供 execute_plan 和 execute_case 使用）
-func (e *Executor) executeCaseSteps(taskID, caseExecutionID, caseID string, stepsRaw []interface{}, stopOnFail, captureScreenshots bool, screenshotQuality int) *CaseExecutionResult {
+// ctx 取消后不再执行剩余步骤，返回 Cancelled 的结果
+func (e *Executor) executeCaseSteps(ctx context.Context, taskID, caseExecutionID, caseID string, stepsRaw []interface{}, stopOnFail bool, shotOpts screenshotOptions, watch *displayWatch) *CaseExecutionResult {
 	result := &CaseExecutionResult{
 		Success:    true,
 		TotalSteps: len(stepsRaw),
 	}
+	defer func() {
+		result.DismissedDialogs = dialogWatcherFromContext(ctx).drain(caseID)
+	}()
 
 	for i, stepRaw := range stepsRaw {
+		if ctx.Err() != nil {
+			result.Success = false
+			result.Cancelled = true
+			result.ErrorMessage = "任务已取消"
+			return result
+		}
+
 		stepMap, ok := stepRaw.(map[string]interface{})
 		if !ok {
 			log("WARN", fmt.Sprintf("[Task:%s] 步骤 %d 格式错误", taskID, i+1))
@@ -243,28 +499,38 @@ func (e *Executor) executeCaseSteps(taskID, caseExecutionID, caseID string, step
 		e.sendTaskProgress(taskID, int32(len(stepsRaw)), int32(i), int32(result.PassedSteps), int32(result.FailedSteps), stepTaskType, "RUNNING")
 
 		// 执行步骤（带前后截图）
-		stepResult := e.executeStepWithScreenshots(stepExecutionID, stepID, stepTaskType, stepParams, captureScreenshots, screenshotQuality)
+		stepResult := e.executeStepWithScreenshots(ctx, stepExecutionID, stepID, stepTaskType, stepParams, shotOpts, watch)
+		e.recordHistoryStep(taskID, stepResult)
+		planReportFromContext(ctx).addStep(i, stepResult)
 
 		if stepResult.Status != "SUCCESS" {
 			result.FailedSteps++
-			taskErr := classifyError(fmt.Errorf("%s", stepResult.ErrorMessage))
 
 			// 发送步骤失败结果
-			e.sendStepResultV2(stepTaskID, stepResult)
+			e.sendStepResultWithUpload(stepTaskID, stepResult, shotOpts)
 
-			if stopOnFail {
+			// fail_on_resolution_change 时分辨率变化总是结束用例
+			if (stopOnFail || watch.tripped) && ctx.Err() == nil {
 				result.Success = false
-				result.ErrorMessage = taskErr.Message
+				result.ErrorMessage = stepResult.ErrorMessage
 				return result
 			}
 		} else {
 			result.PassedSteps++
 
 			// 发送步骤成功结果
-			e.sendStepResultV2(stepTaskID, stepResult)
+			e.sendStepResultWithUpload(stepTaskID, stepResult, shotOpts)
 		}
 	}
 
+	// 最后一个步骤执行中被取消
+	if ctx.Err() != nil {
+		result.Success = false
+		result.Cancelled = true
+		result.ErrorMessage = "任务已取消"
+		return result
+	}
+
 	// 如果有失败的步骤，标记用例失败
 	if result.FailedSteps > 0 {
 		result.Success = false
@@ -292,33 +558,43 @@ func (e *Executor) executeExecuteCase(taskID string, payload map[string]interfac
 	if sf, ok := payload["stop_on_fail"].(bool); ok {
 		stopOnFail = sf
 	}
-	captureScreenshots := true
-	if cs, ok := payload["capture_screenshots"].(bool); ok {
-		captureScreenshots = cs
-	}
-	screenshotQuality := 60
-	if sq, ok := payload["screenshot_quality"].(float64); ok && sq > 0 && sq <= 100 {
-		screenshotQuality = int(sq)
+	shotOpts := e.parseScreenshotOptions(payload, false)
+	shotOpts.Video = parseVideoOptions(payload, shotOpts.Quality)
+	dialogs, err := newDialogWatcher(payload)
+	if err != nil {
+		e.sendTaskResultWithError(taskID, classifyError(err), nil, startTime)
+		return
 	}
 
 	log("INFO", fmt.Sprintf("[Task:%s] execute_case 开始，用例=%s，共 %d 个步骤", taskID, caseID, len(stepsRaw)))
 
 	// 执行所有步骤
-	result := e.executeCaseSteps(taskID, caseExecutionID, caseID, stepsRaw, stopOnFail, captureScreenshots, screenshotQuality)
+	ctx, cancel := e.taskContext(taskID)
+	defer cancel()
+	defer e.releaseHeldKeys(taskID)
+	ctx = withDialogWatcher(ctx, dialogs)
+	stopDialogs := dialogs.start(ctx, taskID)
+	defer stopDialogs()
+	result := e.executeCaseSteps(ctx, taskID, caseExecutionID, caseID, stepsRaw, stopOnFail, shotOpts, newDisplayWatch(payload))
+	shotOpts.waitUploads()
+	if result.Cancelled {
+		e.sendTaskCancelled(taskID, startTime)
+		return
+	}
 
 	log("INFO", fmt.Sprintf("[Task:%s] execute_case 完成: passed=%d, failed=%d", taskID, result.PassedSteps, result.FailedSteps))
 
 	// 发送结果
-	resultJSON, _ := json.Marshal(map[string]interface{}{
+	resultJSON, _ := json.Marshal(withDismissedDialogs(map[string]interface{}{
 		"case_execution_id": caseExecutionID,
 		"case_id":           caseID,
 		"total_steps":       result.TotalSteps,
 		"passed_steps":      result.PassedSteps,
 		"failed_steps":      result.FailedSteps,
-	})
+	}, result.DismissedDialogs))
 
 	if !result.Success {
-		e.sendTaskResultWithError(taskID, newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_UNSPECIFIED, result.ErrorMessage), nil, startTime)
+		e.sendTaskResultWithError(taskID, newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_UNSPECIFIED, result.ErrorMessage), nil, startTime, string(resultJSON))
 	} else {
 		e.sendTaskResultSuccess(taskID, string(resultJSON), nil, startTime)
 	}
@@ -326,45 +602,160 @@ func (e *Executor) executeExecuteCase(taskID string, payload map[string]interfac
 
 // ==================== 步骤截图执行 ====================
 
+// 截图模式
+const (
+	ScreenshotModeAlways    = "always"     // 每个步骤都发送前后截图
+	ScreenshotModeOnFailure = "on_failure" // 仅步骤失败时发送前后截图
+	ScreenshotModeNever     = "never"      // 不截图
+)
+
+// screenshotOptions 步骤截图选项
+type screenshotOptions struct {
+	Mode             string // 截图模式（screenshot_mode，默认 always）
+	Quality          int    // JPEG 质量 1-100（screenshot_quality，未指定时使用执行器配置的默认值）
+	MaxWidth         int    // 截图最大宽度，超出时等比缩小（screenshot_max_width，<= 0 不缩放）
+	AnnotateFailures bool   // 图像未找到时标注失败截图（annotate_failures）
+
+	Uploader *screenshotUploader // 截图上传器（upload_url，为空时内联 base64）
+	Video    *videoOptions       // 步骤录像（record_video，仅 debug_case / execute_case），nil 表示不录像
+}
+
+// enabled 是否需要截图
+func (o screenshotOptions) enabled() bool {
+	return o.Mode != ScreenshotModeNever
+}
+
+// waitUploads 等待所有截图上传完成（在发送任务最终结果前调用，保证步骤结果先到达）
+func (o screenshotOptions) waitUploads() {
+	if o.Uploader != nil {
+		o.Uploader.wait()
+	}
+}
+
+// parseScreenshotOptions 解析批量任务的截图选项
+// 兼容旧参数 capture_screenshots: false 等价于 screenshot_mode: "never"
+// screenshot_max_width、screenshot_quality 未指定时使用执行器配置的默认值
+func (e *Executor) parseScreenshotOptions(payload map[string]interface{}, annotateDefault bool) screenshotOptions {
+	opts := screenshotOptions{
+		Mode:             ScreenshotModeAlways,
+		Quality:          e.screenshotQuality,
+		MaxWidth:         e.screenshotMaxWidth,
+		AnnotateFailures: annotateDefault,
+	}
+	if cs, ok := payload["capture_screenshots"].(bool); ok && !cs {
+		opts.Mode = ScreenshotModeNever
+	}
+	if mode, ok := payload["screenshot_mode"].(string); ok && mode != "" {
+		switch mode {
+		case ScreenshotModeAlways, ScreenshotModeOnFailure, ScreenshotModeNever:
+			opts.Mode = mode
+		default:
+			log("WARN", fmt.Sprintf("未知的 screenshot_mode: %s，使用 %s", mode, opts.Mode))
+		}
+	}
+	if sq, ok := payload["screenshot_quality"].(float64); ok && sq > 0 && sq <= 100 {
+		opts.Quality = int(sq)
+	}
+	if mw, ok := payload["screenshot_max_width"].(float64); ok {
+		opts.MaxWidth = int(mw)
+	}
+	if af, ok := payload["annotate_failures"].(bool); ok {
+		opts.AnnotateFailures = af
+	}
+	if uploadURL, ok := payload["upload_url"].(string); ok && uploadURL != "" && opts.enabled() {
+		token, _ := payload["upload_token"].(string)
+		opts.Uploader = newScreenshotUploader(uploadURL, token, defaultUploadWorkers)
+	}
+	return opts
+}
+
 // executeStepWithScreenshots 执行单个步骤并在前后截图
 // 返回完整的 StepExecutionResult，供 executeDebugCase 和 executeCaseSteps 共用
 func (e *Executor) executeStepWithScreenshots(
+	ctx context.Context,
 	stepExecutionID, stepID, stepTaskType string,
 	stepParams map[string]interface{},
-	captureScreenshots bool, screenshotQuality int,
+	shotOpts screenshotOptions,
+	watch *displayWatch,
 ) *StepExecutionResult {
-	// 1. 执行前截图
-	var screenshotBefore string
-	if captureScreenshots {
-		if sb, err := screen.CaptureScreenToBase64(screenshotQuality); err == nil {
-			screenshotBefore = sb
-		}
+	// 0. 检查分辨率是否变化（fail_on_resolution_change 时不执行步骤，直接失败）
+	change := watch.check()
+
+	// 1. 执行前截图（on_failure 模式也需提前截取，成功后再丢弃）
+	var before, after *stepScreenshot
+	if shotOpts.enabled() {
+		before, _ = captureStepScreenshot(shotOpts)
 	}
 
 	// 2. 执行步骤
 	stepStartTime := time.Now()
-	actionResult := e.executeSingleStepV2(stepTaskType, stepParams)
+	var actionResult *ActionResult
+	var video *stepVideo
+	if change != nil && watch.failOnChange {
+		actionResult = &ActionResult{Error: change.err()}
+	} else {
+		// 操作鼠标键盘的步骤执行期间暂停弹窗监视
+		resume := dialogWatcherFromContext(ctx).pause(stepTaskType)
+		recorder := startStepRecording(shotOpts.Video)
+		actionResult = e.executeSingleStepV2(ctx, stepTaskType, stepParams)
+		video = recorder.stop()
+		resume()
+	}
 	durationMs := time.Since(stepStartTime).Milliseconds()
 
-	// 3. 执行后截图
-	var screenshotAfter string
-	if captureScreenshots {
-		if sa, err := screen.CaptureScreenToBase64(screenshotQuality); err == nil {
-			screenshotAfter = sa
+	// 3. 执行后截图（on_failure 模式仅失败时截取）
+	if shotOpts.Mode == ScreenshotModeAlways || (shotOpts.Mode == ScreenshotModeOnFailure && !actionResult.Success) {
+		after, _ = captureStepScreenshot(shotOpts)
+	}
+	if shotOpts.Mode == ScreenshotModeOnFailure && actionResult.Success {
+		before = nil
+	}
+
+	var screenshotBefore, screenshotAfter string
+	var screenshotScale float64
+	var sameAsBefore bool
+	if before != nil {
+		screenshotBefore = before.Data
+		screenshotScale = before.Scale
+	}
+	if after != nil {
+		// 画面未变化（如仅断言的步骤）时只发送执行前截图
+		if before != nil && before.Hash == after.Hash {
+			sameAsBefore = true
+		} else {
+			screenshotAfter = after.Data
+			screenshotScale = after.Scale
 		}
 	}
 
 	// 4. 构建步骤执行结果
 	stepResult := &StepExecutionResult{
-		StepExecutionID:  stepExecutionID,
-		StepID:           stepID,
-		ActionType:       mapTaskTypeToActionType(stepTaskType),
-		ScreenshotBefore: screenshotBefore,
-		ScreenshotAfter:  screenshotAfter,
-		TargetBounds:     actionResult.TargetBounds,
-		ClickPosition:    actionResult.ClickPosition,
-		InputText:        actionResult.InputText,
-		DurationMs:       durationMs,
+		StepExecutionID:             stepExecutionID,
+		StepID:                      stepID,
+		ActionType:                  mapTaskTypeToActionType(stepTaskType),
+		ScreenshotBefore:            screenshotBefore,
+		ScreenshotAfter:             screenshotAfter,
+		ScreenshotScale:             screenshotScale,
+		ScreenScaleFactor:           auto.GetScreenScaleFactor(),
+		ScreenshotAfterSameAsBefore: sameAsBefore,
+		TargetBounds:                actionResult.TargetBounds,
+		Confidence:                  actionResult.Confidence,
+		MatchScale:                  actionResult.Scale,
+		SearchRegion:                actionResult.SearchRegion,
+		AnchorBounds:                actionResult.AnchorBounds,
+		MatchedText:                 actionResult.MatchedText,
+		ClickPosition:               actionResult.ClickPosition,
+		InputText:                   actionResult.InputText,
+		DurationMs:                  durationMs,
+	}
+	if change != nil {
+		change.apply(stepResult)
+	}
+	if video != nil {
+		stepResult.Video, stepResult.VideoFrames = video.Data, video.Frames
+		if shotOpts.Uploader == nil {
+			stepResult.Video = inlineVideo(video.Data, stepID)
+		}
 	}
 
 	// 提取脚本执行输出（Python 等）
@@ -381,6 +772,12 @@ func (e *Executor) executeStepWithScreenshots(
 			} else if exitCode, ok := dataMap["exit_code"].(float64); ok {
 				stepResult.ExitCode = int(exitCode)
 			}
+			if shot, ok := dataMap["sample_screenshot"].(string); ok {
+				stepResult.SampleScreenshot = shot
+			}
+			if cleared, ok := dataMap["cleared"].(bool); ok && stepTaskType == TaskTypeTypeText {
+				stepResult.Cleared = cleared
+			}
 		}
 	}
 
@@ -389,6 +786,16 @@ func (e *Executor) executeStepWithScreenshots(
 		stepResult.Status = mapTaskStatusToString(taskErr.Status)
 		stepResult.ErrorMessage = taskErr.Message
 		stepResult.FailureReason = mapFailureReasonToString(taskErr.Reason)
+		stepResult.AnchorNotFound = errors.Is(actionResult.Error, ErrAnchorNotFound)
+
+		// 图像未找到时用标注后的截图替换执行后截图
+		if shotOpts.enabled() && shotOpts.AnnotateFailures && !stepResult.AnchorNotFound && isImageNotFound(stepTaskType, taskErr) {
+			if annotated, scale := e.annotateImageFailure(stepParams, actionResult.SearchRegion, shotOpts); annotated != "" {
+				stepResult.ScreenshotAfter = annotated
+				stepResult.ScreenshotAfterSameAsBefore = false
+				stepResult.ScreenshotScale = scale
+			}
+		}
 	} else {
 		stepResult.Status = "SUCCESS"
 	}
@@ -396,11 +803,134 @@ func (e *Executor) executeStepWithScreenshots(
 	return stepResult
 }
 
+// executeSingleStepV2 执行单个步骤，返回包含点击位置、目标边界等详细信息的结果
+func (e *Executor) executeSingleStepV2(ctx context.Context, taskType string, payload map[string]interface{}) *ActionResult {
+	mouseX, mouseY := input.GetMousePosition()
+
+	result, err := e.runAction(ctx, taskType, payload)
+	result.Success = err == nil
+	result.Error = err
+
+	if textStr, ok := payload["text"].(string); ok && taskType == TaskTypeTypeText {
+		if masked, _ := payload["mask_in_logs"].(bool); masked {
+			textStr = maskedText
+		}
+		result.InputText = textStr
+	}
+	if err != nil && result.ClickPosition == nil {
+		result.ClickPosition = &PositionInfo{X: mouseX, Y: mouseY}
+	}
+	return result
+}
+
+// isImageNotFound 判断是否为图像类步骤的未找到失败（含等待超时）
+func isImageNotFound(taskType string, taskErr *TaskError) bool {
+	switch taskType {
+	case TaskTypeClickImage, TaskTypeWaitImage, TaskTypeAssertImage:
+	default:
+		return false
+	}
+	return taskErr.Reason == pb.FailureReason_FAILURE_REASON_NOT_FOUND ||
+		taskErr.Reason == pb.FailureReason_FAILURE_REASON_ASSERTION_FAILED ||
+		taskErr.Status == pb.TaskStatus_TASK_STATUS_TIMEOUT
+}
+
+// annotateImageFailure 生成图像查找失败的标注截图（搜索区域 + 最佳候选及分数）
+// region 为执行时实际的搜索区域（relative_to 计算得出，为 nil 时按 params 解析）
+// 返回编码后的截图及其缩放比例，失败时返回空字符串，调用方保留原始截图
+func (e *Executor) annotateImageFailure(params map[string]interface{}, region *BoundsInfo, shotOpts screenshotOptions) (string, float64) {
+	imagePath, _ := params["image"].(string)
+	if imagePath == "" {
+		return "", 0
+	}
+
+	opts, err := e.parseAutoOptions(context.Background(), params)
+	if err != nil {
+		return "", 0
+	}
+	if region != nil {
+		opts = append(opts, auto.WithRegion(region.X, region.Y, region.Width, region.Height))
+	}
+	img, candidate, err := autoimage.AnnotateSearch(imagePath, opts...)
+	if err != nil {
+		log("WARN", fmt.Sprintf("生成失败标注截图失败: %v", err))
+		return "", 0
+	}
+	if candidate != nil {
+		log("DEBUG", fmt.Sprintf("图像未找到，最佳候选 confidence=%.3f at (%d,%d)", candidate.Confidence, candidate.Result.X, candidate.Result.Y))
+	}
+
+	img, scale, err := screen.DownscaleImage(img, shotOpts.MaxWidth)
+	if err != nil {
+		return "", 0
+	}
+	encoded, err := screen.ImageToBase64(img, "jpeg", shotOpts.Quality)
+	if err != nil {
+		return "", 0
+	}
+	return encoded, scale
+}
+
 // ==================== 结果发送 ====================
 
+// sendCaseResult 发送用例中间结果（execute_plan 的 stream_case_results 模式）
+func (e *Executor) sendCaseResult(taskID, planExecutionID, caseExecutionID, caseID string, result *CaseExecutionResult, duration time.Duration) {
+	if e.send == nil {
+		return
+	}
+
+	resultJSON, _ := json.Marshal(&grpc.WsCaseResult{
+		ResultType:      grpc.ResultTypeCase,
+		TaskID:          taskID,
+		PlanExecutionID: planExecutionID,
+		CaseExecutionID: caseExecutionID,
+		CaseID:          caseID,
+		Success:         result.Success,
+		TotalSteps:      result.TotalSteps,
+		PassedSteps:     result.PassedSteps,
+		FailedSteps:     result.FailedSteps,
+		DurationMs:      duration.Milliseconds(),
+		ErrorMessage:    result.ErrorMessage,
+
+		DismissedDialogs: result.DismissedDialogs,
+	})
+
+	status := pb.TaskStatus_TASK_STATUS_SUCCESS
+	if !result.Success {
+		status = pb.TaskStatus_TASK_STATUS_FAILED
+	}
+
+	caseTaskID := caseExecutionID
+	if caseTaskID == "" {
+		caseTaskID = caseID
+	}
+
+	e.send(&pb.WorkerMessage{
+		MessageId: fmt.Sprintf("case_result_%d", time.Now().UnixMilli()),
+		Timestamp: time.Now().UnixMilli(),
+		Payload: &pb.WorkerMessage_TaskResult{
+			TaskResult: &pb.TaskResult{
+				TaskId:     "case_" + caseTaskID,
+				Success:    result.Success,
+				Status:     status,
+				Message:    result.ErrorMessage,
+				ResultJson: string(resultJSON),
+				DurationMs: duration.Milliseconds(),
+			},
+		},
+	})
+}
+
+// sendTaskCancelled 发送批量任务被取消（CancelTask）的最终结果
+func (e *Executor) sendTaskCancelled(taskID string, startTime time.Time) {
+	log("WARN", fmt.Sprintf("[Task:%s] 任务已取消，剩余步骤不再执行", taskID))
+	e.sendTaskResultWithError(taskID, newTaskError(pb.TaskStatus_TASK_STATUS_CANCELLED, pb.FailureReason_FAILURE_REASON_UNSPECIFIED, "任务已取消"), nil, startTime)
+}
+
 // sendTaskProgress 发送任务进度
 func (e *Executor) sendTaskProgress(taskID string, totalSteps, completedSteps, passedSteps, failedSteps int32, currentStepName, status string) {
-	if e.client == nil {
+	e.recordProgress(taskID, totalSteps, completedSteps, passedSteps, failedSteps, currentStepName)
+	if e.send == nil {
 		return
 	}
 
@@ -420,12 +950,12 @@ func (e *Executor) sendTaskProgress(taskID string, totalSteps, completedSteps, p
 		},
 	}
 
-	e.client.SendTaskMessage(msg)
+	e.send(msg)
 }
 
 // sendStepResultV2 发送单个步骤的执行结果（增强版，包含完整的回放数据）
 func (e *Executor) sendStepResultV2(taskID string, result *StepExecutionResult) {
-	if e.client == nil {
+	if e.send == nil {
 		return
 	}
 
@@ -465,14 +995,15 @@ func (e *Executor) sendStepResultV2(taskID string, result *StepExecutionResult)
 		failureReason = pb.FailureReason_FAILURE_REASON_UNSPECIFIED
 	}
 
-	// 构建 MatchLocation（如果有目标边界信息）
+	// 构建 MatchLocation（目标边界的左上角和宽高，与单步任务的 matchLocation 相同）
 	var matchLoc *pb.MatchLocation
 	if result.TargetBounds != nil {
 		matchLoc = &pb.MatchLocation{
-			X:      int32(result.TargetBounds.X),
-			Y:      int32(result.TargetBounds.Y),
-			Width:  int32(result.TargetBounds.Width),
-			Height: int32(result.TargetBounds.Height),
+			X:          int32(result.TargetBounds.X),
+			Y:          int32(result.TargetBounds.Y),
+			Width:      int32(result.TargetBounds.Width),
+			Height:     int32(result.TargetBounds.Height),
+			Confidence: float32(result.Confidence),
 		}
 	}
 
@@ -493,12 +1024,12 @@ func (e *Executor) sendStepResultV2(taskID string, result *StepExecutionResult)
 		},
 	}
 
-	e.client.SendTaskMessage(msg)
+	e.send(msg)
 }
 
 // sendTaskAck 发送任务确认
 func (e *Executor) sendTaskAck(taskID string, accepted bool, message string) {
-	if e.client == nil {
+	if e.send == nil {
 		return
 	}
 
@@ -514,12 +1045,14 @@ func (e *Executor) sendTaskAck(taskID string, accepted bool, message string) {
 		},
 	}
 
-	e.client.SendTaskMessage(msg)
+	e.send(msg)
 }
 
 // sendTaskResultSuccess 发送成功结果
 func (e *Executor) sendTaskResultSuccess(taskID string, resultJSON string, matchLoc *pb.MatchLocation, startTime time.Time) {
-	if e.client == nil {
+	e.recordTaskResult(true)
+	e.finishHistory(taskID, true, pb.TaskStatus_TASK_STATUS_SUCCESS, pb.FailureReason_FAILURE_REASON_UNSPECIFIED, "", startTime)
+	if e.send == nil {
 		return
 	}
 
@@ -540,13 +1073,16 @@ func (e *Executor) sendTaskResultSuccess(taskID string, resultJSON string, match
 		},
 	}
 
-	e.client.SendTaskMessage(msg)
+	e.completedTasks.put(taskID, msg.GetTaskResult())
+	e.send(msg)
 }
 
 // sendTaskResultWithError 发送失败结果
 // 可选的 resultJSON 参数允许在失败时也附带执行数据（如 Python 的 stdout/stderr）
 func (e *Executor) sendTaskResultWithError(taskID string, taskErr *TaskError, matchLoc *pb.MatchLocation, startTime time.Time, resultJSON ...string) {
-	if e.client == nil {
+	e.recordTaskResult(false)
+	e.finishHistory(taskID, false, taskErr.Status, taskErr.Reason, taskErr.Message, startTime)
+	if e.send == nil {
 		return
 	}
 
@@ -573,5 +1109,23 @@ func (e *Executor) sendTaskResultWithError(taskID string, taskErr *TaskError, ma
 		},
 	}
 
-	e.client.SendTaskMessage(msg)
+	e.completedTasks.put(taskID, msg.GetTaskResult())
+	e.send(msg)
+}
+
+// resendTaskResult 重发已缓存的最终结果（用于重复下发的任务）
+func (e *Executor) resendTaskResult(result *pb.TaskResult) {
+	if e.send == nil {
+		return
+	}
+
+	msg := &pb.WorkerMessage{
+		MessageId: fmt.Sprintf("result_%d", time.Now().UnixMilli()),
+		Timestamp: time.Now().UnixMilli(),
+		Payload: &pb.WorkerMessage_TaskResult{
+			TaskResult: result,
+		},
+	}
+
+	e.send(msg)
 }
@@ -0,0 +1,125 @@
+package executor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/grpc"
+)
+
+func TestParseScreenshotOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload map[string]interface{}
+		want    string
+	}{
+		{"默认", map[string]interface{}{}, ScreenshotModeAlways},
+		{"兼容 capture_screenshots=false", map[string]interface{}{"capture_screenshots": false}, ScreenshotModeNever},
+		{"兼容 capture_screenshots=true", map[string]interface{}{"capture_screenshots": true}, ScreenshotModeAlways},
+		{"on_failure", map[string]interface{}{"screenshot_mode": "on_failure"}, ScreenshotModeOnFailure},
+		{"screenshot_mode 优先", map[string]interface{}{"capture_screenshots": false, "screenshot_mode": "always"}, ScreenshotModeAlways},
+		{"未知模式", map[string]interface{}{"screenshot_mode": "sometimes"}, ScreenshotModeAlways},
+	}
+
+	e, _ := newTestExecutor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.parseScreenshotOptions(tt.payload, false).Mode; got != tt.want {
+				t.Errorf("Mode = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScreenshotOptions_MaxWidth(t *testing.T) {
+	e, _ := newTestExecutor()
+	e.SetScreenshotMaxWidth(1600)
+
+	if got := e.parseScreenshotOptions(map[string]interface{}{}, false).MaxWidth; got != 1600 {
+		t.Errorf("未指定时应使用执行器默认值 1600, 实际为 %d", got)
+	}
+	if got := e.parseScreenshotOptions(map[string]interface{}{"screenshot_max_width": float64(0)}, false).MaxWidth; got != 0 {
+		t.Errorf("payload 指定 0 应关闭缩放, 实际为 %d", got)
+	}
+}
+
+func TestParseScreenshotOptions_Quality(t *testing.T) {
+	e, _ := newTestExecutor()
+	if got := e.parseScreenshotOptions(map[string]interface{}{}, false).Quality; got != DefaultScreenshotQuality {
+		t.Errorf("默认质量应为 %d, 实际为 %d", DefaultScreenshotQuality, got)
+	}
+
+	e.SetScreenshotQuality(85)
+	if got := e.parseScreenshotOptions(map[string]interface{}{}, false).Quality; got != 85 {
+		t.Errorf("未指定时应使用执行器默认值 85, 实际为 %d", got)
+	}
+	if got := e.parseScreenshotOptions(map[string]interface{}{"screenshot_quality": float64(30)}, false).Quality; got != 30 {
+		t.Errorf("payload 指定的质量优先, 实际为 %d", got)
+	}
+	e.SetScreenshotQuality(0)
+	if got := e.parseScreenshotOptions(map[string]interface{}{}, false).Quality; got != DefaultScreenshotQuality {
+		t.Errorf("无效质量应使用默认值, 实际为 %d", got)
+	}
+}
+
+func TestExecutePlan_StreamCaseResults(t *testing.T) {
+	newPayload := func(stream bool) map[string]interface{} {
+		return map[string]interface{}{
+			"plan_execution_id":   "pe1",
+			"screenshot_mode":     "never",
+			"stream_case_results": stream,
+			"cases": []interface{}{
+				map[string]interface{}{
+					"case_execution_id": "ce1",
+					"case_id":           "c1",
+					"steps": []interface{}{
+						map[string]interface{}{"step_id": "s1", "task_type": TaskTypeWaitTime, "params": map[string]interface{}{"duration": float64(1)}},
+					},
+				},
+				map[string]interface{}{
+					"case_execution_id": "ce2",
+					"case_id":           "c2",
+					"steps": []interface{}{
+						map[string]interface{}{"step_id": "s2", "task_type": "unknown_type", "params": map[string]interface{}{}},
+					},
+				},
+			},
+		}
+	}
+
+	e, recorder := newTestExecutor()
+	e.executeExecutePlan("plan-1", newPayload(true), time.Now())
+
+	var got []grpc.WsCaseResult
+	for _, id := range []string{"case_ce1", "case_ce2"} {
+		results := recorder.results(id)
+		if len(results) != 1 {
+			t.Fatalf("%s 应发送 1 个用例结果, 实际为 %d", id, len(results))
+		}
+		var cr grpc.WsCaseResult
+		if err := json.Unmarshal([]byte(results[0].ResultJson), &cr); err != nil {
+			t.Fatalf("解析用例结果失败: %v", err)
+		}
+		got = append(got, cr)
+	}
+	if got[0].ResultType != grpc.ResultTypeCase || got[0].TaskID != "plan-1" || got[0].PlanExecutionID != "pe1" {
+		t.Errorf("用例结果标识错误: %+v", got[0])
+	}
+	if !got[0].Success || got[0].PassedSteps != 1 {
+		t.Errorf("用例 ce1 应成功: %+v", got[0])
+	}
+	if got[1].Success || got[1].FailedSteps != 1 {
+		t.Errorf("用例 ce2 应失败: %+v", got[1])
+	}
+	if len(recorder.results("plan-1")) != 1 {
+		t.Error("计划最终结果应照常发送")
+	}
+
+	// 未开启时不发送用例中间结果（兼容旧服务端）
+	e, recorder = newTestExecutor()
+	e.executeExecutePlan("plan-2", newPayload(false), time.Now())
+	if len(recorder.results("case_ce1")) != 0 {
+		t.Error("未开启 stream_case_results 时不应发送用例结果")
+	}
+}
@@ -0,0 +1,260 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+	"github.com/zoeyai/zoeyworker/pkg/vision/cv"
+)
+
+func TestExecute_DuplicateWhileRunning(t *testing.T) {
+	e, recorder := newTestExecutor()
+
+	done := make(chan struct{})
+	go func() {
+		e.Execute("task-1", TaskTypeWaitTime, `{"duration": 200}`)
+		close(done)
+	}()
+
+	// 等待第一次执行注册
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, id, _, _, _, _ := e.GetStatus(); id == "task-1" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("任务未注册")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// 运行期间重复下发
+	e.Execute("task-1", TaskTypeWaitTime, `{"duration": 200}`)
+
+	acks := recorder.acks("task-1")
+	if len(acks) != 2 {
+		t.Fatalf("应有 2 个确认, 实际为 %d", len(acks))
+	}
+	if acks[1].Message != "duplicate, already running" {
+		t.Errorf("重复确认消息错误: %q", acks[1].Message)
+	}
+	if n := len(recorder.results("task-1")); n != 0 {
+		t.Errorf("运行期间不应发送结果, 实际为 %d", n)
+	}
+
+	<-done
+
+	if n := len(recorder.results("task-1")); n != 1 {
+		t.Errorf("应只发送 1 个结果, 实际为 %d", n)
+	}
+}
+
+func TestExecute_DuplicateAfterCompletion(t *testing.T) {
+	e, recorder := newTestExecutor()
+
+	e.Execute("task-2", TaskTypeWaitTime, `{"duration": 1}`)
+
+	results := recorder.results("task-2")
+	if len(results) != 1 {
+		t.Fatalf("应发送 1 个结果, 实际为 %d", len(results))
+	}
+
+	// 完成后重复下发：不重新执行，重发缓存结果
+	start := time.Now()
+	e.Execute("task-2", TaskTypeWaitTime, `{"duration": 500}`)
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("重复任务不应重新执行, 耗时 %v", elapsed)
+	}
+
+	acks := recorder.acks("task-2")
+	if len(acks) != 2 {
+		t.Fatalf("应有 2 个确认, 实际为 %d", len(acks))
+	}
+	if acks[1].Message != "duplicate, already completed" {
+		t.Errorf("重复确认消息错误: %q", acks[1].Message)
+	}
+
+	results = recorder.results("task-2")
+	if len(results) != 2 {
+		t.Fatalf("应重发缓存结果, 结果数为 %d", len(results))
+	}
+	if results[1].ResultJson != results[0].ResultJson || results[1].Status != results[0].Status {
+		t.Errorf("重发结果应与原结果一致: %v vs %v", results[1], results[0])
+	}
+}
+
+func TestGetStatus_Deterministic(t *testing.T) {
+	e, _ := newTestExecutor()
+
+	e.runningTasks["task-c"] = &TaskInfo{TaskID: "task-c", TaskType: TaskTypeClickImage, StartedAt: 300}
+	e.runningTasks["task-a"] = &TaskInfo{TaskID: "task-a", TaskType: TaskTypeWaitTime, StartedAt: 100}
+	e.runningTasks["task-b"] = &TaskInfo{TaskID: "task-b", TaskType: TaskTypeClickText, StartedAt: 200}
+
+	for i := 0; i < 20; i++ {
+		status, id, taskType, startedAt, count, ids := e.GetStatus()
+		if status != "BUSY" || count != 3 {
+			t.Fatalf("状态应为 BUSY/3, 实际为 %s/%d", status, count)
+		}
+		if id != "task-a" || taskType != TaskTypeWaitTime || startedAt != 100 {
+			t.Fatalf("当前任务应为最早开始的 task-a, 实际为 %s", id)
+		}
+		if len(ids) != 3 || ids[0] != "task-a" || ids[1] != "task-b" || ids[2] != "task-c" {
+			t.Fatalf("运行中任务列表顺序错误: %v", ids)
+		}
+	}
+
+	// 存在唯一批量任务时优先返回批量任务
+	e.runningTasks["task-d"] = &TaskInfo{TaskID: "task-d", TaskType: TaskTypeExecutePlan, StartedAt: 400}
+	if _, id, _, _, _, _ := e.GetStatus(); id != "task-d" {
+		t.Errorf("当前任务应为批量任务 task-d, 实际为 %s", id)
+	}
+}
+
+func TestCancelTask_Batch(t *testing.T) {
+	e, recorder := newTestExecutor()
+
+	step := func(id string) map[string]interface{} {
+		return map[string]interface{}{"step_id": id, "task_type": TaskTypeWaitTime, "params": map[string]interface{}{"duration": float64(5000)}}
+	}
+	payload, _ := json.Marshal(map[string]interface{}{
+		"screenshot_mode": "never",
+		"steps":           []interface{}{step("s1"), step("s2"), step("s3")},
+	})
+
+	done := make(chan struct{})
+	go func() {
+		e.Execute("batch-1", TaskTypeDebugCase, string(payload))
+		close(done)
+	}()
+
+	// 运行列表中可以看到当前步骤
+	deadline := time.Now().Add(time.Second)
+	for {
+		if tasks := e.RunningTasks(); len(tasks) == 1 && tasks[0].CurrentStep == TaskTypeWaitTime {
+			if tasks[0].TaskID != "batch-1" || tasks[0].TotalSteps != 3 || tasks[0].Cancelled {
+				t.Fatalf("运行中任务信息错误: %+v", tasks[0])
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("未上报当前步骤: %+v", e.RunningTasks())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if !e.CancelTask("batch-1") {
+		t.Fatal("取消运行中的任务应返回 true")
+	}
+	if e.CancelTask("batch-1") {
+		t.Error("重复取消应返回 false")
+	}
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("取消后任务应尽快结束")
+	}
+
+	results := recorder.results("batch-1")
+	if len(results) != 1 || results[0].Status != pb.TaskStatus_TASK_STATUS_CANCELLED {
+		t.Fatalf("应上报 CANCELLED 结果: %+v", results)
+	}
+	if tasks := e.RunningTasks(); len(tasks) != 0 {
+		t.Errorf("结束后不应在运行列表中: %+v", tasks)
+	}
+	if e.CancelTask("batch-1") {
+		t.Error("已结束的任务取消应返回 false")
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status pb.TaskStatus
+		reason pb.FailureReason
+	}{
+		{"typed timeout", auto.Errorf(auto.ErrTimeout, "等待图像超时: %s", "a.png"), pb.TaskStatus_TASK_STATUS_TIMEOUT, pb.FailureReason_FAILURE_REASON_UNSPECIFIED},
+		{"cv match timeout", cv.ErrMatchTimeout, pb.TaskStatus_TASK_STATUS_TIMEOUT, pb.FailureReason_FAILURE_REASON_UNSPECIFIED},
+		{"context deadline", fmt.Errorf("执行失败: %w", context.DeadlineExceeded), pb.TaskStatus_TASK_STATUS_TIMEOUT, pb.FailureReason_FAILURE_REASON_UNSPECIFIED},
+		{"context canceled", fmt.Errorf("匹配失败: %w", context.Canceled), pb.TaskStatus_TASK_STATUS_CANCELLED, pb.FailureReason_FAILURE_REASON_UNSPECIFIED},
+		{"typed not found", auto.Errorf(auto.ErrNotFound, "未找到进程: %s", "app"), pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_NOT_FOUND},
+		{"wrapped not found", fmt.Errorf("激活失败: %w", auto.Errorf(auto.ErrNotFound, "no window")), pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_NOT_FOUND},
+		{"multiple matches", fmt.Errorf("匹配失败: %w", cv.ErrMultipleMatches), pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_MULTIPLE_MATCHES},
+		{"typed param", auto.Errorf(auto.ErrParam, "缺少 image 参数"), pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_PARAM_ERROR},
+		{"template 404", fmt.Errorf("匹配失败: %w", fmt.Errorf("%w: HTTP 404: https://a/b.png", cv.ErrTemplateNotFound)), pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_PARAM_ERROR},
+		{"template network", fmt.Errorf("匹配失败: %w", fmt.Errorf("%w: Client.Timeout exceeded", cv.ErrTemplateDownload)), pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR},
+		{"assertion over message", auto.Errorf(ErrAssertionFailed, "断言失败: 未找到指定图像"), pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_ASSERTION_FAILED},
+		{"missing system tool", auto.Errorf(auto.ErrSystem, "未找到剪贴板工具 xclip: %w", exec.ErrNotFound), pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR},
+		{"typed wins over message", auto.Errorf(auto.ErrParam, "something timeout"), pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_PARAM_ERROR},
+		{"task error", newTaskError(pb.TaskStatus_TASK_STATUS_CANCELLED, pb.FailureReason_FAILURE_REASON_UNSPECIFIED, "cancelled"), pb.TaskStatus_TASK_STATUS_CANCELLED, pb.FailureReason_FAILURE_REASON_UNSPECIFIED},
+		{"fallback timeout", errors.New("operation timeout"), pb.TaskStatus_TASK_STATUS_TIMEOUT, pb.FailureReason_FAILURE_REASON_UNSPECIFIED},
+		{"fallback not found", errors.New("元素未找到"), pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_NOT_FOUND},
+		{"fallback system", errors.New("disk io"), pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taskErr := classifyError(tt.err)
+			if taskErr.Status != tt.status || taskErr.Reason != tt.reason {
+				t.Errorf("classifyError(%q) = %s/%s, 期望 %s/%s", tt.err, taskErr.Status, taskErr.Reason, tt.status, tt.reason)
+			}
+			if taskErr.Message != tt.err.Error() {
+				t.Errorf("消息应保持不变: %q vs %q", taskErr.Message, tt.err.Error())
+			}
+		})
+	}
+
+	if classifyError(nil) != nil {
+		t.Error("nil 错误应返回 nil")
+	}
+}
+
+func TestTruncateString(t *testing.T) {
+	tests := []struct {
+		input  string
+		maxLen int
+		want   string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello world", 5, "hello...(+6 bytes)"},
+		{"中文字符", 4, "中...(+9 bytes)"}, // 每个汉字 3 字节，不能截断在字符中间
+		{"中文字符", 6, "中文...(+6 bytes)"},
+		{"😀😀", 5, "😀...(+4 bytes)"},
+		{"中文", 0, "...(+6 bytes)"},
+	}
+
+	for _, tt := range tests {
+		if got := truncateString(tt.input, tt.maxLen); got != tt.want {
+			t.Errorf("truncateString(%q, %d) = %q, 期望 %q", tt.input, tt.maxLen, got, tt.want)
+		}
+	}
+}
+
+func FuzzTruncateString(f *testing.F) {
+	f.Add("混合 mixed 文本 😀 emoji 🎉 结束", 10)
+	f.Add("payload={\"text\":\"你好世界\"}", 15)
+	f.Add("👨‍👩‍👧‍👦家庭", 7)
+
+	f.Fuzz(func(t *testing.T, s string, maxLen int) {
+		if !utf8.ValidString(s) {
+			t.Skip()
+		}
+		got := truncateString(s, maxLen)
+		if !utf8.ValidString(got) {
+			t.Fatalf("truncateString(%q, %d) 产生了无效的 UTF-8: %q", s, maxLen, got)
+		}
+		if len(s) <= maxLen && got != s {
+			t.Fatalf("未超长的字符串不应被截断: %q -> %q", s, got)
+		}
+	})
+}
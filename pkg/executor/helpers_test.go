@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"image"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+	"github.com/zoeyai/zoeyworker/pkg/auto/text"
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+	"github.com/zoeyai/zoeyworker/pkg/vision/cv"
+)
+
+// messageRecorder 记录执行器发送的消息
+type messageRecorder struct {
+	mu       sync.Mutex
+	messages []*pb.WorkerMessage
+}
+
+func (r *messageRecorder) send(msg *pb.WorkerMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+}
+
+func (r *messageRecorder) acks(taskID string) []*pb.TaskAck {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var acks []*pb.TaskAck
+	for _, msg := range r.messages {
+		if ack := msg.GetTaskAck(); ack != nil && ack.TaskId == taskID {
+			acks = append(acks, ack)
+		}
+	}
+	return acks
+}
+
+func (r *messageRecorder) results(taskID string) []*pb.TaskResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var results []*pb.TaskResult
+	for _, msg := range r.messages {
+		if res := msg.GetTaskResult(); res != nil && res.TaskId == taskID {
+			results = append(results, res)
+		}
+	}
+	return results
+}
+
+// TestMain 测试期间不真正阻止本机休眠（TestKeepAwake 自行替换 acquireKeepAwake）
+func TestMain(m *testing.M) {
+	acquireKeepAwake = func(reason string) (func(), error) { return func() {}, nil }
+	sessionLocked = func() (bool, bool) { return false, false }
+	captureScreenCheck = func() (image.Image, error) { return nil, errors.New("测试中不截图") }
+	currentDisplay = func() screen.Display { return screen.Display{Width: 1920, Height: 1080, ScaleFactor: 1} }
+	os.Exit(m.Run())
+}
+
+func newTestExecutor() (*Executor, *messageRecorder) {
+	recorder := &messageRecorder{}
+	e := NewExecutor(nil)
+	e.send = recorder.send
+	return e, recorder
+}
+
+// mockLocate 替换定位测试的查找与标注实现，标注截图为 2000x1000 的空白图
+func mockLocate(t *testing.T) {
+	t.Helper()
+	origImage, origMatch, origSearch := locateImage, annotateImageMatch, annotateImageSearch
+	origText, origTextMatch, origArea, origAvailable := findTextOnce, annotateTextMatch, annotateSearchArea, ocrAvailable
+	blank := image.NewRGBA(image.Rect(0, 0, 2000, 1000))
+	annotateImageMatch = func(*cv.MatchResult, ...auto.Option) (image.Image, error) { return blank, nil }
+	annotateImageSearch = func(string, ...auto.Option) (image.Image, *cv.MatchResult, error) {
+		return blank, &cv.MatchResult{Result: cv.Point{X: 5, Y: 6}, Confidence: 0.42}, nil
+	}
+	annotateTextMatch = func(*text.TextMatch, ...auto.Option) (image.Image, error) { return blank, nil }
+	annotateSearchArea = func(...auto.Option) (image.Image, error) { return nil, errors.New("截图失败") }
+	ocrAvailable = func() bool { return true }
+	t.Cleanup(func() {
+		locateImage, annotateImageMatch, annotateImageSearch = origImage, origMatch, origSearch
+		findTextOnce, annotateTextMatch, annotateSearchArea, ocrAvailable = origText, origTextMatch, origArea, origAvailable
+	})
+}
+
+func decodePayload(t *testing.T, payloadJSON string) map[string]interface{} {
+	t.Helper()
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(payloadJSON), &payload); err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+	"github.com/zoeyai/zoeyworker/pkg/history"
+)
+
+// SetHistory 设置本地执行历史（nil 不记录）：任务结束时保存任务和步骤结果，步骤截图写入 store 指定的目录
+func (e *Executor) SetHistory(store history.Store) {
+	e.tasksMutex.Lock()
+	defer e.tasksMutex.Unlock()
+	e.history = store
+}
+
+// recordHistoryStep 记录批量任务的步骤结果，截图（发送或上传前的 data URL）保存为文件
+func (e *Executor) recordHistoryStep(taskID string, result *StepExecutionResult) {
+	e.tasksMutex.Lock()
+	store := e.history
+	info := e.runningTasks[taskID]
+	if store == nil || info == nil || info.run == nil {
+		e.tasksMutex.Unlock()
+		return
+	}
+	run := info.run
+	index := len(run.Steps) + 1
+	e.tasksMutex.Unlock()
+
+	step := &history.Step{
+		StepExecutionID: result.StepExecutionID,
+		StepID:          result.StepID,
+		ActionType:      result.ActionType,
+		Status:          result.Status,
+		FailureReason:   result.FailureReason,
+		Error:           result.ErrorMessage,
+		DurationMs:      result.DurationMs,
+	}
+	if step.StepExecutionID == "" {
+		step.StepExecutionID = fmt.Sprintf("%s#%d", taskID, index)
+	}
+	dir := store.ScreenshotDir(taskID)
+	name := fmt.Sprintf("step%d", index)
+	step.ScreenshotBefore = saveLocalScreenshot(dir, name+"_before.jpg", result.ScreenshotBefore)
+	step.ScreenshotAfter = saveLocalScreenshot(dir, name+"_after.jpg", result.ScreenshotAfter)
+	if result.ScreenshotAfterSameAsBefore && step.ScreenshotAfter == "" {
+		step.ScreenshotAfter = step.ScreenshotBefore
+	}
+
+	e.tasksMutex.Lock()
+	run.Steps = append(run.Steps, step)
+	e.tasksMutex.Unlock()
+}
+
+// finishHistory 任务结束时保存历史记录（每个任务只保存一次）
+func (e *Executor) finishHistory(taskID string, success bool, status pb.TaskStatus, reason pb.FailureReason, message string, startTime time.Time) {
+	e.tasksMutex.Lock()
+	store := e.history
+	info := e.runningTasks[taskID]
+	if store == nil || info == nil || info.run == nil {
+		e.tasksMutex.Unlock()
+		return
+	}
+	run := info.run
+	info.run = nil
+	e.tasksMutex.Unlock()
+
+	run.Success = success
+	run.Status = strings.TrimPrefix(status.String(), "TASK_STATUS_")
+	if reason != pb.FailureReason_FAILURE_REASON_UNSPECIFIED {
+		run.FailureReason = strings.TrimPrefix(reason.String(), "FAILURE_REASON_")
+	}
+	run.Error = message
+	run.DurationMs = time.Since(startTime).Milliseconds()
+	if err := store.Save(run); err != nil {
+		log("WARN", fmt.Sprintf("[Task:%s] 保存执行历史失败: %v", taskID, err))
+	}
+}
@@ -0,0 +1,51 @@
+package executor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/history"
+)
+
+func TestExecute_RecordsHistory(t *testing.T) {
+	store, err := history.Open(filepath.Join(t.TempDir(), "history.db"), history.DefaultRetention)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	e, _ := newTestExecutor()
+	e.SetHistory(store)
+
+	e.Execute("single-1", TaskTypeWaitTime, `{"duration": 1}`)
+	e.Execute("batch-1", TaskTypeDebugCase, `{"screenshot_mode": "never", "steps": [
+		{"step_id": "s1", "step_execution_id": "se1", "task_type": "wait_time", "params": {"duration": 1}},
+		{"step_id": "s2", "task_type": "no_such_action", "params": {}}
+	]}`)
+
+	page, _ := store.List(1, 10, history.Filter{})
+	if page.Total != 2 {
+		t.Fatalf("应保存 2 条记录, 实际 %d", page.Total)
+	}
+	batch, single := page.Runs[0], page.Runs[1]
+	if single.ID != "single-1" || !single.Success || single.Status != "SUCCESS" || len(single.Steps) != 0 {
+		t.Errorf("单步任务记录错误: %+v", single)
+	}
+	if batch.ID != "batch-1" || batch.Success || batch.Status != "FAILED" || batch.TaskType != TaskTypeDebugCase || len(batch.Steps) != 2 {
+		t.Fatalf("批量任务记录错误: %+v", batch)
+	}
+	if step := batch.Steps[0]; step.StepExecutionID != "se1" || step.Status != "SUCCESS" {
+		t.Errorf("步骤 1 记录错误: %+v", step)
+	}
+	if _, step, err := store.Step("batch-1#2"); err != nil || step.StepID != "s2" || step.FailureReason != "PARAM_ERROR" {
+		t.Errorf("没有 step_execution_id 的步骤应按任务 ID 和序号记录: %+v", step)
+	}
+
+	// 重复下发（重发缓存结果）不重复记录
+	e.Execute("single-1", TaskTypeWaitTime, `{"duration": 1}`)
+	if page, _ := store.List(1, 10, history.Filter{}); page.Total != 2 {
+		t.Errorf("重复任务不应重复记录, 实际 %d", page.Total)
+	}
+	if executed, failed := e.Stats(); executed != 2 || failed != 1 {
+		t.Errorf("Stats = %d, %d, 期望 2 个任务其中 1 个失败", executed, failed)
+	}
+}
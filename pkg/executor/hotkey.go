@@ -0,0 +1,76 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/input"
+)
+
+// waitHotkey 可替换的全局热键等待实现（便于测试）
+var waitHotkey = input.WaitHotkey
+
+// defaultHotkeyTimeout wait_for_hotkey 的默认超时（操作员需要时间完成插入 U 盾等操作）
+const defaultHotkeyTimeout = 10 * time.Minute
+
+// SetOperatorNotifier 设置提示操作员的方式（GUI 显示系统通知），wait_for_hotkey 等待前调用；为 nil 时只输出日志
+func (e *Executor) SetOperatorNotifier(fn func(title, message string)) {
+	e.notifyOperator = fn
+}
+
+// executeWaitForHotkey 等待操作员按下全局热键后继续：hotkey（默认 Ctrl+Shift+F9）、timeout（秒，默认 600）、
+// message（提示操作员要做什么）。等待前输出日志并通过 SetOperatorNotifier 提示，超时返回 TIMEOUT，任务取消时立即返回
+func (e *Executor) executeWaitForHotkey(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
+	spec := input.DefaultHotkey
+	if raw, ok := payload["hotkey"]; ok {
+		if spec, ok = raw.(string); !ok || strings.TrimSpace(spec) == "" {
+			return nil, auto.Errorf(auto.ErrParam, "hotkey 必须是非空字符串: %v", raw)
+		}
+	}
+	hotkey, err := input.ParseHotkey(spec)
+	if err != nil {
+		return nil, err
+	}
+	timeout := defaultHotkeyTimeout
+	if raw, ok := payload["timeout"]; ok {
+		v, ok := raw.(float64)
+		if !ok || v <= 0 {
+			return nil, auto.Errorf(auto.ErrParam, "timeout 必须是正数（秒）: %v", raw)
+		}
+		timeout = time.Duration(v * float64(time.Second))
+	}
+	message, _ := payload["message"].(string)
+
+	prompt := fmt.Sprintf("完成后按 %s 继续", hotkey)
+	if message = strings.TrimSpace(message); message != "" {
+		prompt = message + "，" + prompt
+	}
+	log("WARN", fmt.Sprintf("等待操作员: %s（%v 内）", prompt, timeout))
+	if e.notifyOperator != nil {
+		e.notifyOperator("等待操作员", prompt)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	err = waitHotkey(waitCtx, hotkey)
+	switch {
+	case err == nil:
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case errors.Is(err, context.DeadlineExceeded):
+		return nil, auto.Errorf(auto.ErrTimeout, "等待热键 %s 超时（%v）", hotkey, timeout)
+	default:
+		return nil, err
+	}
+	log("INFO", fmt.Sprintf("操作员已按下 %s，继续执行", hotkey))
+	return map[string]interface{}{
+		"pressed":   true,
+		"hotkey":    hotkey.String(),
+		"waited_ms": time.Since(start).Milliseconds(),
+	}, nil
+}
@@ -0,0 +1,61 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/input"
+)
+
+func TestWaitForHotkey(t *testing.T) {
+	origWait := waitHotkey
+	t.Cleanup(func() { waitHotkey = origWait })
+	pressed := make(chan struct{}, 1)
+	var registered atomic.Int32
+	var got input.Hotkey
+	waitHotkey = func(ctx context.Context, h input.Hotkey) error {
+		got = h
+		registered.Add(1)
+		defer registered.Add(-1)
+		select {
+		case <-pressed:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	e, _ := newTestExecutor()
+	var prompts []string
+	e.SetOperatorNotifier(func(title, message string) { prompts = append(prompts, message) })
+
+	pressed <- struct{}{}
+	result, err := e.runAction(context.Background(), TaskTypeWaitForHotkey, decodePayload(t, `{"message": "请插入 U 盾"}`))
+	if err != nil || got.String() != "ctrl+shift+f9" || result.Data.(map[string]interface{})["pressed"] != true {
+		t.Fatalf("默认热键: %v %v %+v", got, err, result)
+	}
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "请插入 U 盾") || !strings.Contains(prompts[0], "ctrl+shift+f9") {
+		t.Errorf("应提示操作员: %v", prompts)
+	}
+
+	_, err = e.runAction(context.Background(), TaskTypeWaitForHotkey, decodePayload(t, `{"hotkey": "Alt+F10", "timeout": 0.05}`))
+	if !errors.Is(err, auto.ErrTimeout) || !strings.Contains(err.Error(), "alt+f10") || registered.Load() != 0 {
+		t.Errorf("超时应返回 ErrTimeout 并注销热键: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if _, err := e.runAction(ctx, TaskTypeWaitForHotkey, decodePayload(t, `{}`)); !errors.Is(err, context.Canceled) || registered.Load() != 0 {
+		t.Errorf("取消任务应立即返回并注销热键: %v", err)
+	}
+
+	for _, payload := range []string{`{"hotkey": ""}`, `{"hotkey": "ctrl+shift"}`, `{"hotkey": "ctrl+f9", "timeout": -1}`} {
+		if _, err := e.runAction(context.Background(), TaskTypeWaitForHotkey, decodePayload(t, payload)); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("%s 应返回 ErrParam: %v", payload, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/zoeyai/zoeyworker/pkg/keepawake"
+)
+
+// acquireKeepAwake 阻止系统休眠和锁屏（可替换，便于测试）
+var acquireKeepAwake = keepawake.Acquire
+
+// keepAwakeReason 显示在系统电源 / 抑制列表中的原因
+const keepAwakeReason = "Zoey Worker 正在执行任务"
+
+// awakeGuard 有任务运行期间阻止系统休眠和锁屏：第一个任务开始时获取，最后一个任务结束时释放
+type awakeGuard struct {
+	mu       sync.Mutex
+	disabled bool
+	running  int
+	release  func() // 已获取时不为 nil
+}
+
+// taskStarted 任务开始（与 taskFinished 成对调用）
+func (g *awakeGuard) taskStarted() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.running++
+	if g.running == 1 && !g.disabled {
+		g.acquireLocked()
+	}
+}
+
+// taskFinished 任务结束，没有运行中的任务时释放
+func (g *awakeGuard) taskFinished() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.running > 0 {
+		g.running--
+	}
+	if g.running == 0 {
+		g.releaseLocked()
+	}
+}
+
+// setEnabled 开启或关闭；任务运行中关闭时立即释放，开启时立即获取
+func (g *awakeGuard) setEnabled(enabled bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.disabled = !enabled
+	if g.disabled {
+		g.releaseLocked()
+	} else if g.running > 0 && g.release == nil {
+		g.acquireLocked()
+	}
+}
+
+func (g *awakeGuard) acquireLocked() {
+	release, err := acquireKeepAwake(keepAwakeReason)
+	if err != nil {
+		log("WARN", fmt.Sprintf("阻止系统休眠失败，长时间任务可能因休眠或锁屏中断: %v", err))
+		return
+	}
+	g.release = release
+	log("INFO", "任务执行中，已阻止系统休眠和锁屏")
+}
+
+func (g *awakeGuard) releaseLocked() {
+	if g.release == nil {
+		return
+	}
+	g.release()
+	g.release = nil
+	log("INFO", "任务已全部结束，已恢复系统休眠和锁屏")
+}
@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+)
+
+func TestKeepAwake(t *testing.T) {
+	origAcquire := acquireKeepAwake
+	t.Cleanup(func() { acquireKeepAwake = origAcquire })
+	var acquired, released atomic.Int32
+	acquireKeepAwake = func(reason string) (func(), error) {
+		acquired.Add(1)
+		return func() { released.Add(1) }, nil
+	}
+	block := make(chan struct{})
+	RegisterAction("test_block", func(ctx context.Context, payload map[string]interface{}) (*ActionResult, error) {
+		<-block
+		return &ActionResult{}, nil
+	})
+	RegisterAction("test_panic", func(ctx context.Context, payload map[string]interface{}) (*ActionResult, error) {
+		panic("boom")
+	})
+	t.Cleanup(func() {
+		actionsMu.Lock()
+		delete(actions, "test_block")
+		delete(actions, "test_panic")
+		actionsMu.Unlock()
+	})
+	e, recorder := newTestExecutor()
+
+	// 并发任务只获取一次，全部结束后释放
+	var wg sync.WaitGroup
+	for _, id := range []string{"a", "b"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.Execute(id, "test_block", `{}`)
+		}()
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, _, _, _, n, _ := e.GetStatus(); n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("任务未注册")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if acquired.Load() != 1 || released.Load() != 0 {
+		t.Fatalf("任务运行中应保持阻止: acquired=%d released=%d", acquired.Load(), released.Load())
+	}
+	close(block)
+	wg.Wait()
+	if acquired.Load() != 1 || released.Load() != 1 {
+		t.Fatalf("全部任务结束后应释放: acquired=%d released=%d", acquired.Load(), released.Load())
+	}
+
+	// 任务 panic 时上报系统错误并释放
+	e.Execute("panic", "test_panic", `{}`)
+	results := recorder.results("panic")
+	if len(results) != 1 || results[0].FailureReason != pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR || !strings.Contains(results[0].Message, "boom") {
+		t.Errorf("panic 应上报 SYSTEM_ERROR: %+v", results)
+	}
+	if acquired.Load() != 2 || released.Load() != 2 {
+		t.Errorf("panic 后应释放: acquired=%d released=%d", acquired.Load(), released.Load())
+	}
+	if status, _, _, _, _, _ := e.GetStatus(); status != "IDLE" {
+		t.Errorf("panic 后任务应注销: %s", status)
+	}
+
+	e.SetKeepAwake(false)
+	e.Execute("disabled", "test_block", `{}`)
+	if acquired.Load() != 2 {
+		t.Errorf("关闭后不应获取: acquired=%d", acquired.Load())
+	}
+}
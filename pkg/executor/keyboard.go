@@ -0,0 +1,341 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/input"
+)
+
+// 可替换的键盘实现（便于测试）
+var (
+	keyTap  = input.KeyTap
+	keyDown = input.KeyDown
+	keyUp   = input.KeyUp
+)
+
+// keyboardGOOS platform_keys 选择的平台（可替换，便于测试）
+var keyboardGOOS = runtime.GOOS
+
+// maxKeyDelay hold_ms、wait_ms 的上限
+const maxKeyDelay = 60 * time.Second
+
+// keyChord 一次按键：最后一个键是主键，前面的是同时按住的修饰键；hold 大于 0 时按住主键 hold 后再松开
+type keyChord struct {
+	keys []string
+	hold time.Duration
+}
+
+// press 按下并松开；按住期间任务取消时同样松开所有键
+func (c keyChord) press(ctx context.Context) error {
+	main, modifiers := c.keys[len(c.keys)-1], c.keys[:len(c.keys)-1]
+	if c.hold <= 0 {
+		keyTap(main, modifiers...)
+		return nil
+	}
+
+	for _, k := range c.keys {
+		keyDown(k)
+	}
+	defer func() {
+		for i := len(c.keys) - 1; i >= 0; i-- {
+			keyUp(c.keys[i])
+		}
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(c.hold):
+		return nil
+	}
+}
+
+// parseKeyList 解析键名数组，校验每个键名（无效时返回包含该键名的 PARAM_ERROR）
+func parseKeyList(raw interface{}, field string) ([]string, error) {
+	list, ok := raw.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, auto.Errorf(auto.ErrParam, "%s 必须是非空的键名数组: %v", field, raw)
+	}
+	keys := make([]string, 0, len(list))
+	for _, item := range list {
+		key, ok := item.(string)
+		if !ok {
+			return nil, auto.Errorf(auto.ErrParam, "%s 中的键名必须是字符串: %v", field, item)
+		}
+		if err := input.ValidateKey(key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// parseKeyDelay 解析毫秒数（hold_ms、wait_ms），未指定时返回 0
+func parseKeyDelay(payload map[string]interface{}, key string) (time.Duration, error) {
+	raw, ok := payload[key]
+	if !ok {
+		return 0, nil
+	}
+	v, ok := raw.(float64)
+	if !ok || v < 0 || time.Duration(v)*time.Millisecond > maxKeyDelay {
+		return 0, auto.Errorf(auto.ErrParam, "%s 必须是 0-%d 之间的毫秒数: %v", key, maxKeyDelay.Milliseconds(), raw)
+	}
+	return time.Duration(v) * time.Millisecond, nil
+}
+
+// parsePlatformKeys 解析 platform_keys（如 {"darwin": ["Cmd", "C"], "windows": ["Ctrl", "C"]}），
+// 校验所有平台的键名，返回当前平台的键；没有当前平台时 ok 为 false
+func parsePlatformKeys(raw interface{}) (keys []string, ok bool, err error) {
+	m, isMap := raw.(map[string]interface{})
+	if !isMap {
+		return nil, false, auto.Errorf(auto.ErrParam, "platform_keys 必须是以平台（darwin、windows、linux）为键的对象: %v", raw)
+	}
+	for goos, v := range m {
+		switch goos {
+		case "darwin", "windows", "linux":
+		default:
+			return nil, false, auto.Errorf(auto.ErrParam, "platform_keys 不支持的平台 %q（可用: darwin、windows、linux）", goos)
+		}
+		list, err := parseKeyList(v, "platform_keys."+goos)
+		if err != nil {
+			return nil, false, err
+		}
+		if goos == keyboardGOOS {
+			keys, ok = list, true
+		}
+	}
+	return keys, ok, nil
+}
+
+// parseKeys 解析要按的键：platform_keys 中当前平台的键优先，其次是 keys 数组（如 ["Primary", "C"]），或旧格式 key + modifiers；
+// 都未指定时 ok 为 false
+func parseKeys(payload map[string]interface{}) (keys []string, ok bool, err error) {
+	if raw, exists := payload["platform_keys"]; exists {
+		if keys, ok, err = parsePlatformKeys(raw); err != nil || ok {
+			return keys, ok, err
+		}
+	}
+	if raw, exists := payload["keys"]; exists {
+		keys, err = parseKeyList(raw, "keys")
+		return keys, err == nil, err
+	}
+	key, _ := payload["key"].(string)
+	if key == "" {
+		return nil, false, nil
+	}
+	if raw, exists := payload["modifiers"]; exists {
+		if keys, err = parseKeyList(raw, "modifiers"); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := input.ValidateKey(key); err != nil {
+		return nil, false, err
+	}
+	return append(keys, key), true, nil
+}
+
+// parseKeyChord 解析按键及 hold_ms
+func parseKeyChord(payload map[string]interface{}) (keyChord, bool, error) {
+	keys, ok, err := parseKeys(payload)
+	if err != nil || !ok {
+		return keyChord{}, ok, err
+	}
+	hold, err := parseKeyDelay(payload, "hold_ms")
+	if err != nil {
+		return keyChord{}, false, err
+	}
+	return keyChord{keys: keys, hold: hold}, true, nil
+}
+
+// keyStep sequence 中的一项：按键，或等待 wait 后继续
+type keyStep struct {
+	chord keyChord
+	wait  time.Duration
+}
+
+// parseKeySequence 解析 sequence，全部校验通过后才开始执行
+func parseKeySequence(raw interface{}) ([]keyStep, error) {
+	list, ok := raw.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, auto.Errorf(auto.ErrParam, "sequence 必须是非空数组: %v", raw)
+	}
+	steps := make([]keyStep, 0, len(list))
+	for i, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, auto.Errorf(auto.ErrParam, "sequence[%d] 必须是对象: %v", i, item)
+		}
+		if _, isWait := m["wait_ms"]; isWait {
+			if _, hasKeys := m["keys"]; hasKeys {
+				return nil, auto.Errorf(auto.ErrParam, "sequence[%d] 不能同时指定 keys 和 wait_ms", i)
+			}
+			wait, err := parseKeyDelay(m, "wait_ms")
+			if err != nil {
+				return nil, fmt.Errorf("sequence[%d]: %w", i, err)
+			}
+			steps = append(steps, keyStep{wait: wait})
+			continue
+		}
+		chord, ok, err := parseKeyChord(m)
+		if err != nil {
+			return nil, fmt.Errorf("sequence[%d]: %w", i, err)
+		}
+		if !ok {
+			return nil, auto.Errorf(auto.ErrParam, "sequence[%d] 缺少 keys 或 wait_ms", i)
+		}
+		steps = append(steps, keyStep{chord: chord})
+	}
+	return steps, nil
+}
+
+// executeKeyPress 执行按键：keys（或旧格式 key + modifiers，platform_keys 按平台覆盖），hold_ms 按住主键的时长；
+// 或 sequence 依次执行按键和等待，如 [{"keys": ["Tab"]}, {"wait_ms": 200}, {"keys": ["Enter"]}]
+// 键名无效时返回 PARAM_ERROR，不会按下任何键
+func (e *Executor) executeKeyPress(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
+	if raw, ok := payload["sequence"]; ok {
+		if _, hasKeys := payload["keys"]; hasKeys {
+			return nil, auto.Errorf(auto.ErrParam, "sequence 和 keys 只能指定一个")
+		}
+		steps, err := parseKeySequence(raw)
+		if err != nil {
+			return nil, err
+		}
+		pressed := [][]string{}
+		for _, step := range steps {
+			if step.chord.keys == nil {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(step.wait):
+				}
+				continue
+			}
+			if err := step.chord.press(ctx); err != nil {
+				return nil, err
+			}
+			pressed = append(pressed, step.chord.keys)
+		}
+		return map[string]interface{}{"pressed": true, "sequence": len(steps), "keys": pressed}, nil
+	}
+
+	chord, ok, err := parseKeyChord(payload)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, auto.Errorf(auto.ErrParam, "缺少 keys、key 或 sequence 参数")
+	}
+	if err := chord.press(ctx); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"pressed": true, "keys": chord.keys, "hold_ms": chord.hold.Milliseconds()}, nil
+}
+
+// heldKeys key_down 按下、尚未 key_up 松开的键（按按下顺序，键名忽略大小写）
+type heldKeys struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (h *heldKeys) add(keys []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, k := range keys {
+		if !containsFold(h.keys, k) {
+			h.keys = append(h.keys, k)
+		}
+	}
+}
+
+func (h *heldKeys) remove(keys []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	remaining := h.keys[:0]
+	for _, k := range h.keys {
+		if !containsFold(keys, k) {
+			remaining = append(remaining, k)
+		}
+	}
+	h.keys = remaining
+}
+
+// snapshot 返回仍按住的键
+func (h *heldKeys) snapshot() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string{}, h.keys...)
+}
+
+// drain 返回并清空仍按住的键（按松开顺序，即按下顺序的逆序）
+func (h *heldKeys) drain() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	keys := make([]string, len(h.keys))
+	for i, k := range h.keys {
+		keys[len(keys)-1-i] = k
+	}
+	h.keys = nil
+	return keys
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// executeKeyDown 按下并保持 keys（或 key），直到 key_up 或批量任务结束，用于跨步骤的组合操作（如按住 Shift 点击）
+func (e *Executor) executeKeyDown(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
+	keys, ok, err := parseKeys(payload)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, auto.Errorf(auto.ErrParam, "缺少 keys 或 key 参数")
+	}
+	for _, k := range keys {
+		keyDown(k)
+	}
+	e.heldKeys.add(keys)
+	return map[string]interface{}{"down": true, "keys": keys, "held": e.heldKeys.snapshot()}, nil
+}
+
+// executeKeyUp 松开 keys（或 key，按逆序）；未指定时松开所有 key_down 按住的键
+func (e *Executor) executeKeyUp(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
+	keys, ok, err := parseKeys(payload)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		for i := len(keys) - 1; i >= 0; i-- {
+			keyUp(keys[i])
+		}
+		e.heldKeys.remove(keys)
+	} else {
+		keys = e.heldKeys.drain()
+		for _, k := range keys {
+			keyUp(k)
+		}
+	}
+	return map[string]interface{}{"released": keys, "held": e.heldKeys.snapshot()}, nil
+}
+
+// releaseHeldKeys 批量任务结束时松开 key_down 后没有 key_up 的键，避免按键卡住影响后续任务
+func (e *Executor) releaseHeldKeys(taskID string) {
+	keys := e.heldKeys.drain()
+	if len(keys) == 0 {
+		return
+	}
+	for _, k := range keys {
+		keyUp(k)
+	}
+	log("WARN", fmt.Sprintf("[Task:%s] 任务结束时仍有按住的键，已松开: %s", taskID, strings.Join(keys, ", ")))
+}
@@ -0,0 +1,116 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+func TestKeyboardTasks(t *testing.T) {
+	origTap, origDown, origUp := keyTap, keyDown, keyUp
+	t.Cleanup(func() { keyTap, keyDown, keyUp = origTap, origDown, origUp })
+	var events []string
+	keyTap = func(key string, modifiers ...string) {
+		events = append(events, "tap:"+strings.Join(append(modifiers, key), "+"))
+	}
+	keyDown = func(key string) { events = append(events, "down:"+key) }
+	keyUp = func(key string) { events = append(events, "up:"+key) }
+	e, _ := newTestExecutor()
+	run := func(taskType, payloadJSON string) (map[string]interface{}, error) {
+		t.Helper()
+		events = nil
+		result, err := e.runAction(context.Background(), taskType, decodePayload(t, payloadJSON))
+		data, _ := result.Data.(map[string]interface{})
+		return data, err
+	}
+
+	if _, err := run(TaskTypeKeyPress, `{"keys": ["Ctrl", "C"]}`); err != nil || strings.Join(events, " ") != "tap:Ctrl+C" {
+		t.Errorf("keys: %v %v", events, err)
+	}
+	if _, err := run(TaskTypeKeyPress, `{"key": "c", "modifiers": ["command"]}`); err != nil || strings.Join(events, " ") != "tap:command+c" {
+		t.Errorf("旧格式 key + modifiers: %v %v", events, err)
+	}
+	if _, err := run(TaskTypeKeyPress, `{"keys": ["Shift", "Down"], "hold_ms": 1}`); err != nil ||
+		strings.Join(events, " ") != "down:Shift down:Down up:Down up:Shift" {
+		t.Errorf("hold_ms 应按住后按逆序松开: %v %v", events, err)
+	}
+
+	start := time.Now()
+	data, err := run(TaskTypeKeyPress, `{"sequence": [{"keys": ["Tab"]}, {"wait_ms": 30}, {"keys": ["Enter"]}]}`)
+	if err != nil || strings.Join(events, " ") != "tap:Tab tap:Enter" || time.Since(start) < 30*time.Millisecond {
+		t.Errorf("sequence: %v %v", events, err)
+	}
+	if data["sequence"] != 3 {
+		t.Errorf("sequence 结果: %v", data)
+	}
+
+	// platform_keys 按平台覆盖 keys，没有当前平台时使用 keys
+	origGOOS := keyboardGOOS
+	t.Cleanup(func() { keyboardGOOS = origGOOS })
+	for goos, want := range map[string]string{"darwin": "tap:Cmd+C", "windows": "tap:Ctrl+C", "linux": "tap:Primary+C"} {
+		keyboardGOOS = goos
+		_, err := run(TaskTypeKeyPress, `{"keys": ["Primary", "C"], "platform_keys": {"darwin": ["Cmd", "C"], "windows": ["Ctrl", "C"]}}`)
+		if err != nil || strings.Join(events, " ") != want {
+			t.Errorf("%s 上 platform_keys: %v %v", goos, events, err)
+		}
+	}
+	keyboardGOOS = origGOOS
+
+	// 无效键名返回 PARAM_ERROR 并指出键名，且不按下任何键
+	for _, payload := range []string{
+		`{"keys": ["Ctrl", "Cee"]}`,
+		`{"key": "Cee"}`,
+		`{"sequence": [{"keys": ["Tab"]}, {"keys": ["Cee"]}]}`,
+		`{"keys": ["a"], "platform_keys": {"windows": ["Alt", "Cee"]}}`,
+	} {
+		_, err := run(TaskTypeKeyPress, payload)
+		if !errors.Is(err, auto.ErrParam) || !strings.Contains(err.Error(), `"Cee"`) || len(events) != 0 {
+			t.Errorf("%s 应返回指出键名的 ErrParam 且不按键: %v %v", payload, err, events)
+		}
+	}
+	for _, payload := range []string{
+		`{}`,
+		`{"keys": []}`,
+		`{"keys": ["a"], "hold_ms": -1}`,
+		`{"sequence": [{"wait_ms": 10, "keys": ["a"]}]}`,
+		`{"sequence": [{}]}`,
+		`{"sequence": [], "keys": ["a"]}`,
+		`{"keys": ["a"], "platform_keys": {"plan9": ["a"]}}`,
+		`{"keys": ["a"], "platform_keys": ["a"]}`,
+	} {
+		if _, err := run(TaskTypeKeyPress, payload); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("%s 应返回 ErrParam: %v", payload, err)
+		}
+	}
+
+	// key_down / key_up 跨步骤按住
+	if _, err := run(TaskTypeKeyDown, `{"keys": ["Shift", "Alt"]}`); err != nil {
+		t.Fatal(err)
+	}
+	downEvents := events
+	if _, err := run(TaskTypeKeyUp, `{"key": "alt"}`); err != nil {
+		t.Fatal(err)
+	}
+	events = append(downEvents, events...)
+	if got := e.heldKeys.snapshot(); len(got) != 1 || got[0] != "Shift" {
+		t.Errorf("key_up 后仍按住的键: %v", got)
+	}
+	if _, err := e.runAction(context.Background(), TaskTypeKeyDown, decodePayload(t, `{"key": "Nope"}`)); !errors.Is(err, auto.ErrParam) {
+		t.Errorf("key_down 无效键名应返回 ErrParam: %v", err)
+	}
+	e.releaseHeldKeys("t1")
+	if strings.Join(events, " ") != "down:Shift down:Alt up:alt up:Shift" || len(e.heldKeys.snapshot()) != 0 {
+		t.Errorf("批量任务结束时应松开按住的键: %v", events)
+	}
+
+	// 未指定键时 key_up 松开所有按住的键
+	run(TaskTypeKeyDown, `{"keys": ["Ctrl", "Shift"]}`)
+	data, _ = run(TaskTypeKeyUp, `{}`)
+	if strings.Join(events, " ") != "up:Shift up:Ctrl" || len(data["held"].([]string)) != 0 {
+		t.Errorf("key_up 应按逆序松开所有按住的键: %v %v", events, data)
+	}
+}
@@ -0,0 +1,152 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+)
+
+// LocalResult 本地运行任务的结果（zoeyworker run 输出）
+type LocalResult struct {
+	TaskID        string             `json:"task_id"`
+	TaskType      string             `json:"task_type"`
+	Success       bool               `json:"success"`
+	Status        string             `json:"status"`                   // SUCCESS, FAILED, CANCELLED ...
+	FailureReason string             `json:"failure_reason,omitempty"` // NOT_FOUND, PARAM_ERROR ...
+	Error         string             `json:"error,omitempty"`
+	DurationMs    int64              `json:"duration_ms"`
+	MatchLocation *LocalMatch        `json:"match_location,omitempty"`
+	Result        json.RawMessage    `json:"result,omitempty"`
+	Steps         []*LocalStepResult `json:"steps,omitempty"` // 批量任务的步骤结果（按完成顺序）
+}
+
+// LocalMatch 匹配位置：X、Y 为目标边界的左上角，Width、Height 为目标的宽高
+type LocalMatch struct {
+	X          int     `json:"x"`
+	Y          int     `json:"y"`
+	Width      int     `json:"width,omitempty"`
+	Height     int     `json:"height,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+// LocalStepResult 批量任务中单个步骤的结果
+type LocalStepResult struct {
+	StepID           string      `json:"step_id"`
+	ActionType       string      `json:"action_type,omitempty"`
+	Status           string      `json:"status"`
+	FailureReason    string      `json:"failure_reason,omitempty"`
+	Error            string      `json:"error,omitempty"`
+	DurationMs       int64       `json:"duration_ms"`
+	MatchLocation    *LocalMatch `json:"match_location,omitempty"`
+	ScreenshotBefore string      `json:"screenshot_before,omitempty"` // 截图文件路径（指定截图目录时）
+	ScreenshotAfter  string      `json:"screenshot_after,omitempty"`
+}
+
+// RunLocal 在本地执行任务，不连接服务端：原本发送给服务端的步骤结果和最终结果被收集后返回
+// screenshotDir 非空时将步骤截图写入该目录（<步骤 ID>_before.jpg / _after.jpg），否则丢弃截图
+func RunLocal(taskType, payloadJSON, screenshotDir string) *LocalResult {
+	e := NewExecutor(nil)
+	taskID := fmt.Sprintf("local_%d", time.Now().UnixMilli())
+	out := &LocalResult{TaskID: taskID, TaskType: taskType, Status: "FAILED"}
+
+	var mu sync.Mutex
+	var final *pb.TaskResult
+	e.SetMessageSink(func(msg *pb.WorkerMessage) {
+		result := msg.GetTaskResult()
+		if result == nil {
+			return // 确认、进度消息
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case result.TaskId == taskID:
+			final = result
+		case strings.HasPrefix(result.TaskId, "step_"):
+			out.Steps = append(out.Steps, localStepResult(len(out.Steps)+1, result, screenshotDir))
+		}
+	})
+
+	start := time.Now()
+	e.Execute(taskID, taskType, payloadJSON)
+
+	mu.Lock()
+	defer mu.Unlock()
+	out.DurationMs = time.Since(start).Milliseconds()
+	if final == nil {
+		out.Error = "任务未返回结果"
+		return out
+	}
+	out.Success = final.Success
+	out.Status = strings.TrimPrefix(final.Status.String(), "TASK_STATUS_")
+	out.Error = final.Message
+	out.DurationMs = final.DurationMs
+	if final.FailureReason != pb.FailureReason_FAILURE_REASON_UNSPECIFIED {
+		out.FailureReason = strings.TrimPrefix(final.FailureReason.String(), "FAILURE_REASON_")
+	}
+	out.MatchLocation = localMatch(final.MatchLocation)
+	if final.ResultJson != "" && final.ResultJson != "{}" && json.Valid([]byte(final.ResultJson)) {
+		out.Result = json.RawMessage(final.ResultJson)
+	}
+	return out
+}
+
+// localStepResult 从步骤结果消息提取输出，截图写入 screenshotDir（文件名前缀为步骤 ID，没有时为序号）
+func localStepResult(index int, result *pb.TaskResult, screenshotDir string) *LocalStepResult {
+	step := &LocalStepResult{Status: "FAILED", Error: result.Message, DurationMs: result.DurationMs, MatchLocation: localMatch(result.MatchLocation)}
+	var detail StepExecutionResult
+	if err := json.Unmarshal([]byte(result.ResultJson), &detail); err != nil {
+		return step
+	}
+	step.StepID = detail.StepID
+	step.ActionType = detail.ActionType
+	step.Status = detail.Status
+	step.FailureReason = detail.FailureReason
+	if detail.ErrorMessage != "" {
+		step.Error = detail.ErrorMessage
+	}
+	if screenshotDir != "" {
+		name := detail.StepID
+		if name == "" {
+			name = fmt.Sprintf("step%d", index)
+		}
+		step.ScreenshotBefore = saveLocalScreenshot(screenshotDir, name+"_before.jpg", detail.ScreenshotBefore)
+		step.ScreenshotAfter = saveLocalScreenshot(screenshotDir, name+"_after.jpg", detail.ScreenshotAfter)
+	}
+	return step
+}
+
+// localMatch 转换匹配位置
+func localMatch(loc *pb.MatchLocation) *LocalMatch {
+	if loc == nil {
+		return nil
+	}
+	return &LocalMatch{X: int(loc.X), Y: int(loc.Y), Width: int(loc.Width), Height: int(loc.Height), Confidence: float64(loc.Confidence)}
+}
+
+// saveLocalScreenshot 将 data URL 截图写入目录，返回文件路径（没有截图或写入失败时返回空字符串）
+func saveLocalScreenshot(dir, name, dataURL string) string {
+	if dataURL == "" {
+		return ""
+	}
+	data, err := decodeDataURL(dataURL)
+	if err != nil {
+		log("WARN", fmt.Sprintf("保存截图 %s 失败: %v", name, err))
+		return ""
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log("WARN", fmt.Sprintf("创建截图目录失败: %v", err))
+		return ""
+	}
+	path := filepath.Join(dir, filepath.Base(name))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log("WARN", fmt.Sprintf("保存截图 %s 失败: %v", name, err))
+		return ""
+	}
+	return path
+}
@@ -0,0 +1,34 @@
+package executor
+
+import (
+	"testing"
+)
+
+func TestRunLocal(t *testing.T) {
+	result := RunLocal(TaskTypeWaitTime, `{"duration": 1}`, "")
+	if !result.Success || result.Status != "SUCCESS" || len(result.Result) == 0 {
+		t.Errorf("wait_time 应成功并返回结果, 实际 %+v", result)
+	}
+
+	result = RunLocal("no_such_type", `{}`, "")
+	if result.Success || result.Status != "FAILED" || result.Error == "" {
+		t.Errorf("未知任务类型应失败, 实际 %+v", result)
+	}
+
+	result = RunLocal(TaskTypeWaitTime, `not json`, "")
+	if result.Success || result.FailureReason != "PARAM_ERROR" {
+		t.Errorf("无效 payload 应返回 PARAM_ERROR, 实际 %+v", result)
+	}
+
+	payload := `{"screenshot_mode": "never", "steps": [
+		{"step_id": "s1", "task_type": "wait_time", "params": {"duration": 1}},
+		{"step_id": "s2", "task_type": "wait_time", "params": {"duration": 1}}
+	]}`
+	result = RunLocal(TaskTypeDebugCase, payload, t.TempDir())
+	if !result.Success {
+		t.Fatalf("debug_case 应成功, 实际 %+v", result)
+	}
+	if len(result.Steps) != 2 || result.Steps[0].StepID != "s1" || result.Steps[1].Status != "SUCCESS" {
+		t.Errorf("应收集两个步骤结果, 实际 %+v", result.Steps)
+	}
+}
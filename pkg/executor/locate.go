@@ -0,0 +1,143 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	autoimage "github.com/zoeyai/zoeyworker/pkg/auto/image"
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+	"github.com/zoeyai/zoeyworker/pkg/auto/text"
+	"github.com/zoeyai/zoeyworker/pkg/vision/cv"
+)
+
+// 可替换的定位与标注实现（便于测试）
+var (
+	locateImage         = autoimage.FindImage
+	findAllImages       = autoimage.FindAllImages
+	annotateImageMatch  = autoimage.AnnotateMatch
+	annotateImageSearch = autoimage.AnnotateSearch
+	annotateTextMatch   = text.AnnotateText
+	annotateSearchArea  = func(opts ...auto.Option) (image.Image, error) {
+		return screen.Annotate(auto.ApplyOptions(opts...), nil)
+	}
+)
+
+// LocateResult 定位测试结果
+type LocateResult struct {
+	Found       bool        `json:"found"`
+	X           int         `json:"x"` // 目标中心（屏幕坐标）
+	Y           int         `json:"y"`
+	Bounds      *BoundsInfo `json:"bounds,omitempty"`
+	Confidence  float64     `json:"confidence"` // 未找到图像时为最佳候选的置信度
+	MatchedText string      `json:"matched_text,omitempty"`
+	DurationMs  int64       `json:"duration_ms"` // 查找耗时（不含标注截图）
+	Message     string      `json:"message,omitempty"`
+	Screenshot  string      `json:"screenshot,omitempty"` // 标注后的截图（缩小的 JPEG data URL）
+}
+
+// LocateImage 在当前屏幕上查找一次图像（文件路径或 base64），返回位置和标注截图
+// 与执行器使用相同的查找路径，但只截图不移动鼠标、不点击，也不需要连接服务端；threshold 为 0 时使用默认阈值
+func LocateImage(source string, threshold float64) (*LocateResult, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, auto.Errorf(auto.ErrParam, "缺少图像（文件路径或 base64）")
+	}
+	if threshold < 0 || threshold > 1 {
+		return nil, auto.Errorf(auto.ErrParam, "threshold 必须在 0-1 之间: %v", threshold)
+	}
+	opts := []auto.Option{auto.WithTimeout(0)}
+	if threshold > 0 {
+		opts = append(opts, auto.WithThreshold(threshold))
+	}
+
+	start := time.Now()
+	match, err := locateImage(source, opts...)
+	result := &LocateResult{DurationMs: time.Since(start).Milliseconds()}
+	if err != nil && !errors.Is(err, auto.ErrTimeout) {
+		return nil, err
+	}
+
+	var img image.Image
+	if match != nil {
+		region := autoimage.MatchRegion(match)
+		result.Found = true
+		result.X, result.Y = match.Result.X, match.Result.Y
+		result.Bounds = &BoundsInfo{X: region.X, Y: region.Y, Width: region.Width, Height: region.Height}
+		result.Confidence = match.Confidence
+		img, err = annotateImageMatch(match, opts...)
+	} else {
+		result.Message = "未找到图像"
+		var candidate *cv.MatchResult
+		img, candidate, err = annotateImageSearch(source, opts...)
+		if candidate != nil {
+			result.Confidence = candidate.Confidence
+			result.Message = fmt.Sprintf("未找到图像，最佳候选置信度 %.3f (%d, %d)", candidate.Confidence, candidate.Result.X, candidate.Result.Y)
+		}
+	}
+	result.Screenshot = encodeLocateScreenshot(img, err)
+	return result, nil
+}
+
+// LocateText 在当前屏幕（或 region 内）OCR 查找一次文字，返回位置和标注截图
+// 与执行器使用相同的查找路径，但只截图不移动鼠标、不点击，也不需要连接服务端
+func LocateText(target string, region *auto.Region) (*LocateResult, error) {
+	if strings.TrimSpace(target) == "" {
+		return nil, auto.Errorf(auto.ErrParam, "缺少要查找的文字")
+	}
+	if !ocrAvailable() {
+		return nil, fmt.Errorf("OCR 功能未安装，请在客户端设置中下载安装 OCR 支持")
+	}
+	opts := []auto.Option{auto.WithTimeout(0)}
+	if region != nil {
+		if region.Width <= 0 || region.Height <= 0 {
+			return nil, auto.Errorf(auto.ErrParam, "region 的宽高必须大于 0: %+v", *region)
+		}
+		opts = append(opts, auto.WithRegion(region.X, region.Y, region.Width, region.Height))
+	}
+
+	start := time.Now()
+	match, reason, err := findText(target, opts)
+	if err != nil {
+		return nil, err
+	}
+	result := &LocateResult{DurationMs: time.Since(start).Milliseconds()}
+
+	var img image.Image
+	if match != nil {
+		result.Found = true
+		result.X, result.Y = match.Position.X, match.Position.Y
+		if match.Bounds != (auto.Region{}) {
+			result.Bounds = &BoundsInfo{X: match.Bounds.X, Y: match.Bounds.Y, Width: match.Bounds.Width, Height: match.Bounds.Height}
+		}
+		result.Confidence = match.Confidence
+		result.MatchedText = match.Text
+		img, err = annotateTextMatch(match, opts...)
+	} else {
+		result.Message = "未找到文字"
+		if reason != nil {
+			result.Message = reason.Error()
+		}
+		img, err = annotateSearchArea(opts...)
+	}
+	result.Screenshot = encodeLocateScreenshot(img, err)
+	return result, nil
+}
+
+// encodeLocateScreenshot 将标注截图缩小并编码为 JPEG data URL（截图失败时只记录日志）
+func encodeLocateScreenshot(img image.Image, err error) string {
+	if err == nil {
+		img, _, err = screen.DownscaleImage(img, screen.DefaultScreenshotMaxWidth)
+	}
+	var data string
+	if err == nil {
+		data, err = screen.ImageToBase64(img, "jpeg", DefaultScreenshotQuality)
+	}
+	if err != nil {
+		log("WARN", fmt.Sprintf("定位测试截图失败: %v", err))
+		return ""
+	}
+	return data
+}
@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/text"
+	"github.com/zoeyai/zoeyworker/pkg/vision/cv"
+)
+
+func TestLocateImage(t *testing.T) {
+	mockLocate(t)
+	var got *auto.Options
+	locateImage = func(source string, opts ...auto.Option) (*cv.MatchResult, error) {
+		got = auto.ApplyOptions(opts...)
+		if source == "missing.png" {
+			return nil, auto.Errorf(auto.ErrTimeout, "未找到")
+		}
+		return &cv.MatchResult{
+			Result:     cv.Point{X: 110, Y: 220},
+			Rectangle:  cv.Rectangle{TopLeft: cv.Point{X: 100, Y: 210}, TopRight: cv.Point{X: 120, Y: 210}, BottomLeft: cv.Point{X: 100, Y: 230}, BottomRight: cv.Point{X: 120, Y: 230}},
+			Confidence: 0.93,
+		}, nil
+	}
+
+	result, err := LocateImage("button.png", 0.9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Found || result.X != 110 || result.Bounds == nil || *result.Bounds != (BoundsInfo{X: 100, Y: 210, Width: 20, Height: 20}) || result.Confidence != 0.93 {
+		t.Errorf("结果 = %+v", result)
+	}
+	if got.Threshold != 0.9 || got.Timeout != 0 {
+		t.Errorf("应只查找一次并使用传入的阈值: %+v", got)
+	}
+	if !strings.HasPrefix(result.Screenshot, "data:image/jpeg;base64,") {
+		t.Errorf("应返回 JPEG 标注截图: %.40s", result.Screenshot)
+	}
+
+	result, err = LocateImage("missing.png", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Found || result.Confidence != 0.42 || !strings.Contains(result.Message, "0.420") {
+		t.Errorf("未找到时应返回最佳候选: %+v", result)
+	}
+	if _, err := LocateImage("button.png", 1.5); !errors.Is(err, auto.ErrParam) {
+		t.Errorf("阈值超出范围应返回参数错误: %v", err)
+	}
+}
+
+func TestLocateText(t *testing.T) {
+	mockLocate(t)
+	var got *auto.Options
+	findTextOnce = func(target string, opts ...auto.Option) (*text.TextMatch, error) {
+		got = auto.ApplyOptions(opts...)
+		if target == "Missing" {
+			return nil, auto.Errorf(auto.ErrNotFound, "未找到文字: %s", target)
+		}
+		return &text.TextMatch{Position: auto.Point{X: 50, Y: 60}, Text: "Save As", Confidence: 0.9}, nil
+	}
+
+	result, err := LocateText("Save", &auto.Region{X: 0, Y: 0, Width: 800, Height: 600})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Found || result.X != 50 || result.MatchedText != "Save As" || result.Bounds != nil || result.Screenshot == "" {
+		t.Errorf("结果 = %+v", result)
+	}
+	if got.Region == nil || got.Region.Width != 800 {
+		t.Errorf("region 未传给识别: %+v", got.Region)
+	}
+
+	// 截图失败不影响查找结果
+	result, err = LocateText("Missing", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Found || !strings.Contains(result.Message, "Missing") || result.Screenshot != "" {
+		t.Errorf("未找到时 = %+v", result)
+	}
+	if _, err := LocateText("Save", &auto.Region{Width: 0, Height: 10}); !errors.Is(err, auto.ErrParam) {
+		t.Errorf("无效 region 应返回参数错误: %v", err)
+	}
+}
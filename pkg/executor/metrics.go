@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/metrics"
+)
+
+var (
+	// zoey_tasks_executed_total 执行完成（发送最终结果）的任务数，含失败、超时和取消
+	tasksExecuted = metrics.NewCounter("zoey_tasks_executed_total", "Tasks that finished and reported a final result.")
+	// zoey_tasks_failed_total 结果不是成功的任务数（失败、超时、取消）
+	tasksFailed = metrics.NewCounter("zoey_tasks_failed_total", "Tasks that finished without success (failed, timed out or cancelled).")
+	// zoey_step_duration_seconds 单个步骤（动作）的执行耗时，按动作类型（action 标签）分组
+	stepDuration = metrics.NewHistogramVec("zoey_step_duration_seconds", "Step execution time by action type.", "action", metrics.DurationBuckets)
+	// zoey_step_screenshot_bytes 步骤截图编码后（Base64 data URL）的大小
+	stepScreenshotBytes = metrics.NewHistogram("zoey_step_screenshot_bytes", "Encoded size of step screenshots (base64 data URL).", metrics.SizeBuckets)
+)
+
+// recordTaskResult 记录任务最终结果（指标和执行器自身的统计）
+func (e *Executor) recordTaskResult(success bool) {
+	tasksExecuted.Inc()
+	e.tasksExecuted.Add(1)
+	if !success {
+		tasksFailed.Inc()
+		e.tasksFailed.Add(1)
+	}
+}
+
+// Stats 执行器创建以来执行完成的任务数和其中未成功的任务数
+func (e *Executor) Stats() (executed, failed int64) {
+	return e.tasksExecuted.Load(), e.tasksFailed.Load()
+}
+
+// recordStep 记录已注册动作的执行耗时
+func recordStep(taskType string, start time.Time) {
+	stepDuration.Observe(taskType, time.Since(start).Seconds())
+}
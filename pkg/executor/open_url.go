@@ -0,0 +1,78 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/process"
+)
+
+// openURL 打开 URL（可替换，便于测试）
+var openURL = process.OpenURL
+
+// defaultOpenURLTimeout open_url 指定 wait_for_title 但未指定 timeout 时等待窗口的时间（页面加载通常比普通窗口慢）
+const defaultOpenURLTimeout = 15 * time.Second
+
+// executeOpenURL 用默认程序或 browser 指定的浏览器打开 url：默认只允许 http / https，allow_any_scheme 为 true 时不限制协议
+// 指定 wait_for_title 时在 timeout（秒，默认 15）内等待标题包含该文字的窗口出现，窗口边界作为 TargetBounds
+// 能确定浏览器进程时返回其 PID、名称和路径
+func (e *Executor) executeOpenURL(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
+	rawURL, _ := payload["url"].(string)
+	if rawURL == "" {
+		return nil, auto.Errorf(auto.ErrParam, "缺少 url 参数")
+	}
+	allowAnyScheme, _ := payload["allow_any_scheme"].(bool)
+	u, err := process.ValidateURL(rawURL, allowAnyScheme)
+	if err != nil {
+		return nil, err
+	}
+	browser, _ := payload["browser"].(string)
+	waitTitle, _ := payload["wait_for_title"].(string)
+	opts, err := e.parseAutoOptions(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := payload["timeout"]; !ok {
+		opts = append(opts, auto.WithTimeout(defaultOpenURLTimeout))
+	}
+
+	proc, err := openURL(u.String(), browser)
+	if err != nil {
+		return nil, fmt.Errorf("打开 URL 失败: %w", err)
+	}
+	if proc == nil && browser != "" {
+		// 通过系统程序转交（如 macOS open -a）时按浏览器名称查找进程
+		if matched, err := (processQuery{name: browser}).match(); err == nil && len(matched) > 0 {
+			proc = &matched[0]
+		}
+	}
+
+	data := map[string]interface{}{"opened": true, "url": u.String()}
+	if browser != "" {
+		data["browser"] = browser
+	}
+	if proc != nil {
+		data["pid"] = proc.PID
+		data["process_name"] = proc.Name
+		data["process_path"] = proc.Path
+	}
+	if waitTitle == "" {
+		return data, nil
+	}
+
+	start := time.Now()
+	w, err := pollWindow(auto.ApplyOptions(opts...), "", waitTitle, TitleMatchContains)
+	if err != nil {
+		return data, err
+	}
+	// pid 保留为浏览器进程，窗口所属进程另见 window_pid
+	wd := windowData(w, result)
+	for _, key := range []string{"window_handle", "title", "x", "y", "bounds"} {
+		data[key] = wd[key]
+	}
+	data["window_pid"] = w.PID
+	data["waited_ms"] = time.Since(start).Milliseconds()
+	return data, nil
+}
@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/window"
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+	"github.com/zoeyai/zoeyworker/pkg/process"
+)
+
+func TestOpenURL(t *testing.T) {
+	origOpen, origGetWindows, origList := openURL, getWindows, listProcesses
+	t.Cleanup(func() { openURL, getWindows, listProcesses = origOpen, origGetWindows, origList })
+	type call struct{ url, browser string }
+	var calls []call
+	openURL = func(rawURL, browser string) (*process.ProcessInfo, error) {
+		calls = append(calls, call{rawURL, browser})
+		if browser == "chrome" {
+			return &process.ProcessInfo{PID: 300, Name: "google-chrome", Path: "/usr/bin/google-chrome"}, nil
+		}
+		return nil, nil
+	}
+	listProcesses = func() ([]process.ProcessInfo, error) {
+		return []process.ProcessInfo{{PID: 400, Name: "Firefox", Path: "/Applications/Firefox.app/Contents/MacOS/firefox"}}, nil
+	}
+	// 第 2 次查询时页面窗口才出现
+	var polls atomic.Int32
+	getWindows = func(filter ...string) ([]window.WindowInfo, error) {
+		if polls.Add(1) < 2 {
+			return nil, nil
+		}
+		return []window.WindowInfo{{PID: 301, Handle: 7, Title: "Login - Example - Google Chrome", OwnerName: "chrome", Bounds: auto.Region{Width: 1280, Height: 800}}}, nil
+	}
+	e, recorder := newTestExecutor()
+	ctx := context.Background()
+
+	res, err := e.runAction(ctx, TaskTypeOpenURL, decodePayload(t, `{"url": "https://example.com/login?a=1&b=2"}`))
+	if err != nil || len(calls) != 1 || calls[0] != (call{"https://example.com/login?a=1&b=2", ""}) {
+		t.Fatalf("应使用默认程序打开: %v %v", calls, err)
+	}
+	if _, ok := res.Data.(map[string]interface{})["pid"]; ok {
+		t.Errorf("默认程序打开时不应返回进程信息: %+v", res.Data)
+	}
+
+	e.Execute("chrome", TaskTypeOpenURL, `{"url": "https://example.com/login", "browser": "chrome", "wait_for_title": "login", "timeout": 2, "interval_ms": 10}`)
+	results := recorder.results("chrome")
+	if len(results) != 1 || !results[0].Success || results[0].MatchLocation == nil || results[0].MatchLocation.Width != 1280 {
+		t.Fatalf("应等待页面窗口出现: %+v", results)
+	}
+	if !strings.Contains(results[0].ResultJson, `"pid":300`) || !strings.Contains(results[0].ResultJson, `"window_pid":301`) {
+		t.Errorf("结果应包含浏览器进程和窗口信息: %s", results[0].ResultJson)
+	}
+
+	res, err = e.runAction(ctx, TaskTypeOpenURL, decodePayload(t, `{"url": "https://example.com", "browser": "firefox"}`))
+	if err != nil || res.Data.(map[string]interface{})["pid"] != 400 {
+		t.Errorf("未直接启动浏览器时应按名称查找进程: %+v %v", res.Data, err)
+	}
+
+	e.Execute("timeout", TaskTypeOpenURL, `{"url": "https://example.com", "wait_for_title": "Dashboard", "timeout": 0.05, "interval_ms": 10}`)
+	if results := recorder.results("timeout"); len(results) != 1 || results[0].Status != pb.TaskStatus_TASK_STATUS_TIMEOUT {
+		t.Errorf("窗口未出现应超时: %+v", results)
+	}
+
+	if _, err := e.runAction(ctx, TaskTypeOpenURL, decodePayload(t, `{"url": "file:///tmp/report.html", "allow_any_scheme": true}`)); err != nil {
+		t.Errorf("allow_any_scheme 时应允许其他协议: %v", err)
+	}
+	calls = nil
+	for _, payload := range []string{`{}`, `{"url": "example.com"}`, `{"url": "file:///etc/passwd"}`, `{"url": "https://example.com", "timeout": -1}`} {
+		if _, err := e.runAction(ctx, TaskTypeOpenURL, decodePayload(t, payload)); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("%s 应返回 ErrParam: %v", payload, err)
+		}
+	}
+	if len(calls) != 0 {
+		t.Errorf("参数错误时不应打开 URL: %v", calls)
+	}
+}
@@ -0,0 +1,196 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+)
+
+// pixelSampleRadius 取色时截取的取样点周围区域半径（像素），断言失败时附带该区域截图
+const pixelSampleRadius = 20
+
+// capturePixelRegion 截取取样区域（截图坐标，可替换，便于测试）
+var capturePixelRegion = screen.CaptureRegion
+
+// RGBColor 像素颜色
+type RGBColor struct {
+	R uint8
+	G uint8
+	B uint8
+}
+
+// Hex 返回 #RRGGBB 格式
+func (c RGBColor) Hex() string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
+
+// parseHexColor 解析 #RRGGBB、RRGGBB 或 #RGB 格式的颜色
+func parseHexColor(s string) (RGBColor, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return RGBColor{}, auto.Errorf(auto.ErrParam, "颜色格式应为 #RRGGBB: %s", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return RGBColor{}, auto.Errorf(auto.ErrParam, "颜色格式应为 #RRGGBB: %s", s)
+	}
+	return RGBColor{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}, nil
+}
+
+// colorDistance 各通道差值的最大值
+func colorDistance(a, b RGBColor) int {
+	diff := func(x, y uint8) int {
+		if x > y {
+			return int(x - y)
+		}
+		return int(y - x)
+	}
+	return max(diff(a.R, b.R), diff(a.G, b.G), diff(a.B, b.B))
+}
+
+// pixelSample 一次取色结果
+type pixelSample struct {
+	X, Y   int         // 取样点（截图坐标）
+	Color  RGBColor    // 取样点颜色
+	Region auto.Region // 截取的取样点周围区域
+	Image  image.Image // 取样区域截图
+}
+
+// samplePixel 截取 (x, y) 周围的区域并读取 (x, y) 的颜色
+// 坐标为截图像素坐标，与 robotgo 输入坐标的换算由 CaptureRegion 完成；
+// 截图像素与请求的区域尺寸不一致时（如 macOS Retina 返回物理像素）按比例定位取样点
+func samplePixel(x, y int) (*pixelSample, error) {
+	region := auto.Region{X: x - pixelSampleRadius, Y: y - pixelSampleRadius, Width: 2*pixelSampleRadius + 1, Height: 2*pixelSampleRadius + 1}
+	// 取样点在主显示器内时将区域裁剪到屏幕内（其他显示器上的点不裁剪）
+	if area := fullScreenArea(); x >= area.X && y >= area.Y && x < area.X+area.Width && y < area.Y+area.Height {
+		x0, y0 := max(region.X, area.X), max(region.Y, area.Y)
+		x1, y1 := min(region.X+region.Width, area.X+area.Width), min(region.Y+region.Height, area.Y+area.Height)
+		region = auto.Region{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+	}
+
+	img, err := capturePixelRegion(region.X, region.Y, region.Width, region.Height)
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return nil, fmt.Errorf("取色失败: 截图为空")
+	}
+	px := bounds.Min.X + (x-region.X)*bounds.Dx()/region.Width
+	py := bounds.Min.Y + (y-region.Y)*bounds.Dy()/region.Height
+	c := color.RGBAModel.Convert(img.At(px, py)).(color.RGBA)
+	return &pixelSample{
+		X:      x,
+		Y:      y,
+		Color:  RGBColor{R: c.R, G: c.G, B: c.B},
+		Region: region,
+		Image:  img,
+	}, nil
+}
+
+// resolvePixelPoint 解析取色位置：指定 image 时取匹配图像的中心（支持 timeout、relative_to 等查找选项），
+// 否则同 resolvePoint（x/y 或 x_pct/y_pct）
+func (e *Executor) resolvePixelPoint(ctx context.Context, payload map[string]interface{}, result *ActionResult) (int, int, error) {
+	imagePath, _ := payload["image"].(string)
+	if imagePath == "" {
+		return resolvePoint(payload)
+	}
+	opts, err := e.parseLocatorOptions(ctx, payload, result)
+	if err != nil {
+		return 0, 0, err
+	}
+	match, err := locateImage(imagePath, opts...)
+	if err != nil {
+		return 0, 0, err
+	}
+	setImageMatch(result, match)
+	return match.Result.X, match.Result.Y, nil
+}
+
+// pixelData 取色结果数据
+func pixelData(sample *pixelSample) map[string]interface{} {
+	return map[string]interface{}{
+		"x":   sample.X,
+		"y":   sample.Y,
+		"r":   int(sample.Color.R),
+		"g":   int(sample.Color.G),
+		"b":   int(sample.Color.B),
+		"hex": sample.Color.Hex(),
+	}
+}
+
+// executeGetPixelColor 获取像素颜色
+func (e *Executor) executeGetPixelColor(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
+	x, y, err := e.resolvePixelPoint(ctx, payload, result)
+	if err != nil {
+		return nil, err
+	}
+	sample, err := samplePixel(x, y)
+	if err != nil {
+		return nil, err
+	}
+	if result.TargetBounds == nil {
+		result.TargetBounds = &BoundsInfo{X: x, Y: y, Width: 1, Height: 1}
+	}
+	return pixelData(sample), nil
+}
+
+// executeAssertPixelColor 断言像素颜色：各通道与 expected 的差值均不超过 tolerance（0-255，默认 0）
+// 失败时返回期望和实际颜色，并附带取样点周围区域的截图（sample_screenshot）
+func (e *Executor) executeAssertPixelColor(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
+	expectedStr, ok := payload["expected"].(string)
+	if !ok || expectedStr == "" {
+		return nil, auto.Errorf(auto.ErrParam, "缺少 expected 参数（#RRGGBB）")
+	}
+	expected, err := parseHexColor(expectedStr)
+	if err != nil {
+		return nil, err
+	}
+	tolerance := 0
+	if raw, ok := payload["tolerance"]; ok {
+		v, ok := raw.(float64)
+		if !ok || v < 0 || v > 255 {
+			return nil, auto.Errorf(auto.ErrParam, "tolerance 必须在 0-255 之间: %v", raw)
+		}
+		tolerance = int(v)
+	}
+
+	x, y, err := e.resolvePixelPoint(ctx, payload, result)
+	if err != nil {
+		return nil, err
+	}
+	sample, err := samplePixel(x, y)
+	if err != nil {
+		return nil, err
+	}
+	if result.TargetBounds == nil {
+		result.TargetBounds = &BoundsInfo{X: x, Y: y, Width: 1, Height: 1}
+	}
+
+	data := pixelData(sample)
+	data["expected"] = expected.Hex()
+	data["tolerance"] = tolerance
+	distance := colorDistance(sample.Color, expected)
+	data["distance"] = distance
+	if distance > tolerance {
+		data["sample_region"] = &BoundsInfo{X: sample.Region.X, Y: sample.Region.Y, Width: sample.Region.Width, Height: sample.Region.Height}
+		if shot, err := screen.ImageToBase64(sample.Image, "png", 0); err == nil {
+			data["sample_screenshot"] = shot
+		} else {
+			log("WARN", fmt.Sprintf("编码取样区域截图失败: %v", err))
+		}
+		return data, auto.Errorf(ErrAssertionFailed, "断言失败: (%d, %d) 的颜色为 %s，期望 %s（容差 %d，差值 %d）",
+			x, y, sample.Color.Hex(), expected.Hex(), tolerance, distance)
+	}
+	data["asserted"] = true
+	return data, nil
+}
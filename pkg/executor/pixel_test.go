@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+	"github.com/zoeyai/zoeyworker/pkg/vision/cv"
+)
+
+func TestPixelColor(t *testing.T) {
+	mockLocate(t)
+	origCapture, origArea := capturePixelRegion, fullScreenArea
+	t.Cleanup(func() { capturePixelRegion, fullScreenArea = origCapture, origArea })
+	fullScreenArea = func() auto.Region { return auto.Region{Width: 1000, Height: 800} }
+	// 模拟 HiDPI：截图为请求区域的 2 倍像素，(300, 200) 为红色，其余为白色
+	var regions []auto.Region
+	capturePixelRegion = func(x, y, w, h int) (image.Image, error) {
+		regions = append(regions, auto.Region{X: x, Y: y, Width: w, Height: h})
+		img := image.NewRGBA(image.Rect(0, 0, 2*w, 2*h))
+		for py := 0; py < 2*h; py++ {
+			for px := 0; px < 2*w; px++ {
+				c := color.RGBA{255, 255, 255, 255}
+				if x+px/2 == 300 && y+py/2 == 200 {
+					c = color.RGBA{0xE0, 0x10, 0x20, 255}
+				}
+				img.Set(px, py, c)
+			}
+		}
+		return img, nil
+	}
+	locateImage = func(source string, opts ...auto.Option) (*cv.MatchResult, error) {
+		return &cv.MatchResult{
+			Result:     cv.Point{X: 300, Y: 200},
+			Rectangle:  cv.Rectangle{TopLeft: cv.Point{X: 290, Y: 190}, TopRight: cv.Point{X: 310, Y: 190}, BottomLeft: cv.Point{X: 290, Y: 210}, BottomRight: cv.Point{X: 310, Y: 210}},
+			Confidence: 0.95,
+		}, nil
+	}
+	e, recorder := newTestExecutor()
+
+	e.Execute("get", TaskTypeGetPixelColor, `{"x": 300, "y": 200}`)
+	res := recorder.results("get")
+	if len(res) != 1 || !res[0].Success || !strings.Contains(res[0].ResultJson, `"hex":"#E01020"`) || !strings.Contains(res[0].ResultJson, `"r":224`) {
+		t.Fatalf("应返回取样点颜色: %+v", res)
+	}
+	if regions[0] != (auto.Region{X: 280, Y: 180, Width: 41, Height: 41}) {
+		t.Errorf("取样区域错误: %+v", regions[0])
+	}
+
+	data, err := e.runAction(context.Background(), TaskTypeGetPixelColor, decodePayload(t, `{"x": 0, "y": 5}`))
+	if err != nil || data.Data.(map[string]interface{})["hex"] != "#FFFFFF" {
+		t.Fatalf("屏幕边缘取色失败: %+v %v", data, err)
+	}
+	if last := regions[len(regions)-1]; last.X != 0 || last.Y != 0 || last.Width != 21 || last.Height != 26 {
+		t.Errorf("取样区域应裁剪到屏幕内: %+v", last)
+	}
+
+	e.Execute("assert-image", TaskTypeAssertPixelColor, `{"image": "led.png", "expected": "#E81828", "tolerance": 8}`)
+	if res := recorder.results("assert-image"); len(res) != 1 || !res[0].Success || res[0].MatchLocation == nil || res[0].MatchLocation.X != 290 || res[0].MatchLocation.Width != 20 {
+		t.Errorf("容差内应成功并取匹配图像的中心: %+v", res)
+	}
+
+	e.Execute("assert-fail", TaskTypeAssertPixelColor, `{"x": 300, "y": 200, "expected": "#0f0"}`)
+	res = recorder.results("assert-fail")
+	if len(res) != 1 || res[0].Success || res[0].FailureReason != pb.FailureReason_FAILURE_REASON_ASSERTION_FAILED {
+		t.Fatalf("颜色不符应为 ASSERTION_FAILED: %+v", res)
+	}
+	if !strings.Contains(res[0].Message, "#E01020") || !strings.Contains(res[0].Message, "#00FF00") {
+		t.Errorf("错误信息应包含实际和期望颜色: %s", res[0].Message)
+	}
+	if !strings.Contains(res[0].ResultJson, `"sample_screenshot":"data:image/png;base64,`) {
+		t.Errorf("失败时应附带取样区域截图: %s", truncateString(res[0].ResultJson, 300))
+	}
+
+	step := e.executeStepWithScreenshots(context.Background(), "", "s1", TaskTypeAssertPixelColor,
+		decodePayload(t, `{"x": 300, "y": 200, "expected": "#FFFFFF"}`), screenshotOptions{Mode: ScreenshotModeNever}, nil)
+	if step.Status != "FAILED" || step.ActionType != "assert" || !strings.HasPrefix(step.SampleScreenshot, "data:image/png;base64,") {
+		t.Errorf("步骤结果应附带取样区域截图: %+v", step)
+	}
+
+	for _, payload := range []string{
+		`{"x": 300, "y": 200}`,
+		`{"x": 300, "y": 200, "expected": "red"}`,
+		`{"x": 300, "y": 200, "expected": "#12345G"}`,
+		`{"x": 300, "y": 200, "expected": "#FFFFFF", "tolerance": 300}`,
+		`{"expected": "#FFFFFF"}`,
+	} {
+		if _, err := e.runAction(context.Background(), TaskTypeAssertPixelColor, decodePayload(t, payload)); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("%s 应返回 ErrParam: %v", payload, err)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+	"github.com/zoeyai/zoeyworker/pkg/process"
+)
+
+// 可替换的进程查询与终止实现（便于测试）
+var (
+	listProcesses = process.GetProcesses
+	stopProcess   = process.StopProcess
+	stopProcesses = process.StopProcesses
+)
+
+// defaultProcessPollInterval assert_process_running 指定 wait_timeout 时的默认轮询间隔
+const defaultProcessPollInterval = 500 * time.Millisecond
+
+// processListError 获取进程列表失败：属于系统错误，不能当作"进程不存在"
+func processListError(err error) error {
+	return newTaskError(pb.TaskStatus_TASK_STATUS_FAILED, pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR, err.Error())
+}
+
+// processQuery assert_process_running 的进程条件：pid 或名称（默认忽略大小写和 .exe 后缀的部分匹配）
+type processQuery struct {
+	pid    int
+	name   string
+	strict bool
+}
+
+func (q processQuery) String() string {
+	if q.pid > 0 {
+		return fmt.Sprintf("PID %d", q.pid)
+	}
+	return q.name
+}
+
+// match 查找满足条件的进程（排除 Worker 自身）
+func (q processQuery) match() ([]process.ProcessInfo, error) {
+	processes, err := listProcesses()
+	if err != nil {
+		return nil, processListError(err)
+	}
+	selfPID := os.Getpid()
+	matched := []process.ProcessInfo{}
+	for _, proc := range processes {
+		if proc.PID == selfPID {
+			continue
+		}
+		if q.pid > 0 && proc.PID == q.pid || q.pid <= 0 && process.MatchName(proc.Name, q.name, q.strict) {
+			matched = append(matched, proc)
+		}
+	}
+	return matched, nil
+}
+
+// parsePID 解析正整数 PID，未指定时返回 0
+func parsePID(payload map[string]interface{}, key string) (int, error) {
+	raw, ok := payload[key]
+	if !ok {
+		return 0, nil
+	}
+	v, ok := raw.(float64)
+	if !ok || v < 1 || v != float64(int(v)) {
+		return 0, auto.Errorf(auto.ErrParam, "%s 必须是正整数: %v", key, raw)
+	}
+	return int(v), nil
+}
+
+// executeAssertProcessRunning 断言进程正在运行：name（兼容 app_name）或 pid 指定进程，expect 为 false 时断言进程不存在；
+// 指定 wait_timeout（秒）时在超时前按 interval_ms 轮询，直到满足条件
+// 获取进程列表失败返回 SYSTEM_ERROR，不会被当作进程不存在
+func (e *Executor) executeAssertProcessRunning(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
+	var q processQuery
+	var err error
+	if q.pid, err = parsePID(payload, "pid"); err != nil {
+		return nil, err
+	}
+	q.name, _ = payload["name"].(string)
+	if q.name == "" {
+		q.name, _ = payload["app_name"].(string)
+	}
+	if q.pid == 0 && strings.TrimSpace(q.name) == "" {
+		return nil, auto.Errorf(auto.ErrParam, "缺少 name 或 pid 参数")
+	}
+	q.strict, _ = payload["strict"].(bool)
+
+	expect := true
+	if raw, ok := payload["expect"]; ok {
+		if expect, ok = raw.(bool); !ok {
+			return nil, auto.Errorf(auto.ErrParam, "expect 必须是布尔值: %v", raw)
+		}
+	}
+	var waitTimeout time.Duration
+	if raw, ok := payload["wait_timeout"]; ok {
+		v, ok := raw.(float64)
+		if !ok || v < 0 {
+			return nil, auto.Errorf(auto.ErrParam, "wait_timeout 必须是非负数（秒）: %v", raw)
+		}
+		waitTimeout = time.Duration(v * float64(time.Second))
+	}
+	interval := defaultProcessPollInterval
+	if raw, ok := payload["interval_ms"]; ok {
+		v, ok := raw.(float64)
+		if !ok || v <= 0 {
+			return nil, auto.Errorf(auto.ErrParam, "interval_ms 必须是正数: %v", raw)
+		}
+		interval = time.Duration(v) * time.Millisecond
+	}
+
+	start := time.Now()
+	for {
+		matched, err := q.match()
+		if err != nil {
+			return nil, err
+		}
+		running := len(matched) > 0
+		pids := make([]int, len(matched))
+		names := make([]string, len(matched))
+		for i, proc := range matched {
+			pids[i], names[i] = proc.PID, proc.Name
+		}
+		data := map[string]interface{}{
+			"running":   running,
+			"expect":    expect,
+			"pids":      pids,
+			"names":     names,
+			"processes": matched,
+			"waited_ms": time.Since(start).Milliseconds(),
+		}
+		if running == expect {
+			data["asserted"] = true
+			return data, nil
+		}
+
+		if time.Since(start) >= waitTimeout {
+			if expect {
+				return data, auto.Errorf(ErrAssertionFailed, "断言失败: 进程 %s 未运行", q)
+			}
+			return data, auto.Errorf(ErrAssertionFailed, "断言失败: 进程 %s 仍在运行（PID %v）", q, pids)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(min(interval, waitTimeout-time.Since(start))):
+		}
+	}
+}
+
+// executeKillProcessByPID 按 PID 终止进程：默认先发送终止信号，force_after_ms（默认 3000）后仍未退出再强制结束；
+// force 为 true 时直接强制结束。进程不存在时返回 NOT_FOUND，获取进程列表失败返回 SYSTEM_ERROR
+func (e *Executor) executeKillProcessByPID(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
+	pid, err := parsePID(payload, "pid")
+	if err != nil {
+		return nil, err
+	}
+	if pid == 0 {
+		return nil, auto.Errorf(auto.ErrParam, "缺少 pid 参数")
+	}
+	if pid == os.Getpid() {
+		return nil, auto.Errorf(auto.ErrParam, "不能终止 Worker 自身（PID %d）", pid)
+	}
+	force, _ := payload["force"].(bool)
+	forceAfter := time.Duration(defaultForceAfterMs) * time.Millisecond
+	if raw, ok := payload["force_after_ms"]; ok {
+		v, ok := raw.(float64)
+		if !ok || v < 0 {
+			return nil, auto.Errorf(auto.ErrParam, "force_after_ms 必须是非负数: %v", raw)
+		}
+		forceAfter = time.Duration(v) * time.Millisecond
+	}
+	if force {
+		forceAfter = 0
+	}
+
+	matched, err := processQuery{pid: pid}.match()
+	if err != nil {
+		return nil, err
+	}
+	if len(matched) == 0 {
+		return nil, auto.Errorf(auto.ErrNotFound, "未找到进程: PID %d", pid)
+	}
+	proc := matched[0]
+	if err := stopProcess(ctx, pid, forceAfter); err != nil {
+		return nil, fmt.Errorf("终止进程失败（PID %d %s）: %w", pid, proc.Name, err)
+	}
+
+	return map[string]interface{}{
+		"killed": true,
+		"pid":    pid,
+		"name":   proc.Name,
+		"path":   proc.Path,
+		"forced": forceAfter == 0,
+	}, nil
+}
@@ -0,0 +1,158 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+	"github.com/zoeyai/zoeyworker/pkg/process"
+)
+
+func TestAssertProcessRunning(t *testing.T) {
+	origList := listProcesses
+	t.Cleanup(func() { listProcesses = origList })
+	// setup.exe 在查询 exitAfter 次后退出
+	var calls, exitAfter atomic.Int32
+	exitAfter.Store(math.MaxInt32)
+	listProcesses = func() ([]process.ProcessInfo, error) {
+		procs := []process.ProcessInfo{{PID: os.Getpid(), Name: "zoeyworker"}, {PID: 100, Name: "MyApp.exe"}, {PID: 101, Name: "myapp-helper"}}
+		if calls.Add(1) <= exitAfter.Load() {
+			procs = append(procs, process.ProcessInfo{PID: 200, Name: "setup.exe"})
+		}
+		return procs, nil
+	}
+	e, recorder := newTestExecutor()
+	ctx := context.Background()
+
+	res, err := e.runAction(ctx, TaskTypeAssertProcessRunning, decodePayload(t, `{"name": "myapp"}`))
+	if data, _ := res.Data.(map[string]interface{}); err != nil || fmt.Sprint(data["pids"]) != "[100 101]" || fmt.Sprint(data["names"]) != "[MyApp.exe myapp-helper]" {
+		t.Fatalf("应列出匹配的进程: %+v %v", res.Data, err)
+	}
+	if _, err := e.runAction(ctx, TaskTypeAssertProcessRunning, decodePayload(t, `{"pid": 101}`)); err != nil {
+		t.Errorf("按 PID 断言失败: %v", err)
+	}
+	if _, err := e.runAction(ctx, TaskTypeAssertProcessRunning, decodePayload(t, `{"name": "zoeyworker"}`)); !errors.Is(err, ErrAssertionFailed) {
+		t.Errorf("不应匹配 Worker 自身: %v", err)
+	}
+
+	e.Execute("still-running", TaskTypeAssertProcessRunning, `{"name": "setup", "expect": false}`)
+	results := recorder.results("still-running")
+	if len(results) != 1 || results[0].FailureReason != pb.FailureReason_FAILURE_REASON_ASSERTION_FAILED || !strings.Contains(results[0].Message, "200") {
+		t.Fatalf("进程仍在运行时断言不存在应失败: %+v", results)
+	}
+	calls.Store(0)
+	exitAfter.Store(2)
+	res, err = e.runAction(ctx, TaskTypeAssertProcessRunning, decodePayload(t, `{"name": "setup", "expect": false, "wait_timeout": 2, "interval_ms": 10}`))
+	if err != nil || res.Data.(map[string]interface{})["running"] != false {
+		t.Fatalf("wait_timeout 内进程退出应成功: %+v %v", res.Data, err)
+	}
+
+	// 获取进程列表失败不能当作进程不存在
+	listProcesses = func() ([]process.ProcessInfo, error) {
+		return nil, errors.New("获取进程列表失败: access denied")
+	}
+	e.Execute("list-error", TaskTypeAssertProcessRunning, `{"name": "setup", "expect": false}`)
+	if results := recorder.results("list-error"); len(results) != 1 || results[0].FailureReason != pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR {
+		t.Errorf("获取进程列表失败应为 SYSTEM_ERROR: %+v", results)
+	}
+
+	for _, payload := range []string{`{}`, `{"pid": -1}`, `{"name": "a", "expect": "no"}`, `{"name": "a", "wait_timeout": -1}`} {
+		if _, err := e.runAction(ctx, TaskTypeAssertProcessRunning, decodePayload(t, payload)); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("%s 应返回 ErrParam: %v", payload, err)
+		}
+	}
+}
+
+func TestCloseApp_StopsAllMatchesTogether(t *testing.T) {
+	origList, origStop := listProcesses, stopProcesses
+	t.Cleanup(func() { listProcesses, stopProcesses = origList, origStop })
+	listProcesses = func() ([]process.ProcessInfo, error) {
+		return []process.ProcessInfo{{PID: 10, Name: "chrome"}, {PID: 11, Name: "Google Chrome Helper"}, {PID: 12, Name: "firefox"}, {PID: 13, Name: "chrome.exe"},
+			{PID: 14, Name: "explorer"}, {PID: 15, Name: "Finder"}, {PID: 16, Name: "loginwindow"}}, nil
+	}
+	var calls [][]int
+	stopProcesses = func(ctx context.Context, pids []int, forceAfter time.Duration) map[int]error {
+		calls = append(calls, pids)
+		return map[int]error{13: errors.New("access denied")}
+	}
+	e, _ := newTestExecutor()
+	ctx := context.Background()
+
+	// 有完全相同的进程名时不做部分匹配（不会终止 Google Chrome Helper）
+	res, err := e.runAction(ctx, TaskTypeCloseApp, decodePayload(t, `{"app_name": "chrome"}`))
+	if err != nil {
+		t.Fatalf("close_app 失败: %v", err)
+	}
+	if len(calls) != 1 || fmt.Sprint(calls[0]) != "[10 13]" {
+		t.Errorf("应一次终止所有名称相同的进程: %v", calls)
+	}
+	data, _ := res.Data.(map[string]interface{})
+	if fmt.Sprint(data["killed_pids"]) != "[10]" || data["matched"] != 2 {
+		t.Errorf("close_app 结果错误: %+v", data)
+	}
+
+	// 没有完全相同的进程名时退回部分匹配
+	calls = nil
+	if _, err := e.runAction(ctx, TaskTypeCloseApp, decodePayload(t, `{"app_name": "helper"}`)); err != nil || len(calls) != 1 || fmt.Sprint(calls[0]) != "[11]" {
+		t.Errorf("应部分匹配到 Google Chrome Helper: %v %v", calls, err)
+	}
+
+	// 过短的名称不做部分匹配；匹配数超过 max_matches 时不终止任何进程
+	calls = nil
+	if _, err := e.runAction(ctx, TaskTypeCloseApp, decodePayload(t, `{"app_name": "er"}`)); !errors.Is(err, auto.ErrNotFound) {
+		t.Errorf("过短的名称不应部分匹配: %v", err)
+	}
+	if _, err := e.runAction(ctx, TaskTypeCloseApp, decodePayload(t, `{"app_name": "chrome", "max_matches": 1}`)); !errors.Is(err, auto.ErrParam) {
+		t.Errorf("超过 max_matches 应返回 ErrParam: %v", err)
+	}
+	if _, err := e.runAction(ctx, TaskTypeCloseApp, decodePayload(t, `{"app_name": "chrome", "max_matches": 0}`)); !errors.Is(err, auto.ErrParam) {
+		t.Errorf("max_matches 非正整数应返回 ErrParam: %v", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("拒绝执行时不应终止进程: %v", calls)
+	}
+}
+
+func TestKillProcessByPID(t *testing.T) {
+	origList, origStop := listProcesses, stopProcess
+	t.Cleanup(func() { listProcesses, stopProcess = origList, origStop })
+	listProcesses = func() ([]process.ProcessInfo, error) {
+		return []process.ProcessInfo{{PID: 100, Name: "MyApp.exe", Path: `C:\MyApp\MyApp.exe`}}, nil
+	}
+	var stopped []time.Duration
+	stopProcess = func(ctx context.Context, pid int, forceAfter time.Duration) error {
+		stopped = append(stopped, forceAfter)
+		return nil
+	}
+	e, recorder := newTestExecutor()
+	ctx := context.Background()
+
+	res, err := e.runAction(ctx, TaskTypeKillProcessByPID, decodePayload(t, `{"pid": 100}`))
+	if data, _ := res.Data.(map[string]interface{}); err != nil || data["name"] != "MyApp.exe" || data["forced"] != false || stopped[0] != 3*time.Second {
+		t.Fatalf("默认应优雅终止: %+v %v %v", res.Data, err, stopped)
+	}
+	if _, err := e.runAction(ctx, TaskTypeKillProcessByPID, decodePayload(t, `{"pid": 100, "force": true}`)); err != nil || stopped[1] != 0 {
+		t.Errorf("force 时应直接强制结束: %v %v", err, stopped)
+	}
+
+	e.Execute("missing", TaskTypeKillProcessByPID, `{"pid": 999}`)
+	if results := recorder.results("missing"); len(results) != 1 || results[0].FailureReason != pb.FailureReason_FAILURE_REASON_NOT_FOUND {
+		t.Errorf("进程不存在应为 NOT_FOUND: %+v", results)
+	}
+	for _, payload := range []string{`{}`, `{"pid": 1.5}`, fmt.Sprintf(`{"pid": %d}`, os.Getpid()), `{"pid": 100, "force_after_ms": -1}`} {
+		if _, err := e.runAction(ctx, TaskTypeKillProcessByPID, decodePayload(t, payload)); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("%s 应返回 ErrParam: %v", payload, err)
+		}
+	}
+	if len(stopped) != 2 {
+		t.Errorf("参数错误时不应终止进程: %v", stopped)
+	}
+}
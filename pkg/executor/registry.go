@@ -0,0 +1,155 @@
+package executor
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+// ActionFunc 动作执行函数
+// 返回的 ActionResult 可为 nil；Success/Error 由调度方根据 error 填充
+type ActionFunc func(ctx context.Context, payload map[string]interface{}) (*ActionResult, error)
+
+var (
+	actionsMu sync.RWMutex
+	actions   = make(map[string]ActionFunc)
+)
+
+// RegisterAction 注册动作（任务类型 -> 执行函数）
+// 已存在的同名动作会被覆盖，嵌入方（GUI、下游分支）可借此替换或扩展内置动作
+func RegisterAction(name string, fn ActionFunc) {
+	if name == "" || fn == nil {
+		panic("executor: RegisterAction 的 name 和 fn 不能为空")
+	}
+	actionsMu.Lock()
+	defer actionsMu.Unlock()
+	actions[name] = fn
+}
+
+// RegisteredActions 返回已注册的动作名称（已排序）
+func RegisteredActions() []string {
+	actionsMu.RLock()
+	defer actionsMu.RUnlock()
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupAction 查找已注册的动作
+func lookupAction(name string) (ActionFunc, bool) {
+	actionsMu.RLock()
+	defer actionsMu.RUnlock()
+	fn, ok := actions[name]
+	return fn, ok
+}
+
+// executorKey context 中保存 *Executor 的 key
+type executorKey struct{}
+
+// withExecutor 将执行器放入 context，供内置动作使用
+func withExecutor(ctx context.Context, e *Executor) context.Context {
+	return context.WithValue(ctx, executorKey{}, e)
+}
+
+// executorFromContext 从 context 中取出执行器
+// 内置动作只能经 runAction 调用；context 中没有执行器属于接线错误，返回 ErrSystem，
+// 不能退回到一个没有消息发送、截图设置和按键状态的新执行器上静默执行
+func executorFromContext(ctx context.Context) (*Executor, error) {
+	e, ok := ctx.Value(executorKey{}).(*Executor)
+	if !ok || e == nil {
+		return nil, auto.Errorf(auto.ErrSystem, "context 中没有执行器")
+	}
+	return e, nil
+}
+
+// runAction 按任务类型分发到已注册的动作（返回的 ActionResult 不为 nil）
+func (e *Executor) runAction(ctx context.Context, taskType string, payload map[string]interface{}) (*ActionResult, error) {
+	fn, ok := lookupAction(taskType)
+	if !ok {
+		return &ActionResult{}, auto.Errorf(auto.ErrParam, "未知的任务类型: %s（可用: %s）", taskType, strings.Join(RegisteredActions(), ", "))
+	}
+	if err := e.checkScreen(taskType, payload); err != nil {
+		return &ActionResult{}, err
+	}
+	start := time.Now()
+	result, err := fn(withExecutor(ctx, e), payload)
+	recordStep(taskType, start)
+	if result == nil {
+		result = &ActionResult{}
+	}
+	return result, err
+}
+
+// simpleAction 将只返回数据的内置动作方法包装为 ActionFunc
+func simpleAction(fn func(e *Executor, ctx context.Context, payload map[string]interface{}) (interface{}, error)) ActionFunc {
+	return func(ctx context.Context, payload map[string]interface{}) (*ActionResult, error) {
+		e, err := executorFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := fn(e, ctx, payload)
+		return &ActionResult{Data: data}, err
+	}
+}
+
+// detailedAction 将填充点击位置、目标边界等详细信息的内置动作方法包装为 ActionFunc
+func detailedAction(fn func(e *Executor, ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error)) ActionFunc {
+	return func(ctx context.Context, payload map[string]interface{}) (*ActionResult, error) {
+		e, err := executorFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result := &ActionResult{}
+		data, err := fn(e, ctx, payload, result)
+		result.Data = data
+		return result, err
+	}
+}
+
+// 注册内置动作
+func init() {
+	RegisterAction(TaskTypeClickImage, detailedAction((*Executor).executeClickImage))
+	RegisterAction(TaskTypeClickText, detailedAction((*Executor).executeClickText))
+	RegisterAction(TaskTypeClickNative, simpleAction((*Executor).executeClickNative))
+	RegisterAction(TaskTypeTypeText, detailedAction((*Executor).executeTypeText))
+	RegisterAction(TaskTypeKeyPress, simpleAction((*Executor).executeKeyPress))
+	RegisterAction(TaskTypeKeyDown, simpleAction((*Executor).executeKeyDown))
+	RegisterAction(TaskTypeKeyUp, simpleAction((*Executor).executeKeyUp))
+	RegisterAction(TaskTypeScreenshot, simpleAction((*Executor).executeScreenshot))
+	RegisterAction(TaskTypeWaitImage, detailedAction((*Executor).executeWaitImage))
+	RegisterAction(TaskTypeWaitText, detailedAction((*Executor).executeWaitText))
+	RegisterAction(TaskTypeWaitTime, simpleAction((*Executor).executeWaitTime))
+	RegisterAction(TaskTypeMouseMove, simpleAction((*Executor).executeMouseMove))
+	RegisterAction(TaskTypeMouseClick, detailedAction((*Executor).executeMouseClick))
+	RegisterAction(TaskTypeActivateApp, simpleAction((*Executor).executeActivateApp))
+	RegisterAction(TaskTypeCloseApp, simpleAction((*Executor).executeCloseApp))
+	RegisterAction(TaskTypeOpenURL, detailedAction((*Executor).executeOpenURL))
+	RegisterAction(TaskTypeGridClick, detailedAction((*Executor).executeGridClick))
+	RegisterAction(TaskTypeImageExists, detailedAction((*Executor).executeImageExists))
+	RegisterAction(TaskTypeTextExists, detailedAction((*Executor).executeTextExists))
+	RegisterAction(TaskTypeTextFindAll, detailedAction((*Executor).executeTextFindAll))
+	RegisterAction(TaskTypeAssertImage, detailedAction((*Executor).executeAssertImage))
+	RegisterAction(TaskTypeAssertImageCount, detailedAction((*Executor).executeAssertImageCount))
+	RegisterAction(TaskTypeAssertText, detailedAction((*Executor).executeAssertText))
+	RegisterAction(TaskTypeGetPixelColor, detailedAction((*Executor).executeGetPixelColor))
+	RegisterAction(TaskTypeAssertPixelColor, detailedAction((*Executor).executeAssertPixelColor))
+	RegisterAction(TaskTypeGetClipboard, simpleAction((*Executor).executeGetClipboard))
+	RegisterAction(TaskTypeSetClipboard, simpleAction((*Executor).executeSetClipboard))
+	RegisterAction(TaskTypeAssertClipboard, simpleAction((*Executor).executeAssertClipboard))
+	RegisterAction(TaskTypeWaitForWindow, detailedAction((*Executor).executeWaitForWindow))
+	RegisterAction(TaskTypeSetWindowBounds, detailedAction((*Executor).executeSetWindowBounds))
+	RegisterAction(TaskTypeMinimizeWindow, simpleAction((*Executor).executeMinimizeWindow))
+	RegisterAction(TaskTypeMaximizeWindow, detailedAction((*Executor).executeMaximizeWindow))
+	RegisterAction(TaskTypeAssertWindowTitle, detailedAction((*Executor).executeAssertWindowTitle))
+	RegisterAction(TaskTypeAssertProcessRunning, simpleAction((*Executor).executeAssertProcessRunning))
+	RegisterAction(TaskTypeKillProcessByPID, simpleAction((*Executor).executeKillProcessByPID))
+	RegisterAction(TaskTypeWaitForHotkey, simpleAction((*Executor).executeWaitForHotkey))
+	RegisterAction(TaskTypeRunPython, simpleAction((*Executor).executeRunPython))
+}
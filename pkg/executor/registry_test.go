@@ -0,0 +1,123 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+)
+
+func TestRegisterAction_CustomAction(t *testing.T) {
+	RegisterAction("custom_echo", func(ctx context.Context, payload map[string]interface{}) (*ActionResult, error) {
+		return &ActionResult{Data: map[string]interface{}{"echo": payload["value"]}}, nil
+	})
+	t.Cleanup(func() {
+		actionsMu.Lock()
+		delete(actions, "custom_echo")
+		actionsMu.Unlock()
+	})
+
+	e, recorder := newTestExecutor()
+	e.Execute("task-custom", "custom_echo", `{"value":"hi"}`)
+
+	results := recorder.results("task-custom")
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("自定义动作应执行成功: %+v", results)
+	}
+	if results[0].ResultJson != `{"echo":"hi"}` {
+		t.Errorf("ResultJson = %s", results[0].ResultJson)
+	}
+}
+
+func TestRunAction_UnknownType(t *testing.T) {
+	e, _ := newTestExecutor()
+	_, err := e.runAction(context.Background(), "no_such_action", nil)
+	if !errors.Is(err, auto.ErrParam) {
+		t.Fatalf("未知任务类型应返回 ErrParam, 实际为 %v", err)
+	}
+	if taskErr := classifyError(err); taskErr.Reason != pb.FailureReason_FAILURE_REASON_PARAM_ERROR {
+		t.Errorf("未知任务类型应归类为 PARAM_ERROR, 实际为 %v", taskErr.Reason)
+	}
+	if !strings.Contains(err.Error(), TaskTypeClickImage) {
+		t.Errorf("错误信息应列出可用动作: %v", err)
+	}
+}
+
+func TestBuiltinAction_RequiresExecutorInContext(t *testing.T) {
+	fn, _ := lookupAction(TaskTypeWaitTime)
+	_, err := fn(context.Background(), map[string]interface{}{"duration": float64(1)})
+	if !errors.Is(err, auto.ErrSystem) {
+		t.Fatalf("context 中没有执行器时内置动作应返回 ErrSystem, 实际 %v", err)
+	}
+	if taskErr := classifyError(err); taskErr.Reason != pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR {
+		t.Errorf("应归类为 SYSTEM_ERROR, 实际 %s", taskErr.Reason)
+	}
+}
+
+// TestAllTaskTypesHandled 确保每个 TaskType 常量都有对应的执行逻辑，新增任务类型时不会漏注册
+func TestAllTaskTypesHandled(t *testing.T) {
+	// 由 Execute 直接处理（自行发送进度和结果）的任务类型
+	handledByExecute := map[string]bool{
+		TaskTypeDebugCase:   true,
+		TaskTypeExecutePlan: true,
+		TaskTypeExecuteCase: true,
+		TaskTypeAIAction:    true,
+	}
+
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	var taskTypes []string
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatalf("解析 %s 失败: %v", file, err)
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if !strings.HasPrefix(name.Name, "TaskType") || i >= len(vs.Values) {
+						continue
+					}
+					lit, ok := vs.Values[i].(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING {
+						continue
+					}
+					value, _ := strconv.Unquote(lit.Value)
+					taskTypes = append(taskTypes, value)
+				}
+			}
+		}
+	}
+
+	if len(taskTypes) < 20 {
+		t.Fatalf("仅找到 %d 个 TaskType 常量，解析可能有误", len(taskTypes))
+	}
+	for _, taskType := range taskTypes {
+		if handledByExecute[taskType] {
+			continue
+		}
+		if _, ok := lookupAction(taskType); !ok {
+			t.Errorf("任务类型 %s 未注册动作", taskType)
+		}
+	}
+}
@@ -0,0 +1,498 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+)
+
+// 报告中步骤截图缩略图的尺寸和质量（HTML 报告内嵌，控制文件体积）
+const (
+	reportThumbnailWidth   = 320
+	reportThumbnailQuality = 60
+)
+
+// SetReportDir 设置 execute_plan 测试报告的根目录（默认 ~/.zoey-worker/reports），报告写入其下的 <plan_execution_id> 目录
+func (e *Executor) SetReportDir(dir string) {
+	e.tasksMutex.Lock()
+	defer e.tasksMutex.Unlock()
+	e.reportDir = dir
+}
+
+// reportBaseDir 返回测试报告的根目录
+func (e *Executor) reportBaseDir() (string, error) {
+	e.tasksMutex.Lock()
+	dir := e.reportDir
+	e.tasksMutex.Unlock()
+	if dir != "" {
+		return dir, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户目录失败: %w", err)
+	}
+	return filepath.Join(homeDir, ".zoey-worker", "reports"), nil
+}
+
+// planReport 收集 execute_plan 的用例和步骤结果，计划结束后生成 JUnit XML 和 HTML 报告（generate_report 为 true 时）
+type planReport struct {
+	planID          string
+	planExecutionID string
+	start           time.Time
+	cases           []*reportCase
+}
+
+// reportCase 报告中的一个用例
+type reportCase struct {
+	caseID          string
+	caseExecutionID string
+	name            string
+	start           time.Time
+	duration        time.Duration
+	stepsRaw        []interface{}
+
+	steps    []StepExecutionResult // 已执行的步骤（截图替换为缩略图）
+	executed map[int]bool          // 已执行步骤在 stepsRaw 中的下标
+	skipped  []reportSkippedStep   // 未执行的步骤（stop_on_fail 或任务取消后）
+	result   *CaseExecutionResult
+}
+
+// reportSkippedStep 未执行的步骤
+type reportSkippedStep struct {
+	StepID   string
+	TaskType string
+}
+
+func newPlanReport(planID, planExecutionID string) *planReport {
+	return &planReport{planID: planID, planExecutionID: planExecutionID, start: time.Now()}
+}
+
+// beginCase 开始记录一个用例，此后 addStep 的步骤归入该用例；r 为 nil 时不做任何事
+func (r *planReport) beginCase(caseID, caseExecutionID, name string, stepsRaw []interface{}) {
+	if r == nil {
+		return
+	}
+	if name == "" {
+		name = caseID
+	}
+	r.cases = append(r.cases, &reportCase{
+		caseID:          caseID,
+		caseExecutionID: caseExecutionID,
+		name:            name,
+		start:           time.Now(),
+		stepsRaw:        stepsRaw,
+		executed:        map[int]bool{},
+	})
+}
+
+// addStep 记录当前用例第 index 个步骤的结果；必须在结果发送（上传截图）之前调用，保存的是副本
+func (r *planReport) addStep(index int, result *StepExecutionResult) {
+	if r == nil || len(r.cases) == 0 {
+		return
+	}
+	c := r.cases[len(r.cases)-1]
+	step := *result
+	step.ScreenshotBefore = reportThumbnail(step.ScreenshotBefore)
+	step.ScreenshotAfter = reportThumbnail(step.ScreenshotAfter)
+	if step.ScreenshotAfterSameAsBefore && step.ScreenshotAfter == "" {
+		step.ScreenshotAfter = step.ScreenshotBefore
+	}
+	step.Video = ""
+	c.steps = append(c.steps, step)
+	c.executed[index] = true
+}
+
+// endCase 结束当前用例，未执行的步骤记为跳过
+func (r *planReport) endCase(result *CaseExecutionResult, duration time.Duration) {
+	if r == nil || len(r.cases) == 0 {
+		return
+	}
+	c := r.cases[len(r.cases)-1]
+	c.result = result
+	c.duration = duration
+	for i, raw := range c.stepsRaw {
+		if c.executed[i] {
+			continue
+		}
+		stepMap, _ := raw.(map[string]interface{})
+		stepID, _ := stepMap["step_id"].(string)
+		taskType, _ := stepMap["task_type"].(string)
+		c.skipped = append(c.skipped, reportSkippedStep{StepID: stepID, TaskType: taskType})
+	}
+	c.stepsRaw = nil
+}
+
+// reportThumbnail 将截图 data URL 缩小为 JPEG 缩略图；上传后的 url/key 原样保留，无法解码时返回空
+func reportThumbnail(dataURL string) string {
+	if dataURL == "" || !strings.HasPrefix(dataURL, "data:") {
+		return dataURL
+	}
+	data, err := decodeDataURL(dataURL)
+	if err != nil {
+		return ""
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+	img, _, err = screen.DownscaleImage(img, reportThumbnailWidth)
+	if err != nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: reportThumbnailQuality}); err != nil {
+		return ""
+	}
+	return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// ==================== JUnit XML ====================
+
+// junitTestSuites JUnit XML 根元素（Jenkins / GitLab 等 CI 可直接解析）：每个用例一个 testsuite，每个步骤一个 testcase
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	ID         string          `xml:"id,attr,omitempty"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+	SystemErr string        `xml:"system-err,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// junitSeconds JUnit 的 time 属性（秒，三位小数）
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// reportStepName 步骤在报告中的名称：step_id，缺省时为序号
+func reportStepName(stepID string, index int) string {
+	if stepID == "" {
+		return fmt.Sprintf("step_%d", index+1)
+	}
+	return stepID
+}
+
+// junit 生成 JUnit 报告：失败原因为 PARAM_ERROR / SYSTEM_ERROR 的步骤记为 error，其余失败记为 failure
+func (r *planReport) junit(total time.Duration) *junitTestSuites {
+	name := r.planID
+	if name == "" {
+		name = r.planExecutionID
+	}
+	suites := &junitTestSuites{Name: name, Time: junitSeconds(total)}
+	for _, c := range r.cases {
+		suite := junitTestSuite{
+			Name:      c.name,
+			ID:        c.caseExecutionID,
+			Time:      junitSeconds(c.duration),
+			Timestamp: c.start.Format("2006-01-02T15:04:05"),
+		}
+		if c.caseID != "" {
+			suite.Properties = append(suite.Properties, junitProperty{Name: "case_id", Value: c.caseID})
+		}
+		if c.caseExecutionID != "" {
+			suite.Properties = append(suite.Properties, junitProperty{Name: "case_execution_id", Value: c.caseExecutionID})
+		}
+		for i, step := range c.steps {
+			tc := junitTestCase{
+				Name:      reportStepName(step.StepID, i),
+				Classname: c.name,
+				Time:      junitSeconds(time.Duration(step.DurationMs) * time.Millisecond),
+				SystemOut: step.Stdout,
+				SystemErr: step.Stderr,
+			}
+			switch step.Status {
+			case "SUCCESS":
+			case "SKIPPED", "CANCELLED":
+				tc.Skipped = &junitSkipped{Message: step.ErrorMessage}
+				suite.Skipped++
+			default:
+				failure := &junitFailure{Message: step.ErrorMessage, Type: step.FailureReason, Text: fmt.Sprintf("%s (%s): %s", tc.Name, step.ActionType, step.ErrorMessage)}
+				if step.FailureReason == "PARAM_ERROR" || step.FailureReason == "SYSTEM_ERROR" {
+					tc.Error = failure
+					suite.Errors++
+				} else {
+					tc.Failure = failure
+					suite.Failures++
+				}
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		for i, step := range c.skipped {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      reportStepName(step.StepID, len(c.steps)+i),
+				Classname: c.name,
+				Time:      junitSeconds(0),
+				Skipped:   &junitSkipped{Message: "未执行"},
+			})
+			suite.Skipped++
+		}
+		suite.Tests = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+	return suites
+}
+
+// ==================== HTML ====================
+
+// reportTemplate 自包含的 HTML 报告（样式内联，截图缩略图以 data URL 内嵌）
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"inc":       func(i int) int { return i + 1 },
+	"lower":     strings.ToLower,
+	"isDataURL": func(s string) bool { return strings.HasPrefix(s, "data:") },
+	"isLink":    func(s string) bool { return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") },
+	"dataURL":   func(s string) template.URL { return template.URL(s) },
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>测试报告 {{.Name}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "Microsoft YaHei", sans-serif; margin: 24px; color: #222; }
+h1 { font-size: 20px; } h2 { font-size: 16px; margin-top: 32px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ddd; padding: 6px 8px; text-align: left; vertical-align: top; font-size: 13px; }
+th { background: #f5f5f5; }
+.summary td { border: none; padding: 2px 12px 2px 0; }
+.success { color: #2e7d32; } .failed, .error { color: #c62828; } .skipped, .cancelled { color: #888; }
+img { max-width: 320px; border: 1px solid #ccc; }
+pre { white-space: pre-wrap; margin: 0; }
+</style>
+</head>
+<body>
+<h1>测试报告 {{.Name}}</h1>
+<table class="summary">
+<tr><td>执行 ID</td><td>{{.ExecutionID}}</td></tr>
+<tr><td>开始时间</td><td>{{.Start}}</td></tr>
+<tr><td>耗时</td><td>{{.Duration}}</td></tr>
+<tr><td>用例</td><td>{{len .Cases}}（<span class="success">通过 {{.PassedCases}}</span>，<span class="failed">失败 {{.FailedCases}}</span>）</td></tr>
+<tr><td>步骤</td><td>{{.Suites.Tests}}（失败 {{.Suites.Failures}}，错误 {{.Suites.Errors}}，跳过 {{.Suites.Skipped}}）</td></tr>
+</table>
+{{range .Cases}}
+<h2 class="{{lower .Status}}">{{.Name}} — {{.Status}}（{{.Duration}}）</h2>
+{{if .Error}}<p class="failed">{{.Error}}</p>{{end}}
+<table>
+<tr><th>#</th><th>步骤</th><th>操作</th><th>状态</th><th>耗时</th><th>错误</th><th>执行前</th><th>执行后</th></tr>
+{{range $i, $s := .Steps}}
+<tr>
+<td>{{inc $i}}</td><td>{{$s.Name}}</td><td>{{$s.Action}}</td><td class="{{lower $s.Status}}">{{$s.Status}}</td><td>{{$s.Duration}}</td>
+<td>{{if $s.Error}}<pre>{{$s.Error}}</pre>{{end}}{{if $s.FailureReason}}<br><small>{{$s.FailureReason}}</small>{{end}}</td>
+<td>{{template "shot" $s.Before}}</td><td>{{template "shot" $s.After}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}
+</body>
+</html>
+{{define "shot"}}{{if isDataURL .}}<img src="{{dataURL .}}" alt="">{{else if isLink .}}<a href="{{.}}">查看截图</a>{{else if .}}{{.}}{{end}}{{end}}`))
+
+// htmlReport HTML 报告的模板数据
+type htmlReport struct {
+	Name        string
+	ExecutionID string
+	Start       string
+	Duration    string
+	PassedCases int
+	FailedCases int
+	Suites      *junitTestSuites
+	Cases       []htmlReportCase
+}
+
+type htmlReportCase struct {
+	Name     string
+	Status   string
+	Duration string
+	Error    string
+	Steps    []htmlReportStep
+}
+
+type htmlReportStep struct {
+	Name          string
+	Action        string
+	Status        string
+	Duration      string
+	Error         string
+	FailureReason string
+	Before, After string
+}
+
+// html 生成 HTML 报告
+func (r *planReport) html(suites *junitTestSuites, total time.Duration) ([]byte, error) {
+	data := htmlReport{
+		Name:        suites.Name,
+		ExecutionID: r.planExecutionID,
+		Start:       r.start.Format("2006-01-02 15:04:05"),
+		Duration:    total.Round(time.Millisecond).String(),
+		Suites:      suites,
+	}
+	for _, c := range r.cases {
+		hc := htmlReportCase{Name: c.name, Status: "SUCCESS", Duration: c.duration.Round(time.Millisecond).String()}
+		if c.result != nil && !c.result.Success {
+			hc.Status, hc.Error = "FAILED", c.result.ErrorMessage
+			if c.result.Cancelled {
+				hc.Status = "CANCELLED"
+			}
+		}
+		if hc.Status == "SUCCESS" {
+			data.PassedCases++
+		} else {
+			data.FailedCases++
+		}
+		for i, step := range c.steps {
+			hc.Steps = append(hc.Steps, htmlReportStep{
+				Name:          reportStepName(step.StepID, i),
+				Action:        step.ActionType,
+				Status:        step.Status,
+				Duration:      (time.Duration(step.DurationMs) * time.Millisecond).String(),
+				Error:         step.ErrorMessage,
+				FailureReason: step.FailureReason,
+				Before:        step.ScreenshotBefore,
+				After:         step.ScreenshotAfter,
+			})
+		}
+		for i, step := range c.skipped {
+			hc.Steps = append(hc.Steps, htmlReportStep{
+				Name:   reportStepName(step.StepID, len(c.steps)+i),
+				Action: mapTaskTypeToActionType(step.TaskType),
+				Status: "SKIPPED",
+				Error:  "未执行",
+			})
+		}
+		data.Cases = append(data.Cases, hc)
+	}
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("生成 HTML 报告失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// reportFiles 报告文件路径（计划结果 JSON 中的 report 字段）
+type reportFiles struct {
+	Dir   string `json:"dir"`
+	JUnit string `json:"junit"`
+	HTML  string `json:"html"`
+}
+
+// write 将报告写入 dir/<plan_execution_id>/（junit.xml、report.html），planExecutionID 为空时使用 taskID
+func (r *planReport) write(baseDir, taskID string) (*reportFiles, error) {
+	total := time.Since(r.start)
+	suites := r.junit(total)
+	xmlData, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("生成 JUnit 报告失败: %w", err)
+	}
+	htmlData, err := r.html(suites, total)
+	if err != nil {
+		return nil, err
+	}
+
+	id := r.planExecutionID
+	if id == "" {
+		id = taskID
+	}
+	files := &reportFiles{Dir: filepath.Join(baseDir, safeFileName(id))}
+	files.JUnit = filepath.Join(files.Dir, "junit.xml")
+	files.HTML = filepath.Join(files.Dir, "report.html")
+	if err := os.MkdirAll(files.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建报告目录失败: %w", err)
+	}
+	if err := os.WriteFile(files.JUnit, append([]byte(xml.Header), xmlData...), 0644); err != nil {
+		return nil, fmt.Errorf("写入 JUnit 报告失败: %w", err)
+	}
+	if err := os.WriteFile(files.HTML, htmlData, 0644); err != nil {
+		return nil, fmt.Errorf("写入 HTML 报告失败: %w", err)
+	}
+	return files, nil
+}
+
+// writePlanReport 将报告写入报告根目录
+func (e *Executor) writePlanReport(r *planReport, taskID string) (*reportFiles, error) {
+	baseDir, err := e.reportBaseDir()
+	if err != nil {
+		return nil, err
+	}
+	return r.write(baseDir, taskID)
+}
+
+// safeFileName 将 ID 转为可用作文件名或目录名的字符串
+func safeFileName(id string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, id)
+	if strings.Trim(name, ".") == "" {
+		name = "_" + name
+	}
+	return name
+}
+
+type planReportKey struct{}
+
+// withPlanReport 将报告收集器放入 context，供 executeCaseSteps 记录步骤结果
+func withPlanReport(ctx context.Context, r *planReport) context.Context {
+	return context.WithValue(ctx, planReportKey{}, r)
+}
+
+// planReportFromContext 从 context 中取出报告收集器，未启用时返回 nil
+func planReportFromContext(ctx context.Context) *planReport {
+	r, _ := ctx.Value(planReportKey{}).(*planReport)
+	return r
+}
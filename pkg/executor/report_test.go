@@ -0,0 +1,161 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+func TestPlanReport(t *testing.T) {
+	RegisterAction("test_pass", func(ctx context.Context, payload map[string]interface{}) (*ActionResult, error) {
+		return &ActionResult{}, nil
+	})
+	RegisterAction("test_fail", func(ctx context.Context, payload map[string]interface{}) (*ActionResult, error) {
+		return nil, auto.Errorf(ErrAssertionFailed, "断言失败: 期望 <ok> & 实际 \"ng\"")
+	})
+	t.Cleanup(func() {
+		actionsMu.Lock()
+		delete(actions, "test_pass")
+		delete(actions, "test_fail")
+		actionsMu.Unlock()
+	})
+
+	e, recorder := newTestExecutor()
+	e.SetReportDir(t.TempDir())
+	e.Execute("plan", TaskTypeExecutePlan, `{"plan_execution_id": "pe/1", "plan_id": "p1", "stop_on_fail": true, "screenshot_mode": "never", "generate_report": true, "cases": [
+		{"case_execution_id": "ce1", "case_id": "c1", "case_name": "登录", "steps": [
+			{"step_id": "s1", "task_type": "test_pass", "params": {}},
+			{"step_id": "s2", "task_type": "test_pass", "params": {}}]},
+		{"case_execution_id": "ce2", "case_id": "c2", "case_name": "下单", "steps": [
+			{"step_id": "s3", "task_type": "test_pass", "params": {}},
+			{"step_id": "s4", "task_type": "test_fail", "params": {}},
+			{"step_id": "s5", "task_type": "test_pass", "params": {}}]},
+		{"case_execution_id": "ce3", "case_id": "c3", "case_name": "未执行", "steps": [
+			{"step_id": "s6", "task_type": "test_pass", "params": {}}]}]}`)
+	res := recorder.results("plan")
+	if len(res) != 1 || res[0].Success {
+		t.Fatalf("计划应失败: %+v", res)
+	}
+	var data struct {
+		Report      *reportFiles `json:"report"`
+		ReportError string       `json:"report_error"`
+	}
+	if err := json.Unmarshal([]byte(res[0].ResultJson), &data); err != nil || data.Report == nil {
+		t.Fatalf("结果应包含报告路径: %s %v", res[0].ResultJson, err)
+	}
+	if filepath.Base(data.Report.Dir) != "pe_1" || filepath.Dir(data.Report.JUnit) != data.Report.Dir {
+		t.Errorf("报告目录错误: %+v", data.Report)
+	}
+
+	raw, err := os.ReadFile(data.Report.JUnit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(raw), "<?xml") {
+		t.Errorf("JUnit 报告应以 XML 声明开头: %.60s", raw)
+	}
+	// 按 JUnit 元素和属性名解析，不依赖内部结构体
+	var doc struct {
+		XMLName  xml.Name `xml:"testsuites"`
+		Name     string   `xml:"name,attr"`
+		Tests    int      `xml:"tests,attr"`
+		Failures int      `xml:"failures,attr"`
+		Skipped  int      `xml:"skipped,attr"`
+		Suites   []struct {
+			Name       string `xml:"name,attr"`
+			Tests      int    `xml:"tests,attr"`
+			Failures   int    `xml:"failures,attr"`
+			Errors     int    `xml:"errors,attr"`
+			Skipped    int    `xml:"skipped,attr"`
+			Time       string `xml:"time,attr"`
+			Timestamp  string `xml:"timestamp,attr"`
+			Properties []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:"value,attr"`
+			} `xml:"properties>property"`
+			Cases []struct {
+				Name      string `xml:"name,attr"`
+				Classname string `xml:"classname,attr"`
+				Time      string `xml:"time,attr"`
+				Failure   *struct {
+					Message string `xml:"message,attr"`
+					Type    string `xml:"type,attr"`
+				} `xml:"failure"`
+				Skipped *struct{} `xml:"skipped"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("JUnit 报告不是合法 XML: %v\n%s", err, raw)
+	}
+	if doc.Name != "p1" || doc.Tests != 5 || doc.Failures != 1 || doc.Skipped != 1 || len(doc.Suites) != 2 {
+		t.Fatalf("testsuites 汇总错误: %+v", doc)
+	}
+	login, order := doc.Suites[0], doc.Suites[1]
+	if login.Name != "登录" || login.Tests != 2 || login.Failures != 0 || login.Skipped != 0 ||
+		len(login.Properties) != 2 || login.Properties[0].Name != "case_id" || login.Properties[0].Value != "c1" {
+		t.Errorf("用例 登录 的 testsuite 错误: %+v", login)
+	}
+	if _, err := time.Parse("2006-01-02T15:04:05", login.Timestamp); err != nil {
+		t.Errorf("timestamp 格式错误: %q", login.Timestamp)
+	}
+	if _, err := strconv.ParseFloat(login.Time, 64); err != nil {
+		t.Errorf("time 应为秒数: %q", login.Time)
+	}
+	if order.Name != "下单" || order.Tests != 3 || order.Failures != 1 || order.Errors != 0 || order.Skipped != 1 {
+		t.Fatalf("用例 下单 的 testsuite 错误: %+v", order)
+	}
+	failed, skipped := order.Cases[1], order.Cases[2]
+	if failed.Name != "s4" || failed.Classname != "下单" || failed.Failure == nil || failed.Failure.Type != "ASSERTION_FAILED" ||
+		!strings.Contains(failed.Failure.Message, `期望 <ok> & 实际 "ng"`) || failed.Skipped != nil {
+		t.Errorf("失败步骤的 testcase 错误: %+v", failed)
+	}
+	if order.Cases[0].Failure != nil || skipped.Name != "s5" || skipped.Skipped == nil || skipped.Time != "0.000" {
+		t.Errorf("成功或未执行步骤的 testcase 错误: %+v", order.Cases)
+	}
+
+	page, err := os.ReadFile(data.Report.HTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<!DOCTYPE html>", "登录", "下单", "s4", "ASSERTION_FAILED", "期望 &lt;ok&gt;"} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("HTML 报告应包含 %q", want)
+		}
+	}
+
+	// 截图替换为内嵌的缩略图，上传后的地址原样保留
+	img := image.NewRGBA(image.Rect(0, 0, 1280, 720))
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	thumb := reportThumbnail("data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()))
+	decoded, err := decodeDataURL(thumb)
+	if err != nil || !strings.HasPrefix(thumb, "data:image/jpeg;base64,") {
+		t.Fatalf("缩略图应为 JPEG data URL: %.40s %v", thumb, err)
+	}
+	if cfg, err := jpeg.DecodeConfig(bytes.NewReader(decoded)); err != nil || cfg.Width != reportThumbnailWidth {
+		t.Errorf("缩略图宽度应为 %d: %+v %v", reportThumbnailWidth, cfg, err)
+	}
+	if got := reportThumbnail("https://cdn.example.com/a.jpg"); got != "https://cdn.example.com/a.jpg" {
+		t.Errorf("上传后的截图地址应原样保留: %q", got)
+	}
+
+	// 未指定 generate_report 时不生成报告
+	e.Execute("plain", TaskTypeExecutePlan, `{"plan_execution_id": "pe2", "screenshot_mode": "never", "cases": [{"case_id": "c1", "steps": [{"step_id": "s1", "task_type": "test_pass", "params": {}}]}]}`)
+	if res := recorder.results("plain"); len(res) != 1 || !res[0].Success || strings.Contains(res[0].ResultJson, `"report"`) {
+		t.Errorf("未启用时结果不应包含报告: %+v", res)
+	}
+}
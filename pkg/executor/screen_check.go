@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+)
+
+// 可替换的锁屏检测实现（便于测试）
+var (
+	sessionLocked      = screen.SessionLocked
+	captureScreenCheck = screen.CaptureScreen
+)
+
+// ErrScreenLocked 屏幕已锁定或截图全黑：依赖屏幕内容的步骤立即失败，不再等待到超时
+var ErrScreenLocked = errors.New("屏幕似乎已锁定（screen appears locked）")
+
+// screenTaskTypes 依赖屏幕内容（图像 / 文字识别、取色）的任务类型，执行前检查锁屏
+var screenTaskTypes = map[string]bool{
+	TaskTypeClickImage:       true,
+	TaskTypeClickText:        true,
+	TaskTypeWaitImage:        true,
+	TaskTypeWaitText:         true,
+	TaskTypeImageExists:      true,
+	TaskTypeTextExists:       true,
+	TaskTypeTextFindAll:      true,
+	TaskTypeAssertImage:      true,
+	TaskTypeAssertImageCount: true,
+	TaskTypeAssertText:       true,
+	TaskTypeGetPixelColor:    true,
+	TaskTypeAssertPixelColor: true,
+}
+
+// checkScreen 依赖屏幕内容的步骤执行前检查锁屏：先查询平台锁屏状态，再检测截图是否全黑
+// allow_blank_screen 为 true 时跳过黑屏检测（用于本就以黑色为主的画面），平台报告的锁屏仍然失败；截图失败时不检查，由步骤自身报告
+func (e *Executor) checkScreen(taskType string, payload map[string]interface{}) error {
+	if !screenTaskTypes[taskType] {
+		return nil
+	}
+	if locked, known := sessionLocked(); known && locked {
+		return fmt.Errorf("%w: 系统报告当前会话已锁屏", ErrScreenLocked)
+	}
+	if allow, _ := payload["allow_blank_screen"].(bool); allow {
+		return nil
+	}
+	img, err := captureScreenCheck()
+	if err != nil {
+		return nil
+	}
+	blank := screen.IsBlankFrame(img)
+	if e.blankScreen.Swap(blank) != blank {
+		if blank {
+			log("WARN", "截图全黑，屏幕可能已锁定或显示器已关闭")
+		} else {
+			log("INFO", "截图恢复正常")
+		}
+	}
+	if blank {
+		return fmt.Errorf("%w: 截图全黑（如画面本就以黑色为主，可设置 allow_blank_screen: true）", ErrScreenLocked)
+	}
+	return nil
+}
+
+// ScreenLocked 屏幕是否已锁定：平台报告锁屏，或最近一次步骤前检查到截图全黑（用于心跳上报）
+func (e *Executor) ScreenLocked() bool {
+	if locked, known := sessionLocked(); known && locked {
+		return true
+	}
+	return e.blankScreen.Load()
+}
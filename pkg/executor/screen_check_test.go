@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"image"
+	"strings"
+	"testing"
+
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+)
+
+func TestScreenLockCheck(t *testing.T) {
+	origLocked, origCapture := sessionLocked, captureScreenCheck
+	t.Cleanup(func() { sessionLocked, captureScreenCheck = origLocked, origCapture })
+
+	e, _ := newTestExecutor()
+	locked := true
+	sessionLocked = func() (bool, bool) { return locked, true }
+
+	_, err := e.runAction(context.Background(), TaskTypeClickImage, decodePayload(t, `{"template_url":"x.png"}`))
+	if !errors.Is(err, ErrScreenLocked) {
+		t.Fatalf("锁屏时 click_image 应立即失败: %v", err)
+	}
+	if te := classifyError(err); te.Reason != pb.FailureReason_FAILURE_REASON_SYSTEM_ERROR || !strings.Contains(te.Message, "screen appears locked") {
+		t.Errorf("锁屏错误分类 = %v %q", te.Reason, te.Message)
+	}
+	if !e.ScreenLocked() {
+		t.Error("平台报告锁屏时 ScreenLocked 应为 true")
+	}
+	// allow_blank_screen 不跳过平台报告的锁屏
+	if err := e.checkScreen(TaskTypeAssertText, decodePayload(t, `{"allow_blank_screen":true}`)); !errors.Is(err, ErrScreenLocked) {
+		t.Errorf("allow_blank_screen 不应跳过平台锁屏检查: %v", err)
+	}
+	// 不依赖屏幕内容的步骤不检查
+	if _, err := e.runAction(context.Background(), TaskTypeWaitTime, decodePayload(t, `{"duration":1}`)); err != nil {
+		t.Errorf("锁屏时 wait_time 不应失败: %v", err)
+	}
+
+	// 平台无法判断时检测黑屏
+	sessionLocked = func() (bool, bool) { return false, false }
+	frame := image.Image(image.NewRGBA(image.Rect(0, 0, 64, 64)))
+	captureScreenCheck = func() (image.Image, error) { return frame, nil }
+	if err := e.checkScreen(TaskTypeWaitImage, map[string]interface{}{}); !errors.Is(err, ErrScreenLocked) {
+		t.Errorf("截图全黑时应返回 ErrScreenLocked: %v", err)
+	}
+	if !e.ScreenLocked() {
+		t.Error("检查到黑屏后 ScreenLocked 应为 true")
+	}
+	if err := e.checkScreen(TaskTypeWaitImage, decodePayload(t, `{"allow_blank_screen":true}`)); err != nil {
+		t.Errorf("allow_blank_screen 应跳过黑屏检测: %v", err)
+	}
+
+	bright := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for i := range bright.Pix {
+		bright.Pix[i] = 0xff
+	}
+	frame = bright
+	if err := e.checkScreen(TaskTypeWaitImage, map[string]interface{}{}); err != nil {
+		t.Errorf("正常画面不应失败: %v", err)
+	}
+	if e.ScreenLocked() {
+		t.Error("截图恢复后 ScreenLocked 应为 false")
+	}
+
+	// 截图失败时不检查，由步骤自身报告
+	captureScreenCheck = func() (image.Image, error) { return nil, errors.New("截屏失败") }
+	if err := e.checkScreen(TaskTypeWaitImage, map[string]interface{}{}); err != nil {
+		t.Errorf("截图失败时不应报告锁屏: %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultUploadWorkers 截图上传并发数
+	defaultUploadWorkers = 4
+	// uploadTimeout 单张截图上传超时
+	uploadTimeout = 30 * time.Second
+)
+
+// screenshotUploader 步骤截图上传器
+//
+// 批量任务 payload 中包含 upload_url 时启用：截图以 multipart/form-data（字段名 file）
+// POST 到 upload_url，服务端返回 {"url": "..."} 或 {"key": "..."}，
+// 步骤结果中的 ScreenshotBefore/After 替换为返回的 url/key。
+// 上传在固定数量的 worker 中进行，不阻塞后续步骤执行；上传失败时回退为内联 base64。
+type screenshotUploader struct {
+	url    string
+	token  string
+	client *http.Client
+
+	jobs chan func()
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// newScreenshotUploader 创建截图上传器并启动 workers 个上传协程
+func newScreenshotUploader(url, token string, workers int) *screenshotUploader {
+	if workers <= 0 {
+		workers = defaultUploadWorkers
+	}
+	u := &screenshotUploader{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: uploadTimeout},
+		jobs:   make(chan func(), workers*2),
+	}
+	for i := 0; i < workers; i++ {
+		u.wg.Add(1)
+		go func() {
+			defer u.wg.Done()
+			for job := range u.jobs {
+				job()
+			}
+		}()
+	}
+	return u
+}
+
+// submit 提交上传任务（队列满时阻塞，防止截图在内存中无限堆积）
+func (u *screenshotUploader) submit(job func()) {
+	u.jobs <- job
+}
+
+// wait 停止接收新任务并等待所有上传完成
+func (u *screenshotUploader) wait() {
+	u.once.Do(func() {
+		close(u.jobs)
+	})
+	u.wg.Wait()
+}
+
+// upload 上传一张 data URL 格式的截图，返回服务端给出的 url 或 key
+func (u *screenshotUploader) upload(dataURL, filename string) (string, error) {
+	data, err := decodeDataURL(dataURL)
+	if err != nil {
+		return "", err
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return "", fmt.Errorf("构建上传请求失败: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", fmt.Errorf("构建上传请求失败: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("构建上传请求失败: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, u.url, &body)
+	if err != nil {
+		return "", fmt.Errorf("构建上传请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if u.token != "" {
+		req.Header.Set("Authorization", "Bearer "+u.token)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("上传截图失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("上传截图失败: HTTP %d: %s", resp.StatusCode, truncateString(string(respBody), 200))
+	}
+
+	var result struct {
+		URL string `json:"url"`
+		Key string `json:"key"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("解析上传响应失败: %w", err)
+	}
+	if result.URL != "" {
+		return result.URL, nil
+	}
+	if result.Key != "" {
+		return result.Key, nil
+	}
+	return "", fmt.Errorf("上传响应缺少 url/key")
+}
+
+// decodeDataURL 解析 data:<mime>;base64,<data> 格式的字符串
+func decodeDataURL(dataURL string) ([]byte, error) {
+	idx := strings.Index(dataURL, ",")
+	if !strings.HasPrefix(dataURL, "data:") || idx < 0 {
+		return nil, fmt.Errorf("无效的 data URL")
+	}
+	data, err := base64.StdEncoding.DecodeString(dataURL[idx+1:])
+	if err != nil {
+		return nil, fmt.Errorf("解码截图失败: %w", err)
+	}
+	return data, nil
+}
+
+// sendStepResultWithUpload 发送步骤结果
+// 启用截图上传时在上传协程中上传截图后再发送，否则直接发送
+func (e *Executor) sendStepResultWithUpload(taskID string, result *StepExecutionResult, shotOpts screenshotOptions) {
+	uploader := shotOpts.Uploader
+	if uploader == nil || (result.ScreenshotBefore == "" && result.ScreenshotAfter == "" && result.Video == "") {
+		e.sendStepResultV2(taskID, result)
+		return
+	}
+
+	uploader.submit(func() {
+		name := result.StepExecutionID
+		if name == "" {
+			name = result.StepID
+		}
+		result.ScreenshotBefore = uploadOrInline(uploader, result.ScreenshotBefore, name+"_before.jpg")
+		result.ScreenshotAfter = uploadOrInline(uploader, result.ScreenshotAfter, name+"_after.jpg")
+		if result.Video != "" {
+			result.Video = inlineVideo(uploadOrInline(uploader, result.Video, name+"_video.zip"), result.StepID)
+		}
+		e.sendStepResultV2(taskID, result)
+	})
+}
+
+// uploadOrInline 上传截图，失败时保留内联 base64
+func uploadOrInline(uploader *screenshotUploader, dataURL, filename string) string {
+	if dataURL == "" {
+		return ""
+	}
+	ref, err := uploader.upload(dataURL, filename)
+	if err != nil {
+		log("WARN", fmt.Sprintf("%v，回退为内联截图", err))
+		return dataURL
+	}
+	return ref
+}
@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestSendStepResultWithUpload(t *testing.T) {
+	var uploaded []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		file.Close()
+		mu.Lock()
+		uploaded = append(uploaded, header.Filename)
+		mu.Unlock()
+		fmt.Fprintf(w, `{"key":"shots/%s"}`, header.Filename)
+	}))
+	defer server.Close()
+
+	e, recorder := newTestExecutor()
+	shotOpts := e.parseScreenshotOptions(map[string]interface{}{"upload_url": server.URL, "upload_token": "secret"}, false)
+	if shotOpts.Uploader == nil {
+		t.Fatal("指定 upload_url 时应启用上传")
+	}
+
+	dataURL := "data:image/jpeg;base64,AAEC"
+	e.sendStepResultWithUpload("step_1", &StepExecutionResult{
+		StepExecutionID:  "se1",
+		Status:           "SUCCESS",
+		ScreenshotBefore: dataURL,
+		ScreenshotAfter:  dataURL,
+	}, shotOpts)
+	shotOpts.waitUploads()
+
+	results := recorder.results("step_1")
+	if len(results) != 1 {
+		t.Fatalf("应发送 1 个步骤结果, 实际为 %d", len(results))
+	}
+	var got StepExecutionResult
+	if err := json.Unmarshal([]byte(results[0].ResultJson), &got); err != nil {
+		t.Fatalf("解析步骤结果失败: %v", err)
+	}
+	if got.ScreenshotBefore != "shots/se1_before.jpg" || got.ScreenshotAfter != "shots/se1_after.jpg" {
+		t.Errorf("截图应替换为上传后的 key: before=%q after=%q", got.ScreenshotBefore, got.ScreenshotAfter)
+	}
+	if len(uploaded) != 2 {
+		t.Errorf("应上传 2 张截图, 实际为 %d", len(uploaded))
+	}
+}
+
+func TestSendStepResultWithUpload_FallbackInline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	e, recorder := newTestExecutor()
+	shotOpts := e.parseScreenshotOptions(map[string]interface{}{"upload_url": server.URL}, false)
+
+	dataURL := "data:image/jpeg;base64,AAEC"
+	e.sendStepResultWithUpload("step_1", &StepExecutionResult{StepID: "s1", Status: "FAILED", ScreenshotBefore: dataURL}, shotOpts)
+	shotOpts.waitUploads()
+
+	results := recorder.results("step_1")
+	if len(results) != 1 {
+		t.Fatalf("应发送 1 个步骤结果, 实际为 %d", len(results))
+	}
+	var got StepExecutionResult
+	if err := json.Unmarshal([]byte(results[0].ResultJson), &got); err != nil {
+		t.Fatalf("解析步骤结果失败: %v", err)
+	}
+	if got.ScreenshotBefore != dataURL {
+		t.Errorf("上传失败时应回退为内联截图, 实际为 %q", got.ScreenshotBefore)
+	}
+}
@@ -0,0 +1,55 @@
+package executor
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"image"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+	"github.com/zoeyai/zoeyworker/pkg/imgutil"
+)
+
+// frameHashSeed 帧哈希种子（仅用于同一进程内比较，无需跨进程稳定）
+var frameHashSeed = maphash.MakeSeed()
+
+// stepScreenshot 步骤截图
+type stepScreenshot struct {
+	Data  string  // Base64 data URL（JPEG）
+	Scale float64 // 截图像素 / 屏幕像素
+	Hash  uint64  // 原始像素哈希，用于判断前后截图是否相同
+}
+
+// captureStepScreenshot 截取屏幕，计算原始像素哈希后缩放并编码
+func captureStepScreenshot(shotOpts screenshotOptions) (*stepScreenshot, error) {
+	img, err := screen.CaptureScreen()
+	if err != nil {
+		return nil, err
+	}
+	hash := hashFrame(img)
+
+	img, scale, err := screen.DownscaleImage(img, shotOpts.MaxWidth)
+	if err != nil {
+		return nil, err
+	}
+	data, err := screen.ImageToBase64(img, "jpeg", shotOpts.Quality)
+	if err != nil {
+		return nil, err
+	}
+	stepScreenshotBytes.Observe(float64(len(data)))
+	return &stepScreenshot{Data: data, Scale: scale, Hash: hash}, nil
+}
+
+// hashFrame 计算图像原始 RGBA 像素的哈希（在 JPEG 编码前计算，开销远小于编码）
+func hashFrame(img image.Image) uint64 {
+	var h maphash.Hash
+	h.SetSeed(frameHashSeed)
+
+	bounds := img.Bounds()
+	var size [8]byte
+	binary.LittleEndian.PutUint32(size[:4], uint32(bounds.Dx()))
+	binary.LittleEndian.PutUint32(size[4:], uint32(bounds.Dy()))
+	h.Write(size[:])
+
+	imgutil.WriteRGBA(&h, img)
+	return h.Sum64()
+}
@@ -0,0 +1,55 @@
+package executor
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestHashFrame(t *testing.T) {
+	newFrame := func(w, h int, c color.RGBA) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, w, h))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+		return img
+	}
+	gray := color.RGBA{R: 128, G: 128, B: 128, A: 255}
+
+	a := newFrame(64, 48, gray)
+	b := newFrame(64, 48, gray)
+	if hashFrame(a) != hashFrame(b) {
+		t.Error("相同像素的帧哈希应相同")
+	}
+
+	b.SetRGBA(10, 10, color.RGBA{R: 255, A: 255})
+	if hashFrame(a) == hashFrame(b) {
+		t.Error("单个像素变化时帧哈希应不同")
+	}
+
+	if hashFrame(newFrame(32, 96, gray)) == hashFrame(newFrame(96, 32, gray)) {
+		t.Error("尺寸不同的帧哈希应不同")
+	}
+
+	// 子图像只哈希自身区域，与等价的独立图像一致
+	big := newFrame(200, 200, gray)
+	big.SetRGBA(0, 0, color.RGBA{B: 255, A: 255})
+	sub := big.SubImage(image.Rect(50, 50, 114, 98))
+	if hashFrame(sub) != hashFrame(a) {
+		t.Error("子图像哈希应与相同内容的独立图像一致")
+	}
+
+	// 非 RGBA 图像按 RGBA 像素计算
+	nrgba := image.NewNRGBA(image.Rect(0, 0, 64, 48))
+	for i := range nrgba.Pix {
+		nrgba.Pix[i] = 128
+		if i%4 == 3 {
+			nrgba.Pix[i] = 255
+		}
+	}
+	if hashFrame(nrgba) != hashFrame(a) {
+		t.Error("非 RGBA 图像应转换为 RGBA 后计算哈希")
+	}
+}
@@ -0,0 +1,196 @@
+package executor
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image/jpeg"
+	"sync"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+)
+
+// captureVideoFrame 可替换的录像帧截图实现（便于测试）
+var captureVideoFrame = screen.CaptureScreen
+
+// 步骤录像参数
+const (
+	defaultVideoFPS      = 3
+	minVideoFPS          = 2
+	maxVideoFPS          = 5
+	defaultVideoMaxWidth = 640 // 帧最大宽度，比步骤截图更小以控制体积
+
+	// 每个步骤最多保留的帧数和 JPEG 总字节数，超出后停止截取（长时间等待的步骤只保留开头部分）
+	videoMaxFrames = 300
+	videoMaxBytes  = 16 << 20
+
+	// videoInlineMaxBytes 没有上传地址（或上传失败）时内联 base64 的录像大小上限，超出时不附带录像
+	videoInlineMaxBytes = 2 << 20
+)
+
+// videoOptions 步骤录像选项（record_video 为 true 时启用）
+type videoOptions struct {
+	FPS      int // 每秒帧数（video_fps，2-5，默认 3）
+	MaxWidth int // 帧最大宽度（video_max_width，默认 640）
+	Quality  int // JPEG 质量，同步骤截图
+}
+
+// parseVideoOptions 解析 debug_case / execute_case 的录像选项，未启用时返回 nil；超出范围的 video_fps 取最近的有效值
+func parseVideoOptions(payload map[string]interface{}, quality int) *videoOptions {
+	if record, _ := payload["record_video"].(bool); !record {
+		return nil
+	}
+	opts := &videoOptions{FPS: defaultVideoFPS, MaxWidth: defaultVideoMaxWidth, Quality: quality}
+	if fps, ok := payload["video_fps"].(float64); ok {
+		opts.FPS = min(max(int(fps), minVideoFPS), maxVideoFPS)
+	}
+	if width, ok := payload["video_max_width"].(float64); ok && width > 0 {
+		opts.MaxWidth = int(width)
+	}
+	return opts
+}
+
+// videoFrame 录像中的一帧
+type videoFrame struct {
+	offset time.Duration // 相对步骤开始的时间
+	data   []byte        // JPEG
+}
+
+// stepRecorder 在独立协程中按固定间隔截取步骤执行期间的画面
+// 第一帧在一个间隔之后截取，短于一个间隔的步骤不产生录像；画面未变化的帧不保存（回放时沿用上一帧）
+type stepRecorder struct {
+	opts  *videoOptions
+	start time.Time
+	quit  chan struct{}
+
+	mu        sync.Mutex
+	stopped   bool
+	frames    []videoFrame
+	bytes     int
+	truncated bool // 达到帧数或字节数上限后不再截取
+}
+
+// startStepRecording 开始录像，opts 为 nil 时返回 nil（stop 返回 nil）
+func startStepRecording(opts *videoOptions) *stepRecorder {
+	if opts == nil {
+		return nil
+	}
+	r := &stepRecorder{opts: opts, start: time.Now(), quit: make(chan struct{})}
+	go r.run()
+	return r
+}
+
+func (r *stepRecorder) run() {
+	ticker := time.NewTicker(time.Second / time.Duration(r.opts.FPS))
+	defer ticker.Stop()
+	var lastHash uint64
+	for {
+		select {
+		case <-r.quit:
+			return
+		case <-ticker.C:
+		}
+		img, err := captureVideoFrame()
+		if err != nil {
+			continue
+		}
+		offset := time.Since(r.start)
+		hash := hashFrame(img)
+		if hash == lastHash {
+			continue
+		}
+		lastHash = hash
+		img, _, err = screen.DownscaleImage(img, r.opts.MaxWidth)
+		if err != nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: r.opts.Quality}); err != nil {
+			continue
+		}
+
+		r.mu.Lock()
+		if r.stopped {
+			r.mu.Unlock()
+			return
+		}
+		if len(r.frames) >= videoMaxFrames || r.bytes+buf.Len() > videoMaxBytes {
+			r.truncated = true
+			r.mu.Unlock()
+			return
+		}
+		r.frames = append(r.frames, videoFrame{offset: offset, data: buf.Bytes()})
+		r.bytes += buf.Len()
+		r.mu.Unlock()
+	}
+}
+
+// stepVideo 编码后的步骤录像
+type stepVideo struct {
+	Data   string // zip 的 data URL
+	Frames int
+}
+
+// stop 立即停止录像（不等待正在截取的帧），将已截取的帧打包为 zip；没有帧时返回 nil
+// zip 中为 frame_0001.jpg 等帧及 manifest.json（fps、每帧相对步骤开始的毫秒数、是否因上限截断）
+func (r *stepRecorder) stop() *stepVideo {
+	if r == nil {
+		return nil
+	}
+	close(r.quit)
+	r.mu.Lock()
+	r.stopped = true
+	frames, truncated := r.frames, r.truncated
+	r.frames = nil
+	r.mu.Unlock()
+	if len(frames) == 0 {
+		return nil
+	}
+
+	type manifestFrame struct {
+		File     string `json:"file"`
+		OffsetMs int64  `json:"offset_ms"`
+	}
+	manifest := struct {
+		FPS       int             `json:"fps"`
+		Truncated bool            `json:"truncated,omitempty"`
+		Frames    []manifestFrame `json:"frames"`
+	}{FPS: r.opts.FPS, Truncated: truncated}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i, frame := range frames {
+		name := fmt.Sprintf("frame_%04d.jpg", i+1)
+		// JPEG 已压缩，不再 deflate
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: r.start.Add(frame.offset)})
+		if err != nil {
+			return nil
+		}
+		w.Write(frame.data)
+		manifest.Frames = append(manifest.Frames, manifestFrame{File: name, OffsetMs: frame.offset.Milliseconds()})
+	}
+	w, err := zw.Create("manifest.json")
+	if err != nil {
+		return nil
+	}
+	json.NewEncoder(w).Encode(manifest)
+	if err := zw.Close(); err != nil {
+		return nil
+	}
+	return &stepVideo{
+		Data:   "data:application/zip;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()),
+		Frames: len(frames),
+	}
+}
+
+// inlineVideo 内联的录像超出大小上限时丢弃（输出 WARN 日志），避免步骤结果过大
+func inlineVideo(dataURL, stepID string) string {
+	if len(dataURL) <= videoInlineMaxBytes*4/3+64 {
+		return dataURL
+	}
+	log("WARN", fmt.Sprintf("步骤 %s 的录像过大（%d KB），未设置 upload_url 时不附带录像", stepID, len(dataURL)*3/4/1024))
+	return ""
+}
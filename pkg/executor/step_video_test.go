@@ -0,0 +1,83 @@
+package executor
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStepVideo(t *testing.T) {
+	if parseVideoOptions(map[string]interface{}{}, 60) != nil {
+		t.Error("未设置 record_video 时不应录像")
+	}
+	if opts := parseVideoOptions(decodePayload(t, `{"record_video": true, "video_fps": 30}`), 60); opts == nil || opts.FPS != maxVideoFPS || opts.MaxWidth != defaultVideoMaxWidth {
+		t.Errorf("video_fps 应限制在 2-5: %+v", opts)
+	}
+
+	origCapture := captureVideoFrame
+	t.Cleanup(func() { captureVideoFrame = origCapture })
+	var captured atomic.Int32
+	captureVideoFrame = func() (image.Image, error) {
+		n := captured.Add(1)
+		img := image.NewRGBA(image.Rect(0, 0, 1280, 720))
+		draw.Draw(img, img.Bounds(), &image.Uniform{color.RGBA{uint8(n * 40), 0, 0, 255}}, image.Point{}, draw.Src)
+		return img, nil
+	}
+	RegisterAction("test_slow", func(ctx context.Context, payload map[string]interface{}) (*ActionResult, error) {
+		time.Sleep(700 * time.Millisecond)
+		return &ActionResult{}, nil
+	})
+	t.Cleanup(func() {
+		actionsMu.Lock()
+		delete(actions, "test_slow")
+		actionsMu.Unlock()
+	})
+
+	e, _ := newTestExecutor()
+	shotOpts := screenshotOptions{Mode: ScreenshotModeNever, Video: &videoOptions{FPS: 5, MaxWidth: 320, Quality: 60}}
+	step := e.executeStepWithScreenshots(context.Background(), "", "s1", "test_slow", map[string]interface{}{}, shotOpts, nil)
+	const prefix = "data:application/zip;base64,"
+	if !strings.HasPrefix(step.Video, prefix) || step.VideoFrames < 2 || step.VideoFrames > 4 {
+		t.Fatalf("应录制 2-4 帧: frames=%d video=%.40s", step.VideoFrames, step.Video)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(step.Video, prefix))
+	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil || len(zr.File) != step.VideoFrames+1 {
+		t.Fatalf("zip 应包含每帧和 manifest.json: %v", err)
+	}
+	frame, _ := zr.Open("frame_0001.jpg")
+	if cfg, err := jpeg.DecodeConfig(frame); err != nil || cfg.Width != 320 {
+		t.Errorf("帧应缩小到 video_max_width: %+v %v", cfg, err)
+	}
+	mf, _ := zr.Open("manifest.json")
+	var manifest struct {
+		FPS    int `json:"fps"`
+		Frames []struct {
+			OffsetMs int64 `json:"offset_ms"`
+		} `json:"frames"`
+	}
+	if err := json.NewDecoder(mf).Decode(&manifest); err != nil || manifest.FPS != 5 || len(manifest.Frames) != step.VideoFrames || manifest.Frames[0].OffsetMs < 150 {
+		t.Errorf("manifest: %+v %v", manifest, err)
+	}
+
+	// 步骤结束后立即停止截取；短于一个帧间隔的步骤不录像
+	after := captured.Load()
+	time.Sleep(300 * time.Millisecond)
+	if captured.Load() > after+1 { // 允许停止时恰好进行中的一次
+		t.Error("步骤结束后不应继续截取")
+	}
+	step = e.executeStepWithScreenshots(context.Background(), "", "s2", TaskTypeWaitTime, decodePayload(t, `{"duration": 1}`), shotOpts, nil)
+	if step.Video != "" || step.VideoFrames != 0 {
+		t.Errorf("短步骤不应录像: %d", step.VideoFrames)
+	}
+}
@@ -0,0 +1,120 @@
+package executor
+
+import (
+	"container/list"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultCompletedTaskCacheSize 默认缓存的已完成任务数量
+const DefaultCompletedTaskCacheSize = 200
+
+// completedTaskCache 已完成任务的 LRU 缓存
+// 用于识别服务端重连后重复下发的任务，并重发缓存的最终结果
+type completedTaskCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List               // 最近使用的在前
+	items    map[string]*list.Element // taskID -> 元素
+}
+
+type completedTaskEntry struct {
+	taskID string
+	result *pb.TaskResult
+}
+
+// newCompletedTaskCache 创建已完成任务缓存
+func newCompletedTaskCache(capacity int) *completedTaskCache {
+	if capacity <= 0 {
+		capacity = DefaultCompletedTaskCacheSize
+	}
+	return &completedTaskCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// put 记录任务的最终结果（保存去掉内嵌截图的副本，见 trimTaskResult）
+func (c *completedTaskCache) put(taskID string, result *pb.TaskResult) {
+	result = trimTaskResult(result)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[taskID]; ok {
+		elem.Value.(*completedTaskEntry).result = result
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[taskID] = c.order.PushFront(&completedTaskEntry{taskID: taskID, result: result})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*completedTaskEntry).taskID)
+	}
+}
+
+// get 获取任务的缓存结果
+func (c *completedTaskCache) get(taskID string) (*pb.TaskResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[taskID]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*completedTaskEntry).result, true
+}
+
+// len 返回缓存中的任务数量
+func (c *completedTaskCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// trimTaskResult 返回结果副本，ResultJson 中内嵌的截图、录像等 data URL 替换为空字符串
+// 缓存只用于应答重复下发的任务，不必为此常驻几百 MB 的 base64 图片
+func trimTaskResult(result *pb.TaskResult) *pb.TaskResult {
+	if result == nil {
+		return nil
+	}
+	trimmed := proto.Clone(result).(*pb.TaskResult)
+	if !strings.Contains(trimmed.ResultJson, "data:") {
+		return trimmed
+	}
+	var data interface{}
+	if err := json.Unmarshal([]byte(trimmed.ResultJson), &data); err != nil {
+		return trimmed
+	}
+	if out, err := json.Marshal(stripDataURLs(data)); err == nil {
+		trimmed.ResultJson = string(out)
+	}
+	return trimmed
+}
+
+// stripDataURLs 递归将 JSON 值中的 data URL 字符串替换为空字符串
+func stripDataURLs(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = stripDataURLs(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = stripDataURLs(item)
+		}
+	case string:
+		if strings.HasPrefix(val, "data:") {
+			return ""
+		}
+	}
+	return v
+}
@@ -0,0 +1,51 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+)
+
+func TestCompletedTaskCache_Eviction(t *testing.T) {
+	cache := newCompletedTaskCache(2)
+
+	cache.put("a", &pb.TaskResult{TaskId: "a"})
+	cache.put("b", &pb.TaskResult{TaskId: "b"})
+	cache.get("a") // a 变为最近使用
+	cache.put("c", &pb.TaskResult{TaskId: "c"})
+
+	if cache.len() != 2 {
+		t.Errorf("缓存大小应为 2, 实际为 %d", cache.len())
+	}
+	if _, ok := cache.get("b"); ok {
+		t.Error("b 应被淘汰")
+	}
+	if _, ok := cache.get("a"); !ok {
+		t.Error("a 不应被淘汰")
+	}
+	if _, ok := cache.get("c"); !ok {
+		t.Error("c 不应被淘汰")
+	}
+}
+
+func TestCompletedTaskCache_TrimsScreenshots(t *testing.T) {
+	cache := newCompletedTaskCache(2)
+	shot := "data:image/png;base64," + strings.Repeat("A", 1024)
+	result := &pb.TaskResult{
+		TaskId:     "a",
+		ResultJson: `{"screenshot":"` + shot + `","step_results":[{"step_id":"s1","screenshot_before":"` + shot + `","screenshot_after":"https://example.com/a.png"}]}`,
+	}
+	cache.put("a", result)
+
+	cached, _ := cache.get("a")
+	if strings.Contains(cached.ResultJson, "data:") {
+		t.Errorf("缓存结果不应保留内嵌截图: %s", cached.ResultJson)
+	}
+	if !strings.Contains(cached.ResultJson, `"step_id":"s1"`) || !strings.Contains(cached.ResultJson, "https://example.com/a.png") {
+		t.Errorf("缓存结果应保留其余字段: %s", cached.ResultJson)
+	}
+	if !strings.Contains(result.ResultJson, shot) {
+		t.Error("不应修改发送的原结果")
+	}
+}
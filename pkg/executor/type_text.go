@@ -0,0 +1,255 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/input"
+)
+
+// typeText 逐字输入文字（可替换，便于测试）
+var typeText = input.TypeText
+
+// pasteSettleDelay 发送粘贴快捷键后等待目标应用读取剪贴板的时间，之后才写入下一段或恢复原剪贴板内容
+var pasteSettleDelay = 200 * time.Millisecond
+
+// focusSettleDelay 点击 target 或清空输入框后等待输入框响应的时间
+var focusSettleDelay = 100 * time.Millisecond
+
+// type_text 的输入方式
+const (
+	TypeMethodType  = "type"  // 逐字输入（默认）
+	TypeMethodPaste = "paste" // 写入剪贴板后发送粘贴快捷键，结束后恢复原剪贴板内容
+)
+
+// maskedText mask_in_logs 时日志和步骤结果中代替原文的内容
+const maskedText = "******"
+
+// typeTokenPattern 文字中的按键标记：{ENTER}、{TAB}、{CTRL+A}、{DELAY:500}（大写，避免误伤普通文字中的花括号）
+var typeTokenPattern = regexp.MustCompile(`\{([A-Z][A-Z0-9_]*(?:\+[A-Z0-9_]+)*|DELAY:\d+)\}`)
+
+// typeSegment type_text 解析后的一段：文字、按键或等待
+type typeSegment struct {
+	text  string
+	keys  []string
+	wait  bool
+	delay time.Duration
+}
+
+// parseTypeText 拆分文字中的按键标记；raw 为 true 时不解析标记
+func parseTypeText(text string, raw bool) ([]typeSegment, error) {
+	if raw {
+		return []typeSegment{{text: text}}, nil
+	}
+	var segments []typeSegment
+	last := 0
+	for _, m := range typeTokenPattern.FindAllStringSubmatchIndex(text, -1) {
+		if m[0] > last {
+			segments = append(segments, typeSegment{text: text[last:m[0]]})
+		}
+		last = m[1]
+		token := text[m[2]:m[3]]
+		if ms, ok := strings.CutPrefix(token, "DELAY:"); ok {
+			v, err := strconv.Atoi(ms)
+			if err != nil || time.Duration(v)*time.Millisecond > maxKeyDelay {
+				return nil, auto.Errorf(auto.ErrParam, "{%s} 的等待时间必须是 0-%d 毫秒", token, maxKeyDelay.Milliseconds())
+			}
+			segments = append(segments, typeSegment{wait: true, delay: time.Duration(v) * time.Millisecond})
+			continue
+		}
+		keys := strings.Split(token, "+")
+		for _, k := range keys {
+			if err := input.ValidateKey(k); err != nil {
+				return nil, fmt.Errorf("{%s}: %w", token, err)
+			}
+		}
+		segments = append(segments, typeSegment{keys: keys})
+	}
+	if last < len(text) {
+		segments = append(segments, typeSegment{text: text[last:]})
+	}
+	return segments, nil
+}
+
+// typeTargetAction 根据 target 的定位方式选择点击动作：image、text 或坐标（x/y、x_pct/y_pct）
+func typeTargetAction(target map[string]interface{}) (string, error) {
+	image, _ := target["image"].(string)
+	textStr, _ := target["text"].(string)
+	switch {
+	case image != "" && textStr != "":
+		return "", auto.Errorf(auto.ErrParam, "target 只能指定 image、text、坐标之一")
+	case image != "":
+		return TaskTypeClickImage, nil
+	case textStr != "":
+		return TaskTypeClickText, nil
+	}
+	for _, key := range []string{"x", "y", "x_pct", "y_pct"} {
+		if _, ok := target[key]; ok {
+			return TaskTypeMouseClick, nil
+		}
+	}
+	return "", auto.Errorf(auto.ErrParam, "target 必须指定 image、text 或坐标（x/y、x_pct/y_pct）")
+}
+
+// executeTypeText 执行输入文字：method 为 type（默认，逐字输入）或 paste（经剪贴板粘贴，适合 CJK 等逐字输入不可靠的文字）；
+// 文字中的 {ENTER}、{TAB}、{CTRL+A}、{DELAY:500} 等标记按键或等待（raw 为 true 时原样输入）；
+// mask_in_logs 为 true 时日志、步骤结果和错误信息中不出现原文（用于密码）
+// 指定 target（同 click_image / click_text / mouse_click 的参数）时先点击输入框；clear_before 为 true 时输入前全选并删除已有内容
+func (e *Executor) executeTypeText(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
+	textStr, ok := payload["text"].(string)
+	if !ok {
+		return nil, auto.Errorf(auto.ErrParam, "缺少 text 参数")
+	}
+	method := TypeMethodType
+	if raw, ok := payload["method"]; ok {
+		name, _ := raw.(string)
+		switch name = strings.ToLower(strings.TrimSpace(name)); name {
+		case TypeMethodType, TypeMethodPaste:
+			method = name
+		default:
+			return nil, auto.Errorf(auto.ErrParam, "method 必须是 type 或 paste: %v", raw)
+		}
+	}
+	rawText, _ := payload["raw"].(bool)
+	masked, _ := payload["mask_in_logs"].(bool)
+
+	segments, err := parseTypeText(textStr, rawText)
+	if err != nil {
+		if masked {
+			return nil, auto.Errorf(auto.ErrParam, "text 中有无效的按键标记（mask_in_logs 已隐藏内容）")
+		}
+		return nil, err
+	}
+
+	clearBefore, _ := payload["clear_before"].(bool)
+	var target map[string]interface{}
+	var targetAction string
+	if raw, ok := payload["target"]; ok {
+		if target, ok = raw.(map[string]interface{}); !ok {
+			return nil, auto.Errorf(auto.ErrParam, "target 必须是对象: %v", raw)
+		}
+		if targetAction, err = typeTargetAction(target); err != nil {
+			return nil, err
+		}
+	}
+
+	if target != nil {
+		clicked, err := e.runAction(ctx, targetAction, target)
+		if err != nil {
+			return nil, fmt.Errorf("点击输入框失败: %w", err)
+		}
+		result.ClickPosition, result.TargetBounds = clicked.ClickPosition, clicked.TargetBounds
+		result.SearchRegion, result.AnchorBounds = clicked.SearchRegion, clicked.AnchorBounds
+		result.MatchedText, result.Confidence, result.Scale = clicked.MatchedText, clicked.Confidence, clicked.Scale
+		time.Sleep(focusSettleDelay)
+	}
+	if clearBefore {
+		// 全选（macOS 为 Command+A）后用 Backspace 删除（macOS 键盘上标为 Delete）
+		keyTap("a", "primary")
+		keyTap("backspace")
+		time.Sleep(focusSettleDelay)
+	}
+
+	if method == TypeMethodPaste {
+		restore := saveClipboard()
+		defer restore()
+	}
+	chars, tokens := 0, 0
+	for _, seg := range segments {
+		switch {
+		case seg.keys != nil:
+			keyTap(seg.keys[len(seg.keys)-1], seg.keys[:len(seg.keys)-1]...)
+			tokens++
+		case seg.wait:
+			tokens++
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(seg.delay):
+			}
+		case method == TypeMethodPaste:
+			if err := writeClipboard(seg.text); err != nil {
+				return nil, fmt.Errorf("写入剪贴板失败: %w", err)
+			}
+			keyTap("v", "primary")
+			time.Sleep(pasteSettleDelay)
+			chars += utf8.RuneCountInString(seg.text)
+		default:
+			typeText(seg.text)
+			chars += utf8.RuneCountInString(seg.text)
+		}
+	}
+	return map[string]interface{}{
+		"typed":   true,
+		"method":  method,
+		"length":  chars,
+		"tokens":  tokens,
+		"clicked": target != nil,
+		"cleared": clearBefore,
+	}, nil
+}
+
+// saveClipboard 保存剪贴板中的文字或图像，返回恢复函数；无法读取或为空时恢复为空文字
+func saveClipboard() (restore func()) {
+	contentType, err := clipboardContentType()
+	if err != nil {
+		log("WARN", fmt.Sprintf("获取剪贴板内容类型失败，粘贴后不恢复剪贴板: %v", err))
+		return func() {}
+	}
+	switch contentType {
+	case input.ClipboardText:
+		if text, err := readClipboard(); err == nil {
+			return func() { restoreClipboard(writeClipboard(text)) }
+		}
+	case input.ClipboardImage:
+		if img, err := readClipboardImage(); err == nil {
+			return func() { restoreClipboard(writeClipboardImage(img)) }
+		}
+	}
+	return func() { restoreClipboard(writeClipboard("")) }
+}
+
+func restoreClipboard(err error) {
+	if err != nil {
+		log("WARN", fmt.Sprintf("恢复剪贴板内容失败: %v", err))
+	}
+}
+
+// redactPayload 将 mask_in_logs 为 true 的对象（包括批量任务的步骤参数）中的 text 替换为 maskedText，用于日志
+func redactPayload(payloadJSON string) string {
+	if !strings.Contains(payloadJSON, "mask_in_logs") {
+		return payloadJSON
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(payloadJSON), &v); err != nil {
+		return "（payload 含 mask_in_logs，已隐藏）"
+	}
+	redactValue(v)
+	out, _ := json.Marshal(v)
+	return string(out)
+}
+
+func redactValue(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if masked, _ := v["mask_in_logs"].(bool); masked {
+			if _, ok := v["text"]; ok {
+				v["text"] = maskedText
+			}
+		}
+		for _, child := range v {
+			redactValue(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			redactValue(child)
+		}
+	}
+}
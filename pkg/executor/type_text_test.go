@@ -0,0 +1,126 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/input"
+)
+
+func TestTypeText(t *testing.T) {
+	origType, origTap := typeText, keyTap
+	origRead, origWrite, origType2, origDelay := readClipboard, writeClipboard, clipboardContentType, pasteSettleDelay
+	t.Cleanup(func() {
+		typeText, keyTap = origType, origTap
+		readClipboard, writeClipboard, clipboardContentType, pasteSettleDelay = origRead, origWrite, origType2, origDelay
+	})
+	var events []string
+	typeText = func(text string) { events = append(events, "type:"+text) }
+	keyTap = func(key string, modifiers ...string) {
+		events = append(events, "tap:"+strings.Join(append(modifiers, key), "+"))
+	}
+	clipboard := "原来的内容"
+	clipboardContentType = func() (string, error) { return input.ClipboardText, nil }
+	readClipboard = func() (string, error) { return clipboard, nil }
+	writeClipboard = func(text string) error {
+		clipboard = text
+		events = append(events, "clip:"+text)
+		return nil
+	}
+	pasteSettleDelay = 0
+
+	e, _ := newTestExecutor()
+	run := func(payloadJSON string) (map[string]interface{}, error) {
+		t.Helper()
+		events = nil
+		result, err := e.runAction(context.Background(), TaskTypeTypeText, decodePayload(t, payloadJSON))
+		data, _ := result.Data.(map[string]interface{})
+		return data, err
+	}
+
+	start := time.Now()
+	data, err := run(`{"text": "user{TAB}pass{DELAY:30}{CTRL+A}{ENTER}"}`)
+	if err != nil || strings.Join(events, " ") != "type:user tap:TAB type:pass tap:CTRL+A tap:ENTER" || time.Since(start) < 30*time.Millisecond {
+		t.Errorf("按键标记: %v %v", events, err)
+	}
+	if data["length"] != 8 || data["tokens"] != 4 {
+		t.Errorf("结果: %v", data)
+	}
+	if _, err := run(`{"text": "if (a) {return}{TAB}", "raw": true}`); err != nil || strings.Join(events, " ") != "type:if (a) {return}{TAB}" {
+		t.Errorf("raw 应原样输入: %v %v", events, err)
+	}
+	if _, err := run(`{"text": "{\"a\": 1} {x}"}`); err != nil || len(events) != 1 {
+		t.Errorf("小写或非标记的花括号应原样输入: %v %v", events, err)
+	}
+
+	// 粘贴模式：逐段写入剪贴板并粘贴，结束后恢复原内容
+	if _, err := run(`{"text": "你好{ENTER}世界", "method": "paste"}`); err != nil ||
+		strings.Join(events, " ") != "clip:你好 tap:primary+v tap:ENTER clip:世界 tap:primary+v clip:原来的内容" {
+		t.Errorf("paste: %v %v", events, err)
+	}
+
+	for _, payload := range []string{`{}`, `{"text": "a", "method": "xdotool"}`, `{"text": "a{DELAY:999999}"}`} {
+		if _, err := run(payload); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("%s 应返回 ErrParam: %v", payload, err)
+		}
+	}
+	if _, err := run(`{"text": "a{ENTRE}"}`); !errors.Is(err, auto.ErrParam) || !strings.Contains(err.Error(), "ENTRE") || len(events) != 0 {
+		t.Errorf("无效的按键标记应指出键名且不输入: %v %v", err, events)
+	}
+	if _, err := run(`{"text": "secret{ENTRE}", "mask_in_logs": true}`); !errors.Is(err, auto.ErrParam) || strings.Contains(err.Error(), "ENTRE") {
+		t.Errorf("mask_in_logs 时错误信息不应包含原文: %v", err)
+	}
+
+	// mask_in_logs：步骤结果和日志中不出现原文
+	step := e.executeStepWithScreenshots(context.Background(), "", "s1", TaskTypeTypeText,
+		decodePayload(t, `{"text": "p@ssw0rd", "mask_in_logs": true}`), screenshotOptions{Mode: ScreenshotModeNever}, nil)
+	if step.Status != "SUCCESS" || step.InputText != maskedText {
+		t.Errorf("步骤结果应隐藏输入内容: %+v", step)
+	}
+	redacted := redactPayload(`{"steps": [{"task_type": "type_text", "params": {"text": "p@ssw0rd", "mask_in_logs": true}}, {"params": {"text": "visible"}}]}`)
+	if strings.Contains(redacted, "p@ssw0rd") || !strings.Contains(redacted, maskedText) || !strings.Contains(redacted, "visible") {
+		t.Errorf("redactPayload = %s", redacted)
+	}
+	if plain := `{"text": "hello"}`; redactPayload(plain) != plain {
+		t.Error("没有 mask_in_logs 时 payload 应原样记录")
+	}
+
+	// clear_before / target：先点击输入框，再全选删除，最后输入
+	origFocus := focusSettleDelay
+	origClick, _ := lookupAction(TaskTypeClickImage)
+	t.Cleanup(func() {
+		focusSettleDelay = origFocus
+		RegisterAction(TaskTypeClickImage, origClick)
+	})
+	focusSettleDelay = 0
+	RegisterAction(TaskTypeClickImage, func(ctx context.Context, payload map[string]interface{}) (*ActionResult, error) {
+		events = append(events, "click:"+payload["image"].(string))
+		return &ActionResult{Success: true, ClickPosition: &PositionInfo{X: 10, Y: 20}}, nil
+	})
+	events = nil
+	result, err := e.runAction(context.Background(), TaskTypeTypeText,
+		decodePayload(t, `{"text": "new", "clear_before": true, "target": {"image": "input.png"}}`))
+	if err != nil || strings.Join(events, " ") != "click:input.png tap:primary+a tap:backspace type:new" {
+		t.Errorf("clear_before + target: %v %v", events, err)
+	}
+	if data, _ := result.Data.(map[string]interface{}); data["cleared"] != true || data["clicked"] != true ||
+		result.ClickPosition == nil || result.ClickPosition.X != 10 {
+		t.Errorf("结果应记录清空和点击位置: %+v", result)
+	}
+	for _, payload := range []string{`{"text": "a", "target": "input.png"}`, `{"text": "a", "target": {}}`,
+		`{"text": "a", "target": {"image": "a.png", "text": "用户名"}}`} {
+		if _, err := run(payload); !errors.Is(err, auto.ErrParam) || len(events) != 0 {
+			t.Errorf("%s 应返回 ErrParam 且不操作: %v %v", payload, err, events)
+		}
+	}
+	events = nil
+	step = e.executeStepWithScreenshots(context.Background(), "", "s2", TaskTypeTypeText,
+		decodePayload(t, `{"text": "x", "clear_before": true}`), screenshotOptions{Mode: ScreenshotModeNever}, nil)
+	if step.Status != "SUCCESS" || !step.Cleared || strings.Join(events, " ") != "tap:primary+a tap:backspace type:x" {
+		t.Errorf("步骤结果应记录 cleared: %+v %v", step, events)
+	}
+}
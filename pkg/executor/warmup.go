@@ -0,0 +1,36 @@
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto/text"
+)
+
+// OCR 预热依赖（测试时替换）
+var (
+	ocrAvailable = isOCRAvailable
+	warmupOCR    = text.Warmup
+)
+
+// WarmupOCR 在后台初始化 OCR 识别器并执行一次推理，消除首个文字类步骤的模型加载延迟（通常 3-8 秒）
+// 不阻塞调用方，OCR 不可用时跳过；同一执行器只预热一次，返回的 channel 在预热结束（或跳过）时关闭
+func (e *Executor) WarmupOCR() <-chan struct{} {
+	e.warmupOnce.Do(func() {
+		e.warmupDone = make(chan struct{})
+		go func() {
+			defer close(e.warmupDone)
+			if !ocrAvailable() {
+				log("DEBUG", "OCR 未安装，跳过预热")
+				return
+			}
+			elapsed, err := warmupOCR()
+			if err != nil {
+				log("WARN", fmt.Sprintf("OCR 预热失败: %v", err))
+				return
+			}
+			log("INFO", fmt.Sprintf("OCR 预热完成，耗时 %v", elapsed.Round(time.Millisecond)))
+		}()
+	})
+	return e.warmupDone
+}
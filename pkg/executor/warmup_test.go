@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mockWarmup 替换 OCR 可用性检查和预热函数，返回预热调用次数
+func mockWarmup(t *testing.T, available bool, warmup func() (time.Duration, error)) *int32 {
+	t.Helper()
+	var calls int32
+	origAvailable, origWarmup := ocrAvailable, warmupOCR
+	ocrAvailable = func() bool { return available }
+	warmupOCR = func() (time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		return warmup()
+	}
+	t.Cleanup(func() {
+		ocrAvailable, warmupOCR = origAvailable, origWarmup
+	})
+	return &calls
+}
+
+func TestWarmupOCR_NonBlockingOnce(t *testing.T) {
+	release := make(chan struct{})
+	calls := mockWarmup(t, true, func() (time.Duration, error) {
+		<-release
+		return 5 * time.Millisecond, nil
+	})
+	e, _ := newTestExecutor()
+
+	// 预热阻塞时调用方立即返回
+	done := e.WarmupOCR()
+	select {
+	case <-done:
+		t.Fatal("预热尚未完成")
+	default:
+	}
+	if again := e.WarmupOCR(); again != done {
+		t.Error("重复调用应返回同一次预热")
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("预热未结束")
+	}
+	if n := atomic.LoadInt32(calls); n != 1 {
+		t.Errorf("预热次数 = %d, 期望 1", n)
+	}
+}
+
+func TestWarmupOCR_SkippedWhenUnavailable(t *testing.T) {
+	calls := mockWarmup(t, false, func() (time.Duration, error) {
+		return 0, nil
+	})
+	e, _ := newTestExecutor()
+
+	select {
+	case <-e.WarmupOCR():
+	case <-time.After(time.Second):
+		t.Fatal("跳过预热时应立即结束")
+	}
+	if n := atomic.LoadInt32(calls); n != 0 {
+		t.Errorf("OCR 不可用时不应预热, 调用次数 = %d", n)
+	}
+}
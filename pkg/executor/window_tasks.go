@@ -0,0 +1,335 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/window"
+)
+
+// 可替换的窗口查询与操作实现（便于测试）
+var (
+	activeWindowTitle = window.GetActiveWindowTitle
+	setWindowBounds   = window.SetWindowBounds
+	minimizeWindow    = window.MinimizeWindow
+	maximizeWindow    = window.MaximizeWindow
+)
+
+// windowSettleDelay 调整窗口后等待窗口管理器完成布局再读取实际边界的时间
+var windowSettleDelay = 300 * time.Millisecond
+
+// 窗口标题匹配模式（均忽略大小写）
+const (
+	TitleMatchContains = "contains" // 包含（默认）
+	TitleMatchExact    = "exact"    // 去除首尾空白后相等
+)
+
+// parseTitleMatchMode 解析 match_mode（contains / exact）
+func parseTitleMatchMode(payload map[string]interface{}) (string, error) {
+	raw, ok := payload["match_mode"]
+	if !ok {
+		return TitleMatchContains, nil
+	}
+	name, _ := raw.(string)
+	switch name = strings.ToLower(strings.TrimSpace(name)); name {
+	case "", TitleMatchContains:
+		return TitleMatchContains, nil
+	case TitleMatchExact:
+		return name, nil
+	default:
+		return "", auto.Errorf(auto.ErrParam, "match_mode 必须是 contains 或 exact: %v", raw)
+	}
+}
+
+// titleMatches 按匹配模式比较窗口标题（忽略大小写）
+func titleMatches(title, expected, mode string) bool {
+	if mode == TitleMatchExact {
+		return strings.EqualFold(strings.TrimSpace(title), strings.TrimSpace(expected))
+	}
+	return strings.Contains(strings.ToLower(title), strings.ToLower(expected))
+}
+
+// windowData 窗口信息结果，x / y 为窗口中心，bounds 同时写入 TargetBounds（作为 MatchLocation）
+func windowData(w *window.WindowInfo, result *ActionResult) map[string]interface{} {
+	bounds := &BoundsInfo{X: w.Bounds.X, Y: w.Bounds.Y, Width: w.Bounds.Width, Height: w.Bounds.Height}
+	if bounds.Width > 0 && bounds.Height > 0 {
+		result.TargetBounds = bounds
+	}
+	return map[string]interface{}{
+		"pid":           w.PID,
+		"window_handle": w.Handle,
+		"title":         w.Title,
+		"owner_name":    w.OwnerName,
+		"x":             w.Bounds.X + w.Bounds.Width/2,
+		"y":             w.Bounds.Y + w.Bounds.Height/2,
+		"bounds":        bounds,
+	}
+}
+
+// findMatchingWindow 查找应用名包含 appName 且标题满足匹配模式的窗口，优先返回有尺寸的窗口；未找到时返回 nil
+func findMatchingWindow(appName, title, mode string) (*window.WindowInfo, error) {
+	filter := title
+	if filter == "" {
+		filter = appName
+	}
+	windows, err := getWindows(filter)
+	if err != nil {
+		return nil, fmt.Errorf("获取窗口列表失败: %w", err)
+	}
+	var found *window.WindowInfo
+	for i := range windows {
+		w := &windows[i]
+		if appName != "" && !strings.Contains(strings.ToLower(w.OwnerName), strings.ToLower(appName)) {
+			continue
+		}
+		if title != "" && !titleMatches(w.Title, title, mode) {
+			continue
+		}
+		if w.Bounds.Width > 0 && w.Bounds.Height > 0 {
+			return w, nil
+		}
+		if found == nil {
+			found = w
+		}
+	}
+	return found, nil
+}
+
+// parseWindowTarget 解析窗口条件：app_name（应用名包含）和 / 或 window_title（兼容 title，按 match_mode 匹配）
+func parseWindowTarget(payload map[string]interface{}) (appName, title, mode string, err error) {
+	appName, _ = payload["app_name"].(string)
+	title, _ = payload["window_title"].(string)
+	if title == "" {
+		title, _ = payload["title"].(string)
+	}
+	if appName == "" && title == "" {
+		return "", "", "", auto.Errorf(auto.ErrParam, "缺少 app_name 或 window_title 参数")
+	}
+	mode, err = parseTitleMatchMode(payload)
+	return appName, title, mode, err
+}
+
+// executeWaitForWindow 等待窗口出现：app_name（应用名包含）和 / 或 window_title（标题，按 match_mode 匹配），
+// timeout（秒）内轮询窗口列表；返回窗口的 PID、句柄、标题和边界，边界作为 TargetBounds
+func (e *Executor) executeWaitForWindow(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
+	appName, title, mode, err := parseWindowTarget(payload)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := e.parseAutoOptions(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	w, err := pollWindow(auto.ApplyOptions(opts...), appName, title, mode)
+	if err != nil {
+		return nil, err
+	}
+	data := windowData(w, result)
+	data["found"] = true
+	data["waited_ms"] = time.Since(start).Milliseconds()
+	return data, nil
+}
+
+// pollWindow 在 o.Timeout 内按轮询间隔查找窗口，超时返回 TIMEOUT
+func pollWindow(o *auto.Options, appName, title, mode string) (*window.WindowInfo, error) {
+	start := time.Now()
+	for {
+		w, err := findMatchingWindow(appName, title, mode)
+		if err != nil {
+			return nil, err
+		}
+		if w != nil {
+			return w, nil
+		}
+		if o.Timeout == 0 || time.Since(start) > o.Timeout {
+			return nil, auto.Errorf(auto.ErrTimeout, "等待窗口超时（%v）: %s", o.Timeout, describeWindow(appName, title))
+		}
+		if err := o.WaitPoll(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// describeWindow 用于错误信息的窗口描述
+func describeWindow(appName, title string) string {
+	switch {
+	case appName != "" && title != "":
+		return fmt.Sprintf("应用 %q 的窗口 %q", appName, title)
+	case appName != "":
+		return fmt.Sprintf("应用 %q 的窗口", appName)
+	default:
+		return fmt.Sprintf("窗口 %q", title)
+	}
+}
+
+// executeAssertWindowTitle 断言当前活动窗口标题：expected 按 match_mode（contains / exact，忽略大小写）匹配
+// 成功时在窗口列表中查找该窗口，返回 PID、句柄和边界（边界作为 TargetBounds）
+func (e *Executor) executeAssertWindowTitle(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
+	expected, _ := payload["expected"].(string)
+	if expected == "" {
+		return nil, auto.Errorf(auto.ErrParam, "缺少 expected 参数")
+	}
+	mode, err := parseTitleMatchMode(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	actual := activeWindowTitle()
+	if !titleMatches(actual, expected, mode) {
+		verb := "包含"
+		if mode == TitleMatchExact {
+			verb = "为"
+		}
+		return map[string]interface{}{"title": actual, "expected": expected, "match_mode": mode},
+			auto.Errorf(ErrAssertionFailed, "断言失败: 当前活动窗口标题为 %q，期望%s %q", actual, verb, expected)
+	}
+
+	data := map[string]interface{}{"title": actual}
+	if w, err := findMatchingWindow("", actual, TitleMatchExact); err != nil {
+		log("WARN", fmt.Sprintf("获取活动窗口信息失败: %v", err))
+	} else if w != nil {
+		data = windowData(w, result)
+	}
+	data["asserted"] = true
+	data["expected"] = expected
+	data["match_mode"] = mode
+	return data, nil
+}
+
+// findTargetWindow 按 payload 的窗口条件查找窗口，未找到时返回 NOT_FOUND
+func findTargetWindow(payload map[string]interface{}) (*window.WindowInfo, error) {
+	appName, title, mode, err := parseWindowTarget(payload)
+	if err != nil {
+		return nil, err
+	}
+	w, err := findMatchingWindow(appName, title, mode)
+	if err != nil {
+		return nil, err
+	}
+	if w == nil {
+		return nil, auto.Errorf(auto.ErrNotFound, "未找到%s", describeWindow(appName, title))
+	}
+	return w, nil
+}
+
+// refreshWindow 窗口操作后重新获取窗口信息（按句柄，没有句柄时按 PID 和标题），窗口不在列表中时返回 nil
+func refreshWindow(ctx context.Context, w *window.WindowInfo) *window.WindowInfo {
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-time.After(windowSettleDelay):
+	}
+	windows, err := getWindows()
+	if err != nil {
+		log("WARN", fmt.Sprintf("获取窗口列表失败: %v", err))
+		return nil
+	}
+	for i := range windows {
+		c := &windows[i]
+		if w.Handle != 0 && c.Handle == w.Handle || w.Handle == 0 && c.PID == w.PID && c.Title == w.Title {
+			return c
+		}
+	}
+	return nil
+}
+
+// windowIdentity 不含位置的窗口信息（窗口最小化或已不在窗口列表中时使用）
+func windowIdentity(w *window.WindowInfo) map[string]interface{} {
+	return map[string]interface{}{
+		"pid":           w.PID,
+		"window_handle": w.Handle,
+		"title":         w.Title,
+		"owner_name":    w.OwnerName,
+	}
+}
+
+// parseOptionalInt 解析可选的整数参数，未指定时返回 def
+func parseOptionalInt(payload map[string]interface{}, key string, def int) (int, error) {
+	raw, ok := payload[key]
+	if !ok {
+		return def, nil
+	}
+	v, ok := raw.(float64)
+	if !ok || v != float64(int(v)) {
+		return 0, auto.Errorf(auto.ErrParam, "%s 必须是整数: %v", key, raw)
+	}
+	return int(v), nil
+}
+
+// executeSetWindowBounds 移动窗口并调整大小：按 app_name / window_title 查找窗口，x / y / width / height 至少指定一个，
+// 未指定的保持不变。窗口管理器可能限制位置和尺寸，结果返回操作后的实际边界（作为 TargetBounds）和请求的边界 requested
+func (e *Executor) executeSetWindowBounds(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
+	hasBounds := false
+	for _, key := range []string{"x", "y", "width", "height"} {
+		if _, ok := payload[key]; ok {
+			hasBounds = true
+		}
+	}
+	if !hasBounds {
+		return nil, auto.Errorf(auto.ErrParam, "缺少 x、y、width、height 参数")
+	}
+	w, err := findTargetWindow(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	target := w.Bounds
+	for _, f := range []struct {
+		key string
+		dst *int
+	}{{"x", &target.X}, {"y", &target.Y}, {"width", &target.Width}, {"height", &target.Height}} {
+		if *f.dst, err = parseOptionalInt(payload, f.key, *f.dst); err != nil {
+			return nil, err
+		}
+	}
+	if target.Width <= 0 || target.Height <= 0 {
+		return nil, auto.Errorf(auto.ErrParam, "width 和 height 必须是正数: %dx%d", target.Width, target.Height)
+	}
+	if err := setWindowBounds(*w, target); err != nil {
+		return nil, fmt.Errorf("调整窗口失败（%s）: %w", w.Title, err)
+	}
+
+	var data map[string]interface{}
+	if actual := refreshWindow(ctx, w); actual != nil {
+		data = windowData(actual, result)
+		data["adjusted"] = actual.Bounds != target
+	} else {
+		data = windowIdentity(w)
+	}
+	data["requested"] = &BoundsInfo{X: target.X, Y: target.Y, Width: target.Width, Height: target.Height}
+	return data, nil
+}
+
+// executeMinimizeWindow 最小化 app_name / window_title 匹配的窗口
+func (e *Executor) executeMinimizeWindow(ctx context.Context, payload map[string]interface{}) (interface{}, error) {
+	w, err := findTargetWindow(payload)
+	if err != nil {
+		return nil, err
+	}
+	minimizeWindow(w.PID)
+
+	data := windowIdentity(w)
+	data["minimized"] = true
+	return data, nil
+}
+
+// executeMaximizeWindow 最大化 app_name / window_title 匹配的窗口，结果返回最大化后的实际边界（作为 TargetBounds）
+func (e *Executor) executeMaximizeWindow(ctx context.Context, payload map[string]interface{}, result *ActionResult) (interface{}, error) {
+	w, err := findTargetWindow(payload)
+	if err != nil {
+		return nil, err
+	}
+	maximizeWindow(w.PID)
+
+	data := windowIdentity(w)
+	if actual := refreshWindow(ctx, w); actual != nil {
+		data = windowData(actual, result)
+	}
+	data["maximized"] = true
+	return data, nil
+}
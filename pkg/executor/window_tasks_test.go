@@ -0,0 +1,153 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/window"
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+)
+
+func TestWaitForWindow(t *testing.T) {
+	origGetWindows := getWindows
+	t.Cleanup(func() { getWindows = origGetWindows })
+	// 第 3 次查询时设置窗口才出现
+	var calls atomic.Int32
+	getWindows = func(filter ...string) ([]window.WindowInfo, error) {
+		windows := []window.WindowInfo{{PID: 10, Title: "MyApp", OwnerName: "MyApp", Bounds: auto.Region{Width: 800, Height: 600}}}
+		if calls.Add(1) >= 3 {
+			windows = append(windows,
+				window.WindowInfo{PID: 11, Title: "Settings — MyApp", OwnerName: "MyApp"},
+				window.WindowInfo{PID: 12, Handle: 0x42, Title: "Settings — MyApp", OwnerName: "MyApp", Bounds: auto.Region{X: 100, Y: 50, Width: 400, Height: 300}})
+		}
+		return windows, nil
+	}
+	e, recorder := newTestExecutor()
+
+	e.Execute("wait", TaskTypeWaitForWindow, `{"app_name": "myapp", "window_title": "settings", "timeout": 2, "interval_ms": 10}`)
+	res := recorder.results("wait")
+	if len(res) != 1 || !res[0].Success {
+		t.Fatalf("窗口出现后应成功: %+v", res)
+	}
+	if loc := res[0].MatchLocation; loc == nil || loc.X != 100 || loc.Y != 50 || loc.Width != 400 || loc.Height != 300 {
+		t.Errorf("MatchLocation 应为有尺寸窗口的边界: %+v", loc)
+	}
+	if !strings.Contains(res[0].ResultJson, `"pid":12`) || !strings.Contains(res[0].ResultJson, `"window_handle":66`) {
+		t.Errorf("结果应包含窗口 PID 和句柄: %s", res[0].ResultJson)
+	}
+
+	step := e.executeStepWithScreenshots(context.Background(), "", "s1", TaskTypeWaitForWindow,
+		decodePayload(t, `{"title": "Settings — MyApp", "match_mode": "exact", "timeout": 0}`), screenshotOptions{Mode: ScreenshotModeNever}, nil)
+	if step.Status != "SUCCESS" || step.ActionType != "wait" || step.TargetBounds == nil || step.TargetBounds.X != 100 {
+		t.Errorf("窗口边界应写入 TargetBounds: %+v", step)
+	}
+
+	e.Execute("timeout", TaskTypeWaitForWindow, `{"window_title": "Preferences", "timeout": 0.05, "interval_ms": 10}`)
+	if res := recorder.results("timeout"); len(res) != 1 || res[0].Status != pb.TaskStatus_TASK_STATUS_TIMEOUT {
+		t.Errorf("未出现时应超时: %+v", res)
+	}
+	for _, payload := range []string{`{}`, `{"app_name": "MyApp", "match_mode": "regex"}`} {
+		if _, err := e.runAction(context.Background(), TaskTypeWaitForWindow, decodePayload(t, payload)); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("%s 应返回 ErrParam: %v", payload, err)
+		}
+	}
+}
+
+func TestAssertWindowTitle(t *testing.T) {
+	origGetWindows, origActive := getWindows, activeWindowTitle
+	t.Cleanup(func() { getWindows, activeWindowTitle = origGetWindows, origActive })
+	getWindows = func(filter ...string) ([]window.WindowInfo, error) {
+		return []window.WindowInfo{{PID: 12, Title: "Settings — MyApp", OwnerName: "MyApp", Bounds: auto.Region{X: 100, Y: 50, Width: 400, Height: 300}}}, nil
+	}
+	activeWindowTitle = func() string { return "Settings — MyApp" }
+	e, recorder := newTestExecutor()
+
+	for _, payload := range []string{
+		`{"expected": "settings"}`,
+		`{"expected": " settings — myapp ", "match_mode": "exact"}`,
+	} {
+		res, err := e.runAction(context.Background(), TaskTypeAssertWindowTitle, decodePayload(t, payload))
+		if err != nil || res.TargetBounds == nil || res.TargetBounds.Width != 400 || res.Data.(map[string]interface{})["pid"] != 12 {
+			t.Errorf("%s 应断言成功并返回窗口信息: %+v %v", payload, res, err)
+		}
+	}
+
+	e.Execute("mismatch", TaskTypeAssertWindowTitle, `{"expected": "Settings", "match_mode": "exact"}`)
+	res := recorder.results("mismatch")
+	if len(res) != 1 || res[0].FailureReason != pb.FailureReason_FAILURE_REASON_ASSERTION_FAILED || !strings.Contains(res[0].Message, "Settings — MyApp") {
+		t.Errorf("标题不符应为 ASSERTION_FAILED 并给出实际标题: %+v", res)
+	}
+	if _, err := e.runAction(context.Background(), TaskTypeAssertWindowTitle, decodePayload(t, `{}`)); !errors.Is(err, auto.ErrParam) {
+		t.Errorf("缺少 expected 应返回 ErrParam: %v", err)
+	}
+}
+
+func TestWindowManagement(t *testing.T) {
+	origGetWindows, origSet, origMin, origMax, origDelay := getWindows, setWindowBounds, minimizeWindow, maximizeWindow, windowSettleDelay
+	t.Cleanup(func() {
+		getWindows, setWindowBounds, minimizeWindow, maximizeWindow, windowSettleDelay = origGetWindows, origSet, origMin, origMax, origDelay
+	})
+	windowSettleDelay = 0
+	// 模拟窗口管理器：宽度最大 1920，最大化后占满屏幕
+	current := auto.Region{X: 100, Y: 50, Width: 800, Height: 600}
+	getWindows = func(filter ...string) ([]window.WindowInfo, error) {
+		return []window.WindowInfo{{PID: 12, Handle: 0x42, Title: "Settings — MyApp", OwnerName: "MyApp", Bounds: current}}, nil
+	}
+	var requested []auto.Region
+	setWindowBounds = func(w window.WindowInfo, bounds auto.Region) error {
+		requested = append(requested, bounds)
+		current = bounds
+		current.Width = min(current.Width, 1920)
+		return nil
+	}
+	var minimized, maximized []int
+	minimizeWindow = func(pid int) { minimized = append(minimized, pid) }
+	maximizeWindow = func(pid int) {
+		maximized = append(maximized, pid)
+		current = auto.Region{Width: 1920, Height: 1080}
+	}
+	e, recorder := newTestExecutor()
+	ctx := context.Background()
+
+	res, err := e.runAction(ctx, TaskTypeSetWindowBounds, decodePayload(t, `{"app_name": "myapp", "width": 1024, "height": 768}`))
+	if err != nil || requested[0] != (auto.Region{X: 100, Y: 50, Width: 1024, Height: 768}) {
+		t.Fatalf("未指定的 x / y 应保持不变: %v %v", requested, err)
+	}
+	if data := res.Data.(map[string]interface{}); data["adjusted"] != false || res.TargetBounds == nil || res.TargetBounds.Width != 1024 {
+		t.Errorf("应返回实际边界: %+v %+v", res.Data, res.TargetBounds)
+	}
+
+	e.Execute("clamped", TaskTypeSetWindowBounds, `{"window_title": "settings", "x": 0, "y": 0, "width": 2560, "height": 1440}`)
+	results := recorder.results("clamped")
+	if len(results) != 1 || !results[0].Success || !strings.Contains(results[0].ResultJson, `"adjusted":true`) ||
+		!strings.Contains(results[0].ResultJson, `"bounds":{"x":0,"y":0,"width":1920,"height":1440}`) {
+		t.Fatalf("窗口管理器限制尺寸时应返回实际边界: %+v", results)
+	}
+
+	if _, err := e.runAction(ctx, TaskTypeMinimizeWindow, decodePayload(t, `{"app_name": "MyApp"}`)); err != nil || len(minimized) != 1 || minimized[0] != 12 {
+		t.Errorf("应最小化匹配的窗口: %v %v", minimized, err)
+	}
+	res, err = e.runAction(ctx, TaskTypeMaximizeWindow, decodePayload(t, `{"app_name": "MyApp"}`))
+	if err != nil || len(maximized) != 1 || res.TargetBounds == nil || res.TargetBounds.Width != 1920 || res.TargetBounds.Height != 1080 {
+		t.Errorf("最大化后应返回实际边界: %+v %v", res, err)
+	}
+
+	for _, taskType := range []string{TaskTypeSetWindowBounds, TaskTypeMinimizeWindow, TaskTypeMaximizeWindow} {
+		e.Execute("missing-"+taskType, taskType, `{"window_title": "Preferences", "x": 0}`)
+		if results := recorder.results("missing-" + taskType); len(results) != 1 || results[0].FailureReason != pb.FailureReason_FAILURE_REASON_NOT_FOUND {
+			t.Errorf("%s 找不到窗口应为 NOT_FOUND: %+v", taskType, results)
+		}
+	}
+	for _, payload := range []string{`{"app_name": "MyApp"}`, `{"x": 0}`, `{"app_name": "MyApp", "x": 1.5}`, `{"app_name": "MyApp", "width": 0}`} {
+		if _, err := e.runAction(ctx, TaskTypeSetWindowBounds, decodePayload(t, payload)); !errors.Is(err, auto.ErrParam) {
+			t.Errorf("%s 应返回 ErrParam: %v", payload, err)
+		}
+	}
+	if len(requested) != 2 {
+		t.Errorf("参数错误或找不到窗口时不应调整窗口: %v", requested)
+	}
+}
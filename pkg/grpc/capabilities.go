@@ -0,0 +1,108 @@
+package grpc
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+	"github.com/zoeyai/zoeyworker/pkg/permissions"
+	"github.com/zoeyai/zoeyworker/pkg/plugin"
+	"github.com/zoeyai/zoeyworker/pkg/uia"
+	"github.com/zoeyai/zoeyworker/pkg/vision/ocr"
+)
+
+// 运行时能力检测函数，测试时替换
+var (
+	ocrPluginStatus  = func() plugin.OCRPluginStatus { return plugin.GetOCRPlugin().GetStatus() }
+	uiaSupported     = uia.IsSupported
+	listDisplays     = screen.GetDisplays
+	checkPermissions = permissions.CheckPermissions
+)
+
+// detectRuntimeCapabilities 检测运行期间可能变化的能力（OCR 插件安装、显示器插拔、macOS 授权等），每次调用重新检测
+func detectRuntimeCapabilities(caps *Capabilities) {
+	ocrStatus := ocr.GetProviderStatus()
+	caps.OCRProvider = string(ocrStatus.Requested)
+	caps.OCRActiveProvider = string(ocrStatus.Active)
+
+	if ocrPluginStatus().Installed {
+		caps.OCRInstalled = true
+		caps.OCRModelVersion = plugin.OCRModelVersion
+	}
+	caps.UIAAvailable = uiaSupported()
+
+	// 使用主显示器的区域和缩放比例（GetScreenSize 首次调用会截图，不适合每次心跳检测）
+	displays := safeListDisplays()
+	caps.DisplayCount = len(displays)
+	for _, d := range displays {
+		if d.Primary {
+			caps.ScreenWidth = d.Bounds.Width
+			caps.ScreenHeight = d.Bounds.Height
+			caps.ScaleFactor = d.ScaleFactor
+			break
+		}
+	}
+
+	// 只有 macOS 需要授权，其他系统不上报
+	if runtime.GOOS == "darwin" {
+		caps.Permissions = checkPermissions()
+	}
+}
+
+// safeListDisplays 获取显示器列表，无桌面会话时底层调用可能 panic，视为没有显示器
+func safeListDisplays() (displays []screen.DisplayInfo) {
+	defer func() {
+		if r := recover(); r != nil {
+			displays = nil
+		}
+	}()
+	return listDisplays()
+}
+
+// toWsCapabilities 转换为连接消息和心跳中的能力信息
+func toWsCapabilities(caps *Capabilities) *WsCapabilities {
+	ws := &WsCapabilities{
+		PythonAvailable:   caps.PythonAvailable,
+		PythonVersion:     caps.PythonVersion,
+		PythonPath:        caps.PythonPath,
+		OcrProvider:       caps.OCRProvider,
+		OcrActiveProvider: caps.OCRActiveProvider,
+		OcrInstalled:      caps.OCRInstalled,
+		OcrModelVersion:   caps.OCRModelVersion,
+		UiaAvailable:      caps.UIAAvailable,
+		DisplayCount:      caps.DisplayCount,
+		ScreenWidth:       caps.ScreenWidth,
+		ScreenHeight:      caps.ScreenHeight,
+		ScaleFactor:       caps.ScaleFactor,
+	}
+	if caps.Permissions != nil {
+		ws.Permissions = &WsPermissions{
+			Accessibility:   caps.Permissions.Accessibility,
+			ScreenRecording: caps.Permissions.ScreenRecording,
+		}
+	}
+	return ws
+}
+
+// capabilitiesChanged 检测能力信息，与上次上报（连接消息或心跳）不同时返回新的能力信息，否则返回 nil
+func (c *Client) capabilitiesChanged() *WsCapabilities {
+	current := toWsCapabilities(GetSystemInfo().Capabilities)
+
+	c.mu.Lock()
+	previous := c.reportedCaps
+	changed := !reflect.DeepEqual(previous, current)
+	if changed {
+		c.reportedCaps = current
+	}
+	c.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	if previous != nil {
+		c.log("INFO", fmt.Sprintf("Capabilities changed, reporting in heartbeat: ocrInstalled=%v uiaAvailable=%v displays=%d",
+			current.OcrInstalled, current.UiaAvailable, current.DisplayCount))
+	}
+	return current
+}
@@ -0,0 +1,66 @@
+package grpc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+	"github.com/zoeyai/zoeyworker/pkg/plugin"
+)
+
+// fakeCapabilities 替换运行时能力检测，返回修改 OCR 安装状态的函数
+func fakeCapabilities(t *testing.T) (setOCR func(bool)) {
+	origOCR, origUIA, origDisplays := ocrPluginStatus, uiaSupported, listDisplays
+	t.Cleanup(func() { ocrPluginStatus, uiaSupported, listDisplays = origOCR, origUIA, origDisplays })
+
+	installed := false
+	ocrPluginStatus = func() plugin.OCRPluginStatus { return plugin.OCRPluginStatus{Installed: installed} }
+	uiaSupported = func() bool { return true }
+	listDisplays = func() []screen.DisplayInfo {
+		return []screen.DisplayInfo{
+			{Index: 0, Bounds: auto.Region{X: -1920, Width: 1920, Height: 1080}, ScaleFactor: 1},
+			{Index: 1, Bounds: auto.Region{Width: 2880, Height: 1800}, ScaleFactor: 2, Primary: true},
+		}
+	}
+	return func(v bool) { installed = v }
+}
+
+func TestCapabilitiesReportedOnChange(t *testing.T) {
+	setOCR := fakeCapabilities(t)
+	server, _ := newAgentServer(t, readUntilClosed)
+	client := NewClient(&ClientConfig{HeartbeatInterval: 60})
+	if err := client.Connect(strings.TrimPrefix(server.URL, "http://"), "ak", "sk"); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect()
+
+	// 连接消息已包含能力信息，未变化时心跳不重复上报
+	if caps := client.capabilitiesChanged(); caps != nil {
+		t.Errorf("能力未变化时不应上报: %+v", caps)
+	}
+
+	// 运行期间安装 OCR 插件：下一次心跳上报完整能力信息
+	setOCR(true)
+	caps := client.capabilitiesChanged()
+	if caps == nil || !caps.OcrInstalled || caps.OcrModelVersion != plugin.OCRModelVersion {
+		t.Fatalf("OCR 安装后应上报, 实际 %+v", caps)
+	}
+	if !caps.UiaAvailable || caps.DisplayCount != 2 || caps.ScreenWidth != 2880 || caps.ScreenHeight != 1800 || caps.ScaleFactor != 2 {
+		t.Errorf("应上报 UIA 和主显示器信息, 实际 %+v", caps)
+	}
+	if caps := client.capabilitiesChanged(); caps != nil {
+		t.Error("已上报的变化不应重复上报")
+	}
+}
+
+func TestDetectCapabilitiesWithoutDisplay(t *testing.T) {
+	fakeCapabilities(t)
+	listDisplays = func() []screen.DisplayInfo { panic("no display") }
+
+	var caps Capabilities
+	detectRuntimeCapabilities(&caps)
+	if caps.DisplayCount != 0 || caps.ScreenWidth != 0 || caps.OCRInstalled || caps.OCRModelVersion != "" {
+		t.Errorf("无显示器且未安装 OCR 时应为空, 实际 %+v", caps)
+	}
+}
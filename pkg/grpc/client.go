@@ -1,37 +1,96 @@
 package grpc
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/url"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/zoeyai/zoeyworker/internal/logger"
 	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
 )
 
 // Client WebSocket 客户端
 type Client struct {
 	config *ClientConfig
-	conn   *websocket.Conn
 
-	agentID     string
-	agentName   string
-	isConnected bool
-
-	outgoing chan *WsWorkerMessage
-	stopCh   chan struct{}
-	stopOnce sync.Once
-	wg       sync.WaitGroup
+	agentID   string
+	agentName string
+	// clientID 持久化的客户端标识，服务端可在认证响应中指定
+	clientID string
+
+	// state 连接状态机（持有 mu 时修改）：
+	//   disconnected → connecting → connected → stopping（Disconnect）→ disconnected
+	//   connected → reconnecting（连接异常断开）→ connecting → ...
+	state ClientStatus
+	// session 当前已认证的连接，未连接时为 nil
+	session *session
+	// attemptSeq 连接尝试序号，只有最新的尝试可以修改 connecting 状态
+	attemptSeq uint64
+	// disconnectCh 手动断开信号：Disconnect 关闭当前通道并换新，进行中的连接和重连随之放弃
+	disconnectCh chan struct{}
+
+	// outbox 发送队列，重连时复用，未发送的任务消息在重连后优先发送
+	outbox *outbox
+	// results 已发送但服务端尚未确认的任务结果，重连时复用
+	results resultTracker
+	// resources 最近一次资源采样结果，随心跳上报
+	resources resourceSampler
+	// plugins 服务端触发的插件安装，进度随心跳上报
+	plugins pluginInstalls
+	// clock 根据服务端 Ping 估计的时钟偏差，随心跳上报
+	clock clockSkew
+	// resultSeq 任务结果 messageId 序号，保证重发时服务端可按 messageId 去重
+	resultSeq atomic.Uint64
+
+	// startedAt 客户端创建时间；reconnects 重连成功次数；lastHeartbeat 最近一次发送心跳的时间（毫秒时间戳），见 Stats
+	startedAt     time.Time
+	reconnects    atomic.Int64
+	lastHeartbeat atomic.Int64
 
 	onStatusChange   StatusCallback
 	onTask           TaskCallback
 	onCancel         CancelCallback
 	onExecutorStatus ExecutorStatusCallback
+	onSettings       SettingsCallback
+
+	// heartbeatReset 心跳间隔修改后通知心跳循环重新计时（容量 1，不阻塞）
+	heartbeatReset chan struct{}
+
+	// screenProbe 检查能否截图，结果随心跳上报；screenErr 为上一次的检查结果（用于只在变化时输出日志）
+	screenProbe func() error
+	screenErr   *string
+
+	// screenLockProbe 检查屏幕是否已锁定，结果随心跳上报；screenLocked 为上一次的检查结果
+	screenLockProbe func() bool
+	screenLocked    bool
+
+	// displayProbe 查询主显示器分辨率，结果随心跳上报
+	displayProbe func() (width, height int, scaleFactor float64)
+
+	// stream 进行中的屏幕流，没有时为 nil
+	stream *screenStream
+
+	// reportedCaps 最近一次上报的能力信息，变化时随下一次心跳上报
+	reportedCaps *WsCapabilities
+
+	// tlsChanged 上次连接后 UpdateTLS 更新了 TLS 选项，下一次 UpdateCredentials 需重新连接
+	tlsChanged bool
 
-	logs   []LogEntry
-	logsMu sync.Mutex
+	// logs 最近 maxLogEntries 条日志（序号连续），logSubs 日志订阅（SubscribeLogs），均由 logsMu 保护
+	logs      []LogEntry
+	logSeq    int64
+	logSubs   map[int]func(LogEntry)
+	nextSubID int
+	logsMu    sync.Mutex
 
 	mu sync.RWMutex
 }
@@ -42,10 +101,28 @@ func NewClient(config *ClientConfig) *Client {
 		config = DefaultConfig()
 	}
 	c := &Client{
-		config:   config,
-		outgoing: make(chan *WsWorkerMessage, 100),
-		stopCh:   make(chan struct{}),
-		logs:     make([]LogEntry, 0, 500),
+		config:         config,
+		state:          StatusDisconnected,
+		disconnectCh:   make(chan struct{}),
+		heartbeatReset: make(chan struct{}, 1),
+		logs:           make([]LogEntry, 0, maxLogEntries),
+		startedAt:      time.Now(),
+	}
+
+	c.clock.correct.Store(config.CorrectClockSkew)
+
+	id, err := loadClientID(config.DataDir)
+	c.clientID = id
+	if err != nil {
+		c.log("WARN", fmt.Sprintf("Client ID will change after restart: %v", err))
+	}
+
+	box, err := newOutbox(config.OutboxDir)
+	c.outbox = box
+	if err != nil {
+		c.log("WARN", fmt.Sprintf("Failed to restore outbox: %v", err))
+	} else if queued := box.snapshot().Queued; queued > 0 {
+		c.log("INFO", fmt.Sprintf("%d task messages from the previous run will be sent after connecting", queued))
 	}
 
 	// 设置全局日志函数，让 data_handler 也能输出日志
@@ -56,15 +133,162 @@ func NewClient(config *ClientConfig) *Client {
 	return c
 }
 
-// Connect 连接到服务端
+// session 一次已认证的连接及其收发协程；重连时创建新的 session，旧协程只引用自己的 session
+type session struct {
+	conn     *websocket.Conn
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+	// lostOnce 发送和接收都可能发现连接断开，只触发一次重连
+	lostOnce sync.Once
+	// resultAck 服务端支持任务结果确认
+	resultAck bool
+	// frames 屏幕流的帧槽位（容量 1），发送队列为空时才发送，不延迟任务消息
+	frames chan *WsWorkerMessage
+	// inputs 远程输入事件队列，由 inputLoop 按顺序执行
+	inputs chan inputRequest
+}
+
+// stopped 会话是否已停止
+func (s *session) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// close 停止会话：关闭连接使阻塞的读写立即返回，并等待所有协程退出（不能在会话协程中调用）
+func (s *session) close() {
+	s.stopOnce.Do(func() { close(s.stop) })
+	s.conn.Close()
+	s.wg.Wait()
+}
+
+// Connect 连接到服务端，连接过程中调用 Disconnect 时返回 ErrDisconnected
 func (c *Client) Connect(serverURL, accessKey, secretKey string) error {
 	c.mu.Lock()
 	c.config.ServerURL = serverURL
 	c.config.AccessKey = accessKey
 	c.config.SecretKey = secretKey
+	ctx, cancel := connectContext(context.Background(), c.disconnectCh)
+	c.mu.Unlock()
+	defer cancel(nil)
+
+	return c.doConnect(ctx)
+}
+
+// ErrAuthRejected 服务端拒绝认证（密钥错误、Agent 被禁用等），重试无意义
+var ErrAuthRejected = errors.New("认证被拒绝")
+
+// ErrDisconnected 连接或重连过程中调用了 Disconnect，已放弃连接
+var ErrDisconnected = errors.New("连接已手动断开")
+
+// connectContext 返回在 parent 取消或 abort 关闭（手动 Disconnect）时取消的 context，后者的 context.Cause 为 ErrDisconnected
+// abort 为调用方持有 mu 时读取的 disconnectCh，保证之后的 Disconnect 一定能取消这次连接
+func connectContext(parent context.Context, abort <-chan struct{}) (context.Context, context.CancelCauseFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	go func() {
+		select {
+		case <-abort:
+			cancel(ErrDisconnected)
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// ConnectWithRetry 连接到服务端，失败时按 ReconnectDelays 依次等待后重试，用完后按最后一个延迟持续重试，
+// 直到连接成功、认证被拒绝（ErrAuthRejected）、ctx 取消或调用 Disconnect（适合开机早于网络就绪的无人值守场景）
+func (c *Client) ConnectWithRetry(ctx context.Context, serverURL, accessKey, secretKey string) error {
+	c.mu.Lock()
+	c.config.ServerURL = serverURL
+	c.config.AccessKey = accessKey
+	c.config.SecretKey = secretKey
+	delays := c.config.ReconnectDelays
+	ctx, cancel := connectContext(ctx, c.disconnectCh)
+	c.mu.Unlock()
+	defer cancel(nil)
+
+	for attempt := 1; ; attempt++ {
+		err := c.doConnect(ctx)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, ErrAuthRejected) || errors.Is(err, context.Cause(ctx)) {
+			// 重试等待结束时 ctx 恰好取消，doConnect 未修改状态直接返回
+			c.stopReconnecting()
+			return err
+		}
+
+		delay := 5
+		if len(delays) > 0 {
+			delay = delays[min(attempt-1, len(delays)-1)]
+		}
+		c.log("WARN", fmt.Sprintf("Connect attempt %d failed, retrying in %ds...", attempt, delay))
+		if !c.waitRetry(ctx, time.Duration(delay)*time.Second) {
+			if cause := context.Cause(ctx); cause != nil {
+				return fmt.Errorf("%w（最后一次错误: %v）", cause, err)
+			}
+			return err
+		}
+	}
+}
+
+// waitRetry 以 reconnecting 状态等待 delay 后重试；ctx 取消（包括手动 Disconnect）或其他连接已开始时返回 false
+func (c *Client) waitRetry(ctx context.Context, delay time.Duration) bool {
+	c.mu.Lock()
+	if ctx.Err() != nil || (c.state != StatusDisconnected && c.state != StatusReconnecting) {
+		c.mu.Unlock()
+		return false
+	}
+	c.state = StatusReconnecting
 	c.mu.Unlock()
+	c.setStatus(StatusReconnecting)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		c.stopReconnecting()
+		return false
+	}
+}
 
-	return c.doConnect()
+// stopReconnecting 重连放弃或取消后从 reconnecting 回到 disconnected（Disconnect 已处理时不重复）
+func (c *Client) stopReconnecting() {
+	c.mu.Lock()
+	reconnecting := c.state == StatusReconnecting
+	if reconnecting {
+		c.state = StatusDisconnected
+	}
+	c.mu.Unlock()
+	if reconnecting {
+		c.setStatus(StatusDisconnected)
+	}
+}
+
+// ProbeServer 测试 config.ServerURL 是否可达（使用 config 的 TLS 选项）：只完成 WebSocket 握手后立即断开，不发送认证消息
+// 返回实际连接的 WebSocket 地址（便于排查地址转换问题）
+func ProbeServer(ctx context.Context, config *ClientConfig) (string, error) {
+	wsURL := buildWsURL(config.ServerURL)
+	tlsConfig, err := config.TLSConfig()
+	if err != nil {
+		return wsURL, fmt.Errorf("%w: %w", ErrTLS, err)
+	}
+	dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second, TLSClientConfig: tlsConfig}
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		if isTLSError(err) {
+			return wsURL, fmt.Errorf("%w: 连接 %s 时握手失败: %w", ErrTLS, wsURL, err)
+		}
+		return wsURL, fmt.Errorf("连接 %s 失败: %w", wsURL, err)
+	}
+	conn.Close()
+	return wsURL, nil
 }
 
 // buildWsURL 根据 serverURL 构建 WebSocket URL
@@ -120,35 +344,76 @@ func isLocalAddress(addr string) bool {
 	return host == "localhost" || host == "127.0.0.1" || host == "0.0.0.0" || host == "::1"
 }
 
-// doConnect 执行连接
-func (c *Client) doConnect() error {
+// doConnect 执行一次连接尝试（从 disconnected 或 reconnecting 状态开始），ctx 取消时放弃并返回 context.Cause(ctx)
+func (c *Client) doConnect(ctx context.Context) error {
 	c.mu.Lock()
+	if err := context.Cause(ctx); err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	if c.state != StatusDisconnected && c.state != StatusReconnecting {
+		state := c.state
+		c.mu.Unlock()
+		return fmt.Errorf("客户端状态为 %s，不能重复连接", state)
+	}
+	c.state = StatusConnecting
+	c.attemptSeq++
+	attempt := c.attemptSeq
 	serverURL := c.config.ServerURL
 	accessKey := c.config.AccessKey
 	secretKey := c.config.SecretKey
+	clientID := c.clientID
+	tlsConfig, tlsErr := c.config.TLSConfig()
+	pingInterval, readTimeout := c.config.keepalive()
+	ackTimeout := c.config.resultAckTimeout()
+	c.tlsChanged = false
 	c.mu.Unlock()
 
 	wsURL := buildWsURL(serverURL)
-	c.log("INFO", fmt.Sprintf("Connecting to %s...", wsURL))
 	c.setStatus(StatusConnecting)
+	if tlsErr != nil {
+		c.log("ERROR", fmt.Sprintf("Invalid TLS settings: %v", tlsErr))
+		c.connectFailed(attempt)
+		return fmt.Errorf("%w: %w", ErrTLS, tlsErr)
+	}
+	if tlsConfig != nil && tlsConfig.InsecureSkipVerify {
+		c.log("WARN", "TLS certificate verification is disabled (insecure_skip_verify)")
+	}
+	c.log("INFO", fmt.Sprintf("Connecting to %s...", wsURL))
 
 	// 创建 WebSocket 连接
 	dialer := websocket.Dialer{
 		HandshakeTimeout: 10 * time.Second,
 		WriteBufferSize:  1024 * 1024,
 		ReadBufferSize:   1024 * 1024,
+		TLSClientConfig:  tlsConfig,
 	}
 
-	conn, _, err := dialer.Dial(wsURL, nil)
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
 	if err != nil {
+		c.connectFailed(attempt)
+		if cause := context.Cause(ctx); cause != nil {
+			return cause
+		}
+		if isTLSError(err) {
+			c.log("ERROR", fmt.Sprintf("TLS handshake failed: %v", err))
+			return fmt.Errorf("%w: 握手失败，请检查 CA 证书或客户端证书配置: %w", ErrTLS, err)
+		}
 		c.log("ERROR", fmt.Sprintf("WebSocket connection failed: %v", err))
-		c.setStatus(StatusDisconnected)
 		return fmt.Errorf("连接失败: %w", err)
 	}
 
-	c.mu.Lock()
-	c.conn = conn
-	c.mu.Unlock()
+	// 认证期间手动断开时关闭连接，使阻塞的读取立即返回
+	stopAbort := context.AfterFunc(ctx, func() { conn.Close() })
+	fail := func(err error) error {
+		stopAbort()
+		conn.Close()
+		c.connectFailed(attempt)
+		if cause := context.Cause(ctx); cause != nil {
+			return cause
+		}
+		return err
+	}
 
 	// 发送认证消息
 	sysInfo := GetSystemInfo()
@@ -156,6 +421,8 @@ func (c *Client) doConnect() error {
 		Type:      "connect",
 		AccessKey: accessKey,
 		SecretKey: secretKey,
+		Features:  clientFeatures,
+		ClientId:  clientID,
 		SystemInfo: &WsSystemInfo{
 			Hostname:     sysInfo.Hostname,
 			Platform:     sysInfo.Platform,
@@ -165,26 +432,18 @@ func (c *Client) doConnect() error {
 		},
 	}
 	if sysInfo.Capabilities != nil {
-		connectMsg.SystemInfo.Capabilities = &WsCapabilities{
-			PythonAvailable: sysInfo.Capabilities.PythonAvailable,
-			PythonVersion:   sysInfo.Capabilities.PythonVersion,
-			PythonPath:      sysInfo.Capabilities.PythonPath,
-		}
+		connectMsg.SystemInfo.Capabilities = toWsCapabilities(sysInfo.Capabilities)
 	}
 
 	data, err := json.Marshal(connectMsg)
 	if err != nil {
 		c.log("ERROR", fmt.Sprintf("Failed to marshal connect message: %v", err))
-		conn.Close()
-		c.setStatus(StatusDisconnected)
-		return fmt.Errorf("序列化认证消息失败: %w", err)
+		return fail(fmt.Errorf("序列化认证消息失败: %w", err))
 	}
 
 	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
 		c.log("ERROR", fmt.Sprintf("Failed to send connect message: %v", err))
-		conn.Close()
-		c.setStatus(StatusDisconnected)
-		return fmt.Errorf("发送认证消息失败: %w", err)
+		return fail(fmt.Errorf("发送认证消息失败: %w", err))
 	}
 
 	// 等待认证响应
@@ -194,151 +453,221 @@ func (c *Client) doConnect() error {
 
 	if err != nil {
 		c.log("ERROR", fmt.Sprintf("Failed to read connect response: %v", err))
-		conn.Close()
-		c.setStatus(StatusDisconnected)
-		return fmt.Errorf("读取认证响应失败: %w", err)
+		return fail(fmt.Errorf("读取认证响应失败: %w", err))
 	}
 
 	var resp WsConnectResponse
 	if err := json.Unmarshal(respData, &resp); err != nil {
 		c.log("ERROR", fmt.Sprintf("Failed to parse connect response: %v", err))
-		conn.Close()
-		c.setStatus(StatusDisconnected)
-		return fmt.Errorf("解析认证响应失败: %w", err)
+		return fail(fmt.Errorf("解析认证响应失败: %w", err))
 	}
 
 	if !resp.Success {
 		c.log("ERROR", fmt.Sprintf("Connect rejected: %s", resp.Message))
-		conn.Close()
-		c.setStatus(StatusDisconnected)
-		return fmt.Errorf("认证被拒绝: %s", resp.Message)
+		return fail(fmt.Errorf("%w: %s", ErrAuthRejected, resp.Message))
+	}
+	if !stopAbort() {
+		return fail(ErrDisconnected)
+	}
+
+	// 服务端进程崩溃或 NAT 超时时 TCP 连接不会关闭，依靠读超时发现：收到 pong 或任何消息时延长
+	if readTimeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+		conn.SetPongHandler(func(string) error {
+			c.clock.pongReceived()
+			return conn.SetReadDeadline(time.Now().Add(readTimeout))
+		})
+	}
+
+	// 协程计数在会话发布前登记，之后的 Disconnect 一定会等待它们退出
+	s := &session{
+		conn:      conn,
+		stop:      make(chan struct{}),
+		resultAck: slices.Contains(resp.Features, FeatureResultAck),
+		frames:    make(chan *WsWorkerMessage, 1),
+		inputs:    make(chan inputRequest, inputQueueSize),
+	}
+	s.wg.Add(5)
+	if pingInterval > 0 {
+		s.wg.Add(1)
+	}
+	if s.resultAck {
+		s.wg.Add(1)
 	}
 
 	c.mu.Lock()
+	if ctx.Err() != nil || c.attemptSeq != attempt || c.state != StatusConnecting {
+		c.mu.Unlock()
+		return fail(ErrDisconnected)
+	}
 	c.agentID = resp.AgentId
 	c.agentName = resp.AgentName
-	c.isConnected = true
-	c.stopCh = make(chan struct{})
-	c.stopOnce = sync.Once{}
-	// 复用 outgoing channel，避免重连时丢失未发送的任务结果
-	if c.outgoing == nil {
-		c.outgoing = make(chan *WsWorkerMessage, 100)
-	}
-	c.wg = sync.WaitGroup{} // 重置 WaitGroup，避免旧的计数器干扰
+	c.reportedCaps = connectMsg.SystemInfo.Capabilities
+	c.state = StatusConnected
+	c.session = s
 	c.mu.Unlock()
 
-	c.log("INFO", fmt.Sprintf("Connected as %s (%s)", c.agentName, c.agentID))
+	c.adoptClientID(resp.ClientId)
+	c.clock.reset()
+	c.log("INFO", fmt.Sprintf("Connected as %s (%s)", resp.AgentName, resp.AgentId))
 	c.setStatus(StatusConnected)
 
-	// 启动消息循环
-	c.wg.Add(2)
-	go c.sendLoop()
-	go c.receiveLoop()
-
-	// 启动心跳
-	c.wg.Add(1)
-	go c.heartbeatLoop()
+	// 断开期间积压的心跳等已过时，积压的任务消息在新消息之前发送；
+	// 上次连接未确认的任务结果可能随断开的连接丢失，排在最前面重发
+	c.outbox.dropTransient()
+	c.outbox.requeue(c.results.takeAll())
+
+	// 启动消息循环和心跳
+	go c.sendLoop(s)
+	go c.receiveLoop(s, readTimeout)
+	go c.heartbeatLoop(s)
+	go c.resourceLoop(s)
+	go c.inputLoop(s)
+	if pingInterval > 0 {
+		go c.pingLoop(s, pingInterval)
+	}
+	if s.resultAck {
+		go c.resultAckLoop(s, ackTimeout)
+	}
 
 	return nil
 }
 
-// sendLoop 发送消息循环
-func (c *Client) sendLoop() {
-	defer c.wg.Done()
+// connectFailed 连接尝试失败后回到 disconnected（已被 Disconnect 或更新的尝试接管时不修改状态）
+func (c *Client) connectFailed(attempt uint64) {
+	c.mu.Lock()
+	current := c.attemptSeq == attempt && c.state == StatusConnecting
+	if current {
+		c.state = StatusDisconnected
+	}
+	c.mu.Unlock()
+	if current {
+		c.setStatus(StatusDisconnected)
+	}
+}
+
+// writeTimeout 单次写入的超时时间，对端停止读取时避免发送协程永久阻塞
+const writeTimeout = 30 * time.Second
+
+// pingLoop 定期发送 WebSocket ping 帧，对端的 pong 由 receiveLoop 中的 pong 处理函数延长读超时
+func (c *Client) pingLoop(s *session, interval time.Duration) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
 	for {
 		select {
-		case <-c.stopCh:
+		case <-s.stop:
 			return
-		case msg := <-c.outgoing:
-			data, err := json.Marshal(msg)
-			if err != nil {
-				c.log("ERROR", fmt.Sprintf("Failed to marshal message: %v", err))
-				continue
-			}
-
-			c.mu.RLock()
-			conn := c.conn
-			c.mu.RUnlock()
-
-			if conn == nil {
-				// 连接不可用，将任务结果放回 channel 等重连后发送，心跳直接丢弃
-				if msg.TaskResult != nil || msg.TaskAck != nil || msg.TaskProgress != nil {
-					select {
-					case c.outgoing <- msg:
-						c.log("WARN", "[sendLoop] Connection unavailable, re-queued task message")
-					default:
-						c.log("ERROR", "[sendLoop] Connection unavailable and queue full, task message lost")
-					}
+		case <-ticker.C:
+			// WriteControl 可与 sendLoop 的写入并发调用
+			c.clock.pingSent()
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				if !s.stopped() {
+					c.log("WARN", fmt.Sprintf("Failed to send ping: %v", err))
+					c.connectionLost(s)
 				}
 				return
 			}
+		}
+	}
+}
 
-			msgType := "unknown"
-			if msg.TaskResult != nil {
-				msgType = fmt.Sprintf("taskResult(taskId=%s)", msg.TaskResult.TaskId)
-			} else if msg.TaskAck != nil {
-				msgType = fmt.Sprintf("taskAck(taskId=%s)", msg.TaskAck.TaskId)
-			} else if msg.Heartbeat != nil {
-				msgType = "heartbeat"
-			}
-
-			if len(data) > 10000 {
-				c.log("DEBUG", fmt.Sprintf("[sendLoop] Sending large message type=%s size=%d bytes", msgType, len(data)))
-			}
+// sendLoop 发送消息循环：按顺序发送队列中的消息，写入成功后才移出队列，
+// 写入失败时消息保留在队首，重连后重新发送（任务消息至少送达一次）
+func (c *Client) sendLoop(s *session) {
+	defer s.wg.Done()
 
-			conn.SetWriteDeadline(time.Now().Add(30 * time.Second))
-			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
-				c.log("ERROR", fmt.Sprintf("[sendLoop] Failed to send message type=%s size=%d: %v", msgType, len(data), err))
-				// 写入失败，将任务相关消息放回 channel 等重连后发送
-				if msg.TaskResult != nil || msg.TaskAck != nil || msg.TaskProgress != nil {
-					select {
-					case c.outgoing <- msg:
-						c.log("WARN", "[sendLoop] Write failed, re-queued task message")
-					default:
-						c.log("ERROR", "[sendLoop] Write failed and queue full, task message lost")
-					}
-				}
+	for {
+		msg, err := c.outbox.peek()
+		if err != nil {
+			c.log("ERROR", fmt.Sprintf("[sendLoop] %v", err))
+		}
+		// 屏幕流的帧不进入发送队列，只在队列为空时发送
+		frame := false
+		if msg == nil {
+			select {
+			case <-s.stop:
 				return
+			case <-c.outbox.ready:
+				continue
+			case msg = <-s.frames:
+				frame = true
 			}
-			conn.SetWriteDeadline(time.Time{})
+		}
+		if s.stopped() {
+			return
+		}
 
-			if len(data) > 10000 {
-				c.log("DEBUG", fmt.Sprintf("[sendLoop] Large message sent successfully type=%s size=%d bytes", msgType, len(data)))
+		data, err := json.Marshal(msg)
+		if err != nil {
+			c.log("ERROR", fmt.Sprintf("Failed to marshal message: %v", err))
+			if !frame {
+				c.outbox.ack(msg)
 			}
+			continue
 		}
-	}
-}
 
-// receiveLoop 接收消息循环
-func (c *Client) receiveLoop() {
-	defer c.wg.Done()
-
-	for {
-		select {
-		case <-c.stopCh:
-			return
-		default:
+		msgType := "unknown"
+		if msg.TaskResult != nil {
+			msgType = fmt.Sprintf("taskResult(taskId=%s)", msg.TaskResult.TaskId)
+		} else if msg.TaskAck != nil {
+			msgType = fmt.Sprintf("taskAck(taskId=%s)", msg.TaskAck.TaskId)
+		} else if msg.Heartbeat != nil {
+			msgType = "heartbeat"
+		} else if frame {
+			msgType = fmt.Sprintf("screenFrame(streamId=%s)", msg.ScreenFrame.StreamId)
 		}
 
-		c.mu.RLock()
-		conn := c.conn
-		c.mu.RUnlock()
+		if len(data) > 10000 && !frame {
+			c.log("DEBUG", fmt.Sprintf("[sendLoop] Sending large message type=%s size=%d bytes", msgType, len(data)))
+		}
 
-		if conn == nil {
+		s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err := s.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			c.log("ERROR", fmt.Sprintf("[sendLoop] Failed to send message type=%s size=%d: %v", msgType, len(data), err))
+			// 写入失败（包括超时）后连接不可再用，停止会话（receiveLoop 随之退出）并重连
+			if !s.stopped() {
+				c.connectionLost(s)
+			}
 			return
 		}
+		s.conn.SetWriteDeadline(time.Time{})
+		if s.resultAck && msg.TaskResult != nil {
+			c.results.track(msg, time.Now())
+		}
+		if !frame {
+			c.outbox.ack(msg)
+		}
+
+		if len(data) > 10000 && !frame {
+			c.log("DEBUG", fmt.Sprintf("[sendLoop] Large message sent successfully type=%s size=%d bytes", msgType, len(data)))
+		}
+	}
+}
 
-		_, data, err := conn.ReadMessage()
+// receiveLoop 接收消息循环，readTimeout 大于 0 时每收到一条消息延长读超时
+func (c *Client) receiveLoop(s *session, readTimeout time.Duration) {
+	defer s.wg.Done()
+
+	for {
+		_, data, err := s.conn.ReadMessage()
 		if err != nil {
-			select {
-			case <-c.stopCh:
+			if s.stopped() {
 				return
-			default:
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				c.log("ERROR", fmt.Sprintf("No data from server for %v, connection considered dead", readTimeout))
+			} else {
 				c.log("ERROR", fmt.Sprintf("WebSocket read error: %v", err))
-				go c.attemptReconnect()
-				return
 			}
+			c.connectionLost(s)
+			return
+		}
+		if readTimeout > 0 {
+			s.conn.SetReadDeadline(time.Now().Add(readTimeout))
 		}
 
 		var msg WsServerMessage
@@ -359,21 +688,39 @@ func (c *Client) handleServerMessage(msg *WsServerMessage) {
 	case msg.ExecuteTask != nil:
 		c.handleExecuteTask(msg.ExecuteTask)
 	case msg.DataRequest != nil:
-		c.handleDataRequest(msg.MessageId, msg.DataRequest)
+		// 截图、枚举窗口等请求可能较慢，不阻塞 Ping、取消任务等后续消息
+		go c.handleDataRequest(msg.MessageId, msg.DataRequest)
 	case msg.CancelTask != nil:
 		c.handleCancelTask(msg.CancelTask)
+	case msg.UpdateSettings != nil:
+		c.handleUpdateSettings(msg.MessageId, msg.UpdateSettings)
+	case msg.StartScreenStream != nil:
+		c.handleStartScreenStream(msg.MessageId, msg.StartScreenStream)
+	case msg.StopScreenStream != nil:
+		c.handleStopScreenStream(msg.StopScreenStream)
+	case msg.InputEvent != nil:
+		// 输入事件按顺序在会话的输入协程中执行，不阻塞 Ping 等后续消息
+		c.dispatchInputEvent(msg.MessageId, msg.InputEvent)
+	case msg.ResultAck != nil:
+		if !c.results.ack(msg.ResultAck.MessageId) {
+			c.log("DEBUG", fmt.Sprintf("Ignoring resultAck for unknown message %s", msg.ResultAck.MessageId))
+		}
 	}
 }
 
 // handlePing 处理 Ping
 func (c *Client) handlePing(msgID string, ping *WsPing) {
 	c.log("DEBUG", "Received ping, sending pong")
+	now := time.Now().UnixMilli()
+	if ping.Timestamp > 0 {
+		c.recordClockSample(ping.Timestamp, now)
+	}
 	c.sendMessage(&WsWorkerMessage{
 		MessageId: msgID,
-		Timestamp: time.Now().UnixMilli(),
-		AgentId:   c.agentID,
+		Timestamp: now,
+		AgentId:   c.currentAgentID(),
 		Pong: &WsPong{
-			ClientTimestamp: time.Now().UnixMilli(),
+			ClientTimestamp: now,
 			ServerTimestamp: ping.Timestamp,
 		},
 	})
@@ -396,12 +743,17 @@ func (c *Client) handleExecuteTask(task *WsExecuteTask) {
 func (c *Client) handleDataRequest(msgID string, req *WsDataRequest) {
 	c.log("INFO", fmt.Sprintf("Received data request: %s", req.RequestType))
 
-	response := HandleDataRequest(req.RequestType, req.PayloadJson)
+	var response *DataResponseResult
+	if strings.EqualFold(req.RequestType, RequestTypeInstallPlugin) {
+		response = c.installPlugin(req.PayloadJson)
+	} else {
+		response = HandleDataRequest(req.RequestType, req.PayloadJson)
+	}
 
 	c.sendMessage(&WsWorkerMessage{
 		MessageId: msgID,
 		Timestamp: time.Now().UnixMilli(),
-		AgentId:   c.agentID,
+		AgentId:   c.currentAgentID(),
 		DataResponse: &WsDataResponse{
 			RequestType: response.RequestType,
 			Success:     response.Success,
@@ -428,7 +780,7 @@ func (c *Client) handleCancelTask(cmd *WsCancelTask) {
 	c.sendMessage(&WsWorkerMessage{
 		MessageId: fmt.Sprintf("cancel_ack_%d", time.Now().UnixMilli()),
 		Timestamp: time.Now().UnixMilli(),
-		AgentId:   c.agentID,
+		AgentId:   c.currentAgentID(),
 		TaskResult: &WsTaskResult{
 			TaskId:  cmd.TaskId,
 			Success: success,
@@ -445,8 +797,8 @@ func (c *Client) handleCancelTask(cmd *WsCancelTask) {
 }
 
 // heartbeatLoop 心跳循环
-func (c *Client) heartbeatLoop() {
-	defer c.wg.Done()
+func (c *Client) heartbeatLoop(s *session) {
+	defer s.wg.Done()
 
 	c.mu.RLock()
 	interval := c.config.HeartbeatInterval
@@ -457,14 +809,78 @@ func (c *Client) heartbeatLoop() {
 
 	for {
 		select {
-		case <-c.stopCh:
+		case <-s.stop:
 			return
 		case <-ticker.C:
 			c.sendHeartbeat()
+		case <-c.heartbeatReset:
+			// SetHeartbeatInterval 修改间隔后立即按新间隔重新计时
+			c.mu.RLock()
+			next := c.config.HeartbeatInterval
+			c.mu.RUnlock()
+			if next > 0 && next != interval {
+				interval = next
+				ticker.Reset(time.Duration(interval) * time.Second)
+			}
 		}
 	}
 }
 
+// SetHeartbeatInterval 设置心跳间隔（秒），已连接时立即按新间隔重新计时
+func (c *Client) SetHeartbeatInterval(seconds int) {
+	if seconds <= 0 {
+		return
+	}
+	c.mu.Lock()
+	c.config.HeartbeatInterval = seconds
+	c.mu.Unlock()
+
+	select {
+	case c.heartbeatReset <- struct{}{}:
+	default:
+	}
+}
+
+// UpdateTLS 更新 TLS 证书选项，返回是否有变化；新选项在下一次连接时生效，
+// 已连接时由随后调用的 UpdateCredentials 重新连接（地址和密钥未变化时同样重连）
+func (c *Client) UpdateTLS(caCertFile, clientCertFile, clientKeyFile string, insecureSkipVerify bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changed := c.config.CACertFile != caCertFile || c.config.ClientCertFile != clientCertFile ||
+		c.config.ClientKeyFile != clientKeyFile || c.config.InsecureSkipVerify != insecureSkipVerify
+	c.config.CACertFile = caCertFile
+	c.config.ClientCertFile = clientCertFile
+	c.config.ClientKeyFile = clientKeyFile
+	c.config.InsecureSkipVerify = insecureSkipVerify
+	c.tlsChanged = c.tlsChanged || changed
+	return changed
+}
+
+// UpdateCredentials 更新服务端地址和密钥，返回是否有变化（包括 UpdateTLS 更新的 TLS 选项）
+// 有变化且已连接时断开并使用新配置重新连接（未发送的任务结果保留在发送队列中，重连后继续发送）
+func (c *Client) UpdateCredentials(serverURL, accessKey, secretKey string) (bool, error) {
+	c.mu.Lock()
+	changed := c.config.ServerURL != serverURL || c.config.AccessKey != accessKey || c.config.SecretKey != secretKey || c.tlsChanged
+	c.tlsChanged = false
+	connected := c.state == StatusConnected
+	c.config.ServerURL = serverURL
+	c.config.AccessKey = accessKey
+	c.config.SecretKey = secretKey
+	c.mu.Unlock()
+
+	if !changed || !connected {
+		return changed, nil
+	}
+	c.log("INFO", "Connection settings changed, reconnecting...")
+	c.Disconnect()
+
+	c.mu.Lock()
+	ctx, cancel := connectContext(context.Background(), c.disconnectCh)
+	c.mu.Unlock()
+	defer cancel(nil)
+	return true, c.doConnect(ctx)
+}
+
 // sendHeartbeat 发送心跳
 func (c *Client) sendHeartbeat() {
 	c.mu.RLock()
@@ -473,13 +889,14 @@ func (c *Client) sendHeartbeat() {
 
 	var agentStatus *WsAgentStatus
 	if callback != nil {
-		status, taskID, taskType, startedAt, count := callback()
+		status, taskID, taskType, startedAt, count, taskIDs := callback()
 		agentStatus = &WsAgentStatus{
 			Status:            status,
 			CurrentTaskId:     taskID,
 			CurrentTaskType:   taskType,
 			TaskStartedAt:     startedAt,
 			RunningTasksCount: int32(count),
+			RunningTaskIds:    taskIDs,
 		}
 	} else {
 		agentStatus = &WsAgentStatus{
@@ -488,63 +905,171 @@ func (c *Client) sendHeartbeat() {
 		}
 	}
 
+	heartbeat := &WsHeartbeat{
+		AgentStatus:  agentStatus,
+		ResourceInfo: c.resources.get(),
+		Capabilities: c.capabilitiesChanged(),
+		Plugins:      c.plugins.report(),
+	}
+	c.probeScreen(heartbeat)
+	c.probeScreenLock(agentStatus)
+	c.probeDisplay(heartbeat)
+	c.probeClockSkew(heartbeat)
+	stats := c.outbox.snapshot()
+	stats.Unacked = int64(c.results.count())
+	heartbeat.Outbox = &stats
+
 	c.sendMessage(&WsWorkerMessage{
 		MessageId: fmt.Sprintf("heartbeat_%d", time.Now().UnixMilli()),
 		Timestamp: time.Now().UnixMilli(),
-		AgentId:   c.agentID,
-		Heartbeat: &WsHeartbeat{
-			AgentStatus: agentStatus,
-		},
+		AgentId:   c.currentAgentID(),
+		Heartbeat: heartbeat,
 	})
+	c.lastHeartbeat.Store(time.Now().UnixMilli())
 	c.log("DEBUG", "Heartbeat sent")
 }
 
+// SetScreenProbe 设置截图可用性检查，每次心跳时调用并上报结果（如 Windows 服务在用户登录前无法截图）
+func (c *Client) SetScreenProbe(probe func() error) {
+	c.mu.Lock()
+	c.screenProbe = probe
+	c.mu.Unlock()
+}
+
+// probeScreen 检查能否截图并写入心跳，可用性变化时输出日志
+func (c *Client) probeScreen(heartbeat *WsHeartbeat) {
+	c.mu.RLock()
+	probe := c.screenProbe
+	c.mu.RUnlock()
+	if probe == nil {
+		return
+	}
+
+	var message string
+	if err := probe(); err != nil {
+		message = err.Error()
+	}
+	available := message == ""
+	heartbeat.ScreenAvailable = &available
+	heartbeat.ScreenError = message
+
+	c.mu.Lock()
+	previous := c.screenErr
+	c.screenErr = &message
+	c.mu.Unlock()
+	switch {
+	case previous != nil && *previous == message:
+	case !available:
+		c.log("WARN", fmt.Sprintf("Screen capture unavailable: %s", message))
+	case previous != nil:
+		c.log("INFO", "Screen capture available")
+	}
+}
+
+// SetScreenLockProbe 设置锁屏检查，每次心跳时调用并通过 AgentStatus.ScreenLocked 上报，便于服务端告警
+func (c *Client) SetScreenLockProbe(probe func() bool) {
+	c.mu.Lock()
+	c.screenLockProbe = probe
+	c.mu.Unlock()
+}
+
+// probeScreenLock 检查屏幕是否已锁定并写入 Agent 状态，状态变化时输出日志
+func (c *Client) probeScreenLock(status *WsAgentStatus) {
+	c.mu.RLock()
+	probe := c.screenLockProbe
+	c.mu.RUnlock()
+	if probe == nil {
+		return
+	}
+
+	locked := probe()
+	status.ScreenLocked = locked
+
+	c.mu.Lock()
+	previous := c.screenLocked
+	c.screenLocked = locked
+	c.mu.Unlock()
+	switch {
+	case previous == locked:
+	case locked:
+		c.log("WARN", "Screen appears locked")
+	default:
+		c.log("INFO", "Screen unlocked")
+	}
+}
+
+// SetDisplayProbe 设置显示器分辨率查询，每次心跳时调用并上报 display
+func (c *Client) SetDisplayProbe(probe func() (width, height int, scaleFactor float64)) {
+	c.mu.Lock()
+	c.displayProbe = probe
+	c.mu.Unlock()
+}
+
+// probeDisplay 查询显示器分辨率并写入心跳（没有可用的显示器时不上报）
+func (c *Client) probeDisplay(heartbeat *WsHeartbeat) {
+	c.mu.RLock()
+	probe := c.displayProbe
+	c.mu.RUnlock()
+	if probe == nil {
+		return
+	}
+	if width, height, scale := probe(); width > 0 && height > 0 {
+		heartbeat.Display = &WsDisplayInfo{Width: width, Height: height, ScaleFactor: scale}
+	}
+}
+
 // sendMessage 发送消息到队列
 func (c *Client) sendMessage(msg *WsWorkerMessage) {
-	select {
-	case c.outgoing <- msg:
-	default:
+	if msg.AgentId == "" {
+		msg.ClientId = c.ClientID()
+	}
+	msg.Timestamp = c.clock.correctTimestamp(msg.Timestamp)
+	dropped, err := c.outbox.push(msg)
+	if err != nil {
+		c.log("ERROR", fmt.Sprintf("Failed to spill task message to disk, dropping message: %v", err))
+	} else if dropped {
 		c.log("WARN", "Outgoing message queue full, dropping message")
 	}
 }
 
-// closeStopCh 安全关闭停止信号（防止 double close panic）
-func (c *Client) closeStopCh() {
-	c.stopOnce.Do(func() {
-		close(c.stopCh)
-	})
+// connectionLost 会话的连接异常断开（读写失败或读超时），发送、接收和 ping 协程都可能调用，只触发一次重连
+func (c *Client) connectionLost(s *session) {
+	s.lostOnce.Do(func() { go c.attemptReconnect(s) })
 }
 
-// Disconnect 断开连接
+// Disconnect 断开连接，同时放弃进行中的连接和自动重连，可并发调用
 func (c *Client) Disconnect() error {
 	c.mu.Lock()
-	if !c.isConnected {
-		c.mu.Unlock()
-		return nil
+	close(c.disconnectCh)
+	c.disconnectCh = make(chan struct{})
+	s := c.session
+	c.session = nil
+	prev := c.state
+	if s != nil {
+		c.state = StatusStopping
+	} else if prev != StatusStopping {
+		c.state = StatusDisconnected
 	}
-	c.isConnected = false
 	c.mu.Unlock()
 
-	c.closeStopCh()
-
-	// 先关闭 WebSocket 连接，使阻塞的 ReadMessage 立即返回错误
-	// 这样 receiveLoop 才能退出，wg.Wait 才不会死锁
-	c.mu.Lock()
-	if c.conn != nil {
-		c.conn.WriteMessage(websocket.CloseMessage,
-			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-		c.conn.Close()
-		c.conn = nil
+	if s == nil {
+		if prev == StatusConnecting || prev == StatusReconnecting {
+			c.log("INFO", "Connect aborted")
+			c.setStatus(StatusDisconnected)
+		}
+		return nil
 	}
-	c.mu.Unlock()
+	c.setStatus(StatusStopping)
 
-	// 等待 goroutine 结束（连接已关闭，ReadMessage 会立即返回错误）
-	c.wg.Wait()
+	// 先通知服务端正常关闭，再关闭连接使阻塞的 ReadMessage 立即返回，等待所有协程退出
+	s.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	s.close()
 
-	// 清理状态
 	c.mu.Lock()
 	c.agentID = ""
 	c.agentName = ""
+	c.state = StatusDisconnected
 	c.mu.Unlock()
 
 	c.log("INFO", "Disconnected")
@@ -553,44 +1078,57 @@ func (c *Client) Disconnect() error {
 	return nil
 }
 
-// attemptReconnect 尝试重连
-func (c *Client) attemptReconnect() {
+// attemptReconnect 停止异常断开的会话并按 ReconnectDelays 重连；会话已被 Disconnect 停止时不处理，
+// 重连过程中调用 Disconnect 时立即放弃
+func (c *Client) attemptReconnect(s *session) {
 	c.mu.Lock()
-	if !c.isConnected {
+	if c.session != s {
 		c.mu.Unlock()
 		return
 	}
-	c.isConnected = false
-
-	// 关闭旧的停止信号，让 sendLoop/heartbeatLoop 退出
-	c.closeStopCh()
-
-	// 关闭旧连接
-	if c.conn != nil {
-		c.conn.Close()
-		c.conn = nil
+	c.session = nil
+	reconnect := !c.config.DisableReconnect
+	delays := c.config.ReconnectDelays
+	if reconnect {
+		c.state = StatusReconnecting
+	} else {
+		c.state = StatusDisconnected
 	}
+	ctx, cancel := connectContext(context.Background(), c.disconnectCh)
 	c.mu.Unlock()
+	defer cancel(nil)
 
-	// 注意：当前 goroutine 是从 receiveLoop（已退出）中启动的
-	// 等待剩余的 sendLoop 和 heartbeatLoop 退出
-	c.wg.Wait()
+	// 当前协程不属于会话，可以等待会话的协程全部退出
+	s.close()
 
+	if !reconnect {
+		c.log("WARN", "Connection lost, auto reconnect is disabled")
+		c.setStatus(StatusDisconnected)
+		return
+	}
 	c.setStatus(StatusReconnecting)
 
 	// 指数退避重连
-	for i, delay := range c.config.ReconnectDelays {
-		c.log("INFO", fmt.Sprintf("Reconnect attempt %d/%d in %ds...", i+1, len(c.config.ReconnectDelays), delay))
-		time.Sleep(time.Duration(delay) * time.Second)
+	for i, delay := range delays {
+		c.log("INFO", fmt.Sprintf("Reconnect attempt %d/%d in %ds...", i+1, len(delays), delay))
+		if !c.waitRetry(ctx, time.Duration(delay)*time.Second) {
+			return
+		}
 
-		if err := c.doConnect(); err == nil {
+		reconnectsTotal.Inc()
+		err := c.doConnect(ctx)
+		if err == nil {
+			c.reconnects.Add(1)
 			c.log("INFO", "Reconnected successfully!")
 			return
 		}
+		if ctx.Err() != nil || errors.Is(err, ErrAuthRejected) {
+			return
+		}
 	}
 
 	c.log("ERROR", "Failed to reconnect after all attempts")
-	c.setStatus(StatusDisconnected)
+	c.stopReconnecting()
 }
 
 // ==================== 供 executor 调用的方法 ====================
@@ -646,6 +1184,8 @@ func (c *Client) SendTaskMessage(msg *pb.WorkerMessage) {
 				}
 			}
 			wsMsg.TaskResult = wsResult
+			// executor 的 messageId 为毫秒时间戳，同一毫秒内的结果会重复；加序号后服务端才能按 messageId 去重
+			wsMsg.MessageId = fmt.Sprintf("%s_%d", msg.MessageId, c.resultSeq.Add(1))
 		}
 	case *pb.WorkerMessage_Pong:
 		if p := payload.Pong; p != nil {
@@ -682,23 +1222,26 @@ func (c *Client) SendTaskMessage(msg *pb.WorkerMessage) {
 	c.sendMessage(wsMsg)
 }
 
+// currentAgentID 当前连接的 Agent ID（收发协程与重连并发时读取）
+func (c *Client) currentAgentID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.agentID
+}
+
 // GetStatus 获取当前状态
 func (c *Client) GetStatus() (ClientStatus, string, string) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	status := StatusDisconnected
-	if c.isConnected {
-		status = StatusConnected
-	}
-	return status, c.agentID, c.agentName
+	return c.state, c.agentID, c.agentName
 }
 
 // IsConnected 检查是否已连接
 func (c *Client) IsConnected() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.isConnected
+	return c.state == StatusConnected
 }
 
 // SetStatusCallback 设置状态变更回调
@@ -729,7 +1272,7 @@ func (c *Client) SetExecutorStatusCallback(callback ExecutorStatusCallback) {
 	c.mu.Unlock()
 }
 
-// setStatus 设置状态并触发回调
+// setStatus 触发状态回调（状态本身由状态机在持有 mu 时修改）
 func (c *Client) setStatus(status ClientStatus) {
 	c.mu.RLock()
 	callback := c.onStatusChange
@@ -745,25 +1288,43 @@ func (c *Client) Log(level, message string) {
 	c.log(level, message)
 }
 
-// log 记录日志（内部方法）
+// maxLogEntries 日志缓冲区保留的条数
+const maxLogEntries = 500
+
+// log 记录日志（内部方法）：保存到日志缓冲区（GUI 读取）、通知订阅者并通过 logger 输出到控制台和日志文件
+// 低于全局日志级别（logger.SetLevel）的日志既不输出也不保存
 func (c *Client) log(level, message string) {
+	if !logger.Enabled(logger.ParseLevel(level)) {
+		return
+	}
+
+	c.logsMu.Lock()
+	c.logSeq++
 	entry := LogEntry{
+		Seq:       c.logSeq,
 		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
 		Level:     level,
 		Message:   message,
 	}
-
-	c.logsMu.Lock()
 	c.logs = append(c.logs, entry)
-	if len(c.logs) > 500 {
-		c.logs = c.logs[len(c.logs)-500:]
+	if len(c.logs) > maxLogEntries {
+		c.logs = c.logs[len(c.logs)-maxLogEntries:]
+	}
+	subs := make([]func(LogEntry), 0, len(c.logSubs))
+	for _, fn := range c.logSubs {
+		subs = append(subs, fn)
 	}
 	c.logsMu.Unlock()
 
-	fmt.Printf("[%s] %s\n", level, message)
+	for _, fn := range subs {
+		fn(entry)
+	}
+
+	// 控制台和日志文件由 logger 统一输出
+	logger.Log(logger.ParseLevel(level), message)
 }
 
-// GetLogs 获取日志
+// GetLogs 获取最近 limit 条日志（limit <= 0 时返回全部）
 func (c *Client) GetLogs(limit int) []LogEntry {
 	c.logsMu.Lock()
 	defer c.logsMu.Unlock()
@@ -776,3 +1337,37 @@ func (c *Client) GetLogs(limit int) []LogEntry {
 	copy(result, c.logs[len(c.logs)-limit:])
 	return result
 }
+
+// GetLogsSince 返回序号大于 seq 的日志（按序号递增），用于增量拉取：
+// 调用方记录收到的最大序号作为下次的参数，不会重复也不会遗漏缓冲区内的日志；
+// seq 早于缓冲区时返回全部缓冲的日志（第一条的序号大于 seq+1 表示中间的日志已被丢弃）
+func (c *Client) GetLogsSince(seq int64) []LogEntry {
+	c.logsMu.Lock()
+	defer c.logsMu.Unlock()
+
+	if len(c.logs) == 0 || seq >= c.logSeq {
+		return nil
+	}
+	start := int(max(seq-c.logs[0].Seq+1, 0))
+	result := make([]LogEntry, len(c.logs)-start)
+	copy(result, c.logs[start:])
+	return result
+}
+
+// SubscribeLogs 订阅日志，之后的每条日志都调用 fn（在记录日志的协程中同步调用，fn 不应阻塞），
+// 返回取消订阅的函数（可重复调用）
+func (c *Client) SubscribeLogs(fn func(LogEntry)) (unsubscribe func()) {
+	c.logsMu.Lock()
+	defer c.logsMu.Unlock()
+	if c.logSubs == nil {
+		c.logSubs = make(map[int]func(LogEntry))
+	}
+	id := c.nextSubID
+	c.nextSubID++
+	c.logSubs[id] = fn
+	return func() {
+		c.logsMu.Lock()
+		delete(c.logSubs, id)
+		c.logsMu.Unlock()
+	}
+}
@@ -1,8 +1,23 @@
 package grpc
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zoeyai/zoeyworker/internal/logger"
+	"github.com/zoeyai/zoeyworker/pkg/plugin"
 )
 
 func TestGetSystemInfo(t *testing.T) {
@@ -112,6 +127,445 @@ func TestClientLogs(t *testing.T) {
 	t.Logf("获取到 %d 条日志", len(logs))
 }
 
+func TestClientLogsHonorLevel(t *testing.T) {
+	old := logger.GetLevel()
+	t.Cleanup(func() { logger.SetLevel(old) })
+	logger.SetLevel(logger.ParseLevel("warn"))
+
+	client := NewClient(nil)
+	client.log("DEBUG", "debug")
+	client.log("INFO", "info")
+	client.log("WARN", "warn")
+	client.log("ERROR", "error")
+
+	logs := client.GetLogs(10)
+	if len(logs) != 2 || logs[0].Level != "WARN" || logs[1].Level != "ERROR" {
+		t.Errorf("WARN 级别下应只保留 WARN 和 ERROR, 实际 %+v", logs)
+	}
+}
+
+func TestClientLogsSinceAndSubscribe(t *testing.T) {
+	client := NewClient(nil)
+	if logs := client.GetLogsSince(0); len(logs) != 0 {
+		t.Errorf("没有日志时应返回空, 实际 %+v", logs)
+	}
+
+	var received []LogEntry
+	unsubscribe := client.SubscribeLogs(func(entry LogEntry) { received = append(received, entry) })
+	client.log("INFO", "first")
+	client.log("INFO", "second")
+	unsubscribe()
+	unsubscribe()
+	client.log("INFO", "third")
+	if len(received) != 2 || received[0].Seq != 1 || received[1].Message != "second" {
+		t.Errorf("订阅者应收到取消前的日志, 实际 %+v", received)
+	}
+
+	if logs := client.GetLogsSince(1); len(logs) != 2 || logs[0].Seq != 2 || logs[1].Message != "third" {
+		t.Errorf("应只返回序号大于 1 的日志, 实际 %+v", logs)
+	}
+	if logs := client.GetLogsSince(3); len(logs) != 0 {
+		t.Errorf("没有新日志时应返回空, 实际 %+v", logs)
+	}
+
+	// 早于缓冲区的序号返回全部缓冲的日志，序号保持连续
+	for i := range maxLogEntries {
+		client.log("INFO", fmt.Sprintf("burst %d", i))
+	}
+	logs := client.GetLogsSince(1)
+	if len(logs) != maxLogEntries || logs[0].Seq != 4 || logs[len(logs)-1].Seq != maxLogEntries+3 {
+		t.Errorf("应返回缓冲区中的 %d 条日志 (4-%d), 实际 %d 条 %d-%d", maxLogEntries, maxLogEntries+3, len(logs), logs[0].Seq, logs[len(logs)-1].Seq)
+	}
+}
+
+func TestConnectWithRetryStopsOnAuthRejected(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.ReadMessage()
+		conn.WriteJSON(WsConnectResponse{Type: "connect_response", Success: false, Message: "invalid key"})
+	}))
+	defer server.Close()
+
+	client := NewClient(&ClientConfig{ReconnectDelays: []int{0}})
+	err := client.ConnectWithRetry(context.Background(), strings.TrimPrefix(server.URL, "http://"), "ak", "sk")
+	if !errors.Is(err, ErrAuthRejected) {
+		t.Fatalf("认证被拒绝时应返回 ErrAuthRejected, 实际 %v", err)
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("认证被拒绝后不应重试, 实际连接 %d 次", n)
+	}
+}
+
+func TestProbeServer(t *testing.T) {
+	var messages atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+			messages.Add(1)
+		}
+	}))
+	defer server.Close()
+
+	addr := strings.TrimPrefix(server.URL, "http://")
+	wsURL, err := ProbeServer(context.Background(), &ClientConfig{ServerURL: addr})
+	if err != nil {
+		t.Fatalf("握手应成功: %v", err)
+	}
+	if wsURL != "ws://"+addr+"/ws/agent" {
+		t.Errorf("WebSocket 地址不正确: %s", wsURL)
+	}
+	if n := messages.Load(); n != 0 {
+		t.Errorf("探测不应发送认证消息, 实际 %d 条", n)
+	}
+
+	server.Close()
+	if _, err := ProbeServer(context.Background(), &ClientConfig{ServerURL: addr}); err == nil {
+		t.Error("服务端关闭后探测应失败")
+	}
+}
+
+// newAgentServer 启动接受所有认证的服务端，认证成功后调用 handle（n 为第几个连接，从 1 开始）
+func newAgentServer(t *testing.T, handle func(n int32, conn *websocket.Conn)) (*httptest.Server, *atomic.Int32) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		n := connections.Add(1)
+		conn.ReadMessage()
+		conn.WriteJSON(WsConnectResponse{Type: "connect_response", Success: true, AgentId: "agent"})
+		handle(n, conn)
+	}))
+	t.Cleanup(server.Close)
+	return server, &connections
+}
+
+// readUntilClosed 持续读取直到连接关闭（处理 ping 等控制帧）
+func readUntilClosed(n int32, conn *websocket.Conn) {
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// newKeepaliveServer respondPings 为 false 时不回复 ping（模拟已崩溃但 TCP 未关闭的对端）
+func newKeepaliveServer(t *testing.T, respondPings bool) (*httptest.Server, *atomic.Int32) {
+	return newAgentServer(t, func(n int32, conn *websocket.Conn) {
+		if !respondPings {
+			conn.SetPingHandler(func(string) error { return nil })
+		}
+		readUntilClosed(n, conn)
+	})
+}
+
+func TestKeepaliveDetectsDeadConnection(t *testing.T) {
+	config := &ClientConfig{HeartbeatInterval: 60, PingInterval: 1, ReadTimeout: 2, ReconnectDelays: []int{0}}
+	dead, deadConnections := newKeepaliveServer(t, false)
+	healthy, healthyConnections := newKeepaliveServer(t, true)
+
+	deadClient := NewClient(config)
+	healthyConfig := *config
+	healthyClient := NewClient(&healthyConfig)
+	for client, server := range map[*Client]*httptest.Server{deadClient: dead, healthyClient: healthy} {
+		if err := client.Connect(strings.TrimPrefix(server.URL, "http://"), "ak", "sk"); err != nil {
+			t.Fatalf("连接失败: %v", err)
+		}
+		defer client.Disconnect()
+	}
+
+	time.Sleep(3500 * time.Millisecond)
+	if n := deadConnections.Load(); n < 2 {
+		t.Errorf("对端不回复 pong 时应在读超时后重连, 实际连接 %d 次", n)
+	}
+	if n := healthyConnections.Load(); n != 1 {
+		t.Errorf("对端回复 pong 时不应重连, 实际连接 %d 次", n)
+	}
+}
+
+func TestKeepaliveDefaults(t *testing.T) {
+	if interval, timeout := DefaultConfig().keepalive(); interval != 15*time.Second || timeout != 45*time.Second {
+		t.Errorf("默认 ping 间隔和读超时应为 15s/45s, 实际 %v/%v", interval, timeout)
+	}
+	if interval, timeout := (&ClientConfig{}).keepalive(); interval != 0 || timeout != 0 {
+		t.Errorf("未设置 PingInterval 时不应检测, 实际 %v/%v", interval, timeout)
+	}
+}
+
+// waitFor 等待条件成立，超时后测试失败
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待超时: %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReconnectAfterServerDrop(t *testing.T) {
+	// 第一个连接认证后立即断开，之后的连接保持
+	server, connections := newAgentServer(t, func(n int32, conn *websocket.Conn) {
+		if n > 1 {
+			readUntilClosed(n, conn)
+		}
+	})
+	client := NewClient(&ClientConfig{HeartbeatInterval: 60, ReconnectDelays: []int{0, 0}})
+	var mu sync.Mutex
+	var statuses []ClientStatus
+	client.SetStatusCallback(func(status ClientStatus) {
+		mu.Lock()
+		statuses = append(statuses, status)
+		mu.Unlock()
+	})
+	if err := client.Connect(strings.TrimPrefix(server.URL, "http://"), "ak", "sk"); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect()
+
+	waitFor(t, "断开后重连", func() bool { return connections.Load() == 2 && client.IsConnected() })
+	time.Sleep(100 * time.Millisecond)
+	if n := connections.Load(); n != 2 {
+		t.Errorf("一次断开只应触发一次重连, 实际连接 %d 次", n)
+	}
+	if n := client.Stats().Reconnects; n != 1 {
+		t.Errorf("重连成功次数应为 1, 实际 %d", n)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Contains(statuses, StatusReconnecting) {
+		t.Errorf("重连过程应经过 reconnecting 状态, 实际 %v", statuses)
+	}
+}
+
+func TestDisconnectAbortsReconnect(t *testing.T) {
+	// 所有连接认证后立即断开
+	server, connections := newAgentServer(t, func(int32, *websocket.Conn) {})
+	client := NewClient(&ClientConfig{HeartbeatInterval: 60, ReconnectDelays: []int{1, 1, 1}})
+	if err := client.Connect(strings.TrimPrefix(server.URL, "http://"), "ak", "sk"); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, "进入重连", func() bool {
+		status, _, _ := client.GetStatus()
+		return status == StatusReconnecting
+	})
+	client.Disconnect()
+	if status, _, _ := client.GetStatus(); status != StatusDisconnected {
+		t.Errorf("手动断开后状态应为 disconnected, 实际 %s", status)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if n := connections.Load(); n != 1 {
+		t.Errorf("手动断开后不应继续重连, 实际连接 %d 次", n)
+	}
+}
+
+func TestDisconnectAbortsConnectWithRetry(t *testing.T) {
+	client := NewClient(&ClientConfig{ReconnectDelays: []int{1}})
+	done := make(chan error, 1)
+	go func() {
+		done <- client.ConnectWithRetry(context.Background(), "127.0.0.1:1", "ak", "sk")
+	}()
+
+	waitFor(t, "进入重试等待", func() bool {
+		status, _, _ := client.GetStatus()
+		return status == StatusReconnecting
+	})
+	client.Disconnect()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrDisconnected) {
+			t.Errorf("手动断开后应返回 ErrDisconnected, 实际 %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("手动断开后 ConnectWithRetry 应立即返回")
+	}
+}
+
+func TestConcurrentDisconnect(t *testing.T) {
+	// 服务端在客户端断开的同时关闭连接
+	release := make(chan struct{})
+	server, connections := newAgentServer(t, func(n int32, conn *websocket.Conn) {
+		if n == 1 {
+			<-release
+		} else {
+			readUntilClosed(n, conn)
+		}
+	})
+	addr := strings.TrimPrefix(server.URL, "http://")
+	client := NewClient(&ClientConfig{HeartbeatInterval: 60, PingInterval: 1, ReconnectDelays: []int{0}})
+	if err := client.Connect(addr, "ak", "sk"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		client.sendMessage(&WsWorkerMessage{TaskResult: &WsTaskResult{TaskId: "t"}})
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.Disconnect()
+		}()
+		if i == 5 {
+			close(release)
+		}
+	}
+	wg.Wait()
+
+	waitFor(t, "断开完成", func() bool {
+		status, _, _ := client.GetStatus()
+		return status == StatusDisconnected
+	})
+	time.Sleep(100 * time.Millisecond)
+	if client.IsConnected() || connections.Load() != 1 {
+		t.Errorf("并发断开后不应重连, 实际连接 %d 次", connections.Load())
+	}
+
+	// 断开后可以再次连接
+	if err := client.Connect(addr, "ak", "sk"); err != nil {
+		t.Fatalf("断开后应能再次连接: %v", err)
+	}
+	if err := client.Connect(addr, "ak", "sk"); err == nil {
+		t.Error("已连接时重复连接应返回错误")
+	}
+	client.Disconnect()
+}
+
+func TestHeartbeatReportsScreenAvailability(t *testing.T) {
+	client := NewClient(nil)
+	client.sendHeartbeat()
+	if hb := nextMessage(client).Heartbeat; hb.ScreenAvailable != nil {
+		t.Error("未设置截图检查时不应上报截图可用性")
+	}
+
+	probeErr := errors.New("没有可用的显示器")
+	client.SetScreenProbe(func() error { return probeErr })
+	client.sendHeartbeat()
+	hb := nextMessage(client).Heartbeat
+	if hb.ScreenAvailable == nil || *hb.ScreenAvailable || hb.ScreenError != probeErr.Error() {
+		t.Errorf("截图不可用时应上报原因, 实际 available=%v error=%q", hb.ScreenAvailable, hb.ScreenError)
+	}
+
+	probeErr = nil
+	client.sendHeartbeat()
+	hb = nextMessage(client).Heartbeat
+	if hb.ScreenAvailable == nil || !*hb.ScreenAvailable || hb.ScreenError != "" {
+		t.Errorf("截图恢复后应上报可用, 实际 available=%v error=%q", hb.ScreenAvailable, hb.ScreenError)
+	}
+}
+
+func TestHeartbeatReportsScreenLocked(t *testing.T) {
+	client := NewClient(nil)
+	client.sendHeartbeat()
+	if nextMessage(client).Heartbeat.AgentStatus.ScreenLocked {
+		t.Error("未设置锁屏检查时不应上报锁屏")
+	}
+
+	locked := true
+	client.SetScreenLockProbe(func() bool { return locked })
+	client.sendHeartbeat()
+	if !nextMessage(client).Heartbeat.AgentStatus.ScreenLocked {
+		t.Error("锁屏时应上报 screenLocked")
+	}
+
+	locked = false
+	client.sendHeartbeat()
+	if nextMessage(client).Heartbeat.AgentStatus.ScreenLocked {
+		t.Error("解锁后不应上报 screenLocked")
+	}
+}
+
+func TestHeartbeatReportsDisplay(t *testing.T) {
+	client := NewClient(nil)
+	client.sendHeartbeat()
+	if nextMessage(client).Heartbeat.Display != nil {
+		t.Error("未设置分辨率查询时不应上报 display")
+	}
+
+	width := 2560
+	client.SetDisplayProbe(func() (int, int, float64) { return width, 1600, 2 })
+	client.sendHeartbeat()
+	if d := nextMessage(client).Heartbeat.Display; d == nil || *d != (WsDisplayInfo{Width: 2560, Height: 1600, ScaleFactor: 2}) {
+		t.Errorf("display = %+v", d)
+	}
+
+	width = 0
+	client.sendHeartbeat()
+	if d := nextMessage(client).Heartbeat.Display; d != nil {
+		t.Errorf("没有可用的显示器时不应上报 display: %+v", d)
+	}
+}
+
+func TestConnectWithRetryUntilCanceled(t *testing.T) {
+	// 占用端口后立即关闭，保证连接失败
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	var statuses []ClientStatus
+	client := NewClient(&ClientConfig{ReconnectDelays: []int{0}})
+	client.SetStatusCallback(func(status ClientStatus) { statuses = append(statuses, status) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	err = client.ConnectWithRetry(ctx, addr, "ak", "sk")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ctx 超时后应返回 context 错误, 实际 %v", err)
+	}
+
+	retries := 0
+	for _, status := range statuses {
+		if status == StatusReconnecting {
+			retries++
+		}
+	}
+	if retries < 2 {
+		t.Errorf("连接失败后应持续重试, 实际重试 %d 次", retries)
+	}
+	if statuses[len(statuses)-1] != StatusDisconnected {
+		t.Errorf("取消后状态应为 disconnected, 实际 %v", statuses[len(statuses)-1])
+	}
+}
+
+func TestUpdateCredentialsWhileDisconnected(t *testing.T) {
+	client := NewClient(nil)
+	client.SetHeartbeatInterval(9)
+	client.SetHeartbeatInterval(0) // 无效值忽略
+	if client.config.HeartbeatInterval != 9 {
+		t.Errorf("心跳间隔应为 9, 实际 %d", client.config.HeartbeatInterval)
+	}
+
+	changed, err := client.UpdateCredentials("a:1", "ak", "sk")
+	if !changed || err != nil {
+		t.Fatalf("首次设置应返回有变化且未连接时不重连, 实际 %v %v", changed, err)
+	}
+	if changed, _ := client.UpdateCredentials("a:1", "ak", "sk"); changed {
+		t.Error("相同配置不应视为变化")
+	}
+}
+
 func TestDataHandler_GetApplications(t *testing.T) {
 	result := HandleDataRequest(RequestTypeGetApplications, "{}")
 
@@ -203,6 +657,64 @@ func TestDataHandler_GetElements(t *testing.T) {
 	t.Logf("GetElements 结果: success=%v, message=%s", result.Success, result.Message)
 }
 
+func TestDataHandler_GetDisplays(t *testing.T) {
+	result := HandleDataRequest(RequestTypeGetDisplays, "{}")
+
+	if result.RequestType != RequestTypeGetDisplays {
+		t.Errorf("RequestType 应为 %s", RequestTypeGetDisplays)
+	}
+	if !result.Success {
+		t.Fatalf("GetDisplays 应成功: %s", result.Message)
+	}
+
+	var data struct {
+		Displays []struct {
+			Index       int     `json:"index"`
+			ScaleFactor float64 `json:"scale_factor"`
+			Bounds      struct {
+				Width  int `json:"width"`
+				Height int `json:"height"`
+			} `json:"bounds"`
+		} `json:"displays"`
+	}
+	if err := json.Unmarshal([]byte(result.PayloadJSON), &data); err != nil {
+		t.Fatalf("解析 PayloadJSON 失败: %v", err)
+	}
+	for i, d := range data.Displays {
+		if d.Index != i {
+			t.Errorf("displays[%d].index = %d", i, d.Index)
+		}
+		t.Logf("  display %d: %dx%d scale=%.2f", d.Index, d.Bounds.Width, d.Bounds.Height, d.ScaleFactor)
+	}
+}
+
+func TestDataHandler_GetPlugins(t *testing.T) {
+	result := HandleDataRequest(RequestTypeGetPlugins, "{}")
+	if !result.Success {
+		t.Fatalf("GetPlugins 应成功: %s", result.Message)
+	}
+
+	var data struct {
+		Plugins []struct {
+			Name      string            `json:"name"`
+			Installed bool              `json:"installed"`
+			Paths     map[string]string `json:"paths"`
+		} `json:"plugins"`
+	}
+	if err := json.Unmarshal([]byte(result.PayloadJSON), &data); err != nil {
+		t.Fatalf("解析 PayloadJSON 失败: %v", err)
+	}
+	var found bool
+	for _, p := range data.Plugins {
+		if p.Name == plugin.OCRPluginName {
+			found = p.Paths["det"] != ""
+		}
+	}
+	if !found {
+		t.Errorf("插件列表应包含 OCR 插件及其文件路径: %s", result.PayloadJSON)
+	}
+}
+
 func TestDataHandler_UnknownType(t *testing.T) {
 	result := HandleDataRequest("UNKNOWN_TYPE", "{}")
 
@@ -0,0 +1,135 @@
+package grpc
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 时钟偏差估计参数
+const (
+	// clockSkewSamples 保留最近的 Ping 样本数
+	clockSkewSamples = 8
+	// clockSkewMinSamples 样本数达到后才给出估计（避免连接刚建立时的单个高延迟样本）
+	clockSkewMinSamples = 3
+	// clockSkewWarnThreshold 偏差超过该值时输出 WARN 日志
+	clockSkewWarnThreshold = 5 * time.Second
+)
+
+// clockSkew 根据服务端 Ping 中的服务端时间戳估计本机时钟与服务端的偏差（本机时钟 - 服务端时钟）
+//
+// 每个样本为本机收到 Ping 的时间减去服务端发送时间，即偏差加上单程网络延迟。
+// 与 NTP 的时钟过滤相同，取最近若干样本中的最小值（延迟最小、排队干扰最少的样本），
+// 再减去 WebSocket ping 帧往返时间的一半作为单程延迟的估计
+type clockSkew struct {
+	mu      sync.Mutex
+	samples []int64       // 毫秒，最多 clockSkewSamples 个
+	rtt     time.Duration // 最近一次 WebSocket ping 帧的往返时间，0 为尚未测量
+	warned  bool          // 上一次估计是否超出阈值（只在变化时输出日志）
+
+	pingSentAt atomic.Int64 // 最近一次发送 ping 帧的时间（纳秒），收到 pong 时计算往返时间
+	correct    atomic.Bool  // 发送消息时按估计的偏差校正 Timestamp，见 SetClockSkewCorrection
+}
+
+// add 记录一个样本，返回当前估计
+func (s *clockSkew) add(serverMs, clientMs int64) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.samples = append(s.samples, clientMs-serverMs)
+	if len(s.samples) > clockSkewSamples {
+		s.samples = s.samples[len(s.samples)-clockSkewSamples:]
+	}
+	return s.estimateLocked()
+}
+
+// estimate 返回估计的偏差（正数表示本机时钟偏快），样本不足时 ok 为 false
+func (s *clockSkew) estimate() (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.estimateLocked()
+}
+
+func (s *clockSkew) estimateLocked() (time.Duration, bool) {
+	if len(s.samples) < clockSkewMinSamples {
+		return 0, false
+	}
+	return time.Duration(slices.Min(s.samples))*time.Millisecond - s.rtt/2, true
+}
+
+// reset 新连接的服务端可能不同，清空样本
+func (s *clockSkew) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.samples = nil
+	s.rtt = 0
+}
+
+// pingSent 记录发送 ping 帧的时间
+func (s *clockSkew) pingSent() {
+	s.pingSentAt.Store(time.Now().UnixNano())
+}
+
+// pongReceived 收到 pong 帧时记录往返时间
+func (s *clockSkew) pongReceived() {
+	sent := s.pingSentAt.Swap(0)
+	if sent == 0 {
+		return
+	}
+	s.mu.Lock()
+	s.rtt = time.Since(time.Unix(0, sent))
+	s.mu.Unlock()
+}
+
+// correctTimestamp 启用校正且已有估计时，将本机毫秒时间戳换算为服务端时钟
+func (s *clockSkew) correctTimestamp(ms int64) int64 {
+	if !s.correct.Load() {
+		return ms
+	}
+	if skew, ok := s.estimate(); ok {
+		return ms - skew.Milliseconds()
+	}
+	return ms
+}
+
+// SetClockSkewCorrection 设置发送消息时是否按估计的时钟偏差校正消息 Timestamp（默认关闭，只上报偏差）
+func (c *Client) SetClockSkewCorrection(enabled bool) {
+	c.clock.correct.Store(enabled)
+}
+
+// ClockSkew 返回估计的本机时钟与服务端时钟的偏差（正数表示本机时钟偏快），收到的 Ping 不足时 ok 为 false
+func (c *Client) ClockSkew() (skew time.Duration, ok bool) {
+	return c.clock.estimate()
+}
+
+// recordClockSample 记录服务端 Ping 的时间戳，偏差超出阈值或恢复时输出日志
+func (c *Client) recordClockSample(serverMs, clientMs int64) {
+	skew, ok := c.clock.add(serverMs, clientMs)
+	if !ok {
+		return
+	}
+	exceeded := skew.Abs() > clockSkewWarnThreshold
+
+	c.clock.mu.Lock()
+	previous := c.clock.warned
+	c.clock.warned = exceeded
+	c.clock.mu.Unlock()
+	switch {
+	case exceeded == previous:
+	case exceeded && skew > 0:
+		c.log("WARN", fmt.Sprintf("Local clock is about %v ahead of the server, step timestamps may be misleading", skew.Round(time.Second)))
+	case exceeded:
+		c.log("WARN", fmt.Sprintf("Local clock is about %v behind the server, step timestamps may be misleading", (-skew).Round(time.Second)))
+	default:
+		c.log("INFO", fmt.Sprintf("Local clock is in sync with the server (skew %v)", skew.Round(time.Millisecond)))
+	}
+}
+
+// probeClockSkew 将估计的时钟偏差写入心跳（样本不足时不上报）
+func (c *Client) probeClockSkew(heartbeat *WsHeartbeat) {
+	if skew, ok := c.clock.estimate(); ok {
+		ms := skew.Milliseconds()
+		heartbeat.ClockSkewMs = &ms
+	}
+}
@@ -0,0 +1,126 @@
+package grpc
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// countLogs 统计包含 substr 的指定级别日志条数
+func countLogs(client *Client, level, substr string) int {
+	n := 0
+	for _, entry := range client.GetLogs(0) {
+		if entry.Level == level && strings.Contains(entry.Message, substr) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestClockSkewEstimate(t *testing.T) {
+	var s clockSkew
+	const skew = 5 * 60 * 1000 // 本机时钟快 5 分钟
+	server := int64(1_700_000_000_000)
+	// 单程延迟 50、10、200ms：取延迟最小的样本
+	for i, delay := range []int64{50, 10} {
+		if _, ok := s.add(server+int64(i)*1000, server+int64(i)*1000+skew+delay); ok {
+			t.Fatal("样本不足时不应给出估计")
+		}
+	}
+	got, ok := s.add(server+2000, server+2000+skew+200)
+	if !ok || got != (skew+10)*time.Millisecond {
+		t.Fatalf("估计 = %v %v, 期望 %v", got, ok, (skew+10)*time.Millisecond)
+	}
+
+	// 减去往返时间的一半
+	s.rtt = 20 * time.Millisecond
+	if got, _ := s.estimate(); got != skew*time.Millisecond {
+		t.Errorf("扣除单程延迟后估计 = %v", got)
+	}
+
+	// 只保留最近的样本：旧的低延迟样本滑出窗口后估计随之更新
+	for i := 0; i < clockSkewSamples; i++ {
+		s.add(server, server-3000+100) // 本机时钟慢 3 秒，延迟 100ms
+	}
+	if got, _ := s.estimate(); got != -2910*time.Millisecond {
+		t.Errorf("窗口滑动后估计 = %v", got)
+	}
+
+	s.reset()
+	if _, ok := s.estimate(); ok {
+		t.Error("重置后不应有估计")
+	}
+}
+
+func TestClockSkewPingAndHeartbeat(t *testing.T) {
+	client := NewClient(nil)
+
+	// 样本不足时心跳不上报
+	client.handlePing("p0", &WsPing{Timestamp: time.Now().UnixMilli() - 120_000})
+	client.sendHeartbeat()
+	var hb *WsHeartbeat
+	for msg := nextMessage(client); msg != nil; msg = nextMessage(client) {
+		if msg.Pong != nil && msg.Pong.ServerTimestamp == 0 {
+			t.Errorf("pong 应回传服务端时间戳: %+v", msg.Pong)
+		}
+		if msg.Heartbeat != nil {
+			hb = msg.Heartbeat
+		}
+	}
+	if hb == nil || hb.ClockSkewMs != nil {
+		t.Fatalf("样本不足时不应上报时钟偏差: %+v", hb)
+	}
+
+	// 服务端时钟比本机慢 2 分钟（即本机快 2 分钟）
+	for i := 0; i < clockSkewMinSamples; i++ {
+		client.handlePing("p", &WsPing{Timestamp: time.Now().UnixMilli() - 120_000})
+	}
+	skew, ok := client.ClockSkew()
+	if !ok || skew < 119*time.Second || skew > 121*time.Second {
+		t.Fatalf("ClockSkew = %v %v", skew, ok)
+	}
+	if n := countLogs(client, "WARN", "ahead of the server"); n != 1 {
+		t.Errorf("超出阈值时应输出一次 WARN，实际 %d", n)
+	}
+	client.sendHeartbeat()
+	hb = nil
+	for msg := nextMessage(client); msg != nil; msg = nextMessage(client) {
+		if msg.Heartbeat != nil {
+			hb = msg.Heartbeat
+		}
+	}
+	if hb == nil || hb.ClockSkewMs == nil || *hb.ClockSkewMs < 119_000 || *hb.ClockSkewMs > 121_000 {
+		t.Fatalf("心跳应上报时钟偏差: %+v", hb)
+	}
+
+	// 默认不校正消息时间戳；开启后换算为服务端时钟
+	now := time.Now().UnixMilli()
+	client.sendMessage(&WsWorkerMessage{Timestamp: now, TaskResult: &WsTaskResult{TaskId: "t1"}})
+	if msg := nextMessage(client); msg.Timestamp != now {
+		t.Errorf("未开启校正时不应修改 Timestamp: %d != %d", msg.Timestamp, now)
+	}
+	client.SetClockSkewCorrection(true)
+	client.sendMessage(&WsWorkerMessage{Timestamp: now, TaskResult: &WsTaskResult{TaskId: "t2"}})
+	if msg := nextMessage(client); msg.Timestamp != now-skew.Milliseconds() {
+		t.Errorf("校正后 Timestamp = %d, 期望 %d", msg.Timestamp, now-skew.Milliseconds())
+	}
+
+	// 时钟恢复同步：旧样本滑出窗口后输出 INFO
+	for i := 0; i < clockSkewSamples; i++ {
+		client.handlePing("p", &WsPing{Timestamp: time.Now().UnixMilli()})
+	}
+	if skew, _ := client.ClockSkew(); skew.Abs() > time.Second {
+		t.Errorf("同步后 ClockSkew = %v", skew)
+	}
+	if countLogs(client, "INFO", "in sync with the server") != 1 || countLogs(client, "WARN", "ahead of the server") != 1 {
+		t.Error("恢复同步时应输出一次 INFO，且不重复 WARN")
+	}
+
+	// 本机时钟偏慢
+	for i := 0; i < clockSkewSamples; i++ {
+		client.recordClockSample(now+60_000, now)
+	}
+	if countLogs(client, "WARN", "behind the server") != 1 {
+		t.Error("本机时钟偏慢时应输出 WARN")
+	}
+}
@@ -3,9 +3,12 @@ package grpc
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
 	"github.com/zoeyai/zoeyworker/pkg/auto/window"
 	"github.com/zoeyai/zoeyworker/pkg/permissions"
+	"github.com/zoeyai/zoeyworker/pkg/plugin"
 	"github.com/zoeyai/zoeyworker/pkg/process"
 	"github.com/zoeyai/zoeyworker/pkg/uia"
 )
@@ -15,6 +18,17 @@ const (
 	RequestTypeGetApplications = "GET_APPLICATIONS"
 	RequestTypeGetWindows      = "GET_WINDOWS"
 	RequestTypeGetElements     = "GET_ELEMENTS"
+	RequestTypeGetDisplays     = "GET_DISPLAYS"
+	RequestTypeGetPlugins      = "GET_PLUGINS"
+
+	// 远程排查请求，可在任务执行期间调用
+	RequestTypeCaptureScreenshot = "CAPTURE_SCREENSHOT"
+	RequestTypeListWindows       = "LIST_WINDOWS"
+	RequestTypeListProcesses     = "LIST_PROCESSES"
+
+	// 插件安装：INSTALL_PLUGIN 由客户端在后台安装并随心跳上报进度，PLUGIN_STATUS 查询安装状态
+	RequestTypeInstallPlugin = "INSTALL_PLUGIN"
+	RequestTypePluginStatus  = "PLUGIN_STATUS"
 )
 
 // DataResponseResult 数据响应结果
@@ -56,13 +70,25 @@ func HandleDataRequest(requestType string, payloadJSON string) *DataResponseResu
 		payload = make(map[string]interface{})
 	}
 
-	switch requestType {
+	switch strings.ToUpper(requestType) {
 	case RequestTypeGetApplications:
 		return handleGetApplications()
 	case RequestTypeGetWindows:
 		return handleGetWindows(payload)
 	case RequestTypeGetElements:
 		return handleGetElements(payload)
+	case RequestTypeGetDisplays:
+		return handleGetDisplays()
+	case RequestTypeGetPlugins:
+		return handleGetPlugins()
+	case RequestTypeCaptureScreenshot:
+		return handleCaptureScreenshot(payload)
+	case RequestTypeListWindows:
+		return handleListWindows(payload)
+	case RequestTypeListProcesses:
+		return handleListProcesses(payload)
+	case RequestTypePluginStatus:
+		return handlePluginStatus(payload)
 	default:
 		return &DataResponseResult{
 			RequestType: requestType,
@@ -195,7 +221,7 @@ func handleGetWindows(payload map[string]interface{}) *DataResponseResult {
 
 	// 转换为 proto 格式
 	type WindowInfoOutput struct {
-		Handle    int    `json:"handle"`
+		Handle    int64  `json:"handle"`
 		Title     string `json:"title"`
 		ClassName string `json:"class_name"`
 		PID       int    `json:"pid"`
@@ -211,7 +237,7 @@ func handleGetWindows(payload map[string]interface{}) *DataResponseResult {
 	output := make([]WindowInfoOutput, 0, len(windows))
 	for _, win := range windows {
 		w := WindowInfoOutput{
-			Handle:    win.PID, // Go 版本使用 PID 作为句柄
+			Handle:    win.Handle, // 原生窗口句柄（不可用时为 0）
 			Title:     win.Title,
 			ClassName: "",           // robotgo 不提供 class_name
 			PID:       win.PID,
@@ -245,6 +271,52 @@ func handleGetWindows(payload map[string]interface{}) *DataResponseResult {
 	}
 }
 
+// handleGetDisplays 处理获取显示器列表请求
+// 返回每个显示器的序号（即任务 payload 中的 display_id）、全局坐标区域和缩放比例
+func handleGetDisplays() *DataResponseResult {
+	data, err := json.Marshal(map[string]interface{}{
+		"displays": screen.GetDisplays(),
+	})
+	if err != nil {
+		return &DataResponseResult{
+			RequestType: RequestTypeGetDisplays,
+			Success:     false,
+			Message:     fmt.Sprintf("JSON序列化失败: %v", err),
+			PayloadJSON: `{"displays":[]}`,
+		}
+	}
+
+	return &DataResponseResult{
+		RequestType: RequestTypeGetDisplays,
+		Success:     true,
+		Message:     "",
+		PayloadJSON: string(data),
+	}
+}
+
+// handleGetPlugins 处理获取插件列表请求
+// 返回每个可选插件的名称、安装状态、安装进度和文件路径
+func handleGetPlugins() *DataResponseResult {
+	data, err := json.Marshal(map[string]interface{}{
+		"plugins": plugin.ListInfo(),
+	})
+	if err != nil {
+		return &DataResponseResult{
+			RequestType: RequestTypeGetPlugins,
+			Success:     false,
+			Message:     fmt.Sprintf("JSON序列化失败: %v", err),
+			PayloadJSON: `{"plugins":[]}`,
+		}
+	}
+
+	return &DataResponseResult{
+		RequestType: RequestTypeGetPlugins,
+		Success:     true,
+		Message:     "",
+		PayloadJSON: string(data),
+	}
+}
+
 // handleGetElements 处理获取 UI 元素请求
 // 使用 Python 桥接支持 Windows UI Automation
 func handleGetElements(payload map[string]interface{}) *DataResponseResult {
@@ -258,17 +330,20 @@ func handleGetElements(payload map[string]interface{}) *DataResponseResult {
 		}
 	}
 
-	// 解析窗口句柄
-	windowHandle := 0
+	// 解析窗口标识：优先使用原生窗口句柄，否则按进程 ID 连接
+	var target uia.WindowTarget
 	if handle, ok := payload["window_handle"].(float64); ok {
-		windowHandle = int(handle)
+		target.Handle = int64(handle)
+	}
+	if pid, ok := payload["pid"].(float64); ok {
+		target.PID = int(pid)
 	}
 
-	if windowHandle == 0 {
+	if !target.IsValid() {
 		return &DataResponseResult{
 			RequestType: RequestTypeGetElements,
 			Success:     false,
-			Message:     "缺少有效的 window_handle 参数",
+			Message:     "缺少有效的 window_handle 或 pid 参数",
 			PayloadJSON: `{"elements":[]}`,
 		}
 	}
@@ -288,7 +363,7 @@ func handleGetElements(payload map[string]interface{}) *DataResponseResult {
 	}
 
 	// 获取元素
-	elements, err := uia.GetElements(windowHandle, opts)
+	elements, err := uia.GetElements(target, opts)
 	if err != nil {
 		return &DataResponseResult{
 			RequestType: RequestTypeGetElements,
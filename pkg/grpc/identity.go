@@ -0,0 +1,92 @@
+package grpc
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// clientIDFile 客户端标识文件（位于 DataDir）
+const clientIDFile = "client_id"
+
+// maxClientIDLength 客户端标识的最大长度（服务端可指定其他格式的标识）
+const maxClientIDLength = 128
+
+// newClientID 生成随机的 UUID（v4）
+func newClientID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// validClientID 标识非空、不超过 maxClientIDLength 且不含空白字符
+func validClientID(id string) bool {
+	return id != "" && len(id) <= maxClientIDLength && !strings.ContainsAny(id, " \t\r\n")
+}
+
+// loadClientID 读取 dir 中保存的客户端标识，不存在或无效时生成新的标识并保存；
+// dir 为空时只生成（每次启动不同）。保存失败时仍返回新标识和错误
+func loadClientID(dir string) (string, error) {
+	if dir == "" {
+		return newClientID(), nil
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, clientIDFile)); err == nil {
+		if id := strings.TrimSpace(string(data)); validClientID(id) {
+			return id, nil
+		}
+	}
+	id := newClientID()
+	return id, saveClientID(dir, id)
+}
+
+// saveClientID 保存客户端标识（先写临时文件再重命名，避免写入中断留下不完整的标识）
+func saveClientID(dir, id string) error {
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	path := filepath.Join(dir, clientIDFile)
+	if err := os.WriteFile(path+".tmp", []byte(id+"\n"), 0600); err != nil {
+		return fmt.Errorf("保存客户端标识失败: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("保存客户端标识失败: %w", err)
+	}
+	return nil
+}
+
+// ClientID 客户端标识：首次运行时生成并保存在 DataDir，重启后不变，连接时发送给服务端用于关联同一台机器
+func (c *Client) ClientID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clientID
+}
+
+// adoptClientID 使用服务端在认证响应中指定的标识（与当前不同且有效时），并保存供之后的连接使用
+func (c *Client) adoptClientID(id string) {
+	if id == "" {
+		return
+	}
+	if !validClientID(id) {
+		c.log("WARN", fmt.Sprintf("Ignoring invalid clientId from server: %q", id))
+		return
+	}
+	c.mu.Lock()
+	previous := c.clientID
+	c.clientID = id
+	c.mu.Unlock()
+
+	if previous == id {
+		return
+	}
+	c.log("INFO", fmt.Sprintf("Server assigned clientId %s (was %s)", id, previous))
+	if err := saveClientID(c.config.DataDir, id); err != nil {
+		c.log("WARN", err.Error())
+	}
+}
@@ -0,0 +1,95 @@
+package grpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestClientIDPersisted(t *testing.T) {
+	dir := t.TempDir()
+	id := NewClient(&ClientConfig{DataDir: dir}).ClientID()
+	if !uuidPattern.MatchString(id) {
+		t.Fatalf("应生成 UUID, 实际 %q", id)
+	}
+	if again := NewClient(&ClientConfig{DataDir: dir}).ClientID(); again != id {
+		t.Errorf("重启后标识应不变: %s != %s", again, id)
+	}
+
+	// 文件损坏时重新生成
+	os.WriteFile(filepath.Join(dir, clientIDFile), []byte("bad id\n"), 0600)
+	if regenerated := NewClient(&ClientConfig{DataDir: dir}).ClientID(); regenerated == id || !uuidPattern.MatchString(regenerated) {
+		t.Errorf("无效标识应重新生成, 实际 %q", regenerated)
+	}
+
+	if a, b := NewClient(nil).ClientID(), NewClient(nil).ClientID(); a == b || a == "" {
+		t.Errorf("未设置 DataDir 时每次生成新标识: %q %q", a, b)
+	}
+}
+
+func TestClientIDSentAndOverridden(t *testing.T) {
+	dir := t.TempDir()
+	client := NewClient(&ClientConfig{HeartbeatInterval: 60, DataDir: dir})
+	original := client.ClientID()
+
+	// 认证完成前产生的消息携带客户端标识
+	client.handlePing("ping_1", &WsPing{})
+	if msg := nextMessage(client); msg.ClientId != original {
+		t.Errorf("未认证时消息应携带 clientId, 实际 %q", msg.ClientId)
+	}
+
+	connects := make(chan WsConnectMessage, 1)
+	received := make(chan *WsWorkerMessage, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var connect WsConnectMessage
+		conn.ReadJSON(&connect)
+		connects <- connect
+		conn.WriteJSON(WsConnectResponse{Type: "connect_response", Success: true, AgentId: "agent", ClientId: "server-assigned"})
+		for {
+			var msg WsWorkerMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg.Heartbeat == nil {
+				received <- &msg
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	connectTo(t, client, server)
+	if connect := <-connects; connect.ClientId != original {
+		t.Errorf("连接消息应携带 clientId %s, 实际 %q", original, connect.ClientId)
+	}
+
+	// 服务端指定的标识立即生效并保存
+	if id := client.ClientID(); id != "server-assigned" {
+		t.Errorf("应使用服务端指定的标识, 实际 %q", id)
+	}
+	if id := NewClient(&ClientConfig{DataDir: dir}).ClientID(); id != "server-assigned" {
+		t.Errorf("服务端指定的标识应保存, 重启后为 %q", id)
+	}
+
+	// 认证后消息使用 agentId
+	client.handlePing("ping_1", &WsPing{})
+	if msg := nextWorkerMessage(t, received); msg.ClientId != "" || msg.AgentId != "agent" {
+		t.Errorf("认证后消息不应携带 clientId, 实际 %+v", msg)
+	}
+
+	// 无效的标识被忽略
+	client.adoptClientID("bad id")
+	if id := client.ClientID(); id != "server-assigned" {
+		t.Errorf("无效标识应忽略, 实际 %q", id)
+	}
+}
@@ -9,6 +9,10 @@ type WsConnectMessage struct {
 	AccessKey  string        `json:"accessKey"`
 	SecretKey  string        `json:"secretKey"`
 	SystemInfo *WsSystemInfo `json:"systemInfo,omitempty"`
+	// Features 客户端支持的协议特性（如 resultAck），旧服务端忽略
+	Features []string `json:"features,omitempty"`
+	// ClientId 客户端标识，重启后不变，服务端据此关联同一台机器
+	ClientId string `json:"clientId,omitempty"`
 }
 
 // WsSystemInfo 系统信息（JSON）
@@ -26,6 +30,27 @@ type WsCapabilities struct {
 	PythonAvailable bool   `json:"pythonAvailable"`
 	PythonVersion   string `json:"pythonVersion,omitempty"`
 	PythonPath      string `json:"pythonPath,omitempty"`
+	// OcrProvider 配置的 OCR 执行提供者，OcrActiveProvider 为实际使用的
+	OcrProvider       string `json:"ocrProvider,omitempty"`
+	OcrActiveProvider string `json:"ocrActiveProvider,omitempty"`
+	// OcrInstalled OCR 插件已安装（未安装时不能执行 click_text 等 OCR 任务）
+	OcrInstalled    bool   `json:"ocrInstalled"`
+	OcrModelVersion string `json:"ocrModelVersion,omitempty"`
+	// UiaAvailable 支持 UI Automation（click_native 等任务）
+	UiaAvailable bool `json:"uiaAvailable"`
+	// DisplayCount 显示器数量，ScreenWidth/ScreenHeight/ScaleFactor 为主显示器的分辨率和缩放比例
+	DisplayCount int     `json:"displayCount"`
+	ScreenWidth  int     `json:"screenWidth,omitempty"`
+	ScreenHeight int     `json:"screenHeight,omitempty"`
+	ScaleFactor  float64 `json:"scaleFactor,omitempty"`
+	// Permissions macOS 授权状态，其他系统不上报
+	Permissions *WsPermissions `json:"permissions,omitempty"`
+}
+
+// WsPermissions macOS 授权状态
+type WsPermissions struct {
+	Accessibility   bool `json:"accessibility"`
+	ScreenRecording bool `json:"screenRecording"`
 }
 
 // WsConnectResponse 认证响应
@@ -35,6 +60,10 @@ type WsConnectResponse struct {
 	Message   string `json:"message"`
 	AgentId   string `json:"agentId"`
 	AgentName string `json:"agentName"`
+	// Features 服务端启用的协议特性，未返回时按旧协议通信
+	Features []string `json:"features,omitempty"`
+	// ClientId 服务端指定的客户端标识，与连接消息中的不同时客户端改用并保存
+	ClientId string `json:"clientId,omitempty"`
 }
 
 // WsServerMessage 服务端消息
@@ -45,6 +74,14 @@ type WsServerMessage struct {
 	CancelTask  *WsCancelTask  `json:"cancelTask,omitempty"`
 	Ping        *WsPing        `json:"ping,omitempty"`
 	DataRequest *WsDataRequest `json:"dataRequest,omitempty"`
+	ResultAck   *WsResultAck   `json:"resultAck,omitempty"`
+	// UpdateSettings 服务端下发的运行设置，处理结果以 DataResponse（requestType UPDATE_SETTINGS）回复
+	UpdateSettings *WsUpdateSettings `json:"updateSettings,omitempty"`
+	// StartScreenStream 开始屏幕流，处理结果以 DataResponse（requestType START_SCREEN_STREAM）回复
+	StartScreenStream *WsStartScreenStream `json:"startScreenStream,omitempty"`
+	StopScreenStream  *WsStopScreenStream  `json:"stopScreenStream,omitempty"`
+	// InputEvent 远程输入事件，处理结果以 DataResponse（requestType INPUT_EVENT）回复
+	InputEvent *WsInputEvent `json:"inputEvent,omitempty"`
 }
 
 // WsExecuteTask 执行任务命令
@@ -65,6 +102,58 @@ type WsPing struct {
 	Timestamp int64 `json:"timestamp"`
 }
 
+// WsResultAck 任务结果确认，MessageId 为所确认 taskResult 消息的 messageId
+type WsResultAck struct {
+	MessageId string `json:"messageId"`
+}
+
+// WsUpdateSettings 服务端下发的运行设置，未设置（零值）的字段不修改
+type WsUpdateSettings struct {
+	HeartbeatIntervalSeconds int    `json:"heartbeatIntervalSeconds,omitempty"`
+	LogLevel                 string `json:"logLevel,omitempty"`
+	ScreenshotQuality        int    `json:"screenshotQuality,omitempty"`
+	// Persist 为 true 时保存到配置文件，重启后仍然生效
+	Persist bool `json:"persist,omitempty"`
+}
+
+// WsStartScreenStream 开始屏幕流（远程查看执行过程），未设置（零值）的字段使用默认值
+type WsStartScreenStream struct {
+	StreamId        string  `json:"streamId"`
+	Fps             float64 `json:"fps,omitempty"`
+	MaxWidth        int     `json:"maxWidth,omitempty"`
+	Quality         int     `json:"quality,omitempty"`
+	DurationSeconds int     `json:"durationSeconds,omitempty"`
+}
+
+// WsStopScreenStream 停止屏幕流
+type WsStopScreenStream struct {
+	StreamId string `json:"streamId"`
+}
+
+// WsInputEvent 远程输入事件（远程控制），需要开启 remote_control 且屏幕流进行中
+type WsInputEvent struct {
+	// StreamId 进行中的屏幕流
+	StreamId string `json:"streamId"`
+	// Type 事件类型：move、click、key、type、scroll
+	Type string `json:"type"`
+	// X、Y 屏幕截图坐标（move、click）
+	X int `json:"x,omitempty"`
+	Y int `json:"y,omitempty"`
+	// Button 鼠标按键：left（默认）、right、middle
+	Button string `json:"button,omitempty"`
+	Double bool   `json:"double,omitempty"`
+	// Key 按键名（key），Modifiers 同时按住的修饰键（key、click）
+	Key       string   `json:"key,omitempty"`
+	Modifiers []string `json:"modifiers,omitempty"`
+	// Text 输入的文字（type）
+	Text string `json:"text,omitempty"`
+	// DeltaX、DeltaY 滚动量（scroll）
+	DeltaX int `json:"deltaX,omitempty"`
+	DeltaY int `json:"deltaY,omitempty"`
+	// Force 任务执行期间仍然执行
+	Force bool `json:"force,omitempty"`
+}
+
 // WsDataRequest 数据查询请求
 type WsDataRequest struct {
 	RequestType string `json:"requestType"`
@@ -76,12 +165,31 @@ type WsWorkerMessage struct {
 	MessageId    string          `json:"messageId"`
 	Timestamp    int64           `json:"timestamp"`
 	AgentId      string          `json:"agentId,omitempty"`
+	ClientId     string          `json:"clientId,omitempty"` // 客户端标识，认证完成前（AgentId 为空）产生的消息携带
 	TaskAck      *WsTaskAck      `json:"taskAck,omitempty"`
 	TaskProgress *WsTaskProgress `json:"taskProgress,omitempty"`
 	TaskResult   *WsTaskResult   `json:"taskResult,omitempty"`
 	Pong         *WsPong         `json:"pong,omitempty"`
 	DataResponse *WsDataResponse `json:"dataResponse,omitempty"`
 	Heartbeat    *WsHeartbeat    `json:"heartbeat,omitempty"`
+	ScreenFrame  *WsScreenFrame  `json:"screenFrame,omitempty"`
+}
+
+// WsScreenFrame 屏幕流的一帧；Ended 为 true 时表示屏幕流已结束，不含图像
+type WsScreenFrame struct {
+	StreamId string `json:"streamId"`
+	Seq      int64  `json:"seq"`
+	Image    string `json:"image,omitempty"` // JPEG data URL
+	Width    int    `json:"width,omitempty"`
+	Height   int    `json:"height,omitempty"`
+	// Scale 帧像素 / 屏幕截图像素，远程输入的坐标为帧坐标除以 Scale
+	Scale float64 `json:"scale,omitempty"`
+	Ended bool    `json:"ended,omitempty"`
+	// Reason 结束原因：stopped、duration、replaced 或 capture_failed
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Dropped 因上一帧尚未发出而跳过的帧数（结束时上报）
+	Dropped int `json:"dropped,omitempty"`
 }
 
 // WsTaskAck 任务确认
@@ -114,7 +222,44 @@ type WsTaskResult struct {
 	MatchLocation *WsMatchLocation `json:"matchLocation,omitempty"`
 }
 
-// WsMatchLocation 匹配位置
+// ResultTypeCase 用例中间结果标记（WsCaseResult.ResultType）
+const ResultTypeCase = "case"
+
+// WsCaseResult 用例中间结果，序列化在 WsTaskResult.ResultJson 中
+// execute_plan 的 payload 设置 stream_case_results: true 时，每个用例完成后发送一次；
+// 所在 WsTaskResult 的 taskId 为 "case_<caseExecutionId>"，不代表计划任务结束，
+// 计划的最终结果仍以原 taskId 发送
+type WsCaseResult struct {
+	ResultType      string `json:"result_type"` // 固定为 "case"
+	TaskID          string `json:"task_id"`     // 所属计划任务 ID
+	PlanExecutionID string `json:"plan_execution_id"`
+	CaseExecutionID string `json:"case_execution_id"`
+	CaseID          string `json:"case_id"`
+	Success         bool   `json:"success"`
+	TotalSteps      int    `json:"total_steps"`
+	PassedSteps     int    `json:"passed_steps"`
+	FailedSteps     int    `json:"failed_steps"`
+	DurationMs      int64  `json:"duration_ms"`
+	ErrorMessage    string `json:"error_message,omitempty"`
+
+	DismissedDialogs []WsDismissedDialog `json:"dismissed_dialogs,omitempty"` // 用例执行期间弹窗监视处理的弹窗（仅供参考，不影响结果）
+}
+
+// WsDismissedDialog 弹窗监视（dialog_watcher）处理的一个弹窗
+type WsDismissedDialog struct {
+	Rule        string `json:"rule"`   // 命中的规则名称
+	Action      string `json:"action"` // click / escape / close_window
+	CaseID      string `json:"case_id,omitempty"`
+	X           int    `json:"x"` // 弹窗定位目标的边界（屏幕坐标）
+	Y           int    `json:"y"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	MatchedText string `json:"matched_text,omitempty"`
+	Timestamp   int64  `json:"timestamp"`       // 处理时间（毫秒时间戳）
+	Error       string `json:"error,omitempty"` // 处理失败的原因（如未找到按钮）
+}
+
+// WsMatchLocation 匹配位置：X、Y 为目标边界的左上角（屏幕坐标），Width、Height 为目标的宽高
 type WsMatchLocation struct {
 	X          int32   `json:"x"`
 	Y          int32   `json:"y"`
@@ -141,6 +286,50 @@ type WsDataResponse struct {
 type WsHeartbeat struct {
 	ResourceInfo *WsResourceInfo `json:"resourceInfo,omitempty"`
 	AgentStatus  *WsAgentStatus  `json:"agentStatus,omitempty"`
+	// ScreenAvailable 能否截图（未设置检查时不上报），不可用时 ScreenError 为原因
+	ScreenAvailable *bool  `json:"screenAvailable,omitempty"`
+	ScreenError     string `json:"screenError,omitempty"`
+	// Display 主显示器当前的分辨率（未设置检查时不上报）
+	Display *WsDisplayInfo `json:"display,omitempty"`
+	// Outbox 发送队列统计
+	Outbox *WsOutboxStats `json:"outbox,omitempty"`
+	// Capabilities 与上次上报（连接消息或心跳）相比发生变化时的完整能力信息，未变化时不上报
+	Capabilities *WsCapabilities `json:"capabilities,omitempty"`
+	// Plugins 服务端触发的插件安装进度：安装期间每次心跳上报，结束后上报一次最终状态
+	Plugins []*WsPluginStatus `json:"plugins,omitempty"`
+	// ClockSkewMs 根据服务端 Ping 估计的本机时钟减服务端时钟（毫秒，正数表示本机时钟偏快），收到的 Ping 不足时不上报
+	ClockSkewMs *int64 `json:"clockSkewMs,omitempty"`
+}
+
+// WsDisplayInfo 显示器分辨率
+type WsDisplayInfo struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// ScaleFactor 缩放比例（截图像素 / 鼠标输入坐标，macOS Retina 通常为 2）
+	ScaleFactor float64 `json:"scaleFactor"`
+}
+
+// WsPluginStatus 插件安装状态
+type WsPluginStatus struct {
+	Name       string  `json:"name"`
+	Installed  bool    `json:"installed"`
+	Installing bool    `json:"installing"`
+	Progress   float64 `json:"progress"`
+	Phase      string  `json:"phase,omitempty"`
+	// Error 安装失败的原因
+	Error string `json:"error,omitempty"`
+}
+
+// WsOutboxStats 发送队列统计
+type WsOutboxStats struct {
+	// Queued 待发送的任务消息数（内存和磁盘）
+	Queued int64 `json:"queued"`
+	// Spilled 累计溢出到磁盘的任务消息数
+	Spilled int64 `json:"spilled"`
+	// Dropped 累计丢弃的消息数（队列已满的心跳等，或无法写入磁盘的任务消息）
+	Dropped int64 `json:"dropped"`
+	// Unacked 已发送但服务端尚未确认的任务结果数（服务端支持 resultAck 时）
+	Unacked int64 `json:"unacked"`
 }
 
 // WsResourceInfo 资源信息
@@ -152,9 +341,12 @@ type WsResourceInfo struct {
 
 // WsAgentStatus Agent 状态
 type WsAgentStatus struct {
-	Status            string `json:"status"`
-	CurrentTaskId     string `json:"currentTaskId,omitempty"`
-	CurrentTaskType   string `json:"currentTaskType,omitempty"`
-	TaskStartedAt     int64  `json:"taskStartedAt,omitempty"`
-	RunningTasksCount int32  `json:"runningTasksCount"`
+	Status            string   `json:"status"`
+	CurrentTaskId     string   `json:"currentTaskId,omitempty"`
+	CurrentTaskType   string   `json:"currentTaskType,omitempty"`
+	TaskStartedAt     int64    `json:"taskStartedAt,omitempty"`
+	RunningTasksCount int32    `json:"runningTasksCount"`
+	RunningTaskIds    []string `json:"runningTaskIds,omitempty"`
+	// ScreenLocked 屏幕似乎已锁定（平台报告锁屏或截图全黑），依赖屏幕内容的步骤会立即失败
+	ScreenLocked bool `json:"screenLocked,omitempty"`
 }
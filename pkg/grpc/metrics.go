@@ -0,0 +1,10 @@
+package grpc
+
+import "github.com/zoeyai/zoeyworker/pkg/metrics"
+
+var (
+	// zoey_reconnects_total 连接异常断开后的重连尝试次数
+	reconnectsTotal = metrics.NewCounter("zoey_reconnects_total", "Reconnect attempts after the connection was lost.")
+	// zoey_send_queue_depth 发送队列中待发送的任务消息数（含溢出到磁盘的消息）
+	sendQueueDepth = metrics.NewGauge("zoey_send_queue_depth", "Task messages waiting in the send queue, including messages spilled to disk.")
+)
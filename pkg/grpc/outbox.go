@@ -0,0 +1,295 @@
+package grpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// outboxMemoryLimit 内存中最多保留的任务消息数，超出后溢出到磁盘
+	outboxMemoryLimit = 100
+	// outboxSegmentSize 每个溢出文件最多保存的消息数
+	outboxSegmentSize = 100
+	// outboxTransientLimit 心跳、pong 等可丢弃消息的队列长度，满时丢弃新消息
+	outboxTransientLimit = 100
+)
+
+// outbox 发送队列：任务消息（TaskAck / TaskProgress / TaskResult）按顺序至少送达一次，
+// 内存队列满时追加到 dir 下的溢出文件（每行一条 JSON），发送成功（ack）后才移出队列；
+// 其他消息满时直接丢弃。dir 为空时不溢出，内存队列满后丢弃任务消息
+type outbox struct {
+	dir string
+
+	mu sync.Mutex
+	// tasks 内存中的任务消息，位于所有溢出文件之前
+	tasks []*WsWorkerMessage
+	// segments 溢出文件（从旧到新），writer 为正在追加的最新文件
+	segments      []string
+	segmentQueued []int
+	writer        *os.File
+	writerCount   int
+	nextSegment   int64
+	transient     []*WsWorkerMessage
+	stats         WsOutboxStats
+	// ready 有新消息时通知发送协程（容量 1，不阻塞）
+	ready chan struct{}
+}
+
+// newOutbox 创建发送队列，dir 下上次异常退出时残留的溢出文件在连接后优先发送
+func newOutbox(dir string) (*outbox, error) {
+	o := &outbox{dir: dir, nextSegment: time.Now().UnixNano(), ready: make(chan struct{}, 1)}
+	if dir == "" {
+		return o, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return o, nil
+	}
+	if err != nil {
+		return o, fmt.Errorf("读取发送队列目录失败: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".jsonl") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		n, err := countLines(path)
+		if err != nil {
+			return o, err
+		}
+		o.segments = append(o.segments, path)
+		o.segmentQueued = append(o.segmentQueued, n)
+		o.stats.Queued += int64(n)
+	}
+	return o, nil
+}
+
+// countLines 统计溢出文件中的消息数
+func countLines(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	n := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<30)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) > 0 {
+			n++
+		}
+	}
+	return n, scanner.Err()
+}
+
+// isReliable 是否为需要保证送达的任务消息
+func isReliable(msg *WsWorkerMessage) bool {
+	return msg.TaskResult != nil || msg.TaskAck != nil || msg.TaskProgress != nil
+}
+
+// push 加入队列，返回是否被丢弃（err 为任务消息写入磁盘失败的原因）
+func (o *outbox) push(msg *WsWorkerMessage) (dropped bool, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	defer o.notify()
+	defer o.updateDepth()
+
+	if !isReliable(msg) {
+		if len(o.transient) >= outboxTransientLimit {
+			o.stats.Dropped++
+			return true, nil
+		}
+		o.transient = append(o.transient, msg)
+		return false, nil
+	}
+
+	// 已有溢出文件时新消息也写入磁盘，保证顺序
+	if len(o.segments) == 0 && len(o.tasks) < outboxMemoryLimit {
+		o.tasks = append(o.tasks, msg)
+		o.stats.Queued++
+		return false, nil
+	}
+	if o.dir == "" {
+		o.stats.Dropped++
+		return true, nil
+	}
+	if err := o.spill(msg); err != nil {
+		o.stats.Dropped++
+		return true, err
+	}
+	o.stats.Queued++
+	o.stats.Spilled++
+	return false, nil
+}
+
+// spill 将任务消息追加到最新的溢出文件（调用方持有 mu）
+func (o *outbox) spill(msg *WsWorkerMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %w", err)
+	}
+	if o.writer == nil || o.writerCount >= outboxSegmentSize {
+		if err := o.rotate(); err != nil {
+			return err
+		}
+	}
+	if _, err := o.writer.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入溢出文件失败: %w", err)
+	}
+	o.writerCount++
+	o.segmentQueued[len(o.segmentQueued)-1]++
+	return nil
+}
+
+// rotate 关闭当前溢出文件并创建新文件（调用方持有 mu）
+func (o *outbox) rotate() error {
+	o.closeWriter()
+	if err := os.MkdirAll(o.dir, 0o700); err != nil {
+		return fmt.Errorf("创建发送队列目录失败: %w", err)
+	}
+	o.nextSegment++
+	path := filepath.Join(o.dir, fmt.Sprintf("%020d.jsonl", o.nextSegment))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("创建溢出文件失败: %w", err)
+	}
+	o.writer = f
+	o.writerCount = 0
+	o.segments = append(o.segments, path)
+	o.segmentQueued = append(o.segmentQueued, 0)
+	return nil
+}
+
+// closeWriter 关闭正在追加的溢出文件（调用方持有 mu）
+func (o *outbox) closeWriter() {
+	if o.writer != nil {
+		o.writer.Close()
+		o.writer = nil
+	}
+}
+
+// load 内存队列为空时读入最旧的溢出文件并删除（调用方持有 mu），返回无法解析而丢弃的行数
+func (o *outbox) load() (int, error) {
+	path := o.segments[0]
+	if len(o.segments) == 1 {
+		o.closeWriter()
+	}
+	queued := o.segmentQueued[0]
+	o.segments = o.segments[1:]
+	o.segmentQueued = o.segmentQueued[1:]
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		o.stats.Queued -= int64(queued)
+		o.stats.Dropped += int64(queued)
+		return queued, fmt.Errorf("读取溢出文件失败: %w", err)
+	}
+	os.Remove(path)
+
+	corrupt := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		var msg WsWorkerMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			corrupt++
+			continue
+		}
+		o.tasks = append(o.tasks, &msg)
+	}
+	// 以实际读入的消息数为准（上次异常退出时可能只写了半行）
+	o.stats.Queued += int64(len(o.tasks) - queued)
+	o.stats.Dropped += int64(corrupt)
+	return corrupt, nil
+}
+
+// peek 返回下一条待发送的消息（不移出队列），任务消息优先；队列为空时返回 nil
+func (o *outbox) peek() (*WsWorkerMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var err error
+	if len(o.tasks) == 0 && len(o.segments) > 0 {
+		var corrupt int
+		if corrupt, err = o.load(); err == nil && corrupt > 0 {
+			err = fmt.Errorf("溢出文件中有 %d 条消息无法解析，已丢弃", corrupt)
+		}
+		o.updateDepth()
+	}
+	if len(o.tasks) > 0 {
+		return o.tasks[0], err
+	}
+	if len(o.transient) > 0 {
+		return o.transient[0], err
+	}
+	return nil, err
+}
+
+// ack 消息已发送，移出队列
+func (o *outbox) ack(msg *WsWorkerMessage) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.tasks) > 0 && o.tasks[0] == msg {
+		o.tasks[0] = nil
+		o.tasks = o.tasks[1:]
+		o.stats.Queued--
+		o.updateDepth()
+	} else if len(o.transient) > 0 && o.transient[0] == msg {
+		o.transient[0] = nil
+		o.transient = o.transient[1:]
+	}
+}
+
+// requeue 将消息放回任务队列队首（未确认的任务结果重发），保持原有顺序
+func (o *outbox) requeue(msgs []*WsWorkerMessage) {
+	if len(msgs) == 0 {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	defer o.notify()
+	defer o.updateDepth()
+	o.tasks = append(slices.Clone(msgs), o.tasks...)
+	o.stats.Queued += int64(len(msgs))
+}
+
+// dropTransient 丢弃积压的心跳、pong 等消息（重连后已过时），任务消息保留
+func (o *outbox) dropTransient() {
+	o.mu.Lock()
+	o.transient = nil
+	o.mu.Unlock()
+}
+
+// snapshot 返回当前统计
+func (o *outbox) snapshot() WsOutboxStats {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.stats
+}
+
+// updateDepth 更新发送队列长度指标（调用方持有 mu）
+func (o *outbox) updateDepth() {
+	sendQueueDepth.Set(float64(o.stats.Queued))
+}
+
+// notify 唤醒发送协程
+func (o *outbox) notify() {
+	select {
+	case o.ready <- struct{}{}:
+	default:
+	}
+}
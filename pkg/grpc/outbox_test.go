@@ -0,0 +1,204 @@
+package grpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// nextMessage 取出队首消息
+func nextMessage(client *Client) *WsWorkerMessage {
+	msg, _ := client.outbox.peek()
+	if msg != nil {
+		client.outbox.ack(msg)
+	}
+	return msg
+}
+
+func taskResult(i int) *WsWorkerMessage {
+	return &WsWorkerMessage{TaskResult: &WsTaskResult{TaskId: fmt.Sprintf("task-%d", i)}}
+}
+
+// drain 按顺序取出所有任务结果的 TaskId
+func drain(t *testing.T, o *outbox) []string {
+	t.Helper()
+	var ids []string
+	for {
+		msg, err := o.peek()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg == nil {
+			return ids
+		}
+		if msg.TaskResult != nil {
+			ids = append(ids, msg.TaskResult.TaskId)
+		}
+		o.ack(msg)
+	}
+}
+
+func checkOrder(t *testing.T, ids []string, n int) {
+	t.Helper()
+	if len(ids) != n {
+		t.Fatalf("应取出 %d 条任务结果, 实际 %d", n, len(ids))
+	}
+	for i, id := range ids {
+		if id != fmt.Sprintf("task-%d", i) {
+			t.Fatalf("第 %d 条为 %s，顺序错误", i, id)
+		}
+	}
+}
+
+func TestOutboxSpillsInOrder(t *testing.T) {
+	dir := t.TempDir()
+	o, err := newOutbox(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 250 {
+		if dropped, err := o.push(taskResult(i)); dropped || err != nil {
+			t.Fatalf("任务结果不应被丢弃: %v", err)
+		}
+	}
+	if stats := o.snapshot(); stats.Queued != 250 || stats.Spilled != 150 || stats.Dropped != 0 {
+		t.Errorf("统计错误: %+v", stats)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if len(files) != 2 {
+		t.Errorf("150 条溢出消息应写入 2 个文件, 实际 %d", len(files))
+	}
+
+	checkOrder(t, drain(t, o), 250)
+
+	// 读取溢出文件期间继续写入的消息排在后面
+	var ids []string
+	for i := range 250 {
+		o.push(taskResult(i))
+	}
+	for len(ids) < 120 {
+		msg, _ := o.peek()
+		ids = append(ids, msg.TaskResult.TaskId)
+		o.ack(msg)
+	}
+	for i := 250; i < 300; i++ {
+		o.push(taskResult(i))
+	}
+	checkOrder(t, append(ids, drain(t, o)...), 300)
+
+	if stats := o.snapshot(); stats.Queued != 0 {
+		t.Errorf("发送完后队列应为空: %+v", stats)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.jsonl")); len(files) != 0 {
+		t.Errorf("发送完后溢出文件应删除, 剩余 %v", files)
+	}
+}
+
+func TestOutboxRestoresSpilledMessages(t *testing.T) {
+	dir := t.TempDir()
+	o, _ := newOutbox(dir)
+	for i := range 150 {
+		o.push(taskResult(i))
+	}
+	o.closeWriter()
+
+	// 模拟重启：内存中的 100 条丢失，磁盘上的 50 条恢复
+	restored, err := newOutbox(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := restored.snapshot(); stats.Queued != 50 {
+		t.Errorf("应恢复 50 条消息, 实际 %+v", stats)
+	}
+	restored.push(taskResult(150))
+	ids := drain(t, restored)
+	if len(ids) != 51 || ids[0] != "task-100" || ids[50] != "task-150" {
+		t.Errorf("恢复的消息应在新消息之前发送, 实际 %v", ids)
+	}
+}
+
+func TestOutboxDropsTransient(t *testing.T) {
+	o, _ := newOutbox("")
+	for range outboxTransientLimit {
+		o.push(&WsWorkerMessage{Heartbeat: &WsHeartbeat{}})
+	}
+	if dropped, _ := o.push(&WsWorkerMessage{Heartbeat: &WsHeartbeat{}}); !dropped {
+		t.Error("心跳队列满时应丢弃")
+	}
+
+	// 任务消息优先于心跳发送
+	o.push(taskResult(0))
+	if msg, _ := o.peek(); msg.TaskResult == nil {
+		t.Error("任务消息应优先发送")
+	}
+	o.dropTransient()
+	if ids := drain(t, o); len(ids) != 1 {
+		t.Errorf("dropTransient 不应丢弃任务消息, 实际 %v", ids)
+	}
+
+	// 未设置目录时任务消息在内存队列满后丢弃
+	for i := range outboxMemoryLimit + 1 {
+		o.push(taskResult(i))
+	}
+	if stats := o.snapshot(); stats.Queued != outboxMemoryLimit || stats.Dropped != 2 || stats.Spilled != 0 {
+		t.Errorf("统计错误: %+v", stats)
+	}
+}
+
+func TestOutboxFlushesQueuedResultsOnConnect(t *testing.T) {
+	var mu sync.Mutex
+	var ids []string
+	var heartbeat *WsHeartbeat
+	server, _ := newAgentServer(t, func(n int32, conn *websocket.Conn) {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg WsWorkerMessage
+			json.Unmarshal(data, &msg)
+			mu.Lock()
+			if msg.TaskResult != nil {
+				ids = append(ids, msg.TaskResult.TaskId)
+			} else if msg.Heartbeat != nil {
+				heartbeat = msg.Heartbeat
+			}
+			mu.Unlock()
+		}
+	})
+
+	// 断开期间产生的任务结果溢出到磁盘，连接后按顺序发送，积压的心跳被丢弃
+	client := NewClient(&ClientConfig{HeartbeatInterval: 60, OutboxDir: t.TempDir()})
+	for i := range 150 {
+		client.sendMessage(taskResult(i))
+	}
+	client.sendHeartbeat()
+	if err := client.Connect(strings.TrimPrefix(server.URL, "http://"), "ak", "sk"); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect()
+
+	waitFor(t, "积压的任务结果发送完", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(ids) == 150
+	})
+	client.sendHeartbeat()
+	waitFor(t, "心跳上报发送队列统计", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return heartbeat != nil
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	checkOrder(t, ids, 150)
+	if stats := heartbeat.Outbox; stats == nil || stats.Queued != 0 || stats.Spilled != 50 {
+		t.Errorf("心跳应包含发送队列统计, 实际 %+v", stats)
+	}
+}
@@ -0,0 +1,112 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/zoeyai/zoeyworker/pkg/plugin"
+)
+
+// pluginInstalls 服务端触发的插件安装（同一插件同一时间只有一个）
+type pluginInstalls struct {
+	mu sync.Mutex
+	// running 进行中的安装
+	running map[string]plugin.Plugin
+	// finished 已结束但尚未随心跳上报的安装结果
+	finished []*WsPluginStatus
+}
+
+// start 登记插件安装，已在安装（包括 GUI 发起的安装）时返回 false
+func (p *pluginInstalls) start(name string, pl plugin.Plugin) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.running[name] != nil || pl.Status().Installing {
+		return false
+	}
+	if p.running == nil {
+		p.running = make(map[string]plugin.Plugin)
+	}
+	p.running[name] = pl
+	return true
+}
+
+// finish 记录安装结果，下一次心跳上报
+func (p *pluginInstalls) finish(name string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status := toWsPluginStatus(p.running[name].Status())
+	if err != nil {
+		status.Error = err.Error()
+	}
+	delete(p.running, name)
+	p.finished = append(p.finished, status)
+}
+
+// report 返回进行中安装的当前进度和尚未上报的安装结果，没有时返回 nil
+func (p *pluginInstalls) report() []*WsPluginStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	statuses := p.finished
+	p.finished = nil
+	for _, pl := range p.running {
+		statuses = append(statuses, toWsPluginStatus(pl.Status()))
+	}
+	return statuses
+}
+
+func toWsPluginStatus(s plugin.Status) *WsPluginStatus {
+	return &WsPluginStatus{
+		Name:       s.Name,
+		Installed:  s.Installed,
+		Installing: s.Installing,
+		Progress:   s.Progress,
+		Phase:      s.Phase,
+	}
+}
+
+// installPlugin 处理 INSTALL_PLUGIN：在后台安装插件并立即回复，进度随心跳上报；
+// 安装结束后立即发送心跳，服务端随之收到更新后的能力信息（如 ocrInstalled）
+// payload: name（插件名，如 "ocr"）
+func (c *Client) installPlugin(payloadJSON string) *DataResponseResult {
+	var req struct {
+		Name string `json:"name"`
+	}
+	json.Unmarshal([]byte(payloadJSON), &req)
+
+	pl, ok := plugin.Get(req.Name)
+	if !ok {
+		return remoteFailure(RequestTypeInstallPlugin, "unknown_plugin", fmt.Sprintf("未知的插件: %q", req.Name))
+	}
+	if status := pl.Status(); status.Installed {
+		return remoteSuccess(RequestTypeInstallPlugin, "插件已安装", toWsPluginStatus(status))
+	}
+	if !c.plugins.start(req.Name, pl) {
+		return remoteFailure(RequestTypeInstallPlugin, "already_downloading", fmt.Sprintf("插件 %s 正在下载中 (already downloading)", req.Name))
+	}
+
+	c.log("INFO", fmt.Sprintf("Installing plugin %s requested by server", req.Name))
+	go func() {
+		err := pl.Install(context.Background(), nil)
+		if err != nil {
+			c.log("ERROR", fmt.Sprintf("Plugin %s install failed: %v", req.Name, err))
+		} else {
+			c.log("INFO", fmt.Sprintf("Plugin %s installed", req.Name))
+		}
+		c.plugins.finish(req.Name, err)
+		c.sendHeartbeat()
+	}()
+	return remoteSuccess(RequestTypeInstallPlugin, "已开始安装", toWsPluginStatus(pl.Status()))
+}
+
+// handlePluginStatus 处理 PLUGIN_STATUS：返回指定插件的安装状态和文件路径
+// payload: name（插件名）
+func handlePluginStatus(payload map[string]interface{}) *DataResponseResult {
+	name, _ := payload["name"].(string)
+	pl, ok := plugin.Get(name)
+	if !ok {
+		return remoteFailure(RequestTypePluginStatus, "unknown_plugin", fmt.Sprintf("未知的插件: %q", name))
+	}
+	return remoteSuccess(RequestTypePluginStatus, "", plugin.Info{Status: pl.Status(), Paths: pl.Paths()})
+}
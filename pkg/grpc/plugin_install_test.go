@@ -0,0 +1,134 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/plugin"
+)
+
+// fakePlugin 安装在 release 关闭前阻塞的插件
+type fakePlugin struct {
+	name    string
+	release chan struct{}
+	fail    bool
+
+	mu         sync.Mutex
+	installing bool
+	installed  bool
+}
+
+func (p *fakePlugin) Name() string { return p.name }
+
+func (p *fakePlugin) Status() plugin.Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return plugin.Status{Name: p.name, Installed: p.installed, Installing: p.installing, Progress: 50}
+}
+
+func (p *fakePlugin) Install(ctx context.Context, progress func(float64)) error {
+	p.mu.Lock()
+	p.installing = true
+	p.mu.Unlock()
+	<-p.release
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.installing = false
+	if p.fail {
+		return errors.New("下载失败")
+	}
+	p.installed = true
+	return nil
+}
+
+func (p *fakePlugin) Uninstall() error         { return nil }
+func (p *fakePlugin) Paths() map[string]string { return map[string]string{"model": "/tmp/model"} }
+
+// installRequest 通过数据请求安装插件
+func installRequest(t *testing.T, client *Client, name string) *WsDataResponse {
+	t.Helper()
+	client.handleDataRequest("install_1", &WsDataRequest{RequestType: "install_plugin", PayloadJson: `{"name":"` + name + `"}`})
+	msg := nextMessage(client)
+	if msg == nil || msg.DataResponse == nil || msg.DataResponse.RequestType != RequestTypeInstallPlugin {
+		t.Fatalf("应以 DataResponse 回复安装请求, 实际 %+v", msg)
+	}
+	return msg.DataResponse
+}
+
+func TestInstallPluginRequest(t *testing.T) {
+	p := &fakePlugin{name: "test-install", release: make(chan struct{})}
+	plugin.Register(p)
+	client := NewClient(nil)
+
+	if resp := installRequest(t, client, "missing"); resp.Success {
+		t.Error("未知插件应失败")
+	}
+	if resp := installRequest(t, client, p.name); !resp.Success {
+		t.Fatalf("应开始安装: %s", resp.Message)
+	}
+	if resp := installRequest(t, client, p.name); resp.Success || !strings.Contains(resp.Message, "already downloading") {
+		t.Errorf("安装中重复请求应拒绝, 实际 %+v", resp)
+	}
+
+	// 安装期间心跳上报进度
+	waitFor(t, "开始安装", func() bool { return p.Status().Installing })
+	client.sendHeartbeat()
+	if plugins := nextMessage(client).Heartbeat.Plugins; len(plugins) != 1 || !plugins[0].Installing || plugins[0].Progress != 50 {
+		t.Errorf("心跳应上报安装进度, 实际 %+v", plugins)
+	}
+
+	// 安装结束后立即发送心跳上报最终状态，之后不再上报
+	close(p.release)
+	var hb *WsHeartbeat
+	waitFor(t, "安装完成后的心跳", func() bool {
+		if msg := nextMessage(client); msg != nil && msg.Heartbeat != nil {
+			hb = msg.Heartbeat
+		}
+		return hb != nil
+	})
+	if len(hb.Plugins) != 1 || !hb.Plugins[0].Installed || hb.Plugins[0].Error != "" {
+		t.Errorf("应上报安装完成, 实际 %+v", hb.Plugins)
+	}
+	client.sendHeartbeat()
+	if plugins := nextMessage(client).Heartbeat.Plugins; plugins != nil {
+		t.Errorf("安装结果只上报一次, 实际 %+v", plugins)
+	}
+
+	if resp := installRequest(t, client, p.name); !resp.Success || resp.Message != "插件已安装" {
+		t.Errorf("已安装时应直接返回, 实际 %+v", resp)
+	}
+}
+
+func TestInstallPluginFailureAndStatus(t *testing.T) {
+	p := &fakePlugin{name: "test-failing", release: make(chan struct{}), fail: true}
+	plugin.Register(p)
+	client := NewClient(nil)
+
+	close(p.release)
+	installRequest(t, client, p.name)
+	var hb *WsHeartbeat
+	waitFor(t, "安装失败后的心跳", func() bool {
+		if msg := nextMessage(client); msg != nil && msg.Heartbeat != nil {
+			hb = msg.Heartbeat
+		}
+		return hb != nil
+	})
+	if len(hb.Plugins) != 1 || hb.Plugins[0].Installed || hb.Plugins[0].Error != "下载失败" {
+		t.Errorf("应上报失败原因, 实际 %+v", hb.Plugins)
+	}
+
+	result := HandleDataRequest(RequestTypePluginStatus, `{"name":"test-failing"}`)
+	var info plugin.Info
+	json.Unmarshal([]byte(result.PayloadJSON), &info)
+	if !result.Success || info.Name != p.name || info.Installed || info.Paths["model"] != "/tmp/model" {
+		t.Errorf("应返回插件状态, 实际 %+v", result)
+	}
+	if result := HandleDataRequest(RequestTypePluginStatus, `{"name":"missing"}`); result.Success {
+		t.Error("未知插件应失败")
+	}
+}
@@ -0,0 +1,212 @@
+package grpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"runtime"
+	"strings"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+	"github.com/zoeyai/zoeyworker/pkg/auto/window"
+	"github.com/zoeyai/zoeyworker/pkg/process"
+)
+
+// 远程排查请求的大小限制
+const (
+	// maxRemoteScreenshotSize 截图 base64 的最大长度，超出时降低质量和宽度重新编码
+	maxRemoteScreenshotSize = 4 << 20
+	// defaultRemoteScreenshotQuality 截图默认 JPEG 质量
+	defaultRemoteScreenshotQuality = 70
+	// maxRemoteWindows 窗口列表最多返回的条数
+	maxRemoteWindows = 500
+	// maxRemoteProcesses 进程列表最多返回的条数
+	maxRemoteProcesses = 2000
+)
+
+// 远程排查请求失败时 payload 中的 error 字段，服务端据此区分权限问题和其他错误
+const (
+	remoteErrScreenRecordingDenied = "screen_recording_denied"
+	remoteErrCaptureFailed         = "capture_failed"
+	remoteErrListFailed            = "list_failed"
+)
+
+// screenRecordingDeniedMessage 未授予屏幕录制权限时的提示
+const screenRecordingDeniedMessage = "未授予屏幕录制权限：请在 系统设置 > 隐私与安全性 > 屏幕录制 中允许 Zoey Worker 后重启"
+
+// 远程排查使用的系统调用，测试时替换
+var (
+	// captureScreenImage 截取指定显示器（displayID < 0 时截取主显示器），不修改执行器的截图设置
+	captureScreenImage = func(displayID int) (image.Image, error) {
+		if displayID >= 0 {
+			return screen.CaptureDisplay(displayID)
+		}
+		return screen.CaptureScreen()
+	}
+	listWindowInfos  = window.GetWindows
+	listProcessInfos = process.GetProcesses
+	// screenRecordingGranted 是否有屏幕录制权限（只有 macOS 需要授权）
+	screenRecordingGranted = func() bool {
+		return runtime.GOOS != "darwin" || checkPermissions().ScreenRecording
+	}
+)
+
+// intPayload 读取 payload 中的整数参数
+func intPayload(payload map[string]interface{}, key string, def int) int {
+	if v, ok := payload[key].(float64); ok {
+		return int(v)
+	}
+	return def
+}
+
+// remoteFailure 远程排查请求失败的响应，payload 的 error 字段为错误类型
+func remoteFailure(requestType, code, message string) *DataResponseResult {
+	data, _ := json.Marshal(map[string]string{"error": code})
+	return &DataResponseResult{
+		RequestType: requestType,
+		Success:     false,
+		Message:     message,
+		PayloadJSON: string(data),
+	}
+}
+
+// remoteSuccess 序列化远程排查请求的结果
+func remoteSuccess(requestType, message string, result any) *DataResponseResult {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return remoteFailure(requestType, remoteErrListFailed, fmt.Sprintf("JSON序列化失败: %v", err))
+	}
+	return &DataResponseResult{
+		RequestType: requestType,
+		Success:     true,
+		Message:     message,
+		PayloadJSON: string(data),
+	}
+}
+
+// handleCaptureScreenshot 截取当前屏幕用于远程排查，与正在执行的任务并行，不影响任务的截图设置
+// payload: display_id（默认主显示器）、max_width（默认 1280，<= 0 不缩放）、quality（默认 70）
+// 返回 JPEG data URL；超过大小限制时依次降低质量和宽度
+func handleCaptureScreenshot(payload map[string]interface{}) *DataResponseResult {
+	if !screenRecordingGranted() {
+		return remoteFailure(RequestTypeCaptureScreenshot, remoteErrScreenRecordingDenied, screenRecordingDeniedMessage)
+	}
+
+	displayID := intPayload(payload, "display_id", -1)
+	maxWidth := intPayload(payload, "max_width", screen.DefaultScreenshotMaxWidth)
+	quality := intPayload(payload, "quality", defaultRemoteScreenshotQuality)
+	if quality <= 0 || quality > 100 {
+		quality = defaultRemoteScreenshotQuality
+	}
+
+	img, err := captureScreenImage(displayID)
+	if err != nil {
+		log("WARN", fmt.Sprintf("Remote screenshot failed: %v", err))
+		return remoteFailure(RequestTypeCaptureScreenshot, remoteErrCaptureFailed, fmt.Sprintf("截图失败: %v", err))
+	}
+	screenWidth := img.Bounds().Dx()
+	if maxWidth <= 0 {
+		maxWidth = screenWidth
+	}
+
+	for attempt := 0; ; attempt++ {
+		scaled, _, err := screen.DownscaleImage(img, maxWidth)
+		if err != nil {
+			return remoteFailure(RequestTypeCaptureScreenshot, remoteErrCaptureFailed, fmt.Sprintf("缩放截图失败: %v", err))
+		}
+		encoded, err := screen.ImageToBase64(scaled, "jpeg", quality)
+		if err != nil {
+			return remoteFailure(RequestTypeCaptureScreenshot, remoteErrCaptureFailed, fmt.Sprintf("编码截图失败: %v", err))
+		}
+		if len(encoded) <= maxRemoteScreenshotSize {
+			return remoteSuccess(RequestTypeCaptureScreenshot, "", map[string]interface{}{
+				"image":        encoded,
+				"width":        scaled.Bounds().Dx(),
+				"height":       scaled.Bounds().Dy(),
+				"screen_width": screenWidth,
+				"display_id":   displayID,
+				"quality":      quality,
+			})
+		}
+		if attempt >= 4 {
+			return remoteFailure(RequestTypeCaptureScreenshot, remoteErrCaptureFailed,
+				fmt.Sprintf("截图超过大小限制 %d 字节，请减小 max_width", maxRemoteScreenshotSize))
+		}
+		// 先降低质量，再减半宽度
+		if quality > 40 {
+			quality = 40
+		} else {
+			maxWidth = max(scaled.Bounds().Dx()/2, 1)
+		}
+	}
+}
+
+// handleListWindows 列出窗口（标题、所属进程、区域），最多 maxRemoteWindows 条
+// payload: process_name（按进程名筛选）、limit
+// macOS 未授予屏幕录制权限时窗口标题为空，仍返回列表并在 message 中说明
+func handleListWindows(payload map[string]interface{}) *DataResponseResult {
+	var filter []string
+	if name, ok := payload["process_name"].(string); ok && name != "" {
+		filter = append(filter, name)
+	}
+	windows, err := listWindowInfos(filter...)
+	if err != nil {
+		return remoteFailure(RequestTypeListWindows, remoteErrListFailed, fmt.Sprintf("获取窗口列表失败: %v", err))
+	}
+
+	type windowOutput struct {
+		Handle int64  `json:"handle"`
+		Title  string `json:"title"`
+		Owner  string `json:"owner"`
+		PID    int    `json:"pid"`
+		Bounds struct {
+			X      int `json:"x"`
+			Y      int `json:"y"`
+			Width  int `json:"width"`
+			Height int `json:"height"`
+		} `json:"bounds"`
+	}
+	limit := min(max(intPayload(payload, "limit", maxRemoteWindows), 1), maxRemoteWindows)
+	output := make([]windowOutput, 0, min(len(windows), limit))
+	for _, win := range windows[:min(len(windows), limit)] {
+		w := windowOutput{Handle: win.Handle, Title: win.Title, Owner: win.OwnerName, PID: win.PID}
+		w.Bounds.X, w.Bounds.Y = win.Bounds.X, win.Bounds.Y
+		w.Bounds.Width, w.Bounds.Height = win.Bounds.Width, win.Bounds.Height
+		output = append(output, w)
+	}
+
+	message := ""
+	if !screenRecordingGranted() {
+		message = screenRecordingDeniedMessage + "（窗口标题不可用）"
+	}
+	return remoteSuccess(RequestTypeListWindows, message, map[string]interface{}{
+		"windows":   output,
+		"total":     len(windows),
+		"truncated": len(windows) > len(output),
+	})
+}
+
+// handleListProcesses 列出进程（名称、PID、路径），最多 maxRemoteProcesses 条
+// payload: name（进程名包含，不区分大小写）、limit
+func handleListProcesses(payload map[string]interface{}) *DataResponseResult {
+	processes, err := listProcessInfos()
+	if err != nil {
+		return remoteFailure(RequestTypeListProcesses, remoteErrListFailed, fmt.Sprintf("获取进程列表失败: %v", err))
+	}
+
+	name, _ := payload["name"].(string)
+	name = strings.ToLower(name)
+	matched := make([]process.ProcessInfo, 0, len(processes))
+	for _, proc := range processes {
+		if proc.Name != "" && strings.Contains(strings.ToLower(proc.Name), name) {
+			matched = append(matched, proc)
+		}
+	}
+
+	limit := min(max(intPayload(payload, "limit", maxRemoteProcesses), 1), maxRemoteProcesses)
+	return remoteSuccess(RequestTypeListProcesses, "", map[string]interface{}{
+		"processes": matched[:min(len(matched), limit)],
+		"total":     len(matched),
+		"truncated": len(matched) > limit,
+	})
+}
@@ -0,0 +1,148 @@
+package grpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+	"github.com/zoeyai/zoeyworker/pkg/auto/window"
+	"github.com/zoeyai/zoeyworker/pkg/process"
+)
+
+// fakeRemoteData 替换截图、窗口和进程枚举，返回修改屏幕录制权限的函数
+func fakeRemoteData(t *testing.T, windows []window.WindowInfo, processes []process.ProcessInfo) (setGranted func(bool)) {
+	origCapture, origWindows, origProcesses, origGranted := captureScreenImage, listWindowInfos, listProcessInfos, screenRecordingGranted
+	t.Cleanup(func() {
+		captureScreenImage, listWindowInfos, listProcessInfos, screenRecordingGranted = origCapture, origWindows, origProcesses, origGranted
+	})
+
+	granted := true
+	screenRecordingGranted = func() bool { return granted }
+	captureScreenImage = func(displayID int) (image.Image, error) {
+		if displayID > 0 {
+			return nil, fmt.Errorf("显示器 %d 不存在", displayID)
+		}
+		img := image.NewRGBA(image.Rect(0, 0, 2560, 1440))
+		for x := range 2560 {
+			img.Set(x, x%1440, color.White)
+		}
+		return img, nil
+	}
+	listWindowInfos = func(filter ...string) ([]window.WindowInfo, error) { return windows, nil }
+	listProcessInfos = func() ([]process.ProcessInfo, error) { return processes, nil }
+	return func(v bool) { granted = v }
+}
+
+func TestCaptureScreenshotRequest(t *testing.T) {
+	setGranted := fakeRemoteData(t, nil, nil)
+
+	// 请求类型不区分大小写，默认缩放到 1280 宽
+	result := HandleDataRequest("capture_screenshot", "{}")
+	if !result.Success {
+		t.Fatalf("截图失败: %s", result.Message)
+	}
+	var shot struct {
+		Image       string `json:"image"`
+		Width       int    `json:"width"`
+		Height      int    `json:"height"`
+		ScreenWidth int    `json:"screen_width"`
+	}
+	json.Unmarshal([]byte(result.PayloadJSON), &shot)
+	if !strings.HasPrefix(shot.Image, "data:image/jpeg;base64,") || shot.Width != 1280 || shot.Height != 720 || shot.ScreenWidth != 2560 {
+		t.Errorf("应返回缩放后的 JPEG, 实际 %dx%d (屏幕 %d)", shot.Width, shot.Height, shot.ScreenWidth)
+	}
+
+	result = HandleDataRequest(RequestTypeCaptureScreenshot, `{"max_width": 640}`)
+	json.Unmarshal([]byte(result.PayloadJSON), &shot)
+	if shot.Width != 640 {
+		t.Errorf("max_width=640 时宽度应为 640, 实际 %d", shot.Width)
+	}
+
+	// 截图失败和未授权返回不同的错误类型
+	var failure struct {
+		Error string `json:"error"`
+	}
+	result = HandleDataRequest(RequestTypeCaptureScreenshot, `{"display_id": 3}`)
+	json.Unmarshal([]byte(result.PayloadJSON), &failure)
+	if result.Success || failure.Error != remoteErrCaptureFailed || !strings.Contains(result.Message, "显示器 3") {
+		t.Errorf("显示器不存在时应返回 capture_failed, 实际 %+v", result)
+	}
+
+	setGranted(false)
+	result = HandleDataRequest(RequestTypeCaptureScreenshot, "{}")
+	json.Unmarshal([]byte(result.PayloadJSON), &failure)
+	if result.Success || failure.Error != remoteErrScreenRecordingDenied || result.Message != screenRecordingDeniedMessage {
+		t.Errorf("未授权时应返回 screen_recording_denied, 实际 %+v", result)
+	}
+}
+
+func TestListWindowsRequest(t *testing.T) {
+	windows := make([]window.WindowInfo, maxRemoteWindows+10)
+	for i := range windows {
+		windows[i] = window.WindowInfo{PID: i, Handle: int64(i + 1), Title: fmt.Sprintf("窗口 %d", i), OwnerName: "Finder", Bounds: auto.Region{X: i, Width: 800, Height: 600}}
+	}
+	setGranted := fakeRemoteData(t, windows, nil)
+
+	var list struct {
+		Windows []struct {
+			Handle int64  `json:"handle"`
+			Title  string `json:"title"`
+			Owner  string `json:"owner"`
+			Bounds struct {
+				X     int `json:"x"`
+				Width int `json:"width"`
+			} `json:"bounds"`
+		} `json:"windows"`
+		Total     int  `json:"total"`
+		Truncated bool `json:"truncated"`
+	}
+	result := HandleDataRequest(RequestTypeListWindows, "{}")
+	json.Unmarshal([]byte(result.PayloadJSON), &list)
+	if !result.Success || len(list.Windows) != maxRemoteWindows || list.Total != len(windows) || !list.Truncated {
+		t.Fatalf("应截断到 %d 条, 实际 %d/%d truncated=%v", maxRemoteWindows, len(list.Windows), list.Total, list.Truncated)
+	}
+	if w := list.Windows[2]; w.Handle != 3 || w.Title != "窗口 2" || w.Owner != "Finder" || w.Bounds.X != 2 || w.Bounds.Width != 800 {
+		t.Errorf("窗口信息不完整: %+v", w)
+	}
+
+	// 未授权时仍返回列表（标题可能为空），并提示原因
+	setGranted(false)
+	result = HandleDataRequest(RequestTypeListWindows, `{"limit": 5}`)
+	json.Unmarshal([]byte(result.PayloadJSON), &list)
+	if !result.Success || len(list.Windows) != 5 || !strings.Contains(result.Message, "屏幕录制") {
+		t.Errorf("未授权时应返回列表并提示, 实际 %d 条, message=%q", len(list.Windows), result.Message)
+	}
+}
+
+func TestListProcessesRequest(t *testing.T) {
+	fakeRemoteData(t, nil, []process.ProcessInfo{
+		{PID: 1, Name: "launchd", Path: "/sbin/launchd"},
+		{PID: 200, Name: "Google Chrome", Path: "/Applications/Google Chrome.app"},
+		{PID: 201, Name: "Google Chrome Helper"},
+		{PID: 300},
+	})
+
+	var list struct {
+		Processes []process.ProcessInfo `json:"processes"`
+		Total     int                   `json:"total"`
+		Truncated bool                  `json:"truncated"`
+	}
+	result := HandleDataRequest(RequestTypeListProcesses, `{"name": "chrome", "limit": 1}`)
+	json.Unmarshal([]byte(result.PayloadJSON), &list)
+	if !result.Success || len(list.Processes) != 1 || list.Total != 2 || !list.Truncated {
+		t.Fatalf("应按名称筛选并截断, 实际 %+v", list)
+	}
+	if p := list.Processes[0]; p.PID != 200 || p.Path != "/Applications/Google Chrome.app" {
+		t.Errorf("进程信息不完整: %+v", p)
+	}
+
+	listProcessInfos = func() ([]process.ProcessInfo, error) { return nil, errors.New("permission denied") }
+	if result := HandleDataRequest(RequestTypeListProcesses, "{}"); result.Success || !strings.Contains(result.Message, "permission denied") {
+		t.Errorf("枚举失败时应返回错误, 实际 %+v", result)
+	}
+}
@@ -0,0 +1,248 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto/input"
+)
+
+// RequestTypeInputEvent 远程输入事件的响应类型（WsDataResponse.RequestType）
+const RequestTypeInputEvent = "INPUT_EVENT"
+
+// 远程输入事件类型
+const (
+	InputEventMove   = "move"
+	InputEventClick  = "click"
+	InputEventKey    = "key"
+	InputEventType   = "type"
+	InputEventScroll = "scroll"
+)
+
+// 远程输入的取值限制
+const (
+	maxRemoteTextLength = 1000
+	maxRemoteScroll     = 100
+	// inputQueueSize 每个会话排队等待执行的输入事件上限
+	inputQueueSize = 32
+)
+
+// inputRequest 排队等待执行的远程输入事件
+type inputRequest struct {
+	msgID string
+	ev    *WsInputEvent
+}
+
+// remoteModifiers 允许的修饰键
+var remoteModifiers = map[string]bool{
+	"ctrl": true, "control": true, "shift": true, "alt": true, "option": true,
+	"cmd": true, "command": true, "meta": true, "win": true,
+}
+
+// 远程输入使用的鼠标键盘操作，测试时替换
+var (
+	inputMoveTo = input.MoveTo
+	inputClick  = func(button string, double bool) {
+		if double {
+			input.DoubleClick(button)
+		} else {
+			input.Click(button)
+		}
+	}
+	inputKeyTap  = input.KeyTap
+	inputKeyDown = input.KeyDown
+	inputKeyUp   = input.KeyUp
+	inputType    = input.TypeText
+	inputScroll  = input.Scroll
+)
+
+// SetRemoteControl 开启或关闭远程控制（服务端在屏幕流期间发送鼠标键盘事件）
+func (c *Client) SetRemoteControl(enabled bool) {
+	c.mu.Lock()
+	c.config.RemoteControl = enabled
+	c.mu.Unlock()
+}
+
+// validateInputEvent 校验输入事件的类型和参数
+func validateInputEvent(ev *WsInputEvent) error {
+	for _, m := range ev.Modifiers {
+		if !remoteModifiers[strings.ToLower(m)] {
+			return fmt.Errorf("不支持的修饰键 %q", m)
+		}
+	}
+	switch ev.Type {
+	case InputEventMove, InputEventClick:
+		if ev.X < 0 || ev.Y < 0 {
+			return fmt.Errorf("坐标不能为负（%d, %d）", ev.X, ev.Y)
+		}
+		if ev.Type == InputEventClick && ev.Button != "" && ev.Button != "left" && ev.Button != "right" && ev.Button != "middle" {
+			return fmt.Errorf("不支持的鼠标按键 %q", ev.Button)
+		}
+	case InputEventKey:
+		if ev.Key == "" {
+			return errors.New("缺少 key")
+		}
+	case InputEventType:
+		if ev.Text == "" || len([]rune(ev.Text)) > maxRemoteTextLength {
+			return fmt.Errorf("text 长度应在 1-%d 之间", maxRemoteTextLength)
+		}
+	case InputEventScroll:
+		if (ev.DeltaX == 0 && ev.DeltaY == 0) || max(abs(ev.DeltaX), abs(ev.DeltaY)) > maxRemoteScroll {
+			return fmt.Errorf("滚动量应在 1-%d 之间", maxRemoteScroll)
+		}
+	default:
+		return fmt.Errorf("未知的输入事件类型 %q", ev.Type)
+	}
+	return nil
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// taskRunning 执行器是否有任务在执行
+func (c *Client) taskRunning() bool {
+	c.mu.RLock()
+	callback := c.onExecutorStatus
+	c.mu.RUnlock()
+
+	if callback == nil {
+		return false
+	}
+	_, _, _, _, count, _ := callback()
+	return count > 0
+}
+
+// checkRemoteInput 检查是否允许执行远程输入：已开启远程控制、屏幕流进行中，且没有任务在执行（或 force）
+func (c *Client) checkRemoteInput(ev *WsInputEvent) error {
+	c.mu.RLock()
+	enabled := c.config.RemoteControl
+	st := c.stream
+	c.mu.RUnlock()
+
+	if !enabled {
+		return errors.New("远程控制未开启（配置 remote_control）")
+	}
+	if st == nil || st.opts.StreamId != ev.StreamId {
+		return errors.New("远程控制需要进行中的屏幕流")
+	}
+	if !ev.Force && c.taskRunning() {
+		return errors.New("任务执行中，拒绝远程输入（设置 force 强制执行）")
+	}
+	return validateInputEvent(ev)
+}
+
+// describeInputEvent 审计日志中的事件描述，不记录输入的文字内容
+func describeInputEvent(ev *WsInputEvent) string {
+	var detail string
+	switch ev.Type {
+	case InputEventMove:
+		detail = fmt.Sprintf("(%d, %d)", ev.X, ev.Y)
+	case InputEventClick:
+		detail = fmt.Sprintf("(%d, %d) button=%s double=%v", ev.X, ev.Y, ev.Button, ev.Double)
+	case InputEventKey:
+		detail = fmt.Sprintf("key=%s", ev.Key)
+	case InputEventType:
+		detail = fmt.Sprintf("text(%d chars)", len([]rune(ev.Text)))
+	case InputEventScroll:
+		detail = fmt.Sprintf("delta=(%d, %d)", ev.DeltaX, ev.DeltaY)
+	}
+	if len(ev.Modifiers) > 0 {
+		detail += " modifiers=" + strings.Join(ev.Modifiers, "+")
+	}
+	return fmt.Sprintf("%s %s stream=%s force=%v", ev.Type, detail, ev.StreamId, ev.Force)
+}
+
+// executeInputEvent 执行已校验的输入事件
+func executeInputEvent(ev *WsInputEvent) {
+	switch ev.Type {
+	case InputEventMove:
+		inputMoveTo(ev.X, ev.Y)
+	case InputEventClick:
+		button := ev.Button
+		if button == "" {
+			button = "left"
+		}
+		for _, m := range ev.Modifiers {
+			inputKeyDown(m)
+		}
+		inputMoveTo(ev.X, ev.Y)
+		inputClick(button, ev.Double)
+		for _, m := range ev.Modifiers {
+			inputKeyUp(m)
+		}
+	case InputEventKey:
+		inputKeyTap(ev.Key, ev.Modifiers...)
+	case InputEventType:
+		inputType(ev.Text)
+	case InputEventScroll:
+		inputScroll(ev.DeltaX, ev.DeltaY)
+	}
+}
+
+// handleInputEvent 处理远程输入事件并以 DataResponse 回复；每个事件（包括被拒绝的）都以 INFO 记录，便于审计
+func (c *Client) handleInputEvent(msgID string, ev *WsInputEvent) {
+	err := c.checkRemoteInput(ev)
+	if err != nil {
+		c.log("INFO", fmt.Sprintf("Remote input rejected: %s: %v", describeInputEvent(ev), err))
+	} else {
+		c.log("INFO", fmt.Sprintf("Remote input: %s", describeInputEvent(ev)))
+		executeInputEvent(ev)
+	}
+	c.sendInputResponse(msgID, err)
+}
+
+// sendInputResponse 回复输入事件的处理结果，err 为拒绝原因
+func (c *Client) sendInputResponse(msgID string, err error) {
+	response := &WsDataResponse{RequestType: RequestTypeInputEvent, Success: true, PayloadJson: "{}"}
+	if err != nil {
+		response.Success = false
+		response.Message = err.Error()
+	}
+
+	c.sendMessage(&WsWorkerMessage{
+		MessageId:    msgID,
+		Timestamp:    time.Now().UnixMilli(),
+		AgentId:      c.currentAgentID(),
+		DataResponse: response,
+	})
+}
+
+// dispatchInputEvent 将输入事件交给会话的输入协程按顺序执行：长文本输入、带修饰键的点击可能较慢，
+// 不能阻塞 receiveLoop，否则 pong 无法读取，读超时后会误判连接断开。队列已满时拒绝；未连接时直接处理
+func (c *Client) dispatchInputEvent(msgID string, ev *WsInputEvent) {
+	c.mu.RLock()
+	s := c.session
+	c.mu.RUnlock()
+
+	if s == nil {
+		c.handleInputEvent(msgID, ev)
+		return
+	}
+	select {
+	case s.inputs <- inputRequest{msgID: msgID, ev: ev}:
+	default:
+		err := errors.New("远程输入队列已满")
+		c.log("INFO", fmt.Sprintf("Remote input rejected: %s: %v", describeInputEvent(ev), err))
+		c.sendInputResponse(msgID, err)
+	}
+}
+
+// inputLoop 按接收顺序执行会话的远程输入事件，会话停止后丢弃未执行的事件
+func (c *Client) inputLoop(s *session) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case req := <-s.inputs:
+			c.handleInputEvent(req.msgID, req.ev)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package grpc
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeInput 替换鼠标键盘操作，返回记录的操作
+func fakeInput(t *testing.T) *[]string {
+	origMove, origClick, origTap, origDown, origUp, origType, origScroll := inputMoveTo, inputClick, inputKeyTap, inputKeyDown, inputKeyUp, inputType, inputScroll
+	t.Cleanup(func() {
+		inputMoveTo, inputClick, inputKeyTap, inputKeyDown, inputKeyUp, inputType, inputScroll = origMove, origClick, origTap, origDown, origUp, origType, origScroll
+	})
+
+	var calls []string
+	record := func(format string, args ...any) { calls = append(calls, fmt.Sprintf(format, args...)) }
+	inputMoveTo = func(x, y int) { record("move %d,%d", x, y) }
+	inputClick = func(button string, double bool) { record("click %s %v", button, double) }
+	inputKeyTap = func(key string, modifiers ...string) { record("tap %s %v", key, modifiers) }
+	inputKeyDown = func(key string) { record("down %s", key) }
+	inputKeyUp = func(key string) { record("up %s", key) }
+	inputType = func(text string) { record("type %s", text) }
+	inputScroll = func(x, y int) { record("scroll %d,%d", x, y) }
+	return &calls
+}
+
+// sendInput 处理一条输入事件并返回回复
+func sendInput(t *testing.T, client *Client, ev *WsInputEvent) *WsDataResponse {
+	t.Helper()
+	client.handleServerMessage(&WsServerMessage{MessageId: "input_1", InputEvent: ev})
+	msg := nextMessage(client)
+	if msg == nil || msg.DataResponse == nil || msg.DataResponse.RequestType != RequestTypeInputEvent {
+		t.Fatalf("应以 DataResponse 回复输入事件, 实际 %+v", msg)
+	}
+	return msg.DataResponse
+}
+
+func TestRemoteInputGating(t *testing.T) {
+	calls := fakeInput(t)
+	client := NewClient(nil)
+	click := &WsInputEvent{StreamId: "s1", Type: InputEventClick, X: 100, Y: 200}
+
+	if resp := sendInput(t, client, click); resp.Success || !strings.Contains(resp.Message, "remote_control") {
+		t.Errorf("未开启远程控制时应拒绝, 实际 %+v", resp)
+	}
+
+	client.SetRemoteControl(true)
+	if resp := sendInput(t, client, click); resp.Success || !strings.Contains(resp.Message, "屏幕流") {
+		t.Errorf("没有屏幕流时应拒绝, 实际 %+v", resp)
+	}
+
+	client.stream = &screenStream{opts: WsStartScreenStream{StreamId: "s1"}}
+	if resp := sendInput(t, client, &WsInputEvent{StreamId: "s2", Type: InputEventMove}); resp.Success {
+		t.Error("streamId 不匹配时应拒绝")
+	}
+
+	// 任务执行期间需要 force
+	running := 1
+	client.SetExecutorStatusCallback(func() (string, string, string, int64, int, []string) {
+		return "BUSY", "task-1", "execute_plan", 0, running, []string{"task-1"}
+	})
+	if resp := sendInput(t, client, click); resp.Success || !strings.Contains(resp.Message, "force") {
+		t.Errorf("任务执行中应拒绝, 实际 %+v", resp)
+	}
+	if len(*calls) != 0 {
+		t.Fatalf("被拒绝的事件不应执行: %v", *calls)
+	}
+
+	forced := *click
+	forced.Force = true
+	if resp := sendInput(t, client, &forced); !resp.Success {
+		t.Errorf("force 时应执行: %s", resp.Message)
+	}
+	running = 0
+	if resp := sendInput(t, client, click); !resp.Success {
+		t.Errorf("空闲时应执行: %s", resp.Message)
+	}
+	if want := []string{"move 100,200", "click left false", "move 100,200", "click left false"}; fmt.Sprint(*calls) != fmt.Sprint(want) {
+		t.Errorf("执行的操作 = %v, 期望 %v", *calls, want)
+	}
+}
+
+func TestRemoteInputEvents(t *testing.T) {
+	calls := fakeInput(t)
+	client := NewClient(&ClientConfig{RemoteControl: true})
+	client.stream = &screenStream{opts: WsStartScreenStream{StreamId: "s1"}}
+
+	for _, ev := range []*WsInputEvent{
+		{Type: InputEventClick, X: 5, Y: 6, Button: "right", Double: true, Modifiers: []string{"shift"}},
+		{Type: InputEventKey, Key: "c", Modifiers: []string{"cmd"}},
+		{Type: InputEventType, Text: "hello"},
+		{Type: InputEventScroll, DeltaY: -3},
+	} {
+		ev.StreamId = "s1"
+		if resp := sendInput(t, client, ev); !resp.Success {
+			t.Fatalf("%s 应执行: %s", ev.Type, resp.Message)
+		}
+	}
+	want := []string{"down shift", "move 5,6", "click right true", "up shift", "tap c [cmd]", "type hello", "scroll 0,-3"}
+	if fmt.Sprint(*calls) != fmt.Sprint(want) {
+		t.Errorf("执行的操作 = %v, 期望 %v", *calls, want)
+	}
+
+	*calls = nil
+	for name, ev := range map[string]*WsInputEvent{
+		"unknown type":  {Type: "drag"},
+		"negative":      {Type: InputEventMove, X: -1},
+		"button":        {Type: InputEventClick, Button: "back"},
+		"modifier":      {Type: InputEventKey, Key: "a", Modifiers: []string{"hyper"}},
+		"empty key":     {Type: InputEventKey},
+		"long text":     {Type: InputEventType, Text: strings.Repeat("a", maxRemoteTextLength+1)},
+		"no scroll":     {Type: InputEventScroll},
+		"scroll amount": {Type: InputEventScroll, DeltaX: maxRemoteScroll + 1},
+	} {
+		ev.StreamId = "s1"
+		if resp := sendInput(t, client, ev); resp.Success || resp.Message == "" {
+			t.Errorf("%s: 非法事件应拒绝", name)
+		}
+	}
+	if len(*calls) != 0 {
+		t.Errorf("非法事件不应执行: %v", *calls)
+	}
+}
+
+func TestSlowRemoteInputDoesNotBlockPong(t *testing.T) {
+	fakeInput(t)
+	typing, release := make(chan struct{}), make(chan struct{})
+	var releaseOnce sync.Once
+	unblock := func() { releaseOnce.Do(func() { close(release) }) }
+	inputType = func(text string) {
+		close(typing)
+		<-release
+	}
+
+	ready := make(chan struct{})
+	received := make(chan *WsWorkerMessage, 16)
+	server, _ := newAgentServer(t, func(n int32, conn *websocket.Conn) {
+		<-ready
+		conn.WriteJSON(WsServerMessage{MessageId: "input_1", InputEvent: &WsInputEvent{StreamId: "s1", Type: InputEventType, Text: "slow"}})
+		<-typing
+		conn.WriteJSON(WsServerMessage{MessageId: "ping_1", Ping: &WsPing{Timestamp: time.Now().UnixMilli()}})
+		for {
+			var msg WsWorkerMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg.Pong != nil || msg.DataResponse != nil {
+				received <- &msg
+			}
+		}
+	})
+	client := NewClient(&ClientConfig{HeartbeatInterval: 60, RemoteControl: true})
+	if err := client.Connect(strings.TrimPrefix(server.URL, "http://"), "ak", "sk"); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	defer client.Disconnect()
+	client.mu.Lock()
+	client.stream = &screenStream{opts: WsStartScreenStream{StreamId: "s1"}}
+	client.mu.Unlock()
+	close(ready)
+
+	next := func() *WsWorkerMessage {
+		select {
+		case msg := <-received:
+			return msg
+		case <-time.After(3 * time.Second):
+			unblock() // 否则 Disconnect 会等待阻塞的输入事件
+			t.Fatal("等待回复超时")
+			return nil
+		}
+	}
+	// 输入事件仍在执行时 ping 照常回复
+	if msg := next(); msg.Pong == nil {
+		t.Fatalf("输入执行期间应先回复 pong, 实际 %+v", msg)
+	}
+	unblock()
+	if msg := next(); msg.DataResponse == nil || msg.MessageId != "input_1" || !msg.DataResponse.Success {
+		t.Errorf("输入完成后应回复 DataResponse, 实际 %+v", msg)
+	}
+}
@@ -0,0 +1,152 @@
+package grpc
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+// 资源采样函数，测试时替换
+var (
+	// cpuPercent 返回自上次调用以来的 CPU 使用率（采样间隔即两次调用的间隔）
+	cpuPercent = func() (float64, error) {
+		percents, err := cpu.Percent(0, false)
+		if err != nil || len(percents) == 0 {
+			return 0, err
+		}
+		return percents[0], nil
+	}
+	memoryPercent = func() (float64, error) {
+		vm, err := mem.VirtualMemory()
+		if err != nil {
+			return 0, err
+		}
+		return vm.UsedPercent, nil
+	}
+	diskPercent = func(path string) (float64, error) {
+		usage, err := disk.Usage(path)
+		if err != nil {
+			return 0, err
+		}
+		return usage.UsedPercent, nil
+	}
+)
+
+// resourceSampler 保存最近一次资源采样结果，心跳直接读取，不在心跳中阻塞采样
+type resourceSampler struct {
+	mu        sync.Mutex
+	latest    *WsResourceInfo
+	sampledAt time.Time
+	// lastErr 上一次采样的错误，只在变化时输出日志
+	lastErr string
+}
+
+// get 返回最近一次采样结果，尚未采样时为 nil
+func (r *resourceSampler) get() *WsResourceInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.latest
+}
+
+// Stats 客户端运行统计（供 GUI 显示）
+type Stats struct {
+	// Resources 最近一次资源采样（与心跳上报的相同，连接后按心跳间隔采样），尚未采样时为 nil
+	Resources *WsResourceInfo
+	SampledAt time.Time
+	// StartedAt 客户端创建时间（用于计算运行时长）
+	StartedAt time.Time
+	// Reconnects 连接异常断开后重连成功的次数
+	Reconnects int64
+	// LastHeartbeat 最近一次发送心跳的时间，尚未发送时为零值
+	LastHeartbeat time.Time
+}
+
+// Stats 返回运行统计
+func (c *Client) Stats() Stats {
+	c.resources.mu.Lock()
+	stats := Stats{Resources: c.resources.latest, SampledAt: c.resources.sampledAt}
+	c.resources.mu.Unlock()
+
+	stats.StartedAt = c.startedAt
+	stats.Reconnects = c.reconnects.Load()
+	if ms := c.lastHeartbeat.Load(); ms > 0 {
+		stats.LastHeartbeat = time.UnixMilli(ms)
+	}
+	return stats
+}
+
+// clampPercent 将使用率限制在 0–100（采样异常时可能出现负数、超过 100 或 NaN）
+func clampPercent(v float64) float32 {
+	if math.IsNaN(v) {
+		return 0
+	}
+	return float32(min(max(v, 0), 100))
+}
+
+// sampleResources 采样 CPU、内存和 dataDir 所在磁盘的使用率，单项失败时该项为 0
+func (c *Client) sampleResources(dataDir string) {
+	info := &WsResourceInfo{}
+	var errs []error
+
+	if v, err := cpuPercent(); err != nil {
+		errs = append(errs, fmt.Errorf("CPU: %w", err))
+	} else {
+		info.CpuUsage = clampPercent(v)
+	}
+	if v, err := memoryPercent(); err != nil {
+		errs = append(errs, fmt.Errorf("内存: %w", err))
+	} else {
+		info.MemoryUsage = clampPercent(v)
+	}
+	if dataDir != "" {
+		if v, err := diskPercent(dataDir); err != nil {
+			errs = append(errs, fmt.Errorf("磁盘: %w", err))
+		} else {
+			info.DiskUsage = clampPercent(v)
+		}
+	}
+
+	errText := fmt.Sprint(errs)
+	if len(errs) == 0 {
+		errText = ""
+	}
+
+	c.resources.mu.Lock()
+	c.resources.latest = info
+	c.resources.sampledAt = time.Now()
+	changed := errText != c.resources.lastErr
+	c.resources.lastErr = errText
+	c.resources.mu.Unlock()
+
+	if changed && errText != "" {
+		c.log("WARN", fmt.Sprintf("Failed to sample resource usage: %s", errText))
+	}
+}
+
+// resourceLoop 后台按心跳间隔采样资源使用率（CPU 使用率为两次采样之间的平均值）
+func (c *Client) resourceLoop(s *session) {
+	defer s.wg.Done()
+
+	c.mu.RLock()
+	dataDir := c.config.DataDir
+	c.mu.RUnlock()
+
+	c.sampleResources(dataDir)
+	for {
+		c.mu.RLock()
+		interval := max(time.Duration(c.config.HeartbeatInterval)*time.Second, time.Second)
+		c.mu.RUnlock()
+
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(interval):
+			c.sampleResources(dataDir)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSampleResources(t *testing.T) {
+	client := NewClient(nil)
+	if stats := client.Stats(); stats.Resources != nil || !stats.LastHeartbeat.IsZero() || stats.StartedAt.IsZero() {
+		t.Errorf("初始统计 = %+v", stats)
+	}
+	client.sendHeartbeat()
+	if hb := nextMessage(client).Heartbeat; hb.ResourceInfo != nil {
+		t.Error("尚未采样时不应上报资源信息")
+	}
+	if client.Stats().LastHeartbeat.IsZero() {
+		t.Error("发送心跳后应记录心跳时间")
+	}
+
+	// 真实采样：使用率在 0–100 之间
+	client.sampleResources(t.TempDir())
+	info := client.resources.get()
+	for name, v := range map[string]float32{"cpu": info.CpuUsage, "memory": info.MemoryUsage, "disk": info.DiskUsage} {
+		if v < 0 || v > 100 {
+			t.Errorf("%s 使用率 %v 超出范围", name, v)
+		}
+	}
+	if info.MemoryUsage == 0 || info.DiskUsage == 0 {
+		t.Errorf("内存和磁盘使用率应大于 0: %+v", info)
+	}
+	if stats := client.Stats(); stats.Resources != info || stats.SampledAt.IsZero() {
+		t.Errorf("统计应返回最近一次采样: %+v", stats)
+	}
+
+	origCPU, origMemory, origDisk := cpuPercent, memoryPercent, diskPercent
+	t.Cleanup(func() { cpuPercent, memoryPercent, diskPercent = origCPU, origMemory, origDisk })
+	cpuPercent = func() (float64, error) { return 130, nil }
+	memoryPercent = func() (float64, error) { return math.NaN(), nil }
+	diskPercent = func(string) (float64, error) { return 0, errors.New("no such volume") }
+
+	client.sampleResources("/missing")
+	client.sendHeartbeat()
+	got := nextMessage(client).Heartbeat.ResourceInfo
+	if got == nil || got.CpuUsage != 100 || got.MemoryUsage != 0 || got.DiskUsage != 0 {
+		t.Errorf("异常值应限制在 0–100, 失败项为 0, 实际 %+v", got)
+	}
+}
@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// FeatureResultAck 任务结果确认：服务端收到 taskResult 后回复 resultAck（包括重复收到的），
+// 客户端超时或重连后以相同 messageId 重发未确认的结果，服务端按 messageId 去重
+const FeatureResultAck = "resultAck"
+
+// clientFeatures 连接时声明的客户端协议特性
+var clientFeatures = []string{FeatureResultAck, FeatureScreenStream}
+
+// defaultResultAckTimeout 未设置 ResultAckTimeout 时的确认超时
+const defaultResultAckTimeout = 30 * time.Second
+
+// pendingResult 已写入连接、等待服务端确认的任务结果
+type pendingResult struct {
+	msg    *WsWorkerMessage
+	sentAt time.Time
+}
+
+// resultTracker 等待确认的任务结果（按发送顺序），只在服务端支持 resultAck 的连接上使用
+type resultTracker struct {
+	mu      sync.Mutex
+	pending []pendingResult
+}
+
+// track 记录已发送的任务结果
+func (r *resultTracker) track(msg *WsWorkerMessage, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending = append(r.pending, pendingResult{msg: msg, sentAt: now})
+}
+
+// ack 服务端已确认，返回是否为等待中的结果（重复确认或已超时重发的返回 false）
+func (r *resultTracker) ack(messageID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := slices.IndexFunc(r.pending, func(p pendingResult) bool { return p.msg.MessageId == messageID })
+	if i < 0 {
+		return false
+	}
+	r.pending = slices.Delete(r.pending, i, i+1)
+	return true
+}
+
+// expired 移出并返回发送超过 timeout 仍未确认的结果
+func (r *resultTracker) expired(now time.Time, timeout time.Duration) []*WsWorkerMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var msgs []*WsWorkerMessage
+	r.pending = slices.DeleteFunc(r.pending, func(p pendingResult) bool {
+		if now.Sub(p.sentAt) < timeout {
+			return false
+		}
+		msgs = append(msgs, p.msg)
+		return true
+	})
+	return msgs
+}
+
+// takeAll 移出并返回所有未确认的结果（重连后重发）
+func (r *resultTracker) takeAll() []*WsWorkerMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msgs := make([]*WsWorkerMessage, len(r.pending))
+	for i, p := range r.pending {
+		msgs[i] = p.msg
+	}
+	r.pending = nil
+	return msgs
+}
+
+// count 未确认的结果数
+func (r *resultTracker) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
+
+// resultAckLoop 定期检查确认超时的任务结果并放回发送队列队首重发
+func (c *Client) resultAckLoop(s *session, timeout time.Duration) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(max(timeout/4, 100*time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			if msgs := c.results.expired(now, timeout); len(msgs) > 0 {
+				c.log("WARN", fmt.Sprintf("%d task results not acknowledged within %v, resending", len(msgs), timeout))
+				c.outbox.requeue(msgs)
+			}
+		}
+	}
+}
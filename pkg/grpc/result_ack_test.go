@@ -0,0 +1,150 @@
+package grpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	pb "github.com/zoeyai/zoeyworker/pkg/grpc/pb"
+)
+
+// resultAction 测试服务端收到 taskResult 后的处理
+type resultAction int
+
+const (
+	replyAck resultAction = iota
+	ignoreResult
+	dropConnection
+)
+
+// resultServer 记录收到的 taskResult messageId
+type resultServer struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (r *resultServer) received() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.ids)
+}
+
+// start 启动服务端，features 为认证响应中启用的特性，handle 决定如何处理第 conn 个连接收到的结果
+func (r *resultServer) start(t *testing.T, features []string, handle func(conn int32, id string) resultAction) *httptest.Server {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		n := connections.Add(1)
+		var connect WsConnectMessage
+		conn.ReadJSON(&connect)
+		if !slices.Contains(connect.Features, FeatureResultAck) {
+			t.Errorf("认证消息应声明 %s, 实际 %v", FeatureResultAck, connect.Features)
+		}
+		conn.WriteJSON(WsConnectResponse{Type: "connect_response", Success: true, AgentId: "agent", Features: features})
+		for {
+			var msg WsWorkerMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg.TaskResult == nil {
+				continue
+			}
+			r.mu.Lock()
+			r.ids = append(r.ids, msg.MessageId)
+			r.mu.Unlock()
+			switch handle(n, msg.MessageId) {
+			case replyAck:
+				conn.WriteJSON(WsServerMessage{ResultAck: &WsResultAck{MessageId: msg.MessageId}})
+			case dropConnection:
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func sendResult(client *Client, taskID string) {
+	client.SendTaskMessage(&pb.WorkerMessage{
+		MessageId: "result_1",
+		Payload:   &pb.WorkerMessage_TaskResult{TaskResult: &pb.TaskResult{TaskId: taskID}},
+	})
+}
+
+func connectTo(t *testing.T, client *Client, server *httptest.Server) {
+	t.Helper()
+	if err := client.Connect(strings.TrimPrefix(server.URL, "http://"), "ak", "sk"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Disconnect() })
+}
+
+func TestResultAckResendsAfterTimeout(t *testing.T) {
+	// 第一次收到不确认，超时重发后确认
+	var r resultServer
+	var count atomic.Int32
+	server := r.start(t, []string{FeatureResultAck}, func(int32, string) resultAction {
+		if count.Add(1) == 1 {
+			return ignoreResult
+		}
+		return replyAck
+	})
+	client := NewClient(&ClientConfig{HeartbeatInterval: 60, ResultAckTimeout: 1})
+	connectTo(t, client, server)
+
+	sendResult(client, "t1")
+	waitFor(t, "结果被重发并确认", func() bool { return len(r.received()) == 2 && client.results.count() == 0 })
+	if ids := r.received(); ids[0] != ids[1] {
+		t.Errorf("重发应保持 messageId 不变, 实际 %v", ids)
+	}
+}
+
+func TestResultAckResendsAfterReconnect(t *testing.T) {
+	// 第一个连接收到结果后断开（结果可能已丢失），重连后重发
+	var r resultServer
+	server := r.start(t, []string{FeatureResultAck}, func(conn int32, _ string) resultAction {
+		if conn == 1 {
+			return dropConnection
+		}
+		return replyAck
+	})
+	client := NewClient(&ClientConfig{HeartbeatInterval: 60, ReconnectDelays: []int{0}})
+	connectTo(t, client, server)
+
+	sendResult(client, "t1")
+	waitFor(t, "重连后重发并确认", func() bool { return len(r.received()) == 2 && client.results.count() == 0 })
+	if ids := r.received(); ids[0] != ids[1] {
+		t.Errorf("重发应保持 messageId 不变, 实际 %v", ids)
+	}
+}
+
+func TestResultAckDisabledForOldServer(t *testing.T) {
+	// 服务端未启用 resultAck：不等待确认也不重发
+	var r resultServer
+	server := r.start(t, nil, func(int32, string) resultAction { return ignoreResult })
+	client := NewClient(&ClientConfig{HeartbeatInterval: 60, ResultAckTimeout: 1})
+	connectTo(t, client, server)
+
+	sendResult(client, "t1")
+	sendResult(client, "t2")
+	waitFor(t, "结果发送", func() bool { return len(r.received()) == 2 })
+	time.Sleep(1500 * time.Millisecond)
+
+	ids := r.received()
+	if len(ids) != 2 || client.results.count() != 0 {
+		t.Errorf("旧服务端不应重发结果, 收到 %v, 未确认 %d", ids, client.results.count())
+	}
+	if ids[0] == ids[1] {
+		t.Errorf("同一毫秒内的结果 messageId 不应重复: %v", ids)
+	}
+}
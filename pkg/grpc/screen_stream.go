@@ -0,0 +1,239 @@
+package grpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto/screen"
+)
+
+// FeatureScreenStream 支持 startScreenStream/stopScreenStream，服务端据此决定是否提供实时画面
+const FeatureScreenStream = "screenStream"
+
+// RequestTypeStartScreenStream 开始屏幕流的响应类型（WsDataResponse.RequestType）
+const RequestTypeStartScreenStream = "START_SCREEN_STREAM"
+
+// 屏幕流参数的默认值和上限
+const (
+	maxScreenStreamFPS          = 2
+	defaultScreenStreamFPS      = 1
+	defaultScreenStreamQuality  = 50
+	defaultScreenStreamDuration = 60 * time.Second
+	maxScreenStreamDuration     = 10 * time.Minute
+)
+
+// 屏幕流结束原因（WsScreenFrame.Reason）
+const (
+	screenStreamStopped       = "stopped"
+	screenStreamDuration      = "duration"
+	screenStreamReplaced      = "replaced"
+	screenStreamCaptureFailed = "capture_failed"
+)
+
+// errScreenRecordingDenied 未授予屏幕录制权限，拒绝开始屏幕流
+var errScreenRecordingDenied = errors.New(screenRecordingDeniedMessage)
+
+// screenStream 进行中的屏幕流，同一时间最多一个
+type screenStream struct {
+	opts WsStartScreenStream
+	// stop 关闭时停止屏幕流，reason 为结束原因
+	stop     chan struct{}
+	stopOnce sync.Once
+	reason   string
+}
+
+// halt 停止屏幕流，只有第一次调用的原因生效
+func (st *screenStream) halt(reason string) {
+	st.stopOnce.Do(func() {
+		st.reason = reason
+		close(st.stop)
+	})
+}
+
+// normalizeScreenStream 补全默认值并把参数限制在允许范围内
+func normalizeScreenStream(req *WsStartScreenStream) (WsStartScreenStream, error) {
+	if req.StreamId == "" {
+		return WsStartScreenStream{}, errors.New("缺少 streamId")
+	}
+	opts := *req
+	if opts.Fps <= 0 {
+		opts.Fps = defaultScreenStreamFPS
+	}
+	opts.Fps = min(opts.Fps, maxScreenStreamFPS)
+	if opts.MaxWidth <= 0 {
+		opts.MaxWidth = screen.DefaultScreenshotMaxWidth
+	}
+	if opts.Quality <= 0 || opts.Quality > 100 {
+		opts.Quality = defaultScreenStreamQuality
+	}
+	if opts.DurationSeconds <= 0 {
+		opts.DurationSeconds = int(defaultScreenStreamDuration / time.Second)
+	}
+	opts.DurationSeconds = min(opts.DurationSeconds, int(maxScreenStreamDuration/time.Second))
+	return opts, nil
+}
+
+// handleStartScreenStream 开始屏幕流并以 DataResponse 回复（成功时 payload 为实际使用的参数），已有屏幕流时替换
+func (c *Client) handleStartScreenStream(msgID string, req *WsStartScreenStream) {
+	response := &WsDataResponse{RequestType: RequestTypeStartScreenStream, Success: true, PayloadJson: "{}"}
+	if opts, err := c.startScreenStream(req); err != nil {
+		c.log("WARN", fmt.Sprintf("Screen stream %s rejected: %v", req.StreamId, err))
+		response.Success = false
+		response.Message = err.Error()
+		if errors.Is(err, errScreenRecordingDenied) {
+			response.PayloadJson = `{"error":"` + remoteErrScreenRecordingDenied + `"}`
+		}
+	} else if data, err := json.Marshal(opts); err == nil {
+		response.PayloadJson = string(data)
+	}
+
+	c.sendMessage(&WsWorkerMessage{
+		MessageId:    msgID,
+		Timestamp:    time.Now().UnixMilli(),
+		AgentId:      c.currentAgentID(),
+		DataResponse: response,
+	})
+}
+
+// startScreenStream 校验参数并在当前连接上启动屏幕流，连接断开时屏幕流随之停止
+func (c *Client) startScreenStream(req *WsStartScreenStream) (WsStartScreenStream, error) {
+	opts, err := normalizeScreenStream(req)
+	if err != nil {
+		return opts, err
+	}
+	if !screenRecordingGranted() {
+		return opts, errScreenRecordingDenied
+	}
+
+	st := &screenStream{opts: opts, stop: make(chan struct{})}
+	c.mu.Lock()
+	s := c.session
+	if s == nil {
+		c.mu.Unlock()
+		return opts, errors.New("未连接")
+	}
+	// 持有 mu 且会话仍是当前会话时登记，会话关闭一定会等待屏幕流退出
+	s.wg.Add(1)
+	previous := c.stream
+	c.stream = st
+	c.mu.Unlock()
+
+	if previous != nil {
+		previous.halt(screenStreamReplaced)
+	}
+	c.log("INFO", fmt.Sprintf("Screen stream %s started: fps=%g maxWidth=%d quality=%d duration=%ds",
+		opts.StreamId, opts.Fps, opts.MaxWidth, opts.Quality, opts.DurationSeconds))
+	go c.screenStreamLoop(s, st)
+	return opts, nil
+}
+
+// handleStopScreenStream 停止屏幕流，streamId 为空时停止当前的屏幕流
+func (c *Client) handleStopScreenStream(req *WsStopScreenStream) {
+	c.mu.RLock()
+	st := c.stream
+	c.mu.RUnlock()
+
+	if st == nil || (req.StreamId != "" && req.StreamId != st.opts.StreamId) {
+		c.log("DEBUG", fmt.Sprintf("Ignoring stopScreenStream for unknown stream %s", req.StreamId))
+		return
+	}
+	st.halt(screenStreamStopped)
+}
+
+// screenStreamLoop 按帧率截图并放入会话的帧槽位；上一帧尚未发出时跳过本帧，不占用发送队列
+// 停止、到达时长或截图失败时发送结束通知；连接断开时直接退出
+func (c *Client) screenStreamLoop(s *session, st *screenStream) {
+	defer s.wg.Done()
+	defer func() {
+		c.mu.Lock()
+		if c.stream == st {
+			c.stream = nil
+		}
+		c.mu.Unlock()
+	}()
+
+	interval := time.Duration(float64(time.Second) / st.opts.Fps)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	deadline := time.NewTimer(time.Duration(st.opts.DurationSeconds) * time.Second)
+	defer deadline.Stop()
+
+	end := &WsScreenFrame{StreamId: st.opts.StreamId, Ended: true}
+	err := c.nextStreamFrame(s, st, end)
+	for err == nil {
+		select {
+		case <-s.stop:
+			return
+		case <-st.stop:
+		case <-deadline.C:
+			st.halt(screenStreamDuration)
+		case <-ticker.C:
+			err = c.nextStreamFrame(s, st, end)
+			continue
+		}
+		break
+	}
+	if err != nil {
+		c.log("WARN", fmt.Sprintf("Screen stream %s capture failed: %v", st.opts.StreamId, err))
+		st.halt(screenStreamCaptureFailed)
+		end.Message = err.Error()
+	}
+
+	if s.stopped() {
+		return
+	}
+	end.Reason = st.reason
+	c.log("INFO", fmt.Sprintf("Screen stream %s ended: %s, %d frames, %d dropped",
+		st.opts.StreamId, end.Reason, end.Seq, end.Dropped))
+	c.sendMessage(&WsWorkerMessage{
+		MessageId:   fmt.Sprintf("frame_%s_end", st.opts.StreamId),
+		Timestamp:   time.Now().UnixMilli(),
+		AgentId:     c.currentAgentID(),
+		ScreenFrame: end,
+	})
+}
+
+// nextStreamFrame 截取一帧放入会话的帧槽位，上一帧尚未发出时跳过本帧（计入 end.Dropped）
+func (c *Client) nextStreamFrame(s *session, st *screenStream, end *WsScreenFrame) error {
+	if len(s.frames) > 0 {
+		end.Dropped++
+		return nil
+	}
+	frame, err := captureStreamFrame(st.opts)
+	if err != nil {
+		return err
+	}
+	frame.StreamId, frame.Seq = st.opts.StreamId, end.Seq+1
+	select {
+	case s.frames <- &WsWorkerMessage{
+		MessageId:   fmt.Sprintf("frame_%s_%d", st.opts.StreamId, frame.Seq),
+		Timestamp:   time.Now().UnixMilli(),
+		AgentId:     c.currentAgentID(),
+		ScreenFrame: frame,
+	}:
+		end.Seq = frame.Seq
+	default:
+		end.Dropped++
+	}
+	return nil
+}
+
+// captureStreamFrame 截取主显示器并缩放、编码为 JPEG，不修改执行器的截图设置
+func captureStreamFrame(opts WsStartScreenStream) (*WsScreenFrame, error) {
+	img, err := captureScreenImage(-1)
+	if err != nil {
+		return nil, fmt.Errorf("截图失败: %w", err)
+	}
+	scaled, scale, err := screen.DownscaleImage(img, opts.MaxWidth)
+	if err != nil {
+		return nil, fmt.Errorf("缩放截图失败: %w", err)
+	}
+	encoded, err := screen.ImageToBase64(scaled, "jpeg", opts.Quality)
+	if err != nil {
+		return nil, fmt.Errorf("编码截图失败: %w", err)
+	}
+	return &WsScreenFrame{Image: encoded, Width: scaled.Bounds().Dx(), Height: scaled.Bounds().Dy(), Scale: scale}, nil
+}
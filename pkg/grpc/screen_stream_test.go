@@ -0,0 +1,155 @@
+package grpc
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// streamServer 启动服务端，返回向 Worker 发送消息的函数和收到的 Worker 消息
+func streamServer(t *testing.T, client *Client) (send func(*WsServerMessage), received <-chan *WsWorkerMessage) {
+	messages := make(chan *WsWorkerMessage, 100)
+	conns := make(chan *websocket.Conn, 1)
+	server, _ := newAgentServer(t, func(n int32, conn *websocket.Conn) {
+		conns <- conn
+		for {
+			var msg WsWorkerMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg.Heartbeat == nil {
+				messages <- &msg
+			}
+		}
+	})
+	connectTo(t, client, server)
+	conn := <-conns
+	return func(msg *WsServerMessage) {
+		if err := conn.WriteJSON(msg); err != nil {
+			t.Fatal(err)
+		}
+	}, messages
+}
+
+// nextWorkerMessage 等待下一条 Worker 消息
+func nextWorkerMessage(t *testing.T, received <-chan *WsWorkerMessage) *WsWorkerMessage {
+	t.Helper()
+	select {
+	case msg := <-received:
+		return msg
+	case <-time.After(3 * time.Second):
+		t.Fatal("等待 Worker 消息超时")
+		return nil
+	}
+}
+
+func TestScreenStream(t *testing.T) {
+	fakeRemoteData(t, nil, nil)
+	client := NewClient(&ClientConfig{HeartbeatInterval: 60})
+	send, received := streamServer(t, client)
+
+	send(&WsServerMessage{MessageId: "start_1", StartScreenStream: &WsStartScreenStream{StreamId: "s1", Fps: 10, MaxWidth: 640, DurationSeconds: 1}})
+	msg := nextWorkerMessage(t, received)
+	if msg.DataResponse == nil || !msg.DataResponse.Success || msg.DataResponse.RequestType != RequestTypeStartScreenStream {
+		t.Fatalf("应回复开始成功, 实际 %+v", msg.DataResponse)
+	}
+	if !strings.Contains(msg.DataResponse.PayloadJson, `"fps":2`) {
+		t.Errorf("帧率应限制为 2, 实际 %s", msg.DataResponse.PayloadJson)
+	}
+
+	// 1 秒内最多 3 帧（立即一帧 + 每 0.5 秒一帧），之后因到达时长结束
+	var frames []*WsScreenFrame
+	for {
+		frame := nextWorkerMessage(t, received).ScreenFrame
+		if frame == nil {
+			t.Fatal("应只收到屏幕帧")
+		}
+		if frame.Ended {
+			if frame.Reason != screenStreamDuration || frame.Seq != int64(len(frames)) {
+				t.Errorf("结束通知不正确: %+v (收到 %d 帧)", frame, len(frames))
+			}
+			break
+		}
+		frames = append(frames, frame)
+	}
+	if len(frames) == 0 || len(frames) > 3 {
+		t.Fatalf("应按帧率发送 1–3 帧, 实际 %d", len(frames))
+	}
+	if f := frames[0]; f.StreamId != "s1" || f.Seq != 1 || f.Width != 640 || !strings.HasPrefix(f.Image, "data:image/jpeg;base64,") {
+		t.Errorf("帧内容不正确: seq=%d width=%d", f.Seq, f.Width)
+	}
+	waitFor(t, "屏幕流退出", func() bool {
+		client.mu.RLock()
+		defer client.mu.RUnlock()
+		return client.stream == nil
+	})
+}
+
+func TestScreenStreamStopAndReplace(t *testing.T) {
+	fakeRemoteData(t, nil, nil)
+	client := NewClient(&ClientConfig{HeartbeatInterval: 60})
+	send, received := streamServer(t, client)
+
+	// 读取直到收到指定屏幕流的结束通知
+	ended := func(streamID string) *WsScreenFrame {
+		t.Helper()
+		for {
+			if frame := nextWorkerMessage(t, received).ScreenFrame; frame != nil && frame.Ended && frame.StreamId == streamID {
+				return frame
+			}
+		}
+	}
+
+	send(&WsServerMessage{MessageId: "start_1", StartScreenStream: &WsStartScreenStream{StreamId: "s1", Fps: 0.5}})
+	send(&WsServerMessage{MessageId: "start_2", StartScreenStream: &WsStartScreenStream{StreamId: "s2", Fps: 0.5}})
+	if frame := ended("s1"); frame.Reason != screenStreamReplaced {
+		t.Errorf("新的屏幕流应替换旧的, 实际 %+v", frame)
+	}
+
+	// 不匹配的 streamId 被忽略
+	send(&WsServerMessage{StopScreenStream: &WsStopScreenStream{StreamId: "s1"}})
+	send(&WsServerMessage{StopScreenStream: &WsStopScreenStream{StreamId: "s2"}})
+	if frame := ended("s2"); frame.Reason != screenStreamStopped {
+		t.Errorf("应因 stopScreenStream 结束, 实际 %+v", frame)
+	}
+}
+
+func TestScreenStreamRejected(t *testing.T) {
+	setGranted := fakeRemoteData(t, nil, nil)
+	client := NewClient(nil)
+
+	// 未连接
+	if _, err := client.startScreenStream(&WsStartScreenStream{StreamId: "s1"}); err == nil {
+		t.Error("未连接时应拒绝")
+	}
+
+	setGranted(false)
+	client.handleServerMessage(&WsServerMessage{MessageId: "start_1", StartScreenStream: &WsStartScreenStream{StreamId: "s1"}})
+	resp := nextMessage(client).DataResponse
+	if resp == nil || resp.Success || resp.Message != screenRecordingDeniedMessage || !strings.Contains(resp.PayloadJson, remoteErrScreenRecordingDenied) {
+		t.Errorf("未授予屏幕录制权限时应拒绝, 实际 %+v", resp)
+	}
+}
+
+func TestScreenStreamDropsFramesWhileSlotFull(t *testing.T) {
+	fakeRemoteData(t, nil, nil)
+	client := NewClient(nil)
+	s := &session{frames: make(chan *WsWorkerMessage, 1)}
+	st := &screenStream{opts: WsStartScreenStream{StreamId: "s1", MaxWidth: 640, Quality: 50}}
+	end := &WsScreenFrame{}
+
+	// 上一帧尚未发出时跳过，不截图也不阻塞
+	for range 3 {
+		if err := client.nextStreamFrame(s, st, end); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if end.Seq != 1 || end.Dropped != 2 {
+		t.Errorf("应发送 1 帧、跳过 2 帧, 实际 seq=%d dropped=%d", end.Seq, end.Dropped)
+	}
+	if msg := nextMessage(client); msg != nil {
+		t.Errorf("屏幕帧不应进入发送队列: %+v", msg)
+	}
+}
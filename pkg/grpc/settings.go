@@ -0,0 +1,87 @@
+package grpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RequestTypeUpdateSettings 设置下发的响应类型（WsDataResponse.RequestType）
+const RequestTypeUpdateSettings = "UPDATE_SETTINGS"
+
+// maxHeartbeatInterval 服务端可下发的最大心跳间隔（秒）
+const maxHeartbeatInterval = 3600
+
+// settingsLogLevels 支持的日志级别
+var settingsLogLevels = map[string]bool{"DEBUG": true, "INFO": true, "WARN": true, "ERROR": true}
+
+// validateSettings 校验下发的设置，任一取值非法时整体拒绝
+func validateSettings(s *WsUpdateSettings) error {
+	var errs []error
+	if s.HeartbeatIntervalSeconds < 0 || s.HeartbeatIntervalSeconds > maxHeartbeatInterval {
+		errs = append(errs, fmt.Errorf("心跳间隔应在 1-%d 秒之间（当前 %d）", maxHeartbeatInterval, s.HeartbeatIntervalSeconds))
+	}
+	if s.LogLevel != "" && !settingsLogLevels[strings.ToUpper(s.LogLevel)] {
+		errs = append(errs, fmt.Errorf("无效的日志级别 %q，应为 DEBUG、INFO、WARN 或 ERROR", s.LogLevel))
+	}
+	if s.ScreenshotQuality < 0 || s.ScreenshotQuality > 100 {
+		errs = append(errs, fmt.Errorf("截图质量应在 1-100 之间（当前 %d）", s.ScreenshotQuality))
+	}
+	return errors.Join(errs...)
+}
+
+// SetSettingsCallback 设置服务端下发设置的回调
+func (c *Client) SetSettingsCallback(callback SettingsCallback) {
+	c.mu.Lock()
+	c.onSettings = callback
+	c.mu.Unlock()
+}
+
+// applySettings 校验并应用下发的设置：心跳间隔由客户端应用，其余设置和保存交给 SettingsCallback
+func (c *Client) applySettings(s *WsUpdateSettings) error {
+	if err := validateSettings(s); err != nil {
+		return err
+	}
+	s.LogLevel = strings.ToUpper(s.LogLevel)
+
+	c.mu.RLock()
+	callback := c.onSettings
+	c.mu.RUnlock()
+
+	if callback == nil {
+		if s.LogLevel != "" || s.ScreenshotQuality != 0 || s.Persist {
+			return errors.New("Worker 不支持下发日志级别、截图质量或保存设置")
+		}
+	} else if err := callback(s); err != nil {
+		return err
+	}
+
+	if s.HeartbeatIntervalSeconds > 0 {
+		c.SetHeartbeatInterval(s.HeartbeatIntervalSeconds)
+	}
+	return nil
+}
+
+// handleUpdateSettings 处理设置下发，以 DataResponse 回复处理结果（成功时 payload 为已应用的设置）
+func (c *Client) handleUpdateSettings(msgID string, s *WsUpdateSettings) {
+	c.log("INFO", fmt.Sprintf("Received settings update: heartbeat=%ds logLevel=%q screenshotQuality=%d persist=%v",
+		s.HeartbeatIntervalSeconds, s.LogLevel, s.ScreenshotQuality, s.Persist))
+
+	response := &WsDataResponse{RequestType: RequestTypeUpdateSettings, Success: true, PayloadJson: "{}"}
+	if err := c.applySettings(s); err != nil {
+		c.log("WARN", fmt.Sprintf("Settings update rejected: %v", err))
+		response.Success = false
+		response.Message = err.Error()
+	} else if data, err := json.Marshal(s); err == nil {
+		response.PayloadJson = string(data)
+	}
+
+	c.sendMessage(&WsWorkerMessage{
+		MessageId:    msgID,
+		Timestamp:    time.Now().UnixMilli(),
+		AgentId:      c.currentAgentID(),
+		DataResponse: response,
+	})
+}
@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// updateSettings 处理一条设置下发消息并返回回复
+func updateSettings(t *testing.T, client *Client, s *WsUpdateSettings) *WsDataResponse {
+	t.Helper()
+	client.handleServerMessage(&WsServerMessage{MessageId: "settings_1", UpdateSettings: s})
+	msg := nextMessage(client)
+	if msg == nil || msg.DataResponse == nil || msg.MessageId != "settings_1" {
+		t.Fatalf("应以 DataResponse 回复设置下发, 实际 %+v", msg)
+	}
+	if msg.DataResponse.RequestType != RequestTypeUpdateSettings {
+		t.Errorf("RequestType = %s", msg.DataResponse.RequestType)
+	}
+	return msg.DataResponse
+}
+
+func TestUpdateSettings(t *testing.T) {
+	client := NewClient(&ClientConfig{HeartbeatInterval: 5})
+
+	// 未设置回调时只支持心跳间隔
+	if resp := updateSettings(t, client, &WsUpdateSettings{HeartbeatIntervalSeconds: 10}); !resp.Success {
+		t.Errorf("应用心跳间隔失败: %s", resp.Message)
+	}
+	if resp := updateSettings(t, client, &WsUpdateSettings{LogLevel: "debug"}); resp.Success {
+		t.Error("未设置回调时应拒绝日志级别")
+	}
+
+	var applied *WsUpdateSettings
+	client.SetSettingsCallback(func(s *WsUpdateSettings) error {
+		applied = s
+		return nil
+	})
+	for name, s := range map[string]*WsUpdateSettings{
+		"heartbeat": {HeartbeatIntervalSeconds: -1, LogLevel: "DEBUG"},
+		"too long":  {HeartbeatIntervalSeconds: maxHeartbeatInterval + 1},
+		"log level": {HeartbeatIntervalSeconds: 30, LogLevel: "VERBOSE"},
+		"quality":   {ScreenshotQuality: 101},
+	} {
+		if resp := updateSettings(t, client, s); resp.Success || resp.Message == "" {
+			t.Errorf("%s: 非法取值应返回错误", name)
+		}
+	}
+	if applied != nil || client.config.HeartbeatInterval != 10 {
+		t.Fatalf("非法设置不应部分应用: applied=%+v heartbeat=%d", applied, client.config.HeartbeatInterval)
+	}
+
+	resp := updateSettings(t, client, &WsUpdateSettings{HeartbeatIntervalSeconds: 30, LogLevel: "warn", ScreenshotQuality: 80, Persist: true})
+	if !resp.Success {
+		t.Fatalf("合法设置应成功: %s", resp.Message)
+	}
+	var echoed WsUpdateSettings
+	json.Unmarshal([]byte(resp.PayloadJson), &echoed)
+	if echoed != (WsUpdateSettings{HeartbeatIntervalSeconds: 30, LogLevel: "WARN", ScreenshotQuality: 80, Persist: true}) {
+		t.Errorf("应回复已应用的设置, 实际 %s", resp.PayloadJson)
+	}
+	if applied == nil || applied.LogLevel != "WARN" || !applied.Persist || client.config.HeartbeatInterval != 30 {
+		t.Errorf("设置未应用: applied=%+v heartbeat=%d", applied, client.config.HeartbeatInterval)
+	}
+
+	// 回调失败（如保存配置失败）时返回错误
+	client.SetSettingsCallback(func(*WsUpdateSettings) error { return errors.New("磁盘已满") })
+	if resp := updateSettings(t, client, &WsUpdateSettings{ScreenshotQuality: 50, Persist: true}); resp.Success || !strings.Contains(resp.Message, "磁盘已满") {
+		t.Errorf("回调失败时应返回错误, 实际 %+v", resp)
+	}
+}
+
+func TestHeartbeatIntervalChangeResetsTicker(t *testing.T) {
+	var heartbeats atomic.Int32
+	server, _ := newAgentServer(t, func(n int32, conn *websocket.Conn) {
+		for {
+			var msg WsWorkerMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg.Heartbeat != nil {
+				heartbeats.Add(1)
+			}
+		}
+	})
+	client := NewClient(&ClientConfig{HeartbeatInterval: 60})
+	if err := client.Connect(strings.TrimPrefix(server.URL, "http://"), "ak", "sk"); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect()
+
+	// 不需要等原来的 60 秒间隔结束，新间隔立即生效
+	time.Sleep(100 * time.Millisecond)
+	client.SetHeartbeatInterval(1)
+	waitFor(t, "按新间隔发送心跳", func() bool { return heartbeats.Load() >= 1 })
+}
@@ -0,0 +1,62 @@
+package grpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrTLS TLS 证书配置错误或握手失败（服务端证书不受信任等），与认证被拒绝（ErrAuthRejected）区分
+var ErrTLS = errors.New("TLS 错误")
+
+// TLSConfig 根据证书选项构造 wss:// 连接使用的 tls.Config，未设置任何选项时返回 nil（使用系统默认校验）
+// CACertFile 中的证书追加到系统根证书之后；客户端证书和私钥必须同时设置
+func (c *ClientConfig) TLSConfig() (*tls.Config, error) {
+	if c.CACertFile == "" && c.ClientCertFile == "" && c.ClientKeyFile == "" && !c.InsecureSkipVerify {
+		return nil, nil
+	}
+	cfg := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+
+	if c.CACertFile != "" {
+		data, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书失败: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("CA 证书 %s 中没有有效的 PEM 证书", c.CACertFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if c.ClientCertFile != "" || c.ClientKeyFile != "" {
+		if c.ClientCertFile == "" || c.ClientKeyFile == "" {
+			return nil, errors.New("客户端证书和私钥必须同时设置")
+		}
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// isTLSError 判断连接错误是否由 TLS 握手导致（证书不受信任、主机名不匹配、服务端拒绝客户端证书等）
+func isTLSError(err error) bool {
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		invalid          x509.CertificateInvalidError
+		hostname         x509.HostnameError
+		verification     *tls.CertificateVerificationError
+		recordHeader     tls.RecordHeaderError
+		alert            tls.AlertError
+	)
+	return errors.As(err, &unknownAuthority) || errors.As(err, &invalid) || errors.As(err, &hostname) ||
+		errors.As(err, &verification) || errors.As(err, &recordHeader) || errors.As(err, &alert)
+}
@@ -0,0 +1,98 @@
+package grpc
+
+import (
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// newRejectingTLSServer 启动拒绝所有认证请求的 wss 服务端，用于区分 TLS 失败和认证失败
+func newRejectingTLSServer(t *testing.T) *httptest.Server {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.ReadMessage()
+		conn.WriteJSON(WsConnectResponse{Type: "connect_response", Success: false, Message: "invalid key"})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestConnectTLS(t *testing.T) {
+	server := newRejectingTLSServer(t)
+	addr := "wss://" + strings.TrimPrefix(server.URL, "https://")
+
+	// 自签名证书不受信任：TLS 错误而不是认证失败
+	err := NewClient(nil).Connect(addr, "ak", "sk")
+	if !errors.Is(err, ErrTLS) || errors.Is(err, ErrAuthRejected) {
+		t.Fatalf("证书不受信任时应返回 ErrTLS, 实际 %v", err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for name, config := range map[string]*ClientConfig{
+		"ca":       {CACertFile: caFile},
+		"insecure": {InsecureSkipVerify: true},
+	} {
+		err := NewClient(config).Connect(addr, "ak", "sk")
+		if !errors.Is(err, ErrAuthRejected) || errors.Is(err, ErrTLS) {
+			t.Errorf("%s: 握手应成功并由服务端拒绝认证, 实际 %v", name, err)
+		}
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	if cfg, err := DefaultConfig().TLSConfig(); cfg != nil || err != nil {
+		t.Errorf("未设置证书选项时应使用默认配置, 实际 %v %v", cfg, err)
+	}
+
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.pem")
+	os.WriteFile(invalid, []byte("not a certificate"), 0o600)
+	for name, config := range map[string]*ClientConfig{
+		"missing ca":   {CACertFile: filepath.Join(dir, "missing.pem")},
+		"invalid ca":   {CACertFile: invalid},
+		"cert only":    {ClientCertFile: invalid},
+		"invalid pair": {ClientCertFile: invalid, ClientKeyFile: invalid},
+	} {
+		if _, err := config.TLSConfig(); err == nil {
+			t.Errorf("%s: 应返回错误", name)
+		}
+	}
+
+	// 证书配置错误同样归为 ErrTLS
+	err := NewClient(&ClientConfig{CACertFile: invalid}).Connect("wss://127.0.0.1:1", "ak", "sk")
+	if !errors.Is(err, ErrTLS) {
+		t.Errorf("证书配置错误时应返回 ErrTLS, 实际 %v", err)
+	}
+}
+
+func TestUpdateTLSTriggersReconnect(t *testing.T) {
+	client := NewClient(nil)
+	client.UpdateCredentials("a:1", "ak", "sk")
+	if !client.UpdateTLS("/etc/ca.pem", "", "", false) {
+		t.Fatal("TLS 选项变化应返回 true")
+	}
+	if client.UpdateTLS("/etc/ca.pem", "", "", false) {
+		t.Error("相同 TLS 选项不应视为变化")
+	}
+	if changed, _ := client.UpdateCredentials("a:1", "ak", "sk"); !changed {
+		t.Error("TLS 选项变化后 UpdateCredentials 应视为有变化")
+	}
+	if changed, _ := client.UpdateCredentials("a:1", "ak", "sk"); changed {
+		t.Error("变化只应生效一次")
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/zoeyai/zoeyworker/pkg/cmdutil"
+	"github.com/zoeyai/zoeyworker/pkg/permissions"
 )
 
 // Python 检测缓存：启动时检测一次，后续直接使用
@@ -24,6 +26,8 @@ const (
 	StatusConnecting   ClientStatus = "connecting"
 	StatusConnected    ClientStatus = "connected"
 	StatusReconnecting ClientStatus = "reconnecting"
+	// StatusStopping Disconnect 正在停止收发协程
+	StatusStopping ClientStatus = "stopping"
 )
 
 // SystemInfo 系统信息
@@ -41,6 +45,21 @@ type Capabilities struct {
 	PythonAvailable bool   `json:"python_available"`
 	PythonVersion   string `json:"python_version,omitempty"`
 	PythonPath      string `json:"python_path,omitempty"`
+	// OCRProvider 配置的 OCR 执行提供者，OCRActiveProvider 为实际使用的（OCR 尚未加载时为空）
+	OCRProvider       string `json:"ocr_provider,omitempty"`
+	OCRActiveProvider string `json:"ocr_active_provider,omitempty"`
+	// OCRInstalled OCR 插件已安装，OCRModelVersion 为模型版本
+	OCRInstalled    bool   `json:"ocr_installed"`
+	OCRModelVersion string `json:"ocr_model_version,omitempty"`
+	// UIAAvailable 支持 UI Automation（Windows）
+	UIAAvailable bool `json:"uia_available"`
+	// DisplayCount 显示器数量，ScreenWidth/ScreenHeight/ScaleFactor 为主显示器的分辨率和缩放比例
+	DisplayCount int     `json:"display_count"`
+	ScreenWidth  int     `json:"screen_width,omitempty"`
+	ScreenHeight int     `json:"screen_height,omitempty"`
+	ScaleFactor  float64 `json:"scale_factor,omitempty"`
+	// Permissions macOS 辅助功能和屏幕录制授权状态（其他系统为 nil）
+	Permissions *permissions.PermissionStatus `json:"permissions,omitempty"`
 }
 
 // WarmupSystemInfo 预热系统信息检测（启动时调用，异步执行耗时操作）
@@ -81,13 +100,17 @@ func GetSystemInfo() *SystemInfo {
 		cachedPythonInfo = detectPythonEnv()
 	})
 
+	// 复制缓存后附加 OCR、显示器、授权等运行期间可能变化的能力（不缓存）
+	caps := *cachedPythonInfo
+	detectRuntimeCapabilities(&caps)
+
 	return &SystemInfo{
 		Hostname:     hostname,
 		Platform:     platform,
 		OSVersion:    runtime.GOOS + "/" + runtime.GOARCH,
 		AgentVersion: Version,
 		IPAddress:    getLocalIP(),
-		Capabilities: cachedPythonInfo,
+		Capabilities: &caps,
 	}
 }
 
@@ -150,8 +173,32 @@ type ClientConfig struct {
 	HeartbeatInterval int
 	// MaxHeartbeatFailures 最大心跳失败次数
 	MaxHeartbeatFailures int
+	// PingInterval WebSocket ping 帧间隔（秒），0 不发送 ping 也不检测读超时
+	PingInterval int
+	// ReadTimeout 超过该时间（秒）未收到任何数据（包括 pong）视为连接已断开并重连，0 为 PingInterval 的 3 倍
+	ReadTimeout int
 	// ReconnectDelays 重连延迟序列（秒）
 	ReconnectDelays []int
+	// DisableReconnect 连接断开后不自动重连
+	DisableReconnect bool
+	// CACertFile 额外信任的 CA 证书（PEM），用于内部 CA 签发的服务端证书
+	CACertFile string
+	// ClientCertFile 客户端证书（PEM），服务端要求双向 TLS 时与 ClientKeyFile 一起设置
+	ClientCertFile string
+	// ClientKeyFile 客户端私钥（PEM）
+	ClientKeyFile string
+	// InsecureSkipVerify 跳过服务端证书校验（仅用于测试环境）
+	InsecureSkipVerify bool
+	// ResultAckTimeout 服务端支持 resultAck 时，任务结果超过该时间（秒）未确认则重发，0 为 30 秒
+	ResultAckTimeout int
+	// DataDir Worker 数据目录，保存客户端标识，心跳上报其所在磁盘的使用率；为空时不上报磁盘使用率且每次启动生成新的客户端标识
+	DataDir string
+	// OutboxDir 任务消息溢出目录：网络中断期间内存队列满后写入磁盘，重连后按顺序发送；为空时队列满后丢弃
+	OutboxDir string
+	// RemoteControl 允许服务端在屏幕流期间发送鼠标键盘事件（远程控制），默认关闭
+	RemoteControl bool
+	// CorrectClockSkew 发送消息时按估计的时钟偏差校正消息 Timestamp（换算为服务端时钟），默认关闭，只随心跳上报偏差
+	CorrectClockSkew bool
 }
 
 // DefaultConfig 默认配置
@@ -159,25 +206,52 @@ func DefaultConfig() *ClientConfig {
 	return &ClientConfig{
 		HeartbeatInterval:    5,
 		MaxHeartbeatFailures: 3,
+		PingInterval:         15,
 		ReconnectDelays:      []int{2, 5, 10, 30, 60},
 	}
 }
 
+// keepalive 返回 ping 间隔和读超时，PingInterval 未设置时均为 0（不检测）
+func (c *ClientConfig) keepalive() (interval, timeout time.Duration) {
+	if c.PingInterval <= 0 {
+		return 0, 0
+	}
+	interval = time.Duration(c.PingInterval) * time.Second
+	timeout = time.Duration(c.ReadTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 3 * interval
+	}
+	return interval, timeout
+}
+
+// resultAckTimeout 返回任务结果确认超时
+func (c *ClientConfig) resultAckTimeout() time.Duration {
+	if c.ResultAckTimeout <= 0 {
+		return defaultResultAckTimeout
+	}
+	return time.Duration(c.ResultAckTimeout) * time.Second
+}
+
 // StatusCallback 状态变更回调函数
 type StatusCallback func(status ClientStatus)
 
 // TaskCallback 任务回调函数
 type TaskCallback func(taskID, taskType, payloadJSON string)
 
+// SettingsCallback 服务端下发设置回调：应用日志级别、截图质量等非连接设置，Persist 为 true 时保存到配置文件
+// 心跳间隔由客户端自行应用；返回错误时服务端收到失败响应
+type SettingsCallback func(settings *WsUpdateSettings) error
+
 // CancelCallback 取消任务回调函数
 type CancelCallback func(taskID string) bool
 
 // ExecutorStatusCallback 执行器状态回调函数
-// 返回: status, currentTaskID, currentTaskType, taskStartedAt, runningCount
-type ExecutorStatusCallback func() (string, string, string, int64, int)
+// 返回: status, currentTaskID, currentTaskType, taskStartedAt, runningCount, runningTaskIDs
+type ExecutorStatusCallback func() (string, string, string, int64, int, []string)
 
 // LogEntry 日志条目
 type LogEntry struct {
+	Seq       int64  `json:"seq"` // 序号，从 1 开始递增（GetLogsSince 据此只返回新日志）
 	Timestamp string `json:"timestamp"`
 	Level     string `json:"level"`
 	Message   string `json:"message"`
@@ -0,0 +1,383 @@
+// Package history 保存客户端本地执行过的任务记录（任务、步骤、截图文件），供 GUI 查看执行历史
+//
+// 执行器通过 Store 接口写入；SQLiteStore 将运行和步骤保存在 SQLite 数据库（GUI 中为 ~/.zoey-worker/history.db），
+// 截图保存在数据库同目录的 history/screenshots/<任务 ID>/，打开和写入时按保留策略清理过期记录及其截图
+package history
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Run 一次任务执行
+type Run struct {
+	ID            string  `json:"id"` // 任务 ID
+	TaskType      string  `json:"task_type"`
+	Status        string  `json:"status"` // SUCCESS, FAILED, CANCELLED, TIMEOUT ...
+	Success       bool    `json:"success"`
+	FailureReason string  `json:"failure_reason,omitempty"`
+	Error         string  `json:"error,omitempty"`
+	StartedAt     int64   `json:"started_at"` // 开始时间（毫秒时间戳）
+	DurationMs    int64   `json:"duration_ms"`
+	Steps         []*Step `json:"steps,omitempty"` // 批量任务的步骤（按完成顺序）
+}
+
+// Step 批量任务中的一个步骤
+type Step struct {
+	StepExecutionID  string `json:"step_execution_id"` // 服务端的步骤执行记录 ID，没有时为 "<任务 ID>#<序号>"
+	StepID           string `json:"step_id,omitempty"`
+	ActionType       string `json:"action_type,omitempty"`
+	Status           string `json:"status"`
+	FailureReason    string `json:"failure_reason,omitempty"`
+	Error            string `json:"error,omitempty"`
+	DurationMs       int64  `json:"duration_ms"`
+	ScreenshotBefore string `json:"screenshot_before,omitempty"` // 截图文件路径
+	ScreenshotAfter  string `json:"screenshot_after,omitempty"`
+}
+
+// Store 执行器写入历史记录的接口
+type Store interface {
+	// ScreenshotDir 任务 runID 的截图目录（由调用方在写入截图时创建）
+	ScreenshotDir(runID string) string
+	// Save 保存结束的任务，保存后调用方不再修改 run
+	Save(run *Run) error
+}
+
+// Retention 保留策略，零值表示不限制
+type Retention struct {
+	MaxAge  time.Duration // 按开始时间保留的时长
+	MaxRuns int           // 最多保留的运行次数
+}
+
+// DefaultRetention 默认保留最近 30 天、最多 500 次运行
+var DefaultRetention = Retention{MaxAge: 30 * 24 * time.Hour, MaxRuns: 500}
+
+// ErrNotFound 查询的步骤不存在
+var ErrNotFound = errors.New("历史记录不存在")
+
+// schema 运行按保存顺序（seq）编号，步骤按运行内的顺序（idx）保存
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	seq            INTEGER PRIMARY KEY AUTOINCREMENT,
+	id             TEXT    NOT NULL,
+	task_type      TEXT    NOT NULL DEFAULT '',
+	status         TEXT    NOT NULL DEFAULT '',
+	success        INTEGER NOT NULL DEFAULT 0,
+	failure_reason TEXT    NOT NULL DEFAULT '',
+	error          TEXT    NOT NULL DEFAULT '',
+	started_at     INTEGER NOT NULL,
+	duration_ms    INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS runs_id ON runs(id);
+CREATE INDEX IF NOT EXISTS runs_started_at ON runs(started_at);
+CREATE TABLE IF NOT EXISTS steps (
+	run_seq           INTEGER NOT NULL,
+	idx               INTEGER NOT NULL,
+	step_execution_id TEXT    NOT NULL,
+	step_id           TEXT    NOT NULL DEFAULT '',
+	action_type       TEXT    NOT NULL DEFAULT '',
+	status            TEXT    NOT NULL DEFAULT '',
+	failure_reason    TEXT    NOT NULL DEFAULT '',
+	error             TEXT    NOT NULL DEFAULT '',
+	duration_ms       INTEGER NOT NULL DEFAULT 0,
+	screenshot_before TEXT    NOT NULL DEFAULT '',
+	screenshot_after  TEXT    NOT NULL DEFAULT '',
+	PRIMARY KEY (run_seq, idx)
+);
+CREATE INDEX IF NOT EXISTS steps_step_execution_id ON steps(step_execution_id);
+`
+
+// SQLiteStore 基于 SQLite 数据库的历史记录
+type SQLiteStore struct {
+	db        *sql.DB
+	dir       string // 截图根目录
+	retention Retention
+	now       func() time.Time
+}
+
+// Open 打开 path 处的历史数据库（不存在时创建），并按保留策略清理
+func Open(path string, retention Retention) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建历史记录目录失败: %w", err)
+	}
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("打开历史数据库失败: %w", err)
+	}
+	// 执行器和 GUI 共用一个连接，写入互相排队，不会出现 SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("初始化历史数据库失败: %w", err)
+	}
+
+	s := &SQLiteStore{
+		db:        db,
+		dir:       filepath.Join(filepath.Dir(path), "history", "screenshots"),
+		retention: retention,
+		now:       time.Now,
+	}
+	if err := s.prune(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close 关闭数据库
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// ScreenshotDir 任务 runID 的截图目录
+func (s *SQLiteStore) ScreenshotDir(runID string) string {
+	return filepath.Join(s.dir, safeName(runID))
+}
+
+// Save 保存一次运行及其步骤，并按保留策略清理
+func (s *SQLiteStore) Save(run *Run) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("保存历史记录失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO runs (id, task_type, status, success, failure_reason, error, started_at, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.ID, run.TaskType, run.Status, run.Success, run.FailureReason, run.Error, run.StartedAt, run.DurationMs)
+	if err != nil {
+		return fmt.Errorf("保存历史记录失败: %w", err)
+	}
+	seq, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("保存历史记录失败: %w", err)
+	}
+	for i, step := range run.Steps {
+		if _, err := tx.Exec(`INSERT INTO steps (run_seq, idx, step_execution_id, step_id, action_type, status, failure_reason, error,
+			duration_ms, screenshot_before, screenshot_after) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			seq, i, step.StepExecutionID, step.StepID, step.ActionType, step.Status, step.FailureReason, step.Error,
+			step.DurationMs, step.ScreenshotBefore, step.ScreenshotAfter); err != nil {
+			return fmt.Errorf("保存历史记录失败: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("保存历史记录失败: %w", err)
+	}
+	return s.prune()
+}
+
+// prune 删除超出保留策略的记录（过期的，以及未过期中超出数量的最早记录）及其截图
+func (s *SQLiteStore) prune() error {
+	cutoff := int64(0)
+	if s.retention.MaxAge > 0 {
+		cutoff = s.now().Add(-s.retention.MaxAge).UnixMilli()
+	}
+	limit := -1 // SQLite 中 LIMIT -1 表示不限制
+	if s.retention.MaxRuns > 0 {
+		limit = s.retention.MaxRuns
+	}
+	const dropped = `started_at < ?1 OR seq NOT IN (SELECT seq FROM runs WHERE started_at >= ?1 ORDER BY seq DESC LIMIT ?2)`
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("清理历史记录失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT DISTINCT id FROM runs WHERE `+dropped, cutoff, limit)
+	if err != nil {
+		return fmt.Errorf("清理历史记录失败: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("清理历史记录失败: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("清理历史记录失败: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(`DELETE FROM runs WHERE `+dropped, cutoff, limit); err != nil {
+		return fmt.Errorf("清理历史记录失败: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM steps WHERE run_seq NOT IN (SELECT seq FROM runs)`); err != nil {
+		return fmt.Errorf("清理历史记录失败: %w", err)
+	}
+	// 同一任务 ID 重复执行时共用截图目录，仍有记录引用时保留
+	var unused []string
+	for _, id := range ids {
+		var inUse bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM runs WHERE id = ?)`, id).Scan(&inUse); err != nil {
+			return fmt.Errorf("清理历史记录失败: %w", err)
+		}
+		if !inUse {
+			unused = append(unused, id)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("清理历史记录失败: %w", err)
+	}
+	for _, id := range unused {
+		os.RemoveAll(s.ScreenshotDir(id))
+	}
+	return nil
+}
+
+// Filter 查询条件，空字段不过滤
+type Filter struct {
+	Status   string `json:"status"`    // 运行状态（SUCCESS、FAILED ...）
+	TaskType string `json:"task_type"` // 任务类型
+	Query    string `json:"query"`     // 任务 ID 或错误信息包含的文字（忽略大小写）
+}
+
+// where 转为 SQL 条件及参数
+func (f Filter) where() (string, []any) {
+	conds := []string{"1"}
+	var args []any
+	if f.Status != "" {
+		conds = append(conds, "status = ? COLLATE NOCASE")
+		args = append(args, f.Status)
+	}
+	if f.TaskType != "" {
+		conds = append(conds, "task_type = ?")
+		args = append(args, f.TaskType)
+	}
+	if q := strings.ToLower(strings.TrimSpace(f.Query)); q != "" {
+		conds = append(conds, "(instr(lower(id), ?) > 0 OR instr(lower(error), ?) > 0)")
+		args = append(args, q, q)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// DefaultPageSize List 未指定每页数量时的默认值
+const DefaultPageSize = 20
+
+// Page 一页查询结果
+type Page struct {
+	Runs     []*Run // 从新到旧
+	Total    int    // 符合条件的总数
+	Page     int    // 页码（从 1 开始）
+	PageSize int
+}
+
+// List 按保存顺序从新到旧分页查询（page 从 1 开始，pageSize <= 0 时为 DefaultPageSize）
+func (s *SQLiteStore) List(page, pageSize int, filter Filter) (Page, error) {
+	page = max(page, 1)
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	result := Page{Page: page, PageSize: pageSize}
+	where, args := filter.where()
+
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM runs WHERE `+where, args...).Scan(&result.Total); err != nil {
+		return result, fmt.Errorf("查询历史记录失败: %w", err)
+	}
+	runs, err := s.queryRuns(`WHERE `+where+` ORDER BY seq DESC LIMIT ? OFFSET ?`, append(args, pageSize, (page-1)*pageSize)...)
+	if err != nil {
+		return result, err
+	}
+	result.Runs = runs
+	return result, nil
+}
+
+// Step 查找步骤及其所属的运行（同一步骤执行记录出现多次时返回最新的），不存在时返回 ErrNotFound
+func (s *SQLiteStore) Step(stepExecutionID string) (*Run, *Step, error) {
+	var seq, idx int64
+	err := s.db.QueryRow(`SELECT run_seq, idx FROM steps WHERE step_execution_id = ? ORDER BY run_seq DESC, idx DESC LIMIT 1`,
+		stepExecutionID).Scan(&seq, &idx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("查询历史记录失败: %w", err)
+	}
+	runs, err := s.queryRuns(`WHERE seq = ?`, seq)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(runs) == 0 || int(idx) >= len(runs[0].Steps) {
+		return nil, nil, ErrNotFound
+	}
+	return runs[0], runs[0].Steps[idx], nil
+}
+
+// queryRuns 查询运行（clause 为 WHERE 及之后的子句）并加载其步骤
+func (s *SQLiteStore) queryRuns(clause string, args ...any) ([]*Run, error) {
+	rows, err := s.db.Query(`SELECT seq, id, task_type, status, success, failure_reason, error, started_at, duration_ms FROM runs `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询历史记录失败: %w", err)
+	}
+	var runs []*Run
+	bySeq := make(map[int64]*Run)
+	for rows.Next() {
+		var seq int64
+		run := &Run{}
+		if err := rows.Scan(&seq, &run.ID, &run.TaskType, &run.Status, &run.Success, &run.FailureReason, &run.Error,
+			&run.StartedAt, &run.DurationMs); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("查询历史记录失败: %w", err)
+		}
+		runs = append(runs, run)
+		bySeq[seq] = run
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("查询历史记录失败: %w", err)
+	}
+	if len(runs) == 0 {
+		return runs, nil
+	}
+
+	seqs := make([]any, 0, len(bySeq))
+	for seq := range bySeq {
+		seqs = append(seqs, seq)
+	}
+	rows, err = s.db.Query(`SELECT run_seq, step_execution_id, step_id, action_type, status, failure_reason, error, duration_ms,
+		screenshot_before, screenshot_after FROM steps WHERE run_seq IN (?`+strings.Repeat(", ?", len(seqs)-1)+`) ORDER BY run_seq, idx`, seqs...)
+	if err != nil {
+		return nil, fmt.Errorf("查询历史记录失败: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var seq int64
+		step := &Step{}
+		if err := rows.Scan(&seq, &step.StepExecutionID, &step.StepID, &step.ActionType, &step.Status, &step.FailureReason,
+			&step.Error, &step.DurationMs, &step.ScreenshotBefore, &step.ScreenshotAfter); err != nil {
+			return nil, fmt.Errorf("查询历史记录失败: %w", err)
+		}
+		bySeq[seq].Steps = append(bySeq[seq].Steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("查询历史记录失败: %w", err)
+	}
+	return runs, nil
+}
+
+// safeName 将任务 ID 转为可用作目录名的字符串
+func safeName(id string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, id)
+	if strings.Trim(name, ".") == "" {
+		name = "_" + name
+	}
+	return name
+}
@@ -0,0 +1,149 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteStore_SaveListReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	s, err := Open(path, DefaultRetention)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UnixMilli()
+	for i := range 5 {
+		status := "SUCCESS"
+		if i%2 == 1 {
+			status = "FAILED"
+		}
+		run := &Run{ID: fmt.Sprintf("task-%d", i), TaskType: "debug_case", Status: status, StartedAt: now + int64(i), Error: fmt.Sprintf("err %d", i)}
+		run.Steps = []*Step{{StepExecutionID: fmt.Sprintf("task-%d#1", i), Status: status}, {StepExecutionID: fmt.Sprintf("task-%d#2", i), Status: "SKIPPED"}}
+		if err := s.Save(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list := func(page, pageSize int, filter Filter) Page {
+		t.Helper()
+		result, err := s.List(page, pageSize, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	page := list(1, 2, Filter{})
+	if page.Total != 5 || len(page.Runs) != 2 || page.Runs[0].ID != "task-4" || page.Runs[1].ID != "task-3" {
+		t.Errorf("第一页应为最新的两条: %+v", page)
+	}
+	if steps := page.Runs[0].Steps; len(steps) != 2 || steps[0].StepExecutionID != "task-4#1" || steps[1].Status != "SKIPPED" {
+		t.Errorf("步骤应按顺序加载: %+v", steps)
+	}
+	if page := list(3, 2, Filter{}); len(page.Runs) != 1 || page.Runs[0].ID != "task-0" {
+		t.Errorf("最后一页应为最早的记录: %+v", page)
+	}
+	if page := list(1, 0, Filter{Status: "failed"}); page.Total != 2 || page.PageSize != DefaultPageSize {
+		t.Errorf("按状态过滤应有 2 条: %+v", page)
+	}
+	if page := list(1, 0, Filter{Query: "ERR 2"}); page.Total != 1 || page.Runs[0].ID != "task-2" {
+		t.Errorf("按错误信息搜索应命中 task-2: %+v", page)
+	}
+
+	// 重新打开后记录不变
+	s.Close()
+	if s, err = Open(path, DefaultRetention); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if page := list(1, 10, Filter{}); page.Total != 5 {
+		t.Errorf("重新打开后应有 5 条, 实际 %d", page.Total)
+	}
+	run, step, err := s.Step("task-3#1")
+	if err != nil || run.ID != "task-3" || step.Status != "FAILED" {
+		t.Errorf("应找到步骤 task-3#1: %+v %+v %v", run, step, err)
+	}
+	if _, _, err := s.Step("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("不存在的步骤应返回 ErrNotFound: %v", err)
+	}
+}
+
+func TestSQLiteStore_Retention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	retention := Retention{MaxAge: time.Hour, MaxRuns: 3}
+	s, err := Open(path, retention)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	screenshot := func(id string) string {
+		path := filepath.Join(s.ScreenshotDir(id), "step1_before.jpg")
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("jpg"), 0644)
+		return path
+	}
+	total := func() (int, []*Run) {
+		t.Helper()
+		page, err := s.List(1, 10, Filter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return page.Total, page.Runs
+	}
+
+	expired := screenshot("old/1")
+	s.Save(&Run{ID: "old/1", StartedAt: now.Add(-2 * time.Hour).UnixMilli(), Steps: []*Step{{StepExecutionID: "old/1#1"}}})
+	if n, runs := total(); n != 0 {
+		t.Errorf("过期记录应被清理: %+v", runs)
+	}
+	if _, err := os.Stat(expired); !os.IsNotExist(err) {
+		t.Error("过期记录的截图应被删除")
+	}
+	if _, _, err := s.Step("old/1#1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("过期记录的步骤应被删除: %v", err)
+	}
+
+	first := screenshot("run-0")
+	shared := screenshot("run-1")
+	for i := range 4 {
+		s.Save(&Run{ID: fmt.Sprintf("run-%d", i), StartedAt: now.UnixMilli()})
+	}
+	// run-1 重复执行，较早的一次被清理时截图目录仍被引用
+	s.Save(&Run{ID: "run-1", StartedAt: now.UnixMilli()})
+	n, runs := total()
+	if n != 3 || runs[0].ID != "run-1" || runs[2].ID != "run-2" {
+		t.Errorf("应只保留最近 3 条: %+v", runs)
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Error("超出数量的记录截图应被删除")
+	}
+	if _, err := os.Stat(shared); err != nil {
+		t.Error("仍被引用的截图目录应保留")
+	}
+
+	// 重新打开时按新的保留策略清理
+	s.Close()
+	if s, err = Open(path, Retention{MaxRuns: 1}); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if n, runs := total(); n != 1 || runs[0].ID != "run-1" {
+		t.Errorf("重新打开后应只保留最新的记录: %+v", runs)
+	}
+}
+
+func TestSafeName(t *testing.T) {
+	for id, want := range map[string]string{
+		"task-1_a.b": "task-1_a.b",
+		"../x":       ".._x",
+		"..":         "_..",
+		"a/b\\c:d":   "a_b_c_d",
+	} {
+		if got := safeName(id); got != want {
+			t.Errorf("safeName(%q) = %q, 期望 %q", id, got, want)
+		}
+	}
+}
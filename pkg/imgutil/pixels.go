@@ -0,0 +1,26 @@
+// Package imgutil 图像处理的通用小工具
+package imgutil
+
+import (
+	"image"
+	"image/draw"
+	"io"
+)
+
+// WriteRGBA 将图像的 RGBA 像素逐行写入 w（非 *image.RGBA 先转换），用于计算画面哈希；
+// 只写入像素，尺寸、位置等前缀由调用方按需要自行写入
+func WriteRGBA(w io.Writer, img image.Image) {
+	bounds := img.Bounds()
+	rgba, ok := img.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(bounds)
+		draw.Draw(rgba, bounds, img, bounds.Min, draw.Src)
+	}
+
+	// 逐行写入，跳过 Stride 中的填充字节（子图像的 Pix 与父图像共享）
+	rowLen := bounds.Dx() * 4
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		offset := rgba.PixOffset(bounds.Min.X, y)
+		w.Write(rgba.Pix[offset : offset+rowLen])
+	}
+}
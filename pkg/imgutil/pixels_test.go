@@ -0,0 +1,30 @@
+package imgutil
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestWriteRGBA(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	img.Set(1, 1, color.RGBA{R: 1, G: 2, B: 3, A: 255})
+	img.Set(2, 1, color.RGBA{R: 4, G: 5, B: 6, A: 255})
+
+	// 子图像只写入自身范围的像素，不含父图像行中的其余字节
+	var sub bytes.Buffer
+	WriteRGBA(&sub, img.SubImage(image.Rect(1, 1, 3, 2)))
+	if want := []byte{1, 2, 3, 255, 4, 5, 6, 255}; !bytes.Equal(sub.Bytes(), want) {
+		t.Errorf("子图像像素 = %v, 期望 %v", sub.Bytes(), want)
+	}
+
+	// 非 RGBA 图像转换后写入相同的像素
+	gray := image.NewGray(image.Rect(0, 0, 2, 1))
+	gray.Pix[1] = 200
+	var buf bytes.Buffer
+	WriteRGBA(&buf, gray)
+	if want := []byte{0, 0, 0, 255, 200, 200, 200, 255}; !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("灰度图像素 = %v, 期望 %v", buf.Bytes(), want)
+	}
+}
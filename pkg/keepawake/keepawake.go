@@ -0,0 +1,16 @@
+// Package keepawake 在执行任务期间阻止系统休眠和锁屏：
+// macOS 使用 IOKit 电源断言，Windows 使用 SetThreadExecutionState，Linux 使用 systemd-inhibit 和 org.freedesktop.ScreenSaver
+package keepawake
+
+import "sync"
+
+// Acquire 阻止系统空闲休眠、屏幕关闭和锁屏，reason 显示在系统的电源 / 抑制列表中
+// 返回的 release 解除阻止，可重复调用；进程退出（包括崩溃）时系统自动解除
+func Acquire(reason string) (release func(), err error) {
+	releasePlatform, err := acquirePlatform(reason)
+	if err != nil {
+		return nil, err
+	}
+	var once sync.Once
+	return func() { once.Do(releasePlatform) }, nil
+}
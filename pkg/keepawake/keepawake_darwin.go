@@ -0,0 +1,36 @@
+//go:build darwin
+
+package keepawake
+
+/*
+#cgo LDFLAGS: -framework IOKit -framework CoreFoundation
+#include <stdlib.h>
+#include <IOKit/pwr_mgt/IOPMLib.h>
+
+// 创建阻止显示器空闲休眠的电源断言（同时阻止系统空闲休眠和屏保锁屏），失败时返回 0
+static IOPMAssertionID createAssertion(const char* reason) {
+    CFStringRef name = CFStringCreateWithCString(kCFAllocatorDefault, reason, kCFStringEncodingUTF8);
+    IOPMAssertionID id = 0;
+    IOReturn ret = IOPMAssertionCreateWithName(kIOPMAssertionTypePreventUserIdleDisplaySleep, kIOPMAssertionLevelOn, name, &id);
+    CFRelease(name);
+    return ret == kIOReturnSuccess ? id : 0;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// acquirePlatform macOS：创建 IOKit 电源断言，进程退出时系统自动释放
+func acquirePlatform(reason string) (func(), error) {
+	cReason := C.CString(reason)
+	defer C.free(unsafe.Pointer(cReason))
+
+	id := C.createAssertion(cReason)
+	if id == 0 {
+		return nil, errors.New("创建电源断言失败")
+	}
+	return func() { C.IOPMAssertionRelease(id) }, nil
+}
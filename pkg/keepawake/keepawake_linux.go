@@ -0,0 +1,63 @@
+//go:build linux
+
+package keepawake
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"syscall"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// acquirePlatform Linux：systemd-inhibit 阻止空闲和休眠，org.freedesktop.ScreenSaver 阻止屏保锁屏，至少一种成功即可
+func acquirePlatform(reason string) (func(), error) {
+	inhibit, inhibitErr := systemdInhibit(reason)
+	screenSaver, screenSaverErr := screenSaverInhibit(reason)
+	if inhibitErr != nil && screenSaverErr != nil {
+		return nil, errors.Join(inhibitErr, screenSaverErr)
+	}
+	return func() {
+		if inhibit != nil {
+			inhibit()
+		}
+		if screenSaver != nil {
+			screenSaver()
+		}
+	}, nil
+}
+
+// systemdInhibit 运行 systemd-inhibit 并保持到释放；Worker 异常退出时子进程随之结束（Pdeathsig）
+func systemdInhibit(reason string) (func(), error) {
+	path, err := exec.LookPath("systemd-inhibit")
+	if err != nil {
+		return nil, fmt.Errorf("未找到 systemd-inhibit: %w", err)
+	}
+	cmd := exec.Command(path, "--what=idle:sleep", "--who=Zoey Worker", "--why="+reason, "--mode=block", "sleep", "infinity")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Pdeathsig: syscall.SIGTERM}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动 systemd-inhibit 失败: %w", err)
+	}
+	go cmd.Wait() // 回收子进程
+	return func() { cmd.Process.Kill() }, nil
+}
+
+// screenSaverInhibit 通过会话总线调用 org.freedesktop.ScreenSaver.Inhibit（GNOME、KDE 等桌面的屏保和锁屏）
+// 抑制与总线连接绑定，Worker 退出时自动解除
+func screenSaverInhibit(reason string) (func(), error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("连接会话总线失败: %w", err)
+	}
+	obj := conn.Object("org.freedesktop.ScreenSaver", "/org/freedesktop/ScreenSaver")
+	var cookie uint32
+	if err := obj.Call("org.freedesktop.ScreenSaver.Inhibit", 0, "Zoey Worker", reason).Store(&cookie); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("调用 ScreenSaver.Inhibit 失败: %w", err)
+	}
+	return func() {
+		obj.Call("org.freedesktop.ScreenSaver.UnInhibit", 0, cookie)
+		conn.Close()
+	}, nil
+}
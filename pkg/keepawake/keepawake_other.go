@@ -0,0 +1,10 @@
+//go:build !darwin && !windows && !linux
+
+package keepawake
+
+import "errors"
+
+// acquirePlatform 其他平台不支持
+func acquirePlatform(reason string) (func(), error) {
+	return nil, errors.New("当前平台不支持阻止休眠")
+}
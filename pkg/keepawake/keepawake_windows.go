@@ -0,0 +1,41 @@
+//go:build windows
+
+package keepawake
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+)
+
+var procSetThreadExecutionState = syscall.NewLazyDLL("kernel32.dll").NewProc("SetThreadExecutionState")
+
+const (
+	esContinuous      = 0x80000000
+	esSystemRequired  = 0x00000001
+	esDisplayRequired = 0x00000002
+)
+
+// acquirePlatform Windows：SetThreadExecutionState 的状态属于调用线程，线程退出时失效，
+// 因此在锁定到操作系统线程的 goroutine 中设置并保持，直到释放
+func acquirePlatform(reason string) (func(), error) {
+	done := make(chan struct{})
+	result := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		ret, _, err := procSetThreadExecutionState.Call(esContinuous | esSystemRequired | esDisplayRequired)
+		if ret == 0 {
+			result <- fmt.Errorf("SetThreadExecutionState 失败: %v", err)
+			return
+		}
+		result <- nil
+		<-done
+		procSetThreadExecutionState.Call(esContinuous)
+	}()
+	if err := <-result; err != nil {
+		return nil, err
+	}
+	return func() { close(done) }, nil
+}
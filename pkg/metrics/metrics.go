@@ -0,0 +1,288 @@
+// Package metrics 提供 Prometheus 文本格式的运行指标（计数器、仪表和直方图），不依赖 Prometheus 客户端库
+//
+// 指标在各包的包级变量中注册（名称和含义见注册处的注释），由 Serve 启动的 HTTP 服务在 /metrics 输出
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// collector 已注册的指标
+type collector interface {
+	name() string
+	// write 输出 HELP、TYPE 和样本行
+	write(w *bufio.Writer)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]collector{}
+)
+
+// register 注册指标，名称重复时 panic（指标在包初始化时注册，重复即代码错误）
+func register(c collector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[c.name()]; ok {
+		panic(fmt.Sprintf("metrics: 指标 %s 重复注册", c.name()))
+	}
+	registry[c.name()] = c
+}
+
+// WriteText 按名称顺序以 Prometheus 文本格式输出所有指标
+func WriteText(w io.Writer) error {
+	registryMu.Lock()
+	collectors := make([]collector, 0, len(registry))
+	for _, c := range registry {
+		collectors = append(collectors, c)
+	}
+	registryMu.Unlock()
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].name() < collectors[j].name() })
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// desc 指标名称、说明和类型
+type desc struct {
+	metricName string
+	help       string
+	kind       string
+}
+
+func (d desc) name() string { return d.metricName }
+
+func (d desc) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.metricName, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.metricName, d.kind)
+}
+
+// formatFloat 按 Prometheus 文本格式输出数值
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// formatLabels 生成 {k="v",...}，没有标签时返回空字符串
+func formatLabels(pairs ...string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, pairs[i], escape.Replace(pairs[i+1]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// ==================== 计数器 ====================
+
+// Counter 只增不减的计数器
+type Counter struct {
+	desc
+	value atomic.Uint64
+}
+
+// NewCounter 创建并注册计数器，名称按惯例以 _total 结尾
+func NewCounter(name, help string) *Counter {
+	c := &Counter{desc: desc{name, help, "counter"}}
+	register(c)
+	return c
+}
+
+// Inc 加 1
+func (c *Counter) Inc() { c.value.Add(1) }
+
+// Add 增加 n
+func (c *Counter) Add(n uint64) { c.value.Add(n) }
+
+// Value 当前值
+func (c *Counter) Value() uint64 { return c.value.Load() }
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.writeHeader(w)
+	fmt.Fprintf(w, "%s %d\n", c.metricName, c.value.Load())
+}
+
+// ==================== 仪表 ====================
+
+// Gauge 可增可减的当前值（如队列长度）
+type Gauge struct {
+	desc
+	bits atomic.Uint64
+}
+
+// NewGauge 创建并注册仪表
+func NewGauge(name, help string) *Gauge {
+	g := &Gauge{desc: desc{name, help, "gauge"}}
+	register(g)
+	return g
+}
+
+// Set 设置当前值
+func (g *Gauge) Set(v float64) { g.bits.Store(math.Float64bits(v)) }
+
+// Value 当前值
+func (g *Gauge) Value() float64 { return math.Float64frombits(g.bits.Load()) }
+
+func (g *Gauge) write(w *bufio.Writer) {
+	g.writeHeader(w)
+	fmt.Fprintf(w, "%s %s\n", g.metricName, formatFloat(g.Value()))
+}
+
+// ==================== 直方图 ====================
+
+// 常用的直方图桶
+var (
+	// DurationBuckets 耗时（秒）：10ms - 60s
+	DurationBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+	// SizeBuckets 字节数：16KB - 8MB
+	SizeBuckets = []float64{16 << 10, 64 << 10, 128 << 10, 256 << 10, 512 << 10, 1 << 20, 2 << 20, 4 << 20, 8 << 20}
+)
+
+// histogramData 一组直方图样本（每个桶独立计数，输出时累加）
+type histogramData struct {
+	mu     sync.Mutex
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (h *histogramData) observe(buckets []float64, v float64) {
+	i := sort.SearchFloat64s(buckets, v)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.counts == nil {
+		h.counts = make([]uint64, len(buckets))
+	}
+	if i < len(buckets) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+}
+
+func (h *histogramData) write(w *bufio.Writer, name string, buckets []float64, labels ...string) {
+	h.mu.Lock()
+	counts := slices.Clone(h.counts)
+	sum, count := h.sum, h.count
+	h.mu.Unlock()
+
+	var cumulative uint64
+	for i, le := range buckets {
+		if counts != nil {
+			cumulative += counts[i]
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, formatLabels(append(slices.Clip(labels), "le", formatFloat(le))...), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket%s %d\n", name, formatLabels(append(slices.Clip(labels), "le", "+Inf")...), count)
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, formatLabels(labels...), formatFloat(sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, formatLabels(labels...), count)
+}
+
+// Histogram 按桶统计观测值的分布（如耗时、大小）
+type Histogram struct {
+	desc
+	buckets []float64
+	data    histogramData
+}
+
+// NewHistogram 创建并注册直方图，buckets 为递增的桶上界（不含 +Inf）
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	h := &Histogram{desc: desc{name, help, "histogram"}, buckets: sortedBuckets(buckets)}
+	register(h)
+	return h
+}
+
+// Observe 记录一个观测值
+func (h *Histogram) Observe(v float64) { h.data.observe(h.buckets, v) }
+
+// Count 已记录的观测值个数
+func (h *Histogram) Count() uint64 {
+	h.data.mu.Lock()
+	defer h.data.mu.Unlock()
+	return h.data.count
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.writeHeader(w)
+	h.data.write(w, h.metricName, h.buckets)
+}
+
+// HistogramVec 按一个标签分组的直方图（标签值应为有限集合，如动作类型）
+type HistogramVec struct {
+	desc
+	label   string
+	buckets []float64
+
+	mu     sync.Mutex
+	values map[string]*histogramData
+}
+
+// NewHistogramVec 创建并注册按 label 分组的直方图
+func NewHistogramVec(name, help, label string, buckets []float64) *HistogramVec {
+	h := &HistogramVec{desc: desc{name, help, "histogram"}, label: label, buckets: sortedBuckets(buckets), values: map[string]*histogramData{}}
+	register(h)
+	return h
+}
+
+// Observe 记录标签值为 value 的观测值
+func (h *HistogramVec) Observe(value string, v float64) {
+	h.mu.Lock()
+	data := h.values[value]
+	if data == nil {
+		data = &histogramData{}
+		h.values[value] = data
+	}
+	h.mu.Unlock()
+	data.observe(h.buckets, v)
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.writeHeader(w)
+	h.mu.Lock()
+	values := make([]string, 0, len(h.values))
+	for value := range h.values {
+		values = append(values, value)
+	}
+	h.mu.Unlock()
+	sort.Strings(values)
+
+	for _, value := range values {
+		h.mu.Lock()
+		data := h.values[value]
+		h.mu.Unlock()
+		data.write(w, h.metricName, h.buckets, h.label, value)
+	}
+}
+
+func sortedBuckets(buckets []float64) []float64 {
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	return slices.Compact(buckets)
+}
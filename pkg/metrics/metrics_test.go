@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	counter := NewCounter("test_requests_total", "Requests.")
+	gauge := NewGauge("test_queue_depth", "Queue depth.")
+	hist := NewHistogram("test_latency_seconds", "Latency.", []float64{1, 0.1})
+	vec := NewHistogramVec("test_step_seconds", "Step time.", "action", []float64{1})
+
+	counter.Inc()
+	counter.Add(2)
+	gauge.Set(7)
+	hist.Observe(0.05)
+	hist.Observe(0.5)
+	hist.Observe(5)
+	vec.Observe("click", 0.5)
+	vec.Observe(`a"b`, 2)
+
+	var b strings.Builder
+	if err := WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"# HELP test_requests_total Requests.\n# TYPE test_requests_total counter\ntest_requests_total 3\n",
+		"# TYPE test_queue_depth gauge\ntest_queue_depth 7\n",
+		"# TYPE test_latency_seconds histogram\n" +
+			`test_latency_seconds_bucket{le="0.1"} 1` + "\n" +
+			`test_latency_seconds_bucket{le="1"} 2` + "\n" +
+			`test_latency_seconds_bucket{le="+Inf"} 3` + "\n" +
+			"test_latency_seconds_sum 5.55\ntest_latency_seconds_count 3\n",
+		`test_step_seconds_bucket{action="a\"b",le="1"} 0`,
+		`test_step_seconds_bucket{action="click",le="1"} 1`,
+		`test_step_seconds_count{action="click"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("输出缺少 %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "test_latency_seconds") > strings.Index(out, "test_requests_total") {
+		t.Error("指标应按名称排序")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("重复注册应 panic")
+		}
+	}()
+	NewCounter("test_requests_total", "again")
+}
+
+func TestListenAddr(t *testing.T) {
+	for addr, want := range map[string]string{
+		"9100":         "127.0.0.1:9100",
+		":9100":        "127.0.0.1:9100",
+		"0.0.0.0:9100": "0.0.0.0:9100",
+		"[::1]:9100":   "[::1]:9100",
+	} {
+		if got, err := ListenAddr(addr); err != nil || got != want {
+			t.Errorf("ListenAddr(%q) = %q, %v, 期望 %q", addr, got, err, want)
+		}
+	}
+	for _, addr := range []string{"localhost", "host:", ":99999", "a:b:c"} {
+		if _, err := ListenAddr(addr); err == nil {
+			t.Errorf("ListenAddr(%q) 应返回错误", addr)
+		}
+	}
+}
+
+func TestServe(t *testing.T) {
+	NewCounter("test_served_total", "Served.").Inc()
+	server, err := Serve(":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	if !strings.HasPrefix(server.Addr(), "127.0.0.1:") {
+		t.Errorf("只有端口时应监听 127.0.0.1, 实际 %s", server.Addr())
+	}
+
+	resp, err := http.Get("http://" + server.Addr() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "test_served_total 1\n") || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Errorf("应输出指标, 实际 %s %q", resp.Header.Get("Content-Type"), body)
+	}
+
+	if _, err := Serve(server.Addr()); err == nil {
+		t.Error("端口被占用时应返回错误")
+	}
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/internal/logger"
+)
+
+// Handler 以 Prometheus 文本格式输出所有指标
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteText(w)
+	})
+}
+
+// ListenAddr 规范化监听地址：只有端口（"9100" 或 ":9100"）时只监听 127.0.0.1，
+// 需要被其他机器抓取时显式指定主机（如 "0.0.0.0:9100"）
+func ListenAddr(addr string) (string, error) {
+	addr = strings.TrimSpace(addr)
+	if !strings.Contains(addr, ":") {
+		addr = ":" + addr
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("无效的监听地址 %q: %w", addr, err)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return "", fmt.Errorf("无效的监听地址 %q: 端口应为 0-65535", addr)
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// Server 指标 HTTP 服务
+type Server struct {
+	srv      *http.Server
+	listener net.Listener
+}
+
+// Serve 在 addr（见 ListenAddr）上启动指标服务，提供 /metrics；监听失败（如端口被占用）时返回错误
+func Serve(addr string) (*Server, error) {
+	addr, err := ListenAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("监听 %s 失败: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	s := &Server{
+		srv:      &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second},
+		listener: listener,
+	}
+	go func() {
+		if err := s.srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Warn("指标服务已停止: %v", err)
+		}
+	}()
+	return s, nil
+}
+
+// Addr 实际监听的地址（端口为 0 时为系统分配的端口）
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close 停止指标服务
+func (s *Server) Close() error {
+	return s.srv.Close()
+}
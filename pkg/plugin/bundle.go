@@ -0,0 +1,248 @@
+package plugin
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// InstallFromArchive 从离线安装包（.zip / .tgz / .tar.gz）安装 OCR 插件，可通过 Cancel 中止
+//
+// 安装包布局与插件目录一致，根目录的 manifest.json 列出包内每个文件的大小和 SHA256：
+//
+//	manifest.json                {"lib/onnxruntime.dll": {"size": 123, "sha256": "..."}, ...}
+//	lib/onnxruntime.dll          当前平台的 ONNX Runtime（可同时包含多个平台的库）
+//	paddle_weights/det.onnx      检测模型
+//	paddle_weights/rec.onnx      识别模型
+//	paddle_weights/dict.txt      字典
+//	paddle_weights/cls.onnx      方向分类模型（可选）
+//
+// 清单中的文件逐个校验后写入插件目录，校验信息合并到插件目录的 manifest.json，GetStatus 据此检查完整性
+func (p *OCRPlugin) InstallFromArchive(archivePath string) error {
+	return p.run(context.Background(), nil, func(ctx context.Context) error {
+		return p.installFromArchive(ctx, archivePath)
+	})
+}
+
+// installFromArchive 校验安装包清单并解压清单中的文件
+func (p *OCRPlugin) installFromArchive(ctx context.Context, archivePath string) error {
+	p.setPhase("verifying " + filepath.Base(archivePath))
+	manifest, err := readBundleManifest(archivePath)
+	if err != nil {
+		return err
+	}
+
+	var totalSize int64
+	for rel, sum := range manifest {
+		if rel == manifestFile || !filepath.IsLocal(filepath.FromSlash(rel)) {
+			return fmt.Errorf("安装包清单包含非法路径: %s", rel)
+		}
+		if sum.Size < 0 || sum.SHA256 == "" {
+			return fmt.Errorf("安装包清单缺少 %s 的大小或 SHA256", rel)
+		}
+		totalSize += sum.Size
+	}
+	for _, rel := range p.requiredFiles() {
+		if _, ok := manifest[rel]; !ok {
+			return fmt.Errorf("安装包缺少当前平台必需的文件: %s", rel)
+		}
+	}
+
+	if err := precheck(p.baseDir, totalSize); err != nil {
+		return err
+	}
+
+	p.setPhase("extracting " + filepath.Base(archivePath))
+	extracted := make(map[string]fileChecksum)
+	var extractedSize int64
+	err = walkArchive(archivePath, func(name string, r io.Reader) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel := archiveEntryName(name)
+		want, ok := manifest[rel]
+		if _, done := extracted[rel]; !ok || done {
+			return nil
+		}
+		if err := extractVerified(r, filepath.Join(p.baseDir, filepath.FromSlash(rel)), rel, want); err != nil {
+			return err
+		}
+		extracted[rel] = want
+		extractedSize += want.Size
+		if totalSize > 0 {
+			p.setProgress(float64(extractedSize) / float64(totalSize) * 100)
+		}
+		return nil
+	})
+
+	// 已校验的文件即使后续失败也记入安装清单，重新安装时仍会覆盖
+	if len(extracted) > 0 {
+		if saveErr := p.saveChecksums(extracted); err == nil {
+			err = saveErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for rel := range manifest {
+		if _, ok := extracted[rel]; !ok {
+			missing = append(missing, rel)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("安装包缺少清单中列出的文件: %s", strings.Join(missing, ", "))
+	}
+
+	p.setProgress(100)
+	return nil
+}
+
+// requiredFiles 当前平台必需的文件（相对插件目录的路径）
+func (p *OCRPlugin) requiredFiles() []string {
+	paths := []string{
+		p.getOnnxRuntimePath(),
+		filepath.Join(p.baseDir, "paddle_weights", "det.onnx"),
+		filepath.Join(p.baseDir, "paddle_weights", "rec.onnx"),
+		filepath.Join(p.baseDir, "paddle_weights", "dict.txt"),
+	}
+	rels := make([]string, 0, len(paths))
+	for _, path := range paths {
+		rel, _ := filepath.Rel(p.baseDir, path)
+		rels = append(rels, filepath.ToSlash(rel))
+	}
+	return rels
+}
+
+// errStopWalk 提前结束 walkArchive 遍历
+var errStopWalk = errors.New("stop walk")
+
+// readBundleManifest 读取安装包根目录的 manifest.json
+func readBundleManifest(archivePath string) (map[string]fileChecksum, error) {
+	var manifest map[string]fileChecksum
+	err := walkArchive(archivePath, func(name string, r io.Reader) error {
+		if archiveEntryName(name) != manifestFile {
+			return nil
+		}
+		data, err := io.ReadAll(io.LimitReader(r, 1<<20))
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return fmt.Errorf("安装包清单格式错误: %w", err)
+		}
+		return errStopWalk
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return nil, err
+	}
+	if len(manifest) == 0 {
+		return nil, fmt.Errorf("安装包缺少 %s 或清单为空", manifestFile)
+	}
+	return manifest, nil
+}
+
+// walkArchive 按顺序遍历压缩包中的普通文件（根据扩展名识别 zip / tgz）
+func walkArchive(archivePath string, fn func(name string, r io.Reader) error) error {
+	lower := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return fmt.Errorf("打开安装包失败: %w", err)
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			if !f.Mode().IsRegular() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			err = fn(f.Name, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case strings.HasSuffix(lower, ".tgz"), strings.HasSuffix(lower, ".tar.gz"):
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return fmt.Errorf("打开安装包失败: %w", err)
+		}
+		defer f.Close()
+		gzr, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("打开安装包失败: %w", err)
+		}
+		defer gzr.Close()
+		tr := tar.NewReader(gzr)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+			if err := fn(header.Name, tr); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("不支持的安装包格式: %s（支持 .zip、.tgz、.tar.gz）", filepath.Base(archivePath))
+	}
+}
+
+// archiveEntryName 规范化压缩包内的路径（去掉开头的 ./）
+func archiveEntryName(name string) string {
+	return strings.TrimPrefix(path.Clean(strings.ReplaceAll(name, "\\", "/")), "./")
+}
+
+// extractVerified 解压到 destPath.tmp 并计算 SHA256，大小和校验和与清单一致后替换 destPath
+func extractVerified(r io.Reader, destPath, rel string, want fileChecksum) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	tmpPath := destPath + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(out, h), io.LimitReader(r, want.Size+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n != want.Size {
+		err = fmt.Errorf("安装包中 %s 大小不符: 期望 %d，实际 %d", rel, want.Size, n)
+	}
+	if actual := hex.EncodeToString(h.Sum(nil)); err == nil && !strings.EqualFold(actual, want.SHA256) {
+		err = fmt.Errorf("安装包中 %s 校验失败（文件可能损坏或被篡改）: 期望 SHA256 %s，实际 %s", rel, want.SHA256, actual)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, destPath)
+}
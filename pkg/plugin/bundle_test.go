@@ -0,0 +1,165 @@
+package plugin
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// bundleFiles 构造当前平台完整的安装包内容（不含 manifest.json）
+func bundleFiles(p *OCRPlugin) map[string][]byte {
+	files := map[string][]byte{"paddle_weights/cls.onnx": []byte("cls")}
+	for _, rel := range p.requiredFiles() {
+		files[rel] = append([]byte(rel+":"), testContent...)
+	}
+	return files
+}
+
+// bundleManifest 根据文件内容生成安装包清单
+func bundleManifest(files map[string][]byte) []byte {
+	manifest := make(map[string]fileChecksum)
+	for rel, data := range files {
+		manifest[rel] = fileChecksum{Size: int64(len(data)), SHA256: testSHA256(data)}
+	}
+	data, _ := json.Marshal(manifest)
+	return data
+}
+
+// writeBundle 将文件写入 zip 或 tgz 安装包（manifest 为 nil 时不写清单），返回安装包路径
+func writeBundle(t *testing.T, name string, files map[string][]byte, manifest []byte) string {
+	t.Helper()
+	entries := make(map[string][]byte)
+	for rel, data := range files {
+		entries[rel] = data
+	}
+	if manifest != nil {
+		entries[manifestFile] = manifest
+	}
+
+	var buf bytes.Buffer
+	if strings.HasSuffix(name, ".zip") {
+		zw := zip.NewWriter(&buf)
+		for rel, data := range entries {
+			w, err := zw.Create(rel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write(data)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		gzw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gzw)
+		for rel, data := range entries {
+			if err := tw.WriteHeader(&tar.Header{Name: "./" + rel, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+				t.Fatal(err)
+			}
+			tw.Write(data)
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := gzw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInstallFromArchive(t *testing.T) {
+	for _, name := range []string{"ocr-bundle.zip", "ocr-bundle.tgz"} {
+		t.Run(name, func(t *testing.T) {
+			p := &OCRPlugin{baseDir: t.TempDir()}
+			files := bundleFiles(p)
+			if err := p.InstallFromArchive(writeBundle(t, name, files, bundleManifest(files))); err != nil {
+				t.Fatalf("离线安装失败: %v", err)
+			}
+
+			status := p.GetStatus()
+			if !status.Installed || status.ClsModelPath == "" {
+				t.Errorf("离线安装后状态 = %+v", status)
+			}
+			if status.Phase != "done" || status.Progress != 100 {
+				t.Errorf("安装阶段 = %q, 进度 = %v", status.Phase, status.Progress)
+			}
+			if got, _ := os.ReadFile(status.DetModelPath); !bytes.Equal(got, files["paddle_weights/det.onnx"]) {
+				t.Error("解压的模型内容不一致")
+			}
+			if manifest := p.loadManifest(); len(manifest) != len(files) {
+				t.Errorf("插件目录的清单应记录 %d 个文件, 实际 %d", len(files), len(manifest))
+			}
+		})
+	}
+}
+
+func TestInstallFromArchive_ChecksumMismatch(t *testing.T) {
+	p := &OCRPlugin{baseDir: t.TempDir()}
+	files := bundleFiles(p)
+	manifest := bundleManifest(files)
+	files["paddle_weights/rec.onnx"] = bytes.ToUpper(files["paddle_weights/rec.onnx"])
+
+	err := p.InstallFromArchive(writeBundle(t, "ocr-bundle.zip", files, manifest))
+	if err == nil || !strings.Contains(err.Error(), "rec.onnx 校验失败") {
+		t.Fatalf("内容与清单不一致时应返回校验错误, 实际 %v", err)
+	}
+	if p.IsInstalled() {
+		t.Error("校验失败时不应视为已安装")
+	}
+	if status := p.GetStatus(); status.Phase != "failed" {
+		t.Errorf("安装阶段 = %q, 期望 failed", status.Phase)
+	}
+	if _, err := os.Stat(p.GetStatus().RecModelPath + ".tmp"); !os.IsNotExist(err) {
+		t.Error("校验失败时应删除临时文件")
+	}
+}
+
+func TestInstallFromArchive_InvalidBundle(t *testing.T) {
+	p := &OCRPlugin{baseDir: t.TempDir()}
+	files := bundleFiles(p)
+	libRel := p.requiredFiles()[0]
+
+	withoutLib := make(map[string][]byte)
+	for rel, data := range files {
+		if rel != libRel {
+			withoutLib[rel] = data
+		}
+	}
+	listed := bundleManifest(files)
+	delete(files, "paddle_weights/cls.onnx")
+
+	tests := []struct {
+		name    string
+		archive string
+		wantErr string
+	}{
+		{"缺少清单", writeBundle(t, "a.zip", files, nil), "缺少 manifest.json"},
+		{"缺少当前平台的运行库", writeBundle(t, "b.tgz", withoutLib, bundleManifest(withoutLib)), libRel},
+		{"清单中的文件不在包内", writeBundle(t, "c.zip", files, listed), "paddle_weights/cls.onnx"},
+		{"非法路径", writeBundle(t, "d.zip", files, []byte(`{"../evil": {"size": 1, "sha256": "00"}}`)), "非法路径"},
+		{"不支持的格式", filepath.Join(t.TempDir(), "bundle.rar"), "不支持的安装包格式"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.InstallFromArchive(tt.archive)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("错误 = %v, 期望包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+	if _, err := os.Stat(filepath.Join(p.baseDir, "..", "evil")); !os.IsNotExist(err) {
+		t.Error("清单中的非法路径不应被写入")
+	}
+}
@@ -0,0 +1,146 @@
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// manifestFile 安装清单文件名（位于插件目录，离线安装包根目录使用相同格式），
+// 记录每个文件（相对插件目录的路径）的大小和 SHA256
+const manifestFile = "manifest.json"
+
+// fileChecksum 已安装文件的校验信息
+type fileChecksum struct {
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// fileStamp 文件的大小、修改时间和期望的 SHA256，均未变化时沿用上次的校验结果
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+	sha256  string
+	ok      bool
+}
+
+// 校验结果缓存：GetStatus 调用频繁，只在文件变化后重新计算哈希
+var (
+	verifiedMu sync.Mutex
+	verified   = make(map[string]fileStamp)
+)
+
+// fileSHA256 计算文件的 SHA256（小写十六进制）
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyChecksum 校验文件的 SHA256，不一致时返回包含期望值和实际值的错误
+func verifyChecksum(path, expected string) error {
+	actual, err := fileSHA256(path)
+	if err != nil {
+		return fmt.Errorf("计算校验和失败: %w", err)
+	}
+	if !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("%s 校验失败（文件可能损坏或被篡改）: 期望 SHA256 %s，实际 %s", filepath.Base(path), expected, actual)
+	}
+	return nil
+}
+
+// manifestPath 安装清单路径
+func (p *OCRPlugin) manifestPath() string {
+	return filepath.Join(p.baseDir, manifestFile)
+}
+
+// loadManifest 读取安装清单（键为相对插件目录的路径），不存在或损坏时返回空清单
+func (p *OCRPlugin) loadManifest() map[string]fileChecksum {
+	manifest := make(map[string]fileChecksum)
+	data, err := os.ReadFile(p.manifestPath())
+	if err != nil {
+		return manifest
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return make(map[string]fileChecksum)
+	}
+	return manifest
+}
+
+// recordChecksum 计算已安装文件的校验信息并写入清单
+func (p *OCRPlugin) recordChecksum(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(p.baseDir, path)
+	if err != nil {
+		return err
+	}
+	return p.saveChecksums(map[string]fileChecksum{filepath.ToSlash(rel): {Size: info.Size(), SHA256: sum}})
+}
+
+// saveChecksums 将校验信息合并写入安装清单
+func (p *OCRPlugin) saveChecksums(entries map[string]fileChecksum) error {
+	manifest := p.loadManifest()
+	for rel, sum := range entries {
+		manifest[rel] = sum
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(p.manifestPath(), data, 0644); err != nil {
+		return fmt.Errorf("写入安装清单失败: %w", err)
+	}
+	return nil
+}
+
+// verifyInstalled 检查文件存在且与安装清单一致（大小和 SHA256）
+// 清单中没有记录的文件（旧版本安装）只检查是否存在
+func (p *OCRPlugin) verifyInstalled(path string, manifest map[string]fileChecksum) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(p.baseDir, path)
+	if err != nil {
+		return false
+	}
+	want, ok := manifest[filepath.ToSlash(rel)]
+	if !ok {
+		return true
+	}
+	if info.Size() != want.Size {
+		return false
+	}
+
+	verifiedMu.Lock()
+	defer verifiedMu.Unlock()
+	if s, ok := verified[path]; ok && s.size == info.Size() && s.modTime.Equal(info.ModTime()) && s.sha256 == want.SHA256 {
+		return s.ok
+	}
+	sum, err := fileSHA256(path)
+	valid := err == nil && strings.EqualFold(sum, want.SHA256)
+	verified[path] = fileStamp{size: info.Size(), modTime: info.ModTime(), sha256: want.SHA256, ok: valid}
+	return valid
+}
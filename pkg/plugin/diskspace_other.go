@@ -0,0 +1,14 @@
+//go:build !windows
+
+package plugin
+
+import "syscall"
+
+// diskFree 返回 path 所在文件系统当前用户可用的字节数
+func diskFree(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
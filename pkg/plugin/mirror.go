@@ -0,0 +1,110 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// MirrorEnv 插件下载镜像地址的环境变量（多个地址以逗号分隔），排在配置文件的镜像之前
+const MirrorEnv = "ZOEY_PLUGIN_MIRROR"
+
+// SetMirrors 设置下载镜像地址，按顺序尝试，均失败后回退到官方地址
+// 镜像目录下按官方文件名存放文件，如 <mirror>/ch_PP-OCRv4_det_infer.onnx、<mirror>/onnxruntime-win-x64-1.23.0.zip
+func (p *OCRPlugin) SetMirrors(mirrors []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mirrors = append([]string(nil), mirrors...)
+}
+
+// SetProxy 设置下载代理（如 http://127.0.0.1:7890），为空时使用 HTTP_PROXY / HTTPS_PROXY 环境变量
+func (p *OCRPlugin) SetProxy(proxyURL string) error {
+	client, err := newHTTPClient(proxyURL)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.client = client
+	return nil
+}
+
+// newHTTPClient 创建下载用的 HTTP 客户端：指定 proxyURL 时使用该代理，否则读取标准代理环境变量
+func newHTTPClient(proxyURL string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if proxyURL = strings.TrimSpace(proxyURL); proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("无效的代理地址: %q", proxyURL)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// httpClient 返回下载用的 HTTP 客户端（未调用 SetProxy 时按环境变量使用代理）
+func (p *OCRPlugin) httpClient() *http.Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client == nil {
+		p.client, _ = newHTTPClient("")
+	}
+	return p.client
+}
+
+// downloadURLs 返回文件的候选下载地址：环境变量镜像、配置的镜像，最后是官方地址（去重）
+func (p *OCRPlugin) downloadURLs(officialURL string) []string {
+	p.mu.RLock()
+	mirrors := append(strings.Split(os.Getenv(MirrorEnv), ","), p.mirrors...)
+	p.mu.RUnlock()
+
+	name := path.Base(officialURL)
+	if u, err := url.Parse(officialURL); err == nil {
+		name = path.Base(u.Path)
+	}
+
+	var urls []string
+	seen := make(map[string]bool)
+	for _, base := range append(mirrors, "") {
+		candidate := officialURL
+		if base != "" {
+			if base = strings.TrimRight(strings.TrimSpace(base), "/"); base == "" {
+				continue
+			}
+			candidate = base + "/" + name
+		}
+		if !seen[candidate] {
+			seen[candidate] = true
+			urls = append(urls, candidate)
+		}
+	}
+	return urls
+}
+
+// fetch 依次从候选地址下载文件，全部失败时返回包含每个地址及其错误的汇总
+// 中断的下载在下一个地址上续传，校验失败的地址同样跳过
+func (p *OCRPlugin) fetch(ctx context.Context, f downloadFile, onProgress func(int64)) error {
+	urls := p.downloadURLs(f.url)
+	tried := make([]string, 0, len(urls))
+	for _, u := range urls {
+		var err error
+		if f.isArchive {
+			err = p.downloadAndExtract(ctx, u, f.destPath, f.archiveLib, f.sha256, onProgress)
+		} else {
+			err = p.downloadFile(ctx, u, f.destPath, f.sha256, onProgress)
+		}
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		tried = append(tried, fmt.Sprintf("%s: %v", u, err))
+	}
+	return fmt.Errorf("已尝试 %d 个地址:\n  %s", len(tried), strings.Join(tried, "\n  "))
+}
@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownloadURLs(t *testing.T) {
+	t.Setenv(MirrorEnv, "https://env.example.com/ocr/, ")
+	p := &OCRPlugin{}
+	p.SetMirrors([]string{"https://mirror.example.com", "https://env.example.com/ocr"})
+
+	got := p.downloadURLs(RapidOCRBase + "/ch_PP-OCRv4_det_infer.onnx")
+	want := []string{
+		"https://env.example.com/ocr/ch_PP-OCRv4_det_infer.onnx",
+		"https://mirror.example.com/ch_PP-OCRv4_det_infer.onnx",
+		RapidOCRBase + "/ch_PP-OCRv4_det_infer.onnx",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("候选地址 = %v, 期望 %v", got, want)
+	}
+}
+
+func TestFetch_FallsBackToNextMirror(t *testing.T) {
+	broken := httptest.NewServer(http.NotFoundHandler())
+	defer broken.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dict.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(testContent)
+	}))
+	defer mirror.Close()
+
+	t.Setenv(MirrorEnv, "")
+	p := &OCRPlugin{baseDir: t.TempDir()}
+	p.SetMirrors([]string{broken.URL, mirror.URL})
+	f := downloadFile{name: "dict.txt", url: broken.URL + "/official/dict.txt", destPath: filepath.Join(p.baseDir, "dict.txt"), sha256: testSHA256(testContent)}
+
+	if err := p.fetch(context.Background(), f, nil); err != nil {
+		t.Fatalf("第二个镜像可用时应下载成功: %v", err)
+	}
+	if got, _ := os.ReadFile(f.destPath); !bytes.Equal(got, testContent) {
+		t.Error("下载内容不一致")
+	}
+}
+
+func TestFetch_ReportsTriedMirrors(t *testing.T) {
+	broken := httptest.NewServer(http.NotFoundHandler())
+	defer broken.Close()
+
+	t.Setenv(MirrorEnv, broken.URL+"/a")
+	p := &OCRPlugin{baseDir: t.TempDir()}
+	p.SetMirrors([]string{broken.URL + "/b"})
+	f := downloadFile{name: "rec.onnx", url: broken.URL + "/official/rec.onnx", destPath: filepath.Join(p.baseDir, "rec.onnx")}
+
+	err := p.fetch(context.Background(), f, nil)
+	if err == nil {
+		t.Fatal("所有地址都失败时应返回错误")
+	}
+	for _, u := range []string{broken.URL + "/a/rec.onnx", broken.URL + "/b/rec.onnx", f.url} {
+		if !strings.Contains(err.Error(), u) {
+			t.Errorf("错误信息应包含已尝试的地址 %s: %v", u, err)
+		}
+	}
+}
+
+func TestSetProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write(testContent)
+	}))
+	defer proxy.Close()
+
+	p := &OCRPlugin{baseDir: t.TempDir()}
+	if err := p.SetProxy(proxy.URL); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(p.baseDir, "det.onnx")
+	if err := p.downloadFile(context.Background(), "http://plugins.zoey.invalid/det.onnx", dest, "", nil); err != nil {
+		t.Fatalf("经代理下载失败: %v", err)
+	}
+	if proxied != "http://plugins.zoey.invalid/det.onnx" {
+		t.Errorf("代理收到的请求 = %q", proxied)
+	}
+
+	if err := p.SetProxy("not a url"); err == nil {
+		t.Error("无效代理地址应返回错误")
+	}
+}
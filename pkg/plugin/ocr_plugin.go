@@ -5,6 +5,8 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -22,6 +24,16 @@ type OCRPlugin struct {
 	downloading bool
 	progress    float64
 	onProgress  func(float64)
+	// installProgress 本次安装的进度回调（Install 的 progress 参数）
+	installProgress func(float64)
+	// phase 当前安装阶段（如 "downloading det.onnx"）
+	phase string
+	// cancel 取消进行中的安装（未安装时为 nil）
+	cancel context.CancelFunc
+	// mirrors 下载镜像地址（按顺序尝试）
+	mirrors []string
+	// client 下载用的 HTTP 客户端（nil 时按代理环境变量创建）
+	client *http.Client
 }
 
 // OCRPluginStatus OCR 插件状态
@@ -29,16 +41,20 @@ type OCRPluginStatus struct {
 	Installed       bool    `json:"installed"`
 	Downloading     bool    `json:"downloading"`
 	Progress        float64 `json:"progress"` // 0-100
+	Phase           string  `json:"phase"`    // 安装阶段: "downloading det.onnx"、"extracting onnxruntime.dll"、"done"、"canceled"、"failed"、"precheck_failed"（磁盘空间不足或目录不可写）
 	OnnxRuntimePath string  `json:"onnxRuntimePath"`
 	DetModelPath    string  `json:"detModelPath"`
 	RecModelPath    string  `json:"recModelPath"`
 	DictPath        string  `json:"dictPath"`
+	ClsModelPath    string  `json:"clsModelPath"` // 方向分类模型（可选，未下载时为空）
 }
 
 // 模型和库下载地址 - 使用 PP-OCRv5 最新模型 + ONNX Runtime 1.23
 const (
 	// PP-OCRv4 Mobile 模型来自 SWHL/RapidOCR (轻量高速版，仅 16MB)
 	RapidOCRBase = "https://huggingface.co/SWHL/RapidOCR/resolve/main/PP-OCRv4"
+	// OCRModelVersion 安装的 OCR 模型版本（随能力信息上报服务端）
+	OCRModelVersion = "PP-OCRv4"
 	// PP-OCRv3 字典（PP-OCRv4 共用）
 	DictBase = "https://huggingface.co/monkt/paddleocr-onnx/resolve/main/languages/chinese"
 	// ONNX Runtime 1.23.0 官方下载
@@ -52,6 +68,7 @@ type downloadFile struct {
 	url        string
 	destPath   string
 	size       int64  // 预估大小（字节）
+	sha256     string // 下载内容（压缩包为压缩包本身）的 SHA256，为空时不校验
 	isArchive  bool   // 是否为压缩包
 	archiveLib string // 压缩包内的库文件路径
 }
@@ -79,11 +96,13 @@ func (p *OCRPlugin) GetStatus() OCRPluginStatus {
 	p.mu.RLock()
 	downloading := p.downloading
 	progress := p.progress
+	phase := p.phase
 	p.mu.RUnlock()
 
 	status := OCRPluginStatus{
 		Downloading: downloading,
 		Progress:    progress,
+		Phase:       phase,
 	}
 
 	// 检查文件是否存在
@@ -97,11 +116,17 @@ func (p *OCRPlugin) GetStatus() OCRPluginStatus {
 	status.RecModelPath = recPath
 	status.DictPath = dictPath
 
-	// 检查所有文件是否存在
-	status.Installed = fileExists(onnxPath) &&
-		fileExists(detPath) &&
-		fileExists(recPath) &&
-		fileExists(dictPath)
+	// 文件需存在且与安装清单一致，截断或损坏的文件视为未安装
+	manifest := p.loadManifest()
+	if clsPath := filepath.Join(p.baseDir, "paddle_weights", "cls.onnx"); p.verifyInstalled(clsPath, manifest) {
+		status.ClsModelPath = clsPath
+	}
+
+	// 检查所有必需文件（方向分类模型可选，旧版本安装的插件没有该文件）
+	status.Installed = p.verifyInstalled(onnxPath, manifest) &&
+		p.verifyInstalled(detPath, manifest) &&
+		p.verifyInstalled(recPath, manifest) &&
+		p.verifyInstalled(dictPath, manifest)
 
 	return status
 }
@@ -111,8 +136,52 @@ func (p *OCRPlugin) IsInstalled() bool {
 	return p.GetStatus().Installed
 }
 
-// Install 下载并安装 OCR 插件
-func (p *OCRPlugin) Install() error {
+// ErrInstallCanceled 安装被 Cancel 或 ctx 取消
+var ErrInstallCanceled = errors.New("OCR 插件安装已取消")
+
+// Name 插件名
+func (p *OCRPlugin) Name() string {
+	return OCRPluginName
+}
+
+// Status 插件通用状态
+func (p *OCRPlugin) Status() Status {
+	status := p.GetStatus()
+	return Status{
+		Name:       OCRPluginName,
+		Installed:  status.Installed,
+		Installing: status.Downloading,
+		Progress:   status.Progress,
+		Phase:      status.Phase,
+	}
+}
+
+// Paths 已安装的文件路径（方向分类模型未下载时不包含 cls）
+func (p *OCRPlugin) Paths() map[string]string {
+	status := p.GetStatus()
+	paths := map[string]string{
+		"onnxruntime": status.OnnxRuntimePath,
+		"det":         status.DetModelPath,
+		"rec":         status.RecModelPath,
+		"dict":        status.DictPath,
+	}
+	if status.ClsModelPath != "" {
+		paths["cls"] = status.ClsModelPath
+	}
+	return paths
+}
+
+// Install 下载并安装 OCR 插件，progress 接收 0-100 的进度（可为 nil）
+// ctx 取消或调用 Cancel 时中止并返回 ErrInstallCanceled
+func (p *OCRPlugin) Install(ctx context.Context, progress func(float64)) error {
+	return p.run(ctx, progress, p.install)
+}
+
+// run 执行安装任务：同一时间只允许一个安装，可通过 Cancel 中止，结束后更新 phase
+func (p *OCRPlugin) run(parent context.Context, progress func(float64), install func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
 	p.mu.Lock()
 	if p.downloading {
 		p.mu.Unlock()
@@ -120,14 +189,47 @@ func (p *OCRPlugin) Install() error {
 	}
 	p.downloading = true
 	p.progress = 0
+	p.phase = ""
+	p.cancel = cancel
+	p.installProgress = progress
 	p.mu.Unlock()
 
-	defer func() {
-		p.mu.Lock()
-		p.downloading = false
-		p.mu.Unlock()
-	}()
+	err := install(ctx)
+	phase := "done"
+	var precheckErr *PrecheckError
+	switch {
+	case ctx.Err() != nil:
+		// 用户主动取消：清理临时文件，下次从头下载
+		p.removeTempFiles()
+		err, phase = ErrInstallCanceled, "canceled"
+	case errors.As(err, &precheckErr):
+		phase = "precheck_failed"
+	case err != nil:
+		phase = "failed"
+	}
+
+	p.mu.Lock()
+	p.downloading = false
+	p.cancel = nil
+	p.installProgress = nil
+	p.phase = phase
+	p.mu.Unlock()
+	return err
+}
 
+// Cancel 取消进行中的安装（中止 HTTP 请求并删除临时文件），没有进行中的安装时返回 false
+func (p *OCRPlugin) Cancel() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel == nil {
+		return false
+	}
+	p.cancel()
+	return true
+}
+
+// install 下载并安装全部文件
+func (p *OCRPlugin) install(ctx context.Context) error {
 	// 创建目录
 	if err := os.MkdirAll(filepath.Join(p.baseDir, "lib"), 0755); err != nil {
 		return fmt.Errorf("创建目录失败: %w", err)
@@ -139,51 +241,92 @@ func (p *OCRPlugin) Install() error {
 	// 需要下载的文件
 	files := p.getDownloadFiles()
 
-	// 计算总大小
-	var totalSize int64
+	// 计算总大小及仍需下载的大小（压缩包下载后还要解压，按两倍计算）
+	var totalSize, requiredSize int64
 	for _, f := range files {
 		totalSize += f.size
+		if !p.isVerified(f.destPath) {
+			requiredSize += f.size
+			if f.isArchive {
+				requiredSize += f.size
+			}
+		}
 	}
 
-	// 下载所有文件
+	// 开始下载前检查目录权限和磁盘空间，避免下载到一半才失败
+	if err := precheck(p.baseDir, requiredSize); err != nil {
+		return err
+	}
+
+	// 下载所有文件：已安装且校验通过的文件跳过，中断的下载从 .tmp 续传
 	var downloadedSize int64
 	for _, f := range files {
-		var err error
-		if f.isArchive {
-			// 下载并解压压缩包
-			err = p.downloadAndExtract(f.url, f.destPath, f.archiveLib, func(downloaded int64) {
-				p.mu.Lock()
-				p.progress = float64(downloadedSize+downloaded) / float64(totalSize) * 100
-				if p.onProgress != nil {
-					p.onProgress(p.progress)
-				}
-				p.mu.Unlock()
-			})
-		} else {
-			// 直接下载文件
-			err = p.downloadFile(f.url, f.destPath, func(downloaded int64) {
-				p.mu.Lock()
-				p.progress = float64(downloadedSize+downloaded) / float64(totalSize) * 100
-				if p.onProgress != nil {
-					p.onProgress(p.progress)
-				}
-				p.mu.Unlock()
-			})
+		if p.isVerified(f.destPath) {
+			downloadedSize += f.size
+			p.setProgress(float64(downloadedSize) / float64(totalSize) * 100)
+			continue
 		}
-		if err != nil {
-			return fmt.Errorf("下载 %s 失败: %w", f.name, err)
+
+		onProgress := func(downloaded int64) {
+			p.setProgress(float64(downloadedSize+min(downloaded, f.size)) / float64(totalSize) * 100)
+		}
+		p.setPhase("downloading " + f.name)
+		if err := p.fetch(ctx, f, onProgress); err != nil {
+			return fmt.Errorf("下载 %s 失败，%w", f.name, err)
+		}
+		if err := p.recordChecksum(f.destPath); err != nil {
+			return fmt.Errorf("记录 %s 校验和失败: %w", f.name, err)
 		}
 		downloadedSize += f.size
 	}
 
+	p.setProgress(100)
+	return nil
+}
+
+// setPhase 更新安装阶段
+func (p *OCRPlugin) setPhase(phase string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.phase = phase
+}
+
+// removeTempFiles 删除所有下载中的临时文件
+func (p *OCRPlugin) removeTempFiles() {
+	for _, f := range p.getDownloadFiles() {
+		for _, suffix := range []string{".tmp", ".archive", ".archive.tmp"} {
+			os.Remove(f.destPath + suffix)
+		}
+	}
+}
+
+// setProgress 更新下载进度（0-100）并通知回调
+func (p *OCRPlugin) setProgress(progress float64) {
 	p.mu.Lock()
-	p.progress = 100
+	defer p.mu.Unlock()
+	p.progress = progress
 	if p.onProgress != nil {
-		p.onProgress(100)
+		p.onProgress(progress)
 	}
-	p.mu.Unlock()
+	if p.installProgress != nil {
+		p.installProgress(progress)
+	}
+}
 
-	return nil
+// isVerified 文件已安装、在安装清单中有记录且校验通过（旧版本安装的文件没有记录，重新安装时会重新下载）
+func (p *OCRPlugin) isVerified(path string) bool {
+	manifest := p.loadManifest()
+	return p.hasChecksum(path, manifest) && p.verifyInstalled(path, manifest)
+}
+
+// hasChecksum 安装清单中是否记录了该文件
+func (p *OCRPlugin) hasChecksum(path string, manifest map[string]fileChecksum) bool {
+	rel, err := filepath.Rel(p.baseDir, path)
+	if err != nil {
+		return false
+	}
+	_, ok := manifest[filepath.ToSlash(rel)]
+	return ok
 }
 
 // Uninstall 卸载 OCR 插件
@@ -200,6 +343,11 @@ func (p *OCRPlugin) GetConfig() (onnxPath, detPath, recPath, dictPath string, er
 	return status.OnnxRuntimePath, status.DetModelPath, status.RecModelPath, status.DictPath, nil
 }
 
+// GetClsModelPath 获取方向分类模型路径，未下载时返回空字符串
+func (p *OCRPlugin) GetClsModelPath() string {
+	return p.GetStatus().ClsModelPath
+}
+
 // getOnnxRuntimePath 根据平台获取 ONNX Runtime 库路径
 func (p *OCRPlugin) getOnnxRuntimePath() string {
 	switch runtime.GOOS {
@@ -219,7 +367,7 @@ func (p *OCRPlugin) getOnnxRuntimePath() string {
 }
 
 // getDownloadFiles 获取需要下载的文件列表
-// PP-OCRv4 Mobile 模型：检测模型 4.75MB，中文识别模型 10.9MB，字典 74KB（共约 16MB）
+// PP-OCRv4 Mobile 模型：检测模型 4.75MB，中文识别模型 10.9MB，字典 74KB，方向分类模型 0.6MB（共约 17MB）
 func (p *OCRPlugin) getDownloadFiles() []downloadFile {
 	files := []downloadFile{
 		// PP-OCRv4 Mobile 检测模型
@@ -243,6 +391,13 @@ func (p *OCRPlugin) getDownloadFiles() []downloadFile {
 			destPath: filepath.Join(p.baseDir, "paddle_weights", "dict.txt"),
 			size:     30 * 1024, // ~30KB
 		},
+		// 方向分类模型（校正旋转 90° 或倒置的文字行）
+		{
+			name:     "cls.onnx",
+			url:      RapidOCRBase + "/ch_ppocr_mobile_v2.0_cls_infer.onnx",
+			destPath: filepath.Join(p.baseDir, "paddle_weights", "cls.onnx"),
+			size:     600 * 1024, // ~585KB
+		},
 	}
 
 	// 根据平台添加 ONNX Runtime 1.23.0 (从 GitHub 官方下载)
@@ -310,36 +465,64 @@ func (p *OCRPlugin) getDownloadFiles() []downloadFile {
 	return files
 }
 
-// downloadFile 下载单个文件
-func (p *OCRPlugin) downloadFile(url, destPath string, onProgress func(int64)) error {
-	// 创建请求
-	resp, err := http.Get(url)
+// downloadFile 下载单个文件到 destPath.tmp，完成并校验后重命名为 destPath
+// 已有 .tmp 时通过 HTTP Range 从断点续传（服务端不支持时从头下载），onProgress 的字节数包含已下载部分；
+// sha256Hex 非空时校验下载内容，不一致时删除临时文件并返回错误
+func (p *OCRPlugin) downloadFile(ctx context.Context, url, destPath, sha256Hex string, onProgress func(int64)) error {
+	tmpPath := destPath + ".tmp"
+	var offset int64
+	if info, err := os.Stat(tmpPath); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := p.httpClient().Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+			os.Remove(tmpPath)
+			return fmt.Errorf("续传范围不匹配: %s", resp.Header.Get("Content-Range"))
+		}
+		flags |= os.O_APPEND
+	case offset > 0 && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// 临时文件已下载完整，直接校验
+		return p.finishDownload(tmpPath, destPath, sha256Hex)
+	case resp.StatusCode == http.StatusOK:
+		// 首次下载或服务端不支持 Range：从头下载
+		flags |= os.O_TRUNC
+		offset = 0
+	default:
 		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
 	}
 
-	// 创建临时文件
-	tmpPath := destPath + ".tmp"
-	out, err := os.Create(tmpPath)
+	out, err := os.OpenFile(tmpPath, flags, 0644)
 	if err != nil {
 		return err
 	}
 	defer out.Close()
 
-	// 下载并追踪进度
-	var downloaded int64
+	// 下载并追踪进度；中断时保留临时文件，下次安装从断点继续
+	downloaded := offset
+	if onProgress != nil {
+		onProgress(downloaded)
+	}
 	buf := make([]byte, 32*1024) // 32KB buffer
 	for {
 		n, err := resp.Body.Read(buf)
 		if n > 0 {
-			_, writeErr := out.Write(buf[:n])
-			if writeErr != nil {
-				os.Remove(tmpPath)
+			if _, writeErr := out.Write(buf[:n]); writeErr != nil {
 				return writeErr
 			}
 			downloaded += int64(n)
@@ -351,71 +534,52 @@ func (p *OCRPlugin) downloadFile(url, destPath string, onProgress func(int64)) e
 			break
 		}
 		if err != nil {
-			os.Remove(tmpPath)
 			return err
 		}
 	}
 
-	// 重命名为最终文件
-	out.Close()
-	return os.Rename(tmpPath, destPath)
-}
-
-// downloadAndExtract 下载压缩包并解压特定文件
-func (p *OCRPlugin) downloadAndExtract(url, destPath, archiveLib string, onProgress func(int64)) error {
-	// 下载到临时文件
-	tmpArchive := destPath + ".archive.tmp"
-	defer os.Remove(tmpArchive)
-
-	resp, err := http.Get(url)
-	if err != nil {
+	if err := out.Close(); err != nil {
 		return err
 	}
-	defer resp.Body.Close()
+	return p.finishDownload(tmpPath, destPath, sha256Hex)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+// finishDownload 校验临时文件并重命名为最终文件，校验失败时删除临时文件（下次重新下载）
+func (p *OCRPlugin) finishDownload(tmpPath, destPath, sha256Hex string) error {
+	if sha256Hex != "" {
+		if err := verifyChecksum(tmpPath, sha256Hex); err != nil {
+			os.Remove(tmpPath)
+			return err
+		}
 	}
+	return os.Rename(tmpPath, destPath)
+}
 
-	// 下载压缩包
-	out, err := os.Create(tmpArchive)
-	if err != nil {
+// downloadAndExtract 下载压缩包（可续传，sha256Hex 为压缩包的校验和）并解压特定文件
+func (p *OCRPlugin) downloadAndExtract(ctx context.Context, url, destPath, archiveLib, sha256Hex string, onProgress func(int64)) error {
+	// 下载到 destPath.archive（中断时保留 destPath.archive.tmp 用于续传），解压后删除
+	archivePath := destPath + ".archive"
+	if err := p.downloadFile(ctx, url, archivePath, sha256Hex, onProgress); err != nil {
 		return err
 	}
+	defer os.Remove(archivePath)
+	p.setPhase("extracting " + filepath.Base(destPath))
 
-	var downloaded int64
-	buf := make([]byte, 32*1024)
-	for {
-		n, err := resp.Body.Read(buf)
-		if n > 0 {
-			_, writeErr := out.Write(buf[:n])
-			if writeErr != nil {
-				out.Close()
-				return writeErr
-			}
-			downloaded += int64(n)
-			if onProgress != nil {
-				onProgress(downloaded)
-			}
-		}
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			out.Close()
-			return err
-		}
-	}
-	out.Close()
-
-	// 根据文件类型解压
+	// 根据文件类型解压到临时文件，完整解压后再替换，避免中途失败留下不完整的库文件
+	extractPath := destPath + ".tmp"
+	var err error
 	if strings.HasSuffix(url, ".tgz") || strings.HasSuffix(url, ".tar.gz") {
-		return p.extractTgz(tmpArchive, destPath, archiveLib)
+		err = p.extractTgz(archivePath, extractPath, archiveLib)
 	} else if strings.HasSuffix(url, ".zip") {
-		return p.extractZip(tmpArchive, destPath, archiveLib)
+		err = p.extractZip(archivePath, extractPath, archiveLib)
+	} else {
+		err = fmt.Errorf("不支持的压缩格式: %s", url)
 	}
-
-	return fmt.Errorf("不支持的压缩格式: %s", url)
+	if err != nil {
+		os.Remove(extractPath)
+		return err
+	}
+	return os.Rename(extractPath, destPath)
 }
 
 // extractTgz 从 tgz 文件中提取特定文件
@@ -495,16 +659,22 @@ func fileExists(path string) bool {
 	return err == nil
 }
 
-// 全局单例
-var (
-	globalOCRPlugin *OCRPlugin
-	ocrPluginOnce   sync.Once
-)
+// OCRPluginName OCR 插件在注册表中的名称
+const OCRPluginName = "ocr"
 
-// GetOCRPlugin 获取全局 OCR 插件管理器
+// defaultOCRPlugin 内置的 OCR 插件（启动时注册）
+var defaultOCRPlugin = NewOCRPlugin()
+
+func init() {
+	Register(defaultOCRPlugin)
+}
+
+// GetOCRPlugin 获取全局 OCR 插件管理器（注册表中名为 "ocr" 的插件，被替换为其他实现时返回内置插件）
 func GetOCRPlugin() *OCRPlugin {
-	ocrPluginOnce.Do(func() {
-		globalOCRPlugin = NewOCRPlugin()
-	})
-	return globalOCRPlugin
+	if p, ok := Get(OCRPluginName); ok {
+		if ocrPlugin, ok := p.(*OCRPlugin); ok {
+			return ocrPlugin
+		}
+	}
+	return defaultOCRPlugin
 }
@@ -0,0 +1,240 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testContent 测试下载内容
+var testContent = bytes.Repeat([]byte("zoey-ocr-plugin-"), 4096)
+
+func testSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// serveContent 启动文件服务（supportRange 为 false 时忽略 Range 头），返回地址和每次请求的 Range 头
+func serveContent(t *testing.T, content []byte, supportRange bool) (string, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mu.Unlock()
+		if !supportRange {
+			w.Write(content)
+			return
+		}
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, &ranges
+}
+
+func TestDownloadFile_Resume(t *testing.T) {
+	url, ranges := serveContent(t, testContent, true)
+	p := &OCRPlugin{baseDir: t.TempDir()}
+	dest := filepath.Join(p.baseDir, "rec.onnx")
+
+	// 上次下载中断，留下前一半
+	half := int64(len(testContent) / 2)
+	if err := os.WriteFile(dest+".tmp", testContent[:half], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var progress []int64
+	err := p.downloadFile(context.Background(), url, dest, testSHA256(testContent), func(n int64) {
+		progress = append(progress, n)
+	})
+	if err != nil {
+		t.Fatalf("续传失败: %v", err)
+	}
+
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, testContent) {
+		t.Error("续传后的文件内容不一致")
+	}
+	if want := fmt.Sprintf("bytes=%d-", half); len(*ranges) != 1 || (*ranges)[0] != want {
+		t.Errorf("Range 请求头 = %v, 期望 %s", *ranges, want)
+	}
+	if progress[0] != half || progress[len(progress)-1] != int64(len(testContent)) {
+		t.Errorf("进度应从已下载的 %d 字节开始到 %d 结束, 实际 %d..%d", half, len(testContent), progress[0], progress[len(progress)-1])
+	}
+	if _, err := os.Stat(dest + ".tmp"); !os.IsNotExist(err) {
+		t.Error("完成后应删除临时文件")
+	}
+}
+
+func TestDownloadFile_RangeUnsupported(t *testing.T) {
+	url, _ := serveContent(t, testContent, false)
+	p := &OCRPlugin{baseDir: t.TempDir()}
+	dest := filepath.Join(p.baseDir, "det.onnx")
+	if err := os.WriteFile(dest+".tmp", []byte("stale"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.downloadFile(context.Background(), url, dest, testSHA256(testContent), nil); err != nil {
+		t.Fatalf("服务端不支持 Range 时应从头下载: %v", err)
+	}
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, testContent) {
+		t.Error("从头下载的文件内容不一致")
+	}
+}
+
+func TestDownloadFile_TmpAlreadyComplete(t *testing.T) {
+	url, _ := serveContent(t, testContent, true)
+	p := &OCRPlugin{baseDir: t.TempDir()}
+	dest := filepath.Join(p.baseDir, "cls.onnx")
+	if err := os.WriteFile(dest+".tmp", testContent, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 服务端对超出范围的请求返回 416，临时文件校验通过后直接使用
+	if err := p.downloadFile(context.Background(), url, dest, testSHA256(testContent), nil); err != nil {
+		t.Fatalf("临时文件已完整时应直接完成: %v", err)
+	}
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, testContent) {
+		t.Error("文件内容不一致")
+	}
+}
+
+func TestDownloadFile_ChecksumMismatch(t *testing.T) {
+	url, _ := serveContent(t, testContent, true)
+	p := &OCRPlugin{baseDir: t.TempDir()}
+	dest := filepath.Join(p.baseDir, "dict.txt")
+
+	err := p.downloadFile(context.Background(), url, dest, testSHA256([]byte("other")), nil)
+	if err == nil || !strings.Contains(err.Error(), "校验失败") {
+		t.Fatalf("校验和不一致应返回错误, 实际 %v", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("校验失败时不应生成目标文件")
+	}
+	if _, err := os.Stat(dest + ".tmp"); !os.IsNotExist(err) {
+		t.Error("校验失败时应删除临时文件，下次重新下载")
+	}
+}
+
+func TestGetStatus_VerifiesChecksums(t *testing.T) {
+	p := &OCRPlugin{baseDir: t.TempDir()}
+	status := p.GetStatus()
+	for _, path := range []string{status.OnnxRuntimePath, status.DetModelPath, status.RecModelPath, status.DictPath} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, testContent, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.recordChecksum(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !p.IsInstalled() {
+		t.Fatal("文件与清单一致时应为已安装")
+	}
+
+	// 运行库被截断
+	if err := os.WriteFile(status.OnnxRuntimePath, testContent[:100], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if p.IsInstalled() {
+		t.Error("截断的运行库应视为未安装")
+	}
+
+	// 大小不变但内容被修改
+	if err := os.WriteFile(status.OnnxRuntimePath, testContent, 0644); err != nil {
+		t.Fatal(err)
+	}
+	corrupted := bytes.Clone(testContent)
+	corrupted[0] ^= 0xff
+	if err := os.WriteFile(status.DetModelPath, corrupted, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if p.IsInstalled() {
+		t.Error("内容损坏的模型应视为未安装")
+	}
+}
+
+func TestGetStatus_LegacyInstallWithoutManifest(t *testing.T) {
+	p := &OCRPlugin{baseDir: t.TempDir()}
+	status := p.GetStatus()
+	for _, path := range []string{status.OnnxRuntimePath, status.DetModelPath, status.RecModelPath, status.DictPath} {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !p.IsInstalled() {
+		t.Error("没有安装清单的旧版本安装只检查文件是否存在")
+	}
+}
+
+func TestInstall_Cancel(t *testing.T) {
+	// 镜像先返回部分内容，然后一直阻塞直到请求被取消
+	started := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(len(testContent)))
+		w.Write(testContent[:1024])
+		w.(http.Flusher).Flush()
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	t.Setenv(MirrorEnv, srv.URL)
+	p := &OCRPlugin{baseDir: t.TempDir()}
+	if p.Cancel() {
+		t.Error("没有进行中的安装时 Cancel 应返回 false")
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- p.Install(context.Background(), nil) }()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("下载未开始")
+	}
+	if phase := p.GetStatus().Phase; !strings.HasPrefix(phase, "downloading ") {
+		t.Errorf("下载中的阶段 = %q", phase)
+	}
+	if !p.Cancel() {
+		t.Error("安装进行中 Cancel 应返回 true")
+	}
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrInstallCanceled) {
+			t.Errorf("取消后应返回 ErrInstallCanceled, 实际 %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("取消后安装未结束")
+	}
+
+	status := p.GetStatus()
+	if status.Downloading || status.Phase != "canceled" {
+		t.Errorf("取消后状态 = %+v", status)
+	}
+	for _, f := range p.getDownloadFiles() {
+		for _, suffix := range []string{".tmp", ".archive.tmp"} {
+			if _, err := os.Stat(f.destPath + suffix); !os.IsNotExist(err) {
+				t.Errorf("取消后应删除临时文件 %s", f.destPath+suffix)
+			}
+		}
+	}
+}
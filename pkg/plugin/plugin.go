@@ -0,0 +1,83 @@
+package plugin
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Plugin 可选组件（OCR、浏览器驱动等体积较大、按需安装的依赖）
+type Plugin interface {
+	// Name 插件名（注册表中唯一，如 "ocr"）
+	Name() string
+	// Status 安装状态
+	Status() Status
+	// Install 下载并安装，progress 接收 0-100 的进度（可为 nil），ctx 取消时中止
+	// 磁盘空间不足或目录不可写时应在下载前返回 *PrecheckError
+	Install(ctx context.Context, progress func(float64)) error
+	// Uninstall 卸载并删除已安装的文件
+	Uninstall() error
+	// Paths 插件文件路径（用途 -> 路径，如 "det" -> ".../det.onnx"）
+	Paths() map[string]string
+}
+
+// Status 插件通用状态
+type Status struct {
+	Name       string  `json:"name"`
+	Installed  bool    `json:"installed"`
+	Installing bool    `json:"installing"`
+	Progress   float64 `json:"progress"` // 0-100
+	Phase      string  `json:"phase"`    // 安装阶段，含义由插件定义（如 "downloading det.onnx"、"done"、"precheck_failed"）
+}
+
+// Info 插件状态及文件路径（供 GUI 和服务端枚举插件）
+type Info struct {
+	Status
+	Paths map[string]string `json:"paths"`
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Plugin)
+)
+
+// Register 注册插件，已存在的同名插件会被覆盖
+func Register(p Plugin) {
+	if p == nil || p.Name() == "" {
+		panic("plugin: Register 的插件和名称不能为空")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[p.Name()] = p
+}
+
+// Get 按名称获取插件
+func Get(name string) (Plugin, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	p, ok := registry[name]
+	return p, ok
+}
+
+// List 返回所有已注册的插件（按名称排序）
+func List() []Plugin {
+	registryMu.RLock()
+	plugins := make([]Plugin, 0, len(registry))
+	for _, p := range registry {
+		plugins = append(plugins, p)
+	}
+	registryMu.RUnlock()
+
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name() < plugins[j].Name() })
+	return plugins
+}
+
+// ListInfo 返回所有插件的状态及文件路径（按名称排序）
+func ListInfo() []Info {
+	plugins := List()
+	infos := make([]Info, 0, len(plugins))
+	for _, p := range plugins {
+		infos = append(infos, Info{Status: p.Status(), Paths: p.Paths()})
+	}
+	return infos
+}
@@ -0,0 +1,88 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+)
+
+// fakePlugin 测试用插件
+type fakePlugin struct {
+	name      string
+	installed bool
+}
+
+func (f *fakePlugin) Name() string { return f.name }
+
+func (f *fakePlugin) Status() Status { return Status{Name: f.name, Installed: f.installed} }
+
+func (f *fakePlugin) Install(ctx context.Context, progress func(float64)) error {
+	if progress != nil {
+		progress(100)
+	}
+	f.installed = true
+	return nil
+}
+
+func (f *fakePlugin) Uninstall() error {
+	f.installed = false
+	return nil
+}
+
+func (f *fakePlugin) Paths() map[string]string { return map[string]string{"bin": "/opt/" + f.name} }
+
+// withRegistry 测试期间使用独立的注册表
+func withRegistry(t *testing.T, plugins ...Plugin) {
+	t.Helper()
+	registryMu.Lock()
+	saved := registry
+	registry = make(map[string]Plugin)
+	registryMu.Unlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry = saved
+		registryMu.Unlock()
+	})
+	for _, p := range plugins {
+		Register(p)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	withRegistry(t, &fakePlugin{name: "tesseract"}, &fakePlugin{name: "browser"})
+
+	names := []string{}
+	for _, p := range List() {
+		names = append(names, p.Name())
+	}
+	if len(names) != 2 || names[0] != "browser" || names[1] != "tesseract" {
+		t.Errorf("List() = %v, 期望按名称排序", names)
+	}
+
+	p, ok := Get("browser")
+	if !ok {
+		t.Fatal("Get 应返回已注册的插件")
+	}
+	if err := p.Install(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	infos := ListInfo()
+	if !infos[0].Installed || infos[0].Paths["bin"] != "/opt/browser" {
+		t.Errorf("ListInfo()[0] = %+v", infos[0])
+	}
+	if _, ok := Get("missing"); ok {
+		t.Error("未注册的插件 Get 应返回 false")
+	}
+}
+
+func TestGetOCRPlugin(t *testing.T) {
+	p, ok := Get(OCRPluginName)
+	if !ok || p != Plugin(GetOCRPlugin()) {
+		t.Fatal("GetOCRPlugin 应返回注册表中的 OCR 插件")
+	}
+
+	// 注册表中的 ocr 被替换为其他实现时仍返回内置插件
+	withRegistry(t, &fakePlugin{name: OCRPluginName})
+	if GetOCRPlugin() != defaultOCRPlugin {
+		t.Error("OCR 插件被替换后 GetOCRPlugin 应返回内置插件")
+	}
+}
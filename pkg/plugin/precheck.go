@@ -0,0 +1,62 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+)
+
+// installSlack 磁盘空间检查的余量（安装清单、文件系统开销等）
+const installSlack = 20 * 1024 * 1024
+
+// diskFreeSpace 查询可用磁盘空间（测试时替换）
+var diskFreeSpace = diskFree
+
+// PrecheckError 安装前检查失败：插件目录不可写或磁盘空间不足（与网络错误区分）
+type PrecheckError struct {
+	Path string
+	// Required 需要的字节数（含余量），Available 可用字节数，仅空间不足时有值
+	Required  uint64
+	Available uint64
+	// Err 目录不可写时的底层错误
+	Err error
+}
+
+func (e *PrecheckError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("插件目录 %s 不可写，请检查目录权限或更换目录: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("插件目录 %s 所在磁盘空间不足: 需要约 %s，可用 %s，请清理磁盘后重试",
+		e.Path, formatSize(e.Required), formatSize(e.Available))
+}
+
+func (e *PrecheckError) Unwrap() error {
+	return e.Err
+}
+
+// precheck 下载前检查插件目录可写，且所在磁盘的可用空间不少于 required 加余量
+// 无法查询可用空间时（如部分网络文件系统）不阻止安装
+func precheck(dir string, required int64) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return &PrecheckError{Path: dir, Err: err}
+	}
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return &PrecheckError{Path: dir, Err: err}
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	available, err := diskFreeSpace(dir)
+	if err != nil {
+		return nil
+	}
+	if need := uint64(max(required, 0)) + installSlack; available < need {
+		return &PrecheckError{Path: dir, Required: need, Available: available}
+	}
+	return nil
+}
+
+// formatSize 格式化字节数（MB，保留一位小数）
+func formatSize(n uint64) string {
+	return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+}
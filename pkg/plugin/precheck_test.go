@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// stubDiskFree 测试期间将可用磁盘空间固定为 available
+func stubDiskFree(t *testing.T, available uint64) {
+	t.Helper()
+	saved := diskFreeSpace
+	diskFreeSpace = func(string) (uint64, error) { return available, nil }
+	t.Cleanup(func() { diskFreeSpace = saved })
+}
+
+func TestInstall_InsufficientDiskSpace(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	t.Setenv(MirrorEnv, srv.URL)
+	stubDiskFree(t, 10*1024*1024)
+
+	p := &OCRPlugin{baseDir: t.TempDir()}
+	err := p.Install(context.Background(), nil)
+
+	var precheckErr *PrecheckError
+	if !errors.As(err, &precheckErr) {
+		t.Fatalf("空间不足时应返回 PrecheckError, 实际 %v", err)
+	}
+	if precheckErr.Required <= precheckErr.Available || !strings.Contains(err.Error(), p.baseDir) {
+		t.Errorf("错误信息应包含目录和所需空间: %v", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("检查失败时不应开始下载, 实际发出 %d 个请求", n)
+	}
+	if phase := p.GetStatus().Phase; phase != "precheck_failed" {
+		t.Errorf("安装阶段 = %q, 期望 precheck_failed", phase)
+	}
+}
+
+func TestPrecheck(t *testing.T) {
+	stubDiskFree(t, 100*1024*1024)
+	dir := filepath.Join(t.TempDir(), "plugins", "ocr")
+
+	if err := precheck(dir, 50*1024*1024); err != nil {
+		t.Fatalf("空间充足时应通过: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("写入检查后不应留下文件: %v", entries)
+	}
+	if err := precheck(dir, 90*1024*1024); err == nil {
+		t.Error("所需空间加余量超过可用空间时应失败")
+	}
+
+	// 插件目录的上级是普通文件，无法创建目录
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := precheck(filepath.Join(file, "ocr"), 0)
+	var precheckErr *PrecheckError
+	if !errors.As(err, &precheckErr) || precheckErr.Err == nil || !strings.Contains(err.Error(), "不可写") {
+		t.Errorf("目录不可写时应返回 PrecheckError, 实际 %v", err)
+	}
+}
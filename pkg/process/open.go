@@ -0,0 +1,59 @@
+package process
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+// ValidateURL 校验要打开的 URL：必须是带协议的绝对 URL，allowAnyScheme 为 false 时只允许 http / https
+func ValidateURL(rawURL string, allowAnyScheme bool) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, auto.Errorf(auto.ErrParam, "无效的 URL %q: %v", rawURL, err)
+	}
+	if u.Scheme == "" {
+		return nil, auto.Errorf(auto.ErrParam, "URL 缺少协议（如 https://）: %q", rawURL)
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if (scheme == "http" || scheme == "https") && u.Host == "" {
+		return nil, auto.Errorf(auto.ErrParam, "URL 缺少主机名: %q", rawURL)
+	}
+	if !allowAnyScheme && scheme != "http" && scheme != "https" {
+		return nil, auto.Errorf(auto.ErrParam, "只允许 http / https 协议的 URL: %q", rawURL)
+	}
+	return u, nil
+}
+
+// OpenURL 打开 URL：browser 为空时使用系统默认程序（macOS open、Windows url.dll、Linux xdg-open），
+// 否则用指定浏览器打开（chrome、edge、firefox 等别名、程序名或可执行文件路径；macOS 上也可以是应用名）
+// 直接启动浏览器程序时返回其进程信息，通过系统程序转交时返回 nil
+func OpenURL(rawURL, browser string) (*ProcessInfo, error) {
+	if strings.TrimSpace(browser) == "" {
+		return nil, openDefaultPlatform(rawURL)
+	}
+	return openInBrowserPlatform(rawURL, strings.TrimSpace(browser))
+}
+
+// browserCandidates 返回浏览器对应的候选程序：别名（忽略大小写）展开为 aliases 中的名称，其他名称原样返回
+func browserCandidates(browser string, aliases map[string][]string) []string {
+	if names, ok := aliases[strings.ToLower(browser)]; ok {
+		return names
+	}
+	return []string{browser}
+}
+
+// startDetached 启动程序后立即返回，不等待其退出
+func startDetached(path string, args ...string) (*ProcessInfo, error) {
+	cmd := exec.Command(path, args...)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动 %s 失败: %w", path, err)
+	}
+	info := &ProcessInfo{PID: cmd.Process.Pid, Name: filepath.Base(path), Path: path}
+	go cmd.Wait() // 回收子进程
+	return info, nil
+}
@@ -0,0 +1,43 @@
+//go:build darwin
+
+package process
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+// browserAliases 常见浏览器在 macOS 上的应用名
+var browserAliases = map[string][]string{
+	"chrome":   {"Google Chrome"},
+	"chromium": {"Chromium"},
+	"edge":     {"Microsoft Edge"},
+	"firefox":  {"Firefox"},
+	"safari":   {"Safari"},
+	"brave":    {"Brave Browser"},
+}
+
+// openDefaultPlatform macOS：通过 open 用默认程序打开
+func openDefaultPlatform(rawURL string) error {
+	if out, err := exec.Command("open", rawURL).CombinedOutput(); err != nil {
+		return fmt.Errorf("打开 URL 失败: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// openInBrowserPlatform macOS：可执行文件路径直接启动，其他名称作为应用名通过 open -a 打开（不返回进程信息）
+func openInBrowserPlatform(rawURL, browser string) (*ProcessInfo, error) {
+	if info, err := os.Stat(browser); err == nil && !info.IsDir() {
+		return startDetached(browser, rawURL)
+	}
+	for _, app := range browserCandidates(browser, browserAliases) {
+		if err := exec.Command("open", "-a", app, rawURL).Run(); err == nil {
+			return nil, nil
+		}
+	}
+	return nil, auto.Errorf(auto.ErrNotFound, "未找到浏览器: %s", browser)
+}
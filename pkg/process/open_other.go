@@ -0,0 +1,39 @@
+//go:build !darwin && !windows
+
+package process
+
+import (
+	"os/exec"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+// browserAliases 常见浏览器在 Linux 上的程序名
+var browserAliases = map[string][]string{
+	"chrome":   {"google-chrome", "google-chrome-stable", "chromium", "chromium-browser"},
+	"chromium": {"chromium", "chromium-browser"},
+	"edge":     {"microsoft-edge", "microsoft-edge-stable"},
+	"firefox":  {"firefox"},
+	"brave":    {"brave-browser", "brave"},
+}
+
+// openDefaultPlatform Linux：通过 xdg-open 用默认程序打开
+// 部分桌面环境下 xdg-open 会等待浏览器退出，因此不等待其结束
+func openDefaultPlatform(rawURL string) error {
+	path, err := exec.LookPath("xdg-open")
+	if err != nil {
+		return auto.Errorf(auto.ErrSystem, "未找到 xdg-open，请安装 xdg-utils: %w", err)
+	}
+	_, err = startDetached(path, rawURL)
+	return err
+}
+
+// openInBrowserPlatform Linux：在 PATH 中查找浏览器程序并直接启动
+func openInBrowserPlatform(rawURL, browser string) (*ProcessInfo, error) {
+	for _, name := range browserCandidates(browser, browserAliases) {
+		if path, err := exec.LookPath(name); err == nil {
+			return startDetached(path, rawURL)
+		}
+	}
+	return nil, auto.Errorf(auto.ErrNotFound, "未找到浏览器: %s", browser)
+}
@@ -0,0 +1,48 @@
+package process
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		url            string
+		allowAnyScheme bool
+		ok             bool
+	}{
+		{"https://example.com/login?next=/a&b=1", false, true},
+		{"HTTP://example.com", false, true},
+		{" https://example.com ", false, true},
+		{"example.com", false, false},
+		{"https://", false, false},
+		{"file:///etc/passwd", false, false},
+		{"javascript:alert(1)", false, false},
+		{"file:///tmp/report.html", true, true},
+		{"mailto:someone@example.com", true, true},
+		{"://bad", true, false},
+	}
+
+	for _, tt := range tests {
+		_, err := ValidateURL(tt.url, tt.allowAnyScheme)
+		if tt.ok && err != nil {
+			t.Errorf("ValidateURL(%q, %v) 应通过: %v", tt.url, tt.allowAnyScheme, err)
+		}
+		if !tt.ok && !errors.Is(err, auto.ErrParam) {
+			t.Errorf("ValidateURL(%q, %v) 应返回 ErrParam: %v", tt.url, tt.allowAnyScheme, err)
+		}
+	}
+}
+
+func TestBrowserCandidates(t *testing.T) {
+	aliases := map[string][]string{"chrome": {"google-chrome", "chromium"}}
+	if got := browserCandidates("Chrome", aliases); !reflect.DeepEqual(got, []string{"google-chrome", "chromium"}) {
+		t.Errorf("别名应忽略大小写展开: %v", got)
+	}
+	if got := browserCandidates("/opt/browser/bin/browser", aliases); !reflect.DeepEqual(got, []string{"/opt/browser/bin/browser"}) {
+		t.Errorf("非别名应原样返回: %v", got)
+	}
+}
@@ -0,0 +1,73 @@
+//go:build windows
+
+package process
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+
+	"github.com/zoeyai/zoeyworker/pkg/auto"
+)
+
+// appPathsKey 已安装程序的启动路径（浏览器通常不在 PATH 中，但会注册在这里）
+const appPathsKey = `SOFTWARE\Microsoft\Windows\CurrentVersion\App Paths\`
+
+// browserAliases 常见浏览器在 Windows 上的程序名
+var browserAliases = map[string][]string{
+	"chrome":  {"chrome.exe"},
+	"edge":    {"msedge.exe"},
+	"firefox": {"firefox.exe"},
+	"brave":   {"brave.exe"},
+	"opera":   {"opera.exe"},
+}
+
+// openDefaultPlatform Windows：通过 url.dll 用默认程序打开
+// 不使用 cmd /c start，以免 URL 中的 & 等字符被命令行解释
+func openDefaultPlatform(rawURL string) error {
+	_, err := startDetached(filepath.Join(systemRoot(), "System32", "rundll32.exe"), "url.dll,FileProtocolHandler", rawURL)
+	return err
+}
+
+// openInBrowserPlatform Windows：按路径、PATH 和 App Paths 注册表查找浏览器程序并直接启动
+func openInBrowserPlatform(rawURL, browser string) (*ProcessInfo, error) {
+	for _, name := range browserCandidates(browser, browserAliases) {
+		if !strings.HasSuffix(strings.ToLower(name), ".exe") {
+			name += ".exe"
+		}
+		if path, err := exec.LookPath(name); err == nil {
+			return startDetached(path, rawURL)
+		}
+		if path := appPath(name); path != "" {
+			return startDetached(path, rawURL)
+		}
+	}
+	return nil, auto.Errorf(auto.ErrNotFound, "未找到浏览器: %s", browser)
+}
+
+// appPath 从 App Paths 注册表（当前用户优先）读取程序路径，未注册时返回空字符串
+func appPath(exe string) string {
+	for _, root := range []registry.Key{registry.CURRENT_USER, registry.LOCAL_MACHINE} {
+		k, err := registry.OpenKey(root, appPathsKey+filepath.Base(exe), registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		path, _, err := k.GetStringValue("")
+		k.Close()
+		if err == nil && path != "" {
+			return strings.Trim(path, `"`)
+		}
+	}
+	return ""
+}
+
+// systemRoot Windows 目录
+func systemRoot() string {
+	if root := os.Getenv("SystemRoot"); root != "" {
+		return root
+	}
+	return `C:\Windows`
+}
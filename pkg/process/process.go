@@ -2,8 +2,12 @@
 package process
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/go-vgo/robotgo"
 	"github.com/shirou/gopsutil/v4/process"
@@ -112,6 +116,107 @@ func KillProcess(pid int) error {
 	return robotgo.Kill(pid)
 }
 
+// StopProcess 优雅终止进程：先发送终止信号，等待 forceAfter 后仍未退出则强制结束
+// forceAfter <= 0 时直接强制结束；ctx 取消时不再等待，立即强制结束
+func StopProcess(ctx context.Context, pid int, forceAfter time.Duration) error {
+	return StopProcesses(ctx, []int{pid}, forceAfter)[pid]
+}
+
+// StopProcesses 优雅终止一组进程：先向所有进程发送终止信号，再共用一个 forceAfter 期限等待退出，
+// 期限到后仍未退出的进程强制结束（总耗时不随进程数增加）。forceAfter <= 0 时直接强制结束；
+// ctx 取消时不再等待，立即强制结束仍未退出的进程。返回终止失败的进程及错误，全部成功时为空
+func StopProcesses(ctx context.Context, pids []int, forceAfter time.Duration) map[int]error {
+	errs := make(map[int]error)
+	kill := func(pid int) {
+		if err := KillProcess(pid); err != nil {
+			errs[pid] = err
+		}
+	}
+
+	var pending []int
+	for _, pid := range pids {
+		if forceAfter <= 0 {
+			kill(pid)
+			continue
+		}
+		proc, err := process.NewProcess(int32(pid))
+		if err != nil {
+			errs[pid] = fmt.Errorf("进程不存在: PID=%d", pid)
+			continue
+		}
+		if err := proc.Terminate(); err != nil {
+			kill(pid)
+			continue
+		}
+		pending = append(pending, pid)
+	}
+
+	deadline := time.Now().Add(forceAfter)
+wait:
+	for {
+		pending = slices.DeleteFunc(pending, func(pid int) bool { return !IsProcessRunning(pid) })
+		if len(pending) == 0 || !time.Now().Before(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break wait
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	for _, pid := range pending {
+		kill(pid)
+	}
+	return errs
+}
+
+// NormalizeName 规范化进程名：转小写并去掉 ".exe" 后缀
+func NormalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.TrimSuffix(name, ".exe")
+}
+
+// MatchName 判断进程名是否匹配
+// strict 为 true 时要求名称完全相同；否则忽略大小写和 ".exe" 后缀，并支持部分匹配
+func MatchName(procName, name string, strict bool) bool {
+	if strict {
+		return procName == name
+	}
+	target := NormalizeName(name)
+	if target == "" {
+		return false
+	}
+	return strings.Contains(NormalizeName(procName), target)
+}
+
+// MinPartialMatchLen 非 strict 模式下做部分匹配所需的最短名称长度（字符数）
+const MinPartialMatchLen = 3
+
+// FindByName 按名称筛选进程：strict 为 true 时要求名称完全相同；
+// 否则优先返回规范化后（忽略大小写和 ".exe" 后缀）完全相同的进程，没有时才退回部分匹配，
+// 名称短于 MinPartialMatchLen 时不做部分匹配，避免 "a"、"er" 之类的名称匹配到大量无关进程
+func FindByName(processes []ProcessInfo, name string, strict bool) []ProcessInfo {
+	var exact, partial []ProcessInfo
+	target := NormalizeName(name)
+	for _, proc := range processes {
+		switch {
+		case strict:
+			if proc.Name == name {
+				exact = append(exact, proc)
+			}
+		case target == "":
+		case NormalizeName(proc.Name) == target:
+			exact = append(exact, proc)
+		case strings.Contains(NormalizeName(proc.Name), target):
+			partial = append(partial, proc)
+		}
+	}
+	if len(exact) > 0 || utf8.RuneCountInString(target) < MinPartialMatchLen {
+		return exact
+	}
+	return partial
+}
+
 // FindPIDsByName 按名称查找进程 PID
 func FindPIDsByName(name string) ([]int, error) {
 	pids, err := robotgo.FindIds(name)
@@ -0,0 +1,83 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestMatchName(t *testing.T) {
+	tests := []struct {
+		procName string
+		name     string
+		strict   bool
+		want     bool
+	}{
+		{"Google Chrome", "chrome", false, true},
+		{"chrome.exe", "chrome", false, true},
+		{"CHROME.EXE", "Chrome.exe", false, true},
+		{"Google Chrome Helper", "chrome", false, true},
+		{"firefox", "chrome", false, false},
+		{"chrome", "", false, false},
+		{"Google Chrome", "chrome", true, false},
+		{"chrome.exe", "chrome.exe", true, true},
+	}
+
+	for _, tt := range tests {
+		if got := MatchName(tt.procName, tt.name, tt.strict); got != tt.want {
+			t.Errorf("MatchName(%q, %q, %v) = %v, 期望 %v", tt.procName, tt.name, tt.strict, got, tt.want)
+		}
+	}
+}
+
+func TestFindByName(t *testing.T) {
+	processes := []ProcessInfo{{PID: 1, Name: "chrome.exe"}, {PID: 2, Name: "Google Chrome Helper"}, {PID: 3, Name: "Chrome"}, {PID: 4, Name: "explorer"}, {PID: 5, Name: "Finder"}}
+	pids := func(procs []ProcessInfo) []int {
+		var result []int
+		for _, p := range procs {
+			result = append(result, p.PID)
+		}
+		return result
+	}
+	tests := []struct {
+		name   string
+		strict bool
+		want   string
+	}{
+		{"chrome", false, "[1 3]"},  // 优先完全相同的名称
+		{"google", false, "[2]"},    // 没有时退回部分匹配
+		{"er", false, "[]"},         // 过短的名称不做部分匹配
+		{"chrome.exe", true, "[1]"}, // strict 要求完全一致
+		{"chrome", true, "[]"},
+		{"", false, "[]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(pids(FindByName(processes, tt.name, tt.strict))); got != tt.want {
+			t.Errorf("FindByName(%q, %v) = %s, 期望 %s", tt.name, tt.strict, got, tt.want)
+		}
+	}
+}
+
+func TestStopProcesses_ContextCancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("需要 sh 忽略终止信号")
+	}
+	// 子进程忽略 SIGTERM，只能在期限后强制结束
+	cmd := exec.Command("sh", "-c", `trap "" TERM; sleep 30`)
+	if err := cmd.Start(); err != nil {
+		t.Skip(err)
+	}
+	t.Cleanup(func() { cmd.Process.Kill(); cmd.Wait() })
+	time.Sleep(100 * time.Millisecond) // 等待 trap 生效
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	StopProcesses(ctx, []int{cmd.Process.Pid}, 30*time.Second)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ctx 取消后应立即强制结束，实际等待 %v", elapsed)
+	}
+}
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifySignals 返回收到 Ctrl+C 或 SIGTERM（launchd / systemd 停止服务）时取消的 ctx
+func notifySignals() (context.Context, func()) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
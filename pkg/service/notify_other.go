@@ -0,0 +1,10 @@
+//go:build !windows
+
+package service
+
+import "context"
+
+// Notify 返回收到 Ctrl+C 或 SIGTERM（launchd / systemd 停止服务）时取消的 ctx，清理完成后调用 done
+func Notify(name string) (context.Context, func()) {
+	return notifySignals()
+}
@@ -0,0 +1,136 @@
+// Package service 将 Worker 注册为系统服务（Windows 服务、macOS launchd），开机自动启动并在注销后继续运行；
+// Linux 生成 systemd 用户单元由用户自行安装
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultName 默认服务名
+const DefaultName = "zoeyworker"
+
+// ErrNotSupported 当前平台不支持该操作
+var ErrNotSupported = errors.New("当前平台不支持该操作")
+
+// Config 服务配置
+type Config struct {
+	Name        string   // 服务名（Windows 服务名、launchd Label 后缀、systemd 单元名）
+	DisplayName string   // 显示名称
+	Description string   // 描述
+	Executable  string   // Worker 可执行文件的绝对路径
+	Args        []string // 启动参数（包含 -service）
+	LogFile     string   // launchd / systemd 标准输出和标准错误写入的文件（Worker 日志另见 log_file 配置）
+	System      bool     // macOS：安装为 LaunchDaemon（开机即启动，但没有图形会话），否则为 LaunchAgent（用户登录后启动）
+	User        string   // Windows：运行服务的账户（默认 LocalSystem）
+	Password    string   // Windows：账户密码
+}
+
+// NewConfig 以当前可执行文件创建服务配置，args 附加在 -service 之后（如 -profile staging）
+func NewConfig(name string, args ...string) (*Config, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("获取可执行文件路径失败: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	if name == "" {
+		name = DefaultName
+	}
+	return &Config{
+		Name:        name,
+		DisplayName: "Zoey Worker",
+		Description: "Zoey Worker UI 自动化执行节点",
+		Executable:  exe,
+		Args:        append([]string{"-service"}, args...),
+	}, nil
+}
+
+// Label launchd 服务标识
+func (c *Config) Label() string {
+	return "com.zoeyai." + c.Name
+}
+
+// LaunchdPlist 生成 launchd 配置：加载后立即启动，异常退出后自动重启
+// LaunchAgent 限定在图形会话（Aqua）中运行，以便截图和操作鼠标键盘
+func LaunchdPlist(cfg *Config) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	plistString(&b, "Label", cfg.Label())
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range append([]string{cfg.Executable}, cfg.Args...) {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", xmlEscape(arg))
+	}
+	b.WriteString("\t</array>\n")
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	b.WriteString("\t<key>ThrottleInterval</key>\n\t<integer>10</integer>\n")
+	if !cfg.System {
+		plistString(&b, "LimitLoadToSessionType", "Aqua")
+		plistString(&b, "ProcessType", "Interactive")
+	}
+	if cfg.LogFile != "" {
+		plistString(&b, "StandardOutPath", cfg.LogFile)
+		plistString(&b, "StandardErrorPath", cfg.LogFile)
+	}
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+// plistString 写入一个字符串类型的键值对
+func plistString(b *strings.Builder, key, value string) {
+	fmt.Fprintf(b, "\t<key>%s</key>\n\t<string>%s</string>\n", key, xmlEscape(value))
+}
+
+// xmlEscape 转义 XML 特殊字符
+func xmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;").Replace(s)
+}
+
+// SystemdUnit 生成 systemd 用户单元：随图形会话启动（需要 DISPLAY 才能截图），异常退出后自动重启
+func SystemdUnit(cfg *Config) string {
+	args := make([]string, 0, len(cfg.Args)+1)
+	for _, arg := range append([]string{cfg.Executable}, cfg.Args...) {
+		args = append(args, systemdQuote(arg))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s systemd 用户单元，保存为 ~/.config/systemd/user/%s.service 后执行：\n", cfg.DisplayName, cfg.Name)
+	b.WriteString("#   systemctl --user daemon-reload\n")
+	fmt.Fprintf(&b, "#   systemctl --user enable --now %s\n", cfg.Name)
+	b.WriteString("#   loginctl enable-linger $USER    # 注销后继续运行\n")
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s\n", cfg.Description)
+	b.WriteString("After=graphical-session.target network-online.target\n")
+	b.WriteString("Wants=network-online.target\n\n")
+	b.WriteString("[Service]\n")
+	b.WriteString("Type=simple\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(args, " "))
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=5\n")
+	b.WriteString("Environment=DISPLAY=:0\n")
+	if cfg.LogFile != "" {
+		fmt.Fprintf(&b, "StandardOutput=append:%s\n", cfg.LogFile)
+		fmt.Fprintf(&b, "StandardError=append:%s\n", cfg.LogFile)
+	}
+	b.WriteString("\n[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// systemdQuote 为 ExecStart 参数加引号（包含空白、引号或反斜杠时），% 转义为 %%
+func systemdQuote(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
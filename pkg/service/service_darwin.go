@@ -0,0 +1,96 @@
+//go:build darwin
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// plistPath launchd 配置文件路径：LaunchDaemon 位于 /Library/LaunchDaemons，LaunchAgent 位于 ~/Library/LaunchAgents
+func plistPath(cfg *Config) (string, error) {
+	if cfg.System {
+		return filepath.Join("/Library/LaunchDaemons", cfg.Label()+".plist"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户目录失败: %w", err)
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", cfg.Label()+".plist"), nil
+}
+
+// domain launchctl 域：LaunchDaemon 为 system，LaunchAgent 为当前用户的图形会话
+func domain(cfg *Config) string {
+	if cfg.System {
+		return "system"
+	}
+	return fmt.Sprintf("gui/%d", os.Getuid())
+}
+
+// Install 写入 launchd 配置文件，不立即启动（下次登录或开机时自动启动）
+func Install(cfg *Config) error {
+	path, err := plistPath(cfg)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("服务 %s 已存在: %s", cfg.Label(), path)
+	}
+	if cfg.LogFile != "" {
+		if err := os.MkdirAll(filepath.Dir(cfg.LogFile), 0755); err != nil {
+			return fmt.Errorf("创建日志目录失败: %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(LaunchdPlist(cfg)), 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败（LaunchDaemon 需要 sudo）: %w", path, err)
+	}
+	return nil
+}
+
+// Uninstall 停止服务并删除 launchd 配置文件
+func Uninstall(cfg *Config) error {
+	path, err := plistPath(cfg)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("服务 %s 未安装", cfg.Label())
+	}
+	launchctl("bootout", domain(cfg)+"/"+cfg.Label()) // 未运行时失败，忽略
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("删除 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+// Start 加载 launchd 配置并启动服务
+func Start(cfg *Config) error {
+	path, err := plistPath(cfg)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("服务 %s 未安装", cfg.Label())
+	}
+	return launchctl("bootstrap", domain(cfg), path)
+}
+
+// Stop 停止服务并从 launchd 卸载（下次登录或开机时仍会启动）
+func Stop(cfg *Config) error {
+	return launchctl("bootout", domain(cfg)+"/"+cfg.Label())
+}
+
+// launchctl 执行 launchctl 命令，失败时错误信息包含命令输出
+func launchctl(args ...string) error {
+	output, err := exec.Command("launchctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("launchctl %s 失败: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
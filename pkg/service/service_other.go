@@ -0,0 +1,25 @@
+//go:build !windows && !darwin
+
+package service
+
+import "fmt"
+
+// Install 不直接安装：systemd 单元需要按桌面会话调整，使用 SystemdUnit 生成后由用户安装
+func Install(cfg *Config) error {
+	return fmt.Errorf("%w，请将 SystemdUnit 生成的单元保存到 ~/.config/systemd/user/%s.service", ErrNotSupported, cfg.Name)
+}
+
+// Uninstall 由 systemctl 管理
+func Uninstall(cfg *Config) error {
+	return fmt.Errorf("%w，请执行 systemctl --user disable --now %s", ErrNotSupported, cfg.Name)
+}
+
+// Start 由 systemctl 管理
+func Start(cfg *Config) error {
+	return fmt.Errorf("%w，请执行 systemctl --user start %s", ErrNotSupported, cfg.Name)
+}
+
+// Stop 由 systemctl 管理
+func Stop(cfg *Config) error {
+	return fmt.Errorf("%w，请执行 systemctl --user stop %s", ErrNotSupported, cfg.Name)
+}
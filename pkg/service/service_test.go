@@ -0,0 +1,90 @@
+package service
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func testConfig() *Config {
+	return &Config{
+		Name:        DefaultName,
+		DisplayName: "Zoey Worker",
+		Description: "Zoey Worker UI 自动化执行节点",
+		Executable:  "/opt/Zoey Worker/zoeyworker",
+		Args:        []string{"-service", "-profile", "R&D"},
+		LogFile:     "/tmp/zoey/service.log",
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	cfg := testConfig()
+	plist := LaunchdPlist(cfg)
+
+	// 必须是合法的 XML，参数中的特殊字符被转义
+	decoder := xml.NewDecoder(strings.NewReader(plist))
+	decoder.Strict = false
+	var args []string
+	inArray := false
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			inArray = inArray || tok.Name.Local == "array"
+		case xml.EndElement:
+			if tok.Name.Local == "array" {
+				inArray = false
+			}
+		case xml.CharData:
+			if inArray && strings.TrimSpace(string(tok)) != "" {
+				args = append(args, string(tok))
+			}
+		}
+	}
+	want := []string{"/opt/Zoey Worker/zoeyworker", "-service", "-profile", "R&D"}
+	if strings.Join(args, "|") != strings.Join(want, "|") {
+		t.Errorf("ProgramArguments = %q, 期望 %q", args, want)
+	}
+	if !strings.Contains(plist, "<string>com.zoeyai.zoeyworker</string>") {
+		t.Error("应包含 Label")
+	}
+	if !strings.Contains(plist, "<string>Aqua</string>") {
+		t.Error("LaunchAgent 应限定在图形会话中运行")
+	}
+
+	cfg.System = true
+	if strings.Contains(LaunchdPlist(cfg), "Aqua") {
+		t.Error("LaunchDaemon 不应限定会话类型")
+	}
+}
+
+func TestSystemdUnit(t *testing.T) {
+	unit := SystemdUnit(testConfig())
+	for _, want := range []string{
+		`ExecStart="/opt/Zoey Worker/zoeyworker" -service -profile R&D`,
+		"Restart=on-failure",
+		"StandardOutput=append:/tmp/zoey/service.log",
+		"WantedBy=default.target",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("单元应包含 %q:\n%s", want, unit)
+		}
+	}
+}
+
+func TestSystemdQuote(t *testing.T) {
+	for input, want := range map[string]string{
+		"plain":      "plain",
+		"with space": `"with space"`,
+		`a"b`:        `"a\"b"`,
+		"50%":        "50%%",
+		"":           `""`,
+	} {
+		if got := systemdQuote(input); got != want {
+			t.Errorf("systemdQuote(%q) = %s, 期望 %s", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+//go:build windows
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+
+	"github.com/zoeyai/zoeyworker/internal/logger"
+)
+
+// stopTimeout 停止服务时等待 Worker 断开连接的最长时间
+const stopTimeout = 20 * time.Second
+
+// Install 注册 Windows 服务（自动延迟启动，异常退出后 5 秒重启），不立即启动
+func Install(cfg *Config) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("连接服务管理器失败（需要管理员权限）: %w", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(cfg.Name); err == nil {
+		s.Close()
+		return fmt.Errorf("服务 %s 已存在", cfg.Name)
+	}
+	s, err := m.CreateService(cfg.Name, cfg.Executable, mgr.Config{
+		DisplayName:      cfg.DisplayName,
+		Description:      cfg.Description,
+		StartType:        mgr.StartAutomatic,
+		DelayedAutoStart: true,
+		ServiceStartName: cfg.User,
+		Password:         cfg.Password,
+	}, cfg.Args...)
+	if err != nil {
+		return fmt.Errorf("创建服务失败: %w", err)
+	}
+	defer s.Close()
+
+	restart := mgr.RecoveryAction{Type: mgr.ServiceRestart, Delay: 5 * time.Second}
+	if err := s.SetRecoveryActions([]mgr.RecoveryAction{restart, restart, restart}, 24*60*60); err != nil {
+		logger.Warn("设置服务 %s 的失败重启策略失败: %v", cfg.Name, err)
+	}
+	return nil
+}
+
+// Uninstall 停止并删除 Windows 服务
+func Uninstall(cfg *Config) error {
+	m, s, err := openService(cfg.Name)
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer s.Close()
+
+	stopAndWait(s)
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("删除服务失败: %w", err)
+	}
+	return nil
+}
+
+// Start 启动 Windows 服务
+func Start(cfg *Config) error {
+	m, s, err := openService(cfg.Name)
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer s.Close()
+
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("启动服务失败: %w", err)
+	}
+	return nil
+}
+
+// Stop 停止 Windows 服务并等待其退出
+func Stop(cfg *Config) error {
+	m, s, err := openService(cfg.Name)
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer s.Close()
+
+	return stopAndWait(s)
+}
+
+// openService 连接服务管理器并打开服务
+func openService(name string) (*mgr.Mgr, *mgr.Service, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, nil, fmt.Errorf("连接服务管理器失败（需要管理员权限）: %w", err)
+	}
+	s, err := m.OpenService(name)
+	if err != nil {
+		m.Disconnect()
+		return nil, nil, fmt.Errorf("服务 %s 未安装: %w", name, err)
+	}
+	return m, s, nil
+}
+
+// stopAndWait 发送停止请求并等待服务进入已停止状态（已停止时直接返回）
+func stopAndWait(s *mgr.Service) error {
+	status, err := s.Query()
+	if err != nil {
+		return fmt.Errorf("查询服务状态失败: %w", err)
+	}
+	if status.State == svc.Stopped {
+		return nil
+	}
+	if status, err = s.Control(svc.Stop); err != nil {
+		return fmt.Errorf("停止服务失败: %w", err)
+	}
+	deadline := time.Now().Add(stopTimeout)
+	for status.State != svc.Stopped {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("等待服务停止超时（当前状态 %d）", status.State)
+		}
+		time.Sleep(300 * time.Millisecond)
+		if status, err = s.Query(); err != nil {
+			return fmt.Errorf("查询服务状态失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// Notify 返回收到停止请求时取消的 ctx：由服务管理器启动时响应服务的停止和关机请求，否则响应 Ctrl+C
+// Worker 断开连接等清理完成后调用 done，服务随后报告为已停止
+func Notify(name string) (context.Context, func()) {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return notifySignals()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &handler{cancel: cancel, done: make(chan struct{})}
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		if err := svc.Run(name, h); err != nil {
+			logger.Error("服务运行失败: %v", err)
+			cancel()
+		}
+	}()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() { close(h.done) })
+		select {
+		case <-finished:
+		case <-time.After(stopTimeout):
+		}
+	}
+}
+
+// handler 服务控制请求处理
+type handler struct {
+	cancel context.CancelFunc
+	done   chan struct{} // Worker 清理完成
+}
+
+// Execute 报告服务运行中，收到停止或关机请求时取消 ctx 并等待 Worker 清理完成
+func (h *handler) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for {
+		select {
+		case <-h.done:
+			// Worker 自行退出（如认证失败）
+			status <- svc.Status{State: svc.StopPending}
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending, WaitHint: uint32(stopTimeout / time.Millisecond)}
+				h.cancel()
+				select {
+				case <-h.done:
+				case <-time.After(stopTimeout):
+				}
+				return false, 0
+			}
+		}
+	}
+}
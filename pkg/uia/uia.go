@@ -1,5 +1,33 @@
 package uia
 
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotSupported 当前环境不支持 UI Automation
+var ErrNotSupported = errors.New("UI Automation 不受支持")
+
+// WindowTarget 目标窗口标识
+// 优先使用原生窗口句柄（Windows 为 HWND）；句柄不可用时按进程 ID 连接
+type WindowTarget struct {
+	Handle int64
+	PID    int
+}
+
+// IsValid 是否包含可用的窗口标识
+func (t WindowTarget) IsValid() bool {
+	return t.Handle != 0 || t.PID != 0
+}
+
+// String 返回便于日志输出的描述
+func (t WindowTarget) String() string {
+	if t.Handle != 0 {
+		return fmt.Sprintf("handle=%d", t.Handle)
+	}
+	return fmt.Sprintf("pid=%d", t.PID)
+}
+
 // GetElementsOptions UI 元素获取选项
 type GetElementsOptions struct {
 	AutomationID string
@@ -33,6 +61,17 @@ func IsSupported() bool {
 }
 
 // GetElements 获取 UI 元素列表
-func GetElements(windowHandle int, opts *GetElementsOptions) ([]ElementInfo, error) {
+func GetElements(target WindowTarget, opts *GetElementsOptions) ([]ElementInfo, error) {
 	return nil, nil
 }
+
+// ClickElement 点击指定窗口中 AutomationID 对应的元素
+func ClickElement(target WindowTarget, automationID string) error {
+	if !IsSupported() {
+		return ErrNotSupported
+	}
+	if !target.IsValid() {
+		return fmt.Errorf("无效的窗口标识: %s", target)
+	}
+	return nil
+}
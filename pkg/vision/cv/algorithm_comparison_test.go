@@ -0,0 +1,75 @@
+package cv
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// comparisonMethods 对比测试覆盖的匹配方法
+var comparisonMethods = []MatchMethod{MatchMethodSIFT, MatchMethodORB, MatchMethodTemplate}
+
+// TestAlgorithmComparison 在同一组模板上对比各匹配方法的结果、置信度和耗时
+func TestAlgorithmComparison(t *testing.T) {
+	screen, err := ReadImage(filepath.Join("testdata", "target.png"))
+	if err != nil {
+		t.Skipf("读取测试截图失败（需要 OpenCV）: %v", err)
+	}
+	defer screen.Close()
+
+	templates := []string{"template1.png", "template2.png", "template3.png"}
+	for _, method := range comparisonMethods {
+		for _, name := range templates {
+			t.Run(string(method)+"/"+name, func(t *testing.T) {
+				tmpl := NewTemplate(filepath.Join("testdata", name),
+					WithTemplateMethods(method),
+					WithTemplateScales(1.0),
+				)
+				defer tmpl.Close()
+
+				start := time.Now()
+				result, err := tmpl.BestCandidateIn(screen)
+				elapsed := time.Since(start)
+				if err != nil {
+					t.Fatalf("匹配失败: %v", err)
+				}
+				if result == nil {
+					t.Logf("%-4s %-14s 无候选 (%v)", method, name, elapsed)
+					return
+				}
+				t.Logf("%-4s %-14s 位置=(%d,%d) 置信度=%.3f 耗时=%v",
+					method, name, result.Result.X, result.Result.Y, result.Confidence, elapsed)
+			})
+		}
+	}
+}
+
+func TestParseMatchMethod(t *testing.T) {
+	for name, want := range map[string]MatchMethod{
+		"sift":  MatchMethodSIFT,
+		"ORB":   MatchMethodORB,
+		" tpl ": MatchMethodTemplate,
+	} {
+		got, err := ParseMatchMethod(name)
+		if err != nil || got != want {
+			t.Errorf("ParseMatchMethod(%q) = %q, %v; 期望 %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseMatchMethod("akaze"); err == nil {
+		t.Error("未知方法应返回错误")
+	}
+}
+
+func TestTemplateMethods(t *testing.T) {
+	tmpl := NewTemplate("a.png")
+	if !reflect.DeepEqual(tmpl.methods(), DefaultMatchMethods) {
+		t.Errorf("默认方法链 = %v, 期望 %v", tmpl.methods(), DefaultMatchMethods)
+	}
+
+	tmpl = NewTemplate("a.png", WithTemplateMethods(MatchMethodTemplate, MatchMethodORB))
+	want := []MatchMethod{MatchMethodTemplate, MatchMethodORB}
+	if !reflect.DeepEqual(tmpl.methods(), want) {
+		t.Errorf("方法链 = %v, 期望 %v", tmpl.methods(), want)
+	}
+}
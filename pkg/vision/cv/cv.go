@@ -1,6 +1,6 @@
 // Package cv 提供图像匹配功能
 //
-// 仅保留 SIFT 特征点匹配
+// 默认使用 SIFT 特征点匹配，可通过 WithTemplateMethods 选择 ORB 或 matchTemplate
 //
 // 基本用法:
 //
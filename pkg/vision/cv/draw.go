@@ -0,0 +1,55 @@
+package cv
+
+import (
+	"image"
+	"image/color"
+
+	"gocv.io/x/gocv"
+)
+
+// 标注颜色
+var (
+	// ColorRegion 搜索区域标注颜色（蓝）
+	ColorRegion = color.RGBA{R: 0, G: 128, B: 255, A: 255}
+	// ColorCandidate 候选结果标注颜色（红）
+	ColorCandidate = color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	// ColorMatched 匹配成功标注颜色（绿）
+	ColorMatched = color.RGBA{R: 0, G: 200, B: 0, A: 255}
+)
+
+// DrawRectangle 在图像上绘制匹配区域（四个角点连成的外接矩形）
+func DrawRectangle(img *gocv.Mat, rect Rectangle, c color.RGBA, thickness int) {
+	minX, minY, maxX, maxY := rectBounds(rect)
+	gocv.Rectangle(img, image.Rect(minX, minY, maxX, maxY), c, thickness)
+}
+
+// DrawLabel 在图像指定位置绘制带白色背景的文字标签
+// 仅支持 ASCII 字符（OpenCV Hershey 字体）
+func DrawLabel(img *gocv.Mat, text string, pt image.Point, c color.RGBA) {
+	const fontScale = 0.6
+	const thickness = 2
+
+	size := gocv.GetTextSize(text, gocv.FontHersheySimplex, fontScale, thickness)
+	x := max(0, pt.X)
+	y := max(size.Y+6, pt.Y)
+
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	bg := image.Rect(x, y-size.Y-6, x+size.X+6, y+4)
+	gocv.Rectangle(img, bg, white, -1)
+	gocv.Rectangle(img, bg, c, 1)
+	gocv.PutText(img, text, image.Pt(x+3, y), gocv.FontHersheySimplex, fontScale, c, thickness)
+}
+
+// rectBounds 计算四个角点的外接矩形
+func rectBounds(rect Rectangle) (minX, minY, maxX, maxY int) {
+	pts := []Point{rect.TopLeft, rect.BottomLeft, rect.BottomRight, rect.TopRight}
+	minX, minY = pts[0].X, pts[0].Y
+	maxX, maxY = pts[0].X, pts[0].Y
+	for _, p := range pts[1:] {
+		minX = min(minX, p.X)
+		minY = min(minY, p.Y)
+		maxX = max(maxX, p.X)
+		maxY = max(maxY, p.Y)
+	}
+	return
+}
@@ -0,0 +1,15 @@
+package cv
+
+import "errors"
+
+// 图像匹配的错误分类，可通过 errors.Is 判断
+var (
+	// ErrMultipleMatches 匹配到多个候选目标，无法确定唯一结果
+	ErrMultipleMatches = errors.New("multiple matches")
+	// ErrMatchTimeout 循环匹配超时
+	ErrMatchTimeout = errors.New("匹配超时")
+	// ErrTemplateNotFound URL 模板不存在（HTTP 404/410）
+	ErrTemplateNotFound = errors.New("template url not found")
+	// ErrTemplateDownload URL 模板下载失败（网络错误、服务端错误、超过大小上限等）
+	ErrTemplateDownload = errors.New("template download failed")
+)
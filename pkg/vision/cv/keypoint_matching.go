@@ -1,6 +1,7 @@
 package cv
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sort"
@@ -38,8 +39,33 @@ type keypointMatchingBase struct {
 
 // FindBestResult 查找最佳匹配结果
 func (k *keypointMatchingBase) FindBestResult() (*MatchResult, error) {
+	return k.findBest(context.Background(), true)
+}
+
+// FindBestResultCtx 查找最佳匹配结果，ctx 取消时在下一个阶段（检测、匹配、单应性计算）前返回 ctx.Err()
+func (k *keypointMatchingBase) FindBestResultCtx(ctx context.Context) (*MatchResult, error) {
+	return k.findBest(ctx, true)
+}
+
+// FindBestCandidate 查找最佳候选结果（忽略置信度阈值，用于失败诊断）
+func (k *keypointMatchingBase) FindBestCandidate() (*MatchResult, error) {
+	return k.findBest(context.Background(), false)
+}
+
+// FindBestCandidateCtx 查找最佳候选结果，支持通过 ctx 取消
+func (k *keypointMatchingBase) FindBestCandidateCtx(ctx context.Context) (*MatchResult, error) {
+	return k.findBest(ctx, false)
+}
+
+// findBest 查找最佳匹配，applyThreshold 为 false 时不做置信度校验
+// 各阶段之间检查 ctx，已取消时直接返回 ctx.Err()
+func (k *keypointMatchingBase) findBest(ctx context.Context, applyThreshold bool) (*MatchResult, error) {
 	startTime := time.Now()
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// 检查图像
 	if k.imSearch.Empty() || k.imSource.Empty() {
 		return nil, fmt.Errorf("图像为空")
@@ -47,14 +73,21 @@ func (k *keypointMatchingBase) FindBestResult() (*MatchResult, error) {
 
 	// 检测特征点
 	kpSearch, descSearch := k.detector.Detect(k.imSearch)
-	kpSource, descSource := k.detector.Detect(k.imSource)
 	defer descSearch.Close()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	kpSource, descSource := k.detector.Detect(k.imSource)
 	defer descSource.Close()
 
 	if len(kpSearch) < 2 || len(kpSource) < 2 {
 		return nil, nil
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// 创建匹配器（使用匹配器对应的距离类型）
 	matcher := gocv.NewBFMatcherWithParams(k.normType, false)
 	defer matcher.Close()
@@ -69,6 +102,9 @@ func (k *keypointMatchingBase) FindBestResult() (*MatchResult, error) {
 	}
 
 	// 计算匹配结果
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	result, err := k.computeResult(kpSearch, kpSource, goodMatches)
 	if err != nil {
 		return nil, err
@@ -79,9 +115,10 @@ func (k *keypointMatchingBase) FindBestResult() (*MatchResult, error) {
 	}
 
 	result.Time = float64(time.Since(startTime).Milliseconds())
+	result.Scale = 1.0
 
 	// 置信度校验
-	if result.Confidence < k.threshold {
+	if applyThreshold && result.Confidence < k.threshold {
 		return nil, nil
 	}
 
@@ -90,7 +127,12 @@ func (k *keypointMatchingBase) FindBestResult() (*MatchResult, error) {
 
 // FindAllResults 查找所有匹配结果（特征点匹配通常只返回一个结果）
 func (k *keypointMatchingBase) FindAllResults() ([]*MatchResult, error) {
-	result, err := k.FindBestResult()
+	return k.FindAllResultsCtx(context.Background())
+}
+
+// FindAllResultsCtx 查找所有匹配结果，支持通过 ctx 取消
+func (k *keypointMatchingBase) FindAllResultsCtx(ctx context.Context) ([]*MatchResult, error) {
+	result, err := k.FindBestResultCtx(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -443,10 +485,60 @@ func NewSIFTMatching(search, source gocv.Mat, threshold float64) *SIFTMatching {
 
 // Detect 检测特征点
 func (s *SIFTMatching) Detect(img gocv.Mat) ([]gocv.KeyPoint, gocv.Mat) {
-	return s.sift.DetectAndCompute(img, gocv.NewMat())
+	mask := gocv.NewMat()
+	defer mask.Close()
+	return s.sift.DetectAndCompute(img, mask)
 }
 
 // Close 释放资源
 func (s *SIFTMatching) Close() {
 	s.sift.Close()
 }
+
+// ORB 参数：UI 模板通常较小，缩小边缘阈值和 patch 尺寸以保留更多特征点
+const (
+	orbFeatures      = 1000
+	orbScaleFactor   = 1.2
+	orbLevels        = 8
+	orbEdgeThreshold = 15
+	orbPatchSize     = 15
+	orbFastThreshold = 10
+)
+
+// ORBMatching ORB 特征点匹配（二进制描述子，比 SIFT 快但对低纹理图像更敏感）
+type ORBMatching struct {
+	*keypointMatchingBase
+	orb gocv.ORB
+}
+
+// NewORBMatching 创建 ORB 匹配器
+func NewORBMatching(search, source gocv.Mat, threshold float64) *ORBMatching {
+	orb := gocv.NewORBWithParams(orbFeatures, orbScaleFactor, orbLevels, orbEdgeThreshold, 0, 2,
+		gocv.ORBScoreTypeHarris, orbPatchSize, orbFastThreshold)
+	m := &ORBMatching{
+		keypointMatchingBase: &keypointMatchingBase{
+			imSearch:   search,
+			imSource:   source,
+			threshold:  threshold,
+			normType:   gocv.NormHamming,
+			methodName: "ORB",
+			minInliers: defaultKeypointMinInliers,
+			minInRate:  defaultKeypointMinInlierRate,
+		},
+		orb: orb,
+	}
+	m.detector = m
+	return m
+}
+
+// Detect 检测特征点
+func (o *ORBMatching) Detect(img gocv.Mat) ([]gocv.KeyPoint, gocv.Mat) {
+	mask := gocv.NewMat()
+	defer mask.Close()
+	return o.orb.DetectAndCompute(img, mask)
+}
+
+// Close 释放资源
+func (o *ORBMatching) Close() {
+	o.orb.Close()
+}
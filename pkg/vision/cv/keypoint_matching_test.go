@@ -0,0 +1,79 @@
+package cv
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+// cancelingDetector 第一次检测时取消 ctx，并记录检测次数
+type cancelingDetector struct {
+	cancel context.CancelFunc
+	calls  int
+}
+
+func (d *cancelingDetector) Detect(img gocv.Mat) ([]gocv.KeyPoint, gocv.Mat) {
+	d.calls++
+	d.cancel()
+	return nil, gocv.NewMat()
+}
+
+func (d *cancelingDetector) Close() {}
+
+func TestFindBestResultCtx_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	detector := &cancelingDetector{cancel: func() {}}
+	k := &keypointMatchingBase{detector: detector, imSearch: gocv.NewMat(), imSource: gocv.NewMat()}
+	if _, err := k.FindBestResultCtx(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("已取消的 ctx 应返回 context.Canceled, 实际 %v", err)
+	}
+	if detector.calls != 0 {
+		t.Errorf("已取消时不应开始检测, 检测次数 = %d", detector.calls)
+	}
+}
+
+func TestFindBestResultCtx_CancelledBetweenStages(t *testing.T) {
+	search := gocv.NewMatWithSize(32, 32, gocv.MatTypeCV8UC3)
+	defer search.Close()
+	source := gocv.NewMatWithSize(64, 64, gocv.MatTypeCV8UC3)
+	defer source.Close()
+	if search.Empty() || source.Empty() {
+		t.Skip("需要 OpenCV")
+	}
+
+	// 检测模板特征点期间取消：应在检测屏幕特征点之前返回
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	detector := &cancelingDetector{cancel: cancel}
+	k := &keypointMatchingBase{detector: detector, imSearch: search, imSource: source}
+
+	if _, err := k.FindBestResultCtx(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("应返回 context.Canceled, 实际 %v", err)
+	}
+	if detector.calls != 1 {
+		t.Errorf("取消后应在下一阶段前返回, 检测次数 = %d, 期望 1", detector.calls)
+	}
+}
+
+func TestTemplateMatchResultInCtx_Cancelled(t *testing.T) {
+	var loads int
+	old := templates
+	templates = newTemplateCache(1, func(string, bool) (templateImage, error) {
+		loads++
+		return templateImage{mat: gocv.NewMat()}, nil
+	})
+	defer func() { templates = old }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tmpl := NewTemplate("a.png", WithTemplateMethods(MatchMethodSIFT, MatchMethodORB))
+	defer tmpl.Close()
+	if _, err := tmpl.MatchResultInCtx(ctx, gocv.NewMat()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("应返回 context.Canceled, 实际 %v", err)
+	}
+}
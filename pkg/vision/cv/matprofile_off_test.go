@@ -0,0 +1,8 @@
+//go:build !matprofile
+
+package cv
+
+// liveMats 未启用 matprofile 时无法统计 Mat 数量
+func liveMats() (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,10 @@
+//go:build matprofile
+
+package cv
+
+import "gocv.io/x/gocv"
+
+// liveMats 返回当前存活的 Mat 数量（需要 -tags matprofile）
+func liveMats() (int, bool) {
+	return gocv.MatProfile.Count(), true
+}
@@ -0,0 +1,75 @@
+package cv
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// 浸泡测试参数
+const (
+	soakIterations = 1000
+	soakWarmup     = 10
+	// soakHeapSlack 允许的 Go 堆增长（GC 统计误差）
+	soakHeapSlack = 4 << 20
+)
+
+// TestMatchSoak 反复执行完整匹配流程，检查 Mat 和内存没有随迭代次数增长
+// 使用 -tags matprofile 编译时按 gocv.MatProfile 精确统计存活的 Mat
+func TestMatchSoak(t *testing.T) {
+	if testing.Short() {
+		t.Skip("浸泡测试耗时较长，-short 时跳过")
+	}
+	screen, err := ReadImage(filepath.Join("testdata", "target.png"))
+	if err != nil {
+		t.Skipf("读取测试截图失败（需要 OpenCV）: %v", err)
+	}
+	defer screen.Close()
+	// 裁剪一块较小的区域，避免 SIFT 整屏检测拖慢测试
+	region := CropImage(screen, [4]int{0, 0, 640, 480})
+	defer region.Close()
+
+	path := filepath.Join("testdata", "template1.png")
+	iterate := func() {
+		tmpl := NewTemplate(path,
+			WithTemplateMethods(MatchMethodTemplate, MatchMethodORB, MatchMethodSIFT),
+			WithTemplateScales(0.9, 1.0),
+			WithTemplateGrayscale(),
+		)
+		if _, err := tmpl.BestCandidateIn(region); err != nil {
+			t.Fatalf("匹配失败: %v", err)
+		}
+		if _, err := tmpl.MatchAllIn(region); err != nil {
+			t.Fatalf("多目标匹配失败: %v", err)
+		}
+		tmpl.Close()
+	}
+
+	// 预热：填充模板缓存和检测器内部状态
+	for i := 0; i < soakWarmup; i++ {
+		iterate()
+	}
+	baseMats, profiled := liveMats()
+	baseHeap := heapAlloc()
+
+	for i := 0; i < soakIterations; i++ {
+		iterate()
+	}
+
+	if profiled {
+		if mats, _ := liveMats(); mats != baseMats {
+			t.Errorf("存活 Mat 数 %d -> %d，存在未释放的 Mat", baseMats, mats)
+		}
+	}
+	if heap := heapAlloc(); heap > baseHeap+soakHeapSlack {
+		t.Errorf("Go 堆 %d -> %d 字节，%d 次迭代后持续增长", baseHeap, heap, soakIterations)
+	}
+}
+
+// heapAlloc GC 后的 Go 堆占用
+func heapAlloc() uint64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
@@ -1,9 +1,10 @@
 package cv
 
 import (
+	"context"
 	"fmt"
+	stdimage "image"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"gocv.io/x/gocv"
@@ -25,9 +26,23 @@ type Template struct {
 	Threshold float64
 	// ScaleCandidates 额外缩放候选（用于特征点匹配）
 	ScaleCandidates []float64
-
-	// 缓存的模板图像
-	cachedMat *gocv.Mat
+	// Grayscale 是否将模板和屏幕转为灰度图后匹配
+	Grayscale bool
+	// Methods 匹配方法链，按顺序尝试直到匹配成功（为空时使用默认方法）
+	Methods []MatchMethod
+	// NoMask 忽略模板的 alpha 通道（默认 PNG 模板含透明像素时按掩码匹配，透明像素不参与比较）
+	NoMask bool
+	// Overlap 多目标匹配时非极大值抑制的 IoU 阈值
+	Overlap float64
+	// MaxResults 多目标匹配返回的最大结果数（<= 0 表示不限制）
+	MaxResults int
+	// Policy 多个候选置信度并列时的选择策略
+	Policy MatchPolicy
+	// PyramidMinWidth matchTemplate 时屏幕宽度超过该值先在缩小图上粗匹配、再在候选区域精匹配（0 总是启用，< 0 禁用）
+	PyramidMinWidth int
+
+	// 模板图像引用（首次匹配时从全局缓存获取，Close 时释放）
+	ref *templateRef
 }
 
 // TemplateOption 模板选项
@@ -46,6 +61,11 @@ func NewTemplate(filename string, opts ...TemplateOption) *Template {
 			1.5,
 			2.0,
 		},
+		Overlap:    DefaultOverlapThreshold,
+		MaxResults: DefaultMaxResults,
+		Policy:     MatchPolicyFirst,
+
+		PyramidMinWidth: DefaultPyramidMinWidth,
 	}
 
 	for _, opt := range opts {
@@ -69,6 +89,59 @@ func WithTemplateScales(scales ...float64) TemplateOption {
 	}
 }
 
+// WithTemplateGrayscale 设置以灰度图匹配
+func WithTemplateGrayscale() TemplateOption {
+	return func(t *Template) {
+		t.Grayscale = true
+	}
+}
+
+// WithTemplateMethods 设置匹配方法链，按顺序尝试直到匹配成功
+func WithTemplateMethods(methods ...MatchMethod) TemplateOption {
+	return func(t *Template) {
+		t.Methods = methods
+	}
+}
+
+// WithTemplateOverlap 设置多目标匹配时非极大值抑制的 IoU 阈值
+func WithTemplateOverlap(overlap float64) TemplateOption {
+	return func(t *Template) {
+		t.Overlap = overlap
+	}
+}
+
+// WithTemplateMaxResults 设置多目标匹配返回的最大结果数
+func WithTemplateMaxResults(n int) TemplateOption {
+	return func(t *Template) {
+		t.MaxResults = n
+	}
+}
+
+// WithTemplatePolicy 设置多个候选置信度并列时的选择策略
+func WithTemplatePolicy(policy MatchPolicy) TemplateOption {
+	return func(t *Template) {
+		t.Policy = policy
+	}
+}
+
+// WithTemplatePyramid 设置是否启用粗到细匹配（默认仅在屏幕宽度超过 DefaultPyramidMinWidth 时启用）
+func WithTemplatePyramid(enabled bool) TemplateOption {
+	return func(t *Template) {
+		if enabled {
+			t.PyramidMinWidth = 0
+		} else {
+			t.PyramidMinWidth = -1
+		}
+	}
+}
+
+// WithTemplateMask 设置是否使用模板 alpha 通道作为匹配掩码（默认启用）
+func WithTemplateMask(enabled bool) TemplateOption {
+	return func(t *Template) {
+		t.NoMask = !enabled
+	}
+}
+
 // MatchIn 在屏幕图像中匹配模板
 func (t *Template) MatchIn(screen gocv.Mat) (*Point, error) {
 	result, err := t.cvMatch(screen)
@@ -88,95 +161,281 @@ func (t *Template) MatchResultIn(screen gocv.Mat) (*MatchResult, error) {
 	return t.cvMatch(screen)
 }
 
-// MatchAllIn 在屏幕图像中查找所有匹配
+// MatchResultInCtx 在屏幕图像中匹配模板，ctx 取消时在下一个匹配阶段前返回 ctx.Err()
+func (t *Template) MatchResultInCtx(ctx context.Context, screen gocv.Mat) (*MatchResult, error) {
+	return t.match(ctx, screen, true)
+}
+
+// BestCandidateIn 在屏幕图像中查找最佳候选（忽略阈值，用于失败诊断）
+// 返回的 Confidence 可能低于 Threshold；没有任何候选时返回 nil
+func (t *Template) BestCandidateIn(screen gocv.Mat) (*MatchResult, error) {
+	return t.match(context.Background(), screen, false)
+}
+
+// MatchAllIn 在屏幕图像中查找所有匹配，结果按置信度降序排列
+// matchTemplate 方法返回经非极大值抑制去重的多个结果（最多 MaxResults 个）；特征点方法只返回最佳结果
 func (t *Template) MatchAllIn(screen gocv.Mat) ([]*MatchResult, error) {
-	result, err := t.cvMatch(screen)
-	if err != nil || result == nil {
+	return t.MatchAllInCtx(context.Background(), screen)
+}
+
+// MatchAllInCtx 在屏幕图像中查找所有匹配，支持通过 ctx 取消
+func (t *Template) MatchAllInCtx(ctx context.Context, screen gocv.Mat) ([]*MatchResult, error) {
+	image, screen, cleanup, err := t.prepare(screen)
+	if err != nil {
 		return nil, err
 	}
-	return []*MatchResult{result}, nil
+	defer cleanup()
+	return t.matchAll(ctx, image, screen)
 }
 
 // cvMatch 执行 CV 匹配
 func (t *Template) cvMatch(screen gocv.Mat) (*MatchResult, error) {
-	image, err := t.readImage()
+	return t.match(context.Background(), screen, true)
+}
+
+// match 按匹配方法链依次匹配，返回第一个达到阈值的方法的结果
+// applyThreshold 为 false 时尝试所有方法，返回置信度最高的候选
+// 非默认的并列策略需要先找出全部候选，再按 Policy 选择
+func (t *Template) match(ctx context.Context, screen gocv.Mat, applyThreshold bool) (*MatchResult, error) {
+	image, screen, cleanup, err := t.prepare(screen)
 	if err != nil {
 		return nil, err
 	}
-	defer image.Close()
+	defer cleanup()
 
-	scaleList := t.ScaleCandidates
-	if len(scaleList) == 0 {
-		scaleList = []float64{1.0}
+	if applyThreshold && t.Policy != "" && t.Policy != MatchPolicyFirst {
+		results, err := t.matchAll(ctx, image, screen)
+		if err != nil {
+			return nil, err
+		}
+		return selectMatch(results, t.Policy)
 	}
 
 	var best *MatchResult
-	for _, scale := range scaleList {
-		scaledImage, cleanup := scaleTemplate(image, scale)
-		m := NewSIFTMatching(scaledImage, screen, t.Threshold)
-		result, err := m.FindBestResult()
-		m.Close()
-		if cleanup != nil {
-			cleanup()
+	for _, method := range t.methods() {
+		result, err := t.matchMethod(ctx, method, image, screen, applyThreshold)
+		if err != nil {
+			return nil, err
 		}
-		if err != nil || result == nil {
+		if result == nil {
 			continue
 		}
+		if applyThreshold {
+			return result, nil
+		}
 		if best == nil || result.Confidence > best.Confidence {
 			best = result
 		}
 	}
-	if best != nil {
-		return best, nil
+	return best, nil
+}
+
+// prepare 读取模板并按需将屏幕转为灰度图，cleanup 释放转换产生的临时图像
+// 模板图像由缓存共享，不能 Close
+func (t *Template) prepare(screen gocv.Mat) (image, search gocv.Mat, cleanup func(), err error) {
+	image, err = t.readImage()
+	if err != nil {
+		return gocv.Mat{}, gocv.Mat{}, nil, err
+	}
+	if t.Grayscale && screen.Channels() != 1 {
+		grayScreen := ToGray(screen)
+		return image, grayScreen, func() { grayScreen.Close() }, nil
 	}
+	return image, screen, func() {}, nil
+}
 
+// matchAll 按匹配方法链依次查找所有匹配，返回第一个有结果的方法的结果
+func (t *Template) matchAll(ctx context.Context, image, screen gocv.Mat) ([]*MatchResult, error) {
+	for _, method := range t.methods() {
+		if method != MatchMethodTemplate {
+			result, err := t.matchMethod(ctx, method, image, screen, true)
+			if err != nil {
+				return nil, err
+			}
+			if result != nil {
+				return []*MatchResult{result}, nil
+			}
+			continue
+		}
+
+		var all []*MatchResult
+		for _, scale := range t.scales() {
+			m, cleanup := t.templateMatcher(image, screen, scale)
+			m.SetNMS(t.Overlap, t.MaxResults)
+			results, err := m.FindAllResultsCtx(ctx)
+			cleanup()
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			if err != nil {
+				continue
+			}
+			for _, r := range results {
+				r.Scale = effectiveScale(scale)
+			}
+			all = append(all, results...)
+		}
+		if len(all) > 0 {
+			return suppressResults(all, t.Overlap, t.MaxResults), nil
+		}
+	}
 	return nil, nil
 }
 
-// readImage 读取模板图像
-func (t *Template) readImage() (gocv.Mat, error) {
-	filename := t.Filename
+// suppressResults 合并多个缩放候选的结果：非极大值抑制后按置信度降序返回
+func suppressResults(results []*MatchResult, overlap float64, maxCount int) []*MatchResult {
+	boxes := make([]stdimage.Rectangle, len(results))
+	scores := make([]float64, len(results))
+	for i, r := range results {
+		boxes[i] = resultBounds(r)
+		scores[i] = r.Confidence
+	}
+	keep := nonMaxSuppression(boxes, scores, overlap, maxCount)
+	kept := make([]*MatchResult, len(keep))
+	for i, idx := range keep {
+		kept[i] = results[idx]
+	}
+	return kept
+}
 
-	if t.cachedMat != nil && !t.cachedMat.Empty() {
-		return t.cachedMat.Clone(), nil
+// resultBounds 返回匹配结果四个角点的外接矩形
+func resultBounds(r *MatchResult) stdimage.Rectangle {
+	rect := r.Rectangle
+	return stdimage.Rect(
+		min(rect.TopLeft.X, rect.BottomLeft.X), min(rect.TopLeft.Y, rect.TopRight.Y),
+		max(rect.TopRight.X, rect.BottomRight.X), max(rect.BottomLeft.Y, rect.BottomRight.Y),
+	)
+}
+
+// scales 返回缩放候选（为空时仅匹配原始尺寸）
+func (t *Template) scales() []float64 {
+	if len(t.ScaleCandidates) == 0 {
+		return []float64{1.0}
 	}
+	return t.ScaleCandidates
+}
 
-	// 如果是 base64 data URL，直接读取，不处理路径
-	if strings.HasPrefix(filename, "data:image/") {
-		mat, err := ReadImage(filename)
-		if err != nil {
-			return mat, err
+// templateMatcher 创建指定缩放比例的 matchTemplate 匹配器（模板含透明像素时带掩码）
+// cleanup 释放缩放产生的临时图像
+func (t *Template) templateMatcher(image, screen gocv.Mat, scale float64) (*TemplateMatching, func()) {
+	scaledImage, imageCleanup := scaleTemplate(image, scale)
+	var m *TemplateMatching
+	var maskCleanup func()
+	if mask, masked := t.mask(); masked {
+		var scaledMask gocv.Mat
+		scaledMask, maskCleanup = scaleMask(mask, scale)
+		m = NewMaskedTemplateMatching(scaledImage, screen, scaledMask, t.Threshold)
+	} else {
+		m = NewTemplateMatching(scaledImage, screen, t.Threshold)
+	}
+	m.SetPyramidMinWidth(t.PyramidMinWidth)
+	return m, func() {
+		if maskCleanup != nil {
+			maskCleanup()
 		}
-		cached := mat.Clone()
-		if t.cachedMat != nil {
-			t.cachedMat.Close()
+		if imageCleanup != nil {
+			imageCleanup()
 		}
-		t.cachedMat = &cached
-		return mat, nil
 	}
+}
 
-	// 处理相对路径
-	if CurrentPath != "" && !filepath.IsAbs(filename) {
-		filename = filepath.Join(CurrentPath, filename)
+// methods 返回匹配方法链
+// 未指定时：模板含透明像素（且未禁用掩码）使用带掩码的 matchTemplate，否则使用 DefaultMatchMethods
+func (t *Template) methods() []MatchMethod {
+	if len(t.Methods) > 0 {
+		return t.Methods
+	}
+	if _, masked := t.mask(); masked {
+		return []MatchMethod{MatchMethodTemplate}
 	}
+	return DefaultMatchMethods
+}
 
-	mat, err := ReadImage(filename)
-	if err != nil {
-		return mat, err
+// mask 返回模板的 alpha 掩码（未禁用掩码且模板含透明像素时有效）
+func (t *Template) mask() (gocv.Mat, bool) {
+	if t.NoMask || t.ref == nil {
+		return gocv.Mat{}, false
+	}
+	return t.ref.Mask()
+}
+
+// matchMethod 使用指定方法在所有缩放候选上匹配，返回置信度最高的结果
+func (t *Template) matchMethod(ctx context.Context, method MatchMethod, image, screen gocv.Mat, applyThreshold bool) (*MatchResult, error) {
+	switch method {
+	case MatchMethodSIFT, MatchMethodORB, MatchMethodTemplate:
+	default:
+		return nil, fmt.Errorf("不支持的匹配方法: %s", method)
 	}
-	cached := mat.Clone()
-	if t.cachedMat != nil {
-		t.cachedMat.Close()
+
+	var best *MatchResult
+	for _, scale := range t.scales() {
+		var result *MatchResult
+		var err error
+		if method == MatchMethodTemplate {
+			m, cleanup := t.templateMatcher(image, screen, scale)
+			result, err = m.findBest(ctx, applyThreshold)
+			cleanup()
+		} else {
+			scaledImage, cleanup := scaleTemplate(image, scale)
+			if method == MatchMethodORB {
+				m := NewORBMatching(scaledImage, screen, t.Threshold)
+				result, err = m.findBest(ctx, applyThreshold)
+				m.Close()
+			} else {
+				m := NewSIFTMatching(scaledImage, screen, t.Threshold)
+				result, err = m.findBest(ctx, applyThreshold)
+				m.Close()
+			}
+			if cleanup != nil {
+				cleanup()
+			}
+		}
+		// 取消时不再尝试其他缩放比例
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err != nil || result == nil {
+			continue
+		}
+		result.Scale = effectiveScale(scale)
+		if best == nil || result.Confidence > best.Confidence {
+			best = result
+		}
+	}
+	return best, nil
+}
+
+// readImage 读取模板图像（首次调用时解码，之后复用同一个共享 Mat）
+// 支持文件路径、base64 和 http(s) URL
+func (t *Template) readImage() (gocv.Mat, error) {
+	if t.ref == nil {
+		filename := t.Filename
+		switch {
+		case isURLSource(filename):
+			// URL 模板下载到本地缓存目录，之后按文件缓存
+			path, err := fetchTemplate(filename)
+			if err != nil {
+				return gocv.Mat{}, err
+			}
+			filename = path
+		case CurrentPath != "" && !filepath.IsAbs(filename) && !isBase64Source(filename):
+			// 处理相对路径
+			filename = filepath.Join(CurrentPath, filename)
+		}
+		ref, err := templates.acquire(filename, t.Grayscale)
+		if err != nil {
+			return gocv.Mat{}, err
+		}
+		t.ref = ref
 	}
-	t.cachedMat = &cached
-	return mat, nil
+	return t.ref.Mat(), nil
 }
 
 // Close 释放资源
 func (t *Template) Close() {
-	if t.cachedMat != nil {
-		t.cachedMat.Close()
-		t.cachedMat = nil
+	if t.ref != nil {
+		t.ref.Release()
+		t.ref = nil
 	}
 }
 
@@ -185,6 +444,14 @@ func (t *Template) String() string {
 	return fmt.Sprintf("Template(%s)", t.Filename)
 }
 
+// effectiveScale 返回模板实际使用的缩放比例（非正数按原始尺寸处理）
+func effectiveScale(scale float64) float64 {
+	if scale <= 0 {
+		return 1.0
+	}
+	return scale
+}
+
 func scaleTemplate(image gocv.Mat, scale float64) (gocv.Mat, func()) {
 	if scale <= 0 {
 		return image, nil
@@ -198,6 +465,18 @@ func scaleTemplate(image gocv.Mat, scale float64) (gocv.Mat, func()) {
 	return scaled, func() { scaled.Close() }
 }
 
+// scaleMask 按模板缩放比例缩放掩码（最近邻插值，保持二值）
+func scaleMask(mask gocv.Mat, scale float64) (gocv.Mat, func()) {
+	if scale <= 0 || scale == 1.0 {
+		return mask, nil
+	}
+	newW := max(1, int(float64(mask.Cols())*scale))
+	newH := max(1, int(float64(mask.Rows())*scale))
+	scaled := gocv.NewMat()
+	gocv.Resize(mask, &scaled, stdimage.Point{X: newW, Y: newH}, 0, 0, gocv.InterpolationNearestNeighbor)
+	return scaled, func() { scaled.Close() }
+}
+
 // FindLocation 便捷函数：在源图像中查找模板位置
 func FindLocation(screen, template interface{}, opts ...TemplateOption) (*Point, error) {
 	// 加载源图像
@@ -212,6 +491,7 @@ func FindLocation(screen, template interface{}, opts ...TemplateOption) (*Point,
 	switch v := template.(type) {
 	case string:
 		tmpl = NewTemplate(v, opts...)
+		defer tmpl.Close()
 	case *Template:
 		tmpl = v
 	default:
@@ -235,6 +515,7 @@ func FindAllLocations(screen, template interface{}, opts ...TemplateOption) ([]*
 	switch v := template.(type) {
 	case string:
 		tmpl = NewTemplate(v, opts...)
+		defer tmpl.Close()
 	case *Template:
 		tmpl = v
 	default:
@@ -247,6 +528,7 @@ func FindAllLocations(screen, template interface{}, opts ...TemplateOption) ([]*
 // MatchLoop 循环匹配直到找到或超时
 func MatchLoop(screenshotFn func() (gocv.Mat, error), template string, timeout time.Duration, opts ...TemplateOption) (*Point, error) {
 	tmpl := NewTemplate(template, opts...)
+	defer tmpl.Close()
 	startTime := time.Now()
 
 	for {
@@ -266,7 +548,7 @@ func MatchLoop(screenshotFn func() (gocv.Mat, error), template string, timeout t
 		}
 
 		if time.Since(startTime) > timeout {
-			return nil, fmt.Errorf("匹配超时")
+			return nil, ErrMatchTimeout
 		}
 
 		// 短暂休眠避免 CPU 占用过高
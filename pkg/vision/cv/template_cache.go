@@ -0,0 +1,225 @@
+package cv
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"gocv.io/x/gocv"
+)
+
+// DefaultTemplateCacheSize 跨任务模板缓存的默认容量（解码后的模板数量）
+const DefaultTemplateCacheSize = 32
+
+// templates 全局模板缓存（按来源 LRU 淘汰）
+var templates = newTemplateCache(DefaultTemplateCacheSize, loadTemplate)
+
+// SetTemplateCacheSize 设置跨任务模板缓存容量（0 表示禁用，每个 Template 独立解码）
+func SetTemplateCacheSize(size int) {
+	templates.resize(size)
+}
+
+// templateCache 解码后模板 Mat 的 LRU 缓存
+// 条目按引用计数管理：被淘汰时若仍有引用，等最后一个引用释放后再 Close
+type templateCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List // 队首为最近使用
+	entries  map[string]*list.Element
+	load     func(source string, gray bool) (templateImage, error)
+}
+
+// templateImage 解码后的模板图像及其 alpha 掩码
+type templateImage struct {
+	mat     gocv.Mat
+	mask    gocv.Mat
+	hasMask bool
+}
+
+// close 释放模板图像和掩码
+func (img templateImage) close() {
+	img.mat.Close()
+	if img.hasMask {
+		img.mask.Close()
+	}
+}
+
+// templateEntry 缓存条目
+type templateEntry struct {
+	key     string
+	img     templateImage
+	refs    int
+	evicted bool
+}
+
+// templateRef 对缓存模板的引用，Release 后不得再使用 Mat
+type templateRef struct {
+	cache *templateCache
+	entry *templateEntry
+	once  sync.Once
+}
+
+func newTemplateCache(capacity int, load func(source string, gray bool) (templateImage, error)) *templateCache {
+	return &templateCache{
+		capacity: max(0, capacity),
+		ll:       list.New(),
+		entries:  make(map[string]*list.Element),
+		load:     load,
+	}
+}
+
+// Mat 返回共享的模板图像（只读，调用方不得 Close 或修改）
+func (r *templateRef) Mat() gocv.Mat {
+	return r.entry.img.mat
+}
+
+// Mask 返回模板 alpha 通道生成的掩码（只读），模板没有透明像素时 ok 为 false
+func (r *templateRef) Mask() (mask gocv.Mat, ok bool) {
+	return r.entry.img.mask, r.entry.img.hasMask
+}
+
+// Release 释放引用（可重复调用）
+func (r *templateRef) Release() {
+	r.once.Do(func() {
+		r.cache.release(r.entry)
+	})
+}
+
+// acquire 获取模板引用，未命中时解码并加入缓存
+func (c *templateCache) acquire(source string, gray bool) (*templateRef, error) {
+	key := templateKey(source, gray)
+
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		c.ll.MoveToFront(el)
+		entry := el.Value.(*templateEntry)
+		entry.refs++
+		c.mu.Unlock()
+		return &templateRef{cache: c, entry: entry}, nil
+	}
+	c.mu.Unlock()
+
+	// 解码在锁外进行，避免阻塞其他模板的获取
+	img, err := c.load(source, gray)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 并发解码了同一模板：使用先入缓存的版本
+	if el, ok := c.entries[key]; ok {
+		img.close()
+		c.ll.MoveToFront(el)
+		entry := el.Value.(*templateEntry)
+		entry.refs++
+		return &templateRef{cache: c, entry: entry}, nil
+	}
+
+	entry := &templateEntry{key: key, img: img, refs: 1}
+	if c.capacity == 0 {
+		entry.evicted = true
+		return &templateRef{cache: c, entry: entry}, nil
+	}
+	c.entries[key] = c.ll.PushFront(entry)
+	c.evictLocked()
+	return &templateRef{cache: c, entry: entry}, nil
+}
+
+// release 减少引用计数，已淘汰且无引用时释放 Mat
+func (c *templateCache) release(entry *templateEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.refs--
+	if entry.refs <= 0 && entry.evicted {
+		entry.img.close()
+	}
+}
+
+// resize 调整容量并淘汰多余条目
+func (c *templateCache) resize(capacity int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.capacity = max(0, capacity)
+	c.evictLocked()
+}
+
+// evictLocked 淘汰超出容量的最久未使用条目（调用方持有锁）
+func (c *templateCache) evictLocked() {
+	for c.ll.Len() > c.capacity {
+		el := c.ll.Back()
+		entry := el.Value.(*templateEntry)
+		c.ll.Remove(el)
+		delete(c.entries, entry.key)
+		entry.evicted = true
+		if entry.refs <= 0 {
+			entry.img.close()
+		}
+	}
+}
+
+// len 返回缓存条目数
+func (c *templateCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// templateKey 计算模板缓存 key
+// base64 按内容哈希；文件按路径 + 修改时间 + 大小（文件被覆盖后自动失效）
+func templateKey(source string, gray bool) string {
+	var key string
+	switch {
+	case isBase64Source(source):
+		sum := sha256.Sum256([]byte(source))
+		key = "b64:" + hex.EncodeToString(sum[:])
+	default:
+		key = "file:" + source
+		if info, err := os.Stat(source); err == nil {
+			key += fmt.Sprintf("@%d:%d", info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	if gray {
+		key += "|gray"
+	}
+	return key
+}
+
+// isBase64Source 是否为 base64 模板（data URL 或纯 base64 字符串）
+func isBase64Source(source string) bool {
+	return strings.HasPrefix(source, "data:image/") ||
+		(len(source) > 100 && !strings.ContainsAny(source, "/\\"))
+}
+
+// loadTemplate 读取模板图像，PNG 模板带透明像素时同时生成 alpha 掩码
+func loadTemplate(source string, gray bool) (templateImage, error) {
+	mat, err := loadTemplateMat(source, gray)
+	if err != nil {
+		return templateImage{}, err
+	}
+	mask, ok, err := loadTemplateMask(source)
+	if err != nil {
+		mat.Close()
+		return templateImage{}, err
+	}
+	return templateImage{mat: mat, mask: mask, hasMask: ok}, nil
+}
+
+// loadTemplateMat 读取模板图像（文件或 base64），gray 时转为灰度图
+func loadTemplateMat(source string, gray bool) (gocv.Mat, error) {
+	mat, err := ReadImage(source)
+	if err != nil {
+		return gocv.Mat{}, err
+	}
+	if gray {
+		grayMat := ToGray(mat)
+		mat.Close()
+		return grayMat, nil
+	}
+	return mat, nil
+}
@@ -0,0 +1,166 @@
+package cv
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+// countingLoader 返回记录解码次数的加载函数
+func countingLoader(count *atomic.Int32) func(string, bool) (templateImage, error) {
+	return func(string, bool) (templateImage, error) {
+		count.Add(1)
+		return templateImage{mat: gocv.NewMat()}, nil
+	}
+}
+
+func TestTemplateCache_Hit(t *testing.T) {
+	var loads atomic.Int32
+	c := newTemplateCache(4, countingLoader(&loads))
+
+	a, err := c.acquire("data:image/png;base64,AAAA", false)
+	if err != nil {
+		t.Fatalf("acquire 失败: %v", err)
+	}
+	b, _ := c.acquire("data:image/png;base64,AAAA", false)
+	if a.entry != b.entry {
+		t.Error("相同来源应共享同一缓存条目")
+	}
+	if loads.Load() != 1 {
+		t.Errorf("应只解码 1 次, 实际 %d 次", loads.Load())
+	}
+
+	// 灰度与彩色分开缓存
+	g, _ := c.acquire("data:image/png;base64,AAAA", true)
+	if g.entry == a.entry || loads.Load() != 2 {
+		t.Error("灰度模板应单独缓存")
+	}
+
+	a.Release()
+	a.Release() // 重复释放不应重复减计数
+	if b.entry.refs != 1 {
+		t.Errorf("refs = %d, 期望 1", b.entry.refs)
+	}
+	b.Release()
+	g.Release()
+}
+
+func TestTemplateCache_EvictWhileReferenced(t *testing.T) {
+	var loads atomic.Int32
+	c := newTemplateCache(1, countingLoader(&loads))
+
+	a, _ := c.acquire("a.png", false)
+	b, _ := c.acquire("b.png", false)
+	if c.len() != 1 {
+		t.Fatalf("缓存条目数 = %d, 期望 1", c.len())
+	}
+	if !a.entry.evicted || a.entry.refs != 1 {
+		t.Errorf("a 应被淘汰但保留引用: evicted=%v refs=%d", a.entry.evicted, a.entry.refs)
+	}
+	a.Release()
+	if a.entry.refs != 0 {
+		t.Errorf("释放后 refs = %d, 期望 0", a.entry.refs)
+	}
+
+	// 被淘汰的模板再次使用时重新解码
+	a2, _ := c.acquire("a.png", false)
+	if loads.Load() != 3 {
+		t.Errorf("解码次数 = %d, 期望 3", loads.Load())
+	}
+	a2.Release()
+	b.Release()
+
+	// 容量为 0 时不缓存
+	c.resize(0)
+	if c.len() != 0 {
+		t.Errorf("禁用后缓存条目数 = %d, 期望 0", c.len())
+	}
+}
+
+func TestTemplateCache_Concurrent(t *testing.T) {
+	var loads atomic.Int32
+	c := newTemplateCache(2, countingLoader(&loads))
+	sources := []string{"a.png", "b.png", "c.png"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ref, err := c.acquire(sources[(i+j)%len(sources)], false)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				ref.Release()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for el := c.ll.Front(); el != nil; el = el.Next() {
+		if refs := el.Value.(*templateEntry).refs; refs != 0 {
+			t.Errorf("全部释放后 refs = %d", refs)
+		}
+	}
+}
+
+func TestTemplateKey(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "t.png")
+	if err := os.WriteFile(path, []byte("v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	k1 := templateKey(path, false)
+	if err := os.WriteFile(path, []byte("v2-longer"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if templateKey(path, false) == k1 {
+		t.Error("文件内容变化后 key 应变化")
+	}
+
+	if templateKey("data:image/png;base64,AAAA", false) == templateKey("data:image/png;base64,BBBB", false) {
+		t.Error("不同 base64 内容 key 不应相同")
+	}
+	if templateKey("data:image/png;base64,AAAA", false) == templateKey("data:image/png;base64,AAAA", true) {
+		t.Error("灰度与彩色 key 不应相同")
+	}
+}
+
+// BenchmarkTemplateLoad 对比每轮重新解码 base64 模板与使用缓存的单轮耗时
+func BenchmarkTemplateLoad(b *testing.B) {
+	data, err := os.ReadFile(filepath.Join("testdata", "template1.png"))
+	if err != nil {
+		b.Skipf("读取测试模板失败: %v", err)
+	}
+	source := "data:image/png;base64," + base64.StdEncoding.EncodeToString(data)
+
+	b.Run("decode", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			mat, err := loadTemplateMat(source, false)
+			if err != nil {
+				b.Fatal(err)
+			}
+			mat.Close()
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		c := newTemplateCache(DefaultTemplateCacheSize, loadTemplate)
+		for i := 0; i < b.N; i++ {
+			ref, err := c.acquire(source, false)
+			if err != nil {
+				b.Fatal(err)
+			}
+			ref.Release()
+		}
+	})
+}
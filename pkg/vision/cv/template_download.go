@@ -0,0 +1,174 @@
+package cv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// URL 模板下载配置
+var (
+	// TemplateDownloadTimeout 下载 URL 模板的超时时间
+	TemplateDownloadTimeout = 30 * time.Second
+	// MaxTemplateDownloadSize 下载 URL 模板的最大字节数
+	MaxTemplateDownloadSize int64 = 10 << 20
+)
+
+var (
+	templateCacheDirMu sync.RWMutex
+	templateCacheDir   string
+
+	// downloadMu 串行化下载，避免并发任务重复下载同一模板
+	downloadMu sync.Mutex
+)
+
+// templateMeta 本地缓存模板的校验信息
+type templateMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// SetTemplateCacheDir 设置 URL 模板的本地缓存目录（默认 ~/.zoey-worker/cache/templates）
+func SetTemplateCacheDir(dir string) {
+	templateCacheDirMu.Lock()
+	defer templateCacheDirMu.Unlock()
+	templateCacheDir = dir
+}
+
+// TemplateCacheDir 返回 URL 模板的本地缓存目录
+func TemplateCacheDir() (string, error) {
+	templateCacheDirMu.RLock()
+	dir := templateCacheDir
+	templateCacheDirMu.RUnlock()
+	if dir != "" {
+		return dir, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户目录失败: %w", err)
+	}
+	return filepath.Join(homeDir, ".zoey-worker", "cache", "templates"), nil
+}
+
+// isURLSource 是否为 http(s) 模板地址
+func isURLSource(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// fetchTemplate 下载 URL 模板到本地缓存并返回文件路径
+// 已缓存时携带 If-None-Match / If-Modified-Since 重新验证，304 直接复用本地文件；
+// 网络不可用但本地已有缓存时使用缓存。
+func fetchTemplate(url string) (string, error) {
+	dir, err := TemplateCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrTemplateDownload, err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("%w: 创建缓存目录失败: %v", ErrTemplateDownload, err)
+	}
+
+	sum := sha256.Sum256([]byte(url))
+	name := hex.EncodeToString(sum[:])
+	imagePath := filepath.Join(dir, name+".img")
+	metaPath := filepath.Join(dir, name+".json")
+
+	downloadMu.Lock()
+	defer downloadMu.Unlock()
+
+	var meta templateMeta
+	cached := false
+	if _, err := os.Stat(imagePath); err == nil {
+		if data, err := os.ReadFile(metaPath); err == nil && json.Unmarshal(data, &meta) == nil && meta.URL == url {
+			cached = true
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: 无效的模板地址: %v", ErrTemplateDownload, err)
+	}
+	if cached {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+
+	client := &http.Client{Timeout: TemplateDownloadTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		if cached {
+			return imagePath, nil
+		}
+		return "", fmt.Errorf("%w: %s: %v", ErrTemplateDownload, url, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		return imagePath, nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return "", fmt.Errorf("%w: HTTP %d: %s", ErrTemplateNotFound, resp.StatusCode, url)
+	case resp.StatusCode != http.StatusOK:
+		if cached && resp.StatusCode >= 500 {
+			return imagePath, nil
+		}
+		return "", fmt.Errorf("%w: HTTP %d: %s", ErrTemplateDownload, resp.StatusCode, url)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxTemplateDownloadSize+1))
+	if err != nil {
+		return "", fmt.Errorf("%w: 读取响应失败: %v", ErrTemplateDownload, err)
+	}
+	if int64(len(data)) > MaxTemplateDownloadSize {
+		return "", fmt.Errorf("%w: 模板超过大小上限 %d 字节: %s", ErrTemplateDownload, MaxTemplateDownloadSize, url)
+	}
+
+	if err := writeFileAtomic(imagePath, data); err != nil {
+		return "", fmt.Errorf("%w: 写入缓存失败: %v", ErrTemplateDownload, err)
+	}
+	meta = templateMeta{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if data, err := json.Marshal(meta); err == nil {
+		// 元信息写入失败只影响下次重新验证，不影响本次使用
+		_ = writeFileAtomic(metaPath, data)
+	}
+	return imagePath, nil
+}
+
+// writeFileAtomic 先写临时文件再重命名，避免并发读取到不完整的文件
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package cv
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFetchTemplate_ETagRevalidation(t *testing.T) {
+	SetTemplateCacheDir(t.TempDir())
+	defer SetTemplateCacheDir("")
+
+	var downloads, revalidations atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("png-bytes"))
+	}))
+	defer server.Close()
+
+	path, err := fetchTemplate(server.URL + "/button.png")
+	if err != nil {
+		t.Fatalf("首次下载失败: %v", err)
+	}
+	info, _ := os.Stat(path)
+
+	path2, err := fetchTemplate(server.URL + "/button.png")
+	if err != nil {
+		t.Fatalf("重新验证失败: %v", err)
+	}
+	if path2 != path {
+		t.Errorf("缓存路径不一致: %s vs %s", path, path2)
+	}
+	if info2, _ := os.Stat(path); !info2.ModTime().Equal(info.ModTime()) {
+		t.Error("304 时不应重写缓存文件")
+	}
+	if downloads.Load() != 1 || revalidations.Load() != 1 {
+		t.Errorf("下载 %d 次、重新验证 %d 次, 期望各 1 次", downloads.Load(), revalidations.Load())
+	}
+	if data, _ := os.ReadFile(path); string(data) != "png-bytes" {
+		t.Errorf("缓存内容 = %q", data)
+	}
+}
+
+func TestFetchTemplate_Errors(t *testing.T) {
+	SetTemplateCacheDir(t.TempDir())
+	defer SetTemplateCacheDir("")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing.png":
+			http.NotFound(w, r)
+		case "/large.png":
+			w.Write([]byte(strings.Repeat("x", 64)))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	if _, err := fetchTemplate(server.URL + "/missing.png"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("404 应返回 ErrTemplateNotFound, 实际 %v", err)
+	}
+	if _, err := fetchTemplate(server.URL + "/broken.png"); !errors.Is(err, ErrTemplateDownload) {
+		t.Errorf("500 应返回 ErrTemplateDownload, 实际 %v", err)
+	}
+
+	original := MaxTemplateDownloadSize
+	MaxTemplateDownloadSize = 16
+	defer func() { MaxTemplateDownloadSize = original }()
+	if _, err := fetchTemplate(server.URL + "/large.png"); !errors.Is(err, ErrTemplateDownload) {
+		t.Errorf("超过大小上限应返回 ErrTemplateDownload, 实际 %v", err)
+	}
+
+	server.Close()
+	if _, err := fetchTemplate(server.URL + "/offline.png"); !errors.Is(err, ErrTemplateDownload) || errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("网络错误应返回 ErrTemplateDownload, 实际 %v", err)
+	}
+}
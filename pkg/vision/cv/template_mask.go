@@ -0,0 +1,93 @@
+package cv
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"strings"
+
+	"gocv.io/x/gocv"
+)
+
+// pngSignature PNG 文件头
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// loadTemplateMask 读取 PNG 模板的 alpha 通道作为匹配掩码
+// 非 PNG 或完全不透明的模板返回 ok=false
+func loadTemplateMask(source string) (mask gocv.Mat, ok bool, err error) {
+	data, err := templateBytes(source)
+	if err != nil {
+		// 模板图像已能解码，掩码只是增强：读取原始字节失败时按无掩码处理
+		return gocv.Mat{}, false, nil
+	}
+	alpha, ok := pngAlphaMask(data)
+	if !ok {
+		return gocv.Mat{}, false, nil
+	}
+
+	b := alpha.Bounds()
+	mat, err := gocv.NewMatFromBytes(b.Dy(), b.Dx(), gocv.MatTypeCV8UC1, alpha.Pix)
+	if err != nil {
+		return gocv.Mat{}, false, fmt.Errorf("创建模板掩码失败: %w", err)
+	}
+	// NewMatFromBytes 引用 Go 内存，复制一份由 OpenCV 管理
+	mask = mat.Clone()
+	mat.Close()
+	return mask, true, nil
+}
+
+// templateBytes 读取模板的原始字节（文件或 base64）
+func templateBytes(source string) ([]byte, error) {
+	if !isBase64Source(source) {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("无法读取图像: %w", err)
+		}
+		return data, nil
+	}
+	if idx := strings.Index(source, ","); strings.HasPrefix(source, "data:image/") && idx >= 0 {
+		source = source[idx+1:]
+	}
+	data, err := base64.StdEncoding.DecodeString(source)
+	if err != nil {
+		return nil, fmt.Errorf("base64 解码失败: %w", err)
+	}
+	return data, nil
+}
+
+// pngAlphaMask 解码 PNG 并生成二值掩码（透明像素为 0，其余为 255）
+// 非 PNG、解码失败或没有透明像素时返回 ok=false
+func pngAlphaMask(data []byte) (*image.Alpha, bool) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, false
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, false
+	}
+	return alphaMask(img)
+}
+
+// alphaMask 由图像 alpha 通道生成二值掩码，没有透明像素时返回 ok=false
+func alphaMask(img image.Image) (*image.Alpha, bool) {
+	b := img.Bounds()
+	mask := image.NewAlpha(image.Rect(0, 0, b.Dx(), b.Dy()))
+	transparent := false
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			_, _, _, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			if a == 0 {
+				transparent = true
+				continue
+			}
+			mask.Pix[y*mask.Stride+x] = 0xff
+		}
+	}
+	if !transparent {
+		return nil, false
+	}
+	return mask, true
+}
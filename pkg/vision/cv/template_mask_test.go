@@ -0,0 +1,92 @@
+package cv
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// cornerTemplate 生成 size×size 的模板，四个角 corner×corner 区域完全透明
+func cornerTemplate(size, corner int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			inX := x < corner || x >= size-corner
+			inY := y < corner || y >= size-corner
+			if inX && inY {
+				continue
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: 200, G: uint8(x * 10), B: uint8(y * 10), A: 0xff})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("编码 PNG 失败: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestPNGAlphaMask_TransparentCorners(t *testing.T) {
+	const size, corner = 12, 3
+	mask, ok := pngAlphaMask(encodePNG(t, cornerTemplate(size, corner)))
+	if !ok {
+		t.Fatal("含透明角的模板应生成掩码")
+	}
+	if mask.Bounds().Dx() != size || mask.Bounds().Dy() != size {
+		t.Fatalf("掩码尺寸 = %v, 期望 %dx%d", mask.Bounds(), size, size)
+	}
+
+	for _, p := range []image.Point{{0, 0}, {size - 1, 0}, {0, size - 1}, {size - 1, size - 1}, {corner - 1, corner - 1}} {
+		if v := mask.AlphaAt(p.X, p.Y).A; v != 0 {
+			t.Errorf("透明角 %v 掩码 = %d, 期望 0", p, v)
+		}
+	}
+	for _, p := range []image.Point{{size / 2, size / 2}, {corner, 0}, {0, corner}, {size - 1, size / 2}} {
+		if v := mask.AlphaAt(p.X, p.Y).A; v != 0xff {
+			t.Errorf("不透明像素 %v 掩码 = %d, 期望 255", p, v)
+		}
+	}
+}
+
+func TestPNGAlphaMask_NoMask(t *testing.T) {
+	// 完全不透明的 PNG 不需要掩码
+	opaque := cornerTemplate(8, 0)
+	if _, ok := pngAlphaMask(encodePNG(t, opaque)); ok {
+		t.Error("不透明模板不应生成掩码")
+	}
+
+	// 非 PNG 数据不处理
+	if _, ok := pngAlphaMask([]byte("GIF89a...")); ok {
+		t.Error("非 PNG 数据不应生成掩码")
+	}
+}
+
+func TestTemplateBytes(t *testing.T) {
+	data := encodePNG(t, cornerTemplate(6, 2))
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	path := filepath.Join(t.TempDir(), "t.png")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, source := range []string{path, "data:image/png;base64," + encoded} {
+		got, err := templateBytes(source)
+		if err != nil {
+			t.Fatalf("templateBytes(%.30s) 失败: %v", source, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("templateBytes(%.30s) 内容不一致", source)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package cv
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+	"sort"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+// 多目标匹配默认参数
+const (
+	// DefaultOverlapThreshold 非极大值抑制的默认 IoU 阈值，重叠超过该值的候选视为同一目标
+	DefaultOverlapThreshold = 0.3
+	// DefaultMaxResults 多目标匹配默认返回的最大结果数
+	DefaultMaxResults = 10
+
+	// equalMatchTolerance 置信度差值在此范围内的候选视为并列
+	equalMatchTolerance = 0.01
+)
+
+// TemplateMatching 基于 matchTemplate 的模板匹配
+// 设置掩码后只比较掩码非零的像素（用于忽略模板的透明区域）；不处理缩放和旋转
+type TemplateMatching struct {
+	imSearch  gocv.Mat
+	imSource  gocv.Mat
+	mask      gocv.Mat
+	hasMask   bool
+	threshold float64
+	overlap   float64
+	maxCount  int
+
+	// pyramidMinWidth 源图像宽度超过该值时先在缩小图上粗匹配（< 0 禁用）
+	pyramidMinWidth int
+}
+
+// NewTemplateMatching 创建模板匹配器
+func NewTemplateMatching(search, source gocv.Mat, threshold float64) *TemplateMatching {
+	return &TemplateMatching{
+		imSearch:  search,
+		imSource:  source,
+		threshold: threshold,
+		overlap:   DefaultOverlapThreshold,
+		maxCount:  DefaultMaxResults,
+
+		pyramidMinWidth: DefaultPyramidMinWidth,
+	}
+}
+
+// NewMaskedTemplateMatching 创建带掩码的模板匹配器，mask 为与模板同尺寸的单通道图像
+func NewMaskedTemplateMatching(search, source, mask gocv.Mat, threshold float64) *TemplateMatching {
+	m := NewTemplateMatching(search, source, threshold)
+	m.mask = mask
+	m.hasMask = true
+	return m
+}
+
+// SetNMS 设置多目标匹配的非极大值抑制 IoU 阈值和最大结果数（maxCount <= 0 表示不限制）
+func (m *TemplateMatching) SetNMS(overlap float64, maxCount int) {
+	m.overlap = overlap
+	m.maxCount = maxCount
+}
+
+// FindBestResult 查找最佳匹配结果
+func (m *TemplateMatching) FindBestResult() (*MatchResult, error) {
+	return m.findBest(context.Background(), true)
+}
+
+// FindBestResultCtx 查找最佳匹配结果，ctx 取消时在下一个候选区域匹配前返回 ctx.Err()
+func (m *TemplateMatching) FindBestResultCtx(ctx context.Context) (*MatchResult, error) {
+	return m.findBest(ctx, true)
+}
+
+// FindBestCandidate 查找最佳候选结果（忽略置信度阈值，用于失败诊断）
+func (m *TemplateMatching) FindBestCandidate() (*MatchResult, error) {
+	return m.findBest(context.Background(), false)
+}
+
+// FindAllResults 查找所有达到阈值的匹配
+// 重叠的候选经非极大值抑制只保留置信度最高的一个，结果按置信度降序排列
+func (m *TemplateMatching) FindAllResults() ([]*MatchResult, error) {
+	return m.FindAllResultsCtx(context.Background())
+}
+
+// FindAllResultsCtx 查找所有达到阈值的匹配，支持通过 ctx 取消
+func (m *TemplateMatching) FindAllResultsCtx(ctx context.Context) ([]*MatchResult, error) {
+	startTime := time.Now()
+
+	if ok, err := m.check(); err != nil || !ok {
+		return nil, err
+	}
+	limit := m.maxCount
+	if limit <= 0 {
+		limit = pyramidMaxCandidates
+	}
+	regions, err := m.searchRegions(m.threshold, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	w, h := m.imSearch.Cols(), m.imSearch.Rows()
+	var boxes []image.Rectangle
+	var scores []float64
+	for _, roi := range regions {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result, err := m.correlate(roi)
+		if err != nil {
+			return nil, err
+		}
+		for y := 0; y < result.Rows(); y++ {
+			for x := 0; x < result.Cols(); x++ {
+				score := float64(result.GetFloatAt(y, x))
+				if math.IsNaN(score) || math.IsInf(score, 0) || score < m.threshold {
+					continue
+				}
+				px, py := roi.Min.X+x, roi.Min.Y+y
+				boxes = append(boxes, image.Rect(px, py, px+w, py+h))
+				scores = append(scores, math.Min(1, score))
+			}
+		}
+		result.Close()
+	}
+
+	elapsed := float64(time.Since(startTime).Milliseconds())
+	keep := nonMaxSuppression(boxes, scores, m.overlap, m.maxCount)
+	results := make([]*MatchResult, len(keep))
+	for i, idx := range keep {
+		results[i] = rectResult(boxes[idx], scores[idx])
+		results[i].Time = elapsed
+	}
+	return results, nil
+}
+
+// findBest 查找最佳匹配，applyThreshold 为 false 时不做置信度校验
+func (m *TemplateMatching) findBest(ctx context.Context, applyThreshold bool) (*MatchResult, error) {
+	startTime := time.Now()
+
+	if ok, err := m.check(); err != nil || !ok {
+		return nil, err
+	}
+	threshold := m.threshold
+	if !applyThreshold {
+		threshold = math.Inf(-1)
+	}
+	regions, err := m.searchRegions(threshold, pyramidBestCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	confidence := math.Inf(-1)
+	var loc image.Point
+	for _, roi := range regions {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result, err := m.correlate(roi)
+		if err != nil {
+			return nil, err
+		}
+		_, maxVal, _, maxLoc := gocv.MinMaxLoc(result)
+		result.Close()
+
+		// 掩码区域为纯色时相关系数无定义（NaN/Inf）
+		score := float64(maxVal)
+		if math.IsNaN(score) || math.IsInf(score, 0) {
+			continue
+		}
+		if score > confidence {
+			confidence = score
+			loc = roi.Min.Add(maxLoc)
+		}
+	}
+	if math.IsInf(confidence, -1) {
+		return nil, nil
+	}
+	confidence = math.Min(1, math.Max(0, confidence))
+	if applyThreshold && confidence < m.threshold {
+		return nil, nil
+	}
+
+	w, h := m.imSearch.Cols(), m.imSearch.Rows()
+	match := rectResult(image.Rect(loc.X, loc.Y, loc.X+w, loc.Y+h), confidence)
+	match.Time = float64(time.Since(startTime).Milliseconds())
+	return match, nil
+}
+
+// check 检查输入图像，模板大于源图像时 ok 为 false
+func (m *TemplateMatching) check() (ok bool, err error) {
+	if m.imSearch.Empty() || m.imSource.Empty() {
+		return false, fmt.Errorf("图像为空")
+	}
+	if m.imSearch.Cols() > m.imSource.Cols() || m.imSearch.Rows() > m.imSource.Rows() {
+		return false, nil
+	}
+	return true, nil
+}
+
+// correlate 计算模板在源图像 roi 区域内的相关系数图
+// 结果坐标相对于 roi 左上角，调用方负责 Close
+func (m *TemplateMatching) correlate(roi image.Rectangle) (gocv.Mat, error) {
+	source := m.imSource
+	if roi != image.Rect(0, 0, m.imSource.Cols(), m.imSource.Rows()) {
+		source = m.imSource.Region(roi)
+		defer source.Close()
+	}
+	return correlate(source, m.imSearch, m.mask, m.hasMask)
+}
+
+// correlate 计算 search 在 source 上的归一化相关系数图，hasMask 时只比较掩码非零像素
+func correlate(source, search, mask gocv.Mat, hasMask bool) (gocv.Mat, error) {
+	if !hasMask {
+		mask = gocv.NewMat()
+		defer mask.Close()
+	}
+	result := gocv.NewMat()
+	if err := gocv.MatchTemplate(source, search, &result, gocv.TmCcoeffNormed, mask); err != nil {
+		result.Close()
+		return gocv.Mat{}, fmt.Errorf("模板匹配失败: %w", err)
+	}
+	return result, nil
+}
+
+// rectResult 由矩形区域构造匹配结果
+func rectResult(r image.Rectangle, confidence float64) *MatchResult {
+	return &MatchResult{
+		Result: Point{X: (r.Min.X + r.Max.X) / 2, Y: (r.Min.Y + r.Max.Y) / 2},
+		Rectangle: Rectangle{
+			TopLeft:     Point{X: r.Min.X, Y: r.Min.Y},
+			BottomLeft:  Point{X: r.Min.X, Y: r.Max.Y},
+			BottomRight: Point{X: r.Max.X, Y: r.Max.Y},
+			TopRight:    Point{X: r.Max.X, Y: r.Min.Y},
+		},
+		Confidence: confidence,
+		Scale:      1.0,
+	}
+}
+
+// nonMaxSuppression 贪心非极大值抑制
+// 按置信度降序（并列时保持原顺序）依次保留与已保留框 IoU 不超过 overlap 的框，
+// 返回保留框的下标；maxCount > 0 时最多保留 maxCount 个
+func nonMaxSuppression(boxes []image.Rectangle, scores []float64, overlap float64, maxCount int) []int {
+	order := make([]int, len(boxes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+
+	var keep []int
+	for _, idx := range order {
+		if maxCount > 0 && len(keep) >= maxCount {
+			break
+		}
+		suppressed := false
+		for _, k := range keep {
+			if iou(boxes[idx], boxes[k]) > overlap {
+				suppressed = true
+				break
+			}
+		}
+		if !suppressed {
+			keep = append(keep, idx)
+		}
+	}
+	return keep
+}
+
+// iou 计算两个矩形的交并比
+func iou(a, b image.Rectangle) float64 {
+	inter := a.Intersect(b)
+	if inter.Empty() {
+		return 0
+	}
+	interArea := float64(inter.Dx() * inter.Dy())
+	union := float64(a.Dx()*a.Dy()+b.Dx()*b.Dy()) - interArea
+	if union <= 0 {
+		return 0
+	}
+	return interArea / union
+}
+
+// selectMatch 按策略从按置信度降序排列的结果中选出唯一结果
+// 置信度与最高者相差不超过 equalMatchTolerance 的候选视为并列
+func selectMatch(results []*MatchResult, policy MatchPolicy) (*MatchResult, error) {
+	if len(results) == 0 {
+		return nil, nil
+	}
+	tied := 1
+	for tied < len(results) && results[0].Confidence-results[tied].Confidence <= equalMatchTolerance {
+		tied++
+	}
+
+	switch policy {
+	case MatchPolicyTopLeft:
+		best := results[0]
+		for _, r := range results[1:tied] {
+			tl, bestTL := r.Rectangle.TopLeft, best.Rectangle.TopLeft
+			if tl.Y < bestTL.Y || (tl.Y == bestTL.Y && tl.X < bestTL.X) {
+				best = r
+			}
+		}
+		return best, nil
+	case MatchPolicyError:
+		if tied > 1 {
+			return nil, fmt.Errorf("%w: %d 个候选置信度相同 (%.3f)", ErrMultipleMatches, tied, results[0].Confidence)
+		}
+		return results[0], nil
+	default:
+		return results[0], nil
+	}
+}
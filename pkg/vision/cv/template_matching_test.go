@@ -0,0 +1,237 @@
+package cv
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 合成测试图：背景上放置 4 个相同模板
+const (
+	synthTemplateSize = 12
+	synthImageSize    = 120
+)
+
+// synthCopies 4 个模板副本的左上角坐标
+var synthCopies = []image.Point{{10, 10}, {70, 10}, {10, 80}, {90, 95}}
+
+// synthTemplate 生成带纹理的模板
+func synthTemplate() *image.Gray {
+	tmpl := image.NewGray(image.Rect(0, 0, synthTemplateSize, synthTemplateSize))
+	for y := 0; y < synthTemplateSize; y++ {
+		for x := 0; x < synthTemplateSize; x++ {
+			tmpl.SetGray(x, y, color.Gray{Y: uint8((x*37 + y*91) % 256)})
+		}
+	}
+	return tmpl
+}
+
+// synthScreen 生成含 4 个模板副本的屏幕图
+func synthScreen(tmpl *image.Gray) *image.Gray {
+	screen := image.NewGray(image.Rect(0, 0, synthImageSize, synthImageSize))
+	for i := range screen.Pix {
+		screen.Pix[i] = 128
+	}
+	for _, p := range synthCopies {
+		for y := 0; y < synthTemplateSize; y++ {
+			for x := 0; x < synthTemplateSize; x++ {
+				screen.SetGray(p.X+x, p.Y+y, tmpl.GrayAt(x, y))
+			}
+		}
+	}
+	return screen
+}
+
+// sadCandidates 以归一化绝对差计算每个位置的相似度，返回达到阈值的候选框
+// （纯 Go 实现，模拟 matchTemplate 在模板副本附近产生的大量重叠候选）
+func sadCandidates(screen, tmpl *image.Gray, threshold float64) ([]image.Rectangle, []float64) {
+	var boxes []image.Rectangle
+	var scores []float64
+	w, h := tmpl.Bounds().Dx(), tmpl.Bounds().Dy()
+	for sy := 0; sy+h <= screen.Bounds().Dy(); sy++ {
+		for sx := 0; sx+w <= screen.Bounds().Dx(); sx++ {
+			diff := 0
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					d := int(screen.GrayAt(sx+x, sy+y).Y) - int(tmpl.GrayAt(x, y).Y)
+					if d < 0 {
+						d = -d
+					}
+					diff += d
+				}
+			}
+			score := 1 - float64(diff)/float64(w*h*255)
+			if score >= threshold {
+				boxes = append(boxes, image.Rect(sx, sy, sx+w, sy+h))
+				scores = append(scores, score)
+			}
+		}
+	}
+	return boxes, scores
+}
+
+func TestNonMaxSuppression_FourCopies(t *testing.T) {
+	tmpl := synthTemplate()
+	boxes, scores := sadCandidates(synthScreen(tmpl), tmpl, 0.9)
+	if len(boxes) <= len(synthCopies) {
+		t.Fatalf("候选数 = %d, 期望存在重叠候选", len(boxes))
+	}
+
+	keep := nonMaxSuppression(boxes, scores, DefaultOverlapThreshold, DefaultMaxResults)
+	if len(keep) != len(synthCopies) {
+		t.Fatalf("NMS 后结果数 = %d, 期望 %d", len(keep), len(synthCopies))
+	}
+
+	found := make(map[image.Point]bool)
+	for i, idx := range keep {
+		found[boxes[idx].Min] = true
+		if scores[idx] != 1 {
+			t.Errorf("结果 %d 置信度 = %.3f, 期望 1", i, scores[idx])
+		}
+		if i > 0 && scores[keep[i-1]] < scores[idx] {
+			t.Error("结果应按置信度降序排列")
+		}
+	}
+	for _, p := range synthCopies {
+		if !found[p] {
+			t.Errorf("未找到位于 %v 的副本", p)
+		}
+	}
+
+	// 限制结果数
+	if keep := nonMaxSuppression(boxes, scores, DefaultOverlapThreshold, 2); len(keep) != 2 {
+		t.Errorf("maxCount=2 时结果数 = %d", len(keep))
+	}
+}
+
+func TestTemplateMatching_FindAllResults(t *testing.T) {
+	tmpl := synthTemplate()
+	screenMat, err := ImageToMat(synthScreen(tmpl))
+	if err != nil || screenMat.Empty() {
+		t.Skip("需要 OpenCV")
+	}
+	defer screenMat.Close()
+	tmplMat, err := ImageToMat(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tmplMat.Close()
+
+	results, err := NewTemplateMatching(tmplMat, screenMat, 0.9).FindAllResults()
+	if err != nil {
+		t.Fatalf("FindAllResults 失败: %v", err)
+	}
+	if len(results) != len(synthCopies) {
+		t.Fatalf("结果数 = %d, 期望 %d", len(results), len(synthCopies))
+	}
+	found := make(map[image.Point]bool)
+	for _, r := range results {
+		found[image.Pt(r.Rectangle.TopLeft.X, r.Rectangle.TopLeft.Y)] = true
+	}
+	for _, p := range synthCopies {
+		if !found[p] {
+			t.Errorf("未找到位于 %v 的副本", p)
+		}
+	}
+}
+
+func TestIoU(t *testing.T) {
+	a := image.Rect(0, 0, 10, 10)
+	if got := iou(a, a); got != 1 {
+		t.Errorf("相同矩形 IoU = %v, 期望 1", got)
+	}
+	if got := iou(a, image.Rect(20, 20, 30, 30)); got != 0 {
+		t.Errorf("不相交 IoU = %v, 期望 0", got)
+	}
+	// 交集 50，并集 150
+	if got := iou(a, image.Rect(5, 0, 15, 10)); got < 0.333 || got > 0.334 {
+		t.Errorf("半重叠 IoU = %v, 期望 1/3", got)
+	}
+}
+
+func TestSelectMatch(t *testing.T) {
+	at := func(x, y int, confidence float64) *MatchResult {
+		return rectResult(image.Rect(x, y, x+10, y+10), confidence)
+	}
+	// 已按置信度降序：前三个并列
+	results := []*MatchResult{at(50, 40, 0.99), at(80, 10, 0.985), at(10, 10, 0.982), at(0, 0, 0.9)}
+
+	if got, _ := selectMatch(results, MatchPolicyFirst); got != results[0] {
+		t.Errorf("first: 应返回置信度最高者")
+	}
+	if got, _ := selectMatch(results, MatchPolicyTopLeft); got != results[2] {
+		t.Errorf("topleft: 返回 %+v, 期望 (10,10)", got.Rectangle.TopLeft)
+	}
+	if _, err := selectMatch(results, MatchPolicyError); !errors.Is(err, ErrMultipleMatches) {
+		t.Errorf("error: 并列时应返回 ErrMultipleMatches, 实际 %v", err)
+	}
+
+	// 没有并列时 error 策略正常返回
+	single := []*MatchResult{at(0, 0, 0.99), at(50, 50, 0.8)}
+	if got, err := selectMatch(single, MatchPolicyError); err != nil || got != single[0] {
+		t.Errorf("error: 无并列时应返回最佳结果, got=%v err=%v", got, err)
+	}
+	if got, err := selectMatch(nil, MatchPolicyError); got != nil || err != nil {
+		t.Errorf("空结果应返回 nil, got=%v err=%v", got, err)
+	}
+}
+
+func TestParseMatchPolicy(t *testing.T) {
+	for name, want := range map[string]MatchPolicy{
+		"":        MatchPolicyFirst,
+		"first":   MatchPolicyFirst,
+		"TopLeft": MatchPolicyTopLeft,
+		"error":   MatchPolicyError,
+	} {
+		if got, err := ParseMatchPolicy(name); err != nil || got != want {
+			t.Errorf("ParseMatchPolicy(%q) = %q, %v; 期望 %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseMatchPolicy("random"); err == nil {
+		t.Error("未知策略应返回错误")
+	}
+}
+
+func TestTemplate_MatchScale(t *testing.T) {
+	// 模板按 2 倍录制，屏幕上是原始尺寸：应在 0.5 倍候选命中
+	small := synthTemplate()
+	large := image.NewGray(image.Rect(0, 0, synthTemplateSize*2, synthTemplateSize*2))
+	for y := 0; y < synthTemplateSize*2; y++ {
+		for x := 0; x < synthTemplateSize*2; x++ {
+			large.SetGray(x, y, small.GrayAt(x/2, y/2))
+		}
+	}
+	path := filepath.Join(t.TempDir(), "large.png")
+	if err := os.WriteFile(path, encodePNG(t, large), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	screenMat, err := ImageToMat(synthScreen(small))
+	if err != nil || screenMat.Empty() {
+		t.Skip("需要 OpenCV")
+	}
+	defer screenMat.Close()
+
+	tmpl := NewTemplate(path,
+		WithTemplateMethods(MatchMethodTemplate),
+		WithTemplateScales(0.5, 1.0),
+		WithTemplateThreshold(0.9),
+	)
+	defer tmpl.Close()
+	result, err := tmpl.MatchResultIn(screenMat)
+	if err != nil || result == nil {
+		t.Fatalf("匹配失败: result=%v err=%v", result, err)
+	}
+	if result.Scale != 0.5 {
+		t.Errorf("Scale = %v, 期望 0.5", result.Scale)
+	}
+}
+
+func TestRectResult_Scale(t *testing.T) {
+	if got := rectResult(image.Rect(0, 0, 10, 10), 0.9).Scale; got != 1.0 {
+		t.Errorf("单尺度匹配 Scale = %v, 期望 1.0", got)
+	}
+}
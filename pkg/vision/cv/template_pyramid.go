@@ -0,0 +1,118 @@
+package cv
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"gocv.io/x/gocv"
+)
+
+// DefaultPyramidMinWidth 源图像宽度超过该值时默认启用粗到细匹配（4K、2.5K、Retina 截图）
+const DefaultPyramidMinWidth = 2048
+
+// 粗到细匹配参数
+const (
+	// pyramidMinTemplateSize 缩小后模板短边的最小像素数，过小时缩小图上无法可靠匹配
+	pyramidMinTemplateSize = 12
+	// pyramidThresholdMargin 粗匹配阈值相对匹配阈值的下调量（缩小会损失细节，分数偏低）
+	pyramidThresholdMargin = 0.2
+	// pyramidBestCandidates 单结果匹配时在原图精匹配的候选区域数
+	pyramidBestCandidates = 3
+	// pyramidMaxCandidates 不限制结果数时的最大候选区域数
+	pyramidMaxCandidates = 50
+)
+
+// pyramidScales 缩小比例候选，优先使用更小的比例
+var pyramidScales = []float64{0.25, 0.5}
+
+// SetPyramidMinWidth 设置启用粗到细匹配的源图像宽度阈值（0 表示总是启用，< 0 禁用）
+func (m *TemplateMatching) SetPyramidMinWidth(width int) {
+	m.pyramidMinWidth = width
+}
+
+// pyramidScale 返回粗匹配的缩小比例，不适用时返回 0
+func (m *TemplateMatching) pyramidScale() float64 {
+	return choosePyramidScale(m.imSource.Cols(), m.imSearch.Cols(), m.imSearch.Rows(), m.pyramidMinWidth)
+}
+
+// choosePyramidScale 根据源图像宽度和模板尺寸选择缩小比例
+// 源图像不够宽、已禁用或模板缩小后过小时返回 0
+func choosePyramidScale(sourceWidth, templateWidth, templateHeight, minWidth int) float64 {
+	if minWidth < 0 || sourceWidth <= minWidth {
+		return 0
+	}
+	short := min(templateWidth, templateHeight)
+	for _, scale := range pyramidScales {
+		if float64(short)*scale >= pyramidMinTemplateSize {
+			return scale
+		}
+	}
+	return 0
+}
+
+// searchRegions 返回需要在原图上精匹配的区域
+// 未启用粗到细匹配时返回整张源图像；启用时先在缩小图上找出最多 limit 个分数不低于
+// threshold - pyramidThresholdMargin 的候选，再换算回原图并向外扩展容差，没有候选时返回空
+func (m *TemplateMatching) searchRegions(threshold float64, limit int) ([]image.Rectangle, error) {
+	bounds := image.Rect(0, 0, m.imSource.Cols(), m.imSource.Rows())
+	scale := m.pyramidScale()
+	if scale == 0 {
+		return []image.Rectangle{bounds}, nil
+	}
+
+	smallSource := resizeArea(m.imSource, scale)
+	defer smallSource.Close()
+	smallSearch := resizeArea(m.imSearch, scale)
+	defer smallSearch.Close()
+	var smallMask gocv.Mat
+	if m.hasMask {
+		// 掩码与缩小后的模板尺寸必须一致
+		smallMask = gocv.NewMat()
+		defer smallMask.Close()
+		gocv.Resize(m.mask, &smallMask, image.Point{X: smallSearch.Cols(), Y: smallSearch.Rows()}, 0, 0, gocv.InterpolationNearestNeighbor)
+	}
+	if smallSearch.Cols() > smallSource.Cols() || smallSearch.Rows() > smallSource.Rows() {
+		return []image.Rectangle{bounds}, nil
+	}
+
+	result, err := correlate(smallSource, smallSearch, smallMask, m.hasMask)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	// 缩小图上 1 像素对应原图 1/scale 像素，另加取整误差
+	pad := int(math.Ceil(2/scale)) + 2
+	w, h := m.imSearch.Cols(), m.imSearch.Rows()
+	sw, sh := smallSearch.Cols(), smallSearch.Rows()
+	coarseThreshold := threshold - pyramidThresholdMargin
+
+	var regions []image.Rectangle
+	for len(regions) < limit {
+		_, maxVal, _, maxLoc := gocv.MinMaxLoc(result)
+		score := float64(maxVal)
+		if math.IsNaN(score) || math.IsInf(score, 0) || score < coarseThreshold {
+			break
+		}
+		x := int(math.Round(float64(maxLoc.X) / scale))
+		y := int(math.Round(float64(maxLoc.Y) / scale))
+		regions = append(regions, image.Rect(x-pad, y-pad, x+w+pad, y+h+pad).Intersect(bounds))
+
+		// 抑制该候选附近的分数，继续寻找下一个候选
+		suppress := image.Rect(maxLoc.X-sw/2, maxLoc.Y-sh/2, maxLoc.X+sw/2+1, maxLoc.Y+sh/2+1)
+		gocv.Rectangle(&result, suppress, color.RGBA{}, -1)
+	}
+	return regions, nil
+}
+
+// resizeArea 按比例缩小图像（区域插值，缩小时抗锯齿）
+func resizeArea(img gocv.Mat, scale float64) gocv.Mat {
+	dst := gocv.NewMat()
+	size := image.Point{
+		X: max(1, int(math.Round(float64(img.Cols())*scale))),
+		Y: max(1, int(math.Round(float64(img.Rows())*scale))),
+	}
+	gocv.Resize(img, &dst, size, 0, 0, gocv.InterpolationArea)
+	return dst
+}
@@ -0,0 +1,106 @@
+package cv
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+func TestChoosePyramidScale(t *testing.T) {
+	tests := []struct {
+		name                  string
+		source, tw, th, limit int
+		want                  float64
+	}{
+		{"4K 大模板", 3840, 200, 80, DefaultPyramidMinWidth, 0.25},
+		{"4K 小模板降级为 0.5", 3840, 40, 30, DefaultPyramidMinWidth, 0.5},
+		{"模板过小不缩小", 3840, 20, 16, DefaultPyramidMinWidth, 0},
+		{"1080p 默认不启用", 1920, 200, 80, DefaultPyramidMinWidth, 0},
+		{"强制启用", 1920, 200, 80, 0, 0.25},
+		{"禁用", 3840, 200, 80, -1, 0},
+	}
+	for _, tt := range tests {
+		if got := choosePyramidScale(tt.source, tt.tw, tt.th, tt.limit); got != tt.want {
+			t.Errorf("%s: scale = %v, 期望 %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// loadPyramidTestdata 读取测试截图和模板（需要 OpenCV）
+func loadPyramidTestdata(tb testing.TB) (gocv.Mat, []gocv.Mat) {
+	tb.Helper()
+	screen, err := ReadImage(filepath.Join("testdata", "target.png"))
+	if err != nil {
+		tb.Skipf("读取测试截图失败（需要 OpenCV）: %v", err)
+	}
+	var templates []gocv.Mat
+	for _, name := range []string{"template1.png", "template2.png", "template3.png"} {
+		tmpl, err := ReadImage(filepath.Join("testdata", name))
+		if err != nil {
+			tb.Fatalf("读取模板 %s 失败: %v", name, err)
+		}
+		templates = append(templates, tmpl)
+	}
+	return screen, templates
+}
+
+// TestTemplateMatching_PyramidSameResult 粗到细匹配与整图匹配的结果（坐标和置信度）应一致
+func TestTemplateMatching_PyramidSameResult(t *testing.T) {
+	screen, templates := loadPyramidTestdata(t)
+	defer screen.Close()
+	for i, tmpl := range templates {
+		defer tmpl.Close()
+
+		full := NewTemplateMatching(tmpl, screen, 0.8)
+		full.SetPyramidMinWidth(-1)
+		want, err := full.FindBestCandidate()
+		if err != nil || want == nil {
+			t.Fatalf("模板 %d 整图匹配失败: %v", i+1, err)
+		}
+
+		pyramid := NewTemplateMatching(tmpl, screen, 0.8)
+		pyramid.SetPyramidMinWidth(0)
+		got, err := pyramid.FindBestCandidate()
+		if err != nil || got == nil {
+			t.Fatalf("模板 %d 粗到细匹配失败: %v", i+1, err)
+		}
+
+		if got.Rectangle != want.Rectangle || math.Abs(got.Confidence-want.Confidence) > 1e-4 {
+			t.Errorf("模板 %d: 粗到细 %+v (%.4f), 整图 %+v (%.4f)",
+				i+1, got.Rectangle, got.Confidence, want.Rectangle, want.Confidence)
+		}
+	}
+}
+
+// BenchmarkTemplateMatching 对比整图匹配与粗到细匹配的单次耗时
+func BenchmarkTemplateMatching(b *testing.B) {
+	screen, templates := loadPyramidTestdata(b)
+	defer screen.Close()
+	defer func() {
+		for _, tmpl := range templates {
+			tmpl.Close()
+		}
+	}()
+
+	for _, bc := range []struct {
+		name     string
+		minWidth int
+	}{
+		{"full", -1},
+		{"pyramid", 0},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, tmpl := range templates {
+					m := NewTemplateMatching(tmpl, screen, 0.8)
+					m.SetPyramidMinWidth(bc.minWidth)
+					if _, err := m.FindBestResult(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,11 @@
 // Package cv 提供图像匹配功能
 package cv
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Point 表示二维坐标点
 type Point struct {
 	X int `json:"x"`
@@ -25,12 +30,49 @@ type MatchResult struct {
 	Confidence float64 `json:"confidence"`
 	// Time 匹配耗时（毫秒）
 	Time float64 `json:"time,omitempty"`
+	// Scale 命中时模板的缩放比例（多尺度匹配的候选倍率，单尺度匹配为 1.0）
+	Scale float64 `json:"scale"`
 }
 
 // MatchMethod 匹配方法枚举
-// 仅保留 SIFT 算法
 type MatchMethod string
 
 const (
-	MatchMethodSIFT MatchMethod = "sift" // SIFT 特征点匹配（更稳但更慢）
+	MatchMethodSIFT     MatchMethod = "sift" // SIFT 特征点匹配（更稳但更慢）
+	MatchMethodORB      MatchMethod = "orb"  // ORB 特征点匹配（二进制描述子，更快）
+	MatchMethodTemplate MatchMethod = "tpl"  // matchTemplate 像素匹配（不处理旋转，模板含透明像素时带掩码）
 )
+
+// DefaultMatchMethods 默认匹配方法链
+var DefaultMatchMethods = []MatchMethod{MatchMethodSIFT}
+
+// ParseMatchMethod 解析匹配方法名称（不区分大小写）
+func ParseMatchMethod(name string) (MatchMethod, error) {
+	switch m := MatchMethod(strings.ToLower(strings.TrimSpace(name))); m {
+	case MatchMethodSIFT, MatchMethodORB, MatchMethodTemplate:
+		return m, nil
+	default:
+		return "", fmt.Errorf("不支持的匹配方法: %s", name)
+	}
+}
+
+// MatchPolicy 多个候选置信度并列时的选择策略
+type MatchPolicy string
+
+const (
+	MatchPolicyFirst   MatchPolicy = "first"   // 取置信度最高者（并列时取先找到的，默认）
+	MatchPolicyTopLeft MatchPolicy = "topleft" // 并列时取最上方、其次最左侧的候选
+	MatchPolicyError   MatchPolicy = "error"   // 并列时返回 ErrMultipleMatches
+)
+
+// ParseMatchPolicy 解析选择策略名称（不区分大小写，空字符串为 first）
+func ParseMatchPolicy(name string) (MatchPolicy, error) {
+	switch p := MatchPolicy(strings.ToLower(strings.TrimSpace(name))); p {
+	case "":
+		return MatchPolicyFirst, nil
+	case MatchPolicyFirst, MatchPolicyTopLeft, MatchPolicyError:
+		return p, nil
+	default:
+		return "", fmt.Errorf("不支持的匹配策略: %s", name)
+	}
+}
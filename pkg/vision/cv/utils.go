@@ -38,7 +38,9 @@ func ReadImage(filename string) (gocv.Mat, error) {
 	// 作为文件路径读取
 	mat := gocv.IMRead(filename, gocv.IMReadColor)
 	if mat.Empty() {
-		return mat, fmt.Errorf("无法读取图像: %s", filename)
+		// 读取失败时 IMRead 仍会分配一个空 Mat
+		mat.Close()
+		return gocv.Mat{}, fmt.Errorf("无法读取图像: %s", filename)
 	}
 	return mat, nil
 }
@@ -71,7 +73,8 @@ func readBase64Image(dataURL string) (gocv.Mat, error) {
 func ReadImageGray(filename string) (gocv.Mat, error) {
 	mat := gocv.IMRead(filename, gocv.IMReadGrayScale)
 	if mat.Empty() {
-		return mat, fmt.Errorf("无法读取图像: %s", filename)
+		mat.Close()
+		return gocv.Mat{}, fmt.Errorf("无法读取图像: %s", filename)
 	}
 	return mat, nil
 }
@@ -0,0 +1,186 @@
+package ocr
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+
+	"gocv.io/x/gocv"
+	xdraw "golang.org/x/image/draw"
+
+	"github.com/zoeyai/zoeyworker/internal/logger"
+)
+
+// 方向分类参数（与 PaddleOCR ch_ppocr_mobile_v2.0_cls 一致）
+const (
+	// clsImageWidth / clsImageHeight 分类模型输入尺寸
+	clsImageWidth  = 192
+	clsImageHeight = 48
+	// clsThreshold 判定为 180° 的最低概率
+	clsThreshold = 0.9
+	// verticalRatio 裁剪区域高宽比不小于该值时视为旋转 90° 的文字行
+	verticalRatio = 1.5
+)
+
+// orientationClassifier 文字行方向分类器
+type orientationClassifier interface {
+	// upsideDown 判断水平文字行是否倒置（180°）
+	upsideDown(img image.Image) (bool, error)
+	Close()
+}
+
+// angleClassifier 基于 PaddleOCR cls 模型（ONNX，经 OpenCV DNN 推理）的方向分类器
+type angleClassifier struct {
+	net gocv.Net
+}
+
+// newAngleClassifier 加载方向分类模型
+func newAngleClassifier(modelPath string) (*angleClassifier, error) {
+	net := gocv.ReadNetFromONNX(modelPath)
+	if net.Empty() {
+		return nil, fmt.Errorf("加载方向分类模型失败: %s", modelPath)
+	}
+	return &angleClassifier{net: net}, nil
+}
+
+// upsideDown 按 PaddleOCR 的预处理（等比缩放到高 48、右侧补齐到宽 192、归一化到 [-1, 1]）推理，
+// 输出为 [0°, 180°] 两类的概率
+func (c *angleClassifier) upsideDown(img image.Image) (bool, error) {
+	mat, err := gocv.ImageToMatRGB(clsInput(img))
+	if err != nil {
+		return false, fmt.Errorf("转换分类输入失败: %w", err)
+	}
+	defer mat.Close()
+
+	blob := gocv.BlobFromImage(mat, 1/127.5, image.Pt(clsImageWidth, clsImageHeight),
+		gocv.NewScalar(127.5, 127.5, 127.5, 0), false, false)
+	defer blob.Close()
+
+	c.net.SetInput(blob, "")
+	prob := c.net.Forward("")
+	defer prob.Close()
+	if prob.Empty() || prob.Total() < 2 {
+		return false, fmt.Errorf("方向分类输出无效")
+	}
+	return prob.GetFloatAt(0, 1) > clsThreshold, nil
+}
+
+// Close 释放模型
+func (c *angleClassifier) Close() {
+	c.net.Close()
+}
+
+// clsInput 等比缩放到分类模型输入高度，宽度不足的部分以中性灰补齐（归一化后为 0）
+func clsInput(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	w := clsImageWidth
+	if b.Dy() > 0 {
+		w = min(clsImageWidth, max(1, b.Dx()*clsImageHeight/b.Dy()))
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, clsImageWidth, clsImageHeight))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.RGBA{R: 128, G: 128, B: 128, A: 0xff}), image.Point{}, draw.Src)
+	xdraw.BiLinear.Scale(dst, image.Rect(0, 0, w, clsImageHeight), img, b, xdraw.Src, nil)
+	return dst
+}
+
+// correctOrientation 对旋转 90° 或倒置的文字行转正后重新识别，识别置信度更高时替换原结果
+// 调用方需持有 r.mu（重新识别复用同一个引擎）
+func (r *TextRecognizer) correctOrientation(img image.Image, results []OcrResult, run func(image.Image) ([]OcrResult, error)) []OcrResult {
+	for i, result := range results {
+		crop := cropBox(img, result.Box)
+		if crop == nil {
+			continue
+		}
+
+		rotated := false
+		if b := crop.Bounds(); float64(b.Dy()) >= verticalRatio*float64(b.Dx()) {
+			crop = rotate90(crop)
+			rotated = true
+		}
+		flip, err := r.classifier.upsideDown(crop)
+		if err != nil {
+			logger.Debug("方向分类失败: %v", err)
+			continue
+		}
+		if flip {
+			crop = rotate180(crop)
+			rotated = true
+		}
+		if !rotated {
+			continue
+		}
+
+		lines, err := run(crop)
+		if err != nil || len(lines) == 0 {
+			continue
+		}
+		text, confidence := joinLines(lines)
+		if text != "" && confidence > result.Confidence {
+			logger.Debug("文字行方向校正: %q (%.2f) -> %q (%.2f)", result.Text, result.Confidence, text, confidence)
+			results[i].Text = text
+			results[i].Confidence = confidence
+		}
+	}
+	return results
+}
+
+// joinLines 合并重新识别得到的多行文字，置信度取平均值
+func joinLines(lines []OcrResult) (string, float64) {
+	var texts []string
+	var sum float64
+	for _, line := range lines {
+		if line.Text == "" {
+			continue
+		}
+		texts = append(texts, line.Text)
+		sum += line.Confidence
+	}
+	if len(texts) == 0 {
+		return "", 0
+	}
+	return strings.Join(texts, ""), sum / float64(len(texts))
+}
+
+// cropBox 裁剪文字框的外接矩形（原点移到 (0,0)），框为空或在图像外时返回 nil
+func cropBox(img image.Image, box []Point) *image.RGBA {
+	if len(box) == 0 {
+		return nil
+	}
+	rect := image.Rect(box[0].X, box[0].Y, box[0].X, box[0].Y)
+	for _, p := range box[1:] {
+		rect = rect.Union(image.Rect(p.X, p.Y, p.X+1, p.Y+1))
+	}
+	rect = rect.Intersect(img.Bounds())
+	if rect.Empty() {
+		return nil
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, rect.Min, draw.Src)
+	return dst
+}
+
+// rotate90 逆时针旋转 90°（与 PaddleOCR 对竖直文字框的处理一致）
+func rotate90(src *image.RGBA) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			dst.SetRGBA(y, b.Dx()-1-x, src.RGBAAt(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// rotate180 旋转 180°
+func rotate180(src *image.RGBA) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			dst.SetRGBA(b.Dx()-1-x, b.Dy()-1-y, src.RGBAAt(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,129 @@
+package ocr
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"testing"
+)
+
+// fakeClassifier 固定返回是否倒置的方向分类器
+type fakeClassifier struct {
+	flip  bool
+	calls int
+}
+
+func (c *fakeClassifier) upsideDown(image.Image) (bool, error) {
+	c.calls++
+	return c.flip, nil
+}
+
+func (c *fakeClassifier) Close() {}
+
+// boxOf 构造与 convertResult 一致的四角点
+func boxOf(x1, y1, x2, y2 int) []Point {
+	return []Point{{X: x1, Y: y1}, {X: x1, Y: y2}, {X: x2, Y: y2}, {X: x2, Y: y1}}
+}
+
+func TestRotate(t *testing.T) {
+	// 3x2 图像，左上角红色
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	red := color.RGBA{R: 0xff, A: 0xff}
+	src.SetRGBA(0, 0, red)
+
+	r90 := rotate90(src)
+	if r90.Bounds().Dx() != 2 || r90.Bounds().Dy() != 3 {
+		t.Fatalf("rotate90 尺寸 = %v, 期望 2x3", r90.Bounds())
+	}
+	// 逆时针旋转后左上角移到左下角
+	if r90.RGBAAt(0, 2) != red {
+		t.Error("rotate90: 左上角像素应移到左下角")
+	}
+
+	r180 := rotate180(src)
+	if r180.Bounds() != src.Bounds() || r180.RGBAAt(2, 1) != red {
+		t.Error("rotate180: 左上角像素应移到右下角")
+	}
+}
+
+func TestCropBox(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	crop := cropBox(img, boxOf(10, 20, 30, 80))
+	if crop == nil || crop.Bounds() != image.Rect(0, 0, 21, 61) {
+		t.Errorf("裁剪区域 = %v", crop.Bounds())
+	}
+	if cropBox(img, boxOf(200, 200, 220, 220)) != nil {
+		t.Error("图像外的框应返回 nil")
+	}
+	if cropBox(img, nil) != nil {
+		t.Error("空框应返回 nil")
+	}
+}
+
+func TestClsInput(t *testing.T) {
+	got := clsInput(image.NewRGBA(image.Rect(0, 0, 40, 20)))
+	if got.Bounds() != image.Rect(0, 0, clsImageWidth, clsImageHeight) {
+		t.Fatalf("分类输入尺寸 = %v", got.Bounds())
+	}
+	// 40x20 等比缩放为 96x48，右侧以灰色补齐
+	if c := got.RGBAAt(clsImageWidth-1, 0); c.R != 128 {
+		t.Errorf("补齐区域颜色 = %v, 期望灰色", c)
+	}
+}
+
+func TestCorrectOrientation(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	results := []OcrResult{
+		{Text: "x#~", Confidence: 0.3, Box: boxOf(10, 10, 30, 110)}, // 竖直：旋转后重新识别
+		{Text: "设置", Confidence: 0.95, Box: boxOf(50, 10, 150, 40)}, // 水平且未倒置：保持不变
+	}
+	classifier := &fakeClassifier{}
+	r := &TextRecognizer{classifier: classifier}
+
+	var runSizes []image.Rectangle
+	run := func(crop image.Image) ([]OcrResult, error) {
+		runSizes = append(runSizes, crop.Bounds())
+		return []OcrResult{{Text: "提交", Confidence: 0.9}, {Text: "订单", Confidence: 0.8}}, nil
+	}
+	got := r.correctOrientation(img, results, run)
+
+	if got[0].Text != "提交订单" || got[0].Confidence < 0.849 || got[0].Confidence > 0.851 {
+		t.Errorf("竖直文字校正结果 = %q (%.2f), 期望 '提交订单' (0.85)", got[0].Text, got[0].Confidence)
+	}
+	if got[0].Box[0] != (Point{X: 10, Y: 10}) {
+		t.Error("校正后应保留原始屏幕坐标")
+	}
+	if got[1].Text != "设置" {
+		t.Errorf("水平文字不应被修改: %q", got[1].Text)
+	}
+	// 只有旋转过的裁剪区域重新识别，且已转为横向
+	if len(runSizes) != 1 || runSizes[0].Dx() <= runSizes[0].Dy() {
+		t.Errorf("重新识别区域 = %v, 期望 1 个横向区域", runSizes)
+	}
+	if classifier.calls != 2 {
+		t.Errorf("分类次数 = %d, 期望 2", classifier.calls)
+	}
+}
+
+func TestCorrectOrientation_KeepsBetterOriginal(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	results := []OcrResult{{Text: "确定", Confidence: 0.9, Box: boxOf(10, 10, 100, 40)}}
+	r := &TextRecognizer{classifier: &fakeClassifier{flip: true}}
+
+	got := r.correctOrientation(img, results, func(image.Image) ([]OcrResult, error) {
+		return []OcrResult{{Text: "宝戦", Confidence: 0.4}}, nil
+	})
+	if got[0].Text != "确定" {
+		t.Errorf("倒置后识别置信度更低时应保留原结果, 实际 %q", got[0].Text)
+	}
+}
+
+func TestDefaultConfig_ClsOptional(t *testing.T) {
+	orig := statFile
+	defer func() { statFile = orig }()
+	statFile = func(string) (interface{}, error) { return nil, os.ErrNotExist }
+
+	if got := DefaultConfig().ClsModelPath; got != "" {
+		t.Errorf("未找到 cls 模型时 ClsModelPath = %q, 期望为空", got)
+	}
+}
@@ -0,0 +1,120 @@
+package ocr
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"image"
+	"sync"
+	"time"
+
+	"github.com/zoeyai/zoeyworker/pkg/imgutil"
+)
+
+// 识别结果缓存默认参数
+const (
+	// DefaultResultCacheTTL 缓存条目的默认有效期（画面哈希相同但超过该时间仍重新识别）
+	DefaultResultCacheTTL = 2 * time.Second
+	// resultCacheSize 每个识别器最多缓存的画面数（整屏与若干区域轮流轮询时仍能命中）
+	resultCacheSize = 4
+)
+
+// frameKeySeed 画面哈希种子（缓存只在进程内使用，无需跨进程稳定）
+var frameKeySeed = maphash.MakeSeed()
+
+// resultCache 以画面哈希为键的识别结果缓存，按写入顺序淘汰最旧的条目
+type resultCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries []cacheEntry
+	now     func() time.Time
+}
+
+// cacheEntry 缓存条目
+type cacheEntry struct {
+	key     uint64
+	results []OcrResult
+	at      time.Time
+}
+
+// newResultCache 创建识别结果缓存，ttl 为 0 使用 DefaultResultCacheTTL，< 0 返回 nil（不缓存）
+func newResultCache(ttl time.Duration) *resultCache {
+	switch {
+	case ttl < 0:
+		return nil
+	case ttl == 0:
+		ttl = DefaultResultCacheTTL
+	}
+	return &resultCache{ttl: ttl, now: time.Now}
+}
+
+// get 返回未过期的缓存结果（副本）
+func (c *resultCache) get(key uint64) ([]OcrResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for i, e := range c.entries {
+		if e.key != key {
+			continue
+		}
+		if now.Sub(e.at) > c.ttl {
+			c.entries = append(c.entries[:i], c.entries[i+1:]...)
+			return nil, false
+		}
+		return cloneResults(e.results), true
+	}
+	return nil, false
+}
+
+// put 写入识别结果，已存在的键被覆盖，超出容量时淘汰最旧的条目
+func (c *resultCache) put(key uint64, results []OcrResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, e := range c.entries {
+		if e.key == key {
+			c.entries = append(c.entries[:i], c.entries[i+1:]...)
+			break
+		}
+	}
+	if len(c.entries) >= resultCacheSize {
+		c.entries = c.entries[1:]
+	}
+	c.entries = append(c.entries, cacheEntry{key: key, results: cloneResults(results), at: c.now()})
+}
+
+// clear 清空缓存
+func (c *resultCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+// cloneResults 深拷贝识别结果，避免调用方修改缓存内容
+func cloneResults(results []OcrResult) []OcrResult {
+	cloned := make([]OcrResult, len(results))
+	for i, r := range results {
+		cloned[i] = r
+		if r.Box != nil {
+			cloned[i].Box = append([]Point(nil), r.Box...)
+		}
+	}
+	return cloned
+}
+
+// frameKey 计算画面的缓存键：图像边界（区域截图的位置与尺寸）和原始 RGBA 像素的哈希
+func frameKey(img image.Image) uint64 {
+	var h maphash.Hash
+	h.SetSeed(frameKeySeed)
+
+	bounds := img.Bounds()
+	var rect [16]byte
+	binary.LittleEndian.PutUint32(rect[0:], uint32(bounds.Min.X))
+	binary.LittleEndian.PutUint32(rect[4:], uint32(bounds.Min.Y))
+	binary.LittleEndian.PutUint32(rect[8:], uint32(bounds.Dx()))
+	binary.LittleEndian.PutUint32(rect[12:], uint32(bounds.Dy()))
+	h.Write(rect[:])
+
+	imgutil.WriteRGBA(&h, img)
+	return h.Sum64()
+}
@@ -0,0 +1,184 @@
+package ocr
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// cacheFrame 生成带渐变的测试画面
+func cacheFrame(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: uint8(x + y), A: 0xff})
+		}
+	}
+	return img
+}
+
+// countingRun 返回计数的识别函数
+func countingRun(calls *int) func(image.Image) ([]OcrResult, error) {
+	return func(image.Image) ([]OcrResult, error) {
+		*calls++
+		return []OcrResult{{Text: "确定", Confidence: 0.9, Box: []Point{{X: 1, Y: 2}}}}, nil
+	}
+}
+
+func TestRecognizeCached_ChangedFrameBustsCache(t *testing.T) {
+	r := &TextRecognizer{cache: newResultCache(0)}
+	frame := cacheFrame(64, 48)
+	calls := 0
+	run := countingRun(&calls)
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.recognizeCached(frame, run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("画面未变化时识别次数 = %d, 期望 1", calls)
+	}
+
+	// 修改一个像素后重新识别
+	frame.SetRGBA(10, 10, color.RGBA{A: 0xff})
+	if _, err := r.recognizeCached(frame, run); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("画面变化后识别次数 = %d, 期望 2", calls)
+	}
+
+	// 像素相同但区域位置不同（坐标原点不同）
+	if _, err := r.recognizeCached(frame.SubImage(image.Rect(0, 0, 32, 48)), run); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.recognizeCached(frame.SubImage(image.Rect(32, 0, 64, 48)), run); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 4 {
+		t.Errorf("不同区域识别次数 = %d, 期望 4", calls)
+	}
+}
+
+func TestRecognizeCached_ErrorNotCached(t *testing.T) {
+	r := &TextRecognizer{cache: newResultCache(0)}
+	frame := cacheFrame(16, 16)
+	calls := 0
+	failing := func(image.Image) ([]OcrResult, error) {
+		calls++
+		return nil, errors.New("engine busy")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := r.recognizeCached(frame, failing); err == nil {
+			t.Fatal("应返回识别错误")
+		}
+	}
+	if calls != 2 {
+		t.Errorf("识别失败不应缓存, 调用次数 = %d", calls)
+	}
+}
+
+func TestResultCache_TTL(t *testing.T) {
+	c := newResultCache(0)
+	now := time.Unix(1000, 0)
+	c.now = func() time.Time { return now }
+
+	c.put(1, []OcrResult{{Text: "a"}})
+	now = now.Add(DefaultResultCacheTTL)
+	if _, ok := c.get(1); !ok {
+		t.Fatal("有效期内应命中")
+	}
+	now = now.Add(time.Millisecond)
+	if _, ok := c.get(1); ok {
+		t.Error("超过有效期不应命中")
+	}
+	if len(c.entries) != 0 {
+		t.Errorf("过期条目应被移除, 剩余 %d", len(c.entries))
+	}
+}
+
+func TestResultCache_Bounded(t *testing.T) {
+	c := newResultCache(time.Minute)
+	for key := uint64(1); key <= resultCacheSize+1; key++ {
+		c.put(key, nil)
+	}
+	if len(c.entries) != resultCacheSize {
+		t.Fatalf("条目数 = %d, 期望 %d", len(c.entries), resultCacheSize)
+	}
+	if _, ok := c.get(1); ok {
+		t.Error("最旧的条目应被淘汰")
+	}
+	if _, ok := c.get(resultCacheSize + 1); !ok {
+		t.Error("最新的条目应保留")
+	}
+}
+
+func TestResultCache_ReturnsCopy(t *testing.T) {
+	c := newResultCache(0)
+	c.put(1, []OcrResult{{Text: "a", Box: []Point{{X: 1}}}})
+
+	got, _ := c.get(1)
+	got[0].Text = "b"
+	got[0].Box[0].X = 99
+
+	again, _ := c.get(1)
+	if again[0].Text != "a" || again[0].Box[0].X != 1 {
+		t.Errorf("修改返回值不应影响缓存: %+v", again[0])
+	}
+}
+
+func TestNewResultCache_Disabled(t *testing.T) {
+	if newResultCache(-1) != nil {
+		t.Error("ttl < 0 应禁用缓存")
+	}
+	if c := newResultCache(0); c == nil || c.ttl != DefaultResultCacheTTL {
+		t.Error("ttl = 0 应使用默认有效期")
+	}
+}
+
+// BenchmarkFrameKey 1080p 画面计算缓存键的开销（缓存未命中时额外付出的代价）
+func BenchmarkFrameKey(b *testing.B) {
+	frame := cacheFrame(1920, 1080)
+	b.SetBytes(int64(len(frame.Pix)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		frameKey(frame)
+	}
+}
+
+// BenchmarkRecognizePolling 模拟 wait_text 轮询未变化的屏幕：对比启用与禁用缓存时每轮的耗时
+func BenchmarkRecognizePolling(b *testing.B) {
+	img, err := loadImageFromFile(filepath.Join(getTestDataDir(), "target.png"))
+	if err != nil {
+		b.Fatalf("读取测试图片失败: %v", err)
+	}
+
+	for _, bc := range []struct {
+		name string
+		ttl  time.Duration
+	}{
+		{"cached", 0},
+		{"uncached", -1},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			config := setupOCRConfig(b)
+			config.ResultCacheTTL = bc.ttl
+			recognizer, err := NewTextRecognizer(config)
+			if err != nil {
+				b.Skipf("跳过测试：OCR 初始化失败: %v", err)
+			}
+			defer recognizer.Close()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := recognizer.Recognize(img); err != nil {
+					b.Fatalf("OCR 失败: %v", err)
+				}
+			}
+		})
+	}
+}
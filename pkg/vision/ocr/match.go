@@ -0,0 +1,211 @@
+package ocr
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MatchMode 文字匹配模式
+type MatchMode string
+
+const (
+	// MatchModeContains 默认模式：精确 > 双向包含 > 相似度（Threshold）
+	MatchModeContains MatchMode = "contains"
+	// MatchModeExact 去除首尾空白后完全相等（忽略大小写）
+	MatchModeExact MatchMode = "exact"
+	// MatchModeRegex 识别文字满足正则表达式（区分大小写，可用 (?i) 忽略）
+	MatchModeRegex MatchMode = "regex"
+	// MatchModeFuzzy 编辑距离不超过 MaxDistance（容忍 0/O、1/l 等误识别，忽略大小写）
+	MatchModeFuzzy MatchMode = "fuzzy"
+)
+
+// 文字匹配默认参数
+const (
+	// DefaultMaxDistance fuzzy 模式默认允许的最大编辑距离
+	DefaultMaxDistance = 1
+	// DefaultMinConfidence 参与匹配的识别结果的默认最低置信度
+	DefaultMinConfidence = 0.6
+)
+
+// ErrLowConfidence 目标文字只在低于置信度阈值的识别结果中出现
+var ErrLowConfidence = errors.New("ocr confidence below threshold")
+
+// LowConfidenceError 目标文字只在被置信度过滤掉的识别结果中出现，Candidate 为其中最佳的一个
+// errors.Is(err, ErrLowConfidence) 为 true
+type LowConfidenceError struct {
+	Candidate     OcrResult
+	MinConfidence float64
+}
+
+func (e *LowConfidenceError) Error() string {
+	return fmt.Sprintf("最佳候选 '%s' 识别置信度 %.2f 低于阈值 %.2f", e.Candidate.Text, e.Candidate.Confidence, e.MinConfidence)
+}
+
+// Is 支持 errors.Is(err, ErrLowConfidence)
+func (e *LowConfidenceError) Is(target error) bool {
+	return target == ErrLowConfidence
+}
+
+// ParseMatchMode 解析匹配模式名称（忽略大小写，空字符串为 contains）
+func ParseMatchMode(name string) (MatchMode, error) {
+	switch mode := MatchMode(strings.ToLower(strings.TrimSpace(name))); mode {
+	case "":
+		return MatchModeContains, nil
+	case MatchModeContains, MatchModeExact, MatchModeRegex, MatchModeFuzzy:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("不支持的文字匹配模式: %s（可用: contains, exact, regex, fuzzy）", name)
+	}
+}
+
+// TextMatch 文字匹配条件
+type TextMatch struct {
+	// Text 目标文字（regex 模式下为正则表达式）
+	Text string
+	// Mode 匹配模式（空为 contains）
+	Mode MatchMode
+	// Threshold contains 模式的相似度阈值（<= 0 使用 DefaultSimilarityThreshold）
+	Threshold float64
+	// MaxDistance fuzzy 模式允许的最大编辑距离（<= 0 使用 DefaultMaxDistance）
+	MaxDistance int
+	// MinConfidence 参与匹配的识别结果最低置信度（0 使用 DefaultMinConfidence，< 0 不过滤）
+	MinConfidence float64
+}
+
+// mode 返回匹配模式（空为 contains）
+func (m TextMatch) mode() MatchMode {
+	if m.Mode == "" {
+		return MatchModeContains
+	}
+	return m.Mode
+}
+
+// EffectiveMinConfidence 返回生效的最低置信度（不过滤时为 0）
+func (m TextMatch) EffectiveMinConfidence() float64 {
+	switch {
+	case m.MinConfidence < 0:
+		return 0
+	case m.MinConfidence == 0:
+		return DefaultMinConfidence
+	default:
+		return m.MinConfidence
+	}
+}
+
+// match 先在达到置信度阈值的结果中查找；未命中但低置信度结果中存在匹配时返回 *LowConfidenceError
+func (m TextMatch) match(results []OcrResult) (*OcrResult, string, error) {
+	accepted, rejected := FilterByConfidence(results, m.EffectiveMinConfidence())
+	match, kind, err := m.find(accepted)
+	if err != nil || match != nil || len(rejected) == 0 {
+		return match, kind, err
+	}
+	candidate, _, err := m.find(rejected)
+	if err != nil || candidate == nil {
+		return nil, "", err
+	}
+	return nil, "", &LowConfidenceError{Candidate: *candidate, MinConfidence: m.EffectiveMinConfidence()}
+}
+
+// FilterByConfidence 按识别置信度拆分结果：accepted 不低于 minConfidence，rejected 为其余（保持原顺序）
+func FilterByConfidence(results []OcrResult, minConfidence float64) (accepted, rejected []OcrResult) {
+	for _, r := range results {
+		if r.Confidence >= minConfidence {
+			accepted = append(accepted, r)
+		} else {
+			rejected = append(rejected, r)
+		}
+	}
+	return accepted, rejected
+}
+
+// find 在识别结果中查找匹配项，返回命中的结果和匹配方式描述（未命中时为 nil）
+func (m TextMatch) find(results []OcrResult) (*OcrResult, string, error) {
+	switch m.mode() {
+	case MatchModeContains:
+		return m.findContains(results)
+	case MatchModeExact:
+		target := strings.ToLower(strings.TrimSpace(m.Text))
+		for i, result := range results {
+			if strings.ToLower(strings.TrimSpace(result.Text)) == target {
+				return &results[i], "精确匹配", nil
+			}
+		}
+		return nil, "", nil
+	case MatchModeRegex:
+		re, err := regexp.Compile(m.Text)
+		if err != nil {
+			return nil, "", fmt.Errorf("无效的正则表达式 %q: %w", m.Text, err)
+		}
+		for i, result := range results {
+			if result.Text != "" && re.MatchString(result.Text) {
+				return &results[i], "正则匹配", nil
+			}
+		}
+		return nil, "", nil
+	case MatchModeFuzzy:
+		return m.findFuzzy(results)
+	default:
+		return nil, "", fmt.Errorf("不支持的文字匹配模式: %s", m.Mode)
+	}
+}
+
+// findContains 精确匹配优先，其次双向包含（较短一方至少 2 个字节），最后取相似度最高且达到阈值者
+func (m TextMatch) findContains(results []OcrResult) (*OcrResult, string, error) {
+	threshold := m.Threshold
+	if threshold <= 0 {
+		threshold = DefaultSimilarityThreshold
+	}
+	target := strings.ToLower(m.Text)
+	var bestMatch *OcrResult
+	var bestSimilarity float64
+
+	for i, result := range results {
+		text := strings.ToLower(result.Text)
+		if len(text) == 0 {
+			continue
+		}
+		if text == target {
+			return &results[i], "精确匹配", nil
+		}
+		if min(len(target), len(text)) >= 2 && (strings.Contains(text, target) || strings.Contains(target, text)) {
+			return &results[i], "包含匹配", nil
+		}
+		similarity := calculateSimilarity(target, text)
+		if similarity >= threshold && similarity > bestSimilarity {
+			bestSimilarity = similarity
+			bestMatch = &results[i]
+		}
+	}
+	if bestMatch == nil {
+		return nil, "", nil
+	}
+	return bestMatch, fmt.Sprintf("相似匹配(%.0f%%)", bestSimilarity*100), nil
+}
+
+// findFuzzy 返回编辑距离最小且不超过 MaxDistance 的结果（距离相同时取先出现者）
+func (m TextMatch) findFuzzy(results []OcrResult) (*OcrResult, string, error) {
+	maxDistance := m.MaxDistance
+	if maxDistance <= 0 {
+		maxDistance = DefaultMaxDistance
+	}
+	target := []rune(strings.ToLower(strings.TrimSpace(m.Text)))
+	var bestMatch *OcrResult
+	bestDistance := maxDistance + 1
+
+	for i, result := range results {
+		text := strings.ToLower(strings.TrimSpace(result.Text))
+		if text == "" {
+			continue
+		}
+		if distance := levenshteinDistance(target, []rune(text)); distance < bestDistance {
+			bestDistance = distance
+			bestMatch = &results[i]
+		}
+	}
+	if bestMatch == nil {
+		return nil, "", nil
+	}
+	return bestMatch, fmt.Sprintf("模糊匹配(距离 %d)", bestDistance), nil
+}
@@ -0,0 +1,126 @@
+package ocr
+
+import (
+	"errors"
+	"testing"
+)
+
+// matchResults 构造 OCR 识别结果（位置按下标递增，便于断言命中的是哪一项）
+func matchResults(texts ...string) []OcrResult {
+	results := make([]OcrResult, len(texts))
+	for i, text := range texts {
+		results[i] = OcrResult{Text: text, Position: Point{X: i * 100, Y: 10}}
+	}
+	return results
+}
+
+func TestTextMatch_Modes(t *testing.T) {
+	results := matchResults("BOOK", "0K", "Order #1234", "设置")
+
+	tests := []struct {
+		name  string
+		match TextMatch
+		want  string // 命中的识别文字，空表示未命中
+	}{
+		// contains 为默认模式：双向包含会把 "OK" 匹配到 "BOOK"
+		{"contains 默认", TextMatch{Text: "OK"}, "BOOK"},
+		{"contains 精确", TextMatch{Text: "设置", Mode: MatchModeContains}, "设置"},
+		{"exact 不匹配子串", TextMatch{Text: "OK", Mode: MatchModeExact}, ""},
+		{"exact 忽略大小写", TextMatch{Text: "order #1234", Mode: MatchModeExact}, "Order #1234"},
+		{"regex", TextMatch{Text: `^Order #\d+$`, Mode: MatchModeRegex}, "Order #1234"},
+		{"regex 区分大小写", TextMatch{Text: `^order`, Mode: MatchModeRegex}, ""},
+		{"fuzzy 0/O 误识别", TextMatch{Text: "OK", Mode: MatchModeFuzzy}, "0K"},
+		{"fuzzy 超出距离", TextMatch{Text: "Order #12", Mode: MatchModeFuzzy, MaxDistance: 1}, ""},
+		{"fuzzy 自定义距离", TextMatch{Text: "Order #12", Mode: MatchModeFuzzy, MaxDistance: 2}, "Order #1234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := tt.match.find(results)
+			if err != nil {
+				t.Fatalf("find 失败: %v", err)
+			}
+			if tt.want == "" {
+				if got != nil {
+					t.Errorf("应未命中, 实际命中 %q", got.Text)
+				}
+				return
+			}
+			if got == nil || got.Text != tt.want {
+				t.Errorf("命中 %v, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextMatch_FuzzyPrefersClosest(t *testing.T) {
+	// "Save" 与 "Sav3"（距离 1）和 "Save"（距离 0）同时存在时取距离最小者
+	results := matchResults("Sav3", "Save")
+	got, _, err := TextMatch{Text: "save", Mode: MatchModeFuzzy, MaxDistance: 2}.find(results)
+	if err != nil || got == nil || got.Position.X != 100 {
+		t.Errorf("应命中编辑距离最小的 'Save', 实际 %v, err=%v", got, err)
+	}
+}
+
+func TestTextMatch_InvalidRegex(t *testing.T) {
+	if _, _, err := (TextMatch{Text: "(", Mode: MatchModeRegex}).find(matchResults("a")); err == nil {
+		t.Error("无效正则应返回错误")
+	}
+}
+
+func TestParseMatchMode(t *testing.T) {
+	for name, want := range map[string]MatchMode{
+		"":         MatchModeContains,
+		"contains": MatchModeContains,
+		"Exact":    MatchModeExact,
+		" regex ":  MatchModeRegex,
+		"fuzzy":    MatchModeFuzzy,
+	} {
+		if got, err := ParseMatchMode(name); err != nil || got != want {
+			t.Errorf("ParseMatchMode(%q) = %q, %v; 期望 %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseMatchMode("glob"); err == nil {
+		t.Error("未知模式应返回错误")
+	}
+}
+
+func TestFilterByConfidence(t *testing.T) {
+	results := []OcrResult{{Text: "a", Confidence: 0.9}, {Text: "b", Confidence: 0.3}, {Text: "c", Confidence: 0.6}}
+	accepted, rejected := FilterByConfidence(results, 0.6)
+	if len(accepted) != 2 || accepted[0].Text != "a" || accepted[1].Text != "c" {
+		t.Errorf("accepted = %v, 期望 [a c]", accepted)
+	}
+	if len(rejected) != 1 || rejected[0].Text != "b" {
+		t.Errorf("rejected = %v, 期望 [b]", rejected)
+	}
+}
+
+func TestTextMatch_MinConfidence(t *testing.T) {
+	results := []OcrResult{
+		{Text: "Cancel", Confidence: 0.95},
+		{Text: "Submit", Confidence: 0.42, Position: Point{X: 200, Y: 10}},
+	}
+
+	// 目标只出现在低置信度结果中：返回 LowConfidenceError 并附带候选
+	got, _, err := TextMatch{Text: "Submit"}.match(results)
+	if got != nil || !errors.Is(err, ErrLowConfidence) {
+		t.Fatalf("期望 ErrLowConfidence, 实际 got=%v err=%v", got, err)
+	}
+	var lowErr *LowConfidenceError
+	if !errors.As(err, &lowErr) || lowErr.Candidate.Text != "Submit" || lowErr.MinConfidence != DefaultMinConfidence {
+		t.Errorf("候选信息错误: %+v", lowErr)
+	}
+
+	// 降低阈值或关闭过滤后正常命中
+	for _, min := range []float64{0.4, -1} {
+		got, _, err := TextMatch{Text: "Submit", MinConfidence: min}.match(results)
+		if err != nil || got == nil || got.Position.X != 200 {
+			t.Errorf("MinConfidence=%v: got=%v err=%v", min, got, err)
+		}
+	}
+
+	// 完全不存在的文字不是低置信度错误
+	if got, _, err := (TextMatch{Text: "Delete"}).match(results); got != nil || err != nil {
+		t.Errorf("不存在的文字应返回 nil, got=%v err=%v", got, err)
+	}
+}
@@ -36,7 +36,7 @@ func getProjectRoot() string {
 }
 
 // setupOCRConfig 设置 OCR 配置（用于测试）
-func setupOCRConfig(t *testing.T) Config {
+func setupOCRConfig(t testing.TB) Config {
 	root := getProjectRoot()
 
 	// 检测当前系统架构并选择正确的库
@@ -1036,4 +1036,49 @@ func TestPPOCRv5FindText(t *testing.T) {
 	}
 
 	t.Logf("\n查找结果: %d/%d 成功", found, len(searchTexts))
-}
\ No newline at end of file
+}
+
+// BenchmarkFindTextRegion 对比整屏 OCR 与限定区域 OCR 的耗时
+func BenchmarkFindTextRegion(b *testing.B) {
+	config := setupOCRConfig(b)
+	config.ResultCacheTTL = -1 // 每轮都重新识别，只比较模型推理耗时
+	ClearCache()
+	if err := InitGlobalRecognizer(config); err != nil {
+		b.Skipf("跳过测试：OCR 初始化失败: %v", err)
+	}
+	recognizer, err := GetGlobalRecognizer()
+	if err != nil {
+		b.Skipf("跳过测试：%v", err)
+	}
+
+	full, err := loadImageFromFile(filepath.Join(getTestDataDir(), "target.png"))
+	if err != nil {
+		b.Fatalf("读取测试图片失败: %v", err)
+	}
+	const target = "控制台"
+	pos, err := recognizer.FindText(full, target)
+	if err != nil || pos == nil {
+		b.Skipf("跳过测试：整屏未找到 '%s': %v", target, err)
+	}
+
+	// 以文字为中心截取 400x120 的区域（原点移到 (0,0)，与 CaptureRegion 的结果一致）
+	r := image.Rect(pos.X-200, pos.Y-60, pos.X+200, pos.Y+60).Intersect(full.Bounds())
+	region := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(region, region.Bounds(), full, r.Min, draw.Src)
+
+	for _, bc := range []struct {
+		name string
+		img  image.Image
+	}{
+		{"full", full},
+		{"region", region},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := recognizer.FindText(bc.img, target); err != nil {
+					b.Fatalf("OCR 失败: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package ocr
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	goocr "github.com/getcharzp/go-ocr"
+
+	"github.com/zoeyai/zoeyworker/internal/logger"
+)
+
+// ExecutionProvider ONNX Runtime 执行提供者
+type ExecutionProvider string
+
+const (
+	// ProviderAuto 按平台选择加速提供者（macOS CoreML、Windows DirectML、Linux CUDA），不可用时回退 CPU
+	ProviderAuto ExecutionProvider = "auto"
+	// ProviderCPU 纯 CPU 推理（默认）
+	ProviderCPU ExecutionProvider = "cpu"
+	// ProviderCUDA NVIDIA CUDA
+	ProviderCUDA ExecutionProvider = "cuda"
+	// ProviderCoreML Apple CoreML
+	ProviderCoreML ExecutionProvider = "coreml"
+	// ProviderDirectML Windows DirectML
+	ProviderDirectML ExecutionProvider = "directml"
+)
+
+// ParseExecutionProvider 解析执行提供者名称（忽略大小写，空字符串为 cpu）
+func ParseExecutionProvider(name string) (ExecutionProvider, error) {
+	switch p := ExecutionProvider(strings.ToLower(strings.TrimSpace(name))); p {
+	case "":
+		return ProviderCPU, nil
+	case ProviderAuto, ProviderCPU, ProviderCUDA, ProviderCoreML, ProviderDirectML:
+		return p, nil
+	default:
+		return "", fmt.Errorf("不支持的 OCR 执行提供者: %s（可用: auto, cpu, cuda, coreml, directml）", name)
+	}
+}
+
+// platformProvider auto 在当前平台对应的加速提供者
+func platformProvider() ExecutionProvider {
+	switch runtime.GOOS {
+	case "darwin":
+		return ProviderCoreML
+	case "windows":
+		return ProviderDirectML
+	default:
+		return ProviderCUDA
+	}
+}
+
+// 全局执行提供者状态，由 providerMu 保护
+var (
+	providerMu sync.RWMutex
+	// defaultProvider Config.ExecutionProvider 为空时使用的提供者（来自配置文件）
+	defaultProvider = ProviderCPU
+	// activeProvider 最近一次创建引擎实际使用的提供者（尚未创建时为空）
+	activeProvider ExecutionProvider
+)
+
+// SetDefaultExecutionProvider 设置默认执行提供者，只影响之后创建的识别器
+func SetDefaultExecutionProvider(name string) error {
+	p, err := ParseExecutionProvider(name)
+	if err != nil {
+		return err
+	}
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	defaultProvider = p
+	return nil
+}
+
+// ProviderStatus 执行提供者状态
+type ProviderStatus struct {
+	// Requested 配置的提供者（可能为 auto）
+	Requested ExecutionProvider `json:"requested"`
+	// Active 实际使用的提供者（识别器尚未加载时为空）
+	Active ExecutionProvider `json:"active,omitempty"`
+}
+
+// GetProviderStatus 返回默认执行提供者及最近加载的识别器实际使用的提供者
+func GetProviderStatus() ProviderStatus {
+	providerMu.RLock()
+	defer providerMu.RUnlock()
+	return ProviderStatus{Requested: defaultProvider, Active: activeProvider}
+}
+
+// executionProvider 返回生效的提供者：未指定时 UseGPU 为 cuda，否则为默认提供者；auto 解析为平台提供者
+func (c Config) executionProvider() ExecutionProvider {
+	p := c.ExecutionProvider
+	if p == "" {
+		if c.UseGPU {
+			p = ProviderCUDA
+		} else {
+			providerMu.RLock()
+			p = defaultProvider
+			providerMu.RUnlock()
+		}
+	}
+	if p == ProviderAuto {
+		p = platformProvider()
+	}
+	return p
+}
+
+// newEngine 创建 OCR 引擎（测试时替换）
+var newEngine = func(config goocr.Config) (ocrEngine, error) {
+	engine, err := goocr.NewPaddleOcrEngine(config)
+	if err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+// configureProvider 按执行提供者设置引擎参数，引擎不支持该提供者时返回错误
+// go-ocr 目前只开放 CUDA 开关，CoreML / DirectML 会回退到 CPU
+func configureProvider(config *goocr.Config, provider ExecutionProvider) error {
+	switch provider {
+	case ProviderCPU:
+		config.UseCuda = false
+	case ProviderCUDA:
+		config.UseCuda = true
+	default:
+		return fmt.Errorf("OCR 引擎暂不支持 %s", provider)
+	}
+	return nil
+}
+
+// createEngine 使用指定提供者创建引擎，提供者不支持或初始化失败时回退到 CPU，返回实际使用的提供者
+func createEngine(config goocr.Config, provider ExecutionProvider) (ocrEngine, ExecutionProvider, error) {
+	if provider != ProviderCPU {
+		accelerated := config
+		err := configureProvider(&accelerated, provider)
+		if err == nil {
+			var engine ocrEngine
+			if engine, err = newEngine(accelerated); err == nil {
+				return engine, provider, nil
+			}
+		}
+		logger.Warn("OCR 执行提供者 %s 不可用，回退到 CPU: %v", provider, err)
+	}
+
+	config.UseCuda = false
+	engine, err := newEngine(config)
+	if err != nil {
+		return nil, "", err
+	}
+	return engine, ProviderCPU, nil
+}
+
+// setActiveProvider 记录最近创建的引擎实际使用的提供者
+func setActiveProvider(p ExecutionProvider) {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	activeProvider = p
+}
@@ -0,0 +1,105 @@
+package ocr
+
+import (
+	"errors"
+	"testing"
+
+	goocr "github.com/getcharzp/go-ocr"
+)
+
+// stubEngine 替换引擎创建函数，记录每次创建时是否启用 CUDA
+func stubEngine(t *testing.T, fail func(goocr.Config) bool) *[]bool {
+	t.Helper()
+	var attempts []bool
+	orig := newEngine
+	t.Cleanup(func() { newEngine = orig })
+	newEngine = func(config goocr.Config) (ocrEngine, error) {
+		attempts = append(attempts, config.UseCuda)
+		if fail(config) {
+			return nil, errors.New("provider unavailable")
+		}
+		return &fakeEngine{}, nil
+	}
+	return &attempts
+}
+
+func TestParseExecutionProvider(t *testing.T) {
+	for name, want := range map[string]ExecutionProvider{
+		"":         ProviderCPU,
+		"cpu":      ProviderCPU,
+		"Auto":     ProviderAuto,
+		" cuda ":   ProviderCUDA,
+		"CoreML":   ProviderCoreML,
+		"directml": ProviderDirectML,
+	} {
+		if got, err := ParseExecutionProvider(name); err != nil || got != want {
+			t.Errorf("ParseExecutionProvider(%q) = %q, %v; 期望 %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseExecutionProvider("tpu"); err == nil {
+		t.Error("未知提供者应返回错误")
+	}
+}
+
+func TestNewTextRecognizer_FallsBackToCPU(t *testing.T) {
+	attempts := stubEngine(t, func(c goocr.Config) bool { return c.UseCuda })
+
+	r, err := NewTextRecognizer(Config{ExecutionProvider: ProviderCUDA})
+	if err != nil {
+		t.Fatalf("回退 CPU 后应创建成功: %v", err)
+	}
+	defer r.Close()
+
+	if r.Provider() != ProviderCPU {
+		t.Errorf("Provider = %q, 期望 cpu", r.Provider())
+	}
+	if len(*attempts) != 2 || !(*attempts)[0] || (*attempts)[1] {
+		t.Errorf("创建尝试 = %v, 期望先 CUDA 后 CPU", *attempts)
+	}
+	if got := GetProviderStatus().Active; got != ProviderCPU {
+		t.Errorf("Active = %q, 期望 cpu", got)
+	}
+}
+
+func TestNewTextRecognizer_Providers(t *testing.T) {
+	attempts := stubEngine(t, func(goocr.Config) bool { return false })
+
+	r, err := NewTextRecognizer(Config{ExecutionProvider: ProviderCUDA})
+	if err != nil || r.Provider() != ProviderCUDA {
+		t.Fatalf("CUDA 可用时 Provider = %v, err = %v", r, err)
+	}
+	r.Close()
+
+	// 引擎不支持的提供者不尝试创建，直接使用 CPU
+	*attempts = nil
+	r, err = NewTextRecognizer(Config{ExecutionProvider: ProviderDirectML})
+	if err != nil || r.Provider() != ProviderCPU || len(*attempts) != 1 {
+		t.Fatalf("directml: Provider = %q, 尝试 %v, err = %v", r.Provider(), *attempts, err)
+	}
+	r.Close()
+
+	// UseGPU 兼容旧配置
+	if got := (Config{UseGPU: true}).executionProvider(); got != ProviderCUDA {
+		t.Errorf("UseGPU 时提供者 = %q, 期望 cuda", got)
+	}
+}
+
+func TestSetDefaultExecutionProvider(t *testing.T) {
+	t.Cleanup(func() { _ = SetDefaultExecutionProvider("") })
+
+	if err := SetDefaultExecutionProvider("auto"); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetProviderStatus().Requested; got != ProviderAuto {
+		t.Errorf("Requested = %q, 期望 auto", got)
+	}
+	if got := (Config{}).executionProvider(); got != platformProvider() {
+		t.Errorf("auto 解析为 %q, 期望 %q", got, platformProvider())
+	}
+	if err := SetDefaultExecutionProvider("gpu"); err == nil {
+		t.Error("无效名称应返回错误")
+	}
+	if got := GetProviderStatus().Requested; got != ProviderAuto {
+		t.Errorf("无效名称不应修改默认值, 实际 %q", got)
+	}
+}
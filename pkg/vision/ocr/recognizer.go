@@ -1,6 +1,7 @@
 package ocr
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"strings"
@@ -11,23 +12,41 @@ import (
 	goocr "github.com/getcharzp/go-ocr"
 
 	"github.com/zoeyai/zoeyworker/internal/logger"
+	"github.com/zoeyai/zoeyworker/pkg/metrics"
 )
 
 // 默认文字匹配相似度阈值
 const DefaultSimilarityThreshold = 0.8
 
+// zoey_ocr_duration_seconds OCR 引擎识别一张图像的耗时（含方向校正，不含缓存命中）
+var ocrDuration = metrics.NewHistogram("zoey_ocr_duration_seconds", "OCR engine time per image, excluding cache hits.", metrics.DurationBuckets)
+
+// ocrEngine OCR 引擎（goocr.Engine 中识别器用到的方法）
+type ocrEngine interface {
+	RunOCR(img image.Image) ([]goocr.RecResult, error)
+	Destroy()
+}
+
 // TextRecognizer OCR 识别器
+// 可被多个 goroutine 并发使用：引擎调用由 mu 串行化（ONNX 会话不支持并发推理）
 type TextRecognizer struct {
-	engine goocr.Engine
+	engine ocrEngine
 	config Config
 	mu     sync.Mutex
+	// provider 引擎实际使用的执行提供者
+	provider ExecutionProvider
+
+	// cache 最近识别过的画面结果（nil 表示不缓存），轮询未变化的屏幕时跳过模型推理
+	cache *resultCache
+	// classifier 方向分类器（未配置 cls 模型时为 nil）
+	classifier orientationClassifier
 }
 
-// 全局单例实例
+// 全局语言模型注册表（默认语言的识别器即原全局单例），由 globalMu 保护
 var (
-	globalRecognizer *TextRecognizer
-	globalOnce       sync.Once
-	globalErr        error
+	globalMu       sync.Mutex
+	globalRegistry *ModelRegistry
+	globalErr      error
 )
 
 // NewTextRecognizer 创建新的 OCR 识别器
@@ -55,65 +74,157 @@ func NewTextRecognizer(config Config) (*TextRecognizer, error) {
 	if config.CPUThreads > 0 {
 		ocrConfig.NumThreads = config.CPUThreads
 	}
-	if config.UseGPU {
-		ocrConfig.UseCuda = true
-	}
 
-	engine, err := goocr.NewPaddleOcrEngine(ocrConfig)
+	engine, provider, err := createEngine(ocrConfig, config.executionProvider())
 	if err != nil {
 		return nil, fmt.Errorf("创建 OCR 引擎失败: %w", err)
 	}
+	setActiveProvider(provider)
+
+	logger.Info("OCR 引擎初始化成功 (PP-OCRv5, %s)", provider)
 
-	logger.Info("OCR 引擎初始化成功 (PP-OCRv5)")
+	r := &TextRecognizer{
+		engine:   engine,
+		config:   config,
+		provider: provider,
+		cache:    newResultCache(config.ResultCacheTTL),
+	}
+
+	// 方向分类模型可选：加载失败时仅记录警告，按原有流程识别
+	if config.ClsModelPath != "" {
+		if classifier, err := newAngleClassifier(config.ClsModelPath); err != nil {
+			logger.Warn("方向分类模型不可用，旋转文字将无法校正: %v", err)
+		} else {
+			r.classifier = classifier
+			logger.Info("已启用文字方向分类: %s", config.ClsModelPath)
+		}
+	}
+	return r, nil
+}
 
-	return &TextRecognizer{
-		engine: engine,
-		config: config,
-	}, nil
+// Provider 返回引擎实际使用的执行提供者（加速提供者初始化失败时为 cpu）
+func (r *TextRecognizer) Provider() ExecutionProvider {
+	return r.provider
 }
 
-// GetGlobalRecognizer 获取全局 OCR 识别器
+// GetGlobalRecognizer 获取全局 OCR 识别器（默认语言）
 func GetGlobalRecognizer() (*TextRecognizer, error) {
-	globalOnce.Do(func() {
-		globalRecognizer, globalErr = NewTextRecognizer(DefaultConfig())
-	})
-	return globalRecognizer, globalErr
+	return GetRecognizer("")
 }
 
-// InitGlobalRecognizer 使用指定配置初始化全局识别器
+// GetRecognizer 获取指定语言的全局识别器（空字符串为默认语言），其他语言的模型首次使用时加载
+// 最多同时驻留 DefaultMaxResidentModels 个模型，调用方不应长期持有返回的识别器
+func GetRecognizer(language string) (*TextRecognizer, error) {
+	registry, err := globalModels()
+	if err != nil {
+		return nil, err
+	}
+	return registry.Get(language)
+}
+
+// AcquireRecognizer 借出指定语言的全局识别器，release 归还前不会因模型淘汰被关闭
+func AcquireRecognizer(language string) (*TextRecognizer, func(), error) {
+	registry, err := globalModels()
+	if err != nil {
+		return nil, nil, err
+	}
+	return registry.Acquire(language)
+}
+
+// InitGlobalRecognizer 使用指定配置初始化全局识别器，config 为默认语言的配置
+// 全局识别器已初始化（包括已自动初始化）时不做任何操作
 func InitGlobalRecognizer(config Config) error {
-	var err error
-	globalOnce.Do(func() {
-		globalRecognizer, err = NewTextRecognizer(config)
-		globalErr = err
-	})
-	return err
+	globalMu.Lock()
+	defer globalMu.Unlock()
+
+	if globalRegistry != nil {
+		return nil
+	}
+	initGlobalRegistry(config)
+	return globalErr
+}
+
+// globalModels 返回全局注册表，未初始化时使用默认配置初始化
+func globalModels() (*ModelRegistry, error) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+
+	if globalRegistry == nil {
+		initGlobalRegistry(DefaultConfig())
+	}
+	return globalRegistry, globalErr
+}
+
+// initGlobalRegistry 创建全局注册表并加载默认语言模型，调用方需持有 globalMu
+func initGlobalRegistry(config Config) {
+	globalRegistry = NewModelRegistry(config, DefaultMaxResidentModels)
+	_, globalErr = globalRegistry.Get("")
 }
 
 // Recognize 识别图像中的所有文字
+// 与最近识别过的画面（像素和区域）完全相同时直接返回缓存结果
 func (r *TextRecognizer) Recognize(img image.Image) ([]OcrResult, error) {
+	return r.recognizeCached(img, r.runOCR)
+}
+
+// recognizeCached 先按画面哈希查缓存，未命中时调用 run 识别并写入缓存
+func (r *TextRecognizer) recognizeCached(img image.Image, run func(image.Image) ([]OcrResult, error)) ([]OcrResult, error) {
+	if r.cache == nil {
+		return run(img)
+	}
+
+	key := frameKey(img)
+	if results, ok := r.cache.get(key); ok {
+		logger.Debug("OCR 画面未变化，使用缓存结果 (%d 个文本)", len(results))
+		return results, nil
+	}
+	results, err := run(img)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.put(key, results)
+	return results, nil
+}
+
+// runOCR 调用 OCR 引擎识别图像
+func (r *TextRecognizer) runOCR(img image.Image) ([]OcrResult, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if r.engine == nil {
+		return nil, fmt.Errorf("OCR 识别器已关闭")
+	}
 	startTime := time.Now()
 
-	results, err := r.engine.RunOCR(img)
+	ocrResults, err := r.runEngine(img)
 	if err != nil {
 		elapsed := float64(time.Since(startTime).Milliseconds())
 		logger.LogEvent("OCR", false, elapsed, "识别失败")
+		return nil, err
+	}
+	if r.classifier != nil {
+		ocrResults = r.correctOrientation(img, ocrResults, r.runEngine)
+	}
+
+	ocrDuration.Observe(time.Since(startTime).Seconds())
+	elapsed := float64(time.Since(startTime).Milliseconds())
+	logger.LogEvent("OCR", true, elapsed, fmt.Sprintf("识别到 %d 个文本", len(ocrResults)))
+
+	return ocrResults, nil
+}
+
+// runEngine 运行检测和识别模型并转换结果，调用方需持有 r.mu
+func (r *TextRecognizer) runEngine(img image.Image) ([]OcrResult, error) {
+	results, err := r.engine.RunOCR(img)
+	if err != nil {
 		return nil, fmt.Errorf("OCR 识别失败: %w", err)
 	}
 
 	// 转换结果
 	ocrResults := make([]OcrResult, 0, len(results))
 	for _, result := range results {
-		ocrResult := convertResult(result)
-		ocrResults = append(ocrResults, ocrResult)
+		ocrResults = append(ocrResults, convertResult(result))
 	}
-
-	elapsed := float64(time.Since(startTime).Milliseconds())
-	logger.LogEvent("OCR", true, elapsed, fmt.Sprintf("识别到 %d 个文本", len(ocrResults)))
-
 	return ocrResults, nil
 }
 
@@ -125,6 +236,19 @@ func (r *TextRecognizer) FindText(img image.Image, targetText string) (*Point, e
 // FindTextWithThreshold 查找特定文字的位置，支持自定义相似度阈值
 // threshold: 0.0-1.0，建议 0.8（80%）
 func (r *TextRecognizer) FindTextWithThreshold(img image.Image, targetText string, threshold float64) (*Point, error) {
+	result, err := r.FindTextMatch(img, TextMatch{Text: targetText, Mode: MatchModeContains, Threshold: threshold})
+	if errors.Is(err, ErrLowConfidence) {
+		return nil, nil
+	}
+	if err != nil || result == nil {
+		return nil, err
+	}
+	return &result.Position, nil
+}
+
+// FindTextMatch 按匹配条件查找文字，返回命中的识别结果（Text 为实际匹配到的文字），未找到时返回 nil
+// 识别置信度低于 MinConfidence 的结果不参与匹配；目标只出现在这些结果中时返回 *LowConfidenceError
+func (r *TextRecognizer) FindTextMatch(img image.Image, m TextMatch) (*OcrResult, error) {
 	startTime := time.Now()
 
 	results, err := r.Recognize(img)
@@ -132,50 +256,18 @@ func (r *TextRecognizer) FindTextWithThreshold(img image.Image, targetText strin
 		return nil, err
 	}
 
-	target := strings.ToLower(targetText)
-	var bestMatch *OcrResult
-	var bestSimilarity float64
-
-	for i, result := range results {
-		text := strings.ToLower(result.Text)
-
-		// 跳过空文本
-		if len(text) == 0 {
-			continue
-		}
-
-		// 1. 精确匹配（最高优先级）
-		if text == target {
-			elapsed := float64(time.Since(startTime).Milliseconds())
-			logger.LogEvent("OCR", true, elapsed, fmt.Sprintf("精确匹配: %s", targetText))
-			return &result.Position, nil
-		}
-
-		// 2. 包含匹配（次高优先级）
-		// 确保两个字符串都非空，且较短的字符串至少有 2 个字符
-		minLen := len(target)
-		if len(text) < minLen {
-			minLen = len(text)
-		}
-		if minLen >= 2 && (strings.Contains(text, target) || strings.Contains(target, text)) {
+	match, kind, err := m.match(results)
+	if err != nil {
+		if errors.Is(err, ErrLowConfidence) {
 			elapsed := float64(time.Since(startTime).Milliseconds())
-			logger.LogEvent("OCR", true, elapsed, fmt.Sprintf("包含匹配: %s -> %s", targetText, result.Text))
-			return &result.Position, nil
-		}
-
-		// 3. 相似度匹配（使用阈值）
-		similarity := calculateSimilarity(target, text)
-		if similarity >= threshold && similarity > bestSimilarity {
-			bestSimilarity = similarity
-			bestMatch = &results[i]
+			logger.LogEvent("OCR", false, elapsed, fmt.Sprintf("未找到文字: %s (%v)", m.Text, err))
 		}
+		return nil, err
 	}
-
-	// 返回最佳相似度匹配
-	if bestMatch != nil {
+	if match != nil {
 		elapsed := float64(time.Since(startTime).Milliseconds())
-		logger.LogEvent("OCR", true, elapsed, fmt.Sprintf("相似匹配(%.0f%%): %s -> %s", bestSimilarity*100, targetText, bestMatch.Text))
-		return &bestMatch.Position, nil
+		logger.LogEvent("OCR", true, elapsed, fmt.Sprintf("%s: %s -> %s", kind, m.Text, match.Text))
+		return match, nil
 	}
 
 	// 输出调试信息：所有识别到的文字
@@ -190,7 +282,7 @@ func (r *TextRecognizer) FindTextWithThreshold(img image.Image, targetText strin
 	}
 
 	elapsed := float64(time.Since(startTime).Milliseconds())
-	logger.LogEvent("OCR", false, elapsed, fmt.Sprintf("未找到文字: %s (阈值: %.0f%%)", targetText, threshold*100))
+	logger.LogEvent("OCR", false, elapsed, fmt.Sprintf("未找到文字: %s (模式: %s)", m.Text, m.mode()))
 	return nil, nil
 }
 
@@ -325,6 +417,13 @@ func (r *TextRecognizer) Close() error {
 		r.engine.Destroy()
 		r.engine = nil
 	}
+	if r.classifier != nil {
+		r.classifier.Close()
+		r.classifier = nil
+	}
+	if r.cache != nil {
+		r.cache.clear()
+	}
 	return nil
 }
 
@@ -350,12 +449,14 @@ func convertResult(result goocr.RecResult) OcrResult {
 	}
 }
 
-// ClearCache 清除全局识别器缓存
+// ClearCache 清除全局识别器缓存（关闭所有已加载的语言模型）
 func ClearCache() {
-	if globalRecognizer != nil {
-		globalRecognizer.Close()
-		globalRecognizer = nil
+	globalMu.Lock()
+	defer globalMu.Unlock()
+
+	if globalRegistry != nil {
+		globalRegistry.Close()
+		globalRegistry = nil
 	}
-	globalOnce = sync.Once{}
 	globalErr = nil
 }
@@ -0,0 +1,192 @@
+package ocr
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultMaxResidentModels 同时驻留内存的语言模型数上限
+const DefaultMaxResidentModels = 2
+
+// NormalizeLanguage 规范化语言名称（去除首尾空白并转为小写）
+// 语言名称用作模型子目录名，只允许字母、数字、'-' 和 '_'
+func NormalizeLanguage(language string) (string, error) {
+	lang := strings.ToLower(strings.TrimSpace(language))
+	for _, c := range lang {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+			return "", fmt.Errorf("无效的 OCR 语言: %q", language)
+		}
+	}
+	return lang, nil
+}
+
+// ResolveLanguageConfig 返回指定语言的识别器配置
+// 语言为空或与 base.Language 相同时返回 base；其余语言的模型位于默认模型目录的同级子目录
+// （插件为 plugins/ocr/<lang>/，开发环境为 models/<lang>/），其中 rec.onnx 和 dict.txt 必需，
+// det.onnx 和 cls.onnx 不存在时沿用 base 的模型
+func ResolveLanguageConfig(base Config, language string) (Config, error) {
+	lang, err := NormalizeLanguage(language)
+	if err != nil {
+		return Config{}, err
+	}
+	if lang == "" || lang == strings.ToLower(base.Language) {
+		return base, nil
+	}
+
+	dir := filepath.Join(filepath.Dir(filepath.Dir(base.RecModelPath)), lang)
+	config := base
+	config.Language = lang
+	config.RecModelPath = filepath.Join(dir, "rec.onnx")
+	config.DictPath = filepath.Join(dir, "dict.txt")
+	if !fileExists(config.RecModelPath) || !fileExists(config.DictPath) {
+		return Config{}, fmt.Errorf("未安装 OCR 语言模型 %s（需要 %s 和 %s）", lang, config.RecModelPath, config.DictPath)
+	}
+	if p := filepath.Join(dir, "det.onnx"); fileExists(p) {
+		config.DetModelPath = p
+	}
+	if p := filepath.Join(dir, "cls.onnx"); fileExists(p) {
+		config.ClsModelPath = p
+	}
+	return config, nil
+}
+
+// ModelRegistry 按语言懒加载并缓存识别器，可被多个 goroutine 并发使用
+// 驻留的识别器超过上限时淘汰最久未使用的一个：未被借出时立即关闭，否则在最后一次归还时关闭
+type ModelRegistry struct {
+	mu          sync.Mutex
+	base        Config
+	maxResident int
+	// entries 按最近使用时间排序，最近使用的在末尾
+	entries []*registryEntry
+
+	// newRecognizer 创建识别器（测试时替换）
+	newRecognizer func(Config) (*TextRecognizer, error)
+}
+
+// registryEntry 已加载的语言识别器
+type registryEntry struct {
+	language   string
+	recognizer *TextRecognizer
+	// refs 借出次数（Acquire 未归还）
+	refs int
+	// evicted 已被淘汰，归还到 0 时关闭
+	evicted bool
+}
+
+// NewModelRegistry 创建语言模型注册表，base 为默认语言的配置，maxResident <= 0 使用 DefaultMaxResidentModels
+func NewModelRegistry(base Config, maxResident int) *ModelRegistry {
+	if maxResident <= 0 {
+		maxResident = DefaultMaxResidentModels
+	}
+	return &ModelRegistry{
+		base:          base,
+		maxResident:   maxResident,
+		newRecognizer: NewTextRecognizer,
+	}
+}
+
+// Get 获取指定语言的识别器（空字符串为默认语言），未加载时按 ResolveLanguageConfig 解析的配置创建
+// 返回的识别器可能随时被淘汰关闭；需要跨多次识别使用时用 Acquire
+func (m *ModelRegistry) Get(language string) (*TextRecognizer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := m.entry(language)
+	if err != nil {
+		return nil, err
+	}
+	return e.recognizer, nil
+}
+
+// Acquire 借出指定语言的识别器，release 归还前不会被关闭（release 可重复调用）
+func (m *ModelRegistry) Acquire(language string) (recognizer *TextRecognizer, release func(), err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := m.entry(language)
+	if err != nil {
+		return nil, nil, err
+	}
+	e.refs++
+
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			e.refs--
+			if e.evicted && e.refs == 0 {
+				e.recognizer.Close()
+			}
+		})
+	}
+	return e.recognizer, release, nil
+}
+
+// entry 查找或加载语言识别器并标记为最近使用，调用方需持有 m.mu
+func (m *ModelRegistry) entry(language string) (*registryEntry, error) {
+	lang, err := NormalizeLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+	if lang == "" {
+		lang = strings.ToLower(m.base.Language)
+	}
+
+	for i, e := range m.entries {
+		if e.language == lang {
+			m.entries = append(append(m.entries[:i:i], m.entries[i+1:]...), e)
+			return e, nil
+		}
+	}
+
+	config, err := ResolveLanguageConfig(m.base, lang)
+	if err != nil {
+		return nil, err
+	}
+	recognizer, err := m.newRecognizer(config)
+	if err != nil {
+		return nil, fmt.Errorf("初始化 OCR 语言模型 %s 失败: %w", lang, err)
+	}
+
+	for len(m.entries) >= m.maxResident {
+		m.evict(m.entries[0])
+		m.entries = m.entries[1:]
+	}
+	e := &registryEntry{language: lang, recognizer: recognizer}
+	m.entries = append(m.entries, e)
+	return e, nil
+}
+
+// evict 淘汰识别器：未被借出时立即关闭，否则等最后一次归还时关闭
+func (m *ModelRegistry) evict(e *registryEntry) {
+	e.evicted = true
+	if e.refs == 0 {
+		e.recognizer.Close()
+	}
+}
+
+// Languages 返回已加载的语言（按最近使用时间升序）
+func (m *ModelRegistry) Languages() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	languages := make([]string, len(m.entries))
+	for i, e := range m.entries {
+		languages[i] = e.language
+	}
+	return languages
+}
+
+// Close 关闭所有已加载的识别器（借出中的在归还时关闭）
+func (m *ModelRegistry) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.entries {
+		m.evict(e)
+	}
+	m.entries = nil
+}
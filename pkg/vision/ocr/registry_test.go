@@ -0,0 +1,235 @@
+package ocr
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goocr "github.com/getcharzp/go-ocr"
+)
+
+// languageFixture 创建默认模型目录和日语模型子目录，返回默认语言配置
+func languageFixture(t *testing.T) (Config, string) {
+	t.Helper()
+	root := t.TempDir()
+	for _, name := range []string{"paddle_weights/det.onnx", "paddle_weights/rec.onnx", "paddle_weights/dict.txt", "ja/rec.onnx", "ja/dict.txt"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	base := Config{
+		DetModelPath: filepath.Join(root, "paddle_weights", "det.onnx"),
+		RecModelPath: filepath.Join(root, "paddle_weights", "rec.onnx"),
+		DictPath:     filepath.Join(root, "paddle_weights", "dict.txt"),
+		Language:     "ch",
+	}
+	return base, root
+}
+
+func TestResolveLanguageConfig(t *testing.T) {
+	base, root := languageFixture(t)
+
+	for _, lang := range []string{"", "CH"} {
+		if got, err := ResolveLanguageConfig(base, lang); err != nil || got != base {
+			t.Errorf("默认语言 %q 应返回 base 配置, got=%+v err=%v", lang, got, err)
+		}
+	}
+
+	got, err := ResolveLanguageConfig(base, " ja ")
+	if err != nil {
+		t.Fatalf("解析 ja 配置失败: %v", err)
+	}
+	if got.Language != "ja" || got.RecModelPath != filepath.Join(root, "ja", "rec.onnx") || got.DictPath != filepath.Join(root, "ja", "dict.txt") {
+		t.Errorf("ja 配置 = %+v", got)
+	}
+	if got.DetModelPath != base.DetModelPath {
+		t.Errorf("ja 未提供检测模型时应沿用默认模型, 实际 %s", got.DetModelPath)
+	}
+
+	if _, err := ResolveLanguageConfig(base, "ko"); err == nil {
+		t.Error("未安装的语言应返回错误")
+	}
+	if _, err := ResolveLanguageConfig(base, "../ja"); err == nil {
+		t.Error("含路径分隔符的语言名称应返回错误")
+	}
+}
+
+func TestModelRegistry_LRU(t *testing.T) {
+	base, root := languageFixture(t)
+	for _, name := range []string{"ko/rec.onnx", "ko/dict.txt"} {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registry := NewModelRegistry(base, 2)
+	created := map[string]int{}
+	registry.newRecognizer = func(c Config) (*TextRecognizer, error) {
+		created[c.Language]++
+		return &TextRecognizer{config: c}, nil
+	}
+
+	ch, err := registry.Get("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := registry.Get("ch"); again != ch {
+		t.Error("默认语言应复用同一个识别器")
+	}
+	registry.Get("ja")
+	registry.Get("ch") // ch 变为最近使用
+	registry.Get("ko") // 淘汰最久未使用的 ja
+
+	if got := registry.Languages(); !reflect.DeepEqual(got, []string{"ch", "ko"}) {
+		t.Errorf("驻留语言 = %v, 期望 [ch ko]", got)
+	}
+	registry.Get("ja")
+	if created["ja"] != 2 || created["ch"] != 1 {
+		t.Errorf("创建次数 = %v, 被淘汰的 ja 应重新加载", created)
+	}
+
+	registry.Close()
+	if got := registry.Languages(); len(got) != 0 {
+		t.Errorf("Close 后驻留语言 = %v", got)
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	for in, want := range map[string]string{"": "", " JA ": "ja", "zh_tw": "zh_tw", "en-US": "en-us"} {
+		if got, err := NormalizeLanguage(in); err != nil || got != want {
+			t.Errorf("NormalizeLanguage(%q) = %q, %v; 期望 %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"ja/..", "日本語", "a b"} {
+		if _, err := NormalizeLanguage(in); err == nil {
+			t.Errorf("NormalizeLanguage(%q) 应返回错误", in)
+		}
+	}
+}
+
+// fakeEngine 记录并发调用的 OCR 引擎
+type fakeEngine struct {
+	inflight, maxInflight atomic.Int32
+	destroyed             atomic.Bool
+}
+
+func (e *fakeEngine) RunOCR(image.Image) ([]goocr.RecResult, error) {
+	n := e.inflight.Add(1)
+	defer e.inflight.Add(-1)
+	for {
+		m := e.maxInflight.Load()
+		if n <= m || e.maxInflight.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	return []goocr.RecResult{{Box: [4]int{0, 0, 10, 10}, Text: "确定", Score: 0.9}}, nil
+}
+
+func (e *fakeEngine) Destroy() { e.destroyed.Store(true) }
+
+func TestRecognize_ConcurrentSerialized(t *testing.T) {
+	engine := &fakeEngine{}
+	r := &TextRecognizer{engine: engine, cache: newResultCache(0)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				// 每次使用不同画面，避免命中缓存
+				frame := cacheFrame(16, 16)
+				frame.Pix[0] = uint8(i*10 + j)
+				if _, err := r.Recognize(frame); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := engine.maxInflight.Load(); got != 1 {
+		t.Errorf("引擎最大并发数 = %d, 期望 1（ONNX 会话不支持并发推理）", got)
+	}
+}
+
+func TestModelRegistry_AcquireDefersClose(t *testing.T) {
+	base, root := languageFixture(t)
+	for _, name := range []string{"ko/rec.onnx", "ko/dict.txt"} {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registry := NewModelRegistry(base, 2)
+	engines := map[string]*fakeEngine{}
+	registry.newRecognizer = func(c Config) (*TextRecognizer, error) {
+		engines[c.Language] = &fakeEngine{}
+		return &TextRecognizer{engine: engines[c.Language], config: c}, nil
+	}
+
+	ja, release, err := registry.Acquire("ja")
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry.Get("")
+	registry.Get("ko") // 淘汰借出中的 ja
+
+	if engines["ja"].destroyed.Load() {
+		t.Fatal("借出中的识别器不应被关闭")
+	}
+	if _, err := ja.Recognize(cacheFrame(8, 8)); err != nil {
+		t.Errorf("淘汰后归还前仍应可用: %v", err)
+	}
+
+	release()
+	release() // 重复归还无副作用
+	if !engines["ja"].destroyed.Load() {
+		t.Error("归还后被淘汰的识别器应关闭")
+	}
+}
+
+func TestModelRegistry_ConcurrentAcquire(t *testing.T) {
+	base, _ := languageFixture(t)
+	registry := NewModelRegistry(base, 1)
+	registry.newRecognizer = func(c Config) (*TextRecognizer, error) {
+		return &TextRecognizer{engine: &fakeEngine{}, config: c, cache: newResultCache(-1)}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// 容量为 1 时交替使用两种语言，借出的识别器不断被淘汰
+			lang := []string{"", "ja"}[i%2]
+			for j := 0; j < 20; j++ {
+				r, release, err := registry.Acquire(lang)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := r.Recognize(cacheFrame(4, 4)); err != nil {
+					t.Errorf("借出的识别器不可用: %v", err)
+				}
+				release()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 )
 
 func init() {
@@ -74,12 +75,18 @@ type Config struct {
 	RecModelPath string
 	// DictPath 字典文件路径
 	DictPath string
+	// ClsModelPath 方向分类模型路径（可选，为空时不校正旋转 90° 或倒置的文字）
+	ClsModelPath string
 	// Language 语言 (ch, en)
 	Language string
-	// UseGPU 是否使用 GPU
+	// UseGPU 是否使用 GPU（ExecutionProvider 为空时等价于 cuda）
 	UseGPU bool
+	// ExecutionProvider ONNX Runtime 执行提供者（空为 SetDefaultExecutionProvider 设置的默认值），初始化失败时回退 CPU
+	ExecutionProvider ExecutionProvider
 	// CPUThreads CPU 线程数
 	CPUThreads int
+	// ResultCacheTTL 识别结果缓存有效期（0 使用 DefaultResultCacheTTL，< 0 禁用缓存）
+	ResultCacheTTL time.Duration
 }
 
 // DefaultConfig 默认配置
@@ -89,6 +96,7 @@ func DefaultConfig() Config {
 		DetModelPath:       getDefaultModelPath("det.onnx"),
 		RecModelPath:       getDefaultModelPath("rec.onnx"),
 		DictPath:           getDefaultModelPath("dict.txt"),
+		ClsModelPath:       getOptionalModelPath("cls.onnx"),
 		Language:           "ch",
 		UseGPU:             false,
 		CPUThreads:         4,
@@ -169,6 +177,14 @@ func getDefaultModelPath(filename string) string {
 	return paths[0]
 }
 
+// getOptionalModelPath 获取可选模型的路径，所有候选路径都不存在时返回空字符串
+func getOptionalModelPath(filename string) string {
+	if p := getDefaultModelPath(filename); fileExists(p) {
+		return p
+	}
+	return ""
+}
+
 // fileExists 检查文件是否存在
 func fileExists(path string) bool {
 	_, err := statFile(path)
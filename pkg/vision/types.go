@@ -70,6 +70,8 @@ type MatchResult struct {
 	Confidence float64 `json:"confidence"`
 	// Time 匹配耗时（毫秒）
 	Time float64 `json:"time,omitempty"`
+	// Scale 命中时模板的缩放比例（多尺度匹配的候选倍率，单尺度匹配为 1.0）
+	Scale float64 `json:"scale"`
 }
 
 // OcrResult OCR 识别结果
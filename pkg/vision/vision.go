@@ -113,6 +113,7 @@ func convertCVMatchResult(r *cv.MatchResult) *MatchResult {
 		},
 		Confidence: r.Confidence,
 		Time:       r.Time,
+		Scale:      r.Scale,
 	}
 }
 